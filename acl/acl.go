@@ -44,6 +44,10 @@ type ACL struct {
 	// namespaces maps a namespace to a capabilitySet
 	namespaces *iradix.Tree
 
+	// nodePools maps a namespace to the set of node pools its jobs may use.
+	// Namespaces without an entry may use any node pool.
+	nodePools map[string]map[string]struct{}
+
 	agent    string
 	node     string
 	operator string
@@ -75,6 +79,10 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 	acl := &ACL{}
 	nsTxn := iradix.New().Txn()
 
+	// Track the namespaces which are granted access to any node pool
+	nodePools := make(map[string]map[string]struct{})
+	anyNodePool := make(map[string]struct{})
+
 	for _, policy := range policies {
 	NAMESPACES:
 		for _, ns := range policy.Namespaces {
@@ -93,6 +101,25 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 				continue NAMESPACES
 			}
 
+			// Grant the union of the node pools, where a policy that allows
+			// submitting jobs without listing node pools grants all of them
+			if len(ns.NodePools) == 0 {
+				for _, cap := range ns.Capabilities {
+					if cap == NamespaceCapabilitySubmitJob {
+						anyNodePool[ns.Name] = struct{}{}
+					}
+				}
+			} else {
+				pools, ok := nodePools[ns.Name]
+				if !ok {
+					pools = make(map[string]struct{})
+					nodePools[ns.Name] = pools
+				}
+				for _, pool := range ns.NodePools {
+					pools[pool] = struct{}{}
+				}
+			}
+
 			// Add in all the capabilities
 			for _, cap := range ns.Capabilities {
 				if cap == NamespaceCapabilityDeny {
@@ -119,6 +146,12 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 
 	// Finalize the namespaces
 	acl.namespaces = nsTxn.Commit()
+
+	// Finalize the node pools
+	for ns := range anyNodePool {
+		delete(nodePools, ns)
+	}
+	acl.nodePools = nodePools
 	return acl, nil
 }
 
@@ -145,6 +178,22 @@ func (a *ACL) AllowNamespaceOperation(ns string, op string) bool {
 	return capabilities.Check(op)
 }
 
+// AllowNamespaceNodePool checks if jobs in a namespace may use a node pool
+func (a *ACL) AllowNamespaceNodePool(ns string, pool string) bool {
+	// Hot path management tokens
+	if a.management {
+		return true
+	}
+
+	// Namespaces without node pools may use any node pool
+	pools, ok := a.nodePools[ns]
+	if !ok {
+		return true
+	}
+	_, ok = pools[pool]
+	return ok
+}
+
 // AllowAgentRead checks if read operations are allowed for an agent
 func (a *ACL) AllowAgentRead() bool {
 	switch {
@@ -151,6 +151,45 @@ func TestACLMerge(t *testing.T) {
 	assert.Equal(t, false, acl.AllowOperatorWrite())
 }
 
+func TestACLNodePools(t *testing.T) {
+	// No node pools restrict the namespace
+	p1, err := Parse(writeAll)
+	assert.Nil(t, err)
+	acl, err := NewACL(false, []*Policy{p1})
+	assert.Nil(t, err)
+	assert.Equal(t, true, acl.AllowNamespaceNodePool("default", "gpu"))
+
+	// Restrict the namespace to the listed node pools
+	p2, err := Parse(writeGPU)
+	assert.Nil(t, err)
+	acl, err = NewACL(false, []*Policy{p2})
+	assert.Nil(t, err)
+	assert.Equal(t, true, acl.AllowNamespaceNodePool("default", "gpu"))
+	assert.Equal(t, false, acl.AllowNamespaceNodePool("default", "default"))
+
+	// A read policy doesn't lift the restriction
+	p3, err := Parse(readAll)
+	assert.Nil(t, err)
+	acl, err = NewACL(false, []*Policy{p2, p3})
+	assert.Nil(t, err)
+	assert.Equal(t, false, acl.AllowNamespaceNodePool("default", "default"))
+
+	// A write policy without node pools grants all of them
+	acl, err = NewACL(false, []*Policy{p2, p1})
+	assert.Nil(t, err)
+	assert.Equal(t, true, acl.AllowNamespaceNodePool("default", "default"))
+
+	// Management tokens may use any node pool
+	assert.Equal(t, true, ManagementACL.AllowNamespaceNodePool("default", "default"))
+}
+
+var writeGPU = `
+namespace "default" {
+	policy = "write"
+	node_pools = ["gpu"]
+}
+`
+
 var readAll = `
 namespace "default" {
 	policy = "read"
@@ -33,6 +33,7 @@ const (
 
 var (
 	validNamespace = regexp.MustCompile("^[a-zA-Z0-9-]{1,128}$")
+	validNodePool  = regexp.MustCompile("^[a-zA-Z0-9-_]{1,128}$")
 )
 
 // Policy represents a parsed HCL or JSON policy.
@@ -49,6 +50,10 @@ type NamespacePolicy struct {
 	Name         string `hcl:",key"`
 	Policy       string
 	Capabilities []string
+
+	// NodePools restricts the node pools jobs in the namespace may use. If
+	// empty, any node pool may be used.
+	NodePools []string `hcl:"node_pools"`
 }
 
 type AgentPolicy struct {
@@ -142,6 +147,11 @@ func Parse(rules string) (*Policy, error) {
 				return nil, fmt.Errorf("Invalid namespace capability '%s': %#v", cap, ns)
 			}
 		}
+		for _, pool := range ns.NodePools {
+			if !validNodePool.MatchString(pool) {
+				return nil, fmt.Errorf("Invalid namespace node pool '%s': %#v", pool, ns)
+			}
+		}
 
 		// Expand the short hand policy to the capabilities and
 		// add to any existing capabilities
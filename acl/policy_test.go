@@ -152,6 +152,42 @@ func TestParse(t *testing.T) {
 			"Invalid namespace name",
 			nil,
 		},
+		{
+			`
+			namespace "default" {
+				policy = "write"
+				node_pools = ["default", "gpu"]
+			}
+			`,
+			"",
+			&Policy{
+				Namespaces: []*NamespacePolicy{
+					{
+						Name:   "default",
+						Policy: PolicyWrite,
+						Capabilities: []string{
+							NamespaceCapabilityListJobs,
+							NamespaceCapabilityReadJob,
+							NamespaceCapabilitySubmitJob,
+							NamespaceCapabilityDispatchJob,
+							NamespaceCapabilityReadLogs,
+							NamespaceCapabilityReadFS,
+						},
+						NodePools: []string{"default", "gpu"},
+					},
+				},
+			},
+		},
+		{
+			`
+			namespace "default" {
+				policy = "write"
+				node_pools = ["has a space"]
+			}
+			`,
+			"Invalid namespace node pool",
+			nil,
+		},
 		{
 			`
 			namespace "default" {
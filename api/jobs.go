@@ -18,9 +18,21 @@ const (
 	// JobTypeBatch indicates a short-lived process
 	JobTypeBatch = "batch"
 
+	// JobTypeSystem indicates a long-running process placed on every node
+	JobTypeSystem = "system"
+
+	// JobTypeSysBatch indicates a short-lived process placed on every node
+	JobTypeSysBatch = "sysbatch"
+
 	// PeriodicSpecCron is used for a cron spec.
 	PeriodicSpecCron = "cron"
 
+	// PeriodicCatchUpNone, PeriodicCatchUpLast and PeriodicCatchUpAll are the
+	// policies for launches missed while there was no leader.
+	PeriodicCatchUpNone = "none"
+	PeriodicCatchUpLast = "last"
+	PeriodicCatchUpAll  = "all"
+
 	// DefaultNamespace is the default namespace.
 	DefaultNamespace = "default"
 )
@@ -56,6 +68,7 @@ type RegisterOptions struct {
 	EnforceIndex   bool
 	ModifyIndex    uint64
 	PolicyOverride bool
+	Submission     *JobSubmission
 }
 
 // Register is used to register a new job. It returns the ID
@@ -85,6 +98,7 @@ func (j *Jobs) RegisterOpts(job *Job, opts *RegisterOptions, q *WriteOptions) (*
 		if opts.PolicyOverride {
 			req.PolicyOverride = true
 		}
+		req.Submission = opts.Submission
 	}
 
 	var resp JobRegisterResponse
@@ -133,6 +147,17 @@ func (j *Jobs) Versions(jobID string, diffs bool, q *QueryOptions) ([]*Job, []*J
 	return resp.Versions, resp.Diffs, qm, nil
 }
 
+// Submission is used to retrieve the source the given version of a job was
+// submitted with.
+func (j *Jobs) Submission(jobID string, version int, q *QueryOptions) (*JobSubmission, *QueryMeta, error) {
+	var resp JobSubmission
+	qm, err := j.client.query(fmt.Sprintf("/v1/job/%s/submission?version=%d", jobID, version), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // Allocations is used to return the allocs for a given job ID.
 func (j *Jobs) Allocations(jobID string, allAllocs bool, q *QueryOptions) ([]*AllocationListStub, *QueryMeta, error) {
 	var resp []*AllocationListStub
@@ -176,6 +201,17 @@ func (j *Jobs) LatestDeployment(jobID string, q *QueryOptions) (*Deployment, *Qu
 	return resp, qm, nil
 }
 
+// Explain is used to diagnose why the allocations of the given job are not
+// placed.
+func (j *Jobs) Explain(jobID string, q *QueryOptions) (*JobExplanation, *QueryMeta, error) {
+	var resp JobExplanation
+	qm, err := j.client.query("/v1/job/"+jobID+"/explain", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // Evaluations is used to query the evaluations associated with the given job
 // ID.
 func (j *Jobs) Evaluations(jobID string, q *QueryOptions) ([]*Evaluation, *QueryMeta, error) {
@@ -220,6 +256,35 @@ func (j *Jobs) PeriodicForce(jobID string, q *WriteOptions) (string, *WriteMeta,
 	return resp.EvalID, wm, nil
 }
 
+// PeriodicPause pauses the launches of the periodic job
+func (j *Jobs) PeriodicPause(jobID string, q *WriteOptions) (*WriteMeta, error) {
+	wm, err := j.client.write("/v1/job/"+jobID+"/periodic/pause", nil, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// PeriodicResume resumes the launches of the paused periodic job
+func (j *Jobs) PeriodicResume(jobID string, q *WriteOptions) (*WriteMeta, error) {
+	wm, err := j.client.write("/v1/job/"+jobID+"/periodic/resume", nil, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// PeriodicLaunch is used to retrieve the last launch and paused state of the
+// periodic job
+func (j *Jobs) PeriodicLaunch(jobID string, q *QueryOptions) (*PeriodicLaunch, *QueryMeta, error) {
+	var resp PeriodicLaunch
+	qm, err := j.client.query("/v1/job/"+jobID+"/periodic", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // PlanOptions is used to pass through job planning parameters
 type PlanOptions struct {
 	Diff           bool
@@ -253,6 +318,29 @@ func (j *Jobs) PlanOpts(job *Job, opts *PlanOptions, q *WriteOptions) (*JobPlanR
 	return &resp, wm, nil
 }
 
+// Capacity is used to forecast how many more instances of a task group of the
+// job fit in the cluster. The task group may be empty if the job has a single
+// task group and a zero limit uses the default limit of the servers.
+func (j *Jobs) Capacity(job *Job, taskGroup string, limit int, q *WriteOptions) (*JobCapacityResponse, *WriteMeta, error) {
+	if job == nil {
+		return nil, nil, fmt.Errorf("must pass non-nil job")
+	}
+
+	// Setup the request
+	req := &JobCapacityRequest{
+		Job:       job,
+		TaskGroup: taskGroup,
+		Limit:     limit,
+	}
+
+	var resp JobCapacityResponse
+	wm, err := j.client.write("/v1/job/"+*job.ID+"/capacity", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 func (j *Jobs) Summary(jobID string, q *QueryOptions) (*JobSummary, *QueryMeta, error) {
 	var resp JobSummary
 	qm, err := j.client.query("/v1/job/"+jobID+"/summary", &resp, q)
@@ -264,11 +352,30 @@ func (j *Jobs) Summary(jobID string, q *QueryOptions) (*JobSummary, *QueryMeta,
 
 func (j *Jobs) Dispatch(jobID string, meta map[string]string,
 	payload []byte, q *WriteOptions) (*JobDispatchResponse, *WriteMeta, error) {
+	opts := DispatchOptions{Meta: meta, Payload: payload}
+	return j.DispatchOpts(jobID, &opts, q)
+}
+
+// DispatchOptions is used to pass through job dispatch parameters
+type DispatchOptions struct {
+	Meta    map[string]string
+	Payload []byte
+
+	// IdempotencyToken ensures the job is dispatched only once. If a
+	// dispatched job with the same token is not dead, it is returned instead.
+	IdempotencyToken string
+}
+
+func (j *Jobs) DispatchOpts(jobID string, opts *DispatchOptions,
+	q *WriteOptions) (*JobDispatchResponse, *WriteMeta, error) {
 	var resp JobDispatchResponse
 	req := &JobDispatchRequest{
-		JobID:   jobID,
-		Meta:    meta,
-		Payload: payload,
+		JobID: jobID,
+	}
+	if opts != nil {
+		req.Meta = opts.Meta
+		req.Payload = opts.Payload
+		req.IdempotencyToken = opts.IdempotencyToken
 	}
 	wm, err := j.client.write("/v1/job/"+jobID+"/dispatch", req, &resp, q)
 	if err != nil {
@@ -488,9 +595,13 @@ func (u *UpdateStrategy) Empty() bool {
 type PeriodicConfig struct {
 	Enabled         *bool
 	Spec            *string
+	Specs           []string
 	SpecType        *string
-	ProhibitOverlap *bool   `mapstructure:"prohibit_overlap"`
-	TimeZone        *string `mapstructure:"time_zone"`
+	ProhibitOverlap *bool          `mapstructure:"prohibit_overlap"`
+	TimeZone        *string        `mapstructure:"time_zone"`
+	CatchUp         *string        `mapstructure:"catch_up"`
+	CatchUpWindow   *time.Duration `mapstructure:"catch_up_window"`
+	Jitter          *time.Duration
 }
 
 func (p *PeriodicConfig) Canonicalize() {
@@ -509,6 +620,15 @@ func (p *PeriodicConfig) Canonicalize() {
 	if p.TimeZone == nil || *p.TimeZone == "" {
 		p.TimeZone = helper.StringToPtr("UTC")
 	}
+	if p.CatchUp == nil || *p.CatchUp == "" {
+		p.CatchUp = helper.StringToPtr(PeriodicCatchUpLast)
+	}
+	if p.CatchUpWindow == nil {
+		p.CatchUpWindow = helper.TimeToPtr(0)
+	}
+	if p.Jitter == nil {
+		p.Jitter = helper.TimeToPtr(0)
+	}
 }
 
 // Next returns the closest time instant matching the spec that is after the
@@ -516,13 +636,25 @@ func (p *PeriodicConfig) Canonicalize() {
 // returned. The `time.Location` of the returned value matches that of the
 // passed time.
 func (p *PeriodicConfig) Next(fromTime time.Time) time.Time {
-	if *p.SpecType == PeriodicSpecCron {
-		if e, err := cronexpr.Parse(*p.Spec); err == nil {
-			return e.Next(fromTime)
-		}
+	var next time.Time
+	if *p.SpecType != PeriodicSpecCron {
+		return next
 	}
 
-	return time.Time{}
+	specs := p.Specs
+	if p.Spec != nil && *p.Spec != "" {
+		specs = append([]string{*p.Spec}, specs...)
+	}
+	for _, spec := range specs {
+		e, err := cronexpr.Parse(spec)
+		if err != nil {
+			continue
+		}
+		if n := e.Next(fromTime); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
 }
 
 func (p *PeriodicConfig) GetLocation() (*time.Location, error) {
@@ -533,41 +665,57 @@ func (p *PeriodicConfig) GetLocation() (*time.Location, error) {
 	return time.LoadLocation(*p.TimeZone)
 }
 
+// PeriodicLaunch tracks the last launch of a periodic job and whether its
+// launches are paused.
+type PeriodicLaunch struct {
+	ID          string
+	Namespace   string
+	Launch      time.Time
+	Paused      bool
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
 // ParameterizedJobConfig is used to configure the parameterized job.
 type ParameterizedJobConfig struct {
 	Payload      string
 	MetaRequired []string `mapstructure:"meta_required"`
 	MetaOptional []string `mapstructure:"meta_optional"`
+	MaxRunning   int      `mapstructure:"max_running"`
 }
 
 // Job is used to serialize a job.
 type Job struct {
-	Stop              *bool
-	Region            *string
-	Namespace         *string
-	ID                *string
-	ParentID          *string
-	Name              *string
-	Type              *string
-	Priority          *int
-	AllAtOnce         *bool `mapstructure:"all_at_once"`
-	Datacenters       []string
-	Constraints       []*Constraint
-	TaskGroups        []*TaskGroup
-	Update            *UpdateStrategy
-	Periodic          *PeriodicConfig
-	ParameterizedJob  *ParameterizedJobConfig
-	Payload           []byte
-	Meta              map[string]string
-	VaultToken        *string `mapstructure:"vault_token"`
-	Status            *string
-	StatusDescription *string
-	Stable            *bool
-	Version           *uint64
-	SubmitTime        *int64
-	CreateIndex       *uint64
-	ModifyIndex       *uint64
-	JobModifyIndex    *uint64
+	Stop                     *bool
+	Region                   *string
+	Namespace                *string
+	ID                       *string
+	ParentID                 *string
+	Name                     *string
+	Type                     *string
+	Priority                 *int
+	AllAtOnce                *bool `mapstructure:"all_at_once"`
+	Datacenters              []string
+	NodePool                 *string `mapstructure:"node_pool"`
+	SchedulerAlgorithm       *string `mapstructure:"scheduler_algorithm"`
+	Constraints              []*Constraint
+	TaskGroups               []*TaskGroup
+	Update                   *UpdateStrategy
+	Periodic                 *PeriodicConfig
+	ParameterizedJob         *ParameterizedJobConfig
+	Payload                  []byte
+	DispatchIdempotencyToken *string
+	Meta                     map[string]string
+	VaultToken               *string `mapstructure:"vault_token"`
+	ConsulToken              *string `mapstructure:"consul_token"`
+	Status                   *string
+	StatusDescription        *string
+	Stable                   *bool
+	Version                  *uint64
+	SubmitTime               *int64
+	CreateIndex              *uint64
+	ModifyIndex              *uint64
+	JobModifyIndex           *uint64
 }
 
 // IsPeriodic returns whether a job is periodic.
@@ -590,9 +738,18 @@ func (j *Job) Canonicalize() {
 	if j.ParentID == nil {
 		j.ParentID = helper.StringToPtr("")
 	}
+	if j.DispatchIdempotencyToken == nil {
+		j.DispatchIdempotencyToken = helper.StringToPtr("")
+	}
 	if j.Namespace == nil {
 		j.Namespace = helper.StringToPtr(DefaultNamespace)
 	}
+	if j.NodePool == nil {
+		j.NodePool = helper.StringToPtr(NodePoolDefault)
+	}
+	if j.SchedulerAlgorithm == nil {
+		j.SchedulerAlgorithm = helper.StringToPtr("")
+	}
 	if j.Priority == nil {
 		j.Priority = helper.IntToPtr(50)
 	}
@@ -614,6 +771,9 @@ func (j *Job) Canonicalize() {
 	if j.VaultToken == nil {
 		j.VaultToken = helper.StringToPtr("")
 	}
+	if j.ConsulToken == nil {
+		j.ConsulToken = helper.StringToPtr("")
+	}
 	if j.Status == nil {
 		j.Status = helper.StringToPtr("")
 	}
@@ -664,6 +824,7 @@ type JobChildrenSummary struct {
 	Pending int64
 	Running int64
 	Dead    int64
+	Queued  int64
 }
 
 func (jc *JobChildrenSummary) Sum() int {
@@ -671,7 +832,7 @@ func (jc *JobChildrenSummary) Sum() int {
 		return 0
 	}
 
-	return int(jc.Pending + jc.Running + jc.Dead)
+	return int(jc.Pending + jc.Running + jc.Dead + jc.Queued)
 }
 
 // TaskGroup summarizes the state of all the allocations of a particular
@@ -683,6 +844,7 @@ type TaskGroupSummary struct {
 	Running  int
 	Starting int
 	Lost     int
+	Unknown  int
 }
 
 // JobListStub is used to return a subset of information about
@@ -837,15 +999,34 @@ type JobRegisterRequest struct {
 	JobModifyIndex uint64
 	PolicyOverride bool
 
+	// Submission is the optional source the job was parsed from.
+	Submission *JobSubmission
+
 	WriteRequest
 }
 
 // RegisterJobRequest is used to serialize a job registration
 type RegisterJobRequest struct {
 	Job            *Job
-	EnforceIndex   bool   `json:",omitempty"`
-	JobModifyIndex uint64 `json:",omitempty"`
-	PolicyOverride bool   `json:",omitempty"`
+	EnforceIndex   bool           `json:",omitempty"`
+	JobModifyIndex uint64         `json:",omitempty"`
+	PolicyOverride bool           `json:",omitempty"`
+	Submission     *JobSubmission `json:",omitempty"`
+}
+
+// JobSubmission is the original source of a job, as submitted by the user.
+type JobSubmission struct {
+	// Source is the contents of the job file
+	Source string
+
+	// Format is the format of the source; one of "hcl1", "hcl2" or "json"
+	Format string
+
+	// VariableFlags are the values of the variables set with -var
+	VariableFlags map[string]string
+
+	// Variables are the contents of the files given with -var-file
+	Variables string
 }
 
 // JobRegisterResponse is used to respond to a job registration
@@ -876,6 +1057,63 @@ type JobPlanRequest struct {
 	WriteRequest
 }
 
+// JobCapacityRequest is used to forecast how many more instances of a task
+// group of the job fit in the cluster.
+type JobCapacityRequest struct {
+	Job       *Job
+	TaskGroup string
+	Limit     int
+	WriteRequest
+}
+
+// JobCapacityResponse is the response of a capacity forecast
+type JobCapacityResponse struct {
+	Capacity *TaskGroupCapacity
+	Warnings string
+	WriteMeta
+}
+
+// TaskGroupCapacity is the forecast of how many more instances of a task group
+// fit in the cluster and where they would be placed.
+type TaskGroupCapacity struct {
+	TaskGroup         string
+	Placed            int
+	LimitReached      bool
+	ByDatacenter      map[string]int
+	ByNodeClass       map[string]int
+	ByNode            map[string]int
+	LimitingDimension string
+	Metrics           *AllocationMetric
+}
+
+// JobExplanation is the diagnosis of why the allocations of a job are not
+// placed.
+type JobExplanation struct {
+	JobID                       string
+	Namespace                   string
+	JobStatus                   string
+	EvalID                      string
+	EvalStatus                  string
+	EvalStatusDescription       string
+	BlockedEvalID               string
+	ClassEligibility            map[string]bool
+	EscapedComputedClass        bool
+	DeploymentID                string
+	DeploymentStatus            string
+	DeploymentStatusDescription string
+	UnplacedAllocations         map[string]int
+	Reasons                     []*JobExplainReason
+}
+
+// JobExplainReason is a single reason why allocations of a job are not placed.
+// The reasons of an explanation are ordered by relevance.
+type JobExplainReason struct {
+	Type      string
+	TaskGroup string
+	Message   string
+	Nodes     int
+}
+
 type JobPlanResponse struct {
 	JobModifyIndex     uint64
 	CreatedEvals       []*Evaluation
@@ -943,9 +1181,10 @@ type DesiredUpdates struct {
 }
 
 type JobDispatchRequest struct {
-	JobID   string
-	Payload []byte
-	Meta    map[string]string
+	JobID            string
+	Payload          []byte
+	Meta             map[string]string
+	IdempotencyToken string
 }
 
 type JobDispatchResponse struct {
@@ -953,6 +1192,7 @@ type JobDispatchResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
 	JobCreateIndex  uint64
+	Queued          bool
 	WriteMeta
 }
 
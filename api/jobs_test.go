@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -103,23 +104,27 @@ func TestJobs_Canonicalize(t *testing.T) {
 				},
 			},
 			expected: &Job{
-				ID:                helper.StringToPtr(""),
-				Name:              helper.StringToPtr(""),
-				Region:            helper.StringToPtr("global"),
-				Namespace:         helper.StringToPtr(DefaultNamespace),
-				Type:              helper.StringToPtr("service"),
-				ParentID:          helper.StringToPtr(""),
-				Priority:          helper.IntToPtr(50),
-				AllAtOnce:         helper.BoolToPtr(false),
-				VaultToken:        helper.StringToPtr(""),
-				Status:            helper.StringToPtr(""),
-				StatusDescription: helper.StringToPtr(""),
-				Stop:              helper.BoolToPtr(false),
-				Stable:            helper.BoolToPtr(false),
-				Version:           helper.Uint64ToPtr(0),
-				CreateIndex:       helper.Uint64ToPtr(0),
-				ModifyIndex:       helper.Uint64ToPtr(0),
-				JobModifyIndex:    helper.Uint64ToPtr(0),
+				ID:                       helper.StringToPtr(""),
+				Name:                     helper.StringToPtr(""),
+				Region:                   helper.StringToPtr("global"),
+				Namespace:                helper.StringToPtr(DefaultNamespace),
+				NodePool:                 helper.StringToPtr(NodePoolDefault),
+				SchedulerAlgorithm:       helper.StringToPtr(""),
+				DispatchIdempotencyToken: helper.StringToPtr(""),
+				Type:                     helper.StringToPtr("service"),
+				ParentID:                 helper.StringToPtr(""),
+				Priority:                 helper.IntToPtr(50),
+				AllAtOnce:                helper.BoolToPtr(false),
+				VaultToken:               helper.StringToPtr(""),
+				ConsulToken:              helper.StringToPtr(""),
+				Status:                   helper.StringToPtr(""),
+				StatusDescription:        helper.StringToPtr(""),
+				Stop:                     helper.BoolToPtr(false),
+				Stable:                   helper.BoolToPtr(false),
+				Version:                  helper.Uint64ToPtr(0),
+				CreateIndex:              helper.Uint64ToPtr(0),
+				ModifyIndex:              helper.Uint64ToPtr(0),
+				JobModifyIndex:           helper.Uint64ToPtr(0),
 				TaskGroups: []*TaskGroup{
 					{
 						Name:  helper.StringToPtr(""),
@@ -165,23 +170,27 @@ func TestJobs_Canonicalize(t *testing.T) {
 				},
 			},
 			expected: &Job{
-				Namespace:         helper.StringToPtr("bar"),
-				ID:                helper.StringToPtr("bar"),
-				Name:              helper.StringToPtr("foo"),
-				Region:            helper.StringToPtr("global"),
-				Type:              helper.StringToPtr("service"),
-				ParentID:          helper.StringToPtr("lol"),
-				Priority:          helper.IntToPtr(50),
-				AllAtOnce:         helper.BoolToPtr(false),
-				VaultToken:        helper.StringToPtr(""),
-				Stop:              helper.BoolToPtr(false),
-				Stable:            helper.BoolToPtr(false),
-				Version:           helper.Uint64ToPtr(0),
-				Status:            helper.StringToPtr(""),
-				StatusDescription: helper.StringToPtr(""),
-				CreateIndex:       helper.Uint64ToPtr(0),
-				ModifyIndex:       helper.Uint64ToPtr(0),
-				JobModifyIndex:    helper.Uint64ToPtr(0),
+				Namespace:                helper.StringToPtr("bar"),
+				NodePool:                 helper.StringToPtr(NodePoolDefault),
+				SchedulerAlgorithm:       helper.StringToPtr(""),
+				DispatchIdempotencyToken: helper.StringToPtr(""),
+				ID:                       helper.StringToPtr("bar"),
+				Name:                     helper.StringToPtr("foo"),
+				Region:                   helper.StringToPtr("global"),
+				Type:                     helper.StringToPtr("service"),
+				ParentID:                 helper.StringToPtr("lol"),
+				Priority:                 helper.IntToPtr(50),
+				AllAtOnce:                helper.BoolToPtr(false),
+				VaultToken:               helper.StringToPtr(""),
+				ConsulToken:              helper.StringToPtr(""),
+				Stop:                     helper.BoolToPtr(false),
+				Stable:                   helper.BoolToPtr(false),
+				Version:                  helper.Uint64ToPtr(0),
+				Status:                   helper.StringToPtr(""),
+				StatusDescription:        helper.StringToPtr(""),
+				CreateIndex:              helper.Uint64ToPtr(0),
+				ModifyIndex:              helper.Uint64ToPtr(0),
+				JobModifyIndex:           helper.Uint64ToPtr(0),
 				TaskGroups: []*TaskGroup{
 					{
 						Name:  helper.StringToPtr("bar"),
@@ -289,24 +298,28 @@ func TestJobs_Canonicalize(t *testing.T) {
 				},
 			},
 			expected: &Job{
-				Namespace:         helper.StringToPtr(DefaultNamespace),
-				ID:                helper.StringToPtr("example_template"),
-				Name:              helper.StringToPtr("example_template"),
-				ParentID:          helper.StringToPtr(""),
-				Priority:          helper.IntToPtr(50),
-				Region:            helper.StringToPtr("global"),
-				Type:              helper.StringToPtr("service"),
-				AllAtOnce:         helper.BoolToPtr(false),
-				VaultToken:        helper.StringToPtr(""),
-				Stop:              helper.BoolToPtr(false),
-				Stable:            helper.BoolToPtr(false),
-				Version:           helper.Uint64ToPtr(0),
-				Status:            helper.StringToPtr(""),
-				StatusDescription: helper.StringToPtr(""),
-				CreateIndex:       helper.Uint64ToPtr(0),
-				ModifyIndex:       helper.Uint64ToPtr(0),
-				JobModifyIndex:    helper.Uint64ToPtr(0),
-				Datacenters:       []string{"dc1"},
+				Namespace:                helper.StringToPtr(DefaultNamespace),
+				NodePool:                 helper.StringToPtr(NodePoolDefault),
+				SchedulerAlgorithm:       helper.StringToPtr(""),
+				DispatchIdempotencyToken: helper.StringToPtr(""),
+				ID:                       helper.StringToPtr("example_template"),
+				Name:                     helper.StringToPtr("example_template"),
+				ParentID:                 helper.StringToPtr(""),
+				Priority:                 helper.IntToPtr(50),
+				Region:                   helper.StringToPtr("global"),
+				Type:                     helper.StringToPtr("service"),
+				AllAtOnce:                helper.BoolToPtr(false),
+				VaultToken:               helper.StringToPtr(""),
+				ConsulToken:              helper.StringToPtr(""),
+				Stop:                     helper.BoolToPtr(false),
+				Stable:                   helper.BoolToPtr(false),
+				Version:                  helper.Uint64ToPtr(0),
+				Status:                   helper.StringToPtr(""),
+				StatusDescription:        helper.StringToPtr(""),
+				CreateIndex:              helper.Uint64ToPtr(0),
+				ModifyIndex:              helper.Uint64ToPtr(0),
+				JobModifyIndex:           helper.Uint64ToPtr(0),
+				Datacenters:              []string{"dc1"},
 				Update: &UpdateStrategy{
 					Stagger:         helper.TimeToPtr(30 * time.Second),
 					MaxParallel:     helper.IntToPtr(1),
@@ -352,9 +365,11 @@ func TestJobs_Canonicalize(t *testing.T) {
 									},
 								},
 								Resources: &Resources{
-									CPU:      helper.IntToPtr(500),
-									MemoryMB: helper.IntToPtr(256),
-									IOPS:     helper.IntToPtr(0),
+									CPU:         helper.IntToPtr(500),
+									MemoryMB:    helper.IntToPtr(256),
+									MemoryMaxMB: helper.IntToPtr(0),
+									IOPS:        helper.IntToPtr(0),
+									Cores:       helper.IntToPtr(0),
 									Networks: []*NetworkResource{
 										{
 											MBits: helper.IntToPtr(10),
@@ -426,29 +441,36 @@ func TestJobs_Canonicalize(t *testing.T) {
 				Periodic: &PeriodicConfig{},
 			},
 			expected: &Job{
-				Namespace:         helper.StringToPtr(DefaultNamespace),
-				ID:                helper.StringToPtr("bar"),
-				ParentID:          helper.StringToPtr(""),
-				Name:              helper.StringToPtr("bar"),
-				Region:            helper.StringToPtr("global"),
-				Type:              helper.StringToPtr("service"),
-				Priority:          helper.IntToPtr(50),
-				AllAtOnce:         helper.BoolToPtr(false),
-				VaultToken:        helper.StringToPtr(""),
-				Stop:              helper.BoolToPtr(false),
-				Stable:            helper.BoolToPtr(false),
-				Version:           helper.Uint64ToPtr(0),
-				Status:            helper.StringToPtr(""),
-				StatusDescription: helper.StringToPtr(""),
-				CreateIndex:       helper.Uint64ToPtr(0),
-				ModifyIndex:       helper.Uint64ToPtr(0),
-				JobModifyIndex:    helper.Uint64ToPtr(0),
+				Namespace:                helper.StringToPtr(DefaultNamespace),
+				NodePool:                 helper.StringToPtr(NodePoolDefault),
+				SchedulerAlgorithm:       helper.StringToPtr(""),
+				DispatchIdempotencyToken: helper.StringToPtr(""),
+				ID:                       helper.StringToPtr("bar"),
+				ParentID:                 helper.StringToPtr(""),
+				Name:                     helper.StringToPtr("bar"),
+				Region:                   helper.StringToPtr("global"),
+				Type:                     helper.StringToPtr("service"),
+				Priority:                 helper.IntToPtr(50),
+				AllAtOnce:                helper.BoolToPtr(false),
+				VaultToken:               helper.StringToPtr(""),
+				ConsulToken:              helper.StringToPtr(""),
+				Stop:                     helper.BoolToPtr(false),
+				Stable:                   helper.BoolToPtr(false),
+				Version:                  helper.Uint64ToPtr(0),
+				Status:                   helper.StringToPtr(""),
+				StatusDescription:        helper.StringToPtr(""),
+				CreateIndex:              helper.Uint64ToPtr(0),
+				ModifyIndex:              helper.Uint64ToPtr(0),
+				JobModifyIndex:           helper.Uint64ToPtr(0),
 				Periodic: &PeriodicConfig{
 					Enabled:         helper.BoolToPtr(true),
 					Spec:            helper.StringToPtr(""),
 					SpecType:        helper.StringToPtr(PeriodicSpecCron),
 					ProhibitOverlap: helper.BoolToPtr(false),
 					TimeZone:        helper.StringToPtr("UTC"),
+					CatchUp:         helper.StringToPtr(PeriodicCatchUpLast),
+					CatchUpWindow:   helper.TimeToPtr(0),
+					Jitter:          helper.TimeToPtr(0),
 				},
 			},
 		},
@@ -496,23 +518,27 @@ func TestJobs_Canonicalize(t *testing.T) {
 				},
 			},
 			expected: &Job{
-				Namespace:         helper.StringToPtr(DefaultNamespace),
-				ID:                helper.StringToPtr("bar"),
-				Name:              helper.StringToPtr("foo"),
-				Region:            helper.StringToPtr("global"),
-				Type:              helper.StringToPtr("service"),
-				ParentID:          helper.StringToPtr("lol"),
-				Priority:          helper.IntToPtr(50),
-				AllAtOnce:         helper.BoolToPtr(false),
-				VaultToken:        helper.StringToPtr(""),
-				Stop:              helper.BoolToPtr(false),
-				Stable:            helper.BoolToPtr(false),
-				Version:           helper.Uint64ToPtr(0),
-				Status:            helper.StringToPtr(""),
-				StatusDescription: helper.StringToPtr(""),
-				CreateIndex:       helper.Uint64ToPtr(0),
-				ModifyIndex:       helper.Uint64ToPtr(0),
-				JobModifyIndex:    helper.Uint64ToPtr(0),
+				Namespace:                helper.StringToPtr(DefaultNamespace),
+				NodePool:                 helper.StringToPtr(NodePoolDefault),
+				SchedulerAlgorithm:       helper.StringToPtr(""),
+				DispatchIdempotencyToken: helper.StringToPtr(""),
+				ID:                       helper.StringToPtr("bar"),
+				Name:                     helper.StringToPtr("foo"),
+				Region:                   helper.StringToPtr("global"),
+				Type:                     helper.StringToPtr("service"),
+				ParentID:                 helper.StringToPtr("lol"),
+				Priority:                 helper.IntToPtr(50),
+				AllAtOnce:                helper.BoolToPtr(false),
+				VaultToken:               helper.StringToPtr(""),
+				ConsulToken:              helper.StringToPtr(""),
+				Stop:                     helper.BoolToPtr(false),
+				Stable:                   helper.BoolToPtr(false),
+				Version:                  helper.Uint64ToPtr(0),
+				Status:                   helper.StringToPtr(""),
+				StatusDescription:        helper.StringToPtr(""),
+				CreateIndex:              helper.Uint64ToPtr(0),
+				ModifyIndex:              helper.Uint64ToPtr(0),
+				JobModifyIndex:           helper.Uint64ToPtr(0),
 				Update: &UpdateStrategy{
 					Stagger:         helper.TimeToPtr(1 * time.Second),
 					MaxParallel:     helper.IntToPtr(1),
@@ -788,6 +814,45 @@ func TestJobs_Versions(t *testing.T) {
 	}
 }
 
+func TestJobs_Submission(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Register the job with its source
+	job := testJob()
+	opts := &RegisterOptions{
+		Submission: &JobSubmission{
+			Source: `job "job1" {}`,
+			Format: "hcl2",
+		},
+	}
+	_, wm, err := jobs.RegisterOpts(job, opts, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// Query the source of the job
+	result, qm, err := jobs.Submission("job1", 0, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+
+	// Check that the result is what we expect
+	if result.Source != opts.Submission.Source || result.Format != "hcl2" {
+		t.Fatalf("expect: %#v, got: %#v", opts.Submission, result)
+	}
+
+	// Retrieving the source of a missing version returns an error
+	_, _, err = jobs.Submission("job1", 1, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
+
 func TestJobs_PrefixList(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
@@ -930,6 +995,78 @@ func TestJobs_Evaluations(t *testing.T) {
 	}
 }
 
+func TestJobs_Capacity(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Forecasting a nil job fails
+	if _, _, err := jobs.Capacity(nil, "", 0, nil); err == nil || !strings.Contains(err.Error(), "nil") {
+		t.Fatalf("expected nil job error, got: %v", err)
+	}
+
+	// Forecast the job against a cluster without clients
+	job := testJob()
+	resp, wm, err := jobs.Capacity(job, "", 0, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	if resp.Capacity == nil || resp.Capacity.TaskGroup != "group1" || resp.Capacity.Placed != 0 {
+		t.Fatalf("bad: %#v", resp.Capacity)
+	}
+	if resp.Capacity.Metrics == nil {
+		t.Fatalf("expected metrics of the failed placement")
+	}
+
+	// The job is not registered
+	if _, _, err := jobs.Info(*job.ID, nil); err == nil {
+		t.Fatalf("expected job to not be registered")
+	}
+}
+
+func TestJobs_Explain(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Explaining a non-existent job fails
+	if _, _, err := jobs.Explain("job1", nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %v", err)
+	}
+
+	// Register a job that can't be placed since there are no clients
+	job := testJob()
+	resp, wm, err := jobs.Register(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// Wait for the evaluation to be processed and explained
+	testutil.WaitForResult(func() (bool, error) {
+		exp, qm, err := jobs.Explain(*job.ID, nil)
+		if err != nil {
+			return false, err
+		}
+		assertQueryMeta(t, qm)
+		if exp.EvalID != resp.EvalID || exp.EvalStatus != "complete" {
+			return false, fmt.Errorf("eval not processed: %#v", exp)
+		}
+		if len(exp.Reasons) == 0 {
+			return false, fmt.Errorf("expected reasons: %#v", exp)
+		}
+		if exp.UnplacedAllocations["group1"] != 1 {
+			return false, fmt.Errorf("bad unplaced allocations: %#v", exp.UnplacedAllocations)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
 func TestJobs_Deregister(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
@@ -1083,6 +1220,57 @@ func TestJobs_PeriodicForce(t *testing.T) {
 	t.Fatalf("evaluation %q missing", evalID)
 }
 
+func TestJobs_PeriodicPause(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Pausing a non-existent job fails
+	_, err := jobs.PeriodicPause("job1", nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+
+	// Create a new job
+	job := testPeriodicJob()
+	_, _, err = jobs.Register(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Pause it
+	wm, err := jobs.PeriodicPause(*job.ID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	launch, qm, err := jobs.PeriodicLaunch(*job.ID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if launch.ID != *job.ID || !launch.Paused {
+		t.Fatalf("bad: %#v", launch)
+	}
+
+	// Resume it
+	wm, err = jobs.PeriodicResume(*job.ID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	launch, _, err = jobs.PeriodicLaunch(*job.ID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if launch.Paused {
+		t.Fatalf("bad: %#v", launch)
+	}
+}
+
 func TestJobs_Plan(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t, nil, nil)
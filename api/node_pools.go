@@ -0,0 +1,109 @@
+package api
+
+import "fmt"
+
+const (
+	// NodePoolAll is the node pool that always includes all nodes.
+	NodePoolAll = "all"
+
+	// NodePoolDefault is the default node pool.
+	NodePoolDefault = "default"
+)
+
+// NodePools is used to query the node pool endpoints.
+type NodePools struct {
+	client *Client
+}
+
+// NodePools returns a new handle on the node pools.
+func (c *Client) NodePools() *NodePools {
+	return &NodePools{client: c}
+}
+
+// List is used to dump all of the node pools.
+func (n *NodePools) List(q *QueryOptions) ([]*NodePool, *QueryMeta, error) {
+	var resp []*NodePool
+	qm, err := n.client.query("/v1/node/pools", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// PrefixList is used to do a prefix List search over node pools.
+func (n *NodePools) PrefixList(prefix string, q *QueryOptions) ([]*NodePool, *QueryMeta, error) {
+	if q == nil {
+		q = &QueryOptions{Prefix: prefix}
+	} else {
+		q.Prefix = prefix
+	}
+
+	return n.List(q)
+}
+
+// Info is used to query a specific node pool.
+func (n *NodePools) Info(name string, q *QueryOptions) (*NodePool, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing node pool name")
+	}
+	var resp NodePool
+	qm, err := n.client.query("/v1/node/pool/"+name, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Register is used to create or update a node pool.
+func (n *NodePools) Register(pool *NodePool, q *WriteOptions) (*WriteMeta, error) {
+	if pool == nil || pool.Name == "" {
+		return nil, fmt.Errorf("missing node pool name")
+	}
+	wm, err := n.client.write("/v1/node/pool/"+pool.Name, pool, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// Delete is used to delete a node pool.
+func (n *NodePools) Delete(name string, q *WriteOptions) (*WriteMeta, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing node pool name")
+	}
+	wm, err := n.client.delete("/v1/node/pool/"+name, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// ListNodes is used to list the nodes in a node pool.
+func (n *NodePools) ListNodes(name string, q *QueryOptions) ([]*NodeListStub, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing node pool name")
+	}
+	var resp []*NodeListStub
+	qm, err := n.client.query("/v1/node/pool/"+name+"/nodes", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// NodePool is used to serialize a node pool.
+type NodePool struct {
+	Name                   string
+	Description            string
+	Meta                   map[string]string
+	SchedulerConfiguration *NodePoolSchedulerConfiguration
+	CreateIndex            uint64
+	ModifyIndex            uint64
+}
+
+// NodePoolSchedulerConfiguration overrides the cluster wide scheduler
+// configuration for the jobs in a node pool.
+type NodePoolSchedulerConfiguration struct {
+	SchedulerAlgorithm            string
+	MemoryOversubscriptionEnabled *bool
+}
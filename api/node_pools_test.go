@@ -0,0 +1,103 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodePools_Register(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	pools := c.NodePools()
+
+	// Create a node pool and register it
+	pool := testNodePool()
+	wm, err := pools.Register(pool, nil)
+	assert.Nil(err)
+	assertWriteMeta(t, wm)
+
+	// Query the pools back out again along with the built-in ones
+	resp, qm, err := pools.List(nil)
+	assert.Nil(err)
+	assertQueryMeta(t, qm)
+	assert.Len(resp, 3)
+
+	// Query the pool by prefix
+	resp, qm, err = pools.PrefixList("test", nil)
+	assert.Nil(err)
+	assertQueryMeta(t, qm)
+	if assert.Len(resp, 1) {
+		assert.Equal(pool.Name, resp[0].Name)
+	}
+}
+
+func TestNodePools_Register_Invalid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	pools := c.NodePools()
+
+	// Create an invalid node pool and register it
+	pool := testNodePool()
+	pool.Name = "*"
+	_, err := pools.Register(pool, nil)
+	assert.NotNil(err)
+}
+
+func TestNodePools_Info(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	pools := c.NodePools()
+
+	// Trying to retrieve a node pool before it exists returns an error
+	_, _, err := pools.Info("test-pool", nil)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "not found")
+
+	// Register the node pool
+	pool := testNodePool()
+	wm, err := pools.Register(pool, nil)
+	assert.Nil(err)
+	assertWriteMeta(t, wm)
+
+	// Query the node pool again and ensure it exists
+	result, qm, err := pools.Info(pool.Name, nil)
+	assert.Nil(err)
+	assertQueryMeta(t, qm)
+	assert.NotNil(result)
+	assert.Equal(pool.Description, result.Description)
+}
+
+func TestNodePools_Delete(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	pools := c.NodePools()
+
+	// Register a node pool
+	pool := testNodePool()
+	wm, err := pools.Register(pool, nil)
+	assert.Nil(err)
+	assertWriteMeta(t, wm)
+
+	// Delete the node pool
+	wm, err = pools.Delete(pool.Name, nil)
+	assert.Nil(err)
+	assertWriteMeta(t, wm)
+
+	// Built-in node pools can't be deleted
+	_, err = pools.Delete(NodePoolDefault, nil)
+	assert.NotNil(err)
+
+	// Only the built-in pools remain
+	resp, _, err := pools.List(nil)
+	assert.Nil(err)
+	assert.Len(resp, 2)
+}
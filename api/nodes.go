@@ -94,6 +94,52 @@ func (n *Nodes) GC(nodeID string, q *QueryOptions) error {
 	return err
 }
 
+// Meta returns the static and dynamic metadata of the given node.
+func (n *Nodes) Meta(nodeID string, q *QueryOptions) (*NodeMetaResponse, error) {
+	nodeClient, err := n.client.GetNodeClient(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	var resp NodeMetaResponse
+	if _, err := nodeClient.query("/v1/client/metadata", &resp, nil); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ApplyMeta updates the dynamic metadata of the given node. A nil value
+// removes the key from the node's metadata.
+func (n *Nodes) ApplyMeta(nodeID string, meta map[string]*string, q *QueryOptions) (*NodeMetaResponse, error) {
+	nodeClient, err := n.client.GetNodeClient(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	req := &NodeMetaApplyRequest{Meta: meta}
+	var resp NodeMetaResponse
+	if _, err := nodeClient.write("/v1/client/metadata", req, &resp, nil); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NodeMetaApplyRequest is used to update the dynamic metadata of a node.
+type NodeMetaApplyRequest struct {
+	Meta map[string]*string
+}
+
+// NodeMetaResponse contains the metadata of a node.
+type NodeMetaResponse struct {
+	// Meta is the merged metadata of the node
+	Meta map[string]string
+
+	// Static is the metadata set in the client configuration
+	Static map[string]string
+
+	// Dynamic is the metadata set at runtime. A nil value marks a key that
+	// has been removed.
+	Dynamic map[string]*string
+}
+
 // Node is used to deserialize a node entry.
 type Node struct {
 	ID                string
@@ -107,6 +153,7 @@ type Node struct {
 	Links             map[string]string
 	Meta              map[string]string
 	NodeClass         string
+	NodePool          string
 	Drain             bool
 	Status            string
 	StatusDescription string
@@ -155,6 +202,7 @@ type NodeListStub struct {
 	Datacenter        string
 	Name              string
 	NodeClass         string
+	NodePool          string
 	Version           string
 	Drain             bool
 	Status            string
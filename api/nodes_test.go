@@ -257,6 +257,59 @@ func TestNodes_ForceEvaluate(t *testing.T) {
 	}
 }
 
+func TestNodes_Meta(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	nodes := c.Nodes()
+
+	// Wait for node registration and get the ID
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		out, _, err := nodes.List(nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(out); n != 1 {
+			return false, fmt.Errorf("expected 1 node, got: %d", n)
+		}
+		nodeID = out[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	// Set a key
+	value := "bar"
+	out, err := nodes.ApplyMeta(nodeID, map[string]*string{"foo": &value}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.Meta["foo"] != "bar" {
+		t.Fatalf("bad: %#v", out.Meta)
+	}
+
+	// Read it back
+	out, err = nodes.Meta(nodeID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v := out.Dynamic["foo"]; v == nil || *v != "bar" {
+		t.Fatalf("bad: %#v", out.Dynamic)
+	}
+
+	// Remove it again
+	out, err = nodes.ApplyMeta(nodeID, map[string]*string{"foo": nil}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := out.Meta["foo"]; ok {
+		t.Fatalf("bad: %#v", out.Meta)
+	}
+}
+
 func TestNodes_Sort(t *testing.T) {
 	t.Parallel()
 	nodes := []*NodeListStub{
@@ -1,5 +1,7 @@
 package api
 
+import "fmt"
+
 // Operator can be used to perform low-level operator tasks for Nomad.
 type Operator struct {
 	c *Client
@@ -85,3 +87,74 @@ func (op *Operator) RaftRemovePeerByAddress(address string, q *WriteOptions) err
 	resp.Body.Close()
 	return nil
 }
+
+// SchedulerConfiguration is the cluster wide configuration of the schedulers.
+type SchedulerConfiguration struct {
+	// SchedulerAlgorithm is the scoring algorithm used by the schedulers,
+	// either "binpack" or "spread". Empty means "binpack".
+	SchedulerAlgorithm string
+
+	// MemoryOversubscriptionEnabled allows tasks to use memory up to their
+	// memory_max when set.
+	MemoryOversubscriptionEnabled bool
+
+	// CreateIndex/ModifyIndex store the create/modify indexes of this
+	// configuration.
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// SchedulerConfigurationResponse is returned when querying for the current
+// scheduler configuration.
+type SchedulerConfigurationResponse struct {
+	// SchedulerConfig contains the scheduler configuration. It is nil if the
+	// configuration has never been set.
+	SchedulerConfig *SchedulerConfiguration
+
+	QueryMeta
+}
+
+// SchedulerSetConfigurationResponse is returned when updating the scheduler
+// configuration.
+type SchedulerSetConfigurationResponse struct {
+	// Updated is false if a check-and-set update was not applied because the
+	// configuration was modified concurrently.
+	Updated bool
+
+	WriteMeta
+}
+
+// SchedulerGetConfiguration is used to query the current scheduler
+// configuration.
+func (op *Operator) SchedulerGetConfiguration(q *QueryOptions) (*SchedulerConfigurationResponse, *QueryMeta, error) {
+	var resp SchedulerConfigurationResponse
+	qm, err := op.c.query("/v1/operator/scheduler/configuration", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// SchedulerSetConfiguration is used to set the current scheduler
+// configuration.
+func (op *Operator) SchedulerSetConfiguration(conf *SchedulerConfiguration, q *WriteOptions) (*SchedulerSetConfigurationResponse, *WriteMeta, error) {
+	var out SchedulerSetConfigurationResponse
+	wm, err := op.c.write("/v1/operator/scheduler/configuration", conf, &out, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
+// SchedulerCASConfiguration is used to perform a check-and-set update of the
+// scheduler configuration. The update is only applied if the configuration's
+// ModifyIndex matches the current one; the response reports whether it was.
+func (op *Operator) SchedulerCASConfiguration(conf *SchedulerConfiguration, q *WriteOptions) (*SchedulerSetConfigurationResponse, *WriteMeta, error) {
+	var out SchedulerSetConfigurationResponse
+	path := fmt.Sprintf("/v1/operator/scheduler/configuration?cas=%d", conf.ModifyIndex)
+	wm, err := op.c.write(path, conf, &out, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
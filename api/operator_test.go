@@ -36,3 +36,53 @@ func TestOperator_RaftRemovePeerByAddress(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 }
+
+func TestOperator_SchedulerConfiguration(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	// Set the configuration
+	operator := c.Operator()
+	conf := &SchedulerConfiguration{
+		SchedulerAlgorithm: "spread",
+	}
+	resp, _, err := operator.SchedulerSetConfiguration(conf, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !resp.Updated {
+		t.Fatalf("configuration was not updated")
+	}
+
+	// Read it back
+	out, _, err := operator.SchedulerGetConfiguration(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SchedulerConfig == nil || out.SchedulerConfig.SchedulerAlgorithm != "spread" {
+		t.Fatalf("bad: %#v", out.SchedulerConfig)
+	}
+
+	// A check-and-set update with a stale index is not applied
+	conf = out.SchedulerConfig
+	conf.SchedulerAlgorithm = "binpack"
+	conf.ModifyIndex--
+	resp, _, err = operator.SchedulerCASConfiguration(conf, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Updated {
+		t.Fatalf("stale update was applied")
+	}
+
+	// A check-and-set update with the current index is applied
+	conf.ModifyIndex++
+	resp, _, err = operator.SchedulerCASConfiguration(conf, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !resp.Updated {
+		t.Fatalf("configuration was not updated")
+	}
+}
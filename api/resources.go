@@ -5,11 +5,14 @@ import "github.com/hashicorp/nomad/helper"
 // Resources encapsulates the required resources of
 // a given task or task group.
 type Resources struct {
-	CPU      *int
-	MemoryMB *int `mapstructure:"memory"`
-	DiskMB   *int `mapstructure:"disk"`
-	IOPS     *int
-	Networks []*NetworkResource
+	CPU         *int
+	MemoryMB    *int `mapstructure:"memory"`
+	MemoryMaxMB *int `mapstructure:"memory_max"`
+	DiskMB      *int `mapstructure:"disk"`
+	IOPS        *int
+	Networks    []*NetworkResource
+	Cores       *int
+	CoreIDs     []uint16
 }
 
 func (r *Resources) Canonicalize() {
@@ -19,9 +22,15 @@ func (r *Resources) Canonicalize() {
 	if r.MemoryMB == nil {
 		r.MemoryMB = helper.IntToPtr(10)
 	}
+	if r.MemoryMaxMB == nil {
+		r.MemoryMaxMB = helper.IntToPtr(0)
+	}
 	if r.IOPS == nil {
 		r.IOPS = helper.IntToPtr(0)
 	}
+	if r.Cores == nil {
+		r.Cores = helper.IntToPtr(0)
+	}
 	for _, n := range r.Networks {
 		n.Canonicalize()
 	}
@@ -29,9 +38,11 @@ func (r *Resources) Canonicalize() {
 
 func MinResources() *Resources {
 	return &Resources{
-		CPU:      helper.IntToPtr(100),
-		MemoryMB: helper.IntToPtr(10),
-		IOPS:     helper.IntToPtr(0),
+		CPU:         helper.IntToPtr(100),
+		MemoryMB:    helper.IntToPtr(10),
+		MemoryMaxMB: helper.IntToPtr(0),
+		IOPS:        helper.IntToPtr(0),
+		Cores:       helper.IntToPtr(0),
 	}
 
 }
@@ -47,6 +58,9 @@ func (r *Resources) Merge(other *Resources) {
 	if other.MemoryMB != nil {
 		r.MemoryMB = other.MemoryMB
 	}
+	if other.MemoryMaxMB != nil {
+		r.MemoryMaxMB = other.MemoryMaxMB
+	}
 	if other.DiskMB != nil {
 		r.DiskMB = other.DiskMB
 	}
@@ -56,6 +70,9 @@ func (r *Resources) Merge(other *Resources) {
 	if len(other.Networks) != 0 {
 		r.Networks = other.Networks
 	}
+	if other.Cores != nil {
+		r.Cores = other.Cores
+	}
 }
 
 type Port struct {
@@ -72,6 +89,7 @@ type NetworkResource struct {
 	MBits         *int
 	ReservedPorts []Port
 	DynamicPorts  []Port
+	Mode          string
 }
 
 func (n *NetworkResource) Canonicalize() {
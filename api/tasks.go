@@ -45,6 +45,15 @@ type TaskResourceUsage struct {
 	ResourceUsage *ResourceUsage
 	Timestamp     int64
 	Pids          map[string]*ResourceUsage
+	LogShippers   map[string]*LogShipperStats
+}
+
+// LogShipperStats holds the delivery statistics of a task's log shipper
+type LogShipperStats struct {
+	Shipped  uint64
+	Dropped  uint64
+	Failed   uint64
+	Buffered int
 }
 
 // AllocResourceUsage holds the aggregated task resource usage of the
@@ -162,6 +171,8 @@ type ServiceCheck struct {
 	Header        map[string][]string
 	Method        string
 	CheckRestart  *CheckRestart `mapstructure:"check_restart"`
+	GRPCService   string        `mapstructure:"grpc_service"`
+	GRPCUseTLS    bool          `mapstructure:"grpc_use_tls"`
 }
 
 // The Service model represents a Consul service definition
@@ -222,16 +233,35 @@ func (e *EphemeralDisk) Canonicalize() {
 	}
 }
 
+// TaskGroupDependency delays the placement of a task group until another task
+// group in the job reaches the given condition.
+type TaskGroupDependency struct {
+	Group     *string
+	Condition *string
+}
+
+func (d *TaskGroupDependency) Canonicalize() {
+	if d.Group == nil {
+		d.Group = helper.StringToPtr("")
+	}
+	if d.Condition == nil {
+		d.Condition = helper.StringToPtr("running")
+	}
+}
+
 // TaskGroup is the unit of scheduling.
 type TaskGroup struct {
-	Name          *string
-	Count         *int
-	Constraints   []*Constraint
-	Tasks         []*Task
-	RestartPolicy *RestartPolicy
-	EphemeralDisk *EphemeralDisk
-	Update        *UpdateStrategy
-	Meta          map[string]string
+	Name                *string
+	Count               *int
+	Constraints         []*Constraint
+	Tasks               []*Task
+	RestartPolicy       *RestartPolicy
+	EphemeralDisk       *EphemeralDisk
+	Update              *UpdateStrategy
+	Networks            []*NetworkResource
+	DependsOn           []*TaskGroupDependency `mapstructure:"depends_on"`
+	MaxClientDisconnect *time.Duration         `mapstructure:"max_client_disconnect"`
+	Meta                map[string]string
 }
 
 // NewTaskGroup creates a new TaskGroup.
@@ -258,6 +288,14 @@ func (g *TaskGroup) Canonicalize(job *Job) {
 		g.EphemeralDisk.Canonicalize()
 	}
 
+	for _, n := range g.Networks {
+		n.Canonicalize()
+	}
+
+	for _, d := range g.DependsOn {
+		d.Canonicalize()
+	}
+
 	// Merge the update policy from the job
 	if ju, tu := job.Update != nil, g.Update != nil; ju && tu {
 		// Merge the jobs and task groups definition of the update strategy
@@ -319,6 +357,16 @@ func (g *TaskGroup) AddTask(t *Task) *TaskGroup {
 	return g
 }
 
+// DependOn delays placing the task group until the named task group reaches
+// the given condition.
+func (g *TaskGroup) DependOn(group, condition string) *TaskGroup {
+	g.DependsOn = append(g.DependsOn, &TaskGroupDependency{
+		Group:     helper.StringToPtr(group),
+		Condition: helper.StringToPtr(condition),
+	})
+	return g
+}
+
 // RequireDisk adds a ephemeral disk to the task group
 func (g *TaskGroup) RequireDisk(disk *EphemeralDisk) *TaskGroup {
 	g.EphemeralDisk = disk
@@ -327,14 +375,20 @@ func (g *TaskGroup) RequireDisk(disk *EphemeralDisk) *TaskGroup {
 
 // LogConfig provides configuration for log rotation
 type LogConfig struct {
-	MaxFiles      *int `mapstructure:"max_files"`
-	MaxFileSizeMB *int `mapstructure:"max_file_size"`
+	MaxFiles         *int           `mapstructure:"max_files"`
+	MaxFileSizeMB    *int           `mapstructure:"max_file_size"`
+	RotationInterval *time.Duration `mapstructure:"rotation_interval"`
+	Compress         *bool          `mapstructure:"compress"`
+	MaxTotalSizeMB   *int           `mapstructure:"max_total_size"`
 }
 
 func DefaultLogConfig() *LogConfig {
 	return &LogConfig{
-		MaxFiles:      helper.IntToPtr(10),
-		MaxFileSizeMB: helper.IntToPtr(10),
+		MaxFiles:         helper.IntToPtr(10),
+		MaxFileSizeMB:    helper.IntToPtr(10),
+		RotationInterval: helper.TimeToPtr(0),
+		Compress:         helper.BoolToPtr(false),
+		MaxTotalSizeMB:   helper.IntToPtr(0),
 	}
 }
 
@@ -345,6 +399,45 @@ func (l *LogConfig) Canonicalize() {
 	if l.MaxFileSizeMB == nil {
 		l.MaxFileSizeMB = helper.IntToPtr(10)
 	}
+	if l.RotationInterval == nil {
+		l.RotationInterval = helper.TimeToPtr(0)
+	}
+	if l.Compress == nil {
+		l.Compress = helper.BoolToPtr(false)
+	}
+	if l.MaxTotalSizeMB == nil {
+		l.MaxTotalSizeMB = helper.IntToPtr(0)
+	}
+}
+
+// LogShipper ships the task's stdout and stderr to a sink in addition to the
+// rotated log files
+type LogShipper struct {
+	Name       string
+	Type       string
+	Address    *string
+	Protocol   *string
+	BufferSize *int `mapstructure:"buffer_size"`
+}
+
+func (l *LogShipper) Canonicalize() {
+	if l.BufferSize == nil {
+		l.BufferSize = helper.IntToPtr(1024)
+	}
+	if l.Protocol == nil {
+		if l.Type == "syslog" {
+			l.Protocol = helper.StringToPtr("tcp")
+		} else {
+			l.Protocol = helper.StringToPtr("")
+		}
+	}
+	if l.Address == nil {
+		if l.Type == "socket" {
+			l.Address = helper.StringToPtr("/run/systemd/journal/socket")
+		} else {
+			l.Address = helper.StringToPtr("")
+		}
+	}
 }
 
 // DispatchPayloadConfig configures how a task gets its input from a job dispatch
@@ -365,6 +458,7 @@ type Task struct {
 	Meta            map[string]string
 	KillTimeout     *time.Duration `mapstructure:"kill_timeout"`
 	LogConfig       *LogConfig     `mapstructure:"logs"`
+	LogShippers     []*LogShipper
 	Artifacts       []*TaskArtifact
 	Vault           *Vault
 	Templates       []*Template
@@ -387,6 +481,9 @@ func (t *Task) Canonicalize(tg *TaskGroup, job *Job) {
 	} else {
 		t.LogConfig.Canonicalize()
 	}
+	for _, shipper := range t.LogShippers {
+		shipper.Canonicalize()
+	}
 	for _, artifact := range t.Artifacts {
 		artifact.Canonicalize()
 	}
@@ -491,12 +588,24 @@ func (tmpl *Template) Canonicalize() {
 
 type Vault struct {
 	Policies     []string
+	Role         *string
+	EntityAlias  *string `mapstructure:"entity_alias"`
+	TTL          *time.Duration
 	Env          *bool
 	ChangeMode   *string `mapstructure:"change_mode"`
 	ChangeSignal *string `mapstructure:"change_signal"`
 }
 
 func (v *Vault) Canonicalize() {
+	if v.Role == nil {
+		v.Role = helper.StringToPtr("")
+	}
+	if v.EntityAlias == nil {
+		v.EntityAlias = helper.StringToPtr("")
+	}
+	if v.TTL == nil {
+		v.TTL = helper.TimeToPtr(0)
+	}
 	if v.Env == nil {
 		v.Env = helper.BoolToPtr(true)
 	}
@@ -547,6 +656,12 @@ func (t *Task) Constrain(c *Constraint) *Task {
 	return t
 }
 
+// AddLogShipper adds a log shipper to a task
+func (t *Task) AddLogShipper(l *LogShipper) *Task {
+	t.LogShippers = append(t.LogShippers, l)
+	return t
+}
+
 // SetLogConfig sets a log config to a task
 func (t *Task) SetLogConfig(l *LogConfig) *Task {
 	t.LogConfig = l
@@ -581,6 +696,7 @@ const (
 	TaskDownloadingArtifacts   = "Downloading Artifacts"
 	TaskArtifactDownloadFailed = "Failed Artifact Download"
 	TaskSiblingFailed          = "Sibling Task Failed"
+	TaskDiskExceeded           = "Disk Resources Exceeded"
 	TaskSignaling              = "Signaling"
 	TaskRestartSignal          = "Restart Signaled"
 	TaskLeaderDead             = "Leader Task Dead"
@@ -245,6 +245,20 @@ func TestTask_Artifact(t *testing.T) {
 	}
 }
 
+func TestTask_LogShipper_Canonicalize(t *testing.T) {
+	t.Parallel()
+	syslog := &LogShipper{Name: "audit", Type: "syslog", Address: helper.StringToPtr("10.0.0.1:514")}
+	syslog.Canonicalize()
+	assert.Equal(t, "tcp", *syslog.Protocol)
+	assert.Equal(t, 1024, *syslog.BufferSize)
+
+	socket := &LogShipper{Name: "journal", Type: "socket", BufferSize: helper.IntToPtr(10)}
+	socket.Canonicalize()
+	assert.Equal(t, "/run/systemd/journal/socket", *socket.Address)
+	assert.Equal(t, "", *socket.Protocol)
+	assert.Equal(t, 10, *socket.BufferSize)
+}
+
 // Ensures no regression on https://github.com/hashicorp/nomad/issues/3132
 func TestTaskGroup_Canonicalize_Update(t *testing.T) {
 	job := &Job{
@@ -62,3 +62,10 @@ func testNamespace() *Namespace {
 		Description: "Testing namespaces",
 	}
 }
+
+func testNodePool() *NodePool {
+	return &NodePool{
+		Name:        "test-pool",
+		Description: "Testing node pools",
+	}
+}
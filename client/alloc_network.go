@@ -0,0 +1,28 @@
+package client
+
+import (
+	"log"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// groupNetworkManager returns the DriverNetworkManager of the first driver in
+// the task group that must create the group's network namespace itself. If no
+// driver requires it, nil is returned and the client creates the namespace.
+func groupNetworkManager(tg *structs.TaskGroup, allocID string, conf *config.Config,
+	logger *log.Logger) (driver.DriverNetworkManager, error) {
+
+	for _, task := range tg.Tasks {
+		ctx := driver.NewDriverContext(task.Name, allocID, conf, conf.Node, logger, nil)
+		d, err := driver.NewDriver(task.Driver, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if nm, ok := d.(driver.DriverNetworkManager); ok {
+			return nm, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,26 @@
+// +build !linux
+
+package client
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/nomad/client/driver"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// setupAllocNetwork returns an error as group network namespaces are only
+// supported on Linux.
+func setupAllocNetwork(logger *log.Logger, alloc *structs.Allocation, tg *structs.TaskGroup,
+	nm driver.DriverNetworkManager) (*cstructs.NetworkIsolationSpec, error) {
+	return nil, fmt.Errorf("%s network mode is only supported on Linux", tg.NetworkMode())
+}
+
+func teardownAllocNetwork(logger *log.Logger, alloc *structs.Allocation, spec *cstructs.NetworkIsolationSpec,
+	nm driver.DriverNetworkManager) error {
+	return nil
+}
+
+func restoreAllocNetwork(spec *cstructs.NetworkIsolationSpec) {}
@@ -0,0 +1,359 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/nomad/client/driver"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// bridgeName is the name of the bridge allocations in bridge networking
+	// mode are connected to.
+	bridgeName = "nomad"
+
+	// bridgeSubnet is the subnet addresses are allocated from for bridge
+	// networking. The first address is used by the bridge.
+	bridgeSubnet = "172.26.64.0/20"
+
+	// bridgeNATChain is the iptables nat chain port mappings are added to.
+	bridgeNATChain = "NOMAD"
+
+	// netnsDir is the directory named network namespaces are created in.
+	netnsDir = "/var/run/netns"
+)
+
+// bridgeIPs tracks the addresses allocated to network namespaces on the
+// bridge. It is shared by all allocations on the client.
+var bridgeIPs = newBridgeIPAM(bridgeSubnet)
+
+// bridgeLock serializes the creation of the bridge and its iptables rules,
+// as allocations starting at the same time would otherwise race to create
+// them.
+var bridgeLock sync.Mutex
+
+// bridgeIPAM is a simple in-memory allocator of addresses in a subnet.
+type bridgeIPAM struct {
+	network *net.IPNet
+	gateway net.IP
+	used    map[string]struct{}
+	l       sync.Mutex
+}
+
+func newBridgeIPAM(subnet string) *bridgeIPAM {
+	_, network, err := net.ParseCIDR(subnet)
+	if err != nil {
+		panic(err)
+	}
+	return &bridgeIPAM{
+		network: network,
+		gateway: ipAdd(network.IP, 1),
+		used:    make(map[string]struct{}),
+	}
+}
+
+// allocate returns a free address in the subnet.
+func (b *bridgeIPAM) allocate() (net.IP, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	ones, bits := b.network.Mask.Size()
+	size := uint32(1) << uint(bits-ones)
+
+	// Skip the network, gateway and broadcast addresses
+	for i := uint32(2); i < size-1; i++ {
+		ip := ipAdd(b.network.IP, i)
+		if _, ok := b.used[ip.String()]; ok {
+			continue
+		}
+		b.used[ip.String()] = struct{}{}
+		return ip, nil
+	}
+	return nil, fmt.Errorf("no addresses available in %s", b.network)
+}
+
+// reserve marks an address as in use.
+func (b *bridgeIPAM) reserve(ip string) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.used[ip] = struct{}{}
+}
+
+// release returns an address to the pool.
+func (b *bridgeIPAM) release(ip string) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	delete(b.used, ip)
+}
+
+// ipAdd returns the IPv4 address n addresses after ip.
+func ipAdd(ip net.IP, n uint32) net.IP {
+	out := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(out, binary.BigEndian.Uint32(ip.To4())+n)
+	return out
+}
+
+// netnsName returns the name of the network namespace of an allocation.
+func netnsName(allocID string) string {
+	return "nomad-" + allocID
+}
+
+// vethName returns the name of the host side of an allocation's veth pair.
+// Interface names are limited to 15 characters.
+func vethName(allocID string) string {
+	return "veth" + allocID[:8]
+}
+
+// setupAllocNetwork creates the network namespace shared by the tasks of the
+// allocation. In bridge mode the namespace is connected to the bridge and the
+// group's ports are forwarded to it.
+func setupAllocNetwork(logger *log.Logger, alloc *structs.Allocation, tg *structs.TaskGroup,
+	nm driver.DriverNetworkManager) (*cstructs.NetworkIsolationSpec, error) {
+
+	mode := tg.NetworkMode()
+	name := netnsName(alloc.ID)
+	spec := &cstructs.NetworkIsolationSpec{
+		Mode: mode,
+		Path: filepath.Join(netnsDir, name),
+	}
+
+	if nm != nil {
+		dspec, err := nm.CreateNetwork(alloc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create network namespace: %v", err)
+		}
+		spec.Labels = dspec.Labels
+		if err := bindNetns(dspec.Path, spec.Path); err != nil {
+			nm.DestroyNetwork(alloc.ID, dspec)
+			return nil, err
+		}
+	} else if err := runNetCmd("ip", "netns", "add", name); err != nil {
+		return nil, err
+	}
+
+	if err := runNetCmd("ip", "-n", name, "link", "set", "lo", "up"); err != nil {
+		teardownAllocNetwork(logger, alloc, spec, nm)
+		return nil, err
+	}
+
+	if mode != structs.NetworkModeBridge {
+		return spec, nil
+	}
+
+	ip, err := bridgeIPs.allocate()
+	if err != nil {
+		teardownAllocNetwork(logger, alloc, spec, nm)
+		return nil, err
+	}
+	spec.IP = ip.String()
+
+	if err := connectBridge(alloc.ID, name, ip); err != nil {
+		teardownAllocNetwork(logger, alloc, spec, nm)
+		return nil, err
+	}
+
+	for _, args := range portMappingRules(alloc, spec.IP) {
+		if err := runNetCmd("iptables", append([]string{"-t", "nat", "-A", bridgeNATChain}, args...)...); err != nil {
+			teardownAllocNetwork(logger, alloc, spec, nm)
+			return nil, err
+		}
+	}
+
+	logger.Printf("[DEBUG] client: created %s network for alloc %q with address %s", mode, alloc.ID, spec.IP)
+	return spec, nil
+}
+
+// teardownAllocNetwork removes the port mappings, interfaces and network
+// namespace created by setupAllocNetwork. Errors are logged and teardown
+// continues so as much as possible is cleaned up.
+func teardownAllocNetwork(logger *log.Logger, alloc *structs.Allocation, spec *cstructs.NetworkIsolationSpec,
+	nm driver.DriverNetworkManager) error {
+
+	var last error
+	if spec.IP != "" {
+		for _, args := range portMappingRules(alloc, spec.IP) {
+			check := append([]string{"-t", "nat", "-C", bridgeNATChain}, args...)
+			if runNetCmd("iptables", check...) != nil {
+				continue
+			}
+			if err := runNetCmd("iptables", append([]string{"-t", "nat", "-D", bridgeNATChain}, args...)...); err != nil {
+				logger.Printf("[WARN] client: alloc %q failed to remove port mapping: %v", alloc.ID, err)
+				last = err
+			}
+		}
+		if err := runNetCmd("ip", "link", "del", vethName(alloc.ID)); err != nil {
+			logger.Printf("[DEBUG] client: alloc %q failed to remove veth: %v", alloc.ID, err)
+		}
+		bridgeIPs.release(spec.IP)
+	}
+
+	if _, err := os.Stat(spec.Path); err == nil {
+		if err := runNetCmd("ip", "netns", "del", netnsName(alloc.ID)); err != nil {
+			logger.Printf("[WARN] client: alloc %q failed to remove network namespace: %v", alloc.ID, err)
+			last = err
+		}
+	}
+
+	if nm != nil && len(spec.Labels) != 0 {
+		if err := nm.DestroyNetwork(alloc.ID, spec); err != nil {
+			logger.Printf("[WARN] client: alloc %q failed to destroy driver network: %v", alloc.ID, err)
+			last = err
+		}
+	}
+	return last
+}
+
+// restoreAllocNetwork marks the address of a restored allocation's network
+// as in use.
+func restoreAllocNetwork(spec *cstructs.NetworkIsolationSpec) {
+	if spec != nil && spec.IP != "" {
+		bridgeIPs.reserve(spec.IP)
+	}
+}
+
+// bindNetns bind mounts the network namespace at src to dst so it can be
+// referenced by name.
+func bindNetns(src, dst string) error {
+	if err := os.MkdirAll(netnsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", netnsDir, err)
+	}
+	if err := ioutil.WriteFile(dst, nil, 0644); err != nil {
+		return fmt.Errorf("failed to create network namespace file: %v", err)
+	}
+	if err := unix.Mount(src, dst, "none", unix.MS_BIND, ""); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to bind mount network namespace %q: %v", src, err)
+	}
+	return nil
+}
+
+// connectBridge connects the network namespace to the bridge using a veth
+// pair, assigning ip to the namespace side.
+func connectBridge(allocID, netns string, ip net.IP) error {
+	if err := ensureBridge(); err != nil {
+		return err
+	}
+
+	ones, _ := bridgeIPs.network.Mask.Size()
+	host := vethName(allocID)
+	peer := "vethp" + allocID[:8]
+	cmds := [][]string{
+		{"link", "add", host, "type", "veth", "peer", "name", peer},
+		{"link", "set", peer, "netns", netns},
+		{"-n", netns, "link", "set", peer, "name", "eth0"},
+		{"-n", netns, "addr", "add", fmt.Sprintf("%s/%d", ip, ones), "dev", "eth0"},
+		{"-n", netns, "link", "set", "eth0", "up"},
+		{"-n", netns, "route", "add", "default", "via", bridgeIPs.gateway.String()},
+		{"link", "set", host, "master", bridgeName},
+		{"link", "set", host, "up"},
+	}
+	for _, args := range cmds {
+		if err := runNetCmd("ip", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureBridge idempotently creates the bridge and the iptables rules needed
+// to NAT traffic leaving it and forward mapped ports into it.
+func ensureBridge() error {
+	bridgeLock.Lock()
+	defer bridgeLock.Unlock()
+
+	if runNetCmd("ip", "link", "show", bridgeName) != nil {
+		if err := runNetCmd("ip", "link", "add", bridgeName, "type", "bridge"); err != nil {
+			return err
+		}
+	}
+
+	ones, _ := bridgeIPs.network.Mask.Size()
+	gateway := fmt.Sprintf("%s/%d", bridgeIPs.gateway, ones)
+	if out, _ := exec.Command("ip", "addr", "show", "dev", bridgeName).CombinedOutput(); !strings.Contains(string(out), gateway) {
+		if err := runNetCmd("ip", "addr", "add", gateway, "dev", bridgeName); err != nil {
+			return err
+		}
+	}
+	if err := runNetCmd("ip", "link", "set", bridgeName, "up"); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte("1"), 0644); err != nil {
+		return fmt.Errorf("failed to enable ip forwarding: %v", err)
+	}
+
+	if runNetCmd("iptables", "-t", "nat", "-L", bridgeNATChain, "-n") != nil {
+		if err := runNetCmd("iptables", "-t", "nat", "-N", bridgeNATChain); err != nil {
+			return err
+		}
+	}
+
+	subnet := bridgeIPs.network.String()
+	rules := [][]string{
+		{"-t", "nat", "POSTROUTING", "-s", subnet, "!", "-o", bridgeName, "-j", "MASQUERADE"},
+		{"-t", "nat", "PREROUTING", "-m", "addrtype", "--dst-type", "LOCAL", "-j", bridgeNATChain},
+		{"-t", "nat", "OUTPUT", "-m", "addrtype", "--dst-type", "LOCAL", "-j", bridgeNATChain},
+		{"-t", "filter", "FORWARD", "-i", bridgeName, "-j", "ACCEPT"},
+		{"-t", "filter", "FORWARD", "-o", bridgeName, "-j", "ACCEPT"},
+	}
+	for _, rule := range rules {
+		table, chain, spec := rule[:2], rule[2], rule[3:]
+		check := append(append(append([]string{}, table...), "-C", chain), spec...)
+		if runNetCmd("iptables", check...) == nil {
+			continue
+		}
+		add := append(append(append([]string{}, table...), "-A", chain), spec...)
+		if err := runNetCmd("iptables", add...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// portMappingRules returns the iptables rule specs forwarding the ports of
+// the allocation's group network to ip.
+func portMappingRules(alloc *structs.Allocation, ip string) [][]string {
+	if alloc.SharedResources == nil || len(alloc.SharedResources.Networks) == 0 {
+		return nil
+	}
+
+	nw := alloc.SharedResources.Networks[0]
+	ports := make([]structs.Port, 0, len(nw.ReservedPorts)+len(nw.DynamicPorts))
+	ports = append(ports, nw.ReservedPorts...)
+	ports = append(ports, nw.DynamicPorts...)
+
+	var rules [][]string
+	for _, p := range ports {
+		port := strconv.Itoa(p.Value)
+		for _, proto := range []string{"tcp", "udp"} {
+			rules = append(rules, []string{
+				"-p", proto, "--dport", port,
+				"-m", "comment", "--comment", "nomad alloc " + alloc.ID,
+				"-j", "DNAT", "--to-destination", net.JoinHostPort(ip, port),
+			})
+		}
+	}
+	return rules
+}
+
+// runNetCmd runs a networking command and returns an error including its
+// output if it fails.
+func runNetCmd(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestBridgeIPAM_Allocate(t *testing.T) {
+	t.Parallel()
+	ipam := newBridgeIPAM("10.0.0.0/30")
+
+	ip, err := ipam.allocate()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ip.String() != "10.0.0.2" {
+		t.Fatalf("bad ip: %v", ip)
+	}
+
+	// The subnet only has one usable address
+	if _, err := ipam.allocate(); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	ipam.release(ip.String())
+	ip, err = ipam.allocate()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ip.String() != "10.0.0.2" {
+		t.Fatalf("bad ip: %v", ip)
+	}
+}
+
+func TestAllocNetwork_PortMappingRules(t *testing.T) {
+	t.Parallel()
+	alloc := mock.Alloc()
+	alloc.SharedResources.Networks = []*structs.NetworkResource{
+		{
+			Mode:          structs.NetworkModeBridge,
+			ReservedPorts: []structs.Port{{Label: "admin", Value: 8080}},
+		},
+	}
+
+	rules := portMappingRules(alloc, "172.26.64.2")
+	comment := "nomad alloc " + alloc.ID
+	expected := [][]string{
+		{"-p", "tcp", "--dport", "8080", "-m", "comment", "--comment", comment, "-j", "DNAT", "--to-destination", "172.26.64.2:8080"},
+		{"-p", "udp", "--dport", "8080", "-m", "comment", "--comment", comment, "-j", "DNAT", "--to-destination", "172.26.64.2:8080"},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("bad rules:\n%#v\nexpected:\n%#v", rules, expected)
+	}
+}
@@ -65,11 +65,25 @@ type AllocRunner struct {
 
 	taskStatusLock sync.RWMutex
 
+	// networkIsolation is the network namespace shared by the tasks when
+	// the task group uses bridge or none networking.
+	networkIsolation     *cstructs.NetworkIsolationSpec
+	networkIsolationLock sync.Mutex
+
+	// sharedCores are the CPU cores that tasks without reserved cores run
+	// on. Empty if tasks are not restricted to a set of cores.
+	sharedCores     []uint16
+	sharedCoresLock sync.Mutex
+
 	updateCh chan *structs.Allocation
 
 	vaultClient  vaultclient.VaultClient
 	consulClient ConsulServiceAPI
 
+	// consulTokenDeriver is passed to the task runners to derive their
+	// Consul tokens.
+	consulTokenDeriver ConsulTokenDeriverFn
+
 	// prevAlloc allows for Waiting until a previous allocation exits and
 	// the migrates it data. If sticky volumes aren't used and there's no
 	// previous allocation a noop implementation is used so it always safe
@@ -139,6 +153,7 @@ type allocRunnerMutableState struct {
 	AllocClientDescription string
 	TaskStates             map[string]*structs.TaskState
 	DeploymentStatus       *structs.AllocDeploymentStatus
+	NetworkIsolation       *cstructs.NetworkIsolationSpec
 }
 
 // NewAllocRunner is used to create a new allocation context
@@ -253,6 +268,7 @@ func (r *AllocRunner) RestoreState() error {
 			r.taskStates = mutable.TaskStates
 			r.alloc.ClientStatus = getClientStatus(r.taskStates)
 			r.alloc.DeploymentStatus = mutable.DeploymentStatus
+			r.networkIsolation = mutable.NetworkIsolation
 			return nil
 		})
 
@@ -277,6 +293,9 @@ func (r *AllocRunner) RestoreState() error {
 		return fmt.Errorf("restored allocation doesn't contain task group %q", r.alloc.TaskGroup)
 	}
 
+	// Reserve the address of the restored network
+	restoreAllocNetwork(r.networkIsolation)
+
 	// Restore the task runners
 	taskDestroyEvent := structs.NewTaskEvent(structs.TaskKilled)
 	var mErr multierror.Error
@@ -308,6 +327,9 @@ func (r *AllocRunner) RestoreState() error {
 		}
 
 		tr := NewTaskRunner(r.logger, r.config, r.stateDB, r.setTaskState, td, r.Alloc(), task, r.vaultClient, r.consulClient)
+		tr.SetNetworkIsolation(r.networkIsolation)
+		tr.SetConsulTokenDeriver(r.consulTokenDeriver)
+		tr.SetSharedCores(r.getSharedCores())
 		r.tasks[name] = tr
 
 		if restartReason, err := tr.RestoreState(); err != nil {
@@ -378,6 +400,10 @@ func (r *AllocRunner) saveAllocRunnerState() error {
 	allocDir := r.allocDir.Copy()
 	r.allocDirLock.Unlock()
 
+	r.networkIsolationLock.Lock()
+	networkIsolation := r.networkIsolation.Copy()
+	r.networkIsolationLock.Unlock()
+
 	// Start the transaction.
 	return r.stateDB.Batch(func(tx *bolt.Tx) error {
 
@@ -439,6 +465,7 @@ func (r *AllocRunner) saveAllocRunnerState() error {
 			AllocClientDescription: allocClientDescription,
 			TaskStates:             alloc.TaskStates,
 			DeploymentStatus:       alloc.DeploymentStatus,
+			NetworkIsolation:       networkIsolation,
 		}
 
 		if err := putObject(allocBkt, allocRunnerStateMutableKey, &mutable); err != nil {
@@ -464,6 +491,11 @@ func (r *AllocRunner) DestroyState() error {
 
 // DestroyContext is used to destroy the context
 func (r *AllocRunner) DestroyContext() error {
+	if r.config.DiskQuotas {
+		if err := r.allocDir.RemoveDiskQuota(); err != nil {
+			r.logger.Printf("[DEBUG] client: alloc %q failed to remove disk quota: %v", r.allocID, err)
+		}
+	}
 	return r.allocDir.Destroy()
 }
 
@@ -487,6 +519,34 @@ func copyTaskStates(states map[string]*structs.TaskState) map[string]*structs.Ta
 	return copy
 }
 
+// SetConsulTokenDeriver sets the function used by the task runners to derive
+// their Consul tokens. It must be called before Run or RestoreState.
+func (r *AllocRunner) SetConsulTokenDeriver(f ConsulTokenDeriverFn) {
+	r.consulTokenDeriver = f
+}
+
+// SetSharedCores sets the CPU cores that tasks without reserved cores run on
+// and moves the running tasks onto them.
+func (r *AllocRunner) SetSharedCores(cores []uint16) {
+	r.sharedCoresLock.Lock()
+	r.sharedCores = cores
+	r.sharedCoresLock.Unlock()
+
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, tr := range r.tasks {
+		tr.SetSharedCores(cores)
+	}
+}
+
+// getSharedCores returns the CPU cores that tasks without reserved cores run
+// on.
+func (r *AllocRunner) getSharedCores() []uint16 {
+	r.sharedCoresLock.Lock()
+	defer r.sharedCoresLock.Unlock()
+	return r.sharedCores
+}
+
 // Alloc returns the associated allocation
 func (r *AllocRunner) Alloc() *structs.Allocation {
 	r.allocLock.Lock()
@@ -789,6 +849,13 @@ func (r *AllocRunner) Run() {
 		return
 	}
 
+	// Create the network namespace shared by the tasks
+	if err := r.createNetwork(tg); err != nil {
+		r.logger.Printf("[ERR] client: alloc %q failed to create %s network: %v", r.allocID, tg.NetworkMode(), err)
+		r.setStatus(structs.AllocClientStatusFailed, fmt.Sprintf("failed to create network for '%s': %v", alloc.TaskGroup, err))
+		return
+	}
+
 	// Start the watcher
 	wCtx, watcherCancel := context.WithCancel(r.ctx)
 	go r.watchHealth(wCtx)
@@ -806,6 +873,9 @@ func (r *AllocRunner) Run() {
 		r.allocDirLock.Unlock()
 
 		tr := NewTaskRunner(r.logger, r.config, r.stateDB, r.setTaskState, taskdir, r.Alloc(), task.Copy(), r.vaultClient, r.consulClient)
+		tr.SetNetworkIsolation(r.networkIsolation)
+		tr.SetConsulTokenDeriver(r.consulTokenDeriver)
+		tr.SetSharedCores(r.getSharedCores())
 		r.tasks[task.Name] = tr
 		tr.MarkReceived()
 
@@ -813,6 +883,9 @@ func (r *AllocRunner) Run() {
 	}
 	r.taskLock.Unlock()
 
+	// Enforce the ephemeral disk size
+	go r.watchDiskUsage(r.ctx)
+
 	// taskDestroyEvent contains an event that caused the destroyment of a task
 	// in the allocation.
 	var taskDestroyEvent *structs.TaskEvent
@@ -935,6 +1008,9 @@ func (r *AllocRunner) handleDestroy() {
 		r.logger.Printf("[ERR] client: alloc %q unable unmount task directories: %v", r.allocID, err)
 	}
 
+	// Remove the network namespace as no tasks are running.
+	r.destroyNetwork()
+
 	for {
 		select {
 		case <-r.ctx.Done():
@@ -954,6 +1030,57 @@ func (r *AllocRunner) handleDestroy() {
 	}
 }
 
+// createNetwork creates the network namespace shared by the tasks of the
+// allocation if the task group requires one and it was not restored.
+func (r *AllocRunner) createNetwork(tg *structs.TaskGroup) error {
+	r.networkIsolationLock.Lock()
+	defer r.networkIsolationLock.Unlock()
+
+	if tg.NetworkMode() == structs.NetworkModeHost || r.networkIsolation != nil {
+		return nil
+	}
+
+	nm, err := groupNetworkManager(tg, r.allocID, r.config, r.logger)
+	if err != nil {
+		return err
+	}
+
+	spec, err := setupAllocNetwork(r.logger, r.Alloc(), tg, nm)
+	if err != nil {
+		return err
+	}
+	r.networkIsolation = spec
+	return nil
+}
+
+// destroyNetwork tears down the network namespace shared by the tasks of the
+// allocation, if any.
+func (r *AllocRunner) destroyNetwork() {
+	r.networkIsolationLock.Lock()
+	defer r.networkIsolationLock.Unlock()
+
+	if r.networkIsolation == nil {
+		return
+	}
+
+	alloc := r.Alloc()
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil {
+		return
+	}
+
+	nm, err := groupNetworkManager(tg, r.allocID, r.config, r.logger)
+	if err != nil {
+		r.logger.Printf("[ERR] client: alloc %q unable to destroy network: %v", r.allocID, err)
+		return
+	}
+	if err := teardownAllocNetwork(r.logger, alloc, r.networkIsolation, nm); err != nil {
+		r.logger.Printf("[ERR] client: alloc %q unable to destroy network: %v", r.allocID, err)
+		return
+	}
+	r.networkIsolation = nil
+}
+
 // IsWaiting returns true if this alloc is waiting on a previous allocation to
 // terminate.
 func (r *AllocRunner) IsWaiting() bool {
@@ -1065,6 +1192,22 @@ func (r *AllocRunner) Destroy() {
 	r.allocBroadcast.Close()
 }
 
+// killDisconnected marks the allocation lost and kills its tasks because the
+// client couldn't reach the servers for longer than the task group's
+// max_client_disconnect window.
+func (r *AllocRunner) killDisconnected(disconnected time.Duration) {
+	desc := fmt.Sprintf("client disconnected from the servers for %v which exceeds max_client_disconnect",
+		disconnected.Round(time.Second))
+	r.logger.Printf("[INFO] client: alloc %q %s, killing its tasks", r.allocID, desc)
+	r.setStatus(structs.AllocClientStatusLost, desc)
+
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, tr := range r.tasks {
+		tr.Destroy(structs.NewTaskEvent(structs.TaskKilling).SetKillReason(desc))
+	}
+}
+
 // WaitCh returns a channel to wait for termination
 func (r *AllocRunner) WaitCh() <-chan struct{} {
 	return r.waitCh
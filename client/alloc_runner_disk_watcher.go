@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// diskUsageWarnPercent is the percent of its ephemeral disk an
+	// allocation can use before its tasks are warned.
+	diskUsageWarnPercent = 90
+
+	// diskUsageEventSource is the source used for emitting task events
+	diskUsageEventSource = "Disk Usage"
+)
+
+// watchDiskUsage periodically measures the disk usage of the allocation. Its
+// tasks are warned when the usage nears the ephemeral disk size and killed
+// once the usage exceeds it.
+func (r *AllocRunner) watchDiskUsage(ctx context.Context) {
+	interval := r.config.DiskUsageCheckInterval
+	if interval <= 0 {
+		return
+	}
+
+	alloc := r.Alloc()
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil || tg.EphemeralDisk == nil || tg.EphemeralDisk.SizeMB <= 0 {
+		return
+	}
+	limitMB := tg.EphemeralDisk.SizeMB
+	limit := int64(limitMB) * 1024 * 1024
+
+	useQuota := r.config.DiskQuotas
+	warned := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Nothing writes to the alloc dir once the tasks are done
+		if r.Alloc().Terminated() {
+			return
+		}
+
+		usage, err := r.diskUsage(limitMB, &useQuota)
+		if err != nil {
+			r.logger.Printf("[WARN] client: alloc %q failed to measure disk usage: %v", r.allocID, err)
+			continue
+		}
+
+		if usage >= limit {
+			r.killDiskExceeded(usage, limitMB)
+			return
+		}
+
+		// Only warn once each time the usage crosses the threshold
+		warn := usage >= limit*diskUsageWarnPercent/100
+		if warn && !warned {
+			msg := fmt.Sprintf("Allocation is using %s of its %d MB ephemeral disk",
+				humanize.IBytes(uint64(usage)), limitMB)
+			r.taskLock.RLock()
+			for _, tr := range r.tasks {
+				tr.EmitEvent(diskUsageEventSource, msg)
+			}
+			r.taskLock.RUnlock()
+		}
+		warned = warn
+	}
+}
+
+// diskUsage returns the disk usage of the allocation. When enabled, project
+// quotas are used to both limit and measure the usage. If the file system
+// doesn't support them, useQuota is cleared and the usage is measured by
+// walking the allocation directory from then on.
+func (r *AllocRunner) diskUsage(limitMB int, useQuota *bool) (int64, error) {
+	// Copy the alloc dir so new task dirs can be added while measuring
+	r.allocDirLock.Lock()
+	allocDir := r.allocDir.Copy()
+	r.allocDirLock.Unlock()
+
+	if *useQuota {
+		err := allocDir.SetDiskQuota(limitMB)
+		if err == nil {
+			var usage int64
+			if usage, err = allocDir.DiskQuotaUsage(); err == nil {
+				return usage, nil
+			}
+		}
+
+		r.logger.Printf("[WARN] client: alloc %q unable to use disk quotas, measuring disk usage instead: %v", r.allocID, err)
+		*useQuota = false
+	}
+
+	return allocDir.DiskUsage()
+}
+
+// killDiskExceeded fails the allocation and kills its tasks because its disk
+// usage exceeded its ephemeral disk.
+func (r *AllocRunner) killDiskExceeded(usage int64, limitMB int) {
+	desc := fmt.Sprintf("disk usage of %s exceeded the ephemeral disk of %d MB",
+		humanize.IBytes(uint64(usage)), limitMB)
+	r.logger.Printf("[INFO] client: alloc %q %s, killing its tasks", r.allocID, desc)
+	r.setStatus(structs.AllocClientStatusFailed, desc)
+
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, tr := range r.tasks {
+		event := structs.NewTaskEvent(structs.TaskDiskExceeded).
+			SetDiskLimit(int64(limitMB) * 1024 * 1024).
+			SetFailsTask()
+		tr.Destroy(event)
+	}
+}
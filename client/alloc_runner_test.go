@@ -23,6 +23,7 @@ import (
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/vaultclient"
 )
@@ -1206,6 +1207,126 @@ func TestAllocRunner_TaskFailed_KillTG(t *testing.T) {
 	})
 }
 
+// Test that an allocation exceeding its ephemeral disk is killed
+func TestAllocRunner_DiskExceeded_Kill(t *testing.T) {
+	t.Parallel()
+	alloc := mock.Alloc()
+	alloc.Job.TaskGroups[0].EphemeralDisk.SizeMB = 1
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	task.KillTimeout = 10 * time.Millisecond
+	task.Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+
+	upd, ar := testAllocRunnerFromAlloc(alloc, false)
+	ar.config.DiskUsageCheckInterval = 50 * time.Millisecond
+	go ar.Run()
+	defer ar.Destroy()
+
+	// Wait for the task to start and exceed the disk
+	testutil.WaitForResult(func() (bool, error) {
+		_, last := upd.Last()
+		if last == nil {
+			return false, fmt.Errorf("No updates")
+		}
+		if last.ClientStatus != structs.AllocClientStatusRunning {
+			return false, fmt.Errorf("got status %v; want %v", last.ClientStatus, structs.AllocClientStatusRunning)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	data := filepath.Join(ar.GetAllocDir().SharedDir, allocdir.SharedDataDir, "data")
+	if err := ioutil.WriteFile(data, make([]byte, 2*1024*1024), 0666); err != nil {
+		t.Fatalf("failed to write data: %v", err)
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		_, last := upd.Last()
+		if last.ClientStatus != structs.AllocClientStatusFailed {
+			return false, fmt.Errorf("got status %v; want %v", last.ClientStatus, structs.AllocClientStatusFailed)
+		}
+		if !strings.Contains(last.ClientDescription, "ephemeral disk") {
+			return false, fmt.Errorf("unexpected description %q", last.ClientDescription)
+		}
+
+		state := last.TaskStates[task.Name]
+		if state.State != structs.TaskStateDead {
+			return false, fmt.Errorf("got state %v; want %v", state.State, structs.TaskStateDead)
+		}
+		if !state.Failed {
+			return false, fmt.Errorf("task should have failed")
+		}
+		for _, e := range state.Events {
+			if e.Type == structs.TaskDiskExceeded {
+				if e.DiskLimit != 1024*1024 {
+					return false, fmt.Errorf("got disk limit %d; want %d", e.DiskLimit, 1024*1024)
+				}
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("Did not find event %v", structs.TaskDiskExceeded)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestAllocRunner_KillDisconnected(t *testing.T) {
+	t.Parallel()
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	task.KillTimeout = 10 * time.Millisecond
+	task.Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+
+	upd, ar := testAllocRunnerFromAlloc(alloc, false)
+	go ar.Run()
+	defer ar.Destroy()
+
+	// Wait for the task to start
+	testutil.WaitForResult(func() (bool, error) {
+		_, last := upd.Last()
+		if last == nil {
+			return false, fmt.Errorf("No updates")
+		}
+		if last.ClientStatus != structs.AllocClientStatusRunning {
+			return false, fmt.Errorf("got status %v; want %v", last.ClientStatus, structs.AllocClientStatusRunning)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	ar.killDisconnected(2 * time.Minute)
+
+	testutil.WaitForResult(func() (bool, error) {
+		_, last := upd.Last()
+		if last.ClientStatus != structs.AllocClientStatusLost {
+			return false, fmt.Errorf("got status %v; want %v", last.ClientStatus, structs.AllocClientStatusLost)
+		}
+		if !strings.Contains(last.ClientDescription, "max_client_disconnect") {
+			return false, fmt.Errorf("unexpected description %q", last.ClientDescription)
+		}
+
+		state := last.TaskStates[task.Name]
+		if state.State != structs.TaskStateDead {
+			return false, fmt.Errorf("got state %v; want %v", state.State, structs.TaskStateDead)
+		}
+		for _, e := range state.Events {
+			if e.Type == structs.TaskKilling && strings.Contains(e.KillReason, "disconnected") {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("Did not find event %v", structs.TaskKilling)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
 func TestAllocRunner_TaskLeader_KillTG(t *testing.T) {
 	t.Parallel()
 	upd, ar := testAllocRunner(false)
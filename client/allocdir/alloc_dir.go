@@ -309,6 +309,41 @@ func (d *AllocDir) Build() error {
 	return nil
 }
 
+// diskUsageDirs returns the directories the tasks of the allocation write
+// to: the shared alloc directory and each task's local and tmp directories.
+// The rest of the task directories are made of mounts and files embedded in
+// the chroot, which don't count towards the allocation's disk usage.
+func (d *AllocDir) diskUsageDirs() []string {
+	dirs := []string{d.SharedDir}
+	for _, td := range d.TaskDirs {
+		dirs = append(dirs, td.LocalDir, filepath.Join(td.Dir, TmpDirName))
+	}
+	return dirs
+}
+
+// DiskUsage returns the number of bytes used on disk by the files the tasks of
+// the allocation write to.
+func (d *AllocDir) DiskUsage() (int64, error) {
+	var usage int64
+	for _, dir := range d.diskUsageDirs() {
+		err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				// Files can be removed while walking
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			usage += fileDiskUsage(fi)
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure disk usage of %q: %v", dir, err)
+		}
+	}
+	return usage, nil
+}
+
 // List returns the list of files at a path relative to the alloc dir
 func (d *AllocDir) List(path string) ([]*AllocFileInfo, error) {
 	if escapes, err := structs.PathEscapesAllocDir("", path); err != nil {
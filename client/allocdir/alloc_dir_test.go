@@ -434,6 +434,47 @@ func TestAllocDir_Copy(t *testing.T) {
 	}
 }
 
+// Test that DiskUsage only counts the directories tasks write to
+func TestAllocDir_DiskUsage(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(testLogger(), tmp)
+	defer d.Destroy()
+	if err := d.Build(); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	td := d.NewTaskDir(t1.Name)
+	if err := td.Build(false, nil, cstructs.FSIsolationNone); err != nil {
+		t.Fatalf("error build task=%q dir: %v", t1.Name, err)
+	}
+
+	const mb = 1024 * 1024
+	files := map[string]int{
+		filepath.Join(d.SharedDir, SharedDataDir, "data"): mb,
+		filepath.Join(td.LocalDir, "local"):               mb,
+
+		// Files outside of the task's local and tmp dirs aren't counted
+		filepath.Join(td.Dir, "chroot"): 4 * mb,
+	}
+	for path, size := range files {
+		if err := ioutil.WriteFile(path, bytes.Repeat([]byte("a"), size), 0666); err != nil {
+			t.Fatalf("Couldn't write file: %v", err)
+		}
+	}
+
+	usage, err := d.DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage() failed: %v", err)
+	}
+	if usage < 2*mb || usage >= 3*mb {
+		t.Fatalf("DiskUsage() returned %d; want between %d and %d", usage, 2*mb, 3*mb)
+	}
+}
+
 func TestPathFuncs(t *testing.T) {
 	dir, err := ioutil.TempDir("", "nomadtest-pathfuncs")
 	if err != nil {
@@ -105,3 +105,12 @@ func getOwner(fi os.FileInfo) (int, int) {
 	}
 	return int(stat.Uid), int(stat.Gid)
 }
+
+// fileDiskUsage returns the number of bytes allocated on disk for the file
+func fileDiskUsage(fi os.FileInfo) int64 {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fi.Size()
+	}
+	return int64(stat.Blocks) * 512
+}
@@ -64,3 +64,9 @@ func unmountSpecialDirs(taskDir string) error {
 func getOwner(os.FileInfo) (int, int) {
 	return idUnsupported, idUnsupported
 }
+
+// fileDiskUsage returns the size of the file as Windows doesn't expose the
+// number of allocated blocks through os.FileInfo
+func fileDiskUsage(fi os.FileInfo) int64 {
+	return fi.Size()
+}
@@ -0,0 +1,23 @@
+// +build !linux
+
+package allocdir
+
+import "errors"
+
+// errDiskQuotaUnsupported is returned on platforms without project quotas
+var errDiskQuotaUnsupported = errors.New("disk quotas are only supported on Linux")
+
+// SetDiskQuota is only supported on Linux
+func (d *AllocDir) SetDiskQuota(limitMB int) error {
+	return errDiskQuotaUnsupported
+}
+
+// DiskQuotaUsage is only supported on Linux
+func (d *AllocDir) DiskQuotaUsage() (int64, error) {
+	return 0, errDiskQuotaUnsupported
+}
+
+// RemoveDiskQuota is only supported on Linux
+func (d *AllocDir) RemoveDiskQuota() error {
+	return errDiskQuotaUnsupported
+}
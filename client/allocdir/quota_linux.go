@@ -0,0 +1,247 @@
+package allocdir
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// fsIocFsGetXattr and fsIocFsSetXattr are the ioctls to get and set the
+	// extended attributes of a file, which include its project ID
+	fsIocFsGetXattr = 0x801c581f
+	fsIocFsSetXattr = 0x401c5820
+
+	// fsXflagProjInherit makes the files created in a directory inherit its
+	// project ID
+	fsXflagProjInherit = 0x200
+
+	// quotactl commands and flags for project quotas
+	qGetQuota  = 0x800007
+	qSetQuota  = 0x800008
+	prjQuota   = 2
+	qifBLimits = 1
+
+	// quotaBlockSize is the size of the blocks quota limits are set in
+	quotaBlockSize = 1024
+
+	// diskQuotaProjectIDBase is the base of the project IDs of allocations.
+	// Project IDs below it are left to the operator.
+	diskQuotaProjectIDBase = 1 << 30
+)
+
+// fsxattr mirrors struct fsxattr from linux/fs.h
+type fsxattr struct {
+	Xflags     uint32
+	Extsize    uint32
+	Nextents   uint32
+	Projid     uint32
+	Cowextsize uint32
+	Pad        [8]byte
+}
+
+// dqblk mirrors struct if_dqblk from linux/quota.h
+type dqblk struct {
+	Bhardlimit uint64
+	Bsoftlimit uint64
+	Curspace   uint64
+	Ihardlimit uint64
+	Isoftlimit uint64
+	Curinodes  uint64
+	Btime      uint64
+	Itime      uint64
+	Valid      uint32
+}
+
+// diskQuotaProjectID returns the project ID of the allocation, derived from
+// the name of its directory which is the allocation ID.
+func (d *AllocDir) diskQuotaProjectID() uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(filepath.Base(d.AllocDir)))
+	return diskQuotaProjectIDBase | (h.Sum32() &^ (3 << 30))
+}
+
+// SetDiskQuota assigns the directories the tasks of the allocation write to a
+// project and limits the disk usage of the project. It is idempotent and must
+// be called again once new task directories are built. An error is returned
+// if the file system doesn't support project quotas.
+func (d *AllocDir) SetDiskQuota(limitMB int) error {
+	device, err := mountDevice(d.AllocDir)
+	if err != nil {
+		return err
+	}
+
+	id := d.diskQuotaProjectID()
+	for _, dir := range d.diskUsageDirs() {
+		if err := setProject(dir, id); err != nil {
+			return fmt.Errorf("failed to set project of %q: %v", dir, err)
+		}
+	}
+
+	quota := dqblk{
+		Bhardlimit: uint64(limitMB) * 1024 * 1024 / quotaBlockSize,
+		Valid:      qifBLimits,
+	}
+	if err := quotactl(qSetQuota, device, id, &quota); err != nil {
+		return fmt.Errorf("failed to set quota of project %d: %v", id, err)
+	}
+	return nil
+}
+
+// DiskQuotaUsage returns the number of bytes used by the allocation's project
+func (d *AllocDir) DiskQuotaUsage() (int64, error) {
+	device, err := mountDevice(d.AllocDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var quota dqblk
+	if err := quotactl(qGetQuota, device, d.diskQuotaProjectID(), &quota); err != nil {
+		return 0, fmt.Errorf("failed to get quota of project %d: %v", d.diskQuotaProjectID(), err)
+	}
+	return int64(quota.Curspace), nil
+}
+
+// RemoveDiskQuota removes the limit of the allocation's project
+func (d *AllocDir) RemoveDiskQuota() error {
+	device, err := mountDevice(d.AllocDir)
+	if err != nil {
+		return err
+	}
+
+	quota := dqblk{Valid: qifBLimits}
+	if err := quotactl(qSetQuota, device, d.diskQuotaProjectID(), &quota); err != nil {
+		return fmt.Errorf("failed to remove quota of project %d: %v", d.diskQuotaProjectID(), err)
+	}
+	return nil
+}
+
+// setProject sets the project of the directory and of the files in it. The
+// directories inherit the project so only the files that existed before the
+// project was set need to be walked.
+func setProject(dir string, id uint32) error {
+	attr, err := getFsxattr(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if attr.Projid == id && attr.Xflags&fsXflagProjInherit != 0 {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		// Only directories and regular files can be opened to set their
+		// project without side effects
+		if !fi.IsDir() && !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		attr, err := getFsxattr(path)
+		if err != nil {
+			return err
+		}
+		attr.Projid = id
+		if fi.IsDir() {
+			attr.Xflags |= fsXflagProjInherit
+		}
+		return setFsxattr(path, attr)
+	})
+}
+
+func getFsxattr(path string) (*fsxattr, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var attr fsxattr
+	if err := ioctl(f, fsIocFsGetXattr, uintptr(unsafe.Pointer(&attr))); err != nil {
+		return nil, err
+	}
+	return &attr, nil
+}
+
+func setFsxattr(path string, attr *fsxattr) error {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return ioctl(f, fsIocFsSetXattr, uintptr(unsafe.Pointer(attr)))
+}
+
+func ioctl(f *os.File, req, arg uintptr) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func quotactl(cmd int, device string, id uint32, quota *dqblk) error {
+	dev, err := unix.BytePtrFromString(device)
+	if err != nil {
+		return err
+	}
+
+	// QCMD(cmd, type) from linux/quota.h
+	qcmd := uintptr(cmd<<8 | prjQuota)
+	_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, qcmd, uintptr(unsafe.Pointer(dev)),
+		uintptr(id), uintptr(unsafe.Pointer(quota)), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// mountDevice returns the device of the file system the path is on, which
+// quotactl identifies file systems by.
+func mountDevice(path string) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", err
+	}
+	devID := fmt.Sprintf("%d:%d", unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev)))
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Lines are formatted as:
+	// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[2] != devID {
+			continue
+		}
+		for i, field := range fields {
+			if field == "-" && i+2 < len(fields) {
+				return fields[i+2], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("failed to find the device of %q", path)
+}
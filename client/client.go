@@ -124,10 +124,25 @@ type Client struct {
 	// successfully
 	serversDiscoveredCh chan struct{}
 
+	// staticMeta is the node metadata from the configuration and dynamicMeta
+	// is the metadata set at runtime which is overlaid on it. A nil dynamic
+	// value removes the key from the node. Both are guarded by configLock.
+	staticMeta  map[string]string
+	dynamicMeta map[string]*string
+
+	// nodeUpdatedCh triggers pushing node changes to the servers without
+	// waiting for the next periodic check; see watchNodeUpdates
+	nodeUpdatedCh chan struct{}
+
 	// allocs is the current set of allocations
 	allocs    map[string]*AllocRunner
 	allocLock sync.RWMutex
 
+	// sharedCores are the CPU cores that tasks without reserved cores run
+	// on. They exclude the cores reserved by allocations.
+	sharedCores     []uint16
+	sharedCoresLock sync.Mutex
+
 	// allocUpdates stores allocations that need to be synced to the server.
 	allocUpdates chan *structs.Allocation
 
@@ -193,6 +208,7 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulServic
 		servers:             newServerList(),
 		triggerDiscoveryCh:  make(chan struct{}),
 		serversDiscoveredCh: make(chan struct{}),
+		nodeUpdatedCh:       make(chan struct{}, 1),
 	}
 
 	// Initialize the client
@@ -226,6 +242,11 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulServic
 		return nil, fmt.Errorf("node setup failed: %v", err)
 	}
 
+	// Restore the node metadata set at runtime
+	if err := c.restoreNodeMeta(); err != nil {
+		return nil, fmt.Errorf("failed to restore node metadata: %v", err)
+	}
+
 	// Fingerprint the node
 	if err := c.fingerprint(); err != nil {
 		return nil, fmt.Errorf("fingerprinting failed: %v", err)
@@ -641,6 +662,7 @@ func (c *Client) restoreState() error {
 		c.configLock.RLock()
 		ar := NewAllocRunner(c.logger, c.configCopy, c.stateDB, c.updateAllocStatus, alloc, c.vaultClient, c.consulService, watcher)
 		c.configLock.RUnlock()
+		ar.SetConsulTokenDeriver(c.deriveConsulToken)
 
 		c.allocLock.Lock()
 		c.allocs[id] = ar
@@ -667,6 +689,9 @@ func (c *Client) restoreState() error {
 		}
 	}
 
+	// Move the restored tasks off the cores reserved by other tasks
+	c.updateSharedCores()
+
 	return mErr.ErrorOrNil()
 }
 
@@ -917,6 +942,34 @@ func (c *Client) fingerprint() error {
 	if len(skipped) != 0 {
 		c.logger.Printf("[DEBUG] client: fingerprint modules skipped due to white/blacklist: %v", skipped)
 	}
+
+	return c.fingerprintScripts()
+}
+
+// fingerprintScripts runs the custom fingerprint scripts and starts running
+// them periodically. A failing script does not prevent the client from
+// starting.
+func (c *Client) fingerprintScripts() error {
+	scripts, err := fingerprint.ScriptFingerprints(c.config.FingerprintScripts, c.logger)
+	if err != nil {
+		return err
+	}
+
+	var applied []string
+	for _, f := range scripts {
+		applies, err := c.fingerprintScript(f)
+		if err != nil {
+			c.logger.Printf("[WARN] client: fingerprint script %v failed: %v", f.Name(), err)
+		}
+		if applies {
+			applied = append(applied, f.Name())
+		}
+
+		go c.fingerprintScriptPeriodic(f)
+	}
+	if len(scripts) != 0 {
+		c.logger.Printf("[DEBUG] client: applied fingerprint scripts %v", applied)
+	}
 	return nil
 }
 
@@ -937,6 +990,36 @@ func (c *Client) fingerprintPeriodic(name string, f fingerprint.Fingerprint, d t
 	}
 }
 
+// fingerprintScript runs the fingerprint script and applies the attributes it
+// reports. The script runs without holding the config lock since it may run
+// up to its timeout.
+func (c *Client) fingerprintScript(f *fingerprint.ScriptFingerprint) (bool, error) {
+	attrs, err := f.Run()
+	if err != nil {
+		return false, err
+	}
+
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	return f.Apply(c.config.Node, attrs), nil
+}
+
+// fingerprintScriptPeriodic runs a fingerprint script at its interval.
+func (c *Client) fingerprintScriptPeriodic(f *fingerprint.ScriptFingerprint) {
+	_, d := f.Periodic()
+	c.logger.Printf("[DEBUG] client: fingerprinting %v every %v", f.Name(), d)
+	for {
+		select {
+		case <-time.After(d):
+			if _, err := c.fingerprintScript(f); err != nil {
+				c.logger.Printf("[DEBUG] client: periodic fingerprinting for %v failed: %v", f.Name(), err)
+			}
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
 // setupDrivers is used to find the available drivers
 func (c *Client) setupDrivers() error {
 	// Build the white/blacklists of drivers.
@@ -1017,6 +1100,10 @@ func (c *Client) registerAndHeartbeat() {
 		heartbeat = time.After(lib.RandomStagger(initialHeartbeatStagger))
 	}
 
+	// disconnected tracks whether heartbeating failed since the last
+	// successful heartbeat
+	disconnected := false
+
 	for {
 		select {
 		case <-c.serversDiscoveredCh:
@@ -1040,12 +1127,55 @@ func (c *Client) registerAndHeartbeat() {
 
 				// if heartbeating fails, trigger Consul discovery
 				c.triggerDiscovery()
+
+				// Stop the allocations the servers will have replaced
+				disconnected = true
+				c.stopDisconnectedAllocs()
 			}
 		} else {
 			c.heartbeatLock.Lock()
 			heartbeat = time.After(c.heartbeatTTL)
 			c.heartbeatLock.Unlock()
+
+			// The servers may have marked the allocations unknown while
+			// disconnected so send them their current status
+			if disconnected {
+				disconnected = false
+				c.resyncAllocStatuses()
+			}
+		}
+	}
+}
+
+// stopDisconnectedAllocs stops the allocations whose task group's
+// max_client_disconnect window is shorter than the time since the client last
+// heartbeated. The servers replace these allocations once the window expires.
+func (c *Client) stopDisconnectedAllocs() {
+	c.heartbeatLock.Lock()
+	last := c.lastHeartbeat
+	c.heartbeatLock.Unlock()
+	if last.IsZero() {
+		return
+	}
+	disconnected := time.Since(last)
+
+	for _, ar := range c.getAllocRunners() {
+		alloc := ar.Alloc()
+		if alloc.Terminated() {
+			continue
 		}
+		tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+		if tg == nil || tg.MaxClientDisconnect == nil || disconnected <= *tg.MaxClientDisconnect {
+			continue
+		}
+		ar.killDisconnected(disconnected)
+	}
+}
+
+// resyncAllocStatuses sends the status of every allocation to the servers
+func (c *Client) resyncAllocStatuses() {
+	for _, ar := range c.getAllocRunners() {
+		c.updateAllocStatus(ar.Alloc())
 	}
 }
 
@@ -1481,21 +1611,23 @@ func (c *Client) watchNodeUpdates() {
 	for {
 		select {
 		case <-time.After(c.retryIntv(nodeUpdateRetryIntv)):
-			changed, attrHash, metaHash = c.hasNodeChanged(attrHash, metaHash)
-			if changed {
-				c.logger.Printf("[DEBUG] client: state changed, updating node.")
-
-				// Update the config copy.
-				c.configLock.Lock()
-				node := c.config.Node.Copy()
-				c.configCopy.Node = node
-				c.configLock.Unlock()
-
-				c.retryRegisterNode()
-			}
+		case <-c.nodeUpdatedCh:
 		case <-c.shutdownCh:
 			return
 		}
+
+		changed, attrHash, metaHash = c.hasNodeChanged(attrHash, metaHash)
+		if changed {
+			c.logger.Printf("[DEBUG] client: state changed, updating node.")
+
+			// Update the config copy.
+			c.configLock.Lock()
+			node := c.config.Node.Copy()
+			c.configCopy.Node = node
+			c.configLock.Unlock()
+
+			c.retryRegisterNode()
+		}
 	}
 }
 
@@ -1535,6 +1667,9 @@ func (c *Client) runAllocs(update *allocUpdates) {
 				add.ID, err)
 		}
 	}
+
+	// Move tasks off the cores reserved by the updated allocations
+	c.updateSharedCores()
 }
 
 // removeAlloc is invoked when we should remove an allocation
@@ -1593,6 +1728,8 @@ func (c *Client) addAlloc(alloc *structs.Allocation) error {
 
 	ar := NewAllocRunner(c.logger, c.configCopy, c.stateDB, c.updateAllocStatus, alloc, c.vaultClient, c.consulService, prevAlloc)
 	c.configLock.RUnlock()
+	ar.SetConsulTokenDeriver(c.deriveConsulToken)
+	ar.SetSharedCores(c.getSharedCores())
 
 	// Store the alloc runner.
 	c.allocs[alloc.ID] = ar
@@ -1721,6 +1858,45 @@ func (c *Client) deriveToken(alloc *structs.Allocation, taskNames []string, vcli
 	return unwrappedTokens, nil
 }
 
+// deriveConsulToken derives the Consul tokens used by the given tasks to
+// register their services and render their templates. Tasks without a token
+// use the agent's token.
+func (c *Client) deriveConsulToken(alloc *structs.Allocation, taskNames []string) (map[string]string, error) {
+	if alloc == nil {
+		return nil, fmt.Errorf("nil allocation")
+	}
+	if len(taskNames) == 0 {
+		return nil, fmt.Errorf("missing task names")
+	}
+
+	req := &structs.DeriveConsulTokenRequest{
+		NodeID:   c.NodeID(),
+		SecretID: c.secretNodeID(),
+		AllocID:  alloc.ID,
+		Tasks:    taskNames,
+		QueryOptions: structs.QueryOptions{
+			Region:     c.Region(),
+			AllowStale: false,
+		},
+	}
+
+	var resp structs.DeriveConsulTokenResponse
+	if err := c.RPC("Node.DeriveConsulToken", &req, &resp); err != nil {
+		// COMPAT: Servers that don't derive Consul tokens leave tasks using
+		// the agent's token.
+		if strings.Contains(err.Error(), "can't find method") {
+			return nil, nil
+		}
+		c.logger.Printf("[ERR] client.consul: DeriveConsulToken RPC failed: %v", err)
+		return nil, structs.NewRecoverableError(fmt.Errorf("DeriveConsulToken RPC failed: %v", err), true)
+	}
+	if resp.Error != nil {
+		c.logger.Printf("[ERR] client.consul: failed to derive Consul tokens: %v", resp.Error)
+		return nil, resp.Error
+	}
+	return resp.Tasks, nil
+}
+
 // triggerDiscovery causes a Consul discovery to begin (if one hasn't alread)
 func (c *Client) triggerDiscovery() {
 	select {
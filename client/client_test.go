@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -219,6 +220,57 @@ func TestClient_Fingerprint(t *testing.T) {
 	}
 }
 
+func TestClient_FingerprintScript_ConfigLock(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("fingerprint script tests require a shell")
+	}
+	dir, err := ioutil.TempDir("", "nomad-fingerprint")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "slow")
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\nsleep 1\necho foo=bar\n"), 0755); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	conf := config.DefaultConfig()
+	conf.Node = mock.Node()
+	c := &Client{config: conf, logger: testLogger()}
+	f := fingerprint.NewScriptFingerprint(path, time.Minute, 10*time.Second, c.logger)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.fingerprintScript(f)
+		errCh <- err
+	}()
+
+	// The config lock is not held while the script runs
+	time.Sleep(200 * time.Millisecond)
+	locked := make(chan struct{})
+	go func() {
+		c.configLock.Lock()
+		c.configLock.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-errCh:
+		t.Fatalf("script finished before the lock was checked")
+	case <-time.After(500 * time.Millisecond):
+		t.Fatalf("config lock held while the script runs")
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v := conf.Node.Attributes["custom.foo"]; v != "bar" {
+		t.Fatalf("bad attribute: %q", v)
+	}
+}
+
 func TestClient_HasNodeChanged(t *testing.T) {
 	t.Parallel()
 	c := testClient(t, nil)
@@ -180,6 +180,18 @@ type Config struct {
 	// random UUID.
 	NoHostUUID bool
 
+	// DiskUsageCheckInterval is the interval at which the disk usage of each
+	// allocation is checked against its ephemeral disk size
+	DiskUsageCheckInterval time.Duration
+
+	// DiskQuotas enables limiting the disk usage of allocations with file
+	// system project quotas where supported
+	DiskQuotas bool
+
+	// FingerprintScripts configures custom fingerprint scripts that add
+	// attributes to the node. It is nil if no scripts are configured.
+	FingerprintScripts *FingerprintScriptsConfig
+
 	// ACLEnabled controls if ACL enforcement and management is enabled.
 	ACLEnabled bool
 
@@ -207,6 +219,7 @@ func (c *Config) Copy() *Config {
 	nc.GloballyReservedPorts = helper.CopySliceInt(c.GloballyReservedPorts)
 	nc.ConsulConfig = c.ConsulConfig.Copy()
 	nc.VaultConfig = c.VaultConfig.Copy()
+	nc.FingerprintScripts = c.FingerprintScripts.Copy()
 	return nc
 }
 
@@ -227,6 +240,7 @@ func DefaultConfig() *Config {
 		GCInodeUsageThreshold:      70,
 		GCMaxAllocs:                50,
 		NoHostUUID:                 true,
+		DiskUsageCheckInterval:     30 * time.Second,
 		DisableTaggedMetrics:       false,
 		BackwardsCompatibleMetrics: false,
 	}
@@ -0,0 +1,108 @@
+package config
+
+import "time"
+
+const (
+	// DefaultFingerprintScriptInterval is the default interval at which
+	// fingerprint scripts are run.
+	DefaultFingerprintScriptInterval = 5 * time.Minute
+
+	// DefaultFingerprintScriptTimeout is the default time a fingerprint
+	// script may run before it is killed.
+	DefaultFingerprintScriptTimeout = 30 * time.Second
+)
+
+// FingerprintScriptsConfig configures the custom fingerprint scripts run by
+// the client to add site specific attributes to the node.
+type FingerprintScriptsConfig struct {
+	// Dir is the directory containing the fingerprint executables.
+	Dir string `mapstructure:"dir"`
+
+	// Interval is the default interval at which each script is run.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the default time a script may run before it is killed.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Scripts overrides the interval and timeout of individual scripts,
+	// keyed by the file name of the script.
+	Scripts map[string]*FingerprintScriptConfig `mapstructure:"script"`
+}
+
+// FingerprintScriptConfig overrides the settings of a single fingerprint
+// script.
+type FingerprintScriptConfig struct {
+	// Interval is the interval at which the script is run.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the time the script may run before it is killed.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// IntervalFor returns the interval at which the named script is run.
+func (f *FingerprintScriptsConfig) IntervalFor(name string) time.Duration {
+	if s, ok := f.Scripts[name]; ok && s.Interval != 0 {
+		return s.Interval
+	}
+	if f.Interval != 0 {
+		return f.Interval
+	}
+	return DefaultFingerprintScriptInterval
+}
+
+// TimeoutFor returns the time the named script may run before it is killed.
+func (f *FingerprintScriptsConfig) TimeoutFor(name string) time.Duration {
+	if s, ok := f.Scripts[name]; ok && s.Timeout != 0 {
+		return s.Timeout
+	}
+	if f.Timeout != 0 {
+		return f.Timeout
+	}
+	return DefaultFingerprintScriptTimeout
+}
+
+// Copy returns a deep copy of the configuration.
+func (f *FingerprintScriptsConfig) Copy() *FingerprintScriptsConfig {
+	if f == nil {
+		return nil
+	}
+	nf := new(FingerprintScriptsConfig)
+	*nf = *f
+	if f.Scripts != nil {
+		nf.Scripts = make(map[string]*FingerprintScriptConfig, len(f.Scripts))
+		for k, v := range f.Scripts {
+			ns := *v
+			nf.Scripts[k] = &ns
+		}
+	}
+	return nf
+}
+
+// Merge merges two fingerprint script configurations, with values in b
+// taking precedence.
+func (f *FingerprintScriptsConfig) Merge(b *FingerprintScriptsConfig) *FingerprintScriptsConfig {
+	if f == nil {
+		return b.Copy()
+	}
+	result := f.Copy()
+	if b == nil {
+		return result
+	}
+	if b.Dir != "" {
+		result.Dir = b.Dir
+	}
+	if b.Interval != 0 {
+		result.Interval = b.Interval
+	}
+	if b.Timeout != 0 {
+		result.Timeout = b.Timeout
+	}
+	if len(b.Scripts) != 0 && result.Scripts == nil {
+		result.Scripts = make(map[string]*FingerprintScriptConfig, len(b.Scripts))
+	}
+	for k, v := range b.Scripts {
+		ns := *v
+		result.Scripts[k] = &ns
+	}
+	return result
+}
@@ -14,4 +14,9 @@ type ConsulServiceAPI interface {
 	RemoveTask(allocID string, task *structs.Task)
 	UpdateTask(allocID string, existing, newTask *structs.Task, restart consul.TaskRestarter, exec driver.ScriptExecutor, net *cstructs.DriverNetwork) error
 	AllocRegistrations(allocID string) (*consul.AllocRegistration, error)
+	SetTaskToken(allocID, taskName, token string)
 }
+
+// ConsulTokenDeriverFn derives the Consul tokens of the given tasks of an
+// allocation. A task without a token uses the agent's token.
+type ConsulTokenDeriverFn func(alloc *structs.Allocation, taskNames []string) (map[string]string, error)
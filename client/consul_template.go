@@ -99,6 +99,10 @@ type TaskTemplateManagerConfig struct {
 	// VaultToken is the Vault token for the task.
 	VaultToken string
 
+	// ConsulToken is the Consul token derived for the task. If empty the
+	// client's Consul token is used.
+	ConsulToken string
+
 	// TaskDir is the task's directory
 	TaskDir string
 
@@ -597,6 +601,9 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 	if cc.ConsulConfig != nil {
 		conf.Consul.Address = &cc.ConsulConfig.Addr
 		conf.Consul.Token = &cc.ConsulConfig.Token
+		if config.ConsulToken != "" {
+			conf.Consul.Token = &config.ConsulToken
+		}
 
 		if cc.ConsulConfig.EnableSSL != nil && *cc.ConsulConfig.EnableSSL {
 			verify := cc.ConsulConfig.VerifySSL != nil && *cc.ConsulConfig.VerifySSL
@@ -47,11 +47,15 @@ type mockConsulServiceClient struct {
 	// allocRegistrationsFn allows injecting return values for the
 	// AllocRegistrations function.
 	allocRegistrationsFn func(allocID string) (*consul.AllocRegistration, error)
+
+	// tokens records the Consul tokens set for tasks by allocID-task.
+	tokens map[string]string
 }
 
 func newMockConsulServiceClient() *mockConsulServiceClient {
 	m := mockConsulServiceClient{
 		ops:    make([]mockConsulOp, 0, 20),
+		tokens: make(map[string]string),
 		logger: log.New(ioutil.Discard, "", 0),
 	}
 	if testing.Verbose() {
@@ -95,3 +99,10 @@ func (m *mockConsulServiceClient) AllocRegistrations(allocID string) (*consul.Al
 
 	return nil, nil
 }
+
+func (m *mockConsulServiceClient) SetTaskToken(allocID, taskName, token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger.Printf("[TEST] mock_consul: SetTaskToken(%q, %q)", allocID, taskName)
+	m.tokens[allocID+"-"+taskName] = token
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// sharedCores returns the CPU cores of the node that tasks without reserved
+// cores run on. These are the reservable cores of the node excluding the ones
+// reserved for the host and the ones reserved by the non-terminal
+// allocations. Nil is returned if the node has no reservable cores or if
+// every core is reserved, in which case tasks are not restricted.
+func sharedCores(node *structs.Node, allocs []*structs.Allocation) []uint16 {
+	if node == nil || node.Resources == nil || len(node.Resources.CoreIDs) == 0 {
+		return nil
+	}
+
+	reserved := make(map[uint16]struct{})
+	if node.Reserved != nil {
+		for _, core := range node.Reserved.CoreIDs {
+			reserved[core] = struct{}{}
+		}
+	}
+	for _, alloc := range allocs {
+		if alloc.TerminalStatus() {
+			continue
+		}
+		for _, resources := range alloc.TaskResources {
+			for _, core := range resources.CoreIDs {
+				reserved[core] = struct{}{}
+			}
+		}
+	}
+
+	var shared []uint16
+	for _, core := range node.Resources.CoreIDs {
+		if _, ok := reserved[core]; !ok {
+			shared = append(shared, core)
+		}
+	}
+	return shared
+}
+
+// updateSharedCores recomputes the CPU cores shared by tasks without reserved
+// cores and moves the tasks onto them if they changed.
+func (c *Client) updateSharedCores() {
+	c.allocLock.RLock()
+	runners := make([]*AllocRunner, 0, len(c.allocs))
+	allocs := make([]*structs.Allocation, 0, len(c.allocs))
+	for _, ar := range c.allocs {
+		runners = append(runners, ar)
+		allocs = append(allocs, ar.Alloc())
+	}
+	c.allocLock.RUnlock()
+
+	shared := sharedCores(c.Node(), allocs)
+
+	c.sharedCoresLock.Lock()
+	changed := structs.CpusetString(shared) != structs.CpusetString(c.sharedCores)
+	c.sharedCores = shared
+	c.sharedCoresLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	c.logger.Printf("[DEBUG] client: updating shared cores to %q", structs.CpusetString(shared))
+	for _, ar := range runners {
+		ar.SetSharedCores(shared)
+	}
+}
+
+// getSharedCores returns the CPU cores shared by tasks without reserved
+// cores.
+func (c *Client) getSharedCores() []uint16 {
+	c.sharedCoresLock.Lock()
+	defer c.sharedCoresLock.Unlock()
+	return c.sharedCores
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestSharedCores(t *testing.T) {
+	t.Parallel()
+
+	node := mock.Node()
+	node.Resources.CoreIDs = []uint16{0, 1, 2, 3, 4, 5}
+	node.Reserved.CoreIDs = []uint16{0}
+
+	pinned := mock.Alloc()
+	pinned.TaskResources["web"].CoreIDs = []uint16{2, 3}
+
+	stopped := mock.Alloc()
+	stopped.DesiredStatus = structs.AllocDesiredStatusStop
+	stopped.TaskResources["web"].CoreIDs = []uint16{5}
+
+	shared := mock.Alloc()
+
+	cases := []struct {
+		Name     string
+		Node     *structs.Node
+		Allocs   []*structs.Allocation
+		Expected []uint16
+	}{
+		{
+			Name:     "no reservable cores",
+			Node:     mock.Node(),
+			Allocs:   []*structs.Allocation{pinned},
+			Expected: nil,
+		},
+		{
+			Name:     "no reserved cores",
+			Node:     node,
+			Allocs:   []*structs.Allocation{shared},
+			Expected: []uint16{1, 2, 3, 4, 5},
+		},
+		{
+			Name:     "reserved cores",
+			Node:     node,
+			Allocs:   []*structs.Allocation{shared, pinned, stopped},
+			Expected: []uint16{1, 4, 5},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if act := sharedCores(c.Node, c.Allocs); !reflect.DeepEqual(act, c.Expected) {
+				t.Fatalf("got %v; want %v", act, c.Expected)
+			}
+		})
+	}
+}
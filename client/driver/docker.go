@@ -106,6 +106,15 @@ const (
 	// dockerImageResKey is the CreatedResources key for docker images
 	dockerImageResKey = "image"
 
+	// dockerPauseImageConfigOption is the key for setting the image used to
+	// hold open the network namespace of a task group.
+	dockerPauseImageConfigOption  = "docker.network.pause_image"
+	dockerPauseImageConfigDefault = "gcr.io/google_containers/pause-amd64:3.0"
+
+	// dockerNetSandboxLabel is the NetworkIsolationSpec label holding the ID
+	// of the container owning a task group's network namespace.
+	dockerNetSandboxLabel = "docker_sandbox_container_id"
+
 	// dockerAuthHelperPrefix is the prefix to attach to the credential helper
 	// and should be found in the $PATH. Example: ${prefix-}${helper-name}
 	dockerAuthHelperPrefix = "docker-credential-"
@@ -973,6 +982,22 @@ func (d *DockerDriver) createContainerConfig(ctx *ExecContext, task *structs.Tas
 		VolumeDriver: driverConfig.VolumeDriver,
 	}
 
+	// Allow the task to burst above its reserved memory up to the hard limit
+	// of its memory max. The reservation becomes the soft limit.
+	if max := task.Resources.MemoryMaxMB; max > task.Resources.MemoryMB {
+		memLimit = int64(max) * 1024 * 1024
+		hostConfig.Memory = memLimit
+		hostConfig.MemoryReservation = int64(task.Resources.MemoryMB) * 1024 * 1024
+	}
+
+	// Pin the task to its reserved cores. Otherwise keep it off the cores
+	// reserved by other tasks.
+	if len(task.Resources.CoreIDs) != 0 {
+		hostConfig.CPUSetCPUs = structs.CpusetString(task.Resources.CoreIDs)
+	} else if len(ctx.SharedCores) != 0 {
+		hostConfig.CPUSetCPUs = structs.CpusetString(ctx.SharedCores)
+	}
+
 	// Windows does not support MemorySwap/MemorySwappiness #2193
 	if runtime.GOOS == "windows" {
 		hostConfig.MemorySwap = 0
@@ -1062,8 +1087,26 @@ func (d *DockerDriver) createContainerConfig(ctx *ExecContext, task *structs.Tas
 		hostConfig.NetworkMode = defaultNetworkMode
 	}
 
+	// Join the network namespace shared by the allocation's tasks. Ports are
+	// mapped into the namespace by the client so none are published here.
+	if ctx.NetworkIsolation != nil {
+		id, ok := ctx.NetworkIsolation.Labels[dockerNetSandboxLabel]
+		if !ok {
+			return c, fmt.Errorf("network namespace for %s mode was not created by the docker driver", ctx.NetworkIsolation.Mode)
+		}
+		if driverConfig.NetworkMode != "" {
+			return c, fmt.Errorf("network_mode can not be set when the task group uses %s networking", ctx.NetworkIsolation.Mode)
+		}
+		hostConfig.NetworkMode = "container:" + id
+		d.logger.Printf("[DEBUG] driver.docker: joining task group network of container %s", id)
+	}
+
 	// Setup port mapping and exposed ports
-	if len(task.Resources.Networks) == 0 {
+	if ctx.NetworkIsolation != nil {
+		if len(driverConfig.PortMap) > 0 {
+			return c, fmt.Errorf("port_map can not be used when the task group uses %s networking", ctx.NetworkIsolation.Mode)
+		}
+	} else if len(task.Resources.Networks) == 0 {
 		d.logger.Println("[DEBUG] driver.docker: No network interfaces are available")
 		if len(driverConfig.PortMap) > 0 {
 			return c, fmt.Errorf("Trying to map ports but no network interface is available")
@@ -1506,6 +1549,21 @@ func (h *DockerHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+// UpdateCpuset moves the container onto the given shared cores.
+func (h *DockerHandle) UpdateCpuset(cores []uint16) error {
+	if len(cores) == 0 {
+		return nil
+	}
+
+	opts := docker.UpdateContainerOptions{
+		CpusetCpus: structs.CpusetString(cores),
+	}
+	if err := h.client.UpdateContainer(h.containerID, opts); err != nil {
+		return fmt.Errorf("failed to update container cpuset: %v", err)
+	}
+	return nil
+}
+
 func (h *DockerHandle) Exec(ctx context.Context, cmd string, args []string) ([]byte, int, error) {
 	fullCmd := make([]string, len(args)+1)
 	fullCmd[0] = cmd
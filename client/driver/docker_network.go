@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"fmt"
+
+	docker "github.com/fsouza/go-dockerclient"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// CreateNetwork creates the network namespace shared by the tasks of an
+// allocation. Docker containers can only join the network of another
+// container, so a pause container is started to own the namespace.
+func (d *DockerDriver) CreateNetwork(allocID string) (*cstructs.NetworkIsolationSpec, error) {
+	client, _, err := d.dockerClients()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to docker daemon: %s", err)
+	}
+
+	image := d.config.ReadDefault(dockerPauseImageConfigOption, dockerPauseImageConfigDefault)
+	if _, err := client.InspectImage(image); err == docker.ErrNoSuchImage {
+		repo, tag := docker.ParseRepositoryTag(image)
+		if tag == "" {
+			tag = "latest"
+		}
+		d.logger.Printf("[DEBUG] driver.docker: pulling pause image %s:%s", repo, tag)
+		pullOpts := docker.PullImageOptions{Repository: repo, Tag: tag}
+		if err := client.PullImage(pullOpts, docker.AuthConfiguration{}); err != nil {
+			return nil, fmt.Errorf("Failed to pull pause image %q: %v", image, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to inspect pause image %q: %v", image, err)
+	}
+
+	name := fmt.Sprintf("nomad_init_%s", allocID)
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name: name,
+		Config: &docker.Config{
+			Image: image,
+		},
+		HostConfig: &docker.HostConfig{
+			NetworkMode: structs.NetworkModeNone,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create pause container %q: %v", name, err)
+	}
+
+	if err := client.StartContainer(container.ID, nil); err != nil {
+		d.removePauseContainer(client, container.ID)
+		return nil, fmt.Errorf("Failed to start pause container %q: %v", name, err)
+	}
+
+	id := container.ID
+	container, err = client.InspectContainer(id)
+	if err != nil {
+		d.removePauseContainer(client, id)
+		return nil, fmt.Errorf("Failed to inspect pause container %q: %v", name, err)
+	}
+
+	d.logger.Printf("[DEBUG] driver.docker: started pause container %s for alloc %q", container.ID, allocID)
+	return &cstructs.NetworkIsolationSpec{
+		Path: fmt.Sprintf("/proc/%d/ns/net", container.State.Pid),
+		Labels: map[string]string{
+			dockerNetSandboxLabel: container.ID,
+		},
+	}, nil
+}
+
+// DestroyNetwork removes the pause container created by CreateNetwork.
+func (d *DockerDriver) DestroyNetwork(allocID string, spec *cstructs.NetworkIsolationSpec) error {
+	id, ok := spec.Labels[dockerNetSandboxLabel]
+	if !ok {
+		return fmt.Errorf("network spec for alloc %q is missing the pause container ID", allocID)
+	}
+
+	client, _, err := d.dockerClients()
+	if err != nil {
+		return fmt.Errorf("Failed to connect to docker daemon: %s", err)
+	}
+	return d.removePauseContainer(client, id)
+}
+
+// removePauseContainer forcibly removes the pause container with the given ID.
+func (d *DockerDriver) removePauseContainer(client *docker.Client, id string) error {
+	err := client.RemoveContainer(docker.RemoveContainerOptions{
+		ID:    id,
+		Force: true,
+	})
+	if _, ok := err.(*docker.NoSuchContainer); ok {
+		return nil
+	}
+	return err
+}
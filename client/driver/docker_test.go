@@ -798,6 +798,53 @@ func TestDockerDriver_Labels(t *testing.T) {
 	}
 }
 
+func TestDockerDriver_MemoryMax(t *testing.T) {
+	if !tu.IsTravis() {
+		t.Parallel()
+	}
+	task, _, _ := dockerTask()
+	task.Resources.MemoryMaxMB = 512
+
+	client, handle, cleanup := dockerSetup(t, task)
+	defer cleanup()
+
+	waitForExist(t, client, handle.(*DockerHandle))
+
+	container, err := client.InspectContainer(handle.(*DockerHandle).ContainerID())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if want, got := int64(512*1024*1024), container.HostConfig.Memory; want != got {
+		t.Errorf("Wrong memory limit. Expect: %d, got: %d", want, got)
+	}
+	if want, got := int64(256*1024*1024), container.HostConfig.MemoryReservation; want != got {
+		t.Errorf("Wrong memory reservation. Expect: %d, got: %d", want, got)
+	}
+}
+
+func TestDockerDriver_ReservedCores(t *testing.T) {
+	if !tu.IsTravis() {
+		t.Parallel()
+	}
+	task, _, _ := dockerTask()
+	task.Resources.CoreIDs = []uint16{0}
+
+	client, handle, cleanup := dockerSetup(t, task)
+	defer cleanup()
+
+	waitForExist(t, client, handle.(*DockerHandle))
+
+	container, err := client.InspectContainer(handle.(*DockerHandle).ContainerID())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if want, got := "0", container.HostConfig.CPUSetCPUs; want != got {
+		t.Errorf("Wrong cpuset. Expect: %q, got: %q", want, got)
+	}
+}
+
 func TestDockerDriver_ForcePull_IsInvalidConfig(t *testing.T) {
 	if !tu.IsTravis() {
 		t.Parallel()
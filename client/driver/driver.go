@@ -252,6 +252,18 @@ type DriverAbilities struct {
 	Exec bool
 }
 
+// DriverNetworkManager is implemented by drivers that must create the network
+// namespace shared by the tasks of an allocation themselves, such as Docker
+// which can only join the namespace of another container.
+type DriverNetworkManager interface {
+	// CreateNetwork creates a network namespace for the allocation and
+	// returns a spec whose Path references it.
+	CreateNetwork(allocID string) (*cstructs.NetworkIsolationSpec, error)
+
+	// DestroyNetwork destroys a network namespace created by CreateNetwork.
+	DestroyNetwork(allocID string, spec *cstructs.NetworkIsolationSpec) error
+}
+
 // LogEventFn is a callback which allows Drivers to emit task events.
 type LogEventFn func(message string, args ...interface{})
 
@@ -317,6 +329,12 @@ type DriverHandle interface {
 	ScriptExecutor
 }
 
+// CpusetUpdater is implemented by driver handles that can move a running task
+// without reserved cores onto a different set of shared CPU cores.
+type CpusetUpdater interface {
+	UpdateCpuset(cores []uint16) error
+}
+
 // ScriptExecutor is an interface that supports Exec()ing commands in the
 // driver's context. Split out of DriverHandle to ease testing.
 type ScriptExecutor interface {
@@ -330,6 +348,14 @@ type ExecContext struct {
 
 	// TaskEnv contains the task's environment variables.
 	TaskEnv *env.TaskEnv
+
+	// NetworkIsolation is the network namespace shared by the allocation's
+	// tasks. It is nil if the task should use the host's network.
+	NetworkIsolation *cstructs.NetworkIsolationSpec
+
+	// SharedCores are the CPU cores the task runs on if it has no reserved
+	// cores. Empty if the task is not restricted to a set of cores.
+	SharedCores []uint16
 }
 
 // NewExecContext is used to create a new execution context
@@ -228,6 +228,10 @@ type Builder struct {
 	// and affect network env vars.
 	networks []*structs.NetworkResource
 
+	// groupNetworks are the network resources shared by all tasks in the
+	// allocation.
+	groupNetworks []*structs.NetworkResource
+
 	mu *sync.RWMutex
 }
 
@@ -304,6 +308,7 @@ func (b *Builder) Build() *TaskEnv {
 	}
 
 	// Build the network related env vars
+	buildNetworkEnv(envMap, b.groupNetworks, nil)
 	buildNetworkEnv(envMap, b.networks, b.driverNetwork)
 
 	// Build the addr of the other tasks
@@ -399,6 +404,15 @@ func (b *Builder) setAlloc(alloc *structs.Allocation) *Builder {
 		b.taskMeta[fmt.Sprintf("%s%s", MetaPrefix, k)] = v
 	}
 
+	// Add ports shared by the task group
+	b.groupNetworks = nil
+	if alloc.SharedResources != nil {
+		b.groupNetworks = make([]*structs.NetworkResource, len(alloc.SharedResources.Networks))
+		for i, n := range alloc.SharedResources.Networks {
+			b.groupNetworks[i] = n.Copy()
+		}
+	}
+
 	// Add ports from other tasks
 	b.otherPorts = make(map[string]string, len(alloc.TaskResources)*2)
 	for taskName, resources := range alloc.TaskResources {
@@ -325,6 +325,36 @@ func TestEnvironment_DashesInTaskName(t *testing.T) {
 	}
 }
 
+// TestEnvironment_GroupNetwork asserts ports from the task group's shared
+// network are added to the environment.
+func TestEnvironment_GroupNetwork(t *testing.T) {
+	a := mock.Alloc()
+	a.SharedResources.Networks = []*structs.NetworkResource{
+		{
+			Mode:          structs.NetworkModeBridge,
+			IP:            "10.0.0.5",
+			MBits:         10,
+			ReservedPorts: []structs.Port{{Label: "admin", Value: 8080}},
+			DynamicPorts:  []structs.Port{{Label: "http", Value: 22000}},
+		},
+	}
+	task := a.Job.TaskGroups[0].Tasks[0]
+	task.Resources.Networks = nil
+	envMap := NewBuilder(mock.Node(), a, task, "global").Build().Map()
+
+	exp := map[string]string{
+		"NOMAD_PORT_admin": "8080",
+		"NOMAD_ADDR_admin": "10.0.0.5:8080",
+		"NOMAD_PORT_http":  "22000",
+		"NOMAD_IP_http":    "10.0.0.5",
+	}
+	for k, v := range exp {
+		if act := envMap[k]; act != v {
+			t.Fatalf("expected %s=%q but found %q", k, v, act)
+		}
+	}
+}
+
 // TestEnvironment_UpdateTask asserts env vars and task meta are updated when a
 // task is updated.
 func TestEnvironment_UpdateTask(t *testing.T) {
@@ -118,12 +118,13 @@ func (d *ExecDriver) Start(ctx *ExecContext, task *structs.Task) (*StartResponse
 		return nil, err
 	}
 	executorCtx := &executor.ExecutorContext{
-		TaskEnv: ctx.TaskEnv,
-		Driver:  "exec",
-		AllocID: d.DriverContext.allocID,
-		LogDir:  ctx.TaskDir.LogDir,
-		TaskDir: ctx.TaskDir.Dir,
-		Task:    task,
+		TaskEnv:     ctx.TaskEnv,
+		Driver:      "exec",
+		AllocID:     d.DriverContext.allocID,
+		LogDir:      ctx.TaskDir.LogDir,
+		TaskDir:     ctx.TaskDir.Dir,
+		Task:        task,
+		SharedCores: ctx.SharedCores,
 	}
 	if err := exec.SetContext(executorCtx); err != nil {
 		pluginClient.Kill()
@@ -131,11 +132,12 @@ func (d *ExecDriver) Start(ctx *ExecContext, task *structs.Task) (*StartResponse
 	}
 
 	execCmd := &executor.ExecCommand{
-		Cmd:            command,
-		Args:           driverConfig.Args,
-		FSIsolation:    true,
-		ResourceLimits: true,
-		User:           getExecutorUser(task),
+		Cmd:              command,
+		Args:             driverConfig.Args,
+		FSIsolation:      true,
+		ResourceLimits:   true,
+		User:             getExecutorUser(task),
+		NetworkNamespace: networkNamespace(ctx),
 	}
 
 	ps, err := exec.LaunchCmd(execCmd)
@@ -252,6 +254,11 @@ func (h *execHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+// UpdateCpuset moves the task onto the given shared cores.
+func (h *execHandle) UpdateCpuset(cores []uint16) error {
+	return h.executor.UpdateCpuset(cores)
+}
+
 func (h *execHandle) Exec(ctx context.Context, cmd string, args []string) ([]byte, int, error) {
 	deadline, ok := ctx.Deadline()
 	if !ok {
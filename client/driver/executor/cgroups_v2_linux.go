@@ -0,0 +1,375 @@
+package executor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	cgroupConfig "github.com/opencontainers/runc/libcontainer/configs"
+
+	"github.com/hashicorp/nomad/helper/cgutil"
+)
+
+const (
+	// cgroupV2PathKey is the key of the cgroup path in the paths returned by
+	// the v2 manager. The unified hierarchy has a single path for all
+	// controllers.
+	cgroupV2PathKey = "unified"
+
+	// cgroupV2Parent is the cgroup task cgroups are created under on hosts
+	// using the unified hierarchy
+	cgroupV2Parent = "nomad.slice"
+)
+
+var (
+	// cgroupV2Controllers are the controllers enabled for task cgroups
+	cgroupV2Controllers = []string{"cpu", "cpuset", "io", "memory", "pids"}
+)
+
+// cgroupV2Manager manages a cgroup in the unified (v2) cgroup hierarchy. It
+// implements the libcontainer cgroup manager interface so it can be used in
+// place of the v1 manager.
+type cgroupV2Manager struct {
+	cgroups *cgroupConfig.Cgroup
+	path    string
+}
+
+// newCgroupV2Manager returns a manager for the cgroup at the stored path if
+// given, otherwise the path of the passed cgroup config is used.
+func newCgroupV2Manager(groups *cgroupConfig.Cgroup, paths map[string]string) *cgroupV2Manager {
+	path := paths[cgroupV2PathKey]
+	if path == "" {
+		path = filepath.Join(cgutil.UnifiedMountpoint, groups.Path)
+	}
+	return &cgroupV2Manager{cgroups: groups, path: path}
+}
+
+// Apply creates the cgroup and enters the process with the given pid
+func (m *cgroupV2Manager) Apply(pid int) error {
+	if err := m.enableControllers(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.path, 0755); err != nil {
+		return err
+	}
+	return writeCgroupV2File(m.path, "cgroup.procs", strconv.Itoa(pid))
+}
+
+// enableControllers enables the controllers tasks are limited by in each of
+// the ancestors of the cgroup. Controllers the kernel does not provide are
+// skipped.
+func (m *cgroupV2Manager) enableControllers() error {
+	if m.path == cgutil.UnifiedMountpoint {
+		return nil
+	}
+	rel, err := filepath.Rel(cgutil.UnifiedMountpoint, filepath.Dir(m.path))
+	if err != nil {
+		return err
+	}
+	dirs := []string{cgutil.UnifiedMountpoint}
+	if rel != "." {
+		dir := cgutil.UnifiedMountpoint
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			dir = filepath.Join(dir, part)
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		available, err := readCgroupV2File(dir, "cgroup.controllers")
+		if err != nil {
+			return err
+		}
+		availableSet := make(map[string]struct{})
+		for _, c := range strings.Fields(available) {
+			availableSet[c] = struct{}{}
+		}
+
+		var enable []string
+		for _, c := range cgroupV2Controllers {
+			if _, ok := availableSet[c]; ok {
+				enable = append(enable, "+"+c)
+			}
+		}
+		if len(enable) == 0 {
+			continue
+		}
+		if err := writeCgroupV2File(dir, "cgroup.subtree_control", strings.Join(enable, " ")); err != nil {
+			return fmt.Errorf("failed to enable cgroup controllers in %q: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+// GetPids returns the pids in the cgroup
+func (m *cgroupV2Manager) GetPids() ([]int, error) {
+	return readCgroupV2Procs(m.path)
+}
+
+// GetAllPids returns the pids in the cgroup and all its descendants
+func (m *cgroupV2Manager) GetAllPids() ([]int, error) {
+	var pids []int
+	err := filepath.Walk(m.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		dirPids, err := readCgroupV2Procs(path)
+		if err != nil {
+			return err
+		}
+		pids = append(pids, dirPids...)
+		return nil
+	})
+	return pids, err
+}
+
+// GetStats returns the memory and cpu usage of the cgroup, converted to the
+// v1 statistics the executor reports.
+func (m *cgroupV2Manager) GetStats() (*cgroups.Stats, error) {
+	stats := cgroups.NewStats()
+	if err := getCgroupV2MemoryStats(m.path, stats); err != nil {
+		return nil, err
+	}
+	if err := getCgroupV2CpuStats(m.path, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Freeze freezes or thaws the processes in the cgroup
+func (m *cgroupV2Manager) Freeze(state cgroupConfig.FreezerState) error {
+	switch state {
+	case cgroupConfig.Frozen:
+		return writeCgroupV2File(m.path, "cgroup.freeze", "1")
+	case cgroupConfig.Thawed:
+		return writeCgroupV2File(m.path, "cgroup.freeze", "0")
+	default:
+		return fmt.Errorf("invalid freezer state %q", state)
+	}
+}
+
+// Destroy removes the cgroup
+func (m *cgroupV2Manager) Destroy() error {
+	if m.path == cgutil.UnifiedMountpoint {
+		return nil
+	}
+	return cgroups.RemovePaths(map[string]string{cgroupV2PathKey: m.path})
+}
+
+// GetPaths returns the path of the cgroup so the manager can be restored
+func (m *cgroupV2Manager) GetPaths() map[string]string {
+	return map[string]string{cgroupV2PathKey: m.path}
+}
+
+// Set applies the resource limits of the container to the cgroup. The v1
+// limits are converted to their v2 equivalents.
+func (m *cgroupV2Manager) Set(container *cgroupConfig.Config) error {
+	r := container.Cgroups.Resources
+	if r == nil {
+		return nil
+	}
+
+	if r.Memory > 0 {
+		if err := writeCgroupV2File(m.path, "memory.max", strconv.FormatInt(r.Memory, 10)); err != nil {
+			return err
+		}
+	}
+	if r.MemoryReservation > 0 {
+		if err := writeCgroupV2File(m.path, "memory.low", strconv.FormatInt(r.MemoryReservation, 10)); err != nil {
+			return err
+		}
+	}
+
+	// Swap accounting may be disabled in which case there is no swap limit
+	// to set
+	if swap := convertMemorySwapToV2(r.Memory, r.MemorySwap); swap != "" {
+		if _, err := os.Stat(filepath.Join(m.path, "memory.swap.max")); err == nil {
+			if err := writeCgroupV2File(m.path, "memory.swap.max", swap); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.CpuShares > 0 {
+		weight := convertCPUSharesToWeight(uint64(r.CpuShares))
+		if err := writeCgroupV2File(m.path, "cpu.weight", strconv.FormatUint(weight, 10)); err != nil {
+			return err
+		}
+	}
+	if r.CpusetCpus != "" {
+		if err := writeCgroupV2File(m.path, "cpuset.cpus", r.CpusetCpus); err != nil {
+			return err
+		}
+	}
+	if r.BlkioWeight > 0 {
+		weight := convertBlkioWeightToIOWeight(uint64(r.BlkioWeight))
+		if err := writeCgroupV2File(m.path, "io.weight", fmt.Sprintf("default %d", weight)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertCPUSharesToWeight converts v1 cpu shares in the range [2, 262144]
+// to a v2 cpu weight in the range [1, 10000].
+func convertCPUSharesToWeight(shares uint64) uint64 {
+	if shares < 2 {
+		shares = 2
+	} else if shares > 262144 {
+		shares = 262144
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// convertBlkioWeightToIOWeight converts a v1 blkio weight in the range
+// [10, 1000] to a v2 io weight in the range [1, 10000].
+func convertBlkioWeightToIOWeight(weight uint64) uint64 {
+	if weight < 10 {
+		weight = 10
+	} else if weight > 1000 {
+		weight = 1000
+	}
+	return 1 + ((weight-10)*9999)/990
+}
+
+// convertMemorySwapToV2 converts the v1 memory+swap limit to the v2 swap
+// limit, which excludes memory. An empty string is returned if there is no
+// swap limit.
+func convertMemorySwapToV2(memory, memorySwap int64) string {
+	switch {
+	case memorySwap == -1:
+		return "max"
+	case memorySwap == 0:
+		return ""
+	case memorySwap <= memory:
+		return "0"
+	default:
+		return strconv.FormatInt(memorySwap-memory, 10)
+	}
+}
+
+// getCgroupV2MemoryStats populates the memory stats from the cgroup's memory
+// files
+func getCgroupV2MemoryStats(path string, stats *cgroups.Stats) error {
+	memStat, err := readCgroupV2KeyValues(path, "memory.stat")
+	if err != nil {
+		return err
+	}
+	for k, v := range memStat {
+		stats.MemoryStats.Stats[k] = v
+	}
+
+	// Expose the v2 statistics under the names used by v1
+	stats.MemoryStats.Stats["rss"] = memStat["anon"]
+	stats.MemoryStats.Stats["cache"] = memStat["file"]
+	stats.MemoryStats.Cache = memStat["file"]
+	if kernel, ok := memStat["kernel"]; ok {
+		stats.MemoryStats.KernelUsage.Usage = kernel
+	} else {
+		stats.MemoryStats.KernelUsage.Usage = memStat["kernel_stack"] + memStat["slab"] + memStat["sock"]
+	}
+
+	if stats.MemoryStats.Usage.Usage, err = readCgroupV2Uint(path, "memory.current"); err != nil {
+		return err
+	}
+
+	// The peak and swap usage are not available on every kernel
+	if peak, err := readCgroupV2Uint(path, "memory.peak"); err == nil {
+		stats.MemoryStats.Usage.MaxUsage = peak
+	}
+	if swap, err := readCgroupV2Uint(path, "memory.swap.current"); err == nil {
+		stats.MemoryStats.SwapUsage.Usage = swap
+	}
+	return nil
+}
+
+// getCgroupV2CpuStats populates the cpu stats from the cgroup's cpu.stat
+// file. The v2 times are in microseconds while v1 reports nanoseconds.
+func getCgroupV2CpuStats(path string, stats *cgroups.Stats) error {
+	cpuStat, err := readCgroupV2KeyValues(path, "cpu.stat")
+	if err != nil {
+		return err
+	}
+
+	stats.CpuStats.CpuUsage.TotalUsage = cpuStat["usage_usec"] * 1000
+	stats.CpuStats.CpuUsage.UsageInUsermode = cpuStat["user_usec"] * 1000
+	stats.CpuStats.CpuUsage.UsageInKernelmode = cpuStat["system_usec"] * 1000
+	stats.CpuStats.ThrottlingData.Periods = cpuStat["nr_periods"]
+	stats.CpuStats.ThrottlingData.ThrottledPeriods = cpuStat["nr_throttled"]
+	stats.CpuStats.ThrottlingData.ThrottledTime = cpuStat["throttled_usec"] * 1000
+	return nil
+}
+
+// readCgroupV2KeyValues parses a cgroup file made up of "key value" lines
+func readCgroupV2KeyValues(path, file string) (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(path, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = v
+	}
+	return values, sc.Err()
+}
+
+// readCgroupV2Uint reads a cgroup file containing a single integer
+func readCgroupV2Uint(path, file string) (uint64, error) {
+	contents, err := readCgroupV2File(path, file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(contents, 10, 64)
+}
+
+// readCgroupV2Procs returns the pids listed in the cgroup.procs file
+func readCgroupV2Procs(path string) ([]int, error) {
+	contents, err := readCgroupV2File(path, "cgroup.procs")
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, field := range strings.Fields(contents) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid %q: %v", field, err)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func readCgroupV2File(path, file string) (string, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(path, file))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
+
+func writeCgroupV2File(path, file, value string) error {
+	return ioutil.WriteFile(filepath.Join(path, file), []byte(value), 0700)
+}
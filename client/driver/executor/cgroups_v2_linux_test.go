@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cgroupConfig "github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestCgroupV2_ConvertLimits(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		shares, weight uint64
+	}{
+		{2, 1},
+		{1024, 39},
+		{262144, 10000},
+		{1, 1},
+		{300000, 10000},
+	}
+	for _, c := range cases {
+		if act := convertCPUSharesToWeight(c.shares); act != c.weight {
+			t.Fatalf("cpu shares %d: got weight %d; want %d", c.shares, act, c.weight)
+		}
+	}
+
+	if act := convertBlkioWeightToIOWeight(10); act != 1 {
+		t.Fatalf("blkio weight 10: got %d; want 1", act)
+	}
+	if act := convertBlkioWeightToIOWeight(1000); act != 10000 {
+		t.Fatalf("blkio weight 1000: got %d; want 10000", act)
+	}
+
+	swapCases := []struct {
+		memory, swap int64
+		exp          string
+	}{
+		{256, -1, "max"},
+		{256, 0, ""},
+		{256, 256, "0"},
+		{256, 1024, "768"},
+	}
+	for _, c := range swapCases {
+		if act := convertMemorySwapToV2(c.memory, c.swap); act != c.exp {
+			t.Fatalf("memory %d swap %d: got %q; want %q", c.memory, c.swap, act, c.exp)
+		}
+	}
+}
+
+func TestCgroupV2_Set(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "cgroupv2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The swap limit is only set if swap accounting is enabled
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.swap.max"), nil, 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	m := newCgroupV2Manager(nil, map[string]string{cgroupV2PathKey: dir})
+	container := &cgroupConfig.Config{
+		Cgroups: &cgroupConfig.Cgroup{
+			Resources: &cgroupConfig.Resources{
+				Memory:            1024 * 1024 * 1024,
+				MemoryReservation: 256 * 1024 * 1024,
+				MemorySwap:        -1,
+				CpuShares:         1024,
+				CpusetCpus:        "0-1",
+				BlkioWeight:       500,
+			},
+		},
+	}
+	if err := m.Set(container); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := map[string]string{
+		"memory.max":      "1073741824",
+		"memory.low":      "268435456",
+		"memory.swap.max": "max",
+		"cpu.weight":      "39",
+		"cpuset.cpus":     "0-1",
+		"io.weight":       "default 4950",
+	}
+	for file, exp := range expected {
+		act, err := readCgroupV2File(dir, file)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if act != exp {
+			t.Fatalf("%s: got %q; want %q", file, act, exp)
+		}
+	}
+
+	if act, exp := m.GetPaths(), map[string]string{cgroupV2PathKey: dir}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("paths: got %v; want %v", act, exp)
+	}
+}
+
+func TestCgroupV2_GetStats(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "cgroupv2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"memory.stat":         "anon 1000\nfile 2000\nkernel 300\nslab 100\n",
+		"memory.current":      "3500\n",
+		"memory.peak":         "5000\n",
+		"memory.swap.current": "40\n",
+		"cpu.stat":            "usage_usec 30\nuser_usec 20\nsystem_usec 10\nnr_periods 5\nnr_throttled 2\nthrottled_usec 7\n",
+	}
+	for file, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(contents), 0600); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	m := newCgroupV2Manager(nil, map[string]string{cgroupV2PathKey: dir})
+	stats, err := m.GetStats()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	mem := stats.MemoryStats
+	if mem.Stats["rss"] != 1000 || mem.Stats["cache"] != 2000 {
+		t.Fatalf("bad rss/cache: %v", mem.Stats)
+	}
+	if mem.Usage.Usage != 3500 || mem.Usage.MaxUsage != 5000 {
+		t.Fatalf("bad usage: %+v", mem.Usage)
+	}
+	if mem.SwapUsage.Usage != 40 {
+		t.Fatalf("bad swap: %+v", mem.SwapUsage)
+	}
+	if mem.KernelUsage.Usage != 300 {
+		t.Fatalf("bad kernel usage: %+v", mem.KernelUsage)
+	}
+
+	cpu := stats.CpuStats
+	if cpu.CpuUsage.TotalUsage != 30000 || cpu.CpuUsage.UsageInUsermode != 20000 || cpu.CpuUsage.UsageInKernelmode != 10000 {
+		t.Fatalf("bad cpu usage: %+v", cpu.CpuUsage)
+	}
+	if cpu.ThrottlingData.Periods != 5 || cpu.ThrottlingData.ThrottledPeriods != 2 || cpu.ThrottlingData.ThrottledTime != 7000 {
+		t.Fatalf("bad throttling: %+v", cpu.ThrottlingData)
+	}
+}
+
+func TestCgroupV2_GetAllPids(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "cgroupv2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	child := filepath.Join(dir, "child")
+	if err := os.Mkdir(child, 0700); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := writeCgroupV2File(dir, "cgroup.procs", strings.Join([]string{"10", "11"}, "\n")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := writeCgroupV2File(child, "cgroup.procs", "12\n"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	m := newCgroupV2Manager(nil, map[string]string{cgroupV2PathKey: dir})
+	pids, err := m.GetPids()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if exp := []int{10, 11}; !reflect.DeepEqual(pids, exp) {
+		t.Fatalf("pids: got %v; want %v", pids, exp)
+	}
+
+	all, err := m.GetAllPids()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if exp := []int{10, 11, 12}; !reflect.DeepEqual(all, exp) {
+		t.Fatalf("all pids: got %v; want %v", all, exp)
+	}
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -56,6 +57,7 @@ type Executor interface {
 	Exit() error
 	UpdateLogConfig(logConfig *structs.LogConfig) error
 	UpdateTask(task *structs.Task) error
+	UpdateCpuset(cores []uint16) error
 	Version() (*ExecutorVersion, error)
 	Stats() (*cstructs.TaskResourceUsage, error)
 	Signal(s os.Signal) error
@@ -90,6 +92,10 @@ type ExecutorContext struct {
 	// PortLowerBound is the lower bound of the ports that we can use to start
 	// the syslog server
 	PortLowerBound uint
+
+	// SharedCores are the CPU cores the task runs on if it has no reserved
+	// cores. Empty if the task is not restricted to a set of cores.
+	SharedCores []uint16
 }
 
 // ExecCommand holds the user command, args, and other isolation related
@@ -110,6 +116,10 @@ type ExecCommand struct {
 	// ResourceLimits determines whether resource limits are enforced by the
 	// executor.
 	ResourceLimits bool
+
+	// NetworkNamespace is the path of the network namespace the command is
+	// run in. If empty the command is run in the executor's namespace.
+	NetworkNamespace string
 }
 
 // ProcessState holds information about the state of a user process.
@@ -163,6 +173,10 @@ type UniversalExecutor struct {
 	lro         *logging.FileRotator
 	rotatorLock sync.Mutex
 
+	// logShippers ship the task's logs to sinks in addition to the rotated
+	// files. They are keyed by the shipper name.
+	logShippers map[string]*logging.LogShipper
+
 	syslogServer *logging.SyslogServer
 	syslogChan   chan *logging.SyslogMessage
 
@@ -244,8 +258,7 @@ func (e *UniversalExecutor) LaunchCmd(command *ExecCommand) (*ProcessState, erro
 	if err := e.configureLoggers(); err != nil {
 		return nil, err
 	}
-	e.cmd.Stdout = e.lro
-	e.cmd.Stderr = e.lre
+	e.cmd.Stdout, e.cmd.Stderr = e.logWriters()
 
 	// Look up the binary path and make it executable
 	absPath, err := e.lookupBin(e.ctx.TaskEnv.ReplaceEnv(command.Cmd))
@@ -274,7 +287,7 @@ func (e *UniversalExecutor) LaunchCmd(command *ExecCommand) (*ProcessState, erro
 	e.cmd.Env = e.ctx.TaskEnv.List()
 
 	// Start the process
-	if err := e.cmd.Start(); err != nil {
+	if err := withNetworkNamespace(command.NetworkNamespace, e.cmd.Start); err != nil {
 		return nil, fmt.Errorf("failed to start command path=%q --- args=%q: %v", path, e.cmd.Args, err)
 	}
 	go e.collectPids()
@@ -287,7 +300,18 @@ func (e *UniversalExecutor) LaunchCmd(command *ExecCommand) (*ProcessState, erro
 func (e *UniversalExecutor) Exec(deadline time.Time, name string, args []string) ([]byte, int, error) {
 	ctx, cancel := context.WithDeadline(context.Background(), deadline)
 	defer cancel()
-	return ExecScript(ctx, e.cmd.Dir, e.ctx.TaskEnv, e.cmd.SysProcAttr, name, args)
+
+	var out []byte
+	var code int
+	var err error
+	nsErr := withNetworkNamespace(e.command.NetworkNamespace, func() error {
+		out, code, err = ExecScript(ctx, e.cmd.Dir, e.ctx.TaskEnv, e.cmd.SysProcAttr, name, args)
+		return nil
+	})
+	if nsErr != nil {
+		return nil, 0, nsErr
+	}
+	return out, code, err
 }
 
 // ExecScript executes cmd with args and returns the output, exit code, and
@@ -351,9 +375,47 @@ func (e *UniversalExecutor) configureLoggers() error {
 		}
 		e.lre = lre
 	}
+	logging.SetTaskLogConfig(e.ctx.Task.LogConfig, e.lro, e.lre)
+
+	if e.logShippers == nil && len(e.ctx.Task.LogShippers) != 0 {
+		e.logShippers = make(map[string]*logging.LogShipper, len(e.ctx.Task.LogShippers))
+		for _, config := range e.ctx.Task.LogShippers {
+			e.logShippers[config.Name] = logging.NewLogShipper(config, e.ctx.AllocID, e.ctx.Task.Name, e.logger)
+		}
+	}
 	return nil
 }
 
+// logWriters returns the writers the task's stdout and stderr are written
+// to. The log shippers are fed the same output as the file rotators.
+func (e *UniversalExecutor) logWriters() (stdout io.Writer, stderr io.Writer) {
+	if len(e.logShippers) == 0 {
+		return e.lro, e.lre
+	}
+
+	stdouts := []io.Writer{e.lro}
+	stderrs := []io.Writer{e.lre}
+	for _, shipper := range e.logShippers {
+		stdouts = append(stdouts, shipper.Writer(logging.StreamStdout))
+		stderrs = append(stderrs, shipper.Writer(logging.StreamStderr))
+	}
+	return io.MultiWriter(stdouts...), io.MultiWriter(stderrs...)
+}
+
+// logShipperStats returns the delivery statistics of the log shippers or nil
+// if the task has none
+func (e *UniversalExecutor) logShipperStats() map[string]*cstructs.LogShipperStats {
+	if len(e.logShippers) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]*cstructs.LogShipperStats, len(e.logShippers))
+	for name, shipper := range e.logShippers {
+		stats[name] = shipper.Stats()
+	}
+	return stats
+}
+
 // Wait waits until a process has exited and returns it's exitcode and errors
 func (e *UniversalExecutor) Wait() (*ProcessState, error) {
 	<-e.processExited
@@ -367,14 +429,10 @@ func (e *UniversalExecutor) UpdateLogConfig(logConfig *structs.LogConfig) error
 	if e.lro == nil {
 		return fmt.Errorf("log rotator for stdout doesn't exist")
 	}
-	e.lro.MaxFiles = logConfig.MaxFiles
-	e.lro.FileSize = int64(logConfig.MaxFileSizeMB * 1024 * 1024)
-
 	if e.lre == nil {
 		return fmt.Errorf("log rotator for stderr doesn't exist")
 	}
-	e.lre.MaxFiles = logConfig.MaxFiles
-	e.lre.FileSize = int64(logConfig.MaxFileSizeMB * 1024 * 1024)
+	logging.SetTaskLogConfig(logConfig, e.lro, e.lre)
 	return nil
 }
 
@@ -384,11 +442,7 @@ func (e *UniversalExecutor) UpdateTask(task *structs.Task) error {
 	// Updating Log Config
 	e.rotatorLock.Lock()
 	if e.lro != nil && e.lre != nil {
-		fileSize := int64(task.LogConfig.MaxFileSizeMB * 1024 * 1024)
-		e.lro.MaxFiles = task.LogConfig.MaxFiles
-		e.lro.FileSize = fileSize
-		e.lre.MaxFiles = task.LogConfig.MaxFiles
-		e.lre.FileSize = fileSize
+		logging.SetTaskLogConfig(task.LogConfig, e.lro, e.lre)
 	}
 	e.rotatorLock.Unlock()
 	return nil
@@ -459,6 +513,10 @@ func (e *UniversalExecutor) Exit() error {
 		e.lro.Close()
 	}
 
+	for _, shipper := range e.logShippers {
+		shipper.Shutdown()
+	}
+
 	// If the executor did not launch a process, return.
 	if e.command == nil {
 		return nil
@@ -759,6 +817,7 @@ func (e *UniversalExecutor) aggregatedResourceUsage(pidStats map[string]*cstruct
 		ResourceUsage: &resourceUsage,
 		Timestamp:     ts,
 		Pids:          pidStats,
+		LogShippers:   e.logShipperStats(),
 	}
 }
 
@@ -44,3 +44,7 @@ func (e *UniversalExecutor) getAllPids() (map[int]*nomadPid, error) {
 	}
 	return e.scanPids(os.Getpid(), allProcesses)
 }
+
+func (e *UniversalExecutor) UpdateCpuset(cores []uint16) error {
+	return nil
+}
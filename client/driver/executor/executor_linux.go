@@ -18,6 +18,7 @@ import (
 
 	"github.com/hashicorp/nomad/client/stats"
 	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/cgutil"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -26,6 +27,10 @@ var (
 	// The statistics the executor exposes when using cgroups
 	ExecutorCgroupMeasuredMemStats = []string{"RSS", "Cache", "Swap", "Max Usage", "Kernel Usage", "Kernel Max Usage"}
 	ExecutorCgroupMeasuredCpuStats = []string{"System Mode", "User Mode", "Throttled Periods", "Throttled Time", "Percent"}
+
+	// The memory statistics the executor exposes when using the unified (v2)
+	// cgroup hierarchy, which does not track the maximum kernel memory usage
+	ExecutorCgroupV2MeasuredMemStats = []string{"RSS", "Cache", "Swap", "Max Usage", "Kernel Usage"}
 )
 
 // configureIsolation configures chroot and creates cgroups
@@ -37,7 +42,7 @@ func (e *UniversalExecutor) configureIsolation() error {
 	}
 
 	if e.command.ResourceLimits {
-		if err := e.configureCgroups(e.ctx.Task.Resources); err != nil {
+		if err := e.configureCgroups(e.ctx.Task.Resources, e.ctx.SharedCores); err != nil {
 			return fmt.Errorf("error creating cgroups: %v", err)
 		}
 	}
@@ -70,11 +75,15 @@ func (e *UniversalExecutor) applyLimits(pid int) error {
 
 // configureCgroups converts a Nomad Resources specification into the equivalent
 // cgroup configuration. It returns an error if the resources are invalid.
-func (e *UniversalExecutor) configureCgroups(resources *structs.Resources) error {
+func (e *UniversalExecutor) configureCgroups(resources *structs.Resources, sharedCores []uint16) error {
 	e.resConCtx.groups = &cgroupConfig.Cgroup{}
 	e.resConCtx.groups.Resources = &cgroupConfig.Resources{}
 	cgroupName := uuid.Generate()
-	e.resConCtx.groups.Path = filepath.Join("/nomad", cgroupName)
+	if cgutil.UseV2() {
+		e.resConCtx.groups.Path = filepath.Join("/", cgroupV2Parent, cgroupName)
+	} else {
+		e.resConCtx.groups.Path = filepath.Join("/nomad", cgroupName)
+	}
 
 	// TODO: verify this is needed for things like network access
 	e.resConCtx.groups.Resources.AllowAllDevices = true
@@ -86,6 +95,13 @@ func (e *UniversalExecutor) configureCgroups(resources *structs.Resources) error
 		e.resConCtx.groups.Resources.MemorySwap = int64(-1)
 	}
 
+	if resources.MemoryMaxMB > resources.MemoryMB {
+		// Allow bursting up to the memory max with the reserved memory as
+		// the soft limit
+		e.resConCtx.groups.Resources.Memory = int64(resources.MemoryMaxMB * 1024 * 1024)
+		e.resConCtx.groups.Resources.MemoryReservation = int64(resources.MemoryMB * 1024 * 1024)
+	}
+
 	if resources.CPU < 2 {
 		return fmt.Errorf("resources.CPU must be equal to or greater than 2: %v", resources.CPU)
 	}
@@ -93,6 +109,14 @@ func (e *UniversalExecutor) configureCgroups(resources *structs.Resources) error
 	// Set the relative CPU shares for this cgroup.
 	e.resConCtx.groups.Resources.CpuShares = int64(resources.CPU)
 
+	// Pin the task to its reserved cores. Otherwise keep it off the cores
+	// reserved by other tasks.
+	if len(resources.CoreIDs) != 0 {
+		e.resConCtx.groups.Resources.CpusetCpus = structs.CpusetString(resources.CoreIDs)
+	} else if len(sharedCores) != 0 {
+		e.resConCtx.groups.Resources.CpusetCpus = structs.CpusetString(sharedCores)
+	}
+
 	if resources.IOPS != 0 {
 		// Validate it is in an acceptable range.
 		if resources.IOPS < 10 || resources.IOPS > 1000 {
@@ -137,6 +161,9 @@ func (e *UniversalExecutor) Stats() (*cstructs.TaskResourceUsage, error) {
 		KernelMaxUsage: stats.MemoryStats.KernelUsage.MaxUsage,
 		Measured:       ExecutorCgroupMeasuredMemStats,
 	}
+	if cgutil.UseV2() {
+		ms.Measured = ExecutorCgroupV2MeasuredMemStats
+	}
 
 	// CPU Related Stats
 	totalProcessCPUUsage := float64(stats.CpuStats.CpuUsage.TotalUsage)
@@ -158,7 +185,8 @@ func (e *UniversalExecutor) Stats() (*cstructs.TaskResourceUsage, error) {
 			MemoryStats: ms,
 			CpuStats:    cs,
 		},
-		Timestamp: ts.UTC().UnixNano(),
+		Timestamp:   ts.UTC().UnixNano(),
+		LogShippers: e.logShipperStats(),
 	}
 	if pidStats, err := e.pidStats(); err == nil {
 		taskResUsage.Pids = pidStats
@@ -330,7 +358,36 @@ func DestroyCgroup(groups *cgroupConfig.Cgroup, cgPaths map[string]string, execu
 	return mErrs.ErrorOrNil()
 }
 
+// UpdateCpuset moves a task without reserved cores onto the given shared
+// cores.
+func (e *UniversalExecutor) UpdateCpuset(cores []uint16) error {
+	if e.command == nil || !e.command.ResourceLimits || len(cores) == 0 {
+		return nil
+	}
+
+	// Tasks with reserved cores stay pinned to them
+	if len(e.ctx.Task.Resources.CoreIDs) != 0 {
+		return nil
+	}
+
+	e.resConCtx.cgLock.Lock()
+	defer e.resConCtx.cgLock.Unlock()
+	if e.resConCtx.groups == nil || e.resConCtx.cgPaths == nil {
+		return nil
+	}
+
+	e.resConCtx.groups.Resources.CpusetCpus = structs.CpusetString(cores)
+	manager := getCgroupManager(e.resConCtx.groups, e.resConCtx.cgPaths)
+	if err := manager.Set(&cgroupConfig.Config{Cgroups: e.resConCtx.groups}); err != nil {
+		return fmt.Errorf("failed to update cpuset: %v", err)
+	}
+	return nil
+}
+
 // getCgroupManager returns the correct libcontainer cgroup manager.
 func getCgroupManager(groups *cgroupConfig.Cgroup, paths map[string]string) cgroups.Manager {
+	if cgutil.UseV2() {
+		return newCgroupV2Manager(groups, paths)
+	}
 	return &cgroupFs.Manager{Cgroups: groups, Paths: paths}
 }
@@ -16,6 +16,7 @@ import (
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/client/testutil"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
 // testExecutorContextWithChroot returns an ExecutorContext and AllocDir with
@@ -139,6 +140,67 @@ ld.so.conf.d/`
 	}
 }
 
+func TestExecutor_ConfigureCgroups_MemoryMax(t *testing.T) {
+	t.Parallel()
+	e := &UniversalExecutor{}
+
+	// Without a memory max the reserved memory is the hard limit
+	resources := &structs.Resources{CPU: 100, MemoryMB: 256}
+	if err := e.configureCgroups(resources, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if act, exp := e.resConCtx.groups.Resources.Memory, int64(256*1024*1024); act != exp {
+		t.Fatalf("memory limit: got %d; want %d", act, exp)
+	}
+	if act := e.resConCtx.groups.Resources.MemoryReservation; act != 0 {
+		t.Fatalf("memory reservation: got %d; want 0", act)
+	}
+
+	// The memory max is the hard limit and the reserved memory the soft limit
+	resources.MemoryMaxMB = 1024
+	if err := e.configureCgroups(resources, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if act, exp := e.resConCtx.groups.Resources.Memory, int64(1024*1024*1024); act != exp {
+		t.Fatalf("memory limit: got %d; want %d", act, exp)
+	}
+	if act, exp := e.resConCtx.groups.Resources.MemoryReservation, int64(256*1024*1024); act != exp {
+		t.Fatalf("memory reservation: got %d; want %d", act, exp)
+	}
+}
+
+func TestExecutor_ConfigureCgroups_Cpuset(t *testing.T) {
+	t.Parallel()
+	e := &UniversalExecutor{}
+
+	// Tasks without reserved cores run on the shared cores
+	resources := &structs.Resources{CPU: 100, MemoryMB: 256}
+	if err := e.configureCgroups(resources, []uint16{2, 3, 4}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if act, exp := e.resConCtx.groups.Resources.CpusetCpus, "2-4"; act != exp {
+		t.Fatalf("cpuset: got %q; want %q", act, exp)
+	}
+
+	// Tasks with reserved cores are pinned to them
+	resources.CoreIDs = []uint16{0, 1}
+	if err := e.configureCgroups(resources, []uint16{2, 3, 4}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if act, exp := e.resConCtx.groups.Resources.CpusetCpus, "0-1"; act != exp {
+		t.Fatalf("cpuset: got %q; want %q", act, exp)
+	}
+
+	// Tasks are not restricted if there are no shared cores
+	resources.CoreIDs = nil
+	if err := e.configureCgroups(resources, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if act := e.resConCtx.groups.Resources.CpusetCpus; act != "" {
+		t.Fatalf("cpuset: got %q; want none", act)
+	}
+}
+
 func TestExecutor_ClientCleanup(t *testing.T) {
 	t.Parallel()
 	testutil.ExecCompatible(t)
@@ -1,8 +1,11 @@
 package executor
 
 import (
+	"bufio"
+	"encoding/json"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,10 +13,13 @@ import (
 	"testing"
 	"time"
 
+	syslog "github.com/RackSec/srslog"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/driver/env"
+	"github.com/hashicorp/nomad/client/driver/logging"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
 	tu "github.com/hashicorp/nomad/testutil"
 	"github.com/mitchellh/go-ps"
 )
@@ -132,6 +138,65 @@ func TestExecutor_Start_Wait(t *testing.T) {
 	}
 }
 
+func TestExecutor_SyslogServer_LogShipper(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+
+	ctx, allocDir := testExecutorContext(t)
+	defer allocDir.Destroy()
+	ctx.PortLowerBound = 10000
+	ctx.PortUpperBound = 12000
+	ctx.Task.LogShippers = []*structs.LogShipper{
+		{
+			Name:       "json",
+			Type:       structs.LogShipperTypeJSON,
+			Address:    l.Addr().String(),
+			BufferSize: 10,
+		},
+	}
+	executor := NewExecutor(testLogger()).(*UniversalExecutor)
+	if err := executor.SetContext(ctx); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := executor.LaunchSyslogServer(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer executor.Exit()
+
+	// Messages collected by the syslog server are shipped as well
+	executor.syslogChan <- &logging.SyslogMessage{Message: []byte("hello"), Severity: syslog.LOG_INFO}
+	executor.syslogChan <- &logging.SyslogMessage{Message: []byte("oops"), Severity: syslog.LOG_ERR}
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	for _, exp := range []struct{ stream, message string }{
+		{logging.StreamStdout, "hello"},
+		{logging.StreamStderr, "oops"},
+	} {
+		raw, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		var act map[string]interface{}
+		if err := json.Unmarshal(raw, &act); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if act["stream"] != exp.stream || act["message"] != exp.message {
+			t.Fatalf("got %s; want %s from %s", raw, exp.message, exp.stream)
+		}
+	}
+}
+
 func TestExecutor_WaitExitSignal(t *testing.T) {
 	t.Parallel()
 	execCmd := ExecCommand{Cmd: "/bin/sleep", Args: []string{"10000"}}
@@ -29,21 +29,26 @@ func (e *UniversalExecutor) LaunchSyslogServer() (*SyslogServerState, error) {
 
 	e.syslogServer = logging.NewSyslogServer(l, e.syslogChan, e.logger)
 	go e.syslogServer.Start()
-	go e.collectLogs(e.lre, e.lro)
+	stdout, stderr := e.logWriters()
+	go e.collectLogs(stderr, stdout)
 	syslogAddr := fmt.Sprintf("%s://%s", l.Addr().Network(), l.Addr().String())
 	return &SyslogServerState{Addr: syslogAddr}, nil
 }
 
+// collectLogs writes the messages received by the syslog server to the given
+// stderr and stdout writers
 func (e *UniversalExecutor) collectLogs(we io.Writer, wo io.Writer) {
 	for logParts := range e.syslogChan {
+		// Copy the message as it may share its buffer with other messages
+		line := make([]byte, 0, len(logParts.Message)+1)
+		line = append(append(line, logParts.Message...), '\n')
+
 		// If the severity of the log line is err then we write to stderr
 		// otherwise all messages go to stdout
 		if logParts.Severity == syslog.LOG_ERR {
-			e.lre.Write(logParts.Message)
-			e.lre.Write([]byte{'\n'})
+			we.Write(line)
 		} else {
-			e.lro.Write(logParts.Message)
-			e.lro.Write([]byte{'\n'})
+			wo.Write(line)
 		}
 	}
 }
@@ -0,0 +1,14 @@
+// +build !linux
+
+package executor
+
+import "fmt"
+
+// withNetworkNamespace calls fn. Network namespaces are only supported on
+// Linux so an error is returned if path is set.
+func withNetworkNamespace(path string, fn func() error) error {
+	if path != "" {
+		return fmt.Errorf("network namespaces are not supported on this platform")
+	}
+	return fn()
+}
@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// withNetworkNamespace calls fn with the calling OS thread switched into the
+// network namespace at path. Processes started by fn inherit the namespace.
+// If path is empty fn is called in the current namespace.
+func withNetworkNamespace(path string, fn func() error) error {
+	if path == "" {
+		return fn()
+	}
+
+	ns, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open network namespace %q: %v", path, err)
+	}
+	defer ns.Close()
+
+	runtime.LockOSThread()
+	orig, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("failed to open current network namespace: %v", err)
+	}
+	defer orig.Close()
+
+	if err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNET); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("failed to enter network namespace %q: %v", path, err)
+	}
+
+	fnErr := fn()
+
+	// If the original namespace can not be restored the thread is left
+	// locked so the runtime discards it when the goroutine exits.
+	if err := unix.Setns(int(orig.Fd()), unix.CLONE_NEWNET); err != nil {
+		return fmt.Errorf("failed to restore network namespace: %v", err)
+	}
+	runtime.UnlockOSThread()
+	return fnErr
+}
@@ -83,6 +83,10 @@ func (e *ExecutorRPC) UpdateTask(task *structs.Task) error {
 	return e.client.Call("Plugin.UpdateTask", task, new(interface{}))
 }
 
+func (e *ExecutorRPC) UpdateCpuset(cores []uint16) error {
+	return e.client.Call("Plugin.UpdateCpuset", cores, new(interface{}))
+}
+
 func (e *ExecutorRPC) DeregisterServices() error {
 	return e.client.Call("Plugin.DeregisterServices", new(interface{}), new(interface{}))
 }
@@ -166,6 +170,10 @@ func (e *ExecutorRPCServer) UpdateTask(args *structs.Task, resp *interface{}) er
 	return e.Impl.UpdateTask(args)
 }
 
+func (e *ExecutorRPCServer) UpdateCpuset(args []uint16, resp *interface{}) error {
+	return e.Impl.UpdateCpuset(args)
+}
+
 func (e *ExecutorRPCServer) DeregisterServices(args interface{}, resp *interface{}) error {
 	// In 0.6 this is a noop. Goes away in 0.7.
 	return nil
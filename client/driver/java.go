@@ -249,12 +249,13 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (*StartResponse
 
 	// Set the context
 	executorCtx := &executor.ExecutorContext{
-		TaskEnv: ctx.TaskEnv,
-		Driver:  "java",
-		AllocID: d.DriverContext.allocID,
-		Task:    task,
-		TaskDir: ctx.TaskDir.Dir,
-		LogDir:  ctx.TaskDir.LogDir,
+		TaskEnv:     ctx.TaskEnv,
+		Driver:      "java",
+		AllocID:     d.DriverContext.allocID,
+		Task:        task,
+		TaskDir:     ctx.TaskDir.Dir,
+		LogDir:      ctx.TaskDir.LogDir,
+		SharedCores: ctx.SharedCores,
 	}
 	if err := execIntf.SetContext(executorCtx); err != nil {
 		pluginClient.Kill()
@@ -267,11 +268,12 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (*StartResponse
 	}
 
 	execCmd := &executor.ExecCommand{
-		Cmd:            absPath,
-		Args:           args,
-		FSIsolation:    true,
-		ResourceLimits: true,
-		User:           getExecutorUser(task),
+		Cmd:              absPath,
+		Args:             args,
+		FSIsolation:      true,
+		ResourceLimits:   true,
+		User:             getExecutorUser(task),
+		NetworkNamespace: networkNamespace(ctx),
 	}
 	ps, err := execIntf.LaunchCmd(execCmd)
 	if err != nil {
@@ -389,6 +391,11 @@ func (h *javaHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+// UpdateCpuset moves the task onto the given shared cores.
+func (h *javaHandle) UpdateCpuset(cores []uint16) error {
+	return h.executor.UpdateCpuset(cores)
+}
+
 func (h *javaHandle) Exec(ctx context.Context, cmd string, args []string) ([]byte, int, error) {
 	deadline, ok := ctx.Deadline()
 	if !ok {
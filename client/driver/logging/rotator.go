@@ -2,7 +2,9 @@ package logging
 
 import (
 	"bufio"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -12,27 +14,54 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
 const (
 	bufSize  = 32768
 	flushDur = 100 * time.Millisecond
+
+	// CompressedExt is the extension of rotated files that are compressed
+	CompressedExt = ".gz"
 )
 
+// SetTaskLogConfig applies the log config of a task to the rotators of its
+// stdout and stderr. The rotators share the task's total size cap.
+func SetTaskLogConfig(config *structs.LogConfig, stdout, stderr *FileRotator) {
+	group := []string{stdout.baseFileName, stderr.baseFileName}
+	for _, r := range []*FileRotator{stdout, stderr} {
+		r.MaxFiles = config.MaxFiles
+		r.FileSize = int64(config.MaxFileSizeMB * 1024 * 1024)
+		r.RotateInterval = config.RotationInterval
+		r.Compress = config.Compress
+		r.MaxTotalSize = int64(config.MaxTotalSizeMB * 1024 * 1024)
+		r.TotalSizeGroup = group
+	}
+}
+
 // FileRotator writes bytes to a rotated set of files
 type FileRotator struct {
-	MaxFiles int   // MaxFiles is the maximum number of rotated files allowed in a path
-	FileSize int64 // FileSize is the size a rotated file is allowed to grow
+	MaxFiles       int           // MaxFiles is the maximum number of rotated files allowed in a path
+	FileSize       int64         // FileSize is the size a rotated file is allowed to grow
+	RotateInterval time.Duration // RotateInterval is how often the current file is rotated regardless of its size, zero disables it
+	Compress       bool          // Compress enables gzip compression of rotated files
+	MaxTotalSize   int64         // MaxTotalSize caps the total size of the files of the TotalSizeGroup, zero disables it
+	TotalSizeGroup []string      // TotalSizeGroup is the base file names whose files share MaxTotalSize, defaults to the base file name
 
 	path             string // path is the path on the file system where the rotated set of files are opened
 	baseFileName     string // baseFileName is the base file name of the rotated files
 	logFileIdx       int    // logFileIdx is the current index of the rotated files
 	oldestLogFileIdx int    // oldestLogFileIdx is the index of the oldest log file in a path
 
-	currentFile *os.File // currentFile is the file that is currently getting written
-	currentWr   int64    // currentWr is the number of bytes written to the current file
-	bufw        *bufio.Writer
-	bufLock     sync.Mutex
+	currentFile   *os.File  // currentFile is the file that is currently getting written
+	currentWr     int64     // currentWr is the number of bytes written to the current file
+	currentOpened time.Time // currentOpened is when the current file was opened
+	rotateLock    sync.Mutex
+	bufw          *bufio.Writer
+	bufLock       sync.Mutex
+
+	compressWg sync.WaitGroup
 
 	flushTicker *time.Ticker
 	logger      *log.Logger
@@ -69,16 +98,17 @@ func NewFileRotator(path string, baseFile string, maxFiles int,
 // Write writes a byte array to a file and rotates the file if it's size becomes
 // equal to the maximum size the user has defined.
 func (f *FileRotator) Write(p []byte) (n int, err error) {
+	f.rotateLock.Lock()
+	defer f.rotateLock.Unlock()
+
 	n = 0
 	var nw int
 
 	for n < len(p) {
-		// Check if we still have space in the current file, otherwise close and
-		// open the next file
-		if f.currentWr >= f.FileSize {
-			f.flushBuffer()
-			f.currentFile.Close()
-			if err := f.nextFile(); err != nil {
+		// Check if we still have space in the current file and it is not due
+		// for rotation, otherwise close and open the next file
+		if f.currentWr >= f.FileSize || f.intervalElapsed() {
+			if err := f.rotate(); err != nil {
 				f.logger.Printf("[ERROR] driver.rotator: error creating next file: %v", err)
 				return 0, err
 			}
@@ -111,6 +141,94 @@ func (f *FileRotator) Write(p []byte) (n int, err error) {
 	return
 }
 
+// intervalElapsed returns whether the current file is due for rotation on the
+// rotation interval. Empty files are never rotated.
+func (f *FileRotator) intervalElapsed() bool {
+	return f.RotateInterval > 0 && f.currentWr > 0 && time.Since(f.currentOpened) >= f.RotateInterval
+}
+
+// rotate closes the current file, compressing it if enabled, and opens the
+// next file. It must be called with the rotate lock held.
+func (f *FileRotator) rotate() error {
+	f.flushBuffer()
+	f.currentFile.Close()
+	if f.Compress {
+		f.compressFile(f.currentFile.Name())
+	}
+	return f.nextFile()
+}
+
+// compressFile compresses the rotated file in the background
+func (f *FileRotator) compressFile(name string) {
+	f.compressWg.Add(1)
+	go func() {
+		defer f.compressWg.Done()
+		if err := compressFile(name); err != nil {
+			f.logger.Printf("[ERROR] driver.rotator: error compressing file %q: %v", name, err)
+		}
+	}()
+}
+
+// compressFile replaces the file with a gzip compressed copy. The copy is
+// written to a hidden temporary file first so readers never see a partially
+// compressed file.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dir, base := filepath.Split(name)
+	tmpName := filepath.Join(dir, fmt.Sprintf(".%s%s.tmp", base, CompressedExt))
+	dst, err := os.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, name+CompressedExt); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Remove(name)
+}
+
+// ParseRotatedFile parses the name of a rotated file into its base file name
+// and index and returns whether the file is compressed. False is returned if
+// the name is not the name of a rotated file.
+func ParseRotatedFile(name string) (base string, idx int, compressed bool, ok bool) {
+	if strings.HasSuffix(name, CompressedExt) {
+		name = strings.TrimSuffix(name, CompressedExt)
+		compressed = true
+	}
+
+	dot := strings.LastIndex(name, ".")
+	if dot <= 0 {
+		return "", 0, false, false
+	}
+	idx, err := strconv.Atoi(name[dot+1:])
+	if err != nil || idx < 0 {
+		return "", 0, false, false
+	}
+	return name[:dot], idx, compressed, true
+}
+
 // nextFile opens the next file and purges older files if the number of rotated
 // files is larger than the maximum files configured by the user
 func (f *FileRotator) nextFile() error {
@@ -118,6 +236,9 @@ func (f *FileRotator) nextFile() error {
 	for {
 		nextFileIdx += 1
 		logFileName := filepath.Join(f.path, fmt.Sprintf("%s.%d", f.baseFileName, nextFileIdx))
+		if _, err := os.Stat(logFileName + CompressedExt); err == nil {
+			continue
+		}
 		if fi, err := os.Stat(logFileName); err == nil {
 			if fi.IsDir() || fi.Size() >= f.FileSize {
 				continue
@@ -132,7 +253,7 @@ func (f *FileRotator) nextFile() error {
 	// Purge old files if we have more files than MaxFiles
 	f.closedLock.Lock()
 	defer f.closedLock.Unlock()
-	if f.logFileIdx-f.oldestLogFileIdx >= f.MaxFiles && !f.closed {
+	if (f.logFileIdx-f.oldestLogFileIdx >= f.MaxFiles || f.MaxTotalSize > 0) && !f.closed {
 		select {
 		case f.purgeCh <- struct{}{}:
 		default:
@@ -148,22 +269,25 @@ func (f *FileRotator) lastFile() error {
 		return err
 	}
 
-	prefix := fmt.Sprintf("%s.", f.baseFileName)
+	lastCompressed := false
 	for _, fi := range finfos {
 		if fi.IsDir() {
 			continue
 		}
-		if strings.HasPrefix(fi.Name(), prefix) {
-			fileIdx := strings.TrimPrefix(fi.Name(), prefix)
-			n, err := strconv.Atoi(fileIdx)
-			if err != nil {
-				continue
-			}
-			if n > f.logFileIdx {
-				f.logFileIdx = n
-			}
+		base, n, compressed, ok := ParseRotatedFile(fi.Name())
+		if !ok || base != f.baseFileName {
+			continue
+		}
+		if n > f.logFileIdx || (n == f.logFileIdx && compressed) {
+			f.logFileIdx = n
+			lastCompressed = compressed
 		}
 	}
+
+	// Compressed files are never appended to
+	if lastCompressed {
+		f.logFileIdx++
+	}
 	if err := f.createFile(); err != nil {
 		return err
 	}
@@ -183,6 +307,7 @@ func (f *FileRotator) createFile() error {
 		return err
 	}
 	f.currentWr = fi.Size()
+	f.currentOpened = time.Now()
 	f.createOrResetBuffer()
 	return nil
 }
@@ -192,10 +317,38 @@ func (f *FileRotator) createFile() error {
 func (f *FileRotator) flushPeriodically() {
 	for range f.flushTicker.C {
 		f.flushBuffer()
+		f.rotateOnInterval()
+	}
+}
+
+// rotateOnInterval rotates the current file if it is due for rotation on the
+// rotation interval, so files of quiet tasks are rotated without waiting for
+// the next write.
+func (f *FileRotator) rotateOnInterval() {
+	if f.RotateInterval <= 0 {
+		return
+	}
+
+	f.rotateLock.Lock()
+	defer f.rotateLock.Unlock()
+
+	f.closedLock.Lock()
+	closed := f.closed
+	f.closedLock.Unlock()
+	if closed || !f.intervalElapsed() {
+		return
+	}
+
+	if err := f.rotate(); err != nil {
+		f.logger.Printf("[ERROR] driver.rotator: error creating next file: %v", err)
 	}
 }
 
 func (f *FileRotator) Close() {
+	// Wait for rotations in progress
+	f.rotateLock.Lock()
+	defer f.rotateLock.Unlock()
+
 	f.closedLock.Lock()
 	defer f.closedLock.Unlock()
 
@@ -209,6 +362,9 @@ func (f *FileRotator) Close() {
 		close(f.purgeCh)
 		f.closed = true
 	}
+
+	// Wait for rotated files to be compressed
+	f.compressWg.Wait()
 }
 
 // purgeOldFiles removes older files and keeps only the last N files rotated for
@@ -217,47 +373,140 @@ func (f *FileRotator) purgeOldFiles() {
 	for {
 		select {
 		case <-f.purgeCh:
-			var fIndexes []int
-			files, err := ioutil.ReadDir(f.path)
-			if err != nil {
-				f.logger.Printf("[ERROR] driver.rotator: error getting directory listing: %v", err)
-				return
-			}
-			// Inserting all the rotated files in a slice
-			for _, fi := range files {
-				if strings.HasPrefix(fi.Name(), f.baseFileName) {
-					fileIdx := strings.TrimPrefix(fi.Name(), fmt.Sprintf("%s.", f.baseFileName))
-					n, err := strconv.Atoi(fileIdx)
-					if err != nil {
-						f.logger.Printf("[ERROR] driver.rotator: error extracting file index: %v", err)
-						continue
-					}
-					fIndexes = append(fIndexes, n)
-				}
+			f.purgeMaxFiles()
+			if f.MaxTotalSize > 0 {
+				f.purgeTotalSize()
 			}
+		case <-f.doneCh:
+			return
+		}
+	}
+}
 
-			// Not continuing to delete files if the number of files is not more
-			// than MaxFiles
-			if len(fIndexes) <= f.MaxFiles {
-				continue
-			}
+// purgeMaxFiles removes the oldest rotated files so only MaxFiles are kept
+func (f *FileRotator) purgeMaxFiles() {
+	files, err := ioutil.ReadDir(f.path)
+	if err != nil {
+		f.logger.Printf("[ERROR] driver.rotator: error getting directory listing: %v", err)
+		return
+	}
+
+	// Inserting all the rotated files in a slice. A file may briefly exist
+	// both compressed and uncompressed while it is being compressed.
+	seen := make(map[int]struct{})
+	var fIndexes []int
+	for _, fi := range files {
+		base, n, _, ok := ParseRotatedFile(fi.Name())
+		if !ok || base != f.baseFileName {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		fIndexes = append(fIndexes, n)
+	}
 
-			// Sorting the file indexes so that we can purge the older files and keep
-			// only the number of files as configured by the user
-			sort.Sort(sort.IntSlice(fIndexes))
-			toDelete := fIndexes[0 : len(fIndexes)-f.MaxFiles]
-			for _, fIndex := range toDelete {
-				fname := filepath.Join(f.path, fmt.Sprintf("%s.%d", f.baseFileName, fIndex))
-				err := os.RemoveAll(fname)
-				if err != nil {
-					f.logger.Printf("[ERROR] driver.rotator: error removing file: %v", err)
-				}
+	// Not continuing to delete files if the number of files is not more
+	// than MaxFiles
+	if len(fIndexes) <= f.MaxFiles {
+		return
+	}
+
+	// Sorting the file indexes so that we can purge the older files and keep
+	// only the number of files as configured by the user
+	sort.Sort(sort.IntSlice(fIndexes))
+	toDelete := fIndexes[0 : len(fIndexes)-f.MaxFiles]
+	for _, fIndex := range toDelete {
+		fname := filepath.Join(f.path, fmt.Sprintf("%s.%d", f.baseFileName, fIndex))
+		for _, name := range []string{fname, fname + CompressedExt} {
+			if err := os.RemoveAll(name); err != nil {
+				f.logger.Printf("[ERROR] driver.rotator: error removing file: %v", err)
 			}
-			f.oldestLogFileIdx = fIndexes[0]
-		case <-f.doneCh:
-			return
 		}
 	}
+	f.oldestLogFileIdx = fIndexes[0]
+}
+
+// purgeTotalSize removes the oldest rotated files of the total size group
+// until their total size is within MaxTotalSize. The file each base file name
+// is currently writing to is never removed.
+func (f *FileRotator) purgeTotalSize() {
+	group := f.TotalSizeGroup
+	if len(group) == 0 {
+		group = []string{f.baseFileName}
+	}
+	inGroup := make(map[string]struct{}, len(group))
+	for _, base := range group {
+		inGroup[base] = struct{}{}
+	}
+
+	files, err := ioutil.ReadDir(f.path)
+	if err != nil {
+		f.logger.Printf("[ERROR] driver.rotator: error getting directory listing: %v", err)
+		return
+	}
+
+	type rotatedFile struct {
+		name    string
+		base    string
+		idx     int
+		size    int64
+		modTime time.Time
+	}
+
+	var rotated []rotatedFile
+	var total int64
+	current := make(map[string]int)
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		base, n, _, ok := ParseRotatedFile(fi.Name())
+		if !ok {
+			continue
+		}
+		if _, ok := inGroup[base]; !ok {
+			continue
+		}
+
+		rotated = append(rotated, rotatedFile{
+			name:    fi.Name(),
+			base:    base,
+			idx:     n,
+			size:    fi.Size(),
+			modTime: fi.ModTime(),
+		})
+		total += fi.Size()
+		if idx, ok := current[base]; !ok || n > idx {
+			current[base] = n
+		}
+	}
+
+	if total <= f.MaxTotalSize {
+		return
+	}
+
+	// Remove the oldest files first
+	sort.Slice(rotated, func(i, j int) bool {
+		if rotated[i].modTime.Equal(rotated[j].modTime) {
+			return rotated[i].idx < rotated[j].idx
+		}
+		return rotated[i].modTime.Before(rotated[j].modTime)
+	})
+	for _, r := range rotated {
+		if total <= f.MaxTotalSize {
+			break
+		}
+		if current[r.base] == r.idx {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(f.path, r.name)); err != nil {
+			f.logger.Printf("[ERROR] driver.rotator: error removing file: %v", err)
+			continue
+		}
+		total -= r.size
+	}
 }
 
 // flushBuffer flushes the buffer
@@ -1,12 +1,14 @@
 package logging
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/testutil"
 )
@@ -289,3 +291,186 @@ func TestFileRotator_PurgeOldFiles(t *testing.T) {
 		t.Fatalf("%v", lastErr)
 	})
 }
+
+func TestFileRotator_ParseRotatedFile(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name       string
+		base       string
+		idx        int
+		compressed bool
+		ok         bool
+	}{
+		{"redis.stdout.0", "redis.stdout", 0, false, true},
+		{"redis.stdout.12.gz", "redis.stdout", 12, true, true},
+		{".redis.stdout.1.gz.tmp", "", 0, false, false},
+		{"redis.stdout", "", 0, false, false},
+		{"redis.stdout.-1", "", 0, false, false},
+		{"7", "", 0, false, false},
+	}
+	for _, c := range cases {
+		base, idx, compressed, ok := ParseRotatedFile(c.name)
+		if base != c.base || idx != c.idx || compressed != c.compressed || ok != c.ok {
+			t.Fatalf("%q: got (%q, %d, %v, %v); want (%q, %d, %v, %v)", c.name,
+				base, idx, compressed, ok, c.base, c.idx, c.compressed, c.ok)
+		}
+	}
+}
+
+func TestFileRotator_RotateInterval(t *testing.T) {
+	t.Parallel()
+	var path string
+	var err error
+	if path, err = ioutil.TempDir("", pathPrefix); err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer os.RemoveAll(path)
+
+	fr, err := NewFileRotator(path, baseFileName, 10, 1024, logger)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer fr.Close()
+	fr.RotateInterval = 200 * time.Millisecond
+
+	if _, err := fr.Write([]byte("abc")); err != nil {
+		t.Fatalf("got error while writing: %v", err)
+	}
+
+	// The file is rotated without further writes
+	var lastErr error
+	testutil.WaitForResult(func() (bool, error) {
+		if _, err := os.Stat(filepath.Join(path, "redis.stdout.1")); err != nil {
+			lastErr = fmt.Errorf("expected rotated file: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("%v", lastErr)
+	})
+
+	fi, err := os.Stat(filepath.Join(path, "redis.stdout.0"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if fi.Size() != 3 {
+		t.Fatalf("expected size: %v, got: %v", 3, fi.Size())
+	}
+
+	// Empty files aren't rotated
+	time.Sleep(2 * fr.RotateInterval)
+	if _, err := os.Stat(filepath.Join(path, "redis.stdout.2")); err == nil {
+		t.Fatalf("expected empty file not to be rotated")
+	}
+}
+
+func TestFileRotator_Compress(t *testing.T) {
+	t.Parallel()
+	var path string
+	var err error
+	if path, err = ioutil.TempDir("", pathPrefix); err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer os.RemoveAll(path)
+
+	fr, err := NewFileRotator(path, baseFileName, 10, 5, logger)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	fr.Compress = true
+
+	str := "abcdefgh"
+	if _, err := fr.Write([]byte(str)); err != nil {
+		t.Fatalf("got error while writing: %v", err)
+	}
+	fr.Close()
+
+	// The rotated file is compressed and the current file is not
+	if _, err := os.Stat(filepath.Join(path, "redis.stdout.0")); !os.IsNotExist(err) {
+		t.Fatalf("expected rotated file to be removed: %v", err)
+	}
+	f, err := os.Open(filepath.Join(path, "redis.stdout.0.gz"))
+	if err != nil {
+		t.Fatalf("expected compressed file: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(data) != str[:5] {
+		t.Fatalf("expected %q, got %q", str[:5], data)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(path, "redis.stdout.1"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(data) != str[5:] {
+		t.Fatalf("expected %q, got %q", str[5:], data)
+	}
+
+	// A new rotator continues after the compressed file
+	fr, err = NewFileRotator(path, baseFileName, 10, 5, logger)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer fr.Close()
+	if fr.logFileIdx != 1 {
+		t.Fatalf("expected index: %v, got: %v", 1, fr.logFileIdx)
+	}
+}
+
+func TestFileRotator_PurgeTotalSize(t *testing.T) {
+	t.Parallel()
+	var path string
+	var err error
+	if path, err = ioutil.TempDir("", pathPrefix); err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer os.RemoveAll(path)
+
+	// The stderr file counts towards the total size
+	if err := ioutil.WriteFile(filepath.Join(path, "redis.stderr.0"), []byte("abcd"), 0666); err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+
+	fr, err := NewFileRotator(path, baseFileName, 10, 2, logger)
+	if err != nil {
+		t.Fatalf("test setup err: %v", err)
+	}
+	defer fr.Close()
+	fr.MaxTotalSize = 8
+	fr.TotalSizeGroup = []string{baseFileName, "redis.stderr"}
+
+	str := "abcdeghijklmn"
+	if _, err := fr.Write([]byte(str)); err != nil {
+		t.Fatalf("got error while writing: %v", err)
+	}
+
+	// The oldest stdout files are purged to fit the total size
+	var lastErr error
+	testutil.WaitForResult(func() (bool, error) {
+		for i := 0; i < 4; i++ {
+			name := fmt.Sprintf("redis.stdout.%d", i)
+			if _, err := os.Stat(filepath.Join(path, name)); !os.IsNotExist(err) {
+				lastErr = fmt.Errorf("expected %q to be purged: %v", name, err)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("%v", lastErr)
+	})
+
+	// The files being written to are kept
+	for _, name := range []string{"redis.stderr.0", "redis.stdout.6"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			t.Fatalf("expected %q to be kept: %v", name, err)
+		}
+	}
+}
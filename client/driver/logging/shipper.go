@@ -0,0 +1,421 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	syslog "github.com/RackSec/srslog"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// StreamStdout and StreamStderr are the streams a log line is shipped
+	// from
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	// shipperDialTimeout is the timeout to connect to a sink
+	shipperDialTimeout = 5 * time.Second
+
+	// shipperWriteTimeout is the timeout to write a log line to a sink
+	shipperWriteTimeout = 5 * time.Second
+
+	// shipperMinBackoff and shipperMaxBackoff bound the time between attempts
+	// to reconnect to an unavailable sink
+	shipperMinBackoff = 1 * time.Second
+	shipperMaxBackoff = 30 * time.Second
+
+	// shipperMaxLineSize is the size at which a partial line is shipped
+	// without waiting for the rest of the line
+	shipperMaxLineSize = 64 * 1024
+
+	// shipperShutdownTimeout is how long buffered lines are shipped for when
+	// the shipper is shut down
+	shipperShutdownTimeout = 5 * time.Second
+
+	// shipperMaxWriteAttempts is the number of times writing a line to the
+	// sink is attempted before the line is counted as failed
+	shipperMaxWriteAttempts = 3
+)
+
+// LogShipper ships the lines a task writes to stdout and stderr to a sink.
+// Lines are buffered while the sink is slow or unavailable and dropped once
+// the buffer is full, so the sink never blocks the task.
+type LogShipper struct {
+	config   *structs.LogShipper
+	allocID  string
+	taskName string
+	hostname string
+
+	lines chan *logLine
+
+	// conn is the connection to the sink and connClosedCh is closed once the
+	// sink closes it. They are only accessed by the run loop.
+	conn         net.Conn
+	connClosedCh chan struct{}
+	nextDial     time.Time
+	backoff      time.Duration
+
+	shipped uint64
+	dropped uint64
+	failed  uint64
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+	doneCh       chan struct{}
+
+	logger *log.Logger
+}
+
+// logLine is a log line waiting to be shipped
+type logLine struct {
+	stream string
+	data   []byte
+	time   time.Time
+
+	// attempts is the number of failed attempts to write the line
+	attempts int
+}
+
+// NewLogShipper returns a log shipper for the task and starts shipping the
+// lines written to its writers.
+func NewLogShipper(config *structs.LogShipper, allocID, taskName string, logger *log.Logger) *LogShipper {
+	hostname, _ := os.Hostname()
+	s := &LogShipper{
+		config:     config,
+		allocID:    allocID,
+		taskName:   taskName,
+		hostname:   hostname,
+		lines:      make(chan *logLine, config.BufferSize),
+		shutdownCh: make(chan struct{}),
+		doneCh:     make(chan struct{}),
+		logger:     logger,
+	}
+	go s.run()
+	return s
+}
+
+// Writer returns a writer whose lines are shipped as coming from the given
+// stream. Writes never block on the sink and never fail.
+func (s *LogShipper) Writer(stream string) io.Writer {
+	return &shipperWriter{shipper: s, stream: stream}
+}
+
+// Stats returns the delivery statistics of the shipper
+func (s *LogShipper) Stats() *cstructs.LogShipperStats {
+	return &cstructs.LogShipperStats{
+		Shipped:  atomic.LoadUint64(&s.shipped),
+		Dropped:  atomic.LoadUint64(&s.dropped),
+		Failed:   atomic.LoadUint64(&s.failed),
+		Buffered: len(s.lines),
+	}
+}
+
+// Shutdown ships the buffered lines, bounded by a timeout, and stops the
+// shipper. It is safe to call multiple times.
+func (s *LogShipper) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdownCh)
+	})
+	<-s.doneCh
+}
+
+// enqueue buffers the line for shipping, dropping it if the buffer is full
+func (s *LogShipper) enqueue(stream string, data []byte) {
+	line := &logLine{
+		stream: stream,
+		data:   append([]byte(nil), data...),
+		time:   time.Now(),
+	}
+
+	select {
+	case s.lines <- line:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+func (s *LogShipper) run() {
+	defer close(s.doneCh)
+
+	// pending is the line being shipped. It is kept until it is shipped so
+	// it isn't lost while the sink is unavailable.
+	var pending *logLine
+	for {
+		// Wait for the backoff to expire without taking lines off the buffer
+		// so they are shipped once the sink is available again
+		if wait := s.nextDial.Sub(time.Now()); s.conn == nil && wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-s.shutdownCh:
+				timer.Stop()
+				s.shutdown(pending)
+				return
+			}
+		}
+
+		if pending == nil {
+			select {
+			case pending = <-s.lines:
+			case <-s.shutdownCh:
+				s.shutdown(nil)
+				return
+			}
+		}
+
+		if s.ship(pending) {
+			pending = nil
+		}
+	}
+}
+
+// shutdown ships the pending and buffered lines until the buffer is empty,
+// the sink fails or the shutdown timeout is reached. Lines that aren't
+// shipped are counted as failed.
+func (s *LogShipper) shutdown(pending *logLine) {
+	deadline := time.After(shipperShutdownTimeout)
+	for {
+		if pending == nil {
+			select {
+			case pending = <-s.lines:
+			default:
+				s.closeConn()
+				return
+			}
+		}
+
+		select {
+		case <-deadline:
+		default:
+			if s.ship(pending) {
+				pending = nil
+				continue
+			}
+		}
+
+		atomic.AddUint64(&s.failed, uint64(1+len(s.lines)))
+		for len(s.lines) > 0 {
+			<-s.lines
+		}
+		s.closeConn()
+		return
+	}
+}
+
+// ship writes the line to the sink, connecting to it if needed, and returns
+// whether the line was handled. Lines that can't be written are retried up to
+// shipperMaxWriteAttempts times and then counted as failed.
+func (s *LogShipper) ship(line *logLine) bool {
+	if s.conn != nil {
+		select {
+		case <-s.connClosedCh:
+			s.closeConn()
+		default:
+		}
+	}
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network(), s.config.Address, shipperDialTimeout)
+		if err != nil {
+			s.logger.Printf("[WARN] log_shipper: failed to connect to sink %q at %q: %v",
+				s.config.Name, s.config.Address, err)
+			s.retryLater()
+			return false
+		}
+		s.setConn(conn)
+		s.backoff = 0
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(shipperWriteTimeout))
+	if _, err := s.conn.Write(s.format(line)); err != nil {
+		s.logger.Printf("[WARN] log_shipper: failed to ship log line to sink %q: %v", s.config.Name, err)
+		s.closeConn()
+		s.retryLater()
+
+		line.attempts++
+		if line.attempts < shipperMaxWriteAttempts {
+			return false
+		}
+		atomic.AddUint64(&s.failed, 1)
+		return true
+	}
+	atomic.AddUint64(&s.shipped, 1)
+	return true
+}
+
+// setConn sets the connection to the sink. Stream connections are watched so
+// that lines aren't written to a connection the sink has closed.
+func (s *LogShipper) setConn(conn net.Conn) {
+	s.conn = conn
+	s.connClosedCh = make(chan struct{})
+	if s.network() != "tcp" {
+		return
+	}
+
+	// Sinks never send data, so the read only returns once the connection
+	// is closed
+	go func(closedCh chan struct{}) {
+		io.Copy(ioutil.Discard, conn)
+		close(closedCh)
+	}(s.connClosedCh)
+}
+
+// closeConn closes the connection to the sink if there is one
+func (s *LogShipper) closeConn() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.connClosedCh = nil
+	}
+}
+
+// retryLater backs off reconnecting to the sink
+func (s *LogShipper) retryLater() {
+	s.backoff *= 2
+	if s.backoff == 0 {
+		s.backoff = shipperMinBackoff
+	} else if s.backoff > shipperMaxBackoff {
+		s.backoff = shipperMaxBackoff
+	}
+	s.nextDial = time.Now().Add(s.backoff)
+}
+
+// network returns the network used to connect to the sink
+func (s *LogShipper) network() string {
+	switch s.config.Type {
+	case structs.LogShipperTypeSyslog:
+		return s.config.Protocol
+	case structs.LogShipperTypeSocket:
+		return "unixgram"
+	default:
+		return "tcp"
+	}
+}
+
+// format formats the line as expected by the sink
+func (s *LogShipper) format(line *logLine) []byte {
+	switch s.config.Type {
+	case structs.LogShipperTypeSyslog:
+		return s.formatSyslog(line)
+	case structs.LogShipperTypeSocket:
+		return s.formatSocket(line)
+	default:
+		return s.formatJSON(line)
+	}
+}
+
+// formatSyslog formats the line as an RFC5424 message. Messages sent over TCP
+// are framed with their length as defined by RFC5425.
+func (s *LogShipper) formatSyslog(line *logLine) []byte {
+	priority := syslog.LOG_USER | syslog.LOG_INFO
+	if line.stream == StreamStderr {
+		priority = syslog.LOG_USER | syslog.LOG_ERR
+	}
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s %s %s - %s",
+		priority, line.time.UTC().Format(time.RFC3339Nano), nilValue(s.hostname),
+		nilValue(s.taskName), nilValue(s.allocID), line.stream, line.data)
+	if s.config.Protocol == "tcp" {
+		msg = syslog.RFC5425MessageLengthFramer(msg)
+	}
+	return []byte(msg)
+}
+
+// jsonLogLine is the object shipped for each line by the json sink
+type jsonLogLine struct {
+	Timestamp string `json:"timestamp"`
+	AllocID   string `json:"alloc_id"`
+	Task      string `json:"task"`
+	Stream    string `json:"stream"`
+	Message   string `json:"message"`
+}
+
+// formatJSON formats the line as a newline terminated JSON object
+func (s *LogShipper) formatJSON(line *logLine) []byte {
+	out, err := json.Marshal(&jsonLogLine{
+		Timestamp: line.time.UTC().Format(time.RFC3339Nano),
+		AllocID:   s.allocID,
+		Task:      s.taskName,
+		Stream:    line.stream,
+		Message:   string(line.data),
+	})
+	if err != nil {
+		// Marshaling strings can't fail
+		panic(err)
+	}
+	return append(out, '\n')
+}
+
+// formatSocket formats the line as a journald native protocol datagram
+func (s *LogShipper) formatSocket(line *logLine) []byte {
+	priority := syslog.LOG_INFO
+	if line.stream == StreamStderr {
+		priority = syslog.LOG_ERR
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "MESSAGE=%s\n", line.data)
+	fmt.Fprintf(&buf, "PRIORITY=%d\n", priority)
+	fmt.Fprintf(&buf, "SYSLOG_IDENTIFIER=%s\n", s.taskName)
+	fmt.Fprintf(&buf, "NOMAD_ALLOC_ID=%s\n", s.allocID)
+	fmt.Fprintf(&buf, "NOMAD_TASK_NAME=%s\n", s.taskName)
+	fmt.Fprintf(&buf, "NOMAD_STREAM=%s\n", line.stream)
+	return buf.Bytes()
+}
+
+// nilValue returns the RFC5424 nil value for empty header fields
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// shipperWriter splits the bytes written to it into lines and hands them to
+// the shipper
+type shipperWriter struct {
+	shipper *LogShipper
+	stream  string
+
+	buf  []byte
+	lock sync.Mutex
+}
+
+func (w *shipperWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	rest := append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(rest, '\n')
+		if idx == -1 {
+			break
+		}
+		w.shipper.enqueue(w.stream, bytes.TrimSuffix(rest[:idx], []byte{'\r'}))
+		rest = rest[idx+1:]
+	}
+
+	// Ship long partial lines rather than buffering them without bound
+	if len(rest) >= shipperMaxLineSize {
+		w.shipper.enqueue(w.stream, rest)
+		rest = nil
+	}
+
+	if len(rest) == 0 {
+		w.buf = nil
+	} else {
+		w.buf = append([]byte(nil), rest...)
+	}
+	return len(p), nil
+}
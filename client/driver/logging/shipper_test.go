@@ -0,0 +1,238 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestLogShipper_Writer_Lines(t *testing.T) {
+	t.Parallel()
+	s := &LogShipper{lines: make(chan *logLine, 10)}
+	w := s.Writer(StreamStdout)
+
+	// Partial lines are held until the newline is written
+	for _, p := range []string{"foo\nba", "r\r\n", "baz"} {
+		if n, err := w.Write([]byte(p)); err != nil || n != len(p) {
+			t.Fatalf("Write(%q) = %d, %v", p, n, err)
+		}
+	}
+
+	if act := len(s.lines); act != 2 {
+		t.Fatalf("got %d lines; want 2", act)
+	}
+	for _, exp := range []string{"foo", "bar"} {
+		line := <-s.lines
+		if string(line.data) != exp || line.stream != StreamStdout {
+			t.Fatalf("got line %q from %q; want %q from %q", line.data, line.stream, exp, StreamStdout)
+		}
+	}
+}
+
+func TestLogShipper_DropsWhenFull(t *testing.T) {
+	t.Parallel()
+	s := &LogShipper{lines: make(chan *logLine, 2)}
+	w := s.Writer(StreamStderr)
+
+	// Writing never blocks even though nothing consumes the buffer
+	if _, err := w.Write([]byte("a\nb\nc\nd\n")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	stats := s.Stats()
+	if stats.Buffered != 2 || stats.Dropped != 2 {
+		t.Fatalf("bad stats: %+v", stats)
+	}
+}
+
+func TestLogShipper_JSON(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+
+	config := &structs.LogShipper{
+		Name:       "json",
+		Type:       structs.LogShipperTypeJSON,
+		Address:    l.Addr().String(),
+		BufferSize: 10,
+	}
+	s := NewLogShipper(config, "alloc1", "web", logger)
+	s.Writer(StreamStdout).Write([]byte("hello\n"))
+	s.Writer(StreamStderr).Write([]byte("oops\n"))
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	expected := []jsonLogLine{
+		{AllocID: "alloc1", Task: "web", Stream: StreamStdout, Message: "hello"},
+		{AllocID: "alloc1", Task: "web", Stream: StreamStderr, Message: "oops"},
+	}
+	for _, exp := range expected {
+		raw, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		var act jsonLogLine
+		if err := json.Unmarshal(raw, &act); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if act.Timestamp == "" {
+			t.Fatalf("missing timestamp: %s", raw)
+		}
+		act.Timestamp = ""
+		if act != exp {
+			t.Fatalf("got %+v; want %+v", act, exp)
+		}
+	}
+
+	s.Shutdown()
+	if stats := s.Stats(); stats.Shipped != 2 || stats.Failed != 0 || stats.Dropped != 0 {
+		t.Fatalf("bad stats: %+v", stats)
+	}
+}
+
+func TestLogShipper_Syslog_UDP(t *testing.T) {
+	t.Parallel()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer conn.Close()
+
+	config := &structs.LogShipper{
+		Name:       "syslog",
+		Type:       structs.LogShipperTypeSyslog,
+		Address:    conn.LocalAddr().String(),
+		Protocol:   "udp",
+		BufferSize: 10,
+	}
+	s := NewLogShipper(config, "alloc1", "web", logger)
+	defer s.Shutdown()
+	s.Writer(StreamStderr).Write([]byte("oops\n"))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Lines from stderr are logged with the user facility and error severity
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<11>1 ") {
+		t.Fatalf("bad priority: %q", msg)
+	}
+	if !strings.HasSuffix(msg, " web alloc1 stderr - oops") {
+		t.Fatalf("bad message: %q", msg)
+	}
+}
+
+func TestLogShipper_UnavailableSink(t *testing.T) {
+	t.Parallel()
+
+	// Find an address nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	config := &structs.LogShipper{
+		Name:       "json",
+		Type:       structs.LogShipperTypeJSON,
+		Address:    addr,
+		BufferSize: 10,
+	}
+	s := NewLogShipper(config, "alloc1", "web", logger)
+	s.Writer(StreamStdout).Write([]byte("a\nb\n"))
+	s.Shutdown()
+
+	if stats := s.Stats(); stats.Failed != 2 || stats.Shipped != 0 {
+		t.Fatalf("bad stats: %+v", stats)
+	}
+}
+
+func TestLogShipper_SinkRestart(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	addr := l.Addr().String()
+
+	config := &structs.LogShipper{
+		Name:       "json",
+		Type:       structs.LogShipperTypeJSON,
+		Address:    addr,
+		BufferSize: 10,
+	}
+	s := NewLogShipper(config, "alloc1", "web", logger)
+	defer s.Shutdown()
+	w := s.Writer(StreamStdout)
+
+	readLines := func(l net.Listener, expected ...string) {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+		r := bufio.NewReader(conn)
+		for _, exp := range expected {
+			raw, err := r.ReadBytes('\n')
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			var act jsonLogLine
+			if err := json.Unmarshal(raw, &act); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if act.Message != exp {
+				t.Fatalf("got line %q; want %q", act.Message, exp)
+			}
+		}
+	}
+
+	w.Write([]byte("a\n"))
+	readLines(l, "a")
+
+	// Stop the sink and give the shipper time to notice the closed
+	// connection before writing during the outage
+	l.Close()
+	time.Sleep(100 * time.Millisecond)
+	w.Write([]byte("b\nc\n"))
+	time.Sleep(100 * time.Millisecond)
+
+	if stats := s.Stats(); stats.Failed != 0 || stats.Dropped != 0 {
+		t.Fatalf("bad stats during outage: %+v", stats)
+	}
+
+	// Restart the sink; the lines written during the outage are shipped once
+	// the shipper reconnects
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+	readLines(l, "b", "c")
+
+	s.Shutdown()
+	if stats := s.Stats(); stats.Shipped != 3 || stats.Failed != 0 || stats.Dropped != 0 {
+		t.Fatalf("bad stats: %+v", stats)
+	}
+}
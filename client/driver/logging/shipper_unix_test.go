@@ -0,0 +1,55 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package logging
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestLogShipper_Socket(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "shipper")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sock := filepath.Join(dir, "socket")
+	conn, err := net.ListenPacket("unixgram", sock)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer conn.Close()
+
+	config := &structs.LogShipper{
+		Name:       "journal",
+		Type:       structs.LogShipperTypeSocket,
+		Address:    sock,
+		BufferSize: 10,
+	}
+	s := NewLogShipper(config, "alloc1", "web", logger)
+	defer s.Shutdown()
+	s.Writer(StreamStdout).Write([]byte("hello\n"))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	msg := string(buf[:n])
+	for _, field := range []string{"MESSAGE=hello\n", "PRIORITY=6\n", "SYSLOG_IDENTIFIER=web\n", "NOMAD_ALLOC_ID=alloc1\n", "NOMAD_STREAM=stdout\n"} {
+		if !strings.Contains(msg, field) {
+			t.Fatalf("datagram %q missing field %q", msg, field)
+		}
+	}
+}
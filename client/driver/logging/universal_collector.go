@@ -106,6 +106,7 @@ func (s *SyslogCollector) LaunchCollector(ctx *LogCollectorContext) (*SyslogColl
 		return nil, err
 	}
 	s.lre = lre
+	SetTaskLogConfig(ctx.LogConfig, lro, lre)
 
 	go s.collectLogs(lre, lro)
 	syslogAddr := fmt.Sprintf("%s://%s", l.Addr().Network(), l.Addr().String())
@@ -140,14 +141,10 @@ func (s *SyslogCollector) UpdateLogConfig(logConfig *structs.LogConfig) error {
 	if s.lro == nil {
 		return fmt.Errorf("log rotator for stdout doesn't exist")
 	}
-	s.lro.MaxFiles = logConfig.MaxFiles
-	s.lro.FileSize = int64(logConfig.MaxFileSizeMB * 1024 * 1024)
-
 	if s.lre == nil {
 		return fmt.Errorf("log rotator for stderr doesn't exist")
 	}
-	s.lre.MaxFiles = logConfig.MaxFiles
-	s.lre.FileSize = int64(logConfig.MaxFileSizeMB * 1024 * 1024)
+	SetTaskLogConfig(logConfig, s.lro, s.lre)
 	return nil
 }
 
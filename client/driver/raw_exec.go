@@ -146,9 +146,10 @@ func (d *RawExecDriver) Start(ctx *ExecContext, task *structs.Task) (*StartRespo
 	}
 
 	execCmd := &executor.ExecCommand{
-		Cmd:  command,
-		Args: driverConfig.Args,
-		User: task.User,
+		Cmd:              command,
+		Args:             driverConfig.Args,
+		User:             task.User,
+		NetworkNamespace: networkNamespace(ctx),
 	}
 	ps, err := exec.LaunchCmd(execCmd)
 	if err != nil {
@@ -182,6 +182,15 @@ func getExecutorUser(task *structs.Task) string {
 	return task.User
 }
 
+// networkNamespace returns the path of the network namespace the task should
+// be run in or an empty string if it should use the host's network.
+func networkNamespace(ctx *ExecContext) string {
+	if ctx.NetworkIsolation == nil {
+		return ""
+	}
+	return ctx.NetworkIsolation.Path
+}
+
 // SetEnvvars sets path and host env vars depending on the FS isolation used.
 func SetEnvvars(envBuilder *env.Builder, fsi cstructs.FSIsolation, taskDir *allocdir.TaskDir, conf *config.Config) {
 	// Set driver-specific environment variables
@@ -51,6 +51,7 @@ func NewCGroupFingerprint(logger *log.Logger) Fingerprint {
 // have been set in a previous fingerprint run.
 func (f *CGroupFingerprint) clearCGroupAttributes(n *structs.Node) {
 	delete(n.Attributes, "unique.cgroup.mountpoint")
+	delete(n.Attributes, "unique.cgroup.version")
 }
 
 // Periodic determines the interval at which the periodic fingerprinter will run.
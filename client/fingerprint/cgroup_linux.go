@@ -6,13 +6,25 @@ import (
 	"fmt"
 
 	client "github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/cgutil"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 )
 
+const (
+	// cgroupV1 and cgroupV2 are the values of the cgroup version attribute
+	cgroupV1 = "v1"
+	cgroupV2 = "v2"
+)
+
 // FindCgroupMountpointDir is used to find the cgroup mount point on a Linux
-// system.
+// system. On hosts that only mount the unified (v2) hierarchy its mount point
+// is returned.
 func FindCgroupMountpointDir() (string, error) {
+	if cgutil.UseV2() {
+		return cgutil.UnifiedMountpoint, nil
+	}
+
 	mount, err := cgroups.FindCgroupMountpointDir()
 	if err != nil {
 		switch e := err.(type) {
@@ -49,8 +61,14 @@ func (f *CGroupFingerprint) Fingerprint(cfg *client.Config, node *structs.Node)
 
 	node.Attributes["unique.cgroup.mountpoint"] = mount
 
+	version := cgroupV1
+	if cgutil.IsUnified(mount) {
+		version = cgroupV2
+	}
+	node.Attributes["unique.cgroup.version"] = version
+
 	if f.lastState == cgroupUnavailable {
-		f.logger.Printf("[INFO] fingerprint.cgroups: cgroups are available")
+		f.logger.Printf("[INFO] fingerprint.cgroups: cgroups %s are available", version)
 	}
 	f.lastState = cgroupAvailable
 	return true, nil
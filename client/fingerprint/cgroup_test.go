@@ -78,6 +78,7 @@ func TestCGroupFingerprint(t *testing.T) {
 		t.Fatalf("should apply")
 	}
 	assertNodeAttributeContains(t, node, "unique.cgroup.mountpoint")
+	assertNodeAttributeContains(t, node, "unique.cgroup.version")
 
 	f = &CGroupFingerprint{
 		logger:             testLogger(),
@@ -99,4 +100,7 @@ func TestCGroupFingerprint(t *testing.T) {
 	if a, ok := node.Attributes["unique.cgroup.mountpoint"]; ok {
 		t.Fatalf("unexpected attribute found, %s", a)
 	}
+	if a, ok := node.Attributes["unique.cgroup.version"]; ok {
+		t.Fatalf("unexpected attribute found, %s", a)
+	}
 }
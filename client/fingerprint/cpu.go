@@ -34,6 +34,17 @@ func (f *CPUFingerprint) Fingerprint(cfg *config.Config, node *structs.Node) (bo
 		f.logger.Printf("[WARN] fingerprint.cpu: %v", err)
 	}
 
+	// Fingerprint the cores that tasks can reserve for their exclusive use
+	if cores := f.coreIDs(); len(cores) > 0 {
+		if node.Resources == nil {
+			node.Resources = &structs.Resources{}
+		}
+
+		node.Resources.CoreIDs = cores
+		node.Attributes["cpu.reservablecores"] = fmt.Sprintf("%d", len(cores))
+		f.logger.Printf("[DEBUG] fingerprint.cpu: reservable cores: %s", structs.CpusetString(cores))
+	}
+
 	if cfg.CpuCompute != 0 {
 		setResources(cfg.CpuCompute)
 		return true, nil
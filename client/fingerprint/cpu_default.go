@@ -0,0 +1,9 @@
+// +build !linux
+
+package fingerprint
+
+// coreIDs returns the IDs of the CPU cores that can be reserved by tasks.
+// Reserving cores is only supported on Linux.
+func (f *CPUFingerprint) coreIDs() []uint16 {
+	return nil
+}
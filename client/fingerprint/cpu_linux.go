@@ -0,0 +1,26 @@
+package fingerprint
+
+import (
+	"io/ioutil"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// cpuOnlinePath is the path that lists the online CPU cores
+const cpuOnlinePath = "/sys/devices/system/cpu/online"
+
+// coreIDs returns the IDs of the CPU cores that can be reserved by tasks.
+func (f *CPUFingerprint) coreIDs() []uint16 {
+	content, err := ioutil.ReadFile(cpuOnlinePath)
+	if err != nil {
+		f.logger.Printf("[DEBUG] fingerprint.cpu: unable to read online cores from %s: %v", cpuOnlinePath, err)
+		return nil
+	}
+
+	cores, err := structs.ParseCpuset(string(content))
+	if err != nil {
+		f.logger.Printf("[WARN] fingerprint.cpu: unable to parse online cores: %v", err)
+		return nil
+	}
+	return cores
+}
@@ -1,6 +1,7 @@
 package fingerprint
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/hashicorp/nomad/client/config"
@@ -39,6 +40,9 @@ func TestCPUFingerprint(t *testing.T) {
 		t.Fatalf("Expected to find CPU Resources")
 	}
 
+	if runtime.GOOS == "linux" && len(node.Resources.CoreIDs) == 0 {
+		t.Fatalf("Expected to find reservable cores")
+	}
 }
 
 // TestCPUFingerprint_OverrideCompute asserts that setting cpu_total_compute in
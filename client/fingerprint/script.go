@@ -0,0 +1,198 @@
+package fingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// ScriptAttributePrefix is prepended to every attribute reported by a
+	// fingerprint script.
+	ScriptAttributePrefix = "custom."
+
+	// scriptKillWait is how long to wait for a timed out script to exit
+	// after it has been killed.
+	scriptKillWait = 5 * time.Second
+)
+
+// ScriptFingerprint runs an external executable and adds the key=value pairs
+// it prints to the node attributes under the custom prefix.
+type ScriptFingerprint struct {
+	name     string
+	path     string
+	interval time.Duration
+	timeout  time.Duration
+	logger   *log.Logger
+
+	// reported is the set of attributes set by the last successful run, so
+	// that attributes the script no longer reports can be removed.
+	reported map[string]struct{}
+}
+
+// NewScriptFingerprint returns a fingerprinter that runs the executable at
+// path every interval, killing it if it runs longer than timeout.
+func NewScriptFingerprint(path string, interval, timeout time.Duration, logger *log.Logger) *ScriptFingerprint {
+	return &ScriptFingerprint{
+		name:     filepath.Base(path),
+		path:     path,
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		reported: make(map[string]struct{}),
+	}
+}
+
+// ScriptFingerprints returns a fingerprinter for each executable in the
+// configured scripts directory, sorted by file name.
+func ScriptFingerprints(cfg *config.FingerprintScriptsConfig, logger *log.Logger) ([]*ScriptFingerprint, error) {
+	if cfg == nil || cfg.Dir == "" {
+		return nil, nil
+	}
+
+	files, err := ioutil.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint scripts directory %q: %v", cfg.Dir, err)
+	}
+
+	var scripts []*ScriptFingerprint
+	for _, fi := range files {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		if runtime.GOOS != "windows" && fi.Mode().Perm()&0111 == 0 {
+			logger.Printf("[DEBUG] fingerprint.script: skipping non-executable file %q", fi.Name())
+			continue
+		}
+
+		name := fi.Name()
+		path := filepath.Join(cfg.Dir, name)
+		scripts = append(scripts, NewScriptFingerprint(path, cfg.IntervalFor(name), cfg.TimeoutFor(name), logger))
+	}
+
+	return scripts, nil
+}
+
+// Name returns the name of the fingerprint script.
+func (f *ScriptFingerprint) Name() string {
+	return f.name
+}
+
+func (f *ScriptFingerprint) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	attrs, err := f.Run()
+	if err != nil {
+		return false, err
+	}
+	return f.Apply(node, attrs), nil
+}
+
+// Run executes the script and returns the attributes it reports. It does not
+// modify the node so callers can run the script, which may take up to its
+// timeout, without holding the lock protecting the node. A script that has
+// been removed reports no attributes.
+func (f *ScriptFingerprint) Run() (map[string]string, error) {
+	return f.run()
+}
+
+// Apply sets the attributes returned by Run on the node and removes the ones
+// the script no longer reports. It returns whether any attribute is set. On
+// failures Apply should not be called so the previously reported attributes
+// are left in place and a transient failure does not change the node.
+func (f *ScriptFingerprint) Apply(node *structs.Node, attrs map[string]string) bool {
+	// Remove the attributes the script no longer reports
+	for k := range f.reported {
+		if _, ok := attrs[k]; !ok {
+			delete(node.Attributes, k)
+		}
+	}
+
+	f.reported = make(map[string]struct{}, len(attrs))
+	for k, v := range attrs {
+		node.Attributes[k] = v
+		f.reported[k] = struct{}{}
+	}
+
+	return len(attrs) != 0
+}
+
+func (f *ScriptFingerprint) Periodic() (bool, time.Duration) {
+	return true, f.interval
+}
+
+// run executes the script and returns the attributes it reports.
+func (f *ScriptFingerprint) run() (map[string]string, error) {
+	if _, err := os.Stat(f.path); os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(f.path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("fingerprint script %q failed to start: %v", f.name, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(f.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return nil, fmt.Errorf("fingerprint script %q failed: %v: %s", f.name, err, strings.TrimSpace(stderr.String()))
+		}
+	case <-timer.C:
+		// Kill the script along with any children it started, as they may
+		// keep its output open, and wait for it to be reaped.
+		if err := killProcessGroup(cmd); err != nil {
+			f.logger.Printf("[WARN] fingerprint.script: failed to kill %q: %v", f.name, err)
+		}
+		select {
+		case <-errCh:
+		case <-time.After(scriptKillWait):
+			f.logger.Printf("[WARN] fingerprint.script: %q did not exit after being killed", f.name)
+		}
+		return nil, fmt.Errorf("fingerprint script %q timed out after %v", f.name, f.timeout)
+	}
+
+	return f.parse(&stdout), nil
+}
+
+// parse reads key=value lines from the script output. Blank lines and lines
+// starting with # are ignored.
+func (f *ScriptFingerprint) parse(out *bytes.Buffer) map[string]string {
+	attrs := make(map[string]string)
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			f.logger.Printf("[WARN] fingerprint.script: ignoring invalid line %q from %q", line, f.name)
+			continue
+		}
+		attrs[ScriptAttributePrefix+key] = strings.TrimSpace(parts[1])
+	}
+	return attrs
+}
@@ -0,0 +1,138 @@
+package fingerprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func writeScript(t *testing.T, dir, name, body string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return path
+}
+
+func TestScriptFingerprint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fingerprint script tests require a shell")
+	}
+	dir, err := ioutil.TempDir("", "nomad-fingerprint")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeScript(t, dir, "licenses", "echo 'foo = bar'\necho '# comment'\necho baz=qux\necho invalid\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("docs"), 0644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	cfg := &config.FingerprintScriptsConfig{
+		Dir: dir,
+		Scripts: map[string]*config.FingerprintScriptConfig{
+			"licenses": {Interval: time.Hour},
+		},
+	}
+	scripts, err := ScriptFingerprints(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(scripts) != 1 {
+		t.Fatalf("expected only the executable script, got %d", len(scripts))
+	}
+	fp := scripts[0]
+	if p, d := fp.Periodic(); !p || d != time.Hour {
+		t.Fatalf("unexpected periodic %v %v", p, d)
+	}
+
+	node := &structs.Node{
+		Attributes: map[string]string{"kernel.name": "linux"},
+	}
+	assertFingerprintOK(t, fp, node)
+	assertNodeAttributeEquals(t, node, "custom.foo", "bar")
+	assertNodeAttributeEquals(t, node, "custom.baz", "qux")
+	if len(node.Attributes) != 3 {
+		t.Fatalf("unexpected attributes: %v", node.Attributes)
+	}
+
+	// Attributes that are no longer reported are removed
+	writeScript(t, dir, "licenses", "echo foo=updated\n")
+	assertFingerprintOK(t, fp, node)
+	assertNodeAttributeEquals(t, node, "custom.foo", "updated")
+	if _, ok := node.Attributes["custom.baz"]; ok {
+		t.Fatalf("expected custom.baz to be removed: %v", node.Attributes)
+	}
+	assertNodeAttributeEquals(t, node, "kernel.name", "linux")
+
+	// A failing script leaves the attributes in place
+	writeScript(t, dir, "licenses", "exit 1\n")
+	if _, err := fp.Fingerprint(new(config.Config), node); err == nil {
+		t.Fatalf("expected an error")
+	}
+	assertNodeAttributeEquals(t, node, "custom.foo", "updated")
+
+	// A removed script reports nothing
+	os.Remove(path)
+	if _, err := fp.Fingerprint(new(config.Config), node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := node.Attributes["custom.foo"]; ok {
+		t.Fatalf("expected custom.foo to be removed: %v", node.Attributes)
+	}
+}
+
+func TestScriptFingerprint_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fingerprint script tests require a shell")
+	}
+	dir, err := ioutil.TempDir("", "nomad-fingerprint")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeScript(t, dir, "slow", "sleep 10\n")
+	fp := NewScriptFingerprint(path, time.Minute, 100*time.Millisecond, testLogger())
+
+	start := time.Now()
+	node := &structs.Node{Attributes: make(map[string]string)}
+	if _, err := fp.Fingerprint(new(config.Config), node); err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("script was not killed after the timeout")
+	}
+}
+
+func TestScriptFingerprint_Timeout_KillsChildren(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fingerprint script tests require a shell")
+	}
+	dir, err := ioutil.TempDir("", "nomad-fingerprint")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The script starts a child that keeps its output open. The script is
+	// only reaped once the child is killed as well.
+	path := writeScript(t, dir, "slow", "sleep 30 &\nwait\n")
+	fp := NewScriptFingerprint(path, time.Minute, 100*time.Millisecond, testLogger())
+
+	start := time.Now()
+	node := &structs.Node{Attributes: make(map[string]string)}
+	if _, err := fp.Fingerprint(new(config.Config), node); err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+	if time.Since(start) > scriptKillWait/2 {
+		t.Fatalf("children of the script were not killed after the timeout")
+	}
+}
@@ -0,0 +1,22 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package fingerprint
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup places the script in its own process group so the script
+// and any children it started can be killed together.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killProcessGroup kills the process group of the started script.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
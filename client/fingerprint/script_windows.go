@@ -0,0 +1,14 @@
+package fingerprint
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows where there are no process groups.
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// killProcessGroup kills the started script.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
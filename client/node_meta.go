@@ -0,0 +1,132 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper"
+)
+
+var (
+	// ErrNoNodeMeta is returned when applying node metadata without any keys
+	ErrNoNodeMeta = errors.New("no metadata to apply")
+
+	// ErrEmptyNodeMetaKey is returned when applying node metadata with an
+	// empty key
+	ErrEmptyNodeMetaKey = errors.New("metadata keys must not be empty")
+)
+
+// restoreNodeMeta overlays the persisted node metadata set at runtime on the
+// metadata from the configuration.
+func (c *Client) restoreNodeMeta() error {
+	var dynamic map[string]*string
+	err := c.stateDB.View(func(tx *bolt.Tx) error {
+		var err error
+		dynamic, err = getNodeMeta(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.staticMeta = helper.CopyMapStringString(c.config.Node.Meta)
+	c.dynamicMeta = dynamic
+	c.config.Node.Meta = mergeNodeMeta(c.staticMeta, c.dynamicMeta)
+	return nil
+}
+
+// NodeMeta returns the metadata of the node along with the metadata from the
+// configuration and the metadata set at runtime.
+func (c *Client) NodeMeta() *cstructs.NodeMetaResponse {
+	c.configLock.RLock()
+	defer c.configLock.RUnlock()
+	return &cstructs.NodeMetaResponse{
+		Meta:    helper.CopyMapStringString(c.config.Node.Meta),
+		Static:  helper.CopyMapStringString(c.staticMeta),
+		Dynamic: copyDynamicMeta(c.dynamicMeta),
+	}
+}
+
+// ApplyNodeMeta updates the metadata of the node at runtime. Keys set to nil
+// are removed from the node. The metadata is persisted so it survives client
+// restarts and the node is re-registered so the servers recompute its class
+// and unblock evaluations that may now be placed on it.
+func (c *Client) ApplyNodeMeta(meta map[string]*string) (*cstructs.NodeMetaResponse, error) {
+	if len(meta) == 0 {
+		return nil, ErrNoNodeMeta
+	}
+	for k := range meta {
+		if k == "" {
+			return nil, ErrEmptyNodeMetaKey
+		}
+	}
+
+	c.configLock.Lock()
+	dynamic := copyDynamicMeta(c.dynamicMeta)
+	if dynamic == nil {
+		dynamic = make(map[string]*string, len(meta))
+	}
+	for k, v := range meta {
+		if v == nil {
+			dynamic[k] = nil
+		} else {
+			dynamic[k] = helper.StringToPtr(*v)
+		}
+	}
+
+	err := c.stateDB.Update(func(tx *bolt.Tx) error {
+		return putNodeMeta(tx, dynamic)
+	})
+	if err != nil {
+		c.configLock.Unlock()
+		return nil, fmt.Errorf("failed to persist node metadata: %v", err)
+	}
+
+	c.dynamicMeta = dynamic
+	c.config.Node.Meta = mergeNodeMeta(c.staticMeta, dynamic)
+	c.configLock.Unlock()
+
+	// Push the change to the servers
+	select {
+	case c.nodeUpdatedCh <- struct{}{}:
+	default:
+	}
+
+	return c.NodeMeta(), nil
+}
+
+// mergeNodeMeta overlays the dynamic node metadata on the static metadata
+func mergeNodeMeta(static map[string]string, dynamic map[string]*string) map[string]string {
+	meta := make(map[string]string, len(static)+len(dynamic))
+	for k, v := range static {
+		meta[k] = v
+	}
+	for k, v := range dynamic {
+		if v == nil {
+			delete(meta, k)
+		} else {
+			meta[k] = *v
+		}
+	}
+	return meta
+}
+
+// copyDynamicMeta returns a deep copy of the dynamic node metadata
+func copyDynamicMeta(dynamic map[string]*string) map[string]*string {
+	if dynamic == nil {
+		return nil
+	}
+	c := make(map[string]*string, len(dynamic))
+	for k, v := range dynamic {
+		if v == nil {
+			c[k] = nil
+		} else {
+			c[k] = helper.StringToPtr(*v)
+		}
+	}
+	return c
+}
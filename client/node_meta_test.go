@@ -0,0 +1,96 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/command/agent/consul"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ApplyNodeMeta(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	s1, _ := testServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	c1 := testClient(t, func(c *config.Config) {
+		c.DevMode = false
+		c.RPCHandler = s1
+		c.Node.Meta = map[string]string{
+			"class": "a",
+			"zone":  "z1",
+		}
+	})
+	defer c1.Shutdown()
+	waitTilNodeReady(c1, t)
+	class := c1.Node().ComputedClass
+
+	// Empty keys are rejected
+	_, err := c1.ApplyNodeMeta(map[string]*string{"": helper.StringToPtr("foo")})
+	assert.Equal(ErrEmptyNodeMetaKey, err)
+	_, err = c1.ApplyNodeMeta(nil)
+	assert.Equal(ErrNoNodeMeta, err)
+
+	// Set a key and remove a configured one
+	out, err := c1.ApplyNodeMeta(map[string]*string{
+		"rack": helper.StringToPtr("r1"),
+		"zone": nil,
+	})
+	assert.Nil(err)
+	assert.Equal(map[string]string{"class": "a", "rack": "r1"}, out.Meta)
+	assert.Equal(map[string]string{"class": "a", "zone": "z1"}, out.Static)
+
+	// The servers should see the change and a new computed class
+	testutil.WaitForResult(func() (bool, error) {
+		node, err := s1.State().NodeByID(nil, c1.NodeID())
+		if err != nil {
+			return false, err
+		}
+		if node.Meta["rack"] != "r1" {
+			return false, fmt.Errorf("node meta not updated: %v", node.Meta)
+		}
+		if _, ok := node.Meta["zone"]; ok {
+			return false, fmt.Errorf("node meta not removed: %v", node.Meta)
+		}
+		if node.ComputedClass == class {
+			return false, fmt.Errorf("computed class not updated")
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Restart the client with its configured metadata
+	if err := c1.Shutdown(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c1.config.Node.Meta = map[string]string{
+		"class": "a",
+		"zone":  "z1",
+	}
+
+	logger := log.New(c1.config.LogOutput, "", log.LstdFlags)
+	catalog := consul.NewMockCatalog(logger)
+	mockService := newMockConsulServiceClient()
+	mockService.logger = logger
+	c2, err := NewClient(c1.config, catalog, mockService, logger)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer c2.Shutdown()
+
+	// The metadata set at runtime is restored
+	assert.Equal(map[string]string{"class": "a", "rack": "r1"}, c2.Node().Meta)
+	meta := c2.NodeMeta()
+	assert.Equal("r1", *meta.Dynamic["rack"])
+	assert.Contains(meta.Dynamic, "zone")
+	assert.Nil(meta.Dynamic["zone"])
+	assert.Equal(structs.NodeStatusInit, c2.Node().Status)
+}
@@ -22,7 +22,7 @@ const (
 
 func newRestartTracker(policy *structs.RestartPolicy, jobType string) *RestartTracker {
 	onSuccess := true
-	if jobType == structs.JobTypeBatch {
+	if jobType == structs.JobTypeBatch || jobType == structs.JobTypeSysBatch {
 		onSuccess = false
 	}
 	return &RestartTracker{
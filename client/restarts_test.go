@@ -84,6 +84,15 @@ func TestClient_RestartTracker_NoRestartOnSuccess(t *testing.T) {
 	}
 }
 
+func TestClient_RestartTracker_NoRestartOnSuccess_SysBatch(t *testing.T) {
+	t.Parallel()
+	p := testPolicy(false, structs.RestartPolicyModeDelay)
+	rt := newRestartTracker(p, structs.JobTypeSysBatch)
+	if state, _ := rt.SetWaitResult(testWaitResult(0)).GetState(); state != structs.TaskTerminated {
+		t.Fatalf("NextRestart() returned %v, expected: %v", state, structs.TaskTerminated)
+	}
+}
+
 func TestClient_RestartTracker_ZeroAttempts(t *testing.T) {
 	t.Parallel()
 	p := testPolicy(true, structs.RestartPolicyModeFail)
@@ -17,12 +17,21 @@ allocations/ (bucket)
     |--> alloc_runner persisted objects (k/v)
 	|--> <task-name>/ (bucket)
         |--> task_runner persisted objects (k/v)
+
+node/ (bucket)
+|--> meta -> dynamic node metadata (k/v)
 */
 
 var (
 	// allocationsBucket is the bucket name containing all allocation related
 	// data
 	allocationsBucket = []byte("allocations")
+
+	// nodeBucket is the bucket name containing node related data
+	nodeBucket = []byte("node")
+
+	// nodeMetaKey is the key of the node metadata set at runtime
+	nodeMetaKey = []byte("meta")
 )
 
 func putObject(bkt *bolt.Bucket, key []byte, obj interface{}) error {
@@ -201,3 +210,27 @@ func getAllAllocationIDs(tx *bolt.Tx) ([]string, error) {
 
 	return allocIDs, nil
 }
+
+// putNodeMeta persists the node metadata set at runtime
+func putNodeMeta(tx *bolt.Tx, meta map[string]*string) error {
+	bkt, err := tx.CreateBucketIfNotExists(nodeBucket)
+	if err != nil {
+		return err
+	}
+	return putObject(bkt, nodeMetaKey, meta)
+}
+
+// getNodeMeta returns the persisted node metadata set at runtime or nil if
+// none was persisted
+func getNodeMeta(tx *bolt.Tx) (map[string]*string, error) {
+	bkt := tx.Bucket(nodeBucket)
+	if bkt == nil || bkt.Get(nodeMetaKey) == nil {
+		return nil, nil
+	}
+
+	var meta map[string]*string
+	if err := getObject(bkt, nodeMetaKey, &meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
@@ -69,6 +69,26 @@ type TaskResourceUsage struct {
 	ResourceUsage *ResourceUsage
 	Timestamp     int64
 	Pids          map[string]*ResourceUsage
+
+	// LogShippers holds the delivery statistics of the task's log shippers
+	// keyed by the shipper name
+	LogShippers map[string]*LogShipperStats
+}
+
+// LogShipperStats holds the delivery statistics of a task's log shipper
+type LogShipperStats struct {
+	// Shipped is the number of log lines delivered to the sink
+	Shipped uint64
+
+	// Dropped is the number of log lines dropped because the buffer was full
+	Dropped uint64
+
+	// Failed is the number of log lines that could not be delivered to the
+	// sink
+	Failed uint64
+
+	// Buffered is the number of log lines waiting to be shipped
+	Buffered int
 }
 
 // AllocResourceUsage holds the aggregated task resource usage of the
@@ -84,6 +104,26 @@ type AllocResourceUsage struct {
 	Timestamp int64
 }
 
+// NodeMetaApplyRequest is used to update the metadata of the node at runtime
+type NodeMetaApplyRequest struct {
+	// Meta is the metadata to set. A nil value removes the key from the
+	// node.
+	Meta map[string]*string
+}
+
+// NodeMetaResponse is the metadata of the node
+type NodeMetaResponse struct {
+	// Meta is the metadata of the node as seen by the servers
+	Meta map[string]string
+
+	// Static is the metadata from the client configuration
+	Static map[string]string
+
+	// Dynamic is the metadata set at runtime which is overlaid on the
+	// static metadata. A nil value removes the key from the node.
+	Dynamic map[string]*string
+}
+
 // joinStringSet takes two slices of strings and joins them
 func joinStringSet(s1, s2 []string) []string {
 	lookup := make(map[string]struct{}, len(s1))
@@ -131,6 +171,40 @@ func (f FSIsolation) String() string {
 	}
 }
 
+// NetworkIsolationSpec describes the network namespace shared by the tasks of
+// an allocation whose task group requests a bridge or none network mode.
+type NetworkIsolationSpec struct {
+	// Mode is the network mode of the task group.
+	Mode string
+
+	// Path is the path to the network namespace tasks should join.
+	Path string
+
+	// Labels contains driver specific information about the namespace, such
+	// as the ID of the container holding it open.
+	Labels map[string]string
+
+	// IP is the address assigned to the namespace on the bridge, if any.
+	IP string
+}
+
+// Copy a NetworkIsolationSpec struct. If it is nil, nil is returned.
+func (n *NetworkIsolationSpec) Copy() *NetworkIsolationSpec {
+	if n == nil {
+		return nil
+	}
+	labels := make(map[string]string, len(n.Labels))
+	for k, v := range n.Labels {
+		labels[k] = v
+	}
+	return &NetworkIsolationSpec{
+		Mode:   n.Mode,
+		Path:   n.Path,
+		Labels: labels,
+		IP:     n.IP,
+	}
+}
+
 // DriverNetwork is the network created by driver's (eg Docker's bridge
 // network) during Prestart.
 type DriverNetwork struct {
@@ -57,6 +57,10 @@ const (
 	// vaultTokenFile is the name of the file holding the Vault token inside the
 	// task's secret directory
 	vaultTokenFile = "vault_token"
+
+	// consulTokenFile is the name of the file holding the derived Consul token
+	// inside the task's secret directory
+	consulTokenFile = "consul_token"
 )
 
 var (
@@ -109,6 +113,20 @@ type TaskRunner struct {
 	driverNet     *cstructs.DriverNetwork
 	driverNetLock sync.Mutex
 
+	// networkIsolation is the network namespace shared by the tasks of the
+	// allocation or nil if the task uses the host's network. It is set
+	// before the task runner is started and never modified.
+	networkIsolation *cstructs.NetworkIsolationSpec
+
+	// reservesCores is whether the task is pinned to CPU cores reserved for
+	// it. Reserved cores can't change during the lifetime of the task.
+	reservesCores bool
+
+	// sharedCores are the CPU cores the task runs on if it doesn't reserve
+	// cores. Empty if the task is not restricted to a set of cores.
+	sharedCores     []uint16
+	sharedCoresLock sync.Mutex
+
 	// updateCh is used to receive updated versions of the allocation
 	updateCh chan *structs.Allocation
 
@@ -144,6 +162,16 @@ type TaskRunner struct {
 	// vaultClient is used to retrieve and renew any needed Vault token
 	vaultClient vaultclient.VaultClient
 
+	// consulTokenDeriver derives the Consul token the task's services and
+	// templates use. It is nil if tasks use the agent's token.
+	consulTokenDeriver ConsulTokenDeriverFn
+
+	// consulToken is the Consul token derived for the task. If empty the
+	// agent's token is used.
+	//
+	// Must acquire persistLock when accessing
+	consulToken string
+
 	// templateManager is used to manage any consul-templates this task may have
 	templateManager *TaskTemplateManager
 
@@ -266,6 +294,7 @@ func NewTaskRunner(logger *log.Logger, config *config.Config,
 		unblockCh:        make(chan struct{}),
 		restartCh:        make(chan *taskRestartEvent),
 		signalCh:         make(chan SignalEvent),
+		reservesCores:    task.Resources != nil && len(task.Resources.CoreIDs) != 0,
 	}
 
 	tc.baseLabels = []metrics.Label{
@@ -297,6 +326,68 @@ func (r *TaskRunner) MarkReceived() {
 	r.updater(r.task.Name, structs.TaskStatePending, structs.NewTaskEvent(structs.TaskReceived), true)
 }
 
+// SetNetworkIsolation sets the network namespace shared by the tasks of the
+// allocation. It must be called before Run.
+func (r *TaskRunner) SetNetworkIsolation(spec *cstructs.NetworkIsolationSpec) {
+	r.networkIsolation = spec
+}
+
+// SetSharedCores sets the CPU cores the task runs on if it doesn't reserve
+// cores. If the task is running it is moved onto the cores.
+func (r *TaskRunner) SetSharedCores(cores []uint16) {
+	r.sharedCoresLock.Lock()
+	r.sharedCores = cores
+	r.sharedCoresLock.Unlock()
+
+	// Tasks with reserved cores stay pinned to them
+	if r.reservesCores {
+		return
+	}
+
+	handle := r.getHandle()
+	if handle == nil {
+		return
+	}
+	updater, ok := handle.(driver.CpusetUpdater)
+	if !ok {
+		return
+	}
+	if err := updater.UpdateCpuset(cores); err != nil {
+		r.logger.Printf("[WARN] client: failed to update cpuset of task %q for alloc %q: %v",
+			r.task.Name, r.alloc.ID, err)
+	}
+}
+
+// getSharedCores returns the CPU cores the task runs on if it doesn't
+// reserve cores.
+func (r *TaskRunner) getSharedCores() []uint16 {
+	r.sharedCoresLock.Lock()
+	defer r.sharedCoresLock.Unlock()
+	return r.sharedCores
+}
+
+// SetConsulTokenDeriver sets the function used to derive the task's Consul
+// token. It must be called before Run.
+func (r *TaskRunner) SetConsulTokenDeriver(f ConsulTokenDeriverFn) {
+	r.consulTokenDeriver = f
+}
+
+// getConsulToken returns the Consul token derived for the task.
+func (r *TaskRunner) getConsulToken() string {
+	r.persistLock.Lock()
+	defer r.persistLock.Unlock()
+	return r.consulToken
+}
+
+// newExecContext returns an ExecContext for the task using the current
+// environment.
+func (r *TaskRunner) newExecContext() *driver.ExecContext {
+	ctx := driver.NewExecContext(r.taskDir, r.envBuilder.Build())
+	ctx.NetworkIsolation = r.networkIsolation
+	ctx.SharedCores = r.getSharedCores()
+	return ctx
+}
+
 // WaitCh returns a channel to wait for termination
 func (r *TaskRunner) WaitCh() <-chan struct{} {
 	return r.waitCh
@@ -371,6 +462,16 @@ func (r *TaskRunner) RestoreState() (string, error) {
 	r.setCreatedResources(snap.CreatedResources)
 	r.driverNet = snap.DriverNetwork
 
+	// Read the derived Consul token from the secret directory
+	consulTokenPath := filepath.Join(r.taskDir.SecretsDir, consulTokenFile)
+	if data, err := ioutil.ReadFile(consulTokenPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read Consul token for task %q in alloc %q: %v", r.task.Name, r.alloc.ID, err)
+		}
+	} else {
+		r.consulToken = string(data)
+	}
+
 	if r.task.Vault != nil {
 		// Read the token from the secret directory
 		tokenPath := filepath.Join(r.taskDir.SecretsDir, vaultTokenFile)
@@ -399,7 +500,7 @@ func (r *TaskRunner) RestoreState() (string, error) {
 		r.envBuilder.SetDriverNetwork(r.driverNet)
 
 		// Open a connection to the driver handle
-		ctx := driver.NewExecContext(r.taskDir, r.envBuilder.Build())
+		ctx := r.newExecContext()
 		handle, err := d.Open(ctx, snap.HandleID)
 
 		// In the case it fails, we relaunch the task in the Run() method.
@@ -879,6 +980,67 @@ func (r *TaskRunner) deriveVaultToken() (token string, exit bool) {
 	}
 }
 
+// deriveConsulToken derives the Consul token of the task if it registers
+// services or renders templates and no token has been derived yet. Recoverable
+// errors are retried using exponential backoffs. It returns false if the task
+// should not be started.
+func (r *TaskRunner) deriveConsulToken(alloc *structs.Allocation, task *structs.Task) bool {
+	if r.consulTokenDeriver == nil || (len(task.Services) == 0 && len(task.Templates) == 0) {
+		return true
+	}
+	if r.getConsulToken() != "" {
+		return true
+	}
+
+	attempts := 0
+	for {
+		tokens, err := r.consulTokenDeriver(alloc, []string{task.Name})
+		if err == nil {
+			token := tokens[task.Name]
+			if err := r.writeConsulToken(token); err != nil {
+				r.logger.Printf("[ERR] client: %v", err)
+				r.setState(structs.TaskStateDead,
+					structs.NewTaskEvent(structs.TaskSetupFailure).SetSetupError(err).SetFailsTask(),
+					false)
+				return false
+			}
+
+			r.persistLock.Lock()
+			r.consulToken = token
+			r.persistLock.Unlock()
+			return true
+		}
+
+		// Check if we can't recover from the error
+		if !structs.IsRecoverable(err) {
+			r.logger.Printf("[ERR] client: failed to derive Consul token for task %v on alloc %q: %v",
+				task.Name, alloc.ID, err)
+			wrapped := fmt.Errorf("failed to derive Consul token: %v", err)
+			r.setState(structs.TaskStateDead,
+				structs.NewTaskEvent(structs.TaskSetupFailure).SetSetupError(wrapped).SetFailsTask(),
+				false)
+			return false
+		}
+
+		// Handle the retry case
+		backoff := (1 << (2 * uint64(attempts))) * vaultBackoffBaseline
+		if backoff > vaultBackoffLimit {
+			backoff = vaultBackoffLimit
+		}
+		r.logger.Printf("[ERR] client: failed to derive Consul token for task %v on alloc %q: %v; retrying in %v",
+			task.Name, alloc.ID, err, backoff)
+
+		attempts++
+
+		// Wait till retrying
+		select {
+		case <-r.waitCh:
+			return false
+		case <-time.After(backoff):
+		}
+	}
+}
+
 // writeToken writes the given token to disk
 func (r *TaskRunner) writeToken(token string) error {
 	tokenPath := filepath.Join(r.taskDir.SecretsDir, vaultTokenFile)
@@ -889,6 +1051,18 @@ func (r *TaskRunner) writeToken(token string) error {
 	return nil
 }
 
+// writeConsulToken writes the derived Consul token to the task's secret
+// directory so it survives client restarts without being persisted in the
+// client's state database.
+func (r *TaskRunner) writeConsulToken(token string) error {
+	tokenPath := filepath.Join(r.taskDir.SecretsDir, consulTokenFile)
+	if err := ioutil.WriteFile(tokenPath, []byte(token), 0600); err != nil {
+		return fmt.Errorf("failed to save Consul token to secret dir for task %q in alloc %q: %v", r.task.Name, r.alloc.ID, err)
+	}
+
+	return nil
+}
+
 // updatedTokenHandler is called when a new Vault token is retrieved. Things
 // that rely on the token should be updated here.
 func (r *TaskRunner) updatedTokenHandler() {
@@ -906,6 +1080,7 @@ func (r *TaskRunner) updatedTokenHandler() {
 			Templates:            r.task.Templates,
 			ClientConfig:         r.config,
 			VaultToken:           r.vaultFuture.Get(),
+			ConsulToken:          r.getConsulToken(),
 			TaskDir:              r.taskDir.Dir,
 			EnvBuilder:           r.envBuilder,
 			MaxTemplateEventRate: DefaultMaxTemplateEventRate,
@@ -941,6 +1116,12 @@ func (r *TaskRunner) prestart(alloc *structs.Allocation, task *structs.Task, res
 		r.envBuilder.SetVaultToken(r.vaultFuture.Get(), task.Vault.Env)
 	}
 
+	// Derive the Consul token used by the task's services and templates
+	if !r.deriveConsulToken(alloc, task) {
+		resultCh <- false
+		return
+	}
+
 	// If the job is a dispatch job and there is a payload write it to disk
 	requirePayload := len(alloc.Job.Payload) != 0 &&
 		(r.task.DispatchPayload != nil && r.task.DispatchPayload.File != "")
@@ -1022,6 +1203,7 @@ func (r *TaskRunner) prestart(alloc *structs.Allocation, task *structs.Task, res
 				Templates:            r.task.Templates,
 				ClientConfig:         r.config,
 				VaultToken:           r.vaultFuture.Get(),
+				ConsulToken:          r.getConsulToken(),
 				TaskDir:              r.taskDir.Dir,
 				EnvBuilder:           r.envBuilder,
 				MaxTemplateEventRate: DefaultMaxTemplateEventRate,
@@ -1276,7 +1458,7 @@ func (r *TaskRunner) cleanup() {
 
 	res := r.getCreatedResources()
 
-	ctx := driver.NewExecContext(r.taskDir, r.envBuilder.Build())
+	ctx := r.newExecContext()
 	attempts := 1
 	var cleanupErr error
 	for retry := true; retry; attempts++ {
@@ -1405,7 +1587,7 @@ func (r *TaskRunner) startTask() error {
 	}
 
 	// Run prestart
-	ctx := driver.NewExecContext(r.taskDir, r.envBuilder.Build())
+	ctx := r.newExecContext()
 	presp, err := drv.Prestart(ctx, r.task)
 
 	// Merge newly created resources into previously created resources
@@ -1426,7 +1608,7 @@ func (r *TaskRunner) startTask() error {
 	}
 
 	// Create a new context for Start since the environment may have been updated.
-	ctx = driver.NewExecContext(r.taskDir, r.envBuilder.Build())
+	ctx = r.newExecContext()
 
 	// Start the job
 	sresp, err := drv.Start(ctx, r.task)
@@ -1474,6 +1656,7 @@ func (r *TaskRunner) registerServices(d driver.Driver, h driver.DriverHandle, n
 		exec = h
 	}
 	interpolatedTask := interpolateServices(r.envBuilder.Build(), r.task)
+	r.consul.SetTaskToken(r.alloc.ID, r.task.Name, r.getConsulToken())
 	return r.consul.RegisterTask(r.alloc.ID, interpolatedTask, r, exec, n)
 }
 
@@ -1677,6 +1860,7 @@ func (r *TaskRunner) updateServices(d driver.Driver, h driver.ScriptExecutor, ol
 	r.driverNetLock.Lock()
 	net := r.driverNet.Copy()
 	r.driverNetLock.Unlock()
+	r.consul.SetTaskToken(r.alloc.ID, new.Name, r.getConsulToken())
 	return r.consul.UpdateTask(r.alloc.ID, oldInterpolatedTask, newInterpolatedTask, r, exec, net)
 }
 
@@ -1882,6 +2066,32 @@ func (r *TaskRunner) setGaugeForCPU(ru *cstructs.TaskResourceUsage) {
 	}
 }
 
+func (r *TaskRunner) setGaugeForLogShippers(ru *cstructs.TaskResourceUsage) {
+	for name, ls := range ru.LogShippers {
+		if !r.config.DisableTaggedMetrics {
+			labels := make([]metrics.Label, len(r.baseLabels), len(r.baseLabels)+1)
+			copy(labels, r.baseLabels)
+			labels = append(labels, metrics.Label{Name: "log_shipper", Value: name})
+
+			metrics.SetGaugeWithLabels([]string{"client", "allocs", "log_shipper", "shipped"},
+				float32(ls.Shipped), labels)
+			metrics.SetGaugeWithLabels([]string{"client", "allocs", "log_shipper", "dropped"},
+				float32(ls.Dropped), labels)
+			metrics.SetGaugeWithLabels([]string{"client", "allocs", "log_shipper", "failed"},
+				float32(ls.Failed), labels)
+			metrics.SetGaugeWithLabels([]string{"client", "allocs", "log_shipper", "buffered"},
+				float32(ls.Buffered), labels)
+		}
+
+		if r.config.BackwardsCompatibleMetrics {
+			metrics.SetGauge([]string{"client", "allocs", r.alloc.Job.Name, r.alloc.TaskGroup, r.alloc.ID, r.task.Name, "log_shipper", name, "shipped"}, float32(ls.Shipped))
+			metrics.SetGauge([]string{"client", "allocs", r.alloc.Job.Name, r.alloc.TaskGroup, r.alloc.ID, r.task.Name, "log_shipper", name, "dropped"}, float32(ls.Dropped))
+			metrics.SetGauge([]string{"client", "allocs", r.alloc.Job.Name, r.alloc.TaskGroup, r.alloc.ID, r.task.Name, "log_shipper", name, "failed"}, float32(ls.Failed))
+			metrics.SetGauge([]string{"client", "allocs", r.alloc.Job.Name, r.alloc.TaskGroup, r.alloc.ID, r.task.Name, "log_shipper", name, "buffered"}, float32(ls.Buffered))
+		}
+	}
+}
+
 // emitStats emits resource usage stats of tasks to remote metrics collector
 // sinks
 func (r *TaskRunner) emitStats(ru *cstructs.TaskResourceUsage) {
@@ -1896,4 +2106,8 @@ func (r *TaskRunner) emitStats(ru *cstructs.TaskResourceUsage) {
 	if ru.ResourceUsage.CpuStats != nil {
 		r.setGaugeForCPU(ru)
 	}
+
+	if len(ru.LogShippers) != 0 {
+		r.setGaugeForLogShippers(ru)
+	}
 }
@@ -444,6 +444,40 @@ func TestTaskRunner_SaveRestoreState(t *testing.T) {
 	}
 }
 
+func TestTaskRunner_ConsulToken_SaveRestoreState(t *testing.T) {
+	t.Parallel()
+	ctx := testTaskRunner(t, false)
+	defer ctx.Cleanup()
+
+	ctx.tr.SetConsulTokenDeriver(func(a *structs.Allocation, tasks []string) (map[string]string, error) {
+		return map[string]string{tasks[0]: "1234"}, nil
+	})
+	if !ctx.tr.deriveConsulToken(ctx.tr.alloc, ctx.tr.task) {
+		t.Fatalf("failed to derive token")
+	}
+	if err := ctx.tr.SaveState(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The token is written to the secrets dir rather than the state db
+	data, err := ioutil.ReadFile(filepath.Join(ctx.tr.taskDir.SecretsDir, consulTokenFile))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "1234" {
+		t.Fatalf("bad token on disk: %q", data)
+	}
+
+	tr2 := NewTaskRunner(ctx.tr.logger, ctx.tr.config, ctx.tr.stateDB, ctx.upd.Update,
+		ctx.tr.taskDir, ctx.tr.alloc, ctx.tr.task, ctx.tr.vaultClient, ctx.tr.consul)
+	if _, err := tr2.RestoreState(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if token := tr2.getConsulToken(); token != "1234" {
+		t.Fatalf("bad restored token: %q", token)
+	}
+}
+
 func TestTaskRunner_Download_List(t *testing.T) {
 	t.Parallel()
 	ts := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir("."))))
@@ -145,6 +145,9 @@ func convertServerConfig(agentConfig *Config, logOutput io.Writer) (*nomad.Confi
 	if len(agentConfig.Server.EnabledSchedulers) != 0 {
 		conf.EnabledSchedulers = agentConfig.Server.EnabledSchedulers
 	}
+	if len(agentConfig.Server.SchedulerPlugins) != 0 {
+		conf.SchedulerPlugins = agentConfig.Server.SchedulerPlugins
+	}
 	if agentConfig.ACL.Enabled {
 		conf.ACLEnabled = true
 	}
@@ -312,6 +315,7 @@ func (a *Agent) clientConfig() (*clientconfig.Config, error) {
 	conf.Node.Name = a.config.NodeName
 	conf.Node.Meta = a.config.Client.Meta
 	conf.Node.NodeClass = a.config.Client.NodeClass
+	conf.Node.NodePool = a.config.Client.NodePool
 
 	// Set up the HTTP advertise address
 	conf.Node.HTTPAddr = a.config.AdvertiseAddrs.HTTP
@@ -326,6 +330,7 @@ func (a *Agent) clientConfig() (*clientconfig.Config, error) {
 	r.MemoryMB = a.config.Client.Reserved.MemoryMB
 	r.DiskMB = a.config.Client.Reserved.DiskMB
 	r.IOPS = a.config.Client.Reserved.IOPS
+	r.CoreIDs = a.config.Client.Reserved.ParsedCores
 	conf.GloballyReservedPorts = a.config.Client.Reserved.ParsedReservedPorts
 
 	conf.Version = a.config.Version
@@ -361,6 +366,13 @@ func (a *Agent) clientConfig() (*clientconfig.Config, error) {
 		conf.NoHostUUID = true
 	}
 
+	// Set the ephemeral disk enforcement configs
+	conf.DiskUsageCheckInterval = a.config.Client.DiskUsageCheckInterval
+	conf.DiskQuotas = a.config.Client.DiskQuotas
+
+	// Setup the fingerprint scripts
+	conf.FingerprintScripts = a.config.Client.FingerprintScripts.Copy()
+
 	// Setup the ACLs
 	conf.ACLEnabled = a.config.ACL.Enabled
 	conf.ACLTokenTTL = a.config.ACL.TokenTTL
@@ -741,6 +753,19 @@ func (a *Agent) setupConsul(consulConfig *config.ConsulConfig) error {
 	// Create Consul Service client for service advertisement and checks.
 	a.consulService = consul.NewServiceClient(client.Agent(), a.consulSupportsTLSSkipVerify, a.logger)
 
+	// Tasks with their own Consul token register their services using a
+	// client sharing the agent's HTTP client but using the task's token.
+	a.consulService.SetTokenAgentFactory(func(token string) consul.AgentAPI {
+		tokenConf := *apiConf
+		tokenConf.Token = token
+		tokenClient, err := api.NewClient(&tokenConf)
+		if err != nil {
+			a.logger.Printf("[ERR] agent: failed to create Consul client for task token: %v", err)
+			return client.Agent()
+		}
+		return tokenClient.Agent()
+	})
+
 	// Run the Consul service client's sync'ing main loop
 	go a.consulService.Run()
 	return nil
@@ -25,6 +25,7 @@ client {
 	alloc_dir = "/tmp/alloc"
 	servers = ["a.b.c:80", "127.0.0.1:1234"]
 	node_class = "linux-medium-64bit"
+	node_pool = "gpu"
 	meta {
 		foo = "bar"
 		baz = "zip"
@@ -46,6 +47,7 @@ client {
 		disk = 10
 		iops = 10
 		reserved_ports = "1,100,10-12"
+		cores = "0-1"
 	}
 	client_min_port = 1000
 	client_max_port = 2000
@@ -60,6 +62,16 @@ client {
     gc_inode_usage_threshold = 91
     gc_max_allocs = 50
     no_host_uuid = false
+    disk_usage_check_interval = "15s"
+    disk_quotas = true
+    fingerprint_scripts {
+        dir = "/etc/nomad/fingerprint.d"
+        interval = "10m"
+        timeout = "20s"
+        script "licenses" {
+            interval = "1h"
+        }
+    }
 }
 server {
 	enabled = true
@@ -82,6 +94,10 @@ server {
 	retry_interval = "15s"
 	rejoin_after_leave = true
     encrypt = "abc"
+	scheduler_plugin "custom" {
+		command = "/usr/local/bin/custom-scheduler"
+		args = ["-foo"]
+	}
 }
 acl {
     enabled = true
@@ -130,6 +146,7 @@ consul {
     client_auto_join = true
     auto_advertise = true
     checks_use_advertise = true
+    allow_unauthenticated = false
 }
 vault {
     address = "127.0.0.1:9500"
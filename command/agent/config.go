@@ -19,6 +19,7 @@ import (
 	client "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad"
+	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/version"
 )
@@ -170,6 +171,9 @@ type ClientConfig struct {
 	// NodeClass is used to group the node by class
 	NodeClass string `mapstructure:"node_class"`
 
+	// NodePool is the node pool the node joins
+	NodePool string `mapstructure:"node_pool"`
+
 	// Options is used for configuration of nomad internals,
 	// like fingerprinters and drivers. The format is:
 	//
@@ -232,6 +236,18 @@ type ClientConfig struct {
 	// NoHostUUID disables using the host's UUID and will force generation of a
 	// random UUID.
 	NoHostUUID *bool `mapstructure:"no_host_uuid"`
+
+	// DiskUsageCheckInterval is the interval at which the disk usage of each
+	// allocation is checked against its ephemeral disk size
+	DiskUsageCheckInterval time.Duration `mapstructure:"disk_usage_check_interval"`
+
+	// DiskQuotas enables limiting the disk usage of allocations with file
+	// system project quotas where supported
+	DiskQuotas bool `mapstructure:"disk_quotas"`
+
+	// FingerprintScripts configures a directory of executables whose
+	// key=value output is added to the node attributes
+	FingerprintScripts *client.FingerprintScriptsConfig `mapstructure:"fingerprint_scripts"`
 }
 
 // ACLConfig is configuration specific to the ACL system
@@ -287,6 +303,10 @@ type ServerConfig struct {
 	// that the workers dequeue for processing.
 	EnabledSchedulers []string `mapstructure:"enabled_schedulers"`
 
+	// SchedulerPlugins are the scheduler plugins launched by the server. Each
+	// plugin handles the evaluations of jobs of its type.
+	SchedulerPlugins []*config.SchedulerPluginConfig `mapstructure:"-"`
+
 	// NodeGCThreshold controls how "old" a node must be to be collected by GC.
 	// Age is not the only requirement for a node to be GCed but the threshold
 	// can be used to filter by age.
@@ -473,18 +493,27 @@ type AdvertiseAddrs struct {
 }
 
 type Resources struct {
-	CPU                 int    `mapstructure:"cpu"`
-	MemoryMB            int    `mapstructure:"memory"`
-	DiskMB              int    `mapstructure:"disk"`
-	IOPS                int    `mapstructure:"iops"`
-	ReservedPorts       string `mapstructure:"reserved_ports"`
-	ParsedReservedPorts []int  `mapstructure:"-"`
+	CPU                 int      `mapstructure:"cpu"`
+	MemoryMB            int      `mapstructure:"memory"`
+	DiskMB              int      `mapstructure:"disk"`
+	IOPS                int      `mapstructure:"iops"`
+	ReservedPorts       string   `mapstructure:"reserved_ports"`
+	ParsedReservedPorts []int    `mapstructure:"-"`
+	Cores               string   `mapstructure:"cores"`
+	ParsedCores         []uint16 `mapstructure:"-"`
 }
 
-// ParseReserved expands the ReservedPorts string into a slice of port numbers.
-// The supported syntax is comma separated integers or ranges separated by
-// hyphens. For example, "80,120-150,160"
+// ParseReserved expands the ReservedPorts string into a slice of port numbers
+// and the Cores string into a slice of core IDs. The supported syntax is comma
+// separated integers or ranges separated by hyphens. For example,
+// "80,120-150,160"
 func (r *Resources) ParseReserved() error {
+	cores, err := structs.ParseCpuset(r.Cores)
+	if err != nil {
+		return fmt.Errorf("failed to parse reserved cores: %v", err)
+	}
+	r.ParsedCores = cores
+
 	parts := strings.Split(r.ReservedPorts, ",")
 
 	// Hot path the empty case
@@ -588,17 +617,18 @@ func DefaultConfig() *Config {
 		Consul:         config.DefaultConsulConfig(),
 		Vault:          config.DefaultVaultConfig(),
 		Client: &ClientConfig{
-			Enabled:               false,
-			MaxKillTimeout:        "30s",
-			ClientMinPort:         14000,
-			ClientMaxPort:         14512,
-			Reserved:              &Resources{},
-			GCInterval:            1 * time.Minute,
-			GCParallelDestroys:    2,
-			GCDiskUsageThreshold:  80,
-			GCInodeUsageThreshold: 70,
-			GCMaxAllocs:           50,
-			NoHostUUID:            helper.BoolToPtr(true),
+			Enabled:                false,
+			MaxKillTimeout:         "30s",
+			ClientMinPort:          14000,
+			ClientMaxPort:          14512,
+			Reserved:               &Resources{},
+			GCInterval:             1 * time.Minute,
+			GCParallelDestroys:     2,
+			GCDiskUsageThreshold:   80,
+			GCInodeUsageThreshold:  70,
+			GCMaxAllocs:            50,
+			NoHostUUID:             helper.BoolToPtr(true),
+			DiskUsageCheckInterval: 30 * time.Second,
 		},
 		Server: &ServerConfig{
 			Enabled:          false,
@@ -1045,6 +1075,13 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	// Add the schedulers
 	result.EnabledSchedulers = append(result.EnabledSchedulers, b.EnabledSchedulers...)
 
+	// Add the scheduler plugins
+	if len(b.SchedulerPlugins) != 0 {
+		result.SchedulerPlugins = make([]*config.SchedulerPluginConfig, 0, len(a.SchedulerPlugins)+len(b.SchedulerPlugins))
+		result.SchedulerPlugins = append(result.SchedulerPlugins, a.SchedulerPlugins...)
+		result.SchedulerPlugins = append(result.SchedulerPlugins, b.SchedulerPlugins...)
+	}
+
 	// Copy the start join addresses
 	result.StartJoin = make([]string, 0, len(a.StartJoin)+len(b.StartJoin))
 	result.StartJoin = append(result.StartJoin, a.StartJoin...)
@@ -1074,6 +1111,9 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.NodeClass != "" {
 		result.NodeClass = b.NodeClass
 	}
+	if b.NodePool != "" {
+		result.NodePool = b.NodePool
+	}
 	if b.NetworkInterface != "" {
 		result.NetworkInterface = b.NetworkInterface
 	}
@@ -1117,6 +1157,15 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.NoHostUUID != nil {
 		result.NoHostUUID = b.NoHostUUID
 	}
+	if b.DiskUsageCheckInterval != 0 {
+		result.DiskUsageCheckInterval = b.DiskUsageCheckInterval
+	}
+	if b.DiskQuotas {
+		result.DiskQuotas = true
+	}
+	if b.FingerprintScripts != nil {
+		result.FingerprintScripts = result.FingerprintScripts.Merge(b.FingerprintScripts)
+	}
 
 	// Add the servers
 	result.Servers = append(result.Servers, b.Servers...)
@@ -1321,6 +1370,12 @@ func (r *Resources) Merge(b *Resources) *Resources {
 	if len(b.ParsedReservedPorts) != 0 {
 		result.ParsedReservedPorts = b.ParsedReservedPorts
 	}
+	if b.Cores != "" {
+		result.Cores = b.Cores
+	}
+	if len(b.ParsedCores) != 0 {
+		result.ParsedCores = b.ParsedCores
+	}
 	return &result
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
+	client "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/mitchellh/mapstructure"
 )
@@ -349,6 +350,7 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		"alloc_dir",
 		"servers",
 		"node_class",
+		"node_pool",
 		"options",
 		"meta",
 		"chroot_env",
@@ -366,6 +368,9 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		"gc_parallel_destroys",
 		"gc_max_allocs",
 		"no_host_uuid",
+		"disk_usage_check_interval",
+		"disk_quotas",
+		"fingerprint_scripts",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -381,6 +386,7 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 	delete(m, "chroot_env")
 	delete(m, "reserved")
 	delete(m, "stats")
+	delete(m, "fingerprint_scripts")
 
 	var config ClientConfig
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
@@ -444,6 +450,13 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse fingerprint scripts config
+	if o := listVal.Filter("fingerprint_scripts"); len(o.Items) > 0 {
+		if err := parseFingerprintScripts(&config.FingerprintScripts, o); err != nil {
+			return multierror.Prefix(err, "fingerprint_scripts ->")
+		}
+	}
+
 	*result = &config
 	return nil
 }
@@ -472,6 +485,7 @@ func parseReserved(result **Resources, list *ast.ObjectList) error {
 		"disk",
 		"iops",
 		"reserved_ports",
+		"cores",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -494,6 +508,88 @@ func parseReserved(result **Resources, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseFingerprintScripts(result **client.FingerprintScriptsConfig, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'fingerprint_scripts' block allowed")
+	}
+
+	// Get our fingerprint_scripts object
+	obj := list.Items[0]
+
+	// Value should be an object
+	var listVal *ast.ObjectList
+	if ot, ok := obj.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("fingerprint_scripts value: should be an object")
+	}
+
+	// Check for invalid keys
+	valid := []string{
+		"dir",
+		"interval",
+		"timeout",
+		"script",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+	delete(m, "script")
+
+	var config client.FingerprintScriptsConfig
+	if err := decodeDurations(m, &config); err != nil {
+		return err
+	}
+
+	// Parse the per script overrides
+	if o := listVal.Filter("script"); len(o.Items) > 0 {
+		config.Scripts = make(map[string]*client.FingerprintScriptConfig, len(o.Items))
+		for _, item := range o.Items {
+			if len(item.Keys) != 1 {
+				return fmt.Errorf("script block must be labeled with the script name")
+			}
+			name := item.Keys[0].Token.Value().(string)
+
+			if err := checkHCLKeys(item.Val, []string{"interval", "timeout"}); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("script %q ->", name))
+			}
+
+			var sm map[string]interface{}
+			if err := hcl.DecodeObject(&sm, item.Val); err != nil {
+				return err
+			}
+
+			var script client.FingerprintScriptConfig
+			if err := decodeDurations(sm, &script); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("script %q ->", name))
+			}
+			config.Scripts[name] = &script
+		}
+	}
+
+	*result = &config
+	return nil
+}
+
+// decodeDurations decodes m into result, parsing duration strings.
+func decodeDurations(m map[string]interface{}, result interface{}) error {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           result,
+	})
+	if err != nil {
+		return err
+	}
+	return dec.Decode(m)
+}
+
 func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -533,6 +629,7 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 		"rejoin_after_leave",
 		"encrypt",
 		"authoritative_region",
+		"scheduler_plugin",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -542,7 +639,11 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 	if err := hcl.DecodeObject(&m, listVal); err != nil {
 		return err
 	}
+	delete(m, "scheduler_plugin")
 
+	var plugins struct {
+		Plugins []*config.SchedulerPluginConfig `hcl:"scheduler_plugin,expand"`
+	}
 	var config ServerConfig
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
@@ -556,6 +657,15 @@ func parseServer(result **ServerConfig, list *ast.ObjectList) error {
 		return err
 	}
 
+	// Parse the scheduler plugins. These are keyed by job type so they are
+	// decoded by HCL directly.
+	if o := listVal.Filter("scheduler_plugin"); len(o.Items) > 0 {
+		if err := hcl.DecodeObject(&plugins, listVal); err != nil {
+			return err
+		}
+		config.SchedulerPlugins = plugins.Plugins
+	}
+
 	*result = &config
 	return nil
 }
@@ -715,6 +825,7 @@ func parseConsulConfig(result **config.ConsulConfig, list *ast.ObjectList) error
 	// Check for invalid keys
 	valid := []string{
 		"address",
+		"allow_unauthenticated",
 		"auth",
 		"auto_advertise",
 		"ca_file",
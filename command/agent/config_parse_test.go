@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	client "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/kr/pretty"
@@ -49,6 +50,7 @@ func TestConfig_Parse(t *testing.T) {
 					AllocDir:  "/tmp/alloc",
 					Servers:   []string{"a.b.c:80", "127.0.0.1:1234"},
 					NodeClass: "linux-medium-64bit",
+					NodePool:  "gpu",
 					Meta: map[string]string{
 						"foo": "bar",
 						"baz": "zip",
@@ -74,13 +76,27 @@ func TestConfig_Parse(t *testing.T) {
 						IOPS:                10,
 						ReservedPorts:       "1,100,10-12",
 						ParsedReservedPorts: []int{1, 10, 11, 12, 100},
+						Cores:               "0-1",
+						ParsedCores:         []uint16{0, 1},
+					},
+					GCInterval:             6 * time.Second,
+					GCParallelDestroys:     6,
+					GCDiskUsageThreshold:   82,
+					GCInodeUsageThreshold:  91,
+					GCMaxAllocs:            50,
+					NoHostUUID:             helper.BoolToPtr(false),
+					DiskUsageCheckInterval: 15 * time.Second,
+					DiskQuotas:             true,
+					FingerprintScripts: &client.FingerprintScriptsConfig{
+						Dir:      "/etc/nomad/fingerprint.d",
+						Interval: 10 * time.Minute,
+						Timeout:  20 * time.Second,
+						Scripts: map[string]*client.FingerprintScriptConfig{
+							"licenses": {
+								Interval: time.Hour,
+							},
+						},
 					},
-					GCInterval:            6 * time.Second,
-					GCParallelDestroys:    6,
-					GCDiskUsageThreshold:  82,
-					GCInodeUsageThreshold: 91,
-					GCMaxAllocs:           50,
-					NoHostUUID:            helper.BoolToPtr(false),
 				},
 				Server: &ServerConfig{
 					Enabled:                true,
@@ -103,6 +119,13 @@ func TestConfig_Parse(t *testing.T) {
 					RejoinAfterLeave:       true,
 					RetryMaxAttempts:       3,
 					EncryptKey:             "abc",
+					SchedulerPlugins: []*config.SchedulerPluginConfig{
+						{
+							JobType: "custom",
+							Command: "/usr/local/bin/custom-scheduler",
+							Args:    []string{"-foo"},
+						},
+					},
 				},
 				ACL: &ACLConfig{
 					Enabled:          true,
@@ -136,20 +159,21 @@ func TestConfig_Parse(t *testing.T) {
 					Endpoint:       "127.0.0.1:1234",
 				},
 				Consul: &config.ConsulConfig{
-					ServerServiceName:  "nomad",
-					ClientServiceName:  "nomad-client",
-					Addr:               "127.0.0.1:9500",
-					Token:              "token1",
-					Auth:               "username:pass",
-					EnableSSL:          &trueValue,
-					VerifySSL:          &trueValue,
-					CAFile:             "/path/to/ca/file",
-					CertFile:           "/path/to/cert/file",
-					KeyFile:            "/path/to/key/file",
-					ServerAutoJoin:     &trueValue,
-					ClientAutoJoin:     &trueValue,
-					AutoAdvertise:      &trueValue,
-					ChecksUseAdvertise: &trueValue,
+					ServerServiceName:    "nomad",
+					ClientServiceName:    "nomad-client",
+					Addr:                 "127.0.0.1:9500",
+					Token:                "token1",
+					Auth:                 "username:pass",
+					EnableSSL:            &trueValue,
+					VerifySSL:            &trueValue,
+					CAFile:               "/path/to/ca/file",
+					CertFile:             "/path/to/cert/file",
+					KeyFile:              "/path/to/key/file",
+					ServerAutoJoin:       &trueValue,
+					ClientAutoJoin:       &trueValue,
+					AutoAdvertise:        &trueValue,
+					ChecksUseAdvertise:   &trueValue,
+					AllowUnauthenticated: &falseValue,
 				},
 				Vault: &config.VaultConfig{
 					Addr:                 "127.0.0.1:9500",
@@ -80,6 +80,22 @@ type operations struct {
 
 	deregServices []string
 	deregChecks   []string
+
+	// tokens maps service and check IDs to the Consul token they must be
+	// registered with. Entries using the agent's token are omitted.
+	tokens map[string]string
+}
+
+// setToken records the Consul token the service or check must be registered
+// with.
+func (o *operations) setToken(id, token string) {
+	if token == "" {
+		return
+	}
+	if o.tokens == nil {
+		o.tokens = make(map[string]string)
+	}
+	o.tokens[id] = token
 }
 
 // AllocRegistration holds the status of services registered for a particular
@@ -206,6 +222,17 @@ type ServiceClient struct {
 	scripts        map[string]*scriptCheck
 	runningScripts map[string]*scriptHandle
 
+	// tokens maps service and check IDs to the Consul token they are
+	// registered with if it isn't the agent's token.
+	tokens map[string]string
+
+	// taskTokens stores the Consul tokens of tasks keyed by
+	// makeTaskTokenKey. tokenAgent returns a client using the given token and
+	// is nil if task tokens aren't supported.
+	taskTokens     map[string]string
+	taskTokensLock sync.RWMutex
+	tokenAgent     func(token string) AgentAPI
+
 	// allocRegistrations stores the services and checks that are registered
 	// with Consul by allocation ID.
 	allocRegistrations     map[string]*AllocRegistration
@@ -242,6 +269,8 @@ func NewServiceClient(consulClient AgentAPI, skipVerifySupport bool, logger *log
 		checks:             make(map[string]*api.AgentCheckRegistration),
 		scripts:            make(map[string]*scriptCheck),
 		runningScripts:     make(map[string]*scriptHandle),
+		tokens:             make(map[string]string),
+		taskTokens:         make(map[string]string),
 		allocRegistrations: make(map[string]*AllocRegistration),
 		agentServices:      make(map[string]struct{}),
 		agentChecks:        make(map[string]struct{}),
@@ -249,6 +278,51 @@ func NewServiceClient(consulClient AgentAPI, skipVerifySupport bool, logger *log
 	}
 }
 
+// SetTokenAgentFactory sets the function used to create Consul agent clients
+// that use a task's Consul token. It must be called before Run.
+func (c *ServiceClient) SetTokenAgentFactory(f func(token string) AgentAPI) {
+	c.tokenAgent = f
+}
+
+// SetTaskToken sets the Consul token used to register the task's services
+// and checks. It must be called before the task is registered. An empty
+// token registers them using the agent's token.
+func (c *ServiceClient) SetTaskToken(allocID, taskName, token string) {
+	key := makeTaskTokenKey(allocID, taskName)
+	c.taskTokensLock.Lock()
+	defer c.taskTokensLock.Unlock()
+	if token == "" {
+		delete(c.taskTokens, key)
+		return
+	}
+	c.taskTokens[key] = token
+}
+
+// taskToken returns the Consul token of the task or an empty string if the
+// agent's token should be used.
+func (c *ServiceClient) taskToken(allocID, taskName string) string {
+	if c.tokenAgent == nil {
+		return ""
+	}
+	c.taskTokensLock.RLock()
+	defer c.taskTokensLock.RUnlock()
+	return c.taskTokens[makeTaskTokenKey(allocID, taskName)]
+}
+
+// agentForToken returns a Consul agent client using the given token.
+func (c *ServiceClient) agentForToken(token string) AgentAPI {
+	if token == "" || c.tokenAgent == nil {
+		return c.client
+	}
+	return c.tokenAgent(token)
+}
+
+// agentFor returns the Consul agent client to use for the service or check
+// with the given ID.
+func (c *ServiceClient) agentFor(id string) AgentAPI {
+	return c.agentForToken(c.tokens[id])
+}
+
 // seen is used by markSeen and hasSeen
 const seen = 1
 
@@ -347,6 +421,9 @@ func (c *ServiceClient) merge(ops *operations) {
 	for _, s := range ops.scripts {
 		c.scripts[s.id] = s
 	}
+	for id, token := range ops.tokens {
+		c.tokens[id] = token
+	}
 	for _, sid := range ops.deregServices {
 		delete(c.services, sid)
 	}
@@ -390,7 +467,7 @@ func (c *ServiceClient) sync() error {
 			continue
 		}
 		// Unknown Nomad managed service; kill
-		if err := c.client.ServiceDeregister(id); err != nil {
+		if err := c.agentFor(id).ServiceDeregister(id); err != nil {
 			metrics.IncrCounter([]string{"client", "consul", "sync_failure"}, 1)
 			return err
 		}
@@ -415,7 +492,7 @@ func (c *ServiceClient) sync() error {
 			// Port changed, reregister it and its checks
 			portsChanged[id] = struct{}{}
 		}
-		if err = c.client.ServiceRegister(locals); err != nil {
+		if err = c.agentFor(id).ServiceRegister(locals); err != nil {
 			metrics.IncrCounter([]string{"client", "consul", "sync_failure"}, 1)
 			return err
 		}
@@ -434,7 +511,7 @@ func (c *ServiceClient) sync() error {
 			continue
 		}
 		// Unknown Nomad managed check; kill
-		if err := c.client.CheckDeregister(id); err != nil {
+		if err := c.agentFor(id).CheckDeregister(id); err != nil {
 			metrics.IncrCounter([]string{"client", "consul", "sync_failure"}, 1)
 			return err
 		}
@@ -450,7 +527,7 @@ func (c *ServiceClient) sync() error {
 				continue
 			}
 		}
-		if err := c.client.CheckRegister(check); err != nil {
+		if err := c.agentFor(id).CheckRegister(check); err != nil {
 			metrics.IncrCounter([]string{"client", "consul", "sync_failure"}, 1)
 			return err
 		}
@@ -468,6 +545,15 @@ func (c *ServiceClient) sync() error {
 		}
 	}
 
+	// Forget the tokens of services and checks that have been removed
+	for id := range c.tokens {
+		_, service := c.services[id]
+		_, check := c.checks[id]
+		if !service && !check {
+			delete(c.tokens, id)
+		}
+	}
+
 	// A Consul operation has succeeded, mark Consul as having been seen
 	c.markSeen()
 
@@ -512,6 +598,9 @@ func (c *ServiceClient) RegisterAgent(role string, services []*structs.Service)
 			if check.Type == structs.ServiceCheckScript {
 				return fmt.Errorf("service %q contains invalid check: agent checks do not support scripts", service.Name)
 			}
+			if check.Type == structs.ServiceCheckGRPC {
+				return fmt.Errorf("service %q contains invalid check: agent checks do not support grpc", service.Name)
+			}
 			checkHost, checkPort := serviceReg.Address, serviceReg.Port
 			if check.PortLabel != "" {
 				// Unlike tasks, agents don't use port labels. Agent ports are
@@ -600,6 +689,7 @@ func (c *ServiceClient) serviceRegs(ops *operations, allocID string, service *st
 	// with tests that may reuse Tasks
 	copy(serviceReg.Tags, service.Tags)
 	ops.regServices = append(ops.regServices, serviceReg)
+	ops.setToken(id, c.taskToken(allocID, task.Name))
 
 	// Build the check registrations
 	checkIDs, err := c.checkRegs(ops, allocID, id, service, task, exec, net)
@@ -623,6 +713,7 @@ func (c *ServiceClient) checkRegs(ops *operations, allocID, serviceID string, se
 		return nil, nil
 	}
 
+	token := c.taskToken(allocID, task.Name)
 	checkIDs := make([]string, 0, numChecks)
 	for _, check := range service.Checks {
 		if check.TLSSkipVerify && !c.skipVerifySupport {
@@ -637,7 +728,7 @@ func (c *ServiceClient) checkRegs(ops *operations, allocID, serviceID string, se
 				return nil, fmt.Errorf("driver doesn't support script checks")
 			}
 			ops.scripts = append(ops.scripts, newScriptCheck(
-				allocID, task.Name, checkID, check, exec, c.client, c.logger, c.shutdownCh))
+				allocID, task.Name, checkID, check, exec, c.agentForToken(token), c.logger, c.shutdownCh))
 
 		}
 
@@ -648,11 +739,19 @@ func (c *ServiceClient) checkRegs(ops *operations, allocID, serviceID string, se
 			portLabel = service.PortLabel
 		}
 		ip, port := task.Resources.Networks.Port(portLabel)
+
+		// gRPC checks are run by Nomad and heartbeated like script checks
+		if check.Type == structs.ServiceCheckGRPC {
+			grpcExec := newGRPCCheckExecutor(ip, port, check)
+			ops.scripts = append(ops.scripts, newScriptCheck(
+				allocID, task.Name, checkID, check, grpcExec, c.agentForToken(token), c.logger, c.shutdownCh))
+		}
 		checkReg, err := createCheckReg(serviceID, checkID, check, ip, port)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add check %q: %v", check.Name, err)
 		}
 		ops.regChecks = append(ops.regChecks, checkReg)
+		ops.setToken(checkID, token)
 	}
 	return checkIDs, nil
 }
@@ -851,6 +950,10 @@ func (c *ServiceClient) RemoveTask(allocID string, task *structs.Task) {
 	// Remove the task from the alloc's registrations
 	c.removeTaskRegistration(allocID, task.Name)
 
+	// Services are deregistered using the token they were registered with
+	// so the task's token is no longer needed
+	c.SetTaskToken(allocID, task.Name, "")
+
 	// Now add them to the deregistration fields; main Run loop will update
 	c.commit(&ops)
 }
@@ -1020,6 +1123,11 @@ func makeTaskServiceID(allocID, taskName string, service *structs.Service) strin
 	return strings.Join(parts, "-")
 }
 
+// makeTaskTokenKey creates the key of a task's Consul token.
+func makeTaskTokenKey(allocID, taskName string) string {
+	return allocID + "-" + taskName
+}
+
 // makeCheckID creates a unique ID for a check.
 func makeCheckID(serviceID string, check *structs.ServiceCheck) string {
 	return check.Hash(serviceID)
@@ -1027,8 +1135,8 @@ func makeCheckID(serviceID string, check *structs.ServiceCheck) string {
 
 // createCheckReg creates a Check that can be registered with Consul.
 //
-// Script checks simply have a TTL set and the caller is responsible for
-// running the script and heartbeating.
+// Script and gRPC checks simply have a TTL set and the caller is responsible
+// for running the check and heartbeating.
 func createCheckReg(serviceID, checkID string, check *structs.ServiceCheck, host string, port int) (*api.AgentCheckRegistration, error) {
 	chkReg := api.AgentCheckRegistration{
 		ID:        checkID,
@@ -1062,7 +1170,7 @@ func createCheckReg(serviceID, checkID string, check *structs.ServiceCheck, host
 		chkReg.Header = check.Header
 	case structs.ServiceCheckTCP:
 		chkReg.TCP = net.JoinHostPort(host, strconv.Itoa(port))
-	case structs.ServiceCheckScript:
+	case structs.ServiceCheckScript, structs.ServiceCheckGRPC:
 		chkReg.TTL = (check.Interval + ttlCheckBuffer).String()
 	default:
 		return nil, fmt.Errorf("check type %+q not valid", check.Type)
@@ -0,0 +1,76 @@
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// grpcCheckExecutor implements the ScriptExecutor interface by querying the
+// standard gRPC health checking protocol of a service. It allows gRPC checks
+// to be run natively by the client and heartbeated to Consul as TTL checks
+// using the same machinery as script checks.
+type grpcCheckExecutor struct {
+	// addr is the host:port of the gRPC server to check
+	addr string
+
+	// service is the name of the gRPC service to check. An empty service
+	// checks the overall health of the server.
+	service string
+
+	// useTLS enables TLS when connecting to the gRPC server
+	useTLS bool
+
+	// skipVerify disables verification of the server's certificate when
+	// useTLS is set
+	skipVerify bool
+}
+
+// newGRPCCheckExecutor returns an executor for the given gRPC check run
+// against host:port.
+func newGRPCCheckExecutor(host string, port int, check *structs.ServiceCheck) *grpcCheckExecutor {
+	return &grpcCheckExecutor{
+		addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		service:    check.GRPCService,
+		useTLS:     check.GRPCUseTLS,
+		skipVerify: check.TLSSkipVerify,
+	}
+}
+
+// Exec performs a single gRPC health check. The cmd and args are ignored. A
+// serving status returns an exit code of 0, any other status or error returns
+// an exit code of 2 which is treated as critical.
+func (g *grpcCheckExecutor) Exec(ctx context.Context, _ string, _ []string) ([]byte, int, error) {
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if g.useTLS {
+		tlsConf := &tls.Config{InsecureSkipVerify: g.skipVerify}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.DialContext(ctx, g.addr, opts...)
+	if err != nil {
+		return nil, 2, fmt.Errorf("failed to connect to gRPC server %q: %v", g.addr, err)
+	}
+	defer conn.Close()
+
+	client := hv1.NewHealthClient(conn)
+	resp, err := client.Check(ctx, &hv1.HealthCheckRequest{Service: g.service})
+	if err != nil {
+		return nil, 2, fmt.Errorf("gRPC health check of %q failed: %v", g.addr, err)
+	}
+
+	output := []byte(fmt.Sprintf("gRPC check %s: %s", g.addr, resp.Status))
+	if resp.Status != hv1.HealthCheckResponse_SERVING {
+		return output, 2, nil
+	}
+	return output, 0, nil
+}
@@ -0,0 +1,68 @@
+package consul
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testGRPCServer starts a gRPC server serving the standard health service and
+// returns its address along with a func to stop it.
+func testGRPCServer(t *testing.T) (*health.Server, string, int, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+
+	hs := health.NewServer()
+	srv := grpc.NewServer()
+	hv1.RegisterHealthServer(srv, hs)
+	go srv.Serve(l)
+
+	addr := l.Addr().(*net.TCPAddr)
+	return hs, addr.IP.String(), addr.Port, srv.Stop
+}
+
+func TestConsulGRPC_Exec_Codes(t *testing.T) {
+	t.Parallel()
+	hs, host, port, stop := testGRPCServer(t)
+	defer stop()
+
+	hs.SetServingStatus("healthy", hv1.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("unhealthy", hv1.HealthCheckResponse_NOT_SERVING)
+
+	cases := []struct {
+		service string
+		code    int
+		err     bool
+	}{
+		{"healthy", 0, false},
+		{"unhealthy", 2, false},
+		{"unknown", 2, true},
+	}
+
+	for _, c := range cases {
+		check := &structs.ServiceCheck{
+			Name:        "grpc",
+			Type:        structs.ServiceCheckGRPC,
+			GRPCService: c.service,
+		}
+		exec := newGRPCCheckExecutor(host, port, check)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, code, err := exec.Exec(ctx, "", nil)
+		cancel()
+		if code != c.code {
+			t.Errorf("service %q: expected code %d but found %d", c.service, c.code, code)
+		}
+		if (err != nil) != c.err {
+			t.Errorf("service %q: expected error=%t but found: %v", c.service, c.err, err)
+		}
+	}
+}
@@ -1373,6 +1373,81 @@ func TestConsul_DriverNetwork_Change(t *testing.T) {
 	syncAndAssertPort(net.PortMap["x"])
 }
 
+// tokenRecordingAgent wraps a MockAgent and records the Consul token used to
+// register and deregister each service and check.
+type tokenRecordingAgent struct {
+	*MockAgent
+	token  string
+	tokens map[string]string
+}
+
+func (a *tokenRecordingAgent) ServiceRegister(service *api.AgentServiceRegistration) error {
+	a.tokens[service.ID] = a.token
+	return a.MockAgent.ServiceRegister(service)
+}
+
+func (a *tokenRecordingAgent) CheckRegister(check *api.AgentCheckRegistration) error {
+	a.tokens[check.ID] = a.token
+	return a.MockAgent.CheckRegister(check)
+}
+
+func (a *tokenRecordingAgent) ServiceDeregister(serviceID string) error {
+	a.tokens["dereg-"+serviceID] = a.token
+	return a.MockAgent.ServiceDeregister(serviceID)
+}
+
+// TestConsul_TaskToken asserts that the services and checks of a task with a
+// Consul token are registered and deregistered using that token.
+func TestConsul_TaskToken(t *testing.T) {
+	ctx := setupFake()
+	tokens := make(map[string]string)
+	ctx.ServiceClient.SetTokenAgentFactory(func(token string) AgentAPI {
+		return &tokenRecordingAgent{MockAgent: ctx.FakeConsul, token: token, tokens: tokens}
+	})
+
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "testcheck",
+			Type:     "tcp",
+			Interval: time.Second,
+			Timeout:  time.Second,
+		},
+	}
+
+	ctx.ServiceClient.SetTaskToken("allocid", ctx.Task.Name, "task-token")
+	if err := ctx.ServiceClient.RegisterTask("allocid", ctx.Task, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error registering task: %v", err)
+	}
+	if err := ctx.syncOnce(); err != nil {
+		t.Fatalf("unexpected error syncing task: %v", err)
+	}
+
+	serviceID := makeTaskServiceID("allocid", ctx.Task.Name, ctx.Task.Services[0])
+	checkID := makeCheckID(serviceID, ctx.Task.Services[0].Checks[0])
+	if token := tokens[serviceID]; token != "task-token" {
+		t.Fatalf("expected service registered with task token but found %q", token)
+	}
+	if token := tokens[checkID]; token != "task-token" {
+		t.Fatalf("expected check registered with task token but found %q", token)
+	}
+
+	// Removing the task deregisters the service with the task's token and
+	// forgets it
+	ctx.ServiceClient.RemoveTask("allocid", ctx.Task)
+	if err := ctx.syncOnce(); err != nil {
+		t.Fatalf("unexpected error syncing task: %v", err)
+	}
+	if token := tokens["dereg-"+serviceID]; token != "task-token" {
+		t.Fatalf("expected service deregistered with task token but found %q", token)
+	}
+	if n := len(ctx.ServiceClient.tokens); n != 0 {
+		t.Fatalf("expected no tokens but found %d: %v", n, ctx.ServiceClient.tokens)
+	}
+	if token := ctx.ServiceClient.taskToken("allocid", ctx.Task.Name); token != "" {
+		t.Fatalf("expected task token to be cleared but found %q", token)
+	}
+}
+
 // TestIsNomadService asserts the isNomadService helper returns true for Nomad
 // task IDs and false for unknown IDs and Nomad agent IDs (see #2827).
 func TestIsNomadService(t *testing.T) {
@@ -1440,3 +1515,37 @@ func TestCreateCheckReg(t *testing.T) {
 		t.Fatalf("diff:\n%s\n", strings.Join(diff, "\n"))
 	}
 }
+
+func TestCreateCheckReg_GRPC(t *testing.T) {
+	check := &structs.ServiceCheck{
+		Name:        "name",
+		Type:        structs.ServiceCheckGRPC,
+		PortLabel:   "label",
+		Interval:    10 * time.Second,
+		Timeout:     2 * time.Second,
+		GRPCService: "foo.Bar",
+	}
+
+	serviceID := "testService"
+	checkID := check.Hash(serviceID)
+
+	expected := &api.AgentCheckRegistration{
+		ID:        checkID,
+		Name:      "name",
+		ServiceID: serviceID,
+		AgentServiceCheck: api.AgentServiceCheck{
+			Timeout:  "2s",
+			Interval: "10s",
+			TTL:      (check.Interval + ttlCheckBuffer).String(),
+		},
+	}
+
+	actual, err := createCheckReg(serviceID, checkID, check, "localhost", 41111)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if diff := pretty.Diff(actual, expected); len(diff) > 0 {
+		t.Fatalf("diff:\n%s\n", strings.Join(diff, "\n"))
+	}
+}
@@ -4,8 +4,11 @@ package agent
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net/http"
 	"os"
@@ -22,6 +25,7 @@ import (
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/driver/logging"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hpcloud/tail/watch"
 	"github.com/ugorji/go/codec"
@@ -864,6 +868,11 @@ func (s *HTTPServer) logs(follow, plain bool, offset int64,
 			return fmt.Errorf("failed to list entries: %v", err)
 		}
 
+		// Offsets are into the uncompressed logs
+		if err := uncompressedLogSizes(fs, logPath, entries); err != nil {
+			return err
+		}
+
 		// If we are not following logs, determine the max index for the logs we are
 		// interested in so we can stop there.
 		maxIndex := int64(math.MaxInt64)
@@ -882,6 +891,7 @@ func (s *HTTPServer) logs(follow, plain bool, offset int64,
 
 		var eofCancelCh chan error
 		exitAfter := false
+		compressed := strings.HasSuffix(logEntry.Name, logging.CompressedExt)
 		if !follow && idx > maxIndex {
 			// Exceeded what was there initially so return
 			return nil
@@ -890,12 +900,17 @@ func (s *HTTPServer) logs(follow, plain bool, offset int64,
 			eofCancelCh = make(chan error)
 			close(eofCancelCh)
 			exitAfter = true
-		} else {
+		} else if !compressed {
 			eofCancelCh = blockUntilNextLog(fs, &t, logPath, task, logType, idx+1)
 		}
 
 		p := filepath.Join(logPath, logEntry.Name)
-		err = s.stream(openOffset, p, fs, framer, eofCancelCh)
+		if compressed {
+			// Compressed logs are complete so there is no need to wait at EOF
+			err = s.streamCompressed(openOffset, p, fs, framer)
+		} else {
+			err = s.stream(openOffset, p, fs, framer, eofCancelCh)
+		}
 
 		if err != nil {
 			// Check if there was an error where the file does not exist. That means
@@ -922,6 +937,82 @@ func (s *HTTPServer) logs(follow, plain bool, offset int64,
 	}
 }
 
+// streamCompressed streams the uncompressed content of a gzip compressed log
+// file starting at the uncompressed offset. Compressed log files are no longer
+// written to, so streaming stops at EOF.
+func (s *HTTPServer) streamCompressed(offset int64, path string,
+	fs allocdir.AllocDirFS, framer *StreamFramer) error {
+
+	f, err := fs.ReadAt(path, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read compressed log: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil && err != io.EOF {
+		return err
+	}
+
+	data := make([]byte, streamFrameSize)
+	for {
+		n, readErr := r.Read(data)
+		offset += int64(n)
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+
+		if n != 0 {
+			if err := framer.Send(path, "", data[:n], offset); err != nil {
+				if strings.Contains(err.Error(), io.ErrClosedPipe.Error()) ||
+					strings.Contains(err.Error(), syscall.EPIPE.Error()) ||
+					strings.Contains(err.Error(), syscall.ECONNRESET.Error()) {
+					return syscall.EPIPE
+				}
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// uncompressedLogSizes replaces the size of compressed log file entries with
+// their uncompressed size, which gzip stores modulo 2^32 in its last four
+// bytes. Log files are much smaller than that.
+func uncompressedLogSizes(fs allocdir.AllocDirFS, logPath string, entries []*allocdir.AllocFileInfo) error {
+	for _, entry := range entries {
+		if entry.IsDir || !strings.HasSuffix(entry.Name, logging.CompressedExt) || entry.Size < 4 {
+			continue
+		}
+
+		r, err := fs.ReadAt(filepath.Join(logPath, entry.Name), entry.Size-4)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Purged since listing
+				continue
+			}
+			return fmt.Errorf("failed to read size of %q: %v", entry.Name, err)
+		}
+
+		var size [4]byte
+		_, err = io.ReadFull(r, size[:])
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read size of %q: %v", entry.Name, err)
+		}
+		entry.Size = int64(binary.LittleEndian.Uint32(size[:]))
+	}
+	return nil
+}
+
 // blockUntilNextLog returns a channel that will have data sent when the next
 // log index or anything greater is created.
 func blockUntilNextLog(fs allocdir.AllocDirFS, t *tomb.Tomb, logPath, task, logType string, nextIndex int64) chan error {
@@ -994,28 +1085,33 @@ func (a indexTupleArray) Less(i, j int) bool { return a[i].idx < a[j].idx }
 func (a indexTupleArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 
 // logIndexes takes a set of entries and returns a indexTupleArray of
-// the desired log file entries. If the indexes could not be determined, an
-// error is returned.
+// the desired log file entries. Rotated log files may be compressed and while
+// a file is being compressed both versions exist, in which case the
+// uncompressed file is used. If the indexes could not be determined, an error
+// is returned.
 func logIndexes(entries []*allocdir.AllocFileInfo, task, logType string) (indexTupleArray, error) {
 	var indexes []indexTuple
-	prefix := fmt.Sprintf("%s.%s.", task, logType)
+	seen := make(map[int]int)
+	base := fmt.Sprintf("%s.%s", task, logType)
 	for _, entry := range entries {
 		if entry.IsDir {
 			continue
 		}
 
-		// If nothing was trimmed, then it is not a match
-		idxStr := strings.TrimPrefix(entry.Name, prefix)
-		if idxStr == entry.Name {
+		// If the name doesn't parse, then it is not a match
+		entryBase, idx, compressed, ok := logging.ParseRotatedFile(entry.Name)
+		if !ok || entryBase != base {
 			continue
 		}
 
-		// Convert to an int
-		idx, err := strconv.Atoi(idxStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert %q to a log index: %v", idxStr, err)
+		if i, ok := seen[idx]; ok {
+			if !compressed {
+				indexes[i].entry = entry
+			}
+			continue
 		}
 
+		seen[idx] = len(indexes)
 		indexes = append(indexes, indexTuple{idx: int64(idx), entry: entry})
 	}
 
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -1054,6 +1055,97 @@ func TestHTTP_Logs_NoFollow(t *testing.T) {
 	})
 }
 
+func TestHTTP_Logs_NoFollow_Compressed(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Get a temp alloc dir and create the log dir
+		ad := tempAllocDir(t)
+		defer os.RemoveAll(ad.AllocDir)
+
+		logDir := filepath.Join(ad.SharedDir, allocdir.LogDirName)
+		if err := os.MkdirAll(logDir, 0777); err != nil {
+			t.Fatalf("Failed to make log dir: %v", err)
+		}
+
+		// Create compressed rotated log files followed by the current one
+		task := "foo"
+		logType := "stdout"
+		expected := []byte("012345")
+		for i := 0; i < 3; i++ {
+			logFile := fmt.Sprintf("%s.%s.%d", task, logType, i)
+			data := expected[2*i : 2*i+2]
+			if i < 2 {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				gz.Write(data)
+				gz.Close()
+				logFile += ".gz"
+				data = buf.Bytes()
+			}
+			logFilePath := filepath.Join(logDir, logFile)
+			if err := ioutil.WriteFile(logFilePath, data, 777); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+		}
+
+		// Create a decoder
+		r, w := io.Pipe()
+		wrappedW := &WriteCloseChecker{WriteCloser: w}
+		defer r.Close()
+		defer w.Close()
+		dec := codec.NewDecoder(r, structs.JsonHandle)
+
+		var received []byte
+
+		// Start the reader
+		resultCh := make(chan struct{})
+		go func() {
+			for {
+				var frame StreamFrame
+				if err := dec.Decode(&frame); err != nil {
+					if err == io.EOF {
+						t.Logf("EOF")
+						return
+					}
+
+					t.Errorf("failed to decode: %v", err)
+					return
+				}
+
+				if frame.IsHeartbeat() {
+					continue
+				}
+
+				received = append(received, frame.Data...)
+				if reflect.DeepEqual(received, expected[1:]) {
+					close(resultCh)
+					return
+				}
+			}
+		}()
+
+		// Start streaming logs at an offset into the first compressed file
+		go func() {
+			if err := s.Server.logs(false, false, 1, OriginStart, task, logType, ad, wrappedW); err != nil {
+				t.Errorf("logs() failed: %v", err)
+			}
+		}()
+
+		select {
+		case <-resultCh:
+		case <-time.After(10 * time.Duration(testutil.TestMultiplier()) * streamBatchWindow):
+			t.Fatalf("did not receive data: got %q", string(received))
+		}
+
+		testutil.WaitForResult(func() (bool, error) {
+			return wrappedW.Closed, nil
+		}, func(err error) {
+			t.Fatalf("connection not closed")
+		})
+
+	})
+}
+
 func TestHTTP_Logs_Follow(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -1468,3 +1560,93 @@ func TestLogs_findClosest(t *testing.T) {
 		}
 	}
 }
+
+func TestLogs_logIndexes_Compressed(t *testing.T) {
+	entries := []*allocdir.AllocFileInfo{
+		{
+			Name: "foo.stdout.0.gz",
+			Size: 30,
+		},
+		{
+			Name: "foo.stdout.1.gz",
+			Size: 30,
+		},
+		{
+			// Being compressed
+			Name: "foo.stdout.1",
+			Size: 100,
+		},
+		{
+			Name: "foo.stdout.2",
+			Size: 100,
+		},
+		{
+			Name: ".foo.stdout.2.gz.tmp",
+			Size: 10,
+		},
+		{
+			Name: "foo.stderr.0.gz",
+			Size: 30,
+		},
+	}
+
+	indexes, err := logIndexes(entries, "foo", "stdout")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := map[int64]string{
+		0: "foo.stdout.0.gz",
+		1: "foo.stdout.1",
+		2: "foo.stdout.2",
+	}
+	if len(indexes) != len(expected) {
+		t.Fatalf("Got %d indexes; want %d", len(indexes), len(expected))
+	}
+	for _, index := range indexes {
+		if index.entry.Name != expected[index.idx] {
+			t.Fatalf("index %d: Got file %q; want %q", index.idx, index.entry.Name, expected[index.idx])
+		}
+	}
+}
+
+func TestLogs_uncompressedLogSizes(t *testing.T) {
+	t.Parallel()
+	ad := tempAllocDir(t)
+	defer os.RemoveAll(ad.AllocDir)
+
+	logDir := filepath.Join(ad.SharedDir, allocdir.LogDirName)
+	if err := os.MkdirAll(logDir, 0777); err != nil {
+		t.Fatalf("Failed to make log dir: %v", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(bytes.Repeat([]byte("a"), 1000))
+	gz.Close()
+	if err := ioutil.WriteFile(filepath.Join(logDir, "foo.stdout.0.gz"), buf.Bytes(), 0666); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(logDir, "foo.stdout.1"), []byte("abc"), 0666); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	logPath := filepath.Join(allocdir.SharedAllocName, allocdir.LogDirName)
+	entries, err := ad.List(logPath)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := uncompressedLogSizes(ad, logPath, entries); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := map[string]int64{
+		"foo.stdout.0.gz": 1000,
+		"foo.stdout.1":    3,
+	}
+	for _, entry := range entries {
+		if entry.Size != expected[entry.Name] {
+			t.Fatalf("%q: Got size %d; want %d", entry.Name, entry.Size, expected[entry.Name])
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -55,6 +56,15 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/periodic/force"):
 		jobName := strings.TrimSuffix(path, "/periodic/force")
 		return s.periodicForceRequest(resp, req, jobName)
+	case strings.HasSuffix(path, "/periodic/pause"):
+		jobName := strings.TrimSuffix(path, "/periodic/pause")
+		return s.periodicPauseRequest(resp, req, jobName, true)
+	case strings.HasSuffix(path, "/periodic/resume"):
+		jobName := strings.TrimSuffix(path, "/periodic/resume")
+		return s.periodicPauseRequest(resp, req, jobName, false)
+	case strings.HasSuffix(path, "/periodic"):
+		jobName := strings.TrimSuffix(path, "/periodic")
+		return s.periodicLaunchRequest(resp, req, jobName)
 	case strings.HasSuffix(path, "/plan"):
 		jobName := strings.TrimSuffix(path, "/plan")
 		return s.jobPlan(resp, req, jobName)
@@ -76,9 +86,18 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/deployment"):
 		jobName := strings.TrimSuffix(path, "/deployment")
 		return s.jobLatestDeployment(resp, req, jobName)
+	case strings.HasSuffix(path, "/capacity"):
+		jobName := strings.TrimSuffix(path, "/capacity")
+		return s.jobCapacity(resp, req, jobName)
+	case strings.HasSuffix(path, "/explain"):
+		jobName := strings.TrimSuffix(path, "/explain")
+		return s.jobExplain(resp, req, jobName)
 	case strings.HasSuffix(path, "/stable"):
 		jobName := strings.TrimSuffix(path, "/stable")
 		return s.jobStable(resp, req, jobName)
+	case strings.HasSuffix(path, "/submission"):
+		jobName := strings.TrimSuffix(path, "/submission")
+		return s.jobSubmission(resp, req, jobName)
 	default:
 		return s.jobCRUD(resp, req, path)
 	}
@@ -140,6 +159,44 @@ func (s *HTTPServer) jobPlan(resp http.ResponseWriter, req *http.Request,
 	return out, nil
 }
 
+func (s *HTTPServer) jobCapacity(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args api.JobCapacityRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if args.Job == nil {
+		return nil, CodedError(400, "Job must be specified")
+	}
+	if args.Job.ID == nil {
+		return nil, CodedError(400, "Job must have a valid ID")
+	}
+	if jobName != "" && *args.Job.ID != jobName {
+		return nil, CodedError(400, "Job ID does not match")
+	}
+
+	sJob := ApiJobToStructJob(args.Job)
+	capacityReq := structs.JobCapacityRequest{
+		Job:       sJob,
+		TaskGroup: args.TaskGroup,
+		Limit:     args.Limit,
+		WriteRequest: structs.WriteRequest{
+			Region: args.WriteRequest.Region,
+		},
+	}
+	s.parseWriteRequest(req, &capacityReq.WriteRequest)
+	var out structs.JobCapacityResponse
+	if err := s.agent.RPC("Job.Capacity", &capacityReq, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
 func (s *HTTPServer) ValidateJobRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	// Ensure request method is POST or PUT
 	if !(req.Method == "POST" || req.Method == "PUT") {
@@ -190,6 +247,51 @@ func (s *HTTPServer) periodicForceRequest(resp http.ResponseWriter, req *http.Re
 	return out, nil
 }
 
+func (s *HTTPServer) periodicPauseRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string, paused bool) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.PeriodicPauseRequest{
+		JobID:  jobName,
+		Paused: paused,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("Periodic.Pause", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) periodicLaunchRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.PeriodicLaunchSpecificRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.SinglePeriodicLaunchResponse
+	if err := s.agent.RPC("Periodic.GetLaunch", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Launch == nil {
+		return nil, CodedError(404, "periodic launch not found")
+	}
+	return out.Launch, nil
+}
+
 func (s *HTTPServer) jobAllocations(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 	if req.Method != "GET" {
@@ -286,6 +388,65 @@ func (s *HTTPServer) jobLatestDeployment(resp http.ResponseWriter, req *http.Req
 	return out.Deployment, nil
 }
 
+func (s *HTTPServer) jobSubmission(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	versionStr := req.URL.Query().Get("version")
+	if versionStr == "" {
+		return nil, CodedError(400, "version must be specified")
+	}
+	version, err := strconv.ParseUint(versionStr, 10, 64)
+	if err != nil {
+		return nil, CodedError(400, fmt.Sprintf("Failed to parse value of %q (%v) as a uint64: %v", "version", versionStr, err))
+	}
+
+	args := structs.JobSubmissionRequest{
+		JobID:   jobName,
+		Version: version,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobSubmissionResponse
+	if err := s.agent.RPC("Job.GetJobSubmission", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Submission == nil {
+		return nil, CodedError(404, "job submission not found")
+	}
+	return out.Submission, nil
+}
+
+func (s *HTTPServer) jobExplain(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	args := structs.JobSpecificRequest{
+		JobID: jobName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.JobExplainResponse
+	if err := s.agent.RPC("Job.Explain", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Explanation == nil {
+		return nil, CodedError(404, "job not found")
+	}
+	return out.Explanation, nil
+}
+
 func (s *HTTPServer) jobCRUD(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 	switch req.Method {
@@ -354,6 +515,7 @@ func (s *HTTPServer) jobUpdate(resp http.ResponseWriter, req *http.Request,
 
 	regReq := structs.JobRegisterRequest{
 		Job:            sJob,
+		Submission:     ApiJobSubmissionToStructs(args.Submission),
 		EnforceIndex:   args.EnforceIndex,
 		JobModifyIndex: args.JobModifyIndex,
 		PolicyOverride: args.PolicyOverride,
@@ -536,23 +698,42 @@ func (s *HTTPServer) jobDispatchRequest(resp http.ResponseWriter, req *http.Requ
 	return out, nil
 }
 
+// ApiJobSubmissionToStructs converts the source of a job submitted through
+// the API to its structs representation.
+func ApiJobSubmissionToStructs(sub *api.JobSubmission) *structs.JobSubmission {
+	if sub == nil {
+		return nil
+	}
+
+	return &structs.JobSubmission{
+		Source:        sub.Source,
+		Format:        sub.Format,
+		VariableFlags: helper.CopyMapStringString(sub.VariableFlags),
+		Variables:     sub.Variables,
+	}
+}
+
 func ApiJobToStructJob(job *api.Job) *structs.Job {
 	job.Canonicalize()
 
 	j := &structs.Job{
-		Stop:        *job.Stop,
-		Region:      *job.Region,
-		Namespace:   *job.Namespace,
-		ID:          *job.ID,
-		ParentID:    *job.ParentID,
-		Name:        *job.Name,
-		Type:        *job.Type,
-		Priority:    *job.Priority,
-		AllAtOnce:   *job.AllAtOnce,
-		Datacenters: job.Datacenters,
-		Payload:     job.Payload,
-		Meta:        job.Meta,
-		VaultToken:  *job.VaultToken,
+		Stop:                     *job.Stop,
+		Region:                   *job.Region,
+		Namespace:                *job.Namespace,
+		ID:                       *job.ID,
+		ParentID:                 *job.ParentID,
+		Name:                     *job.Name,
+		Type:                     *job.Type,
+		Priority:                 *job.Priority,
+		AllAtOnce:                *job.AllAtOnce,
+		Datacenters:              job.Datacenters,
+		NodePool:                 *job.NodePool,
+		SchedulerAlgorithm:       structs.SchedulerAlgorithm(*job.SchedulerAlgorithm),
+		Payload:                  job.Payload,
+		DispatchIdempotencyToken: *job.DispatchIdempotencyToken,
+		Meta:                     job.Meta,
+		VaultToken:               *job.VaultToken,
+		ConsulToken:              *job.ConsulToken,
 	}
 
 	if l := len(job.Constraints); l != 0 {
@@ -579,9 +760,13 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 	if job.Periodic != nil {
 		j.Periodic = &structs.PeriodicConfig{
 			Enabled:         *job.Periodic.Enabled,
+			Specs:           job.Periodic.Specs,
 			SpecType:        *job.Periodic.SpecType,
 			ProhibitOverlap: *job.Periodic.ProhibitOverlap,
 			TimeZone:        *job.Periodic.TimeZone,
+			CatchUp:         *job.Periodic.CatchUp,
+			CatchUpWindow:   *job.Periodic.CatchUpWindow,
+			Jitter:          *job.Periodic.Jitter,
 		}
 
 		if job.Periodic.Spec != nil {
@@ -594,6 +779,7 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 			Payload:      job.ParameterizedJob.Payload,
 			MetaRequired: job.ParameterizedJob.MetaRequired,
 			MetaOptional: job.ParameterizedJob.MetaOptional,
+			MaxRunning:   job.ParameterizedJob.MaxRunning,
 		}
 	}
 
@@ -636,6 +822,22 @@ func ApiTgToStructsTG(taskGroup *api.TaskGroup, tg *structs.TaskGroup) {
 		Migrate: *taskGroup.EphemeralDisk.Migrate,
 	}
 
+	tg.Networks = ApiNetworkResourcesToStructs(taskGroup.Networks)
+
+	if l := len(taskGroup.DependsOn); l != 0 {
+		tg.DependsOn = make([]*structs.TaskGroupDependency, l)
+		for i, d := range taskGroup.DependsOn {
+			tg.DependsOn[i] = &structs.TaskGroupDependency{
+				Group:     *d.Group,
+				Condition: *d.Condition,
+			}
+		}
+	}
+
+	if taskGroup.MaxClientDisconnect != nil {
+		tg.MaxClientDisconnect = helper.TimeToPtr(*taskGroup.MaxClientDisconnect)
+	}
+
 	if taskGroup.Update != nil {
 		tg.Update = &structs.UpdateStrategy{
 			Stagger:         *taskGroup.Update.Stagger,
@@ -705,6 +907,8 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 						TLSSkipVerify: check.TLSSkipVerify,
 						Header:        check.Header,
 						Method:        check.Method,
+						GRPCService:   check.GRPCService,
+						GRPCUseTLS:    check.GRPCUseTLS,
 					}
 					if check.CheckRestart != nil {
 						structsTask.Services[i].Checks[j].CheckRestart = &structs.CheckRestart{
@@ -719,47 +923,36 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 	}
 
 	structsTask.Resources = &structs.Resources{
-		CPU:      *apiTask.Resources.CPU,
-		MemoryMB: *apiTask.Resources.MemoryMB,
-		IOPS:     *apiTask.Resources.IOPS,
-	}
-
-	if l := len(apiTask.Resources.Networks); l != 0 {
-		structsTask.Resources.Networks = make([]*structs.NetworkResource, l)
-		for i, nw := range apiTask.Resources.Networks {
-			structsTask.Resources.Networks[i] = &structs.NetworkResource{
-				CIDR:  nw.CIDR,
-				IP:    nw.IP,
-				MBits: *nw.MBits,
-			}
+		CPU:         *apiTask.Resources.CPU,
+		MemoryMB:    *apiTask.Resources.MemoryMB,
+		MemoryMaxMB: *apiTask.Resources.MemoryMaxMB,
+		IOPS:        *apiTask.Resources.IOPS,
+		Cores:       *apiTask.Resources.Cores,
+	}
 
-			if l := len(nw.DynamicPorts); l != 0 {
-				structsTask.Resources.Networks[i].DynamicPorts = make([]structs.Port, l)
-				for j, dp := range nw.DynamicPorts {
-					structsTask.Resources.Networks[i].DynamicPorts[j] = structs.Port{
-						Label: dp.Label,
-						Value: dp.Value,
-					}
-				}
-			}
+	structsTask.Resources.Networks = ApiNetworkResourcesToStructs(apiTask.Resources.Networks)
 
-			if l := len(nw.ReservedPorts); l != 0 {
-				structsTask.Resources.Networks[i].ReservedPorts = make([]structs.Port, l)
-				for j, rp := range nw.ReservedPorts {
-					structsTask.Resources.Networks[i].ReservedPorts[j] = structs.Port{
-						Label: rp.Label,
-						Value: rp.Value,
-					}
-				}
+	structsTask.LogConfig = &structs.LogConfig{
+		MaxFiles:         *apiTask.LogConfig.MaxFiles,
+		MaxFileSizeMB:    *apiTask.LogConfig.MaxFileSizeMB,
+		RotationInterval: *apiTask.LogConfig.RotationInterval,
+		Compress:         *apiTask.LogConfig.Compress,
+		MaxTotalSizeMB:   *apiTask.LogConfig.MaxTotalSizeMB,
+	}
+
+	if l := len(apiTask.LogShippers); l != 0 {
+		structsTask.LogShippers = make([]*structs.LogShipper, l)
+		for i, ls := range apiTask.LogShippers {
+			structsTask.LogShippers[i] = &structs.LogShipper{
+				Name:       ls.Name,
+				Type:       ls.Type,
+				Address:    *ls.Address,
+				Protocol:   *ls.Protocol,
+				BufferSize: *ls.BufferSize,
 			}
 		}
 	}
 
-	structsTask.LogConfig = &structs.LogConfig{
-		MaxFiles:      *apiTask.LogConfig.MaxFiles,
-		MaxFileSizeMB: *apiTask.LogConfig.MaxFileSizeMB,
-	}
-
 	if l := len(apiTask.Artifacts); l != 0 {
 		structsTask.Artifacts = make([]*structs.TaskArtifact, l)
 		for k, ta := range apiTask.Artifacts {
@@ -775,6 +968,9 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 	if apiTask.Vault != nil {
 		structsTask.Vault = &structs.Vault{
 			Policies:     apiTask.Vault.Policies,
+			Role:         *apiTask.Vault.Role,
+			EntityAlias:  *apiTask.Vault.EntityAlias,
+			TTL:          *apiTask.Vault.TTL,
 			Env:          *apiTask.Vault.Env,
 			ChangeMode:   *apiTask.Vault.ChangeMode,
 			ChangeSignal: *apiTask.Vault.ChangeSignal,
@@ -812,3 +1008,42 @@ func ApiConstraintToStructs(c1 *api.Constraint, c2 *structs.Constraint) {
 	c2.RTarget = c1.RTarget
 	c2.Operand = c1.Operand
 }
+
+// ApiNetworkResourcesToStructs converts the API network resources to their
+// structs equivalent.
+func ApiNetworkResourcesToStructs(in []*api.NetworkResource) []*structs.NetworkResource {
+	if len(in) == 0 {
+		return nil
+	}
+
+	out := make([]*structs.NetworkResource, len(in))
+	for i, nw := range in {
+		out[i] = &structs.NetworkResource{
+			CIDR:  nw.CIDR,
+			IP:    nw.IP,
+			MBits: *nw.MBits,
+			Mode:  nw.Mode,
+		}
+
+		if l := len(nw.DynamicPorts); l != 0 {
+			out[i].DynamicPorts = make([]structs.Port, l)
+			for j, dp := range nw.DynamicPorts {
+				out[i].DynamicPorts[j] = structs.Port{
+					Label: dp.Label,
+					Value: dp.Value,
+				}
+			}
+		}
+
+		if l := len(nw.ReservedPorts); l != 0 {
+			out[i].ReservedPorts = make([]structs.Port, l)
+			for j, rp := range nw.ReservedPorts {
+				out[i].ReservedPorts[j] = structs.Port{
+					Label: rp.Label,
+					Value: rp.Value,
+				}
+			}
+		}
+	}
+	return out
+}
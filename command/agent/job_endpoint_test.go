@@ -726,6 +726,91 @@ func TestHTTP_JobDeployments(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobCapacity(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the job
+		job := api.MockJob()
+		args := api.JobCapacityRequest{
+			Job:   job,
+			Limit: 1,
+			WriteRequest: api.WriteRequest{
+				Region:    "global",
+				Namespace: api.DefaultNamespace,
+			},
+		}
+		buf := encodeReq(args)
+
+		// Make the HTTP request
+		req, err := http.NewRequest("PUT", "/v1/job/"+*job.ID+"/capacity", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check the response
+		capacity := obj.(structs.JobCapacityResponse)
+		if capacity.Capacity == nil || capacity.Capacity.TaskGroup != *job.TaskGroups[0].Name {
+			t.Fatalf("bad: %#v", capacity)
+		}
+
+		// Check the job ID is validated
+		req, err = http.NewRequest("PUT", "/v1/job/foo/capacity", encodeReq(args))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.JobSpecificRequest(httptest.NewRecorder(), req); err == nil || !strings.Contains(err.Error(), "does not match") {
+			t.Fatalf("expected job ID error, got: %v", err)
+		}
+	})
+}
+
+func TestHTTP_JobExplain(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the job
+		j := mock.Job()
+		args := structs.JobRegisterRequest{
+			Job: j,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		assert.Nil(s.Agent.RPC("Job.Register", &args, &resp), "JobRegister")
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/job/"+j.ID+"/explain", nil)
+		assert.Nil(err, "HTTP")
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		assert.Nil(err, "JobSpecificRequest")
+
+		// Check the response
+		exp := obj.(*structs.JobExplanation)
+		assert.Equal(j.ID, exp.JobID, "job id")
+		assert.Equal(resp.EvalID, exp.EvalID, "eval id")
+		assert.NotZero(respW.HeaderMap.Get("X-Nomad-Index"), "missing index")
+
+		// A missing job is not found
+		req, err = http.NewRequest("GET", "/v1/job/foo/explain", nil)
+		assert.Nil(err, "HTTP")
+		_, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "job not found")
+	})
+}
+
 func TestHTTP_JobDeployment(t *testing.T) {
 	assert := assert.New(t)
 	t.Parallel()
@@ -846,6 +931,78 @@ func TestHTTP_JobVersions(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobSubmission(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create the job with its source
+		job := api.MockJob()
+		args := api.JobRegisterRequest{
+			Job: job,
+			Submission: &api.JobSubmission{
+				Source:        `job "example" {}`,
+				Format:        "hcl2",
+				VariableFlags: map[string]string{"image": "redis"},
+			},
+			WriteRequest: api.WriteRequest{
+				Region:    "global",
+				Namespace: api.DefaultNamespace,
+			},
+		}
+		buf := encodeReq(args)
+
+		req, err := http.NewRequest("PUT", "/v1/job/"+*job.ID, buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.JobSpecificRequest(httptest.NewRecorder(), req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Make the HTTP request
+		req, err = http.NewRequest("GET", "/v1/job/"+*job.ID+"/submission?version=0", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check the response
+		sub := obj.(*structs.JobSubmission)
+		if sub.Source != `job "example" {}` || sub.Format != "hcl2" || sub.VariableFlags["image"] != "redis" {
+			t.Fatalf("bad: %#v", sub)
+		}
+
+		// Check for the index
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// A version without a source is not found
+		req, err = http.NewRequest("GET", "/v1/job/"+*job.ID+"/submission?version=1", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("expected not found error, got: %v", err)
+		}
+
+		// The version is required
+		req, err = http.NewRequest("GET", "/v1/job/"+*job.ID+"/submission", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		if err == nil || !strings.Contains(err.Error(), "version must be specified") {
+			t.Fatalf("expected version error, got: %v", err)
+		}
+	})
+}
 func TestHTTP_PeriodicForce(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -889,6 +1046,83 @@ func TestHTTP_PeriodicForce(t *testing.T) {
 	})
 }
 
+func TestHTTP_PeriodicPause(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Create and register a periodic job.
+		job := mock.PeriodicJob()
+		args := structs.JobRegisterRequest{
+			Job: job,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobRegisterResponse
+		if err := s.Agent.RPC("Job.Register", &args, &resp); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Make the HTTP request to pause the job
+		req, err := http.NewRequest("POST", "/v1/job/"+job.ID+"/periodic/pause", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		if _, err := s.Server.JobSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check for the index
+		if respW.HeaderMap.Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// Lookup the launch
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"/periodic", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		launch := obj.(*structs.PeriodicLaunch)
+		if launch.ID != job.ID || !launch.Paused {
+			t.Fatalf("bad: %#v", launch)
+		}
+
+		// Make the HTTP request to resume the job
+		req, err = http.NewRequest("PUT", "/v1/job/"+job.ID+"/periodic/resume", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.JobSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Check the launch was resumed
+		launchArgs := structs.PeriodicLaunchSpecificRequest{
+			JobID: job.ID,
+			QueryOptions: structs.QueryOptions{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var launchResp structs.SinglePeriodicLaunchResponse
+		if err := s.Agent.RPC("Periodic.GetLaunch", &launchArgs, &launchResp); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if launchResp.Launch == nil || launchResp.Launch.Paused {
+			t.Fatalf("bad: %#v", launchResp.Launch)
+		}
+	})
+}
+
 func TestHTTP_JobPlan(t *testing.T) {
 	t.Parallel()
 	httpTest(t, nil, func(s *TestAgent) {
@@ -1103,16 +1337,19 @@ func TestHTTP_JobStable(t *testing.T) {
 
 func TestJobs_ApiJobToStructsJob(t *testing.T) {
 	apiJob := &api.Job{
-		Stop:        helper.BoolToPtr(true),
-		Region:      helper.StringToPtr("global"),
-		Namespace:   helper.StringToPtr("foo"),
-		ID:          helper.StringToPtr("foo"),
-		ParentID:    helper.StringToPtr("lol"),
-		Name:        helper.StringToPtr("name"),
-		Type:        helper.StringToPtr("service"),
-		Priority:    helper.IntToPtr(50),
-		AllAtOnce:   helper.BoolToPtr(true),
-		Datacenters: []string{"dc1", "dc2"},
+		Stop:                     helper.BoolToPtr(true),
+		Region:                   helper.StringToPtr("global"),
+		Namespace:                helper.StringToPtr("foo"),
+		ID:                       helper.StringToPtr("foo"),
+		ParentID:                 helper.StringToPtr("lol"),
+		Name:                     helper.StringToPtr("name"),
+		Type:                     helper.StringToPtr("service"),
+		Priority:                 helper.IntToPtr(50),
+		AllAtOnce:                helper.BoolToPtr(true),
+		Datacenters:              []string{"dc1", "dc2"},
+		NodePool:                 helper.StringToPtr("gpu"),
+		SchedulerAlgorithm:       helper.StringToPtr("spread"),
+		DispatchIdempotencyToken: helper.StringToPtr("token"),
 		Constraints: []*api.Constraint{
 			{
 				LTarget: "a",
@@ -1132,9 +1369,13 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 		Periodic: &api.PeriodicConfig{
 			Enabled:         helper.BoolToPtr(true),
 			Spec:            helper.StringToPtr("spec"),
+			Specs:           []string{"spec2"},
 			SpecType:        helper.StringToPtr("cron"),
 			ProhibitOverlap: helper.BoolToPtr(true),
 			TimeZone:        helper.StringToPtr("test zone"),
+			CatchUp:         helper.StringToPtr("all"),
+			CatchUpWindow:   helper.TimeToPtr(time.Hour),
+			Jitter:          helper.TimeToPtr(time.Minute),
 		},
 		ParameterizedJob: &api.ParameterizedJobConfig{
 			Payload:      "payload",
@@ -1167,6 +1408,24 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 					Sticky:  helper.BoolToPtr(true),
 					Migrate: helper.BoolToPtr(true),
 				},
+				Networks: []*api.NetworkResource{
+					{
+						Mode:  "bridge",
+						MBits: helper.IntToPtr(10),
+						DynamicPorts: []api.Port{
+							{
+								Label: "http",
+							},
+						},
+					},
+				},
+				DependsOn: []*api.TaskGroupDependency{
+					{
+						Group:     helper.StringToPtr("db"),
+						Condition: helper.StringToPtr("healthy"),
+					},
+				},
+				MaxClientDisconnect: helper.TimeToPtr(5 * time.Minute),
 				Update: &api.UpdateStrategy{
 					HealthCheck:     helper.StringToPtr(structs.UpdateStrategyHealthCheck_Checks),
 					MinHealthyTime:  helper.TimeToPtr(2 * time.Minute),
@@ -1226,8 +1485,10 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 							},
 						},
 						Resources: &api.Resources{
-							CPU:      helper.IntToPtr(100),
-							MemoryMB: helper.IntToPtr(10),
+							CPU:         helper.IntToPtr(100),
+							MemoryMB:    helper.IntToPtr(10),
+							MemoryMaxMB: helper.IntToPtr(20),
+							Cores:       helper.IntToPtr(2),
 							Networks: []*api.NetworkResource{
 								{
 									IP:    "10.10.11.1",
@@ -1252,8 +1513,20 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 						},
 						KillTimeout: helper.TimeToPtr(10 * time.Second),
 						LogConfig: &api.LogConfig{
-							MaxFiles:      helper.IntToPtr(10),
-							MaxFileSizeMB: helper.IntToPtr(100),
+							MaxFiles:         helper.IntToPtr(10),
+							MaxFileSizeMB:    helper.IntToPtr(100),
+							RotationInterval: helper.TimeToPtr(time.Hour),
+							Compress:         helper.BoolToPtr(true),
+							MaxTotalSizeMB:   helper.IntToPtr(500),
+						},
+						LogShippers: []*api.LogShipper{
+							{
+								Name:       "audit",
+								Type:       "syslog",
+								Address:    helper.StringToPtr("10.0.0.1:514"),
+								Protocol:   helper.StringToPtr("udp"),
+								BufferSize: helper.IntToPtr(512),
+							},
 						},
 						Artifacts: []*api.TaskArtifact{
 							{
@@ -1267,6 +1540,9 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 						},
 						Vault: &api.Vault{
 							Policies:     []string{"a", "b", "c"},
+							Role:         helper.StringToPtr("role"),
+							EntityAlias:  helper.StringToPtr("alias"),
+							TTL:          helper.TimeToPtr(time.Hour),
 							Env:          helper.BoolToPtr(true),
 							ChangeMode:   helper.StringToPtr("c"),
 							ChangeSignal: helper.StringToPtr("sighup"),
@@ -1294,6 +1570,7 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 			},
 		},
 		VaultToken:        helper.StringToPtr("token"),
+		ConsulToken:       helper.StringToPtr("consul"),
 		Status:            helper.StringToPtr("status"),
 		StatusDescription: helper.StringToPtr("status_desc"),
 		Version:           helper.Uint64ToPtr(10),
@@ -1303,16 +1580,19 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 	}
 
 	expected := &structs.Job{
-		Stop:        true,
-		Region:      "global",
-		Namespace:   "foo",
-		ID:          "foo",
-		ParentID:    "lol",
-		Name:        "name",
-		Type:        "service",
-		Priority:    50,
-		AllAtOnce:   true,
-		Datacenters: []string{"dc1", "dc2"},
+		Stop:                     true,
+		Region:                   "global",
+		Namespace:                "foo",
+		ID:                       "foo",
+		ParentID:                 "lol",
+		Name:                     "name",
+		Type:                     "service",
+		Priority:                 50,
+		AllAtOnce:                true,
+		Datacenters:              []string{"dc1", "dc2"},
+		NodePool:                 "gpu",
+		SchedulerAlgorithm:       structs.SchedulerAlgorithmSpread,
+		DispatchIdempotencyToken: "token",
 		Constraints: []*structs.Constraint{
 			{
 				LTarget: "a",
@@ -1327,9 +1607,13 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 		Periodic: &structs.PeriodicConfig{
 			Enabled:         true,
 			Spec:            "spec",
+			Specs:           []string{"spec2"},
 			SpecType:        "cron",
 			ProhibitOverlap: true,
 			TimeZone:        "test zone",
+			CatchUp:         "all",
+			CatchUpWindow:   time.Hour,
+			Jitter:          time.Minute,
 		},
 		ParameterizedJob: &structs.ParameterizedJobConfig{
 			Payload:      "payload",
@@ -1362,6 +1646,24 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 					Sticky:  true,
 					Migrate: true,
 				},
+				Networks: []*structs.NetworkResource{
+					{
+						Mode:  "bridge",
+						MBits: 10,
+						DynamicPorts: []structs.Port{
+							{
+								Label: "http",
+							},
+						},
+					},
+				},
+				DependsOn: []*structs.TaskGroupDependency{
+					{
+						Group:     "db",
+						Condition: "healthy",
+					},
+				},
+				MaxClientDisconnect: helper.TimeToPtr(5 * time.Minute),
 				Update: &structs.UpdateStrategy{
 					Stagger:         1 * time.Second,
 					MaxParallel:     5,
@@ -1421,8 +1723,10 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 							},
 						},
 						Resources: &structs.Resources{
-							CPU:      100,
-							MemoryMB: 10,
+							CPU:         100,
+							MemoryMB:    10,
+							MemoryMaxMB: 20,
+							Cores:       2,
 							Networks: []*structs.NetworkResource{
 								{
 									IP:    "10.10.11.1",
@@ -1447,8 +1751,20 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 						},
 						KillTimeout: 10 * time.Second,
 						LogConfig: &structs.LogConfig{
-							MaxFiles:      10,
-							MaxFileSizeMB: 100,
+							MaxFiles:         10,
+							MaxFileSizeMB:    100,
+							RotationInterval: time.Hour,
+							Compress:         true,
+							MaxTotalSizeMB:   500,
+						},
+						LogShippers: []*structs.LogShipper{
+							{
+								Name:       "audit",
+								Type:       "syslog",
+								Address:    "10.0.0.1:514",
+								Protocol:   "udp",
+								BufferSize: 512,
+							},
 						},
 						Artifacts: []*structs.TaskArtifact{
 							{
@@ -1462,6 +1778,9 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 						},
 						Vault: &structs.Vault{
 							Policies:     []string{"a", "b", "c"},
+							Role:         "role",
+							EntityAlias:  "alias",
+							TTL:          time.Hour,
 							Env:          true,
 							ChangeMode:   "c",
 							ChangeSignal: "sighup",
@@ -1489,7 +1808,8 @@ func TestJobs_ApiJobToStructsJob(t *testing.T) {
 			},
 		},
 
-		VaultToken: "token",
+		VaultToken:  "token",
+		ConsulToken: "consul",
 	}
 
 	structsJob := ApiJobToStructJob(apiJob)
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"net/http"
+
+	"github.com/hashicorp/nomad/client"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) ClientMetadataRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.agent.client == nil {
+		return nil, clientNotRunning
+	}
+
+	switch req.Method {
+	case "GET":
+		return s.clientMetadataRead(resp, req)
+	case "PUT", "POST":
+		return s.clientMetadataApply(resp, req)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) clientMetadataRead(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var secret string
+	s.parseToken(req, &secret)
+
+	// Check node read permissions
+	if aclObj, err := s.agent.Client().ResolveToken(secret); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	return s.agent.Client().NodeMeta(), nil
+}
+
+func (s *HTTPServer) clientMetadataApply(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var secret string
+	s.parseToken(req, &secret)
+
+	// Check node write permissions
+	if aclObj, err := s.agent.Client().ResolveToken(secret); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	var args cstructs.NodeMetaApplyRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+
+	out, err := s.agent.Client().ApplyNodeMeta(args.Meta)
+	switch err {
+	case nil:
+	case client.ErrNoNodeMeta, client.ErrEmptyNodeMetaKey:
+		return nil, CodedError(400, err.Error())
+	default:
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/acl"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMetadataRequest(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// Set a key and remove another
+		args := cstructs.NodeMetaApplyRequest{
+			Meta: map[string]*string{
+				"rack":   helper.StringToPtr("r1"),
+				"absent": nil,
+			},
+		}
+		req, err := http.NewRequest("POST", "/v1/client/metadata", encodeReq(args))
+		assert.Nil(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.ClientMetadataRequest(respW, req)
+		assert.Nil(err)
+
+		out := obj.(*cstructs.NodeMetaResponse)
+		assert.Equal("r1", out.Meta["rack"])
+		assert.Contains(out.Dynamic, "absent")
+		assert.Nil(out.Dynamic["absent"])
+
+		// Read it back
+		req, err = http.NewRequest("GET", "/v1/client/metadata", nil)
+		assert.Nil(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.ClientMetadataRequest(respW, req)
+		assert.Nil(err)
+
+		out = obj.(*cstructs.NodeMetaResponse)
+		assert.Equal("r1", out.Meta["rack"])
+		assert.Equal("r1", *out.Dynamic["rack"])
+		assert.NotContains(out.Static, "rack")
+		assert.Equal(out.Meta, s.Agent.Client().Node().Meta)
+
+		// Empty keys are rejected
+		args.Meta = map[string]*string{"": helper.StringToPtr("foo")}
+		req, err = http.NewRequest("PUT", "/v1/client/metadata", encodeReq(args))
+		assert.Nil(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.ClientMetadataRequest(respW, req)
+		if assert.Implements((*HTTPCodedError)(nil), err) {
+			assert.Equal(400, err.(HTTPCodedError).Code())
+		}
+	})
+}
+
+func TestClientMetadataRequest_ACL(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	httpACLTest(t, nil, func(s *TestAgent) {
+		state := s.Agent.server.State()
+		args := cstructs.NodeMetaApplyRequest{
+			Meta: map[string]*string{"rack": helper.StringToPtr("r1")},
+		}
+
+		// Try request with a read token and expect failure
+		{
+			req, err := http.NewRequest("POST", "/v1/client/metadata", encodeReq(args))
+			assert.Nil(err)
+			respW := httptest.NewRecorder()
+			token := mock.CreatePolicyAndToken(t, state, 1005, "read", mock.NodePolicy(acl.PolicyRead))
+			setToken(req, token)
+			_, err = s.Server.ClientMetadataRequest(respW, req)
+			assert.NotNil(err)
+			assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+		}
+
+		// Try request with a write token
+		{
+			req, err := http.NewRequest("POST", "/v1/client/metadata", encodeReq(args))
+			assert.Nil(err)
+			respW := httptest.NewRecorder()
+			token := mock.CreatePolicyAndToken(t, state, 1007, "write", mock.NodePolicy(acl.PolicyWrite))
+			setToken(req, token)
+			_, err = s.Server.ClientMetadataRequest(respW, req)
+			assert.Nil(err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func (s *HTTPServer) NodePoolsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.NodePoolListRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodePoolListResponse
+	if err := s.agent.RPC("NodePool.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.NodePools == nil {
+		out.NodePools = make([]*structs.NodePool, 0)
+	}
+	return out.NodePools, nil
+}
+
+func (s *HTTPServer) NodePoolSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/node/pool/")
+	switch {
+	case strings.HasSuffix(path, "/nodes"):
+		name := strings.TrimSuffix(path, "/nodes")
+		return s.nodePoolNodesRequest(resp, req, name)
+	case len(path) == 0:
+		return nil, CodedError(400, "Missing Node Pool Name")
+	}
+
+	switch req.Method {
+	case "GET":
+		return s.nodePoolQuery(resp, req, path)
+	case "PUT", "POST":
+		return s.nodePoolUpdate(resp, req, path)
+	case "DELETE":
+		return s.nodePoolDelete(resp, req, path)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) nodePoolQuery(resp http.ResponseWriter, req *http.Request,
+	poolName string) (interface{}, error) {
+	args := structs.NodePoolSpecificRequest{
+		Name: poolName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.SingleNodePoolResponse
+	if err := s.agent.RPC("NodePool.GetNodePool", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.NodePool == nil {
+		return nil, CodedError(404, "node pool not found")
+	}
+	return out.NodePool, nil
+}
+
+func (s *HTTPServer) nodePoolUpdate(resp http.ResponseWriter, req *http.Request,
+	poolName string) (interface{}, error) {
+	// Parse the node pool
+	var pool structs.NodePool
+	if err := decodeBody(req, &pool); err != nil {
+		return nil, CodedError(500, err.Error())
+	}
+
+	// Ensure the node pool name matches
+	if pool.Name != poolName {
+		return nil, CodedError(400, "Node pool name does not match request path")
+	}
+
+	// Format the request
+	args := structs.NodePoolUpsertRequest{
+		NodePools: []*structs.NodePool{&pool},
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("NodePool.UpsertNodePools", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) nodePoolDelete(resp http.ResponseWriter, req *http.Request,
+	poolName string) (interface{}, error) {
+
+	args := structs.NodePoolDeleteRequest{
+		Names: []string{poolName},
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.GenericResponse
+	if err := s.agent.RPC("NodePool.DeleteNodePools", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return nil, nil
+}
+
+func (s *HTTPServer) nodePoolNodesRequest(resp http.ResponseWriter, req *http.Request,
+	poolName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.NodePoolNodesRequest{
+		Name: poolName,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodePoolNodesResponse
+	if err := s.agent.RPC("NodePool.ListNodes", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Nodes == nil {
+		out.Nodes = make([]*structs.NodeListStub, 0)
+	}
+	return out.Nodes, nil
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTP_NodePoolList(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		assert := assert.New(t)
+		p1 := mock.NodePool()
+		args := structs.NodePoolUpsertRequest{
+			NodePools:    []*structs.NodePool{p1},
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.GenericResponse
+		assert.Nil(s.Agent.RPC("NodePool.UpsertNodePools", &args, &resp))
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/node/pools", nil)
+		assert.Nil(err)
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.NodePoolsRequest(respW, req)
+		assert.Nil(err)
+
+		// Check for the index
+		assert.NotEqual("", respW.HeaderMap.Get("X-Nomad-Index"))
+		assert.Equal("true", respW.HeaderMap.Get("X-Nomad-KnownLeader"))
+
+		// Check the output includes the built-in pools
+		pools := obj.([]*structs.NodePool)
+		assert.Len(pools, 3)
+	})
+}
+
+func TestHTTP_NodePoolCRUD(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		assert := assert.New(t)
+
+		// Create the node pool
+		p1 := mock.NodePool()
+		req, err := http.NewRequest("PUT", "/v1/node/pool/"+p1.Name, encodeReq(p1))
+		assert.Nil(err)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.NodePoolSpecificRequest(respW, req)
+		assert.Nil(err)
+		assert.Nil(obj)
+		assert.NotEqual("", respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// The name must match the path
+		req, err = http.NewRequest("PUT", "/v1/node/pool/other", encodeReq(p1))
+		assert.Nil(err)
+		_, err = s.Server.NodePoolSpecificRequest(httptest.NewRecorder(), req)
+		assert.NotNil(err)
+
+		// Query the node pool
+		req, err = http.NewRequest("GET", "/v1/node/pool/"+p1.Name, nil)
+		assert.Nil(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.NodePoolSpecificRequest(respW, req)
+		assert.Nil(err)
+		out := obj.(*structs.NodePool)
+		assert.Equal(p1.Name, out.Name)
+		assert.Equal(p1.Description, out.Description)
+
+		// Delete the node pool
+		req, err = http.NewRequest("DELETE", "/v1/node/pool/"+p1.Name, nil)
+		assert.Nil(err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.NodePoolSpecificRequest(respW, req)
+		assert.Nil(err)
+
+		// Query the deleted node pool
+		req, err = http.NewRequest("GET", "/v1/node/pool/"+p1.Name, nil)
+		assert.Nil(err)
+		_, err = s.Server.NodePoolSpecificRequest(httptest.NewRecorder(), req)
+		if assert.NotNil(err) {
+			assert.Contains(err.Error(), "not found")
+		}
+	})
+}
+
+func TestHTTP_NodePoolNodes(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		assert := assert.New(t)
+		node := mock.Node()
+		node.NodePool = "gpu"
+		args := structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		assert.Nil(s.Agent.RPC("Node.Register", &args, &resp))
+
+		// Make the HTTP request
+		req, err := http.NewRequest("GET", "/v1/node/pool/gpu/nodes", nil)
+		assert.Nil(err)
+		respW := httptest.NewRecorder()
+
+		// Make the request
+		obj, err := s.Server.NodePoolSpecificRequest(respW, req)
+		assert.Nil(err)
+		assert.NotEqual("", respW.HeaderMap.Get("X-Nomad-Index"))
+
+		// Check the output
+		nodes := obj.([]*structs.NodeListStub)
+		if assert.Len(nodes, 1) {
+			assert.Equal(node.ID, nodes[0].ID)
+		}
+	})
+}
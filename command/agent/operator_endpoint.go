@@ -2,19 +2,23 @@ package agent
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/raft"
 )
 
 func (s *HTTPServer) OperatorRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
-	path := strings.TrimPrefix(req.URL.Path, "/v1/operator/raft/")
+	path := strings.TrimPrefix(req.URL.Path, "/v1/operator/")
 	switch {
-	case strings.HasPrefix(path, "configuration"):
+	case strings.HasPrefix(path, "raft/configuration"):
 		return s.OperatorRaftConfiguration(resp, req)
-	case strings.HasPrefix(path, "peer"):
+	case strings.HasPrefix(path, "raft/peer"):
 		return s.OperatorRaftPeer(resp, req)
+	case strings.HasPrefix(path, "scheduler/configuration"):
+		return s.OperatorSchedulerConfiguration(resp, req)
 	default:
 		return nil, CodedError(404, ErrInvalidMethod)
 	}
@@ -67,3 +71,55 @@ func (s *HTTPServer) OperatorRaftPeer(resp http.ResponseWriter, req *http.Reques
 	}
 	return nil, nil
 }
+
+// OperatorSchedulerConfiguration is used to inspect and update the scheduler
+// configuration of the cluster.
+func (s *HTTPServer) OperatorSchedulerConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		var args structs.GenericRequest
+		if done := s.parse(resp, req, &args.Region, &args.QueryOptions); done {
+			return nil, nil
+		}
+
+		var reply structs.SchedulerConfigurationResponse
+		if err := s.agent.RPC("Operator.SchedulerGetConfiguration", &args, &reply); err != nil {
+			return nil, err
+		}
+		setMeta(resp, &reply.QueryMeta)
+		return reply, nil
+
+	case "PUT", "POST":
+		var args structs.SchedulerSetConfigRequest
+		s.parseWriteRequest(req, &args.WriteRequest)
+
+		var conf api.SchedulerConfiguration
+		if err := decodeBody(req, &conf); err != nil {
+			return nil, CodedError(http.StatusBadRequest, err.Error())
+		}
+		args.Config = structs.SchedulerConfiguration{
+			SchedulerAlgorithm:            structs.SchedulerAlgorithm(conf.SchedulerAlgorithm),
+			MemoryOversubscriptionEnabled: conf.MemoryOversubscriptionEnabled,
+		}
+
+		// Check for check-and-set semantics
+		if casStr := req.URL.Query().Get("cas"); casStr != "" {
+			casIndex, err := strconv.ParseUint(casStr, 10, 64)
+			if err != nil {
+				return nil, CodedError(http.StatusBadRequest, "Invalid cas index: "+err.Error())
+			}
+			args.CAS = true
+			args.Config.ModifyIndex = casIndex
+		}
+
+		var reply structs.SchedulerSetConfigurationResponse
+		if err := s.agent.RPC("Operator.SchedulerSetConfiguration", &args, &reply); err != nil {
+			return nil, err
+		}
+		setIndex(resp, reply.Index)
+		return reply, nil
+
+	default:
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+}
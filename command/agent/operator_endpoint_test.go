@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -58,3 +60,92 @@ func TestHTTP_OperatorRaftPeer(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTP_OperatorSchedulerConfiguration(t *testing.T) {
+	t.Parallel()
+	httpTest(t, nil, func(s *TestAgent) {
+		// Update the configuration
+		buf := encodeReq(api.SchedulerConfiguration{
+			MemoryOversubscriptionEnabled: true,
+		})
+		req, err := http.NewRequest("PUT", "/v1/operator/scheduler/configuration", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		resp := httptest.NewRecorder()
+		if _, err := s.Server.OperatorSchedulerConfiguration(resp, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if resp.Code != 200 {
+			t.Fatalf("bad code: %d", resp.Code)
+		}
+		if resp.Header().Get("X-Nomad-Index") == "" {
+			t.Fatalf("missing index")
+		}
+
+		// Read it back
+		req, err = http.NewRequest("GET", "/v1/operator/scheduler/configuration", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		resp = httptest.NewRecorder()
+		obj, err := s.Server.OperatorSchedulerConfiguration(resp, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		out, ok := obj.(structs.SchedulerConfigurationResponse)
+		if !ok {
+			t.Fatalf("unexpected: %T", obj)
+		}
+		if out.SchedulerConfig == nil || !out.SchedulerConfig.MemoryOversubscriptionEnabled {
+			t.Fatalf("bad: %#v", out.SchedulerConfig)
+		}
+
+		// A check-and-set update with a stale index is not applied
+		buf = encodeReq(api.SchedulerConfiguration{
+			SchedulerAlgorithm: "spread",
+		})
+		req, err = http.NewRequest("PUT", "/v1/operator/scheduler/configuration?cas=1", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		resp = httptest.NewRecorder()
+		obj, err = s.Server.OperatorSchedulerConfiguration(resp, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if setResp := obj.(structs.SchedulerSetConfigurationResponse); setResp.Updated {
+			t.Fatalf("stale cas update was applied")
+		}
+
+		// A check-and-set update with the current index is applied
+		buf = encodeReq(api.SchedulerConfiguration{
+			SchedulerAlgorithm: "spread",
+		})
+		cas := strconv.FormatUint(out.SchedulerConfig.ModifyIndex, 10)
+		req, err = http.NewRequest("PUT", "/v1/operator/scheduler/configuration?cas="+cas, buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		resp = httptest.NewRecorder()
+		obj, err = s.Server.OperatorSchedulerConfiguration(resp, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if setResp := obj.(structs.SchedulerSetConfigurationResponse); !setResp.Updated {
+			t.Fatalf("cas update was not applied")
+		}
+
+		// Invalid algorithms are rejected
+		buf = encodeReq(api.SchedulerConfiguration{
+			SchedulerAlgorithm: "unknown",
+		})
+		req, err = http.NewRequest("PUT", "/v1/operator/scheduler/configuration", buf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.OperatorSchedulerConfiguration(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
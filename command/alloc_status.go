@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/api/contexts"
 	"github.com/hashicorp/nomad/client"
+	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/posener/complete"
 )
 
@@ -282,11 +283,56 @@ func (c *AllocStatusCommand) outputTaskDetails(alloc *api.Allocation, stats *api
 		state := alloc.TaskStates[task]
 		c.Ui.Output(c.Colorize().Color(fmt.Sprintf("\n[bold]Task %q is %q[reset]", task, state.State)))
 		c.outputTaskResources(alloc, task, stats, displayStats)
+		c.outputTaskVault(alloc, task)
 		c.Ui.Output("")
 		c.outputTaskStatus(state)
 	}
 }
 
+// outputTaskVault prints the Vault token settings of the passed task if it
+// requires a Vault token
+func (c *AllocStatusCommand) outputTaskVault(alloc *api.Allocation, task string) {
+	if alloc.Job == nil {
+		return
+	}
+
+	var vault *api.Vault
+	for _, tg := range alloc.Job.TaskGroups {
+		if tg.Name == nil || *tg.Name != alloc.TaskGroup {
+			continue
+		}
+		for _, t := range tg.Tasks {
+			if t.Name == task {
+				vault = t.Vault
+			}
+		}
+	}
+	if vault == nil {
+		return
+	}
+
+	role := "<default>"
+	if vault.Role != nil && *vault.Role != "" {
+		role = *vault.Role
+	}
+	ttl := "<default>"
+	if vault.TTL != nil && *vault.TTL != 0 {
+		ttl = vault.TTL.String()
+	}
+	alias := ""
+	if vault.EntityAlias != nil {
+		alias = *vault.EntityAlias
+	}
+
+	c.Ui.Output("")
+	c.Ui.Output("Task Vault")
+	out := []string{
+		"Policies|Role|Entity Alias|TTL",
+		fmt.Sprintf("%s|%s|%s|%s", strings.Join(vault.Policies, ","), role, alias, ttl),
+	}
+	c.Ui.Output(formatListWithSpaces(out))
+}
+
 func formatTaskTimes(t time.Time) string {
 	if t.IsZero() {
 		return "N/A"
@@ -396,6 +442,13 @@ func (c *AllocStatusCommand) outputTaskStatus(state *api.TaskState) {
 			} else {
 				desc = "Task's sibling failed"
 			}
+		case api.TaskDiskExceeded:
+			if event.DiskLimit != 0 {
+				desc = fmt.Sprintf("Allocation's disk usage exceeded its ephemeral disk of %s",
+					humanize.IBytes(uint64(event.DiskLimit)))
+			} else {
+				desc = "Allocation's disk usage exceeded its ephemeral disk"
+			}
 		case api.TaskSignaling:
 			sig := event.TaskSignal
 			reason := event.TaskSignalReason
@@ -466,7 +519,14 @@ func (c *AllocStatusCommand) outputTaskResources(alloc *api.Allocation, task str
 			}
 		}
 	}
-	resourcesOutput = append(resourcesOutput, fmt.Sprintf("%v MHz|%v|%v|%v|%v",
+	cpuUsage = fmt.Sprintf("%v MHz", cpuUsage)
+	if len(resource.CoreIDs) != 0 {
+		cpuUsage = fmt.Sprintf("%v (cores %v)", cpuUsage, structs.CpusetString(resource.CoreIDs))
+	}
+	if max := resource.MemoryMaxMB; max != nil && *max > *resource.MemoryMB {
+		memUsage = fmt.Sprintf("%v (max %v)", memUsage, humanize.IBytes(uint64(*max*bytesPerMegabyte)))
+	}
+	resourcesOutput = append(resourcesOutput, fmt.Sprintf("%v|%v|%v|%v|%v",
 		cpuUsage,
 		memUsage,
 		humanize.IBytes(uint64(*alloc.Resources.DiskMB*bytesPerMegabyte)),
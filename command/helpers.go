@@ -12,7 +12,9 @@ import (
 
 	gg "github.com/hashicorp/go-getter"
 	"github.com/hashicorp/nomad/api"
+	flaghelper "github.com/hashicorp/nomad/helper/flag-helpers"
 	"github.com/hashicorp/nomad/jobspec"
+	"github.com/hashicorp/nomad/jobspec2"
 	"github.com/posener/complete"
 
 	"github.com/ryanuber/columnize"
@@ -233,13 +235,28 @@ READ:
 }
 
 type JobGetter struct {
+	// hcl1 selects the HCL1 parser for job files that predate HCL2
+	hcl1 bool
+
+	// vars and varFiles set the input variables of HCL2 job files
+	vars     flaghelper.StringFlag
+	varFiles flaghelper.StringFlag
+
 	// The fields below can be overwritten for tests
 	testStdin io.Reader
 }
 
 // StructJob returns the Job struct from jobfile.
 func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
+	job, _, err := j.ApiJobWithSubmission(jpath)
+	return job, err
+}
+
+// ApiJobWithSubmission returns the Job struct from jobfile along with the
+// source it was parsed from.
+func (j *JobGetter) ApiJobWithSubmission(jpath string) (*api.Job, *api.JobSubmission, error) {
 	var jobfile io.Reader
+	path := jpath
 	switch jpath {
 	case "-":
 		if j.testStdin != nil {
@@ -247,25 +264,26 @@ func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 		} else {
 			jobfile = os.Stdin
 		}
+		path = "<stdin>"
 	default:
 		if len(jpath) == 0 {
-			return nil, fmt.Errorf("Error jobfile path has to be specified.")
+			return nil, nil, fmt.Errorf("Error jobfile path has to be specified.")
 		}
 
 		job, err := ioutil.TempFile("", "jobfile")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		defer os.Remove(job.Name())
 
 		if err := job.Close(); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Get the pwd
 		pwd, err := os.Getwd()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		client := &gg.Client{
@@ -275,24 +293,78 @@ func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 		}
 
 		if err := client.Get(); err != nil {
-			return nil, fmt.Errorf("Error getting jobfile from %q: %v", jpath, err)
+			return nil, nil, fmt.Errorf("Error getting jobfile from %q: %v", jpath, err)
 		} else {
 			file, err := os.Open(job.Name())
 			defer file.Close()
 			if err != nil {
-				return nil, fmt.Errorf("Error opening file %q: %v", jpath, err)
+				return nil, nil, fmt.Errorf("Error opening file %q: %v", jpath, err)
 			}
 			jobfile = file
 		}
 	}
 
-	// Parse the JobFile
-	jobStruct, err := jobspec.Parse(jobfile)
+	body, err := ioutil.ReadAll(jobfile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading job file from %s: %v", jpath, err)
+	}
+
+	// Parse the JobFile. JSON job files use the HCL1 parser as HCL2 only
+	// supports the native syntax.
+	var jobStruct *api.Job
+	submission := &api.JobSubmission{
+		Source: string(body),
+		Format: "hcl2",
+	}
+	if j.hcl1 || isJSONJob(body) {
+		submission.Format = "hcl1"
+		if isJSONJob(body) {
+			submission.Format = "json"
+		}
+		if len(j.vars) != 0 || len(j.varFiles) != 0 {
+			return nil, nil, fmt.Errorf("Error parsing job file from %s: variables are only supported by HCL2 job files", jpath)
+		}
+		jobStruct, err = jobspec.Parse(bytes.NewReader(body))
+	} else {
+		jobStruct, err = jobspec2.ParseWithConfig(&jobspec2.ParseConfig{
+			Path:     path,
+			Body:     body,
+			ArgVars:  j.vars,
+			VarFiles: j.varFiles,
+			Envs:     os.Environ(),
+		})
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing job file from %s: %v", jpath, err)
+		return nil, nil, fmt.Errorf("Error parsing job file from %s: %v", jpath, err)
+	}
+
+	// Record the variables the job was parsed with
+	if len(j.vars) != 0 {
+		submission.VariableFlags = make(map[string]string, len(j.vars))
+		for _, v := range j.vars {
+			parts := strings.SplitN(v, "=", 2)
+			if len(parts) == 2 {
+				submission.VariableFlags[parts[0]] = parts[1]
+			}
+		}
 	}
+	var variables []string
+	for _, path := range j.varFiles {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error reading variable file %q: %v", path, err)
+		}
+		variables = append(variables, string(contents))
+	}
+	submission.Variables = strings.Join(variables, "\n")
+
+	return jobStruct, submission, nil
+}
 
-	return jobStruct, nil
+// isJSONJob returns whether the job file uses the JSON syntax.
+func isJSONJob(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) != 0 && trimmed[0] == '{'
 }
 
 // COMPAT: Remove in 0.7.0
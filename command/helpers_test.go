@@ -271,6 +271,95 @@ func TestJobGetter_LocalFile(t *testing.T) {
 	}
 }
 
+// Test APIJob with HCL2 variables and the HCL1 parser
+func TestJobGetter_Variables(t *testing.T) {
+	t.Parallel()
+	fh, err := ioutil.TempFile("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(fh.Name())
+	_, err = fh.WriteString(`
+variable "datacenter" {
+	type = string
+}
+
+job "job1" {
+	datacenters = [var.datacenter]
+	group "group1" {
+		task "task1" {
+			driver = "exec"
+		}
+	}
+}`)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	j := &JobGetter{vars: []string{"datacenter=dc2"}}
+	aj, err := j.ApiJob(fh.Name())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(aj.Datacenters, []string{"dc2"}) {
+		t.Fatalf("bad: %#v", aj.Datacenters)
+	}
+
+	// The HCL1 parser does not support variables
+	j = &JobGetter{hcl1: true, vars: []string{"datacenter=dc2"}}
+	if _, err := j.ApiJob(fh.Name()); err == nil || !strings.Contains(err.Error(), "only supported by HCL2") {
+		t.Fatalf("expected variables error, got: %v", err)
+	}
+	j = &JobGetter{hcl1: true}
+	if _, err := j.ApiJob(fh.Name()); err == nil || !strings.Contains(err.Error(), "error parsing") {
+		t.Fatalf("expected HCL1 error, got: %v", err)
+	}
+}
+
+func TestJobGetter_Submission(t *testing.T) {
+	t.Parallel()
+	src := `
+variable "datacenter" {
+	type = string
+}
+
+job "job1" {
+	datacenters = [var.datacenter]
+	group "group1" {
+		task "task1" {
+			driver = "exec"
+		}
+	}
+}`
+
+	j := &JobGetter{
+		vars:      []string{"datacenter=dc2"},
+		testStdin: strings.NewReader(src),
+	}
+	_, sub, err := j.ApiJobWithSubmission("-")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &api.JobSubmission{
+		Source:        src,
+		Format:        "hcl2",
+		VariableFlags: map[string]string{"datacenter": "dc2"},
+	}
+	if !reflect.DeepEqual(sub, expected) {
+		t.Fatalf("bad: %#v", sub)
+	}
+
+	// JSON job files are recorded in their format
+	j = &JobGetter{testStdin: strings.NewReader(`{"job": {"job1": {"datacenters": ["dc1"]}}}`)}
+	if _, sub, err = j.ApiJobWithSubmission("-"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if sub.Format != "json" {
+		t.Fatalf("bad: %#v", sub)
+	}
+}
+
 // Test StructJob with jobfile from HTTP Server
 func TestJobGetter_HTTPServer(t *testing.T) {
 	t.Parallel()
@@ -31,6 +31,10 @@ Inspect Options:
   -json
     Output the job in its JSON format.
 
+  -hcl
+    Output the original job file the job was submitted with, if the job was
+    submitted with the run command.
+
   -t
     Format and display job using a Go template.
 `
@@ -46,6 +50,7 @@ func (c *InspectCommand) AutocompleteFlags() complete.Flags {
 		complete.Flags{
 			"-version": complete.PredictAnything,
 			"-json":    complete.PredictNothing,
+			"-hcl":     complete.PredictNothing,
 			"-t":       complete.PredictAnything,
 		})
 }
@@ -66,12 +71,13 @@ func (c *InspectCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *InspectCommand) Run(args []string) int {
-	var json bool
+	var json, hcl bool
 	var tmpl, versionStr string
 
 	flags := c.Meta.FlagSet("inspect", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&json, "json", false, "")
+	flags.BoolVar(&hcl, "hcl", false, "")
 	flags.StringVar(&tmpl, "t", "", "")
 	flags.StringVar(&versionStr, "version", "", "")
 
@@ -80,6 +86,12 @@ func (c *InspectCommand) Run(args []string) int {
 	}
 	args = flags.Args()
 
+	// The job file is output as submitted so it can't be formatted
+	if hcl && (json || len(tmpl) > 0) {
+		c.Ui.Error("Both hcl and json or template formatting are not allowed")
+		return 1
+	}
+
 	// Get the HTTP client
 	client, err := c.Meta.Client()
 	if err != nil {
@@ -145,6 +157,22 @@ func (c *InspectCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Output the source the job was submitted with
+	if hcl {
+		sub, _, err := client.Jobs().Submission(*job.ID, int(getVersion(job)), nil)
+		if err != nil {
+			if strings.Contains(err.Error(), "job submission not found") {
+				c.Ui.Error(fmt.Sprintf("No job file was stored for version %d of job %q", getVersion(job), *job.ID))
+				return 1
+			}
+			c.Ui.Error(fmt.Sprintf("Error retrieving job file: %s", err))
+			return 1
+		}
+
+		c.Ui.Output(strings.TrimSpace(sub.Source))
+		return 0
+	}
+
 	// If output format is specified, format and output the data
 	if json || len(tmpl) > 0 {
 		out, err := Format(json, tmpl, job)
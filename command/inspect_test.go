@@ -57,6 +57,18 @@ func TestInspectCommand_Fails(t *testing.T) {
 	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Both json and template formatting are not allowed") {
 		t.Fatalf("expected getting formatter error, got: %s", out)
 	}
+	ui.ErrorWriter.Reset()
+
+	// Fails when -hcl is combined with -json or -t
+	for _, args := range [][]string{{"-hcl", "-json"}, {"-hcl", "-t", "{{.ID}}"}} {
+		if code := cmd.Run(append([]string{"-address=" + url}, append(args, "job1")...)); code != 1 {
+			t.Fatalf("expected exit 1 for %v, got: %d", args, code)
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, "Both hcl and json or template formatting are not allowed") {
+			t.Fatalf("expected flag conflict error for %v, got: %s", args, out)
+		}
+		ui.ErrorWriter.Reset()
+	}
 }
 
 func TestInspectCommand_AutocompleteArgs(t *testing.T) {
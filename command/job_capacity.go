@@ -0,0 +1,225 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type JobCapacityCommand struct {
+	Meta
+	JobGetter
+}
+
+func (c *JobCapacityCommand) Help() string {
+	helpText := `
+Usage: nomad job capacity [options] <path>
+
+  Capacity forecasts how many more instances of a task group of the job fit in
+  the cluster. The scheduler repeatedly places the task group against the
+  current state of the cluster, respecting its constraints, distinct_hosts,
+  ports and the reserved resources of the nodes, until no node is feasible. The
+  placements are never submitted.
+
+  If the supplied path is "-", the jobfile is read from stdin. Otherwise
+  it is read from the file at the supplied path or downloaded and
+  read from URL specified.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Capacity Options:
+
+  -group <name>
+    The task group to forecast. It may be omitted if the job has a single
+    task group.
+
+  -limit <n>
+    The maximum number of instances to place. Defaults to the limit of the
+    servers.
+
+  -json
+    Output the forecast in a JSON format.
+
+  -t
+    Format and display the forecast using a Go template.
+
+  -verbose
+    Display the instances placed by node and full IDs.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobCapacityCommand) Synopsis() string {
+	return "Forecast how many more instances of a task group fit"
+}
+
+func (c *JobCapacityCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-group":   complete.PredictAnything,
+			"-limit":   complete.PredictAnything,
+			"-json":    complete.PredictNothing,
+			"-t":       complete.PredictAnything,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (c *JobCapacityCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(complete.PredictFiles("*.nomad"), complete.PredictFiles("*.hcl"))
+}
+
+func (c *JobCapacityCommand) Run(args []string) int {
+	var json, verbose bool
+	var group, tmpl string
+	var limit int
+
+	flags := c.Meta.FlagSet("job capacity", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&group, "group", "", "")
+	flags.IntVar(&limit, "limit", 0, "")
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one job
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	if limit < 0 {
+		c.Ui.Error("Limit must not be negative")
+		return 1
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get Job struct from Jobfile
+	job, err := c.JobGetter.ApiJob(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Force the region to be that of the job.
+	if r := job.Region; r != nil {
+		client.SetRegion(*r)
+	}
+
+	// Force the namespace to be that of the job.
+	if n := job.Namespace; n != nil {
+		client.SetNamespace(*n)
+	}
+
+	resp, _, err := client.Jobs().Capacity(job, group, limit, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error forecasting capacity: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, resp.Capacity)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.outputCapacity(resp.Capacity, verbose, length)
+
+	// Print any warnings if there are any
+	if resp.Warnings != "" {
+		c.Ui.Output(
+			c.Colorize().Color(fmt.Sprintf("\n[bold][yellow]Job Warnings:\n%s[reset]", resp.Warnings)))
+	}
+	return 0
+}
+
+// outputCapacity outputs the forecast in a human readable format
+func (c *JobCapacityCommand) outputCapacity(capacity *api.TaskGroupCapacity, verbose bool, length int) {
+	placed := fmt.Sprintf("%d", capacity.Placed)
+	if capacity.LimitReached {
+		placed += " (limit reached)"
+	}
+	limiting := capacity.LimitingDimension
+	if limiting == "" {
+		limiting = "<none>"
+	}
+	basic := []string{
+		fmt.Sprintf("Task Group|%s", capacity.TaskGroup),
+		fmt.Sprintf("Additional Instances|%s", placed),
+		fmt.Sprintf("Limiting Dimension|%s", limiting),
+	}
+	c.Ui.Output(formatKV(basic))
+
+	if len(capacity.ByDatacenter) != 0 {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Datacenters[reset]"))
+		c.Ui.Output(formatCapacityCounts("Datacenter", capacity.ByDatacenter, length))
+	}
+	if len(capacity.ByNodeClass) != 0 {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Node Classes[reset]"))
+		c.Ui.Output(formatCapacityCounts("Node Class", capacity.ByNodeClass, length))
+	}
+	if verbose && len(capacity.ByNode) != 0 {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Nodes[reset]"))
+		c.Ui.Output(formatCapacityCounts("Node ID", capacity.ByNode, length))
+	}
+
+	if capacity.Metrics != nil {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Placement Failure[reset]"))
+		c.Ui.Output(formatAllocMetrics(capacity.Metrics, false, "  "))
+	}
+}
+
+// formatCapacityCounts formats the number of instances by key, with the
+// largest counts first
+func formatCapacityCounts(header string, counts map[string]int, length int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	out := make([]string, len(keys)+1)
+	out[0] = fmt.Sprintf("%s|Instances", header)
+	for i, k := range keys {
+		name := k
+		switch {
+		case name == "":
+			name = "<none>"
+		case header == "Node ID":
+			name = limit(name, length)
+		}
+		out[i+1] = fmt.Sprintf("%s|%d", name, counts[k])
+	}
+	return formatList(out)
+}
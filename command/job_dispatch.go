@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/api/contexts"
 	flaghelper "github.com/hashicorp/nomad/helper/flag-helpers"
 	"github.com/posener/complete"
@@ -26,7 +27,8 @@ times.
 
 Upon successful creation, the dispatched job ID will be printed and the
 triggered evaluation will be monitored. This can be disabled by supplying the
-detach flag.
+detach flag. If the parameterized job is at its running limit, the dispatched
+job is queued and is not monitored.
 
 General Options:
 
@@ -40,7 +42,12 @@ Dispatch Options:
     key which is overridden when dispatching. The flag can be provided more than
     once to inject multiple metadata key/value pairs. Arbitrary keys are not
     allowed. The parameterized job must allow the key to be merged.
-    
+
+  -idempotency-token
+    Optional identifier used to prevent more than one instance of the job from
+    being dispatched. If a dispatched job with the same token is not dead, it
+    is returned instead of dispatching a new instance.
+
   -detach
     Return immediately instead of entering monitor mode. After job dispatch,
     the evaluation ID will be printed to the screen, which can be used to
@@ -59,9 +66,10 @@ func (c *JobDispatchCommand) Synopsis() string {
 func (c *JobDispatchCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-meta":    complete.PredictAnything,
-			"-detach":  complete.PredictNothing,
-			"-verbose": complete.PredictNothing,
+			"-meta":              complete.PredictAnything,
+			"-idempotency-token": complete.PredictAnything,
+			"-detach":            complete.PredictNothing,
+			"-verbose":           complete.PredictNothing,
 		})
 }
 
@@ -82,6 +90,7 @@ func (c *JobDispatchCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *JobDispatchCommand) Run(args []string) int {
 	var detach, verbose bool
+	var idempotencyToken string
 	var meta []string
 
 	flags := c.Meta.FlagSet("job dispatch", FlagSetClient)
@@ -89,6 +98,7 @@ func (c *JobDispatchCommand) Run(args []string) int {
 	flags.BoolVar(&detach, "detach", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 	flags.Var((*flaghelper.StringFlag)(&meta), "meta", "")
+	flags.StringVar(&idempotencyToken, "idempotency-token", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -145,7 +155,12 @@ func (c *JobDispatchCommand) Run(args []string) int {
 	}
 
 	// Dispatch the job
-	resp, _, err := client.Jobs().Dispatch(job, metaMap, payload, nil)
+	opts := &api.DispatchOptions{
+		Meta:             metaMap,
+		Payload:          payload,
+		IdempotencyToken: idempotencyToken,
+	}
+	resp, _, err := client.Jobs().DispatchOpts(job, opts, nil)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to dispatch job: %s", err))
 		return 1
@@ -161,10 +176,13 @@ func (c *JobDispatchCommand) Run(args []string) int {
 	if evalCreated {
 		basic = append(basic, fmt.Sprintf("Evaluation ID|%s", limit(resp.EvalID, length)))
 	}
+	if resp.Queued {
+		basic = append(basic, "Status|queued (waiting for the running limit of the parameterized job)")
+	}
 	c.Ui.Output(formatKV(basic))
 
-	// Nothing to do
-	if detach || !evalCreated {
+	// Nothing to do. Queued jobs are only evaluated once they are released.
+	if detach || !evalCreated || resp.Queued {
 		return 0
 	}
 
@@ -0,0 +1,198 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type JobExplainCommand struct {
+	Meta
+}
+
+func (c *JobExplainCommand) Help() string {
+	helpText := `
+Usage: nomad job explain [options] <job>
+
+Explain is used to diagnose why the allocations of a job are not placed. It
+aggregates the placement failures of the latest evaluation, the node classes
+the blocked evaluation waits for and the state of the latest deployment into
+a list of reasons, ordered by relevance.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Explain Options:
+
+  -json
+    Output the explanation in a JSON format.
+
+  -t
+    Format and display the explanation using a Go template.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobExplainCommand) Synopsis() string {
+	return "Explain why a job is not placed"
+}
+
+func (c *JobExplainCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json":    complete.PredictNothing,
+			"-t":       complete.PredictAnything,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (c *JobExplainCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Jobs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Jobs]
+	})
+}
+
+func (c *JobExplainCommand) Run(args []string) int {
+	var json, verbose bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet("job explain", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one job
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	jobID := args[0]
+
+	// Check if the job exists
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing jobs: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+
+	// Prefix lookup matched a single job
+	exp, _, err := client.Jobs().Explain(jobs[0].ID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error explaining job: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, exp)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.outputExplanation(exp, length)
+	return 0
+}
+
+// outputExplanation outputs the explanation in a human readable format
+func (c *JobExplainCommand) outputExplanation(exp *api.JobExplanation, length int) {
+	basic := []string{
+		fmt.Sprintf("ID|%s", exp.JobID),
+		fmt.Sprintf("Status|%s", exp.JobStatus),
+	}
+	if exp.EvalID != "" {
+		basic = append(basic, fmt.Sprintf("Evaluation|%s (%s)", limit(exp.EvalID, length), exp.EvalStatus))
+	}
+	if exp.BlockedEvalID != "" {
+		basic = append(basic, fmt.Sprintf("Blocked Evaluation|%s", limit(exp.BlockedEvalID, length)))
+	}
+	if exp.DeploymentID != "" {
+		basic = append(basic, fmt.Sprintf("Deployment|%s (%s)", limit(exp.DeploymentID, length), exp.DeploymentStatus))
+	}
+	c.Ui.Output(formatKV(basic))
+
+	if len(exp.UnplacedAllocations) != 0 {
+		tgs := make([]string, 0, len(exp.UnplacedAllocations))
+		for tg := range exp.UnplacedAllocations {
+			tgs = append(tgs, tg)
+		}
+		sort.Strings(tgs)
+
+		unplaced := make([]string, len(tgs)+1)
+		unplaced[0] = "Task Group|Unplaced"
+		for i, tg := range tgs {
+			unplaced[i+1] = fmt.Sprintf("%s|%d", tg, exp.UnplacedAllocations[tg])
+		}
+		c.Ui.Output(c.Colorize().Color("\n[bold]Unplaced Allocations[reset]"))
+		c.Ui.Output(formatList(unplaced))
+	}
+
+	c.Ui.Output(c.Colorize().Color("\n[bold]Diagnosis[reset]"))
+	if len(exp.Reasons) == 0 {
+		c.Ui.Output("No placement problems found")
+		return
+	}
+
+	reasons := make([]string, len(exp.Reasons)+1)
+	reasons[0] = "Rank|Type|Task Group|Nodes|Reason"
+	for i, r := range exp.Reasons {
+		nodes := "-"
+		if r.Nodes > 0 {
+			nodes = fmt.Sprintf("%d", r.Nodes)
+		}
+		tg := r.TaskGroup
+		if tg == "" {
+			tg = "-"
+		}
+		reasons[i+1] = fmt.Sprintf("%d|%s|%s|%s|%s", i+1, r.Type, tg, nodes, r.Message)
+	}
+	c.Ui.Output(formatList(reasons))
+}
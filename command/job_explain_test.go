@@ -0,0 +1,103 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobExplainCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &JobExplainCommand{}
+}
+
+func TestJobExplainCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &JobExplainCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	if code := cmd.Run([]string{"-address=nope", "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error listing jobs") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+}
+
+func TestJobExplainCommand_AutocompleteArgs(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	srv, _, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &JobExplainCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// Create a fake job
+	state := srv.Agent.Server().State()
+	j := mock.Job()
+	assert.Nil(state.UpsertJob(1000, j))
+
+	prefix := j.ID[:len(j.ID)-5]
+	args := complete.Args{Last: prefix}
+	predictor := cmd.AutocompleteArgs()
+
+	res := predictor.Predict(args)
+	assert.Equal(1, len(res))
+	assert.Equal(j.ID, res[0])
+}
+
+func TestJobExplainCommand_Run(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	srv, _, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &JobExplainCommand{Meta: Meta{Ui: ui, flagAddress: url}}
+
+	// Create a job that failed to place on a missing datacenter
+	state := srv.Agent.Server().State()
+	j := mock.Job()
+	eval := mock.Eval()
+	eval.JobID = j.ID
+	eval.Status = "complete"
+	eval.FailedTGAllocs = map[string]*structs.AllocMetric{
+		"web": {
+			NodesAvailable: map[string]int{"dc9": 0},
+		},
+	}
+	assert.Nil(state.UpsertJob(1000, j))
+	assert.Nil(state.UpsertEvals(1001, []*structs.Evaluation{eval}))
+
+	if code := cmd.Run([]string{"-address=" + url, j.ID}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %v", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	assert.Contains(out, "Unplaced Allocations")
+	assert.Contains(out, `No nodes are available in datacenter "dc9"`)
+	ui.OutputWriter.Reset()
+
+	// Output the explanation as JSON
+	if code := cmd.Run([]string{"-address=" + url, "-json", j.ID}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %v", code, ui.ErrorWriter.String())
+	}
+	assert.Contains(ui.OutputWriter.String(), `"EvalID": "`+eval.ID+`"`)
+}
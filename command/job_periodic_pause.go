@@ -0,0 +1,99 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type JobPeriodicPauseCommand struct {
+	Meta
+}
+
+func (c *JobPeriodicPauseCommand) Help() string {
+	helpText := `
+Usage: nomad job periodic pause [options] <job id>
+
+Pause is used to pause the launches of the given periodic job. The job is
+not launched on its schedule until it is resumed, and launches missed while it
+is paused are not caught up. Paused jobs can still be launched using the
+"nomad job periodic force" command.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobPeriodicPauseCommand) Synopsis() string {
+	return "Pause the launches of a periodic job"
+}
+
+func (c *JobPeriodicPauseCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags(FlagSetClient)
+}
+
+func (c *JobPeriodicPauseCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Jobs, nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches[contexts.Jobs]
+	})
+}
+
+func (c *JobPeriodicPauseCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("job periodic pause", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobID := args[0]
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error pausing periodic job: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+	jobID = jobs[0].ID
+
+	if _, err := client.Jobs().PeriodicPause(jobID, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error pausing periodic job %q: %s", jobID, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Periodic job %q paused", jobID))
+	return 0
+}
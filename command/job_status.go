@@ -176,8 +176,20 @@ func (c *JobStatusCommand) Run(args []string) int {
 	}
 
 	if periodic && !parameterized {
+		// The launch is only tracked once the leader has added the job to the
+		// periodic dispatcher, so a failed lookup is not an error.
+		launch, _, err := client.Jobs().PeriodicLaunch(*job.ID, nil)
+		if err == nil {
+			basic = append(basic, fmt.Sprintf("Paused|%v", launch.Paused))
+			if !launch.Launch.IsZero() {
+				basic = append(basic, fmt.Sprintf("Last Periodic Launch|%s", formatTime(launch.Launch)))
+			}
+		}
+
 		if *job.Stop {
 			basic = append(basic, fmt.Sprintf("Next Periodic Launch|none (job stopped)"))
+		} else if launch != nil && launch.Paused {
+			basic = append(basic, fmt.Sprintf("Next Periodic Launch|none (job paused)"))
 		} else {
 			location, err := job.Periodic.GetLocation()
 			if err == nil {
@@ -491,7 +503,7 @@ func (c *JobStatusCommand) outputJobSummary(client *api.Client, job *api.Job) er
 	if !periodic && !parameterizedJob {
 		c.Ui.Output(c.Colorize().Color("\n[bold]Summary[reset]"))
 		summaries := make([]string, len(summary.Summary)+1)
-		summaries[0] = "Task Group|Queued|Starting|Running|Failed|Complete|Lost"
+		summaries[0] = "Task Group|Queued|Starting|Running|Failed|Complete|Lost|Unknown"
 		taskGroups := make([]string, 0, len(summary.Summary))
 		for taskGroup := range summary.Summary {
 			taskGroups = append(taskGroups, taskGroup)
@@ -499,10 +511,10 @@ func (c *JobStatusCommand) outputJobSummary(client *api.Client, job *api.Job) er
 		sort.Strings(taskGroups)
 		for idx, taskGroup := range taskGroups {
 			tgs := summary.Summary[taskGroup]
-			summaries[idx+1] = fmt.Sprintf("%s|%d|%d|%d|%d|%d|%d",
+			summaries[idx+1] = fmt.Sprintf("%s|%d|%d|%d|%d|%d|%d|%d",
 				taskGroup, tgs.Queued, tgs.Starting,
 				tgs.Running, tgs.Failed,
-				tgs.Complete, tgs.Lost,
+				tgs.Complete, tgs.Lost, tgs.Unknown,
 			)
 		}
 		c.Ui.Output(formatList(summaries))
@@ -520,6 +532,12 @@ func (c *JobStatusCommand) outputJobSummary(client *api.Client, job *api.Job) er
 		summaries[0] = "Pending|Running|Dead"
 		summaries[1] = fmt.Sprintf("%d|%d|%d",
 			summary.Children.Pending, summary.Children.Running, summary.Children.Dead)
+
+		// Only dispatched jobs can be queued
+		if parameterizedJob {
+			summaries[0] = "Queued|" + summaries[0]
+			summaries[1] = fmt.Sprintf("%d|%s", summary.Children.Queued, summaries[1])
+		}
 		c.Ui.Output(formatList(summaries))
 	}
 
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type NodeCommand struct {
+	Meta
+}
+
+func (c *NodeCommand) Help() string {
+	helpText := `
+Usage: nomad node <subcommand> [options]
+
+  This command groups subcommands for interacting with client nodes.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeCommand) Synopsis() string {
+	return "Interact with client nodes"
+}
+
+func (c *NodeCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+)
+
+type NodeMetaCommand struct {
+	Meta
+}
+
+func (c *NodeMetaCommand) Help() string {
+	helpText := `
+Usage: nomad node meta <subcommand> [options]
+
+  This command groups subcommands for reading and updating the metadata of a
+  client node at runtime. Metadata applied with these commands is persisted by
+  the client and takes precedence over the metadata in the agent configuration.
+
+  Read the metadata of the local node:
+
+      $ nomad node meta read
+
+  Set and remove metadata on a node:
+
+      $ nomad node meta apply -node-id 3ba9 -unset zone rack=r1
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeMetaCommand) Synopsis() string {
+	return "Interact with node metadata"
+}
+
+func (c *NodeMetaCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// resolveNodeMetaID returns the full ID of the node targeted by the node meta
+// commands. If no ID is given the local node is used.
+func resolveNodeMetaID(client *api.Client, nodeID string) (string, error) {
+	if nodeID == "" {
+		return getLocalNodeID(client)
+	}
+
+	if len(nodeID) == 1 {
+		return "", fmt.Errorf("Identifier must contain at least two characters.")
+	}
+
+	nodeID = sanatizeUUIDPrefix(nodeID)
+	nodes, _, err := client.Nodes().PrefixList(nodeID)
+	if err != nil {
+		return "", fmt.Errorf("Error querying node: %s", err)
+	}
+	switch len(nodes) {
+	case 0:
+		return "", fmt.Errorf("No node(s) with prefix or id %q found", nodeID)
+	case 1:
+		return nodes[0].ID, nil
+	default:
+		return "", fmt.Errorf("Prefix %q matched multiple nodes", nodeID)
+	}
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type NodeMetaApplyCommand struct {
+	Meta
+}
+
+func (c *NodeMetaApplyCommand) Help() string {
+	helpText := `
+Usage: nomad node meta apply [options] <key>=<value>...
+
+  Modify the metadata of a client node. Keys are set to the given values and
+  any keys passed to -unset are removed. Changes are persisted by the client
+  and sent to the servers, making them available to constraints immediately.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Node Meta Apply Options:
+
+  -node-id
+    The ID of the node to update. Defaults to the local node.
+
+  -unset
+    Comma separated list of keys to remove from the node's metadata.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeMetaApplyCommand) Synopsis() string {
+	return "Modify node metadata"
+}
+
+func (c *NodeMetaApplyCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-node-id": complete.PredictAnything,
+			"-unset":   complete.PredictAnything,
+		})
+}
+
+func (c *NodeMetaApplyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NodeMetaApplyCommand) Run(args []string) int {
+	var nodeID, unset string
+
+	flags := c.Meta.FlagSet("node meta apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&nodeID, "node-id", "", "")
+	flags.StringVar(&unset, "unset", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got something to do
+	args = flags.Args()
+	if len(args) == 0 && unset == "" {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	meta, err := parseNodeMetaArgs(args, unset)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	nodeID, err = resolveNodeMetaID(client, nodeID)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if _, err := client.Nodes().ApplyMeta(nodeID, meta, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error applying node metadata: %s", err))
+		return 1
+	}
+	return 0
+}
+
+// parseNodeMetaArgs builds the metadata update from key=value arguments and
+// a comma separated list of keys to remove.
+func parseNodeMetaArgs(args []string, unset string) (map[string]*string, error) {
+	meta := make(map[string]*string, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid metadata %q: must be in the form key=value", arg)
+		}
+		value := parts[1]
+		meta[parts[0]] = &value
+	}
+
+	if unset == "" {
+		return meta, nil
+	}
+	for _, key := range strings.Split(unset, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := meta[key]; ok {
+			return nil, fmt.Errorf("Key %q cannot be both set and unset", key)
+		}
+		meta[key] = nil
+	}
+	return meta, nil
+}
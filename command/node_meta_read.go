@@ -0,0 +1,134 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type NodeMetaReadCommand struct {
+	Meta
+}
+
+func (c *NodeMetaReadCommand) Help() string {
+	helpText := `
+Usage: nomad node meta read [options]
+
+  Read the metadata of a client node, including both the metadata from the
+  agent configuration and the metadata applied at runtime.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Node Meta Read Options:
+
+  -node-id
+    The ID of the node to read. Defaults to the local node.
+
+  -json
+    Output the node metadata in its JSON format.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeMetaReadCommand) Synopsis() string {
+	return "Read node metadata"
+}
+
+func (c *NodeMetaReadCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-node-id": complete.PredictAnything,
+			"-json":    complete.PredictNothing,
+		})
+}
+
+func (c *NodeMetaReadCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NodeMetaReadCommand) Run(args []string) int {
+	var nodeID string
+	var json bool
+
+	flags := c.Meta.FlagSet("node meta read", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&nodeID, "node-id", "", "")
+	flags.BoolVar(&json, "json", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	if len(flags.Args()) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	nodeID, err = resolveNodeMetaID(client, nodeID)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	meta, err := client.Nodes().Meta(nodeID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading node metadata: %s", err))
+		return 1
+	}
+
+	if json {
+		out, err := Format(true, "", meta)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(c.Colorize().Color("[bold]All Meta[reset]"))
+	c.Ui.Output(formatNodeMeta(meta.Meta))
+	c.Ui.Output(c.Colorize().Color("\n[bold]Dynamic Meta[reset]"))
+	dynamic := make(map[string]string, len(meta.Dynamic))
+	for k, v := range meta.Dynamic {
+		if v == nil {
+			dynamic[k] = "<unset>"
+		} else {
+			dynamic[k] = *v
+		}
+	}
+	c.Ui.Output(formatNodeMeta(dynamic))
+	c.Ui.Output(c.Colorize().Color("\n[bold]Static Meta[reset]"))
+	c.Ui.Output(formatNodeMeta(meta.Static))
+	return 0
+}
+
+// formatNodeMeta formats the metadata as a sorted key/value list.
+func formatNodeMeta(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, fmt.Sprintf("%s|%s", k, meta[k]))
+	}
+	if len(out) == 0 {
+		return "<none>"
+	}
+	return formatKV(out)
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeMetaCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &NodeMetaCommand{}
+	var _ cli.Command = &NodeMetaApplyCommand{}
+	var _ cli.Command = &NodeMetaReadCommand{}
+}
+
+func TestNodeMetaApplyCommand_Fails(t *testing.T) {
+	t.Parallel()
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := new(cli.MockUi)
+	cmd := &NodeMetaApplyCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run(nil); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on malformed metadata
+	if code := cmd.Run([]string{"-address=" + url, "foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "key=value") {
+		t.Fatalf("expected invalid metadata error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on non-existent node
+	if code := cmd.Run([]string{"-address=" + url, "-node-id=12345678-abcd-efab-cdef-123456789abc", "foo=bar"}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "No node(s) with prefix or id") {
+		t.Fatalf("expected not exist error, got: %s", out)
+	}
+}
+
+func TestNodeMetaCommand_ApplyRead(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	srv, client, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	// Wait for a node to appear
+	var nodeID string
+	testutil.WaitForResult(func() (bool, error) {
+		nodes, _, err := client.Nodes().List(nil)
+		if err != nil {
+			return false, err
+		}
+		if len(nodes) == 0 {
+			return false, fmt.Errorf("missing node")
+		}
+		nodeID = nodes[0].ID
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	ui := new(cli.MockUi)
+	apply := &NodeMetaApplyCommand{Meta: Meta{Ui: ui}}
+	if code := apply.Run([]string{"-address=" + url, "-node-id=" + nodeID[:8], "rack=r1"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d\n%s", code, ui.ErrorWriter.String())
+	}
+
+	read := &NodeMetaReadCommand{Meta: Meta{Ui: ui}}
+	if code := read.Run([]string{"-address=" + url, "-node-id=" + nodeID}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d\n%s", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	assert.Contains(out, "Dynamic Meta")
+	assert.Contains(out, "rack")
+	assert.Contains(out, "r1")
+	ui.OutputWriter.Reset()
+
+	if code := apply.Run([]string{"-address=" + url, "-node-id=" + nodeID, "-unset=rack"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d\n%s", code, ui.ErrorWriter.String())
+	}
+	meta, err := client.Nodes().Meta(nodeID, nil)
+	assert.Nil(err)
+	assert.NotContains(meta.Meta, "rack")
+}
+
+func TestNodeMetaApplyCommand_ParseArgs(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	meta, err := parseNodeMetaArgs([]string{"a=1", "b=x=y"}, "c, d")
+	assert.Nil(err)
+	assert.Len(meta, 4)
+	assert.Equal("1", *meta["a"])
+	assert.Equal("x=y", *meta["b"])
+	assert.Nil(meta["c"])
+	assert.Nil(meta["d"])
+
+	_, err = parseNodeMetaArgs([]string{"a=1"}, "a")
+	assert.NotNil(err)
+
+	_, err = parseNodeMetaArgs([]string{"=1"}, "")
+	assert.NotNil(err)
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type NodePoolCommand struct {
+	Meta
+}
+
+func (c *NodePoolCommand) Help() string {
+	helpText := `
+Usage: nomad node pool <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with node pools. Node pools
+  partition the clients of a cluster. Clients join a pool with the node_pool
+  agent configuration and jobs are only placed on the clients of their pool.
+
+  List the node pools:
+
+      $ nomad node pool list
+
+  Create or update a node pool:
+
+      $ nomad node pool apply -description "GPU nodes" gpu
+
+  List the nodes in a node pool:
+
+      $ nomad node pool nodes gpu
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodePoolCommand) Synopsis() string {
+	return "Interact with node pools"
+}
+
+func (c *NodePoolCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	flaghelper "github.com/hashicorp/nomad/helper/flag-helpers"
+	"github.com/posener/complete"
+)
+
+type NodePoolApplyCommand struct {
+	Meta
+}
+
+func (c *NodePoolApplyCommand) Help() string {
+	helpText := `
+Usage: nomad node pool apply [options] <pool>
+
+Apply is used to create or update a node pool. It takes the node pool name to
+create or update as its only argument. Updating a node pool replaces its
+description, metadata and scheduler configuration.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Apply Options:
+
+  -description
+    An optional description for the node pool.
+
+  -meta <key>=<value>
+    Metadata to set on the node pool. This flag can be specified multiple
+    times.
+
+  -memory-oversubscription <true|false>
+    Overrides the cluster wide memory oversubscription setting for the jobs in
+    the node pool. If unset the cluster wide setting is used.
+
+  -scheduler-algorithm <binpack|spread>
+    Overrides the cluster wide scheduler algorithm for the jobs in the node
+    pool. If unset the cluster wide setting is used.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodePoolApplyCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-description":             complete.PredictAnything,
+			"-meta":                    complete.PredictAnything,
+			"-memory-oversubscription": complete.PredictSet("true", "false"),
+			"-scheduler-algorithm":     complete.PredictSet("binpack", "spread"),
+		})
+}
+
+func (c *NodePoolApplyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NodePoolApplyCommand) Synopsis() string {
+	return "Create or update a node pool"
+}
+
+func (c *NodePoolApplyCommand) Run(args []string) int {
+	var description, memOversub, algorithm string
+	var meta []string
+
+	flags := c.Meta.FlagSet("node pool apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&description, "description", "", "")
+	flags.Var((*flaghelper.StringFlag)(&meta), "meta", "")
+	flags.StringVar(&memOversub, "memory-oversubscription", "", "")
+	flags.StringVar(&algorithm, "scheduler-algorithm", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we get exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	name := args[0]
+
+	// Validate we have at-least a name
+	if name == "" {
+		c.Ui.Error("Node pool name required")
+		return 1
+	}
+
+	// Create the request object.
+	pool := &api.NodePool{
+		Name:        name,
+		Description: description,
+	}
+
+	// Parse the metadata
+	for _, m := range meta {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			c.Ui.Error(fmt.Sprintf("Invalid metadata %q: must be in the form key=value", m))
+			return 1
+		}
+		if pool.Meta == nil {
+			pool.Meta = make(map[string]string)
+		}
+		pool.Meta[parts[0]] = parts[1]
+	}
+
+	// Parse the scheduler configuration overrides
+	if memOversub != "" || algorithm != "" {
+		pool.SchedulerConfiguration = &api.NodePoolSchedulerConfiguration{
+			SchedulerAlgorithm: algorithm,
+		}
+	}
+	if memOversub != "" {
+		enabled, err := strconv.ParseBool(memOversub)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Invalid -memory-oversubscription value %q: %s", memOversub, err))
+			return 1
+		}
+		pool.SchedulerConfiguration.MemoryOversubscriptionEnabled = &enabled
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	_, err = client.NodePools().Register(pool, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error applying node pool: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully applied node pool %q!", name))
+	return 0
+}
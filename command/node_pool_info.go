@@ -0,0 +1,131 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type NodePoolInfoCommand struct {
+	Meta
+}
+
+func (c *NodePoolInfoCommand) Help() string {
+	helpText := `
+Usage: nomad node pool info [options] <pool>
+
+Info is used to fetch information on a node pool.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Info Options:
+
+  -json
+    Output the node pool in a JSON format.
+
+  -t
+    Format and display the node pool using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodePoolInfoCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json": complete.PredictNothing,
+			"-t":    complete.PredictAnything,
+		})
+}
+
+func (c *NodePoolInfoCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NodePoolInfoCommand) Synopsis() string {
+	return "Fetch information on a node pool"
+}
+
+func (c *NodePoolInfoCommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet("node pool info", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if l := len(args); l != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Fetch info on the node pool
+	pool, _, err := client.NodePools().Info(args[0], nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error fetching info on node pool: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, pool)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatKVNodePool(pool))
+	return 0
+}
+
+// formatKVNodePool returns a K/V formatted node pool
+func formatKVNodePool(pool *api.NodePool) string {
+	output := []string{
+		fmt.Sprintf("Name|%s", pool.Name),
+		fmt.Sprintf("Description|%s", pool.Description),
+	}
+
+	algorithm := "<cluster default>"
+	memOversub := "<cluster default>"
+	if config := pool.SchedulerConfiguration; config != nil {
+		if config.SchedulerAlgorithm != "" {
+			algorithm = config.SchedulerAlgorithm
+		}
+		if config.MemoryOversubscriptionEnabled != nil {
+			memOversub = fmt.Sprintf("%v", *config.MemoryOversubscriptionEnabled)
+		}
+	}
+	output = append(output, fmt.Sprintf("Scheduler Algorithm|%s", algorithm))
+	output = append(output, fmt.Sprintf("Memory Oversubscription|%s", memOversub))
+
+	keys := make([]string, 0, len(pool.Meta))
+	for k := range pool.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		output = append(output, fmt.Sprintf("meta.%s|%s", k, pool.Meta[k]))
+	}
+	return formatKV(output)
+}
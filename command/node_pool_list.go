@@ -0,0 +1,122 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type NodePoolListCommand struct {
+	Meta
+}
+
+func (c *NodePoolListCommand) Help() string {
+	helpText := `
+Usage: nomad node pool list [options]
+
+List is used to list the node pools.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+List Options:
+
+  -prefix
+    Only list the node pools whose name starts with the given prefix.
+
+  -json
+    Output the node pools in a JSON format.
+
+  -t
+    Format and display the node pools using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodePoolListCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-prefix": complete.PredictAnything,
+			"-json":   complete.PredictNothing,
+			"-t":      complete.PredictAnything,
+		})
+}
+
+func (c *NodePoolListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NodePoolListCommand) Synopsis() string {
+	return "List node pools"
+}
+
+func (c *NodePoolListCommand) Run(args []string) int {
+	var json bool
+	var tmpl, prefix string
+
+	flags := c.Meta.FlagSet("node pool list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&prefix, "prefix", "", "")
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got no arguments
+	args = flags.Args()
+	if l := len(args); l != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	pools, _, err := client.NodePools().PrefixList(prefix, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error retrieving node pools: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, pools)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatNodePools(pools))
+	return 0
+}
+
+func formatNodePools(pools []*api.NodePool) string {
+	if len(pools) == 0 {
+		return "No node pools found"
+	}
+
+	// Sort the output by node pool name
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	rows := make([]string, len(pools)+1)
+	rows[0] = "Name|Description"
+	for i, pool := range pools {
+		rows[i+1] = fmt.Sprintf("%s|%s",
+			pool.Name,
+			pool.Description)
+	}
+	return formatList(rows)
+}
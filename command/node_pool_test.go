@@ -0,0 +1,108 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodePoolCommand_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &NodePoolCommand{}
+	var _ cli.Command = &NodePoolApplyCommand{}
+	var _ cli.Command = &NodePoolDeleteCommand{}
+	var _ cli.Command = &NodePoolInfoCommand{}
+	var _ cli.Command = &NodePoolListCommand{}
+	var _ cli.Command = &NodePoolNodesCommand{}
+}
+
+func TestNodePoolApplyCommand_Fails(t *testing.T) {
+	t.Parallel()
+	ui := new(cli.MockUi)
+	cmd := &NodePoolApplyCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on malformed metadata
+	if code := cmd.Run([]string{"-meta=foo", "gpu"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "key=value") {
+		t.Fatalf("expected invalid metadata error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on an invalid scheduler configuration
+	if code := cmd.Run([]string{"-memory-oversubscription=maybe", "gpu"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "memory-oversubscription") {
+		t.Fatalf("expected invalid value error, got: %s", out)
+	}
+}
+
+func TestNodePoolCommand_ApplyInfoDelete(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	srv, client, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	// Create a node pool
+	ui := new(cli.MockUi)
+	apply := &NodePoolApplyCommand{Meta: Meta{Ui: ui}}
+	code := apply.Run([]string{"-address=" + url, "-description=GPU nodes",
+		"-meta=team=ml", "-memory-oversubscription=true",
+		"-scheduler-algorithm=spread", "gpu"})
+	assert.Equal(0, code, ui.ErrorWriter.String())
+
+	pool, _, err := client.NodePools().Info("gpu", nil)
+	assert.Nil(err)
+	assert.Equal("GPU nodes", pool.Description)
+	assert.Equal("ml", pool.Meta["team"])
+	if assert.NotNil(pool.SchedulerConfiguration) {
+		assert.True(*pool.SchedulerConfiguration.MemoryOversubscriptionEnabled)
+		assert.Equal("spread", pool.SchedulerConfiguration.SchedulerAlgorithm)
+	}
+
+	// List the node pools
+	ui = new(cli.MockUi)
+	list := &NodePoolListCommand{Meta: Meta{Ui: ui}}
+	assert.Equal(0, list.Run([]string{"-address=" + url}))
+	out := ui.OutputWriter.String()
+	assert.Contains(out, "GPU nodes")
+	assert.Contains(out, api.NodePoolDefault)
+
+	// Read the node pool
+	ui = new(cli.MockUi)
+	info := &NodePoolInfoCommand{Meta: Meta{Ui: ui}}
+	assert.Equal(0, info.Run([]string{"-address=" + url, "gpu"}))
+	out = ui.OutputWriter.String()
+	assert.Contains(out, "meta.team")
+	assert.Contains(out, "Memory Oversubscription")
+	assert.Contains(out, "spread")
+
+	// Delete the node pool
+	ui = new(cli.MockUi)
+	del := &NodePoolDeleteCommand{Meta: Meta{Ui: ui}}
+	assert.Equal(0, del.Run([]string{"-address=" + url, "gpu"}), ui.ErrorWriter.String())
+
+	_, _, err = client.NodePools().Info("gpu", nil)
+	assert.NotNil(err)
+
+	// Built-in node pools can't be deleted
+	ui = new(cli.MockUi)
+	del = &NodePoolDeleteCommand{Meta: Meta{Ui: ui}}
+	assert.Equal(1, del.Run([]string{"-address=" + url, api.NodePoolDefault}))
+	assert.Contains(ui.ErrorWriter.String(), "built-in")
+}
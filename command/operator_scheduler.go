@@ -0,0 +1,30 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type OperatorSchedulerCommand struct {
+	Meta
+}
+
+func (c *OperatorSchedulerCommand) Help() string {
+	helpText := `
+Usage: nomad operator scheduler <subcommand> [options]
+
+The scheduler operator command is used to interact with the cluster wide
+scheduler configuration. The configuration controls how the schedulers score
+nodes and whether memory oversubscription is enabled.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSchedulerCommand) Synopsis() string {
+	return "Provides access to the scheduler configuration"
+}
+
+func (c *OperatorSchedulerCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type OperatorSchedulerGetConfig struct {
+	Meta
+}
+
+func (c *OperatorSchedulerGetConfig) Help() string {
+	helpText := `
+Usage: nomad operator scheduler get-config [options]
+
+Displays the current scheduler configuration of the cluster.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Get Config Options:
+
+  -stale=[true|false]
+    The -stale argument defaults to "false" which means the leader provides the
+    result. If the cluster is in an outage state without a leader, you may need
+    to set -stale to "true" to get the configuration from a non-leader server.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSchedulerGetConfig) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-stale": complete.PredictAnything,
+		})
+}
+
+func (c *OperatorSchedulerGetConfig) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OperatorSchedulerGetConfig) Synopsis() string {
+	return "Display the current scheduler configuration"
+}
+
+func (c *OperatorSchedulerGetConfig) Run(args []string) int {
+	var stale bool
+
+	flags := c.Meta.FlagSet("scheduler get-config", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&stale, "stale", false, "")
+	if err := flags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+
+	// Set up a client.
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Fetch the current configuration.
+	q := &api.QueryOptions{
+		AllowStale: stale,
+	}
+	resp, _, err := client.Operator().SchedulerGetConfiguration(q)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to retrieve scheduler configuration: %v", err))
+		return 1
+	}
+
+	c.Ui.Output(formatKVSchedulerConfig(resp.SchedulerConfig))
+	return 0
+}
+
+// formatKVSchedulerConfig returns a K/V formatted scheduler configuration
+func formatKVSchedulerConfig(config *api.SchedulerConfiguration) string {
+	if config == nil {
+		config = &api.SchedulerConfiguration{}
+	}
+	algorithm := config.SchedulerAlgorithm
+	if algorithm == "" {
+		algorithm = "binpack"
+	}
+	output := []string{
+		fmt.Sprintf("Scheduler Algorithm|%s", algorithm),
+		fmt.Sprintf("Memory Oversubscription|%v", config.MemoryOversubscriptionEnabled),
+		fmt.Sprintf("Modify Index|%d", config.ModifyIndex),
+	}
+	return formatKV(output)
+}
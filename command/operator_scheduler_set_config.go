@@ -0,0 +1,145 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type OperatorSchedulerSetConfig struct {
+	Meta
+}
+
+func (c *OperatorSchedulerSetConfig) Help() string {
+	helpText := `
+Usage: nomad operator scheduler set-config [options]
+
+Modifies the current scheduler configuration of the cluster. Only the settings
+given as flags are changed; the others keep their current value.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Set Config Options:
+
+  -scheduler-algorithm <binpack|spread>
+    Specifies whether the schedulers pack allocations tightly onto as few
+    nodes as possible ("binpack") or spread them across as many nodes as
+    possible ("spread"). Node pools and jobs can override this setting.
+
+  -memory-oversubscription <true|false>
+    Specifies whether tasks may use memory up to their memory_max.
+
+  -check-index <index>
+    If set, the configuration is only updated if the passed modify index
+    matches the current one. The current modify index can be found with
+    "nomad operator scheduler get-config".
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSchedulerSetConfig) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-scheduler-algorithm":     complete.PredictSet("binpack", "spread"),
+			"-memory-oversubscription": complete.PredictSet("true", "false"),
+			"-check-index":             complete.PredictAnything,
+		})
+}
+
+func (c *OperatorSchedulerSetConfig) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OperatorSchedulerSetConfig) Synopsis() string {
+	return "Modify the current scheduler configuration"
+}
+
+func (c *OperatorSchedulerSetConfig) Run(args []string) int {
+	var algorithm, memOversub, checkIndex string
+
+	flags := c.Meta.FlagSet("scheduler set-config", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&algorithm, "scheduler-algorithm", "", "")
+	flags.StringVar(&memOversub, "memory-oversubscription", "", "")
+	flags.StringVar(&checkIndex, "check-index", "", "")
+	if err := flags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+
+	// Check that we got no arguments
+	if len(flags.Args()) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Set up a client.
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+	operator := client.Operator()
+
+	// Fetch the current configuration so unset flags keep their value.
+	resp, _, err := operator.SchedulerGetConfiguration(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to retrieve scheduler configuration: %v", err))
+		return 1
+	}
+	conf := resp.SchedulerConfig
+	if conf == nil {
+		conf = &api.SchedulerConfiguration{}
+	}
+
+	// Apply the flags
+	switch algorithm {
+	case "":
+	case "binpack", "spread":
+		conf.SchedulerAlgorithm = algorithm
+	default:
+		c.Ui.Error(fmt.Sprintf("Invalid -scheduler-algorithm value %q: must be \"binpack\" or \"spread\"", algorithm))
+		return 1
+	}
+	if memOversub != "" {
+		enabled, err := strconv.ParseBool(memOversub)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Invalid -memory-oversubscription value %q: %s", memOversub, err))
+			return 1
+		}
+		conf.MemoryOversubscriptionEnabled = enabled
+	}
+
+	// Update the configuration, using check-and-set if requested
+	if checkIndex == "" {
+		if _, _, err := operator.SchedulerSetConfiguration(conf, nil); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error updating scheduler configuration: %s", err))
+			return 1
+		}
+		c.Ui.Output("Scheduler configuration updated!")
+		return 0
+	}
+
+	index, err := strconv.ParseUint(checkIndex, 10, 64)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Invalid -check-index value %q: %s", checkIndex, err))
+		return 1
+	}
+	conf.ModifyIndex = index
+	setResp, _, err := operator.SchedulerCASConfiguration(conf, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error updating scheduler configuration: %s", err))
+		return 1
+	}
+	if !setResp.Updated {
+		c.Ui.Error("Scheduler configuration was not updated: the check index does not match the current modify index")
+		return 1
+	}
+	c.Ui.Output("Scheduler configuration updated!")
+	return 0
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestOperator_Scheduler_SetConfig_Implements(t *testing.T) {
+	t.Parallel()
+	var _ cli.Command = &OperatorSchedulerSetConfig{}
+}
+
+func TestOperator_Scheduler_SetConfig(t *testing.T) {
+	t.Parallel()
+	s, client, addr := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	// Fails on an invalid algorithm
+	ui := new(cli.MockUi)
+	c := &OperatorSchedulerSetConfig{Meta: Meta{Ui: ui}}
+	if code := c.Run([]string{"-address=" + addr, "-scheduler-algorithm=random"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "scheduler-algorithm") {
+		t.Fatalf("bad: %s", out)
+	}
+
+	// Update the algorithm
+	ui = new(cli.MockUi)
+	c = &OperatorSchedulerSetConfig{Meta: Meta{Ui: ui}}
+	if code := c.Run([]string{"-address=" + addr, "-scheduler-algorithm=spread"}); code != 0 {
+		t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+	}
+
+	resp, _, err := client.Operator().SchedulerGetConfiguration(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	conf := resp.SchedulerConfig
+	if conf == nil || conf.SchedulerAlgorithm != "spread" || conf.MemoryOversubscriptionEnabled {
+		t.Fatalf("bad: %#v", conf)
+	}
+
+	// A stale check index is rejected
+	ui = new(cli.MockUi)
+	c = &OperatorSchedulerSetConfig{Meta: Meta{Ui: ui}}
+	stale := strconv.FormatUint(conf.ModifyIndex-1, 10)
+	if code := c.Run([]string{"-address=" + addr, "-memory-oversubscription=true", "-check-index=" + stale}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+
+	// The current check index is accepted and the algorithm is kept
+	ui = new(cli.MockUi)
+	c = &OperatorSchedulerSetConfig{Meta: Meta{Ui: ui}}
+	current := strconv.FormatUint(conf.ModifyIndex, 10)
+	if code := c.Run([]string{"-address=" + addr, "-memory-oversubscription=true", "-check-index=" + current}); code != 0 {
+		t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+	}
+
+	resp, _, err = client.Operator().SchedulerGetConfiguration(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	conf = resp.SchedulerConfig
+	if conf.SchedulerAlgorithm != "spread" || !conf.MemoryOversubscriptionEnabled {
+		t.Fatalf("bad: %#v", conf)
+	}
+}
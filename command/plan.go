@@ -40,6 +40,11 @@ Usage: nomad plan [options] <path>
   it is read from the file at the supplied path or downloaded and
   read from URL specified.
 
+  Job files are parsed as HCL2, which supports input variables, local values
+  and functions. The values of input variables are set with the -var and
+  -var-file flags and the NOMAD_VAR_<name> environment variables. The -hcl1
+  flag parses job files written for the HCL1 parser.
+
   A job modify index is returned with the plan. This value can be used when
   submitting the job using "nomad run -check-index", which will check that the job
   was not modified between the plan and run command before invoking the
@@ -66,9 +71,19 @@ Plan Options:
     Determines whether the diff between the remote job and planned job is shown.
     Defaults to true.
 
+  -hcl1
+    Parses the job file with the HCL1 parser instead of the HCL2 parser.
+
   -policy-override
     Sets the flag to force override any soft mandatory Sentinel policies.
 
+  -var 'key=value'
+    Sets the value of an input variable of the job file. It may be repeated.
+
+  -var-file=path
+    Sets the values of input variables of the job file from the HCL or JSON
+    variable file at the path. It may be repeated.
+
   -verbose
     Increase diff verbosity.
 `
@@ -83,7 +98,10 @@ func (c *PlanCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
 			"-diff":            complete.PredictNothing,
+			"-hcl1":            complete.PredictNothing,
 			"-policy-override": complete.PredictNothing,
+			"-var":             complete.PredictAnything,
+			"-var-file":        complete.PredictFiles("*"),
 			"-verbose":         complete.PredictNothing,
 		})
 }
@@ -100,6 +118,9 @@ func (c *PlanCommand) Run(args []string) int {
 	flags.BoolVar(&diff, "diff", true, "")
 	flags.BoolVar(&policyOverride, "policy-override", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.BoolVar(&c.JobGetter.hcl1, "hcl1", false, "")
+	flags.Var(&c.JobGetter.vars, "var", "")
+	flags.Var(&c.JobGetter.varFiles, "var-file", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 255
@@ -211,7 +232,7 @@ func formatDryRun(resp *api.JobPlanResponse, job *api.Job) string {
 		out = "[bold][green]- All tasks successfully allocated.[reset]\n"
 	} else {
 		// Change the output depending on if we are a system job or not
-		if job.Type != nil && *job.Type == "system" {
+		if job.Type != nil && (*job.Type == api.JobTypeSystem || *job.Type == api.JobTypeSysBatch) {
 			out = "[bold][yellow]- WARNING: Failed to place allocations on all nodes.[reset]\n"
 		} else {
 			out = "[bold][yellow]- WARNING: Failed to place all allocations.[reset]\n"
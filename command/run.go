@@ -36,6 +36,11 @@ Usage: nomad run [options] <path>
   it is read from the file at the supplied path or downloaded and
   read from URL specified.
 
+  Job files are parsed as HCL2, which supports input variables, local values
+  and functions. The values of input variables are set with the -var and
+  -var-file flags and the NOMAD_VAR_<name> environment variables. The -hcl1
+  flag parses job files written for the HCL1 parser.
+
   Upon successful job submission, this command will immediately
   enter an interactive monitor. This is useful to watch Nomad's
   internals make scheduling decisions and place the submitted work
@@ -55,6 +60,10 @@ Usage: nomad run [options] <path>
   precedence, going from highest to lowest: the -vault-token flag, the
   $VAULT_TOKEN environment variable and finally the value in the job file.
 
+  The run command will set the consul_token of the job based on the following
+  precedence, going from highest to lowest: the -consul-token flag, the
+  $CONSUL_HTTP_TOKEN environment variable and finally the value in the job file.
+
 General Options:
 
   ` + generalOptionsUsage() + `
@@ -69,11 +78,20 @@ Run Options:
     known state. The use of this flag is most common in conjunction with plan
     command.
 
+  -consul-token
+    If set, the passed Consul token is stored in the job before sending to the
+    Nomad servers. This allows passing the Consul token without storing it in
+    the job file. This overrides the token found in $CONSUL_HTTP_TOKEN
+    environment variable and that found in the job.
+
   -detach
     Return immediately instead of entering monitor mode. After job submission,
     the evaluation ID will be printed to the screen, which can be used to
     examine the evaluation using the eval-status command.
 
+  -hcl1
+    Parses the job file with the HCL1 parser instead of the HCL2 parser.
+
   -output
     Output the JSON that would be submitted to the HTTP API without submitting
     the job.
@@ -81,6 +99,13 @@ Run Options:
   -policy-override
     Sets the flag to force override any soft mandatory Sentinel policies.
 
+  -var 'key=value'
+    Sets the value of an input variable of the job file. It may be repeated.
+
+  -var-file=path
+    Sets the values of input variables of the job file from the HCL or JSON
+    variable file at the path. It may be repeated.
+
   -vault-token
     If set, the passed Vault token is stored in the job before sending to the
     Nomad servers. This allows passing the Vault token without storing it in
@@ -101,11 +126,15 @@ func (c *RunCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
 			"-check-index":     complete.PredictNothing,
+			"-consul-token":    complete.PredictAnything,
 			"-detach":          complete.PredictNothing,
+			"-hcl1":            complete.PredictNothing,
 			"-verbose":         complete.PredictNothing,
 			"-vault-token":     complete.PredictAnything,
 			"-output":          complete.PredictNothing,
 			"-policy-override": complete.PredictNothing,
+			"-var":             complete.PredictAnything,
+			"-var-file":        complete.PredictFiles("*"),
 		})
 }
 
@@ -115,7 +144,7 @@ func (c *RunCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *RunCommand) Run(args []string) int {
 	var detach, verbose, output, override bool
-	var checkIndexStr, vaultToken string
+	var checkIndexStr, vaultToken, consulToken string
 
 	flags := c.Meta.FlagSet("run", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
@@ -125,6 +154,10 @@ func (c *RunCommand) Run(args []string) int {
 	flags.BoolVar(&override, "policy-override", false, "")
 	flags.StringVar(&checkIndexStr, "check-index", "", "")
 	flags.StringVar(&vaultToken, "vault-token", "", "")
+	flags.StringVar(&consulToken, "consul-token", "", "")
+	flags.BoolVar(&c.JobGetter.hcl1, "hcl1", false, "")
+	flags.Var(&c.JobGetter.vars, "var", "")
+	flags.Var(&c.JobGetter.varFiles, "var-file", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -151,7 +184,7 @@ func (c *RunCommand) Run(args []string) int {
 	}
 
 	// Get Job struct from Jobfile
-	job, err := c.JobGetter.ApiJob(args[0])
+	job, submission, err := c.JobGetter.ApiJobWithSubmission(args[0])
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
 		return 1
@@ -188,6 +221,16 @@ func (c *RunCommand) Run(args []string) int {
 		job.VaultToken = helper.StringToPtr(vaultToken)
 	}
 
+	// Parse the Consul token
+	if consulToken == "" {
+		// Check the environment variable
+		consulToken = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+
+	if consulToken != "" {
+		job.ConsulToken = helper.StringToPtr(consulToken)
+	}
+
 	if output {
 		req := api.RegisterJobRequest{Job: job}
 		buf, err := json.MarshalIndent(req, "", "    ")
@@ -208,7 +251,7 @@ func (c *RunCommand) Run(args []string) int {
 	}
 
 	// Set the register options
-	opts := &api.RegisterOptions{}
+	opts := &api.RegisterOptions{Submission: submission}
 	if enforce {
 		opts.EnforceIndex = true
 		opts.ModifyIndex = checkIndex
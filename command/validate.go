@@ -26,6 +26,23 @@ Usage: nomad validate [options] <path>
   If the supplied path is "-", the jobfile is read from stdin. Otherwise
   it is read from the file at the supplied path or downloaded and
   read from URL specified.
+
+  Job files are parsed as HCL2, which supports input variables, local values
+  and functions. The values of input variables are set with the -var and
+  -var-file flags and the NOMAD_VAR_<name> environment variables. The -hcl1
+  flag parses job files written for the HCL1 parser.
+
+Validate Options:
+
+  -hcl1
+    Parses the job file with the HCL1 parser instead of the HCL2 parser.
+
+  -var 'key=value'
+    Sets the value of an input variable of the job file. It may be repeated.
+
+  -var-file=path
+    Sets the values of input variables of the job file from the HCL or JSON
+    variable file at the path. It may be repeated.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -35,7 +52,11 @@ func (c *ValidateCommand) Synopsis() string {
 }
 
 func (c *ValidateCommand) AutocompleteFlags() complete.Flags {
-	return nil
+	return complete.Flags{
+		"-hcl1":     complete.PredictNothing,
+		"-var":      complete.PredictAnything,
+		"-var-file": complete.PredictFiles("*"),
+	}
 }
 
 func (c *ValidateCommand) AutocompleteArgs() complete.Predictor {
@@ -45,6 +66,9 @@ func (c *ValidateCommand) AutocompleteArgs() complete.Predictor {
 func (c *ValidateCommand) Run(args []string) int {
 	flags := c.Meta.FlagSet("validate", FlagSetNone)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&c.JobGetter.hcl1, "hcl1", false, "")
+	flags.Var(&c.JobGetter.vars, "var", "")
+	flags.Var(&c.JobGetter.varFiles, "var-file", "")
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
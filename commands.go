@@ -183,6 +183,11 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job capacity": func() (cli.Command, error) {
+			return &command.JobCapacityCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job deployments": func() (cli.Command, error) {
 			return &command.JobDeploymentsCommand{
 				Meta: meta,
@@ -193,11 +198,41 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job explain": func() (cli.Command, error) {
+			return &command.JobExplainCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job history": func() (cli.Command, error) {
 			return &command.JobHistoryCommand{
 				Meta: meta,
 			}, nil
 		},
+		"job inspect": func() (cli.Command, error) {
+			return &command.InspectCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job periodic": func() (cli.Command, error) {
+			return &command.JobPeriodicCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job periodic force": func() (cli.Command, error) {
+			return &command.JobPeriodicForceCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job periodic pause": func() (cli.Command, error) {
+			return &command.JobPeriodicPauseCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job periodic resume": func() (cli.Command, error) {
+			return &command.JobPeriodicResumeCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job promote": func() (cli.Command, error) {
 			return &command.JobPromoteCommand{
 				Meta: meta,
@@ -238,6 +273,11 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"node": func() (cli.Command, error) {
+			return &command.NodeCommand{
+				Meta: meta,
+			}, nil
+		},
 		"node-drain": func() (cli.Command, error) {
 			return &command.NodeDrainCommand{
 				Meta: meta,
@@ -248,6 +288,51 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"node meta": func() (cli.Command, error) {
+			return &command.NodeMetaCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node meta apply": func() (cli.Command, error) {
+			return &command.NodeMetaApplyCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node meta read": func() (cli.Command, error) {
+			return &command.NodeMetaReadCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool": func() (cli.Command, error) {
+			return &command.NodePoolCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool apply": func() (cli.Command, error) {
+			return &command.NodePoolApplyCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool delete": func() (cli.Command, error) {
+			return &command.NodePoolDeleteCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool info": func() (cli.Command, error) {
+			return &command.NodePoolInfoCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool list": func() (cli.Command, error) {
+			return &command.NodePoolListCommand{
+				Meta: meta,
+			}, nil
+		},
+		"node pool nodes": func() (cli.Command, error) {
+			return &command.NodePoolNodesCommand{
+				Meta: meta,
+			}, nil
+		},
 
 		"operator": func() (cli.Command, error) {
 			return &command.OperatorCommand{
@@ -273,6 +358,24 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 			}, nil
 		},
 
+		"operator scheduler": func() (cli.Command, error) {
+			return &command.OperatorSchedulerCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"operator scheduler get-config": func() (cli.Command, error) {
+			return &command.OperatorSchedulerGetConfig{
+				Meta: meta,
+			}, nil
+		},
+
+		"operator scheduler set-config": func() (cli.Command, error) {
+			return &command.OperatorSchedulerSetConfig{
+				Meta: meta,
+			}, nil
+		},
+
 		"plan": func() (cli.Command, error) {
 			return &command.PlanCommand{
 				Meta: meta,
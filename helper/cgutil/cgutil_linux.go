@@ -0,0 +1,44 @@
+// +build linux
+
+// Package cgutil contains helpers to detect which cgroup hierarchy a Linux
+// host uses.
+package cgutil
+
+import (
+	"sync"
+	"syscall"
+)
+
+const (
+	// UnifiedMountpoint is where the unified (v2) cgroup hierarchy is
+	// mounted on hosts that only use the unified hierarchy.
+	UnifiedMountpoint = "/sys/fs/cgroup"
+
+	// cgroup2SuperMagic is the filesystem type of the unified hierarchy
+	cgroup2SuperMagic = 0x63677270
+)
+
+var (
+	useV2     bool
+	useV2Once sync.Once
+)
+
+// UseV2 returns whether the host only mounts the unified (v2) cgroup
+// hierarchy. Hosts that mount the v1 hierarchies, including hybrid hosts that
+// also mount the unified hierarchy, use v1.
+func UseV2() bool {
+	useV2Once.Do(func() {
+		useV2 = IsUnified(UnifiedMountpoint)
+	})
+	return useV2
+}
+
+// IsUnified returns whether the given path is a mount of the unified (v2)
+// cgroup hierarchy.
+func IsUnified(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return int64(st.Type) == cgroup2SuperMagic
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -21,6 +23,7 @@ import (
 )
 
 var reDynamicPorts = regexp.MustCompile("^[a-zA-Z0-9_]+$")
+var reQuotedField = regexp.MustCompile(`'([^']+)'`)
 var errPortLabel = fmt.Errorf("Port label does not conform to naming requirements %s", reDynamicPorts.String())
 
 // Parse parses the job spec from the given io.Reader.
@@ -47,6 +50,13 @@ func Parse(r io.Reader) (*api.Job, error) {
 		return nil, fmt.Errorf("error parsing: root should be an object")
 	}
 
+	return ParseObjectList(list)
+}
+
+// ParseObjectList parses the job spec from the root object list of a parsed
+// HCL file. It allows other front ends, such as the HCL2 parser, to share the
+// validation and decoding of the job.
+func ParseObjectList(list *ast.ObjectList) (*api.Job, error) {
 	// Check for invalid keys
 	valid := []string{
 		"job",
@@ -87,11 +97,12 @@ func ParseFile(path string) (*api.Job, error) {
 
 func parseJob(result *api.Job, list *ast.ObjectList) error {
 	if len(list.Items) != 1 {
-		return fmt.Errorf("only one 'job' block allowed")
+		return posError(list.Items[1], fmt.Errorf("only one 'job' block allowed"))
 	}
+	block := list.Items[0]
 	list = list.Children()
 	if len(list.Items) != 1 {
-		return fmt.Errorf("'job' block missing name")
+		return posError(block, fmt.Errorf("'job' block missing name"))
 	}
 
 	// Get our job object
@@ -100,7 +111,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 	// Decode the full thing into a map[string]interface for ease
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 	delete(m, "constraint")
 	delete(m, "meta")
@@ -115,7 +126,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 
 	// Decode the rest
 	if err := mapstructure.WeakDecode(m, result); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 
 	// Value should be an object
@@ -123,13 +134,14 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 	if ot, ok := obj.Val.(*ast.ObjectType); ok {
 		listVal = ot.List
 	} else {
-		return fmt.Errorf("job '%s' value: should be an object", *result.ID)
+		return posError(obj.Val, fmt.Errorf("job '%s' value: should be an object", *result.ID))
 	}
 
 	// Check for invalid keys
 	valid := []string{
 		"all_at_once",
 		"constraint",
+		"consul_token",
 		"datacenters",
 		"parameterized",
 		"group",
@@ -137,9 +149,11 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		"meta",
 		"name",
 		"namespace",
+		"node_pool",
 		"periodic",
 		"priority",
 		"region",
+		"scheduler_algorithm",
 		"task",
 		"type",
 		"update",
@@ -184,10 +198,10 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		for _, o := range metaO.Elem().Items {
 			var m map[string]interface{}
 			if err := hcl.DecodeObject(&m, o.Val); err != nil {
-				return err
+				return posError(o.Val, err)
 			}
 			if err := mapstructure.WeakDecode(m, &result.Meta); err != nil {
-				return err
+				return posError(o.Val, err)
 			}
 		}
 	}
@@ -253,7 +267,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 
 		// Make sure we haven't already found this
 		if _, ok := seen[n]; ok {
-			return fmt.Errorf("group '%s' defined more than once", n)
+			return posError(item, fmt.Errorf("group '%s' defined more than once", n))
 		}
 		seen[n] = struct{}{}
 
@@ -262,7 +276,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 		if ot, ok := item.Val.(*ast.ObjectType); ok {
 			listVal = ot.List
 		} else {
-			return fmt.Errorf("group '%s': should be an object", n)
+			return posError(item, fmt.Errorf("group '%s': should be an object", n))
 		}
 
 		// Check for invalid keys
@@ -275,6 +289,9 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			"ephemeral_disk",
 			"update",
 			"vault",
+			"network",
+			"depends_on",
+			"max_client_disconnect",
 		}
 		if err := checkHCLKeys(listVal, valid); err != nil {
 			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
@@ -282,7 +299,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, item.Val); err != nil {
-			return err
+			return posError(item.Val, err)
 		}
 		delete(m, "constraint")
 		delete(m, "meta")
@@ -291,13 +308,23 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 		delete(m, "ephemeral_disk")
 		delete(m, "update")
 		delete(m, "vault")
+		delete(m, "network")
+		delete(m, "depends_on")
 
 		// Build the group with the basic decode
 		var g api.TaskGroup
 		g.Name = helper.StringToPtr(n)
-		if err := mapstructure.WeakDecode(m, &g); err != nil {
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &g,
+		})
+		if err != nil {
 			return err
 		}
+		if err := dec.Decode(m); err != nil {
+			return posError(item.Val, err)
+		}
 
 		// Parse constraints
 		if o := listVal.Filter("constraint"); len(o.Items) > 0 {
@@ -328,16 +355,30 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse the group network
+		if o := listVal.Filter("network"); len(o.Items) > 0 {
+			if err := parseGroupNetwork(&g.Networks, o); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("'%s', network ->", n))
+			}
+		}
+
+		// Parse the group dependencies
+		if o := listVal.Filter("depends_on"); len(o.Items) > 0 {
+			if err := parseGroupDependencies(&g.DependsOn, o); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("'%s', depends_on ->", n))
+			}
+		}
+
 		// Parse out meta fields. These are in HCL as a list so we need
 		// to iterate over them and merge them.
 		if metaO := listVal.Filter("meta"); len(metaO.Items) > 0 {
 			for _, o := range metaO.Elem().Items {
 				var m map[string]interface{}
 				if err := hcl.DecodeObject(&m, o.Val); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 				if err := mapstructure.WeakDecode(m, &g.Meta); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 			}
 		}
@@ -378,7 +419,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'restart' block allowed")
+		return posError(list.Items[1], fmt.Errorf("only one 'restart' block allowed"))
 	}
 
 	// Get our job object
@@ -397,7 +438,7 @@ func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 
 	var result api.RestartPolicy
@@ -410,13 +451,39 @@ func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 		return err
 	}
 	if err := dec.Decode(m); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 
 	*final = &result
 	return nil
 }
 
+func parseGroupDependencies(result *[]*api.TaskGroupDependency, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		// Check for invalid keys
+		valid := []string{
+			"group",
+			"condition",
+		}
+		if err := checkHCLKeys(o.Val, valid); err != nil {
+			return err
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return posError(o.Val, err)
+		}
+
+		var d api.TaskGroupDependency
+		if err := mapstructure.WeakDecode(m, &d); err != nil {
+			return posError(o.Val, err)
+		}
+		*result = append(*result, &d)
+	}
+
+	return nil
+}
+
 func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 	for _, o := range list.Elem().Items {
 		// Check for invalid keys
@@ -436,7 +503,7 @@ func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Val); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		m["LTarget"] = m["attribute"]
@@ -467,7 +534,7 @@ func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 		if value, ok := m[structs.ConstraintDistinctHosts]; ok {
 			enabled, err := parseBool(value)
 			if err != nil {
-				return fmt.Errorf("distinct_hosts should be set to true or false; %v", err)
+				return posError(o.Val, fmt.Errorf("distinct_hosts should be set to true or false; %v", err))
 			}
 
 			// If it is not enabled, skip the constraint.
@@ -486,7 +553,7 @@ func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 		// Build the constraint
 		var c api.Constraint
 		if err := mapstructure.WeakDecode(m, &c); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 		if c.Operand == "" {
 			c.Operand = "="
@@ -501,7 +568,7 @@ func parseConstraints(result *[]*api.Constraint, list *ast.ObjectList) error {
 func parseEphemeralDisk(result **api.EphemeralDisk, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'ephemeral_disk' block allowed")
+		return posError(list.Items[1], fmt.Errorf("only one 'ephemeral_disk' block allowed"))
 	}
 
 	// Get our ephemeral_disk object
@@ -519,12 +586,12 @@ func parseEphemeralDisk(result **api.EphemeralDisk, list *ast.ObjectList) error
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 
 	var ephemeralDisk api.EphemeralDisk
 	if err := mapstructure.WeakDecode(m, &ephemeralDisk); err != nil {
-		return err
+		return posError(obj.Val, err)
 	}
 	*result = &ephemeralDisk
 
@@ -561,7 +628,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 
 		// Make sure we haven't already found this
 		if _, ok := seen[n]; ok {
-			return fmt.Errorf("task '%s' defined more than once", n)
+			return posError(item, fmt.Errorf("task '%s' defined more than once", n))
 		}
 		seen[n] = struct{}{}
 
@@ -570,7 +637,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 		if ot, ok := item.Val.(*ast.ObjectType); ok {
 			listVal = ot.List
 		} else {
-			return fmt.Errorf("group '%s': should be an object", n)
+			return posError(item, fmt.Errorf("group '%s': should be an object", n))
 		}
 
 		// Check for invalid keys
@@ -583,6 +650,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			"env",
 			"kill_timeout",
 			"leader",
+			"log_shipper",
 			"logs",
 			"meta",
 			"resources",
@@ -598,13 +666,14 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, item.Val); err != nil {
-			return err
+			return posError(item.Val, err)
 		}
 		delete(m, "artifact")
 		delete(m, "config")
 		delete(m, "constraint")
 		delete(m, "dispatch_payload")
 		delete(m, "env")
+		delete(m, "log_shipper")
 		delete(m, "logs")
 		delete(m, "meta")
 		delete(m, "resources")
@@ -627,7 +696,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			return err
 		}
 		if err := dec.Decode(m); err != nil {
-			return err
+			return posError(item.Val, err)
 		}
 
 		// If we have env, then parse them
@@ -635,10 +704,10 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			for _, o := range o.Elem().Items {
 				var m map[string]interface{}
 				if err := hcl.DecodeObject(&m, o.Val); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 				if err := mapstructure.WeakDecode(m, &t.Env); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 			}
 		}
@@ -654,11 +723,11 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			for _, o := range o.Elem().Items {
 				var m map[string]interface{}
 				if err := hcl.DecodeObject(&m, o.Val); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 
 				if err := mapstructure.WeakDecode(m, &t.Config); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 			}
 		}
@@ -677,10 +746,10 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			for _, o := range metaO.Elem().Items {
 				var m map[string]interface{}
 				if err := hcl.DecodeObject(&m, o.Val); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 				if err := mapstructure.WeakDecode(m, &t.Meta); err != nil {
-					return err
+					return posError(o.Val, err)
 				}
 			}
 		}
@@ -698,32 +767,50 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 		// If we have logs then parse that
 		if o := listVal.Filter("logs"); len(o.Items) > 0 {
 			if len(o.Items) > 1 {
-				return fmt.Errorf("only one logs block is allowed in a Task. Number of logs block found: %d", len(o.Items))
+				return posError(o.Items[1], fmt.Errorf("only one logs block is allowed in a Task. Number of logs block found: %d", len(o.Items)))
 			}
 			var m map[string]interface{}
 			logsBlock := o.Items[0]
 
 			// Check for invalid keys
 			valid := []string{
+				"compress",
 				"max_files",
 				"max_file_size",
+				"max_total_size",
+				"rotation_interval",
 			}
 			if err := checkHCLKeys(logsBlock.Val, valid); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("'%s', logs ->", n))
 			}
 
 			if err := hcl.DecodeObject(&m, logsBlock.Val); err != nil {
-				return err
+				return posError(logsBlock.Val, err)
 			}
 
 			var log api.LogConfig
-			if err := mapstructure.WeakDecode(m, &log); err != nil {
+			dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+				WeaklyTypedInput: true,
+				Result:           &log,
+			})
+			if err != nil {
 				return err
 			}
+			if err := dec.Decode(m); err != nil {
+				return posError(logsBlock.Val, err)
+			}
 
 			t.LogConfig = &log
 		}
 
+		// Parse log shippers
+		if o := listVal.Filter("log_shipper"); len(o.Items) > 0 {
+			if err := parseLogShippers(&t.LogShippers, o); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("'%s', log_shipper ->", n))
+			}
+		}
+
 		// Parse artifacts
 		if o := listVal.Filter("artifact"); len(o.Items) > 0 {
 			if err := parseArtifacts(&t.Artifacts, o); err != nil {
@@ -755,7 +842,7 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 		// If we have a dispatch_payload block parse that
 		if o := listVal.Filter("dispatch_payload"); len(o.Items) > 0 {
 			if len(o.Items) > 1 {
-				return fmt.Errorf("only one dispatch_payload block is allowed in a task. Number of dispatch_payload blocks found: %d", len(o.Items))
+				return posError(o.Items[1], fmt.Errorf("only one dispatch_payload block is allowed in a task. Number of dispatch_payload blocks found: %d", len(o.Items)))
 			}
 			var m map[string]interface{}
 			dispatchBlock := o.Items[0]
@@ -769,12 +856,12 @@ func parseTasks(jobName string, taskGroupName string, result *[]*api.Task, list
 			}
 
 			if err := hcl.DecodeObject(&m, dispatchBlock.Val); err != nil {
-				return err
+				return posError(dispatchBlock.Val, err)
 			}
 
 			t.DispatchPayload = &api.DispatchPayloadConfig{}
 			if err := mapstructure.WeakDecode(m, t.DispatchPayload); err != nil {
-				return err
+				return posError(dispatchBlock.Val, err)
 			}
 		}
 
@@ -799,21 +886,21 @@ func parseArtifacts(result *[]*api.TaskArtifact, list *ast.ObjectList) error {
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Val); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		delete(m, "options")
 
 		var ta api.TaskArtifact
 		if err := mapstructure.WeakDecode(m, &ta); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		var optionList *ast.ObjectList
 		if ot, ok := o.Val.(*ast.ObjectType); ok {
 			optionList = ot.List
 		} else {
-			return fmt.Errorf("artifact should be an object")
+			return posError(o.Val, fmt.Errorf("artifact should be an object"))
 		}
 
 		if oo := optionList.Filter("options"); len(oo.Items) > 0 {
@@ -833,7 +920,7 @@ func parseArtifacts(result *[]*api.TaskArtifact, list *ast.ObjectList) error {
 func parseArtifactOption(result map[string]string, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'options' block allowed per artifact")
+		return posError(list.Items[1], fmt.Errorf("only one 'options' block allowed per artifact"))
 	}
 
 	// Get our resource object
@@ -841,11 +928,11 @@ func parseArtifactOption(result map[string]string, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	if err := mapstructure.WeakDecode(m, &result); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	return nil
@@ -873,7 +960,7 @@ func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
 
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Val); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		templ := &api.Template{
@@ -891,7 +978,7 @@ func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
 			return err
 		}
 		if err := dec.Decode(m); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		*result = append(*result, templ)
@@ -900,6 +987,49 @@ func parseTemplates(result *[]*api.Template, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseLogShippers(result *[]*api.LogShipper, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	for _, item := range list.Items {
+		n := item.Keys[0].Token.Value().(string)
+
+		// Make sure we haven't already found this
+		if _, ok := seen[n]; ok {
+			return posError(item, fmt.Errorf("log_shipper '%s' defined more than once", n))
+		}
+		seen[n] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"address",
+			"buffer_size",
+			"protocol",
+			"type",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s' ->", n))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return posError(item.Val, err)
+		}
+
+		shipper := &api.LogShipper{Name: n}
+		if err := mapstructure.WeakDecode(m, shipper); err != nil {
+			return posError(item.Val, err)
+		}
+
+		*result = append(*result, shipper)
+	}
+
+	return nil
+}
+
 func parseServices(jobName string, taskGroupName string, task *api.Task, serviceObjs *ast.ObjectList) error {
 	task.Services = make([]*api.Service, len(serviceObjs.Items))
 	for idx, o := range serviceObjs.Items {
@@ -918,14 +1048,14 @@ func parseServices(jobName string, taskGroupName string, task *api.Task, service
 		var service api.Service
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Val); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		delete(m, "check")
 		delete(m, "check_restart")
 
 		if err := mapstructure.WeakDecode(m, &service); err != nil {
-			return err
+			return posError(o.Val, err)
 		}
 
 		// Filter checks
@@ -933,7 +1063,7 @@ func parseServices(jobName string, taskGroupName string, task *api.Task, service
 		if ot, ok := o.Val.(*ast.ObjectType); ok {
 			checkList = ot.List
 		} else {
-			return fmt.Errorf("service '%s': should be an object", service.Name)
+			return posError(o.Val, fmt.Errorf("service '%s': should be an object", service.Name))
 		}
 
 		if co := checkList.Filter("check"); len(co.Items) > 0 {
@@ -945,7 +1075,7 @@ func parseServices(jobName string, taskGroupName string, task *api.Task, service
 		// Filter check_restart
 		if cro := checkList.Filter("check_restart"); len(cro.Items) > 0 {
 			if len(cro.Items) > 1 {
-				return fmt.Errorf("check_restart '%s': cannot have more than 1 check_restart", service.Name)
+				return posError(cro.Items[1], fmt.Errorf("check_restart '%s': cannot have more than 1 check_restart", service.Name))
 			}
 			if cr, err := parseCheckRestart(cro.Items[0]); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("service: '%s',", service.Name))
@@ -979,6 +1109,8 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 			"header",
 			"method",
 			"check_restart",
+			"grpc_service",
+			"grpc_use_tls",
 		}
 		if err := checkHCLKeys(co.Val, valid); err != nil {
 			return multierror.Prefix(err, "check ->")
@@ -987,7 +1119,7 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 		var check api.ServiceCheck
 		var cm map[string]interface{}
 		if err := hcl.DecodeObject(&cm, co.Val); err != nil {
-			return err
+			return posError(co.Val, err)
 		}
 
 		// HCL allows repeating stanzas so merge 'header' into a single
@@ -995,19 +1127,19 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 		if headerI, ok := cm["header"]; ok {
 			headerRaw, ok := headerI.([]map[string]interface{})
 			if !ok {
-				return fmt.Errorf("check -> header -> expected a []map[string][]string but found %T", headerI)
+				return posError(co.Val, fmt.Errorf("check -> header -> expected a []map[string][]string but found %T", headerI))
 			}
 			m := map[string][]string{}
 			for _, rawm := range headerRaw {
 				for k, vI := range rawm {
 					vs, ok := vI.([]interface{})
 					if !ok {
-						return fmt.Errorf("check -> header -> %q expected a []string but found %T", k, vI)
+						return posError(co.Val, fmt.Errorf("check -> header -> %q expected a []string but found %T", k, vI))
 					}
 					for _, vI := range vs {
 						v, ok := vI.(string)
 						if !ok {
-							return fmt.Errorf("check -> header -> %q expected a string but found %T", k, vI)
+							return posError(co.Val, fmt.Errorf("check -> header -> %q expected a string but found %T", k, vI))
 						}
 						m[k] = append(m[k], v)
 					}
@@ -1031,7 +1163,7 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 			return err
 		}
 		if err := dec.Decode(cm); err != nil {
-			return err
+			return posError(co.Val, err)
 		}
 
 		// Filter check_restart
@@ -1039,12 +1171,12 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 		if ot, ok := co.Val.(*ast.ObjectType); ok {
 			checkRestartList = ot.List
 		} else {
-			return fmt.Errorf("check_restart '%s': should be an object", check.Name)
+			return posError(co.Val, fmt.Errorf("check_restart '%s': should be an object", check.Name))
 		}
 
 		if cro := checkRestartList.Filter("check_restart"); len(cro.Items) > 0 {
 			if len(cro.Items) > 1 {
-				return fmt.Errorf("check_restart '%s': cannot have more than 1 check_restart", check.Name)
+				return posError(cro.Items[1], fmt.Errorf("check_restart '%s': cannot have more than 1 check_restart", check.Name))
 			}
 			if cr, err := parseCheckRestart(cro.Items[0]); err != nil {
 				return multierror.Prefix(err, fmt.Sprintf("check: '%s',", check.Name))
@@ -1073,7 +1205,7 @@ func parseCheckRestart(cro *ast.ObjectItem) (*api.CheckRestart, error) {
 	var checkRestart api.CheckRestart
 	var crm map[string]interface{}
 	if err := hcl.DecodeObject(&crm, cro.Val); err != nil {
-		return nil, err
+		return nil, posError(cro.Val, err)
 	}
 
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
@@ -1085,7 +1217,7 @@ func parseCheckRestart(cro *ast.ObjectItem) (*api.CheckRestart, error) {
 		return nil, err
 	}
 	if err := dec.Decode(crm); err != nil {
-		return nil, err
+		return nil, posError(cro.Val, err)
 	}
 
 	return &checkRestart, nil
@@ -1097,7 +1229,7 @@ func parseResources(result *api.Resources, list *ast.ObjectList) error {
 		return nil
 	}
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'resource' block allowed per task")
+		return posError(list.Items[1], fmt.Errorf("only one 'resource' block allowed per task"))
 	}
 
 	// Get our resource object
@@ -1108,15 +1240,17 @@ func parseResources(result *api.Resources, list *ast.ObjectList) error {
 	if ot, ok := o.Val.(*ast.ObjectType); ok {
 		listVal = ot.List
 	} else {
-		return fmt.Errorf("resource: should be an object")
+		return posError(o.Val, fmt.Errorf("resource: should be an object"))
 	}
 
 	// Check for invalid keys
 	valid := []string{
 		"cpu",
+		"cores",
 		"iops",
 		"disk",
 		"memory",
+		"memory_max",
 		"network",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
@@ -1125,18 +1259,18 @@ func parseResources(result *api.Resources, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 	delete(m, "network")
 
 	if err := mapstructure.WeakDecode(m, result); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	// Parse the network resources
 	if o := listVal.Filter("network"); len(o.Items) > 0 {
 		if len(o.Items) > 1 {
-			return fmt.Errorf("only one 'network' resource allowed")
+			return posError(o.Items[1], fmt.Errorf("only one 'network' resource allowed"))
 		}
 
 		// Check for invalid keys
@@ -1151,17 +1285,17 @@ func parseResources(result *api.Resources, list *ast.ObjectList) error {
 		var r api.NetworkResource
 		var m map[string]interface{}
 		if err := hcl.DecodeObject(&m, o.Items[0].Val); err != nil {
-			return err
+			return posError(o.Items[0].Val, err)
 		}
 		if err := mapstructure.WeakDecode(m, &r); err != nil {
-			return err
+			return posError(o.Items[0].Val, err)
 		}
 
 		var networkObj *ast.ObjectList
 		if ot, ok := o.Items[0].Val.(*ast.ObjectType); ok {
 			networkObj = ot.List
 		} else {
-			return fmt.Errorf("resource: should be an object")
+			return posError(o.Items[0].Val, fmt.Errorf("resource: should be an object"))
 		}
 		if err := parsePorts(networkObj, &r); err != nil {
 			return multierror.Prefix(err, "resources, network, ports ->")
@@ -1173,37 +1307,69 @@ func parseResources(result *api.Resources, list *ast.ObjectList) error {
 	return nil
 }
 
-func parsePorts(networkObj *ast.ObjectList, nw *api.NetworkResource) error {
+func parseGroupNetwork(result *[]*api.NetworkResource, list *ast.ObjectList) error {
+	if len(list.Items) > 1 {
+		return posError(list.Items[1], fmt.Errorf("only one 'network' block allowed per group"))
+	}
+
 	// Check for invalid keys
 	valid := []string{
+		"mode",
 		"mbits",
 		"port",
 	}
-	if err := checkHCLKeys(networkObj, valid); err != nil {
+	if err := checkHCLKeys(list.Items[0].Val, valid); err != nil {
 		return err
 	}
 
+	var r api.NetworkResource
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, list.Items[0].Val); err != nil {
+		return posError(list.Items[0].Val, err)
+	}
+	delete(m, "port")
+	if err := mapstructure.WeakDecode(m, &r); err != nil {
+		return posError(list.Items[0].Val, err)
+	}
+
+	var networkObj *ast.ObjectList
+	if ot, ok := list.Items[0].Val.(*ast.ObjectType); ok {
+		networkObj = ot.List
+	} else {
+		return posError(list.Items[0].Val, fmt.Errorf("network: should be an object"))
+	}
+	if err := parsePorts(networkObj, &r); err != nil {
+		return multierror.Prefix(err, "ports ->")
+	}
+
+	*result = []*api.NetworkResource{&r}
+	return nil
+}
+
+// parsePorts parses the ports of a network block. The keys of the block are
+// checked by the caller as task and group networks accept different keys.
+func parsePorts(networkObj *ast.ObjectList, nw *api.NetworkResource) error {
 	portsObjList := networkObj.Filter("port")
 	knownPortLabels := make(map[string]bool)
 	for _, port := range portsObjList.Items {
 		if len(port.Keys) == 0 {
-			return fmt.Errorf("ports must be named")
+			return posError(port, fmt.Errorf("ports must be named"))
 		}
 		label := port.Keys[0].Token.Value().(string)
 		if !reDynamicPorts.MatchString(label) {
-			return errPortLabel
+			return posError(port, errPortLabel)
 		}
 		l := strings.ToLower(label)
 		if knownPortLabels[l] {
-			return fmt.Errorf("found a port label collision: %s", label)
+			return posError(port, fmt.Errorf("found a port label collision: %s", label))
 		}
 		var p map[string]interface{}
 		var res api.Port
 		if err := hcl.DecodeObject(&p, port.Val); err != nil {
-			return err
+			return posError(port.Val, err)
 		}
 		if err := mapstructure.WeakDecode(p, &res); err != nil {
-			return err
+			return posError(port.Val, err)
 		}
 		res.Label = label
 		if res.Value > 0 {
@@ -1219,7 +1385,7 @@ func parsePorts(networkObj *ast.ObjectList, nw *api.NetworkResource) error {
 func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'update' block allowed")
+		return posError(list.Items[1], fmt.Errorf("only one 'update' block allowed"))
 	}
 
 	// Get our resource object
@@ -1227,7 +1393,7 @@ func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	// Check for invalid keys
@@ -1253,13 +1419,13 @@ func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 	if err != nil {
 		return err
 	}
-	return dec.Decode(m)
+	return posError(o.Val, dec.Decode(m))
 }
 
 func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'periodic' block allowed per job")
+		return posError(list.Items[1], fmt.Errorf("only one 'periodic' block allowed per job"))
 	}
 
 	// Get our resource object
@@ -1267,15 +1433,19 @@ func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	// Check for invalid keys
 	valid := []string{
 		"enabled",
 		"cron",
+		"crons",
 		"prohibit_overlap",
 		"time_zone",
+		"catch_up",
+		"catch_up_window",
+		"jitter",
 	}
 	if err := checkHCLKeys(o.Val, valid); err != nil {
 		return err
@@ -1284,7 +1454,7 @@ func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 	if value, ok := m["enabled"]; ok {
 		enabled, err := parseBool(value)
 		if err != nil {
-			return fmt.Errorf("periodic.enabled should be set to true or false; %v", err)
+			return posError(o.Val, fmt.Errorf("periodic.enabled should be set to true or false; %v", err))
 		}
 		m["Enabled"] = enabled
 	}
@@ -1295,11 +1465,25 @@ func parsePeriodic(result **api.PeriodicConfig, list *ast.ObjectList) error {
 		m["Spec"] = cron
 	}
 
+	// If "crons" is provided, set the type to "cron" and store the specs.
+	if crons, ok := m["crons"]; ok {
+		m["SpecType"] = structs.PeriodicSpecCron
+		m["Specs"] = crons
+	}
+
 	// Build the constraint
 	var p api.PeriodicConfig
-	if err := mapstructure.WeakDecode(m, &p); err != nil {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &p,
+	})
+	if err != nil {
 		return err
 	}
+	if err := dec.Decode(m); err != nil {
+		return posError(o.Val, err)
+	}
 	*result = &p
 	return nil
 }
@@ -1310,7 +1494,7 @@ func parseVault(result *api.Vault, list *ast.ObjectList) error {
 		return nil
 	}
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'vault' block allowed per task")
+		return posError(list.Items[1], fmt.Errorf("only one 'vault' block allowed per task"))
 	}
 
 	// Get our resource object
@@ -1321,12 +1505,15 @@ func parseVault(result *api.Vault, list *ast.ObjectList) error {
 	if ot, ok := o.Val.(*ast.ObjectType); ok {
 		listVal = ot.List
 	} else {
-		return fmt.Errorf("vault: should be an object")
+		return posError(o.Val, fmt.Errorf("vault: should be an object"))
 	}
 
 	// Check for invalid keys
 	valid := []string{
 		"policies",
+		"role",
+		"entity_alias",
+		"ttl",
 		"env",
 		"change_mode",
 		"change_signal",
@@ -1337,12 +1524,20 @@ func parseVault(result *api.Vault, list *ast.ObjectList) error {
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
-	if err := mapstructure.WeakDecode(m, result); err != nil {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           result,
+	})
+	if err != nil {
 		return err
 	}
+	if err := dec.Decode(m); err != nil {
+		return posError(o.Val, err)
+	}
 
 	return nil
 }
@@ -1350,7 +1545,7 @@ func parseVault(result *api.Vault, list *ast.ObjectList) error {
 func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
-		return fmt.Errorf("only one 'parameterized' block allowed per job")
+		return posError(list.Items[1], fmt.Errorf("only one 'parameterized' block allowed per job"))
 	}
 
 	// Get our resource object
@@ -1358,7 +1553,7 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 
 	var m map[string]interface{}
 	if err := hcl.DecodeObject(&m, o.Val); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	// Check for invalid keys
@@ -1366,6 +1561,7 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 		"payload",
 		"meta_required",
 		"meta_optional",
+		"max_running",
 	}
 	if err := checkHCLKeys(o.Val, valid); err != nil {
 		return err
@@ -1374,7 +1570,7 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 	// Build the parameterized job block
 	var d api.ParameterizedJobConfig
 	if err := mapstructure.WeakDecode(m, &d); err != nil {
-		return err
+		return posError(o.Val, err)
 	}
 
 	*result = &d
@@ -1401,10 +1597,59 @@ func checkHCLKeys(node ast.Node, valid []string) error {
 	for _, item := range list.Items {
 		key := item.Keys[0].Token.Value().(string)
 		if _, ok := validMap[key]; !ok {
-			result = multierror.Append(result, fmt.Errorf(
-				"invalid key: %s", key))
+			result = multierror.Append(result, posError(item, fmt.Errorf(
+				"invalid key: %s", key)))
 		}
 	}
 
 	return result
 }
+
+// posError prefixes the error with the position of the node it occurred at.
+// The position is only included if the file name is known, as it is for job
+// specs parsed with HCL2, so errors of HCL1 job specs are unchanged. Errors
+// decoding the attributes of a block are given the position of the attribute
+// they name.
+func posError(node ast.Node, err error) error {
+	if err == nil || node.Pos().Filename == "" {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *parser.PosError:
+		// The HCL decoder already included the position
+		return err
+	case *mapstructure.Error:
+		var result error
+		for _, msg := range e.Errors {
+			result = multierror.Append(result, fmt.Errorf("%s: %s", attributePos(node, msg), msg))
+		}
+		return result
+	}
+
+	return fmt.Errorf("%s: %v", node.Pos(), err)
+}
+
+// attributePos returns the position of the attribute of the block named by
+// the mapstructure error message, such as "cannot parse 'Count' as int", or
+// the position of the block if no attribute matches.
+func attributePos(node ast.Node, msg string) token.Pos {
+	ot, ok := node.(*ast.ObjectType)
+	match := reQuotedField.FindStringSubmatch(msg)
+	if !ok || match == nil {
+		return node.Pos()
+	}
+
+	// Field names are matched case insensitively and nested fields are
+	// attributed to their top level attribute.
+	name := match[1]
+	if i := strings.IndexAny(name, ".["); i != -1 {
+		name = name[:i]
+	}
+	for _, item := range ot.List.Items {
+		if len(item.Keys) != 0 && strings.EqualFold(item.Keys[0].Token.Value().(string), name) {
+			return item.Pos()
+		}
+	}
+	return node.Pos()
+}
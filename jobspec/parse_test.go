@@ -24,15 +24,18 @@ func TestParse(t *testing.T) {
 		{
 			"basic.hcl",
 			&api.Job{
-				ID:          helper.StringToPtr("binstore-storagelocker"),
-				Name:        helper.StringToPtr("binstore-storagelocker"),
-				Type:        helper.StringToPtr("batch"),
-				Priority:    helper.IntToPtr(52),
-				AllAtOnce:   helper.BoolToPtr(true),
-				Datacenters: []string{"us2", "eu1"},
-				Region:      helper.StringToPtr("fooregion"),
-				Namespace:   helper.StringToPtr("foonamespace"),
-				VaultToken:  helper.StringToPtr("foo"),
+				ID:                 helper.StringToPtr("binstore-storagelocker"),
+				Name:               helper.StringToPtr("binstore-storagelocker"),
+				Type:               helper.StringToPtr("batch"),
+				Priority:           helper.IntToPtr(52),
+				AllAtOnce:          helper.BoolToPtr(true),
+				Datacenters:        []string{"us2", "eu1"},
+				NodePool:           helper.StringToPtr("gpu"),
+				SchedulerAlgorithm: helper.StringToPtr("spread"),
+				Region:             helper.StringToPtr("fooregion"),
+				Namespace:          helper.StringToPtr("foonamespace"),
+				VaultToken:         helper.StringToPtr("foo"),
+				ConsulToken:        helper.StringToPtr("abc"),
 
 				Meta: map[string]string{
 					"foo": "bar",
@@ -144,8 +147,9 @@ func TestParse(t *testing.T) {
 									"LOREM": "ipsum",
 								},
 								Resources: &api.Resources{
-									CPU:      helper.IntToPtr(500),
-									MemoryMB: helper.IntToPtr(128),
+									CPU:         helper.IntToPtr(500),
+									MemoryMB:    helper.IntToPtr(128),
+									MemoryMaxMB: helper.IntToPtr(256),
 									Networks: []*api.NetworkResource{
 										{
 											MBits:         helper.IntToPtr(100),
@@ -225,6 +229,9 @@ func TestParse(t *testing.T) {
 								},
 								Vault: &api.Vault{
 									Policies:     []string{"foo", "bar"},
+									Role:         helper.StringToPtr("nomad-storage"),
+									EntityAlias:  helper.StringToPtr("${NOMAD_JOB_ID}-${NOMAD_TASK_NAME}"),
+									TTL:          helper.TimeToPtr(time.Hour),
 									Env:          helper.BoolToPtr(false),
 									ChangeMode:   helper.StringToPtr(structs.VaultChangeModeSignal),
 									ChangeSignal: helper.StringToPtr("SIGUSR1"),
@@ -356,6 +363,22 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"periodic-crons.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("foo"),
+				Name: helper.StringToPtr("foo"),
+				Periodic: &api.PeriodicConfig{
+					SpecType:      helper.StringToPtr(api.PeriodicSpecCron),
+					Specs:         []string{"*/5 * * *", "30 2 * * *"},
+					CatchUp:       helper.StringToPtr(api.PeriodicCatchUpAll),
+					CatchUpWindow: helper.TimeToPtr(6 * time.Hour),
+					Jitter:        helper.TimeToPtr(30 * time.Second),
+				},
+			},
+			false,
+		},
+
 		{
 			"specify-job.hcl",
 			&api.Job{
@@ -475,6 +498,42 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"service-check-grpc.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("check_grpc"),
+				Name: helper.StringToPtr("check_grpc"),
+				Type: helper.StringToPtr("service"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name:  helper.StringToPtr("group"),
+						Count: helper.IntToPtr(1),
+						Tasks: []*api.Task{
+							{
+								Name: "task",
+								Services: []*api.Service{
+									{
+										Tags:      []string{"foo", "bar"},
+										PortLabel: "grpc",
+										Checks: []api.ServiceCheck{
+											{
+												Name:        "check-name",
+												Type:        "grpc",
+												Interval:    10 * time.Second,
+												Timeout:     2 * time.Second,
+												GRPCService: "foo.Bar",
+												GRPCUseTLS:  true,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			"service-check-bad-header.hcl",
 			nil,
@@ -485,6 +544,195 @@ func TestParse(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"tg-network.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("foo"),
+				Name: helper.StringToPtr("foo"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("bar"),
+						Networks: []*api.NetworkResource{
+							{
+								Mode:  "bridge",
+								MBits: helper.IntToPtr(10),
+								ReservedPorts: []api.Port{
+									{
+										Label: "http",
+										Value: 80,
+									},
+								},
+								DynamicPorts: []api.Port{
+									{
+										Label: "admin",
+									},
+								},
+							},
+						},
+						Tasks: []*api.Task{
+							{
+								Name:   "bar",
+								Driver: "raw_exec",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"tg-depends-on.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("app"),
+				Name: helper.StringToPtr("app"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("db"),
+						Tasks: []*api.Task{
+							{
+								Name:   "db",
+								Driver: "docker",
+							},
+						},
+					},
+					{
+						Name: helper.StringToPtr("migrate"),
+						DependsOn: []*api.TaskGroupDependency{
+							{
+								Group:     helper.StringToPtr("db"),
+								Condition: helper.StringToPtr("healthy"),
+							},
+						},
+						Tasks: []*api.Task{
+							{
+								Name:   "migrate",
+								Driver: "docker",
+							},
+						},
+					},
+					{
+						Name: helper.StringToPtr("web"),
+						DependsOn: []*api.TaskGroupDependency{
+							{
+								Group: helper.StringToPtr("db"),
+							},
+							{
+								Group:     helper.StringToPtr("migrate"),
+								Condition: helper.StringToPtr("complete"),
+							},
+						},
+						Tasks: []*api.Task{
+							{
+								Name:   "web",
+								Driver: "docker",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"tg-max-client-disconnect.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("cache"),
+				Name: helper.StringToPtr("cache"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name:                helper.StringToPtr("redis"),
+						MaxClientDisconnect: helper.TimeToPtr(10 * time.Minute),
+						Tasks: []*api.Task{
+							{
+								Name:   "redis",
+								Driver: "docker",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"resources-cores.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("binstore"),
+				Name: helper.StringToPtr("binstore"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("binsl"),
+						Tasks: []*api.Task{
+							{
+								Name:   "binstore",
+								Driver: "docker",
+								Resources: &api.Resources{
+									Cores:    helper.IntToPtr(2),
+									MemoryMB: helper.IntToPtr(512),
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"log-shipper.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("binstore"),
+				Name: helper.StringToPtr("binstore"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("binsl"),
+						Tasks: []*api.Task{
+							{
+								Name:   "binstore",
+								Driver: "exec",
+								LogShippers: []*api.LogShipper{
+									{
+										Name:       "audit",
+										Type:       "syslog",
+										Address:    helper.StringToPtr("10.0.0.1:514"),
+										Protocol:   helper.StringToPtr("udp"),
+										BufferSize: helper.IntToPtr(512),
+									},
+									{
+										Name: "journal",
+										Type: "socket",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"log-rotation.hcl",
+			&api.Job{
+				ID:   helper.StringToPtr("binstore"),
+				Name: helper.StringToPtr("binstore"),
+				TaskGroups: []*api.TaskGroup{
+					{
+						Name: helper.StringToPtr("binsl"),
+						Tasks: []*api.Task{
+							{
+								Name:   "binstore",
+								Driver: "exec",
+								LogConfig: &api.LogConfig{
+									MaxFiles:         helper.IntToPtr(5),
+									MaxFileSizeMB:    helper.IntToPtr(20),
+									RotationInterval: helper.TimeToPtr(time.Hour),
+									Compress:         helper.BoolToPtr(true),
+									MaxTotalSizeMB:   helper.IntToPtr(80),
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			// TODO This should be pushed into the API
 			"vault_inheritance.hcl",
@@ -540,6 +788,7 @@ func TestParse(t *testing.T) {
 					Payload:      "required",
 					MetaRequired: []string{"foo", "bar"},
 					MetaOptional: []string{"baz", "bam"},
+					MaxRunning:   5,
 				},
 
 				TaskGroups: []*api.TaskGroup{
@@ -615,6 +864,24 @@ func TestOverlappingPorts(t *testing.T) {
 	}
 }
 
+func TestTaskNetworkMode(t *testing.T) {
+	path, err := filepath.Abs(filepath.Join("./test-fixtures", "task-network-mode.hcl"))
+	if err != nil {
+		t.Fatalf("Can't get absolute path for file: %s", err)
+	}
+
+	_, err = ParseFile(path)
+
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	// The network mode is only supported by group networks
+	if !strings.Contains(err.Error(), "resources, network -> invalid key: mode") {
+		t.Fatalf("Expected key error; got %v", err)
+	}
+}
+
 func TestIncorrectKey(t *testing.T) {
 	path, err := filepath.Abs(filepath.Join("./test-fixtures", "basic_wrong_key.hcl"))
 	if err != nil {
@@ -1,11 +1,14 @@
 job "binstore-storagelocker" {
-  region      = "fooregion"
-  namespace   = "foonamespace"
-  type        = "batch"
-  priority    = 52
-  all_at_once = true
-  datacenters = ["us2", "eu1"]
-  vault_token = "foo"
+  region              = "fooregion"
+  namespace           = "foonamespace"
+  type                = "batch"
+  priority            = 52
+  all_at_once         = true
+  datacenters         = ["us2", "eu1"]
+  node_pool           = "gpu"
+  scheduler_algorithm = "spread"
+  vault_token         = "foo"
+  consul_token        = "abc"
 
   meta {
     foo = "bar"
@@ -105,8 +108,9 @@ job "binstore-storagelocker" {
       }
 
       resources {
-        cpu    = 500
-        memory = 128
+        cpu        = 500
+        memory     = 128
+        memory_max = 256
 
         network {
           mbits = "100"
@@ -199,6 +203,9 @@ job "binstore-storagelocker" {
 
       vault {
         policies = ["foo", "bar"]
+        role = "nomad-storage"
+        entity_alias = "${NOMAD_JOB_ID}-${NOMAD_TASK_NAME}"
+        ttl = "1h"
         env = false
         change_mode = "signal"
         change_signal = "SIGUSR1"
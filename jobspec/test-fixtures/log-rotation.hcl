@@ -0,0 +1,15 @@
+job "binstore" {
+  group "binsl" {
+    task "binstore" {
+      driver = "exec"
+
+      logs {
+        max_files         = 5
+        max_file_size     = 20
+        rotation_interval = "1h"
+        compress          = true
+        max_total_size    = 80
+      }
+    }
+  }
+}
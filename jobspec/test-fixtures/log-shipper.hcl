@@ -0,0 +1,18 @@
+job "binstore" {
+  group "binsl" {
+    task "binstore" {
+      driver = "exec"
+
+      log_shipper "audit" {
+        type        = "syslog"
+        address     = "10.0.0.1:514"
+        protocol    = "udp"
+        buffer_size = 512
+      }
+
+      log_shipper "journal" {
+        type = "socket"
+      }
+    }
+  }
+}
@@ -0,0 +1,8 @@
+job "foo" {
+    periodic {
+        crons = ["*/5 * * *", "30 2 * * *"]
+        catch_up = "all"
+        catch_up_window = "6h"
+        jitter = "30s"
+    }
+}
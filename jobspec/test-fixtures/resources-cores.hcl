@@ -0,0 +1,12 @@
+job "binstore" {
+  group "binsl" {
+    task "binstore" {
+      driver = "docker"
+
+      resources {
+        cores  = 2
+        memory = 512
+      }
+    }
+  }
+}
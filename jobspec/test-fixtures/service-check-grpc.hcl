@@ -0,0 +1,22 @@
+job "check_grpc" {
+    type = "service"
+    group "group" {
+        count = 1
+
+        task "task" {
+          service {
+            tags = ["foo", "bar"]
+            port = "grpc"
+
+            check {
+              name         = "check-name"
+              type         = "grpc"
+              interval     = "10s"
+              timeout      = "2s"
+              grpc_service = "foo.Bar"
+              grpc_use_tls = true
+            }
+          }
+        }
+    }
+}
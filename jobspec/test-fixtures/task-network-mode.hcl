@@ -0,0 +1,14 @@
+job "foo" {
+  group "bar" {
+    task "bar" {
+      driver = "raw_exec"
+
+      resources {
+        network {
+          mode  = "bridge"
+          mbits = 10
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,33 @@
+job "app" {
+  group "db" {
+    task "db" {
+      driver = "docker"
+    }
+  }
+
+  group "migrate" {
+    depends_on {
+      group     = "db"
+      condition = "healthy"
+    }
+
+    task "migrate" {
+      driver = "docker"
+    }
+  }
+
+  group "web" {
+    depends_on {
+      group = "db"
+    }
+
+    depends_on {
+      group     = "migrate"
+      condition = "complete"
+    }
+
+    task "web" {
+      driver = "docker"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+job "cache" {
+  group "redis" {
+    max_client_disconnect = "10m"
+
+    task "redis" {
+      driver = "docker"
+    }
+  }
+}
@@ -0,0 +1,18 @@
+job "foo" {
+  group "bar" {
+    network {
+      mode  = "bridge"
+      mbits = 10
+
+      port "http" {
+        static = 80
+      }
+
+      port "admin" {}
+    }
+
+    task "bar" {
+      driver = "raw_exec"
+    }
+  }
+}
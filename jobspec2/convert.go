@@ -0,0 +1,488 @@
+package jobspec2
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+var dynamicSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "for_each", Required: true},
+		{Name: "iterator"},
+		{Name: "labels"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "content"},
+	},
+}
+
+// evalLocals evaluates the local values of the locals blocks. Local values may
+// refer to variables and to each other in any order.
+func (p *parser) evalLocals(blocks hcl.Blocks, ctx *hcl.EvalContext) (map[string]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	pending := make(map[string]*hcl.Attribute)
+	for _, block := range blocks {
+		attrs, moreDiags := block.Body.JustAttributes()
+		diags = append(diags, moreDiags...)
+		for name, attr := range attrs {
+			if existing, ok := pending[name]; ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate local value definition",
+					Detail:   fmt.Sprintf("A local value named %q was already defined at %s.", name, existing.Range),
+					Subject:  &attr.NameRange,
+				})
+				continue
+			}
+			pending[name] = attr
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	locals := make(map[string]cty.Value, len(pending))
+	for len(pending) != 0 {
+		// Evaluate the local values whose dependencies are known, in a stable
+		// order so that diagnostics are deterministic
+		var ready []*hcl.Attribute
+		for _, attr := range pending {
+			if !dependsOnPending(attr.Expr, pending) {
+				ready = append(ready, attr)
+			}
+		}
+		if len(ready) == 0 {
+			cyclic := make([]*hcl.Attribute, 0, len(pending))
+			for _, attr := range pending {
+				cyclic = append(cyclic, attr)
+			}
+			sortAttributes(cyclic)
+			for _, attr := range cyclic {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Cycle in local values",
+					Detail:   fmt.Sprintf("The local value %q depends on itself through other local values.", attr.Name),
+					Subject:  &attr.NameRange,
+				})
+			}
+			return nil, diags
+		}
+
+		sortAttributes(ready)
+		for _, attr := range ready {
+			val, moreDiags := p.evalExpr(attr.Expr, ctx)
+			diags = append(diags, moreDiags...)
+			locals[attr.Name] = val
+			delete(pending, attr.Name)
+		}
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+	}
+
+	return locals, diags
+}
+
+// dependsOnPending returns whether the expression refers to local values that
+// are not yet evaluated.
+func dependsOnPending(expr hcl.Expression, pending map[string]*hcl.Attribute) bool {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "local" || len(traversal) < 2 {
+			continue
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+			if _, ok := pending[attr.Name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evalExpr evaluates the expression. Traversals rooted at names other than
+// those of the evaluation context, such as ${NOMAD_ALLOC_INDEX} or
+// ${attr.kernel.name}, are runtime interpolations resolved by the client, so
+// they evaluate to their own source to be preserved in the job.
+func (p *parser) evalExpr(expr hcl.Expression, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	runtime := make(map[string]cty.Value)
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		if hasVariable(ctx, root) {
+			continue
+		}
+
+		leaf := cty.StringVal("${" + string(traversal.SourceRange().SliceBytes(p.src)) + "}")
+		if val := insertRuntime(runtime[root], traversal[1:], leaf); val != cty.NilVal {
+			runtime[root] = val
+		}
+	}
+	if len(runtime) != 0 {
+		ctx = ctx.NewChild()
+		ctx.Variables = runtime
+	}
+
+	return expr.Value(ctx)
+}
+
+// hasVariable returns whether the variable is defined in the evaluation
+// context or one of its parents.
+func hasVariable(ctx *hcl.EvalContext, name string) bool {
+	for ; ctx != nil; ctx = ctx.Parent() {
+		if _, ok := ctx.Variables[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// insertRuntime inserts the leaf value in the object at the path of the
+// traversal steps, returning cty.NilVal if the path cannot be represented.
+func insertRuntime(obj cty.Value, steps hcl.Traversal, leaf cty.Value) cty.Value {
+	if len(steps) == 0 {
+		if obj != cty.NilVal {
+			// A longer traversal with the same prefix is already inserted
+			return obj
+		}
+		return leaf
+	}
+
+	var name string
+	switch step := steps[0].(type) {
+	case hcl.TraverseAttr:
+		name = step.Name
+	case hcl.TraverseIndex:
+		if step.Key.Type() != cty.String {
+			return cty.NilVal
+		}
+		name = step.Key.AsString()
+	default:
+		return cty.NilVal
+	}
+
+	attrs := make(map[string]cty.Value)
+	if obj != cty.NilVal {
+		if !obj.Type().IsObjectType() {
+			// A shorter traversal with the same prefix is already inserted
+			return obj
+		}
+		attrs = obj.AsValueMap()
+	}
+
+	val := insertRuntime(attrs[name], steps[1:], leaf)
+	if val == cty.NilVal {
+		return cty.NilVal
+	}
+	attrs[name] = val
+	return cty.ObjectVal(attrs)
+}
+
+// convertBlocks converts the blocks into items of an HCL1 object list.
+func (p *parser) convertBlocks(blocks hcl.Blocks, ctx *hcl.EvalContext) (*ast.ObjectList, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	list := &ast.ObjectList{}
+	for _, block := range blocks {
+		body, moreDiags := p.convertBody(block.Body.(*hclsyntax.Body), ctx)
+		diags = append(diags, moreDiags...)
+		list.Add(blockItem(block.Type, block.Labels, block.DefRange, body))
+	}
+	return list, diags
+}
+
+// convertBody evaluates the attributes and expands the dynamic blocks of the
+// body, converting it to an HCL1 object list. Items keep their order in the
+// source.
+func (p *parser) convertBody(body *hclsyntax.Body, ctx *hcl.EvalContext) (*ast.ObjectList, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	type sourceItem struct {
+		pos   int
+		items []*ast.ObjectItem
+	}
+	var sourceItems []sourceItem
+
+	for _, attr := range body.Attributes {
+		val, moreDiags := p.evalExpr(attr.Expr, ctx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() || val.IsNull() {
+			continue
+		}
+
+		node, moreDiags := convertValue(val, attr.Expr.Range())
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		item := &ast.ObjectItem{
+			Keys:   []*ast.ObjectKey{{Token: identToken(attr.Name, attr.NameRange)}},
+			Assign: rangePos(attr.EqualsRange),
+			Val:    node,
+		}
+		sourceItems = append(sourceItems, sourceItem{attr.SrcRange.Start.Byte, []*ast.ObjectItem{item}})
+	}
+
+	for _, block := range body.Blocks {
+		var items []*ast.ObjectItem
+		if block.Type == "dynamic" {
+			var moreDiags hcl.Diagnostics
+			items, moreDiags = p.expandDynamic(block, ctx)
+			diags = append(diags, moreDiags...)
+		} else {
+			list, moreDiags := p.convertBody(block.Body, ctx)
+			diags = append(diags, moreDiags...)
+			items = append(items, blockItem(block.Type, block.Labels, block.DefRange(), list))
+		}
+		sourceItems = append(sourceItems, sourceItem{block.TypeRange.Start.Byte, items})
+	}
+
+	sort.Slice(sourceItems, func(i, j int) bool {
+		return sourceItems[i].pos < sourceItems[j].pos
+	})
+
+	list := &ast.ObjectList{}
+	for _, si := range sourceItems {
+		for _, item := range si.items {
+			list.Add(item)
+		}
+	}
+	return list, diags
+}
+
+// expandDynamic expands a dynamic block into a block for each element of its
+// for_each collection.
+func (p *parser) expandDynamic(block *hclsyntax.Block, ctx *hcl.EvalContext) ([]*ast.ObjectItem, hcl.Diagnostics) {
+	if len(block.Labels) != 1 {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid dynamic block",
+			Detail:   "A dynamic block must have a single label, the type of the blocks to generate.",
+			Subject:  block.DefRange().Ptr(),
+		}}
+	}
+	blockType := block.Labels[0]
+
+	content, diags := block.Body.Content(dynamicSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	contentBlocks := content.Blocks.OfType("content")
+	if len(contentBlocks) != 1 {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid dynamic block",
+			Detail:   "A dynamic block must have exactly one content block.",
+			Subject:  block.DefRange().Ptr(),
+		})
+	}
+	contentBody := contentBlocks[0].Body.(*hclsyntax.Body)
+
+	iterator := blockType
+	if attr, ok := content.Attributes["iterator"]; ok {
+		traversal, moreDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+		if len(traversal) != 1 {
+			return nil, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid dynamic iterator name",
+				Detail:   "The iterator must be a single name.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+		iterator = traversal.RootName()
+	}
+
+	forEachAttr := content.Attributes["for_each"]
+	forEach, moreDiags := p.evalExpr(forEachAttr.Expr, ctx)
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+	if forEach.IsNull() || !forEach.IsKnown() || !forEach.CanIterateElements() {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid dynamic for_each value",
+			Detail:   fmt.Sprintf("Cannot use a %s value in for_each. A list, set, map or object is required.", forEach.Type().FriendlyName()),
+			Subject:  forEachAttr.Expr.Range().Ptr(),
+		})
+	}
+
+	var items []*ast.ObjectItem
+	for it := forEach.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		child := ctx.NewChild()
+		child.Variables = map[string]cty.Value{
+			iterator: cty.ObjectVal(map[string]cty.Value{
+				"key":   key,
+				"value": value,
+			}),
+		}
+
+		var labels []string
+		if attr, ok := content.Attributes["labels"]; ok {
+			val, moreDiags := p.evalExpr(attr.Expr, child)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			val, err := convert.Convert(val, cty.List(cty.String))
+			if err != nil || val.IsNull() || !val.IsWhollyKnown() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid dynamic block labels",
+					Detail:   "The labels must be a list of strings.",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+				continue
+			}
+			for _, label := range val.AsValueSlice() {
+				labels = append(labels, label.AsString())
+			}
+		}
+
+		list, moreDiags := p.convertBody(contentBody, child)
+		diags = append(diags, moreDiags...)
+		items = append(items, blockItem(blockType, labels, block.DefRange(), list))
+	}
+	return items, diags
+}
+
+// blockItem returns the HCL1 item of a block.
+func blockItem(blockType string, labels []string, rng hcl.Range, list *ast.ObjectList) *ast.ObjectItem {
+	keys := []*ast.ObjectKey{{Token: identToken(blockType, rng)}}
+	for _, label := range labels {
+		keys = append(keys, &ast.ObjectKey{Token: stringToken(label, rng)})
+	}
+	return &ast.ObjectItem{
+		Keys: keys,
+		Val: &ast.ObjectType{
+			Lbrace: rangePos(rng),
+			List:   list,
+		},
+	}
+}
+
+// convertValue converts an evaluated value to an HCL1 node. Null elements are
+// omitted.
+func convertValue(val cty.Value, rng hcl.Range) (ast.Node, hcl.Diagnostics) {
+	if !val.IsWhollyKnown() {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown value",
+			Detail:   "The value of this expression cannot be determined.",
+			Subject:  rng.Ptr(),
+		}}
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return &ast.LiteralType{Token: stringToken(val.AsString(), rng)}, nil
+	case ty == cty.Bool:
+		return &ast.LiteralType{Token: token.Token{
+			Type: token.BOOL,
+			Pos:  rangePos(rng),
+			Text: strconv.FormatBool(val.True()),
+		}}, nil
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		if i, acc := bf.Int64(); bf.IsInt() && acc == 0 {
+			return &ast.LiteralType{Token: token.Token{
+				Type: token.NUMBER,
+				Pos:  rangePos(rng),
+				Text: strconv.FormatInt(i, 10),
+			}}, nil
+		}
+		f, _ := bf.Float64()
+		return &ast.LiteralType{Token: token.Token{
+			Type: token.FLOAT,
+			Pos:  rangePos(rng),
+			Text: strconv.FormatFloat(f, 'g', -1, 64),
+		}}, nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var diags hcl.Diagnostics
+		list := &ast.ListType{Lbrack: rangePos(rng)}
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			if elem.IsNull() {
+				continue
+			}
+			node, moreDiags := convertValue(elem, rng)
+			diags = append(diags, moreDiags...)
+			if node != nil {
+				list.Add(node)
+			}
+		}
+		return list, diags
+	case ty.IsMapType() || ty.IsObjectType():
+		var diags hcl.Diagnostics
+		list := &ast.ObjectList{}
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			if elem.IsNull() {
+				continue
+			}
+			node, moreDiags := convertValue(elem, rng)
+			diags = append(diags, moreDiags...)
+			if node != nil {
+				list.Add(&ast.ObjectItem{
+					Keys: []*ast.ObjectKey{{Token: stringToken(key.AsString(), rng)}},
+					Val:  node,
+				})
+			}
+		}
+		return &ast.ObjectType{Lbrace: rangePos(rng), List: list}, diags
+	default:
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported value",
+			Detail:   fmt.Sprintf("A %s value cannot be used in a job.", ty.FriendlyName()),
+			Subject:  rng.Ptr(),
+		}}
+	}
+}
+
+// identToken returns an HCL1 identifier token.
+func identToken(name string, rng hcl.Range) token.Token {
+	return token.Token{
+		Type: token.IDENT,
+		Pos:  rangePos(rng),
+		Text: name,
+	}
+}
+
+// stringToken returns an HCL1 string token. The string is quoted as JSON so
+// that it is not subject to the escaping of HCL1 interpolations.
+func stringToken(s string, rng hcl.Range) token.Token {
+	return token.Token{
+		Type: token.STRING,
+		Pos:  rangePos(rng),
+		Text: strconv.Quote(s),
+		JSON: true,
+	}
+}
+
+// rangePos returns the HCL1 position of the start of the range.
+func rangePos(rng hcl.Range) token.Pos {
+	return token.Pos{
+		Filename: rng.Filename,
+		Offset:   rng.Start.Byte,
+		Line:     rng.Start.Line,
+		Column:   rng.Start.Column,
+	}
+}
+
+// sortAttributes sorts the attributes by their position in the source.
+func sortAttributes(attrs []*hcl.Attribute) {
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Range.Start.Byte < attrs[j].Range.Start.Byte
+	})
+}
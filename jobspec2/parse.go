@@ -0,0 +1,137 @@
+package jobspec2
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/jobspec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ParseConfig configures the parsing of a job spec.
+type ParseConfig struct {
+	// Path is the path of the job file. It is used in diagnostics and to
+	// resolve relative paths.
+	Path string
+
+	// Body is the contents of the job file.
+	Body []byte
+
+	// ArgVars are the values of input variables given on the command line,
+	// in the name=value format.
+	ArgVars []string
+
+	// VarFiles are the paths of files setting the values of input variables.
+	VarFiles []string
+
+	// Envs are the environment variables in the KEY=value format. Variables
+	// prefixed with NOMAD_VAR_ set the value of input variables.
+	Envs []string
+}
+
+var rootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "job", LabelNames: []string{"name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// Parse parses the HCL2 job spec from the given io.Reader. The path is only
+// used in diagnostics.
+//
+// Due to current internal limitations, the entire contents of the
+// io.Reader will be copied into memory first before parsing.
+func Parse(path string, r io.Reader) (*api.Job, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+
+	return ParseWithConfig(&ParseConfig{
+		Path: path,
+		Body: buf.Bytes(),
+	})
+}
+
+// ParseFile parses the given path as an HCL2 job spec, setting input
+// variables from the environment.
+func ParseFile(path string) (*api.Job, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWithConfig(&ParseConfig{
+		Path: path,
+		Body: body,
+		Envs: os.Environ(),
+	})
+}
+
+// ParseWithConfig parses an HCL2 job spec. Input variables and local values
+// are evaluated and expanded into the job, which is then validated and
+// decoded by the same rules as HCL1 job specs.
+func ParseWithConfig(args *ParseConfig) (*api.Job, error) {
+	file, diags := hclsyntax.ParseConfig(args.Body, args.Path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	content, diags := file.Body.Content(rootSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	p := &parser{src: args.Body}
+
+	// Decode the declared variables and set their values
+	vars, diags := p.decodeVariables(content.Blocks.OfType("variable"))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	values, diags := p.variableValues(vars, args)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(values),
+			"local": cty.EmptyObjectVal,
+		},
+		Functions: functions(),
+	}
+
+	locals, diags := p.evalLocals(content.Blocks.OfType("locals"), ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+
+	// Expand the job into the HCL1 syntax tree
+	list, diags := p.convertBlocks(content.Blocks.OfType("job"), ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return jobspec.ParseObjectList(list)
+}
+
+// parser holds the state of parsing a single job file.
+type parser struct {
+	// src is the contents of the job file, used to preserve the source of
+	// runtime interpolations.
+	src []byte
+}
@@ -0,0 +1,363 @@
+package jobspec2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/jobspec"
+	"github.com/kr/pretty"
+)
+
+func parseFixture(t *testing.T, file string, args *ParseConfig) (*api.Job, error) {
+	path, err := filepath.Abs(filepath.Join("./test-fixtures", file))
+	if err != nil {
+		t.Fatalf("file: %s\n\n%s", file, err)
+	}
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("file: %s\n\n%s", file, err)
+	}
+
+	args.Path = path
+	args.Body = body
+	return ParseWithConfig(args)
+}
+
+func TestParse_Variables(t *testing.T) {
+	job, err := parseFixture(t, "variables.hcl", &ParseConfig{
+		ArgVars: []string{"image=redis", "count=2"},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := &api.Job{
+		ID:          helper.StringToPtr("web"),
+		Name:        helper.StringToPtr("web-prod"),
+		Datacenters: []string{"dc1"},
+		Meta: map[string]string{
+			"env":   "prod",
+			"node":  "${node.unique.name}",
+			"index": "${NOMAD_ALLOC_INDEX}-prod",
+		},
+		TaskGroups: []*api.TaskGroup{
+			{
+				Name:  helper.StringToPtr("web"),
+				Count: helper.IntToPtr(4),
+				Tasks: []*api.Task{
+					{
+						Name:   "server",
+						Driver: "docker",
+						Config: map[string]interface{}{
+							"image": "redis:PROD",
+							"port_map": []map[string]interface{}{{
+								"grpc": 8081,
+								"http": 8080,
+							}},
+							"args": []interface{}{"-listen", "${NOMAD_ADDR_http}", "-kernel", "${attr.kernel.name}"},
+						},
+						Resources: &api.Resources{
+							Networks: []*api.NetworkResource{
+								{
+									DynamicPorts: []api.Port{{Label: "grpc"}, {Label: "http"}},
+								},
+							},
+						},
+						Env: map[string]string{
+							"KERNEL": "${attr.kernel.name}",
+							"REGION": `${meta["region"]}`,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(job, expected) {
+		for _, d := range pretty.Diff(job, expected) {
+			t.Logf("%s", d)
+		}
+		t.Fatalf("bad: %# v", pretty.Formatter(job))
+	}
+}
+
+func TestParse_VariablePrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobspec2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hclFile := filepath.Join(dir, "vars.hcl")
+	if err := ioutil.WriteFile(hclFile, []byte(`image = "from-hcl-file"
+datacenters = ["dc2", "dc3"]`), 0644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	jsonFile := filepath.Join(dir, "vars.json")
+	if err := ioutil.WriteFile(jsonFile, []byte(`{"image": "from-json-file"}`), 0644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	cases := []struct {
+		Name        string
+		Args        *ParseConfig
+		Image       string
+		Count       int
+		Datacenters []string
+	}{
+		{
+			Name:        "env",
+			Args:        &ParseConfig{Envs: []string{"NOMAD_VAR_image=from-env", "NOMAD_VAR_count=3", "NOMAD_VAR_other=ignored"}},
+			Image:       "from-env",
+			Count:       6,
+			Datacenters: []string{"dc1"},
+		},
+		{
+			Name: "files",
+			Args: &ParseConfig{
+				Envs:     []string{"NOMAD_VAR_image=from-env", `NOMAD_VAR_datacenters=["dc4"]`},
+				VarFiles: []string{hclFile, jsonFile},
+			},
+			Image:       "from-json-file",
+			Count:       2,
+			Datacenters: []string{"dc2", "dc3"},
+		},
+		{
+			Name: "args",
+			Args: &ParseConfig{
+				Envs:     []string{"NOMAD_VAR_image=from-env"},
+				VarFiles: []string{hclFile},
+				ArgVars:  []string{"image=from-args", `datacenters=["dc5"]`},
+			},
+			Image:       "from-args",
+			Count:       2,
+			Datacenters: []string{"dc5"},
+		},
+	}
+
+	for _, tc := range cases {
+		job, err := parseFixture(t, "variables.hcl", tc.Args)
+		if err != nil {
+			t.Fatalf("case %s: err: %v", tc.Name, err)
+		}
+		if image := job.TaskGroups[0].Tasks[0].Config["image"]; image != tc.Image+":PROD" {
+			t.Fatalf("case %s: got image %q", tc.Name, image)
+		}
+		if count := *job.TaskGroups[0].Count; count != tc.Count {
+			t.Fatalf("case %s: got count %d", tc.Name, count)
+		}
+		if !reflect.DeepEqual(job.Datacenters, tc.Datacenters) {
+			t.Fatalf("case %s: got datacenters %v", tc.Name, job.Datacenters)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := []struct {
+		Name string
+		Src  string
+		Args []string
+		Err  string
+	}{
+		{
+			Name: "unset variable",
+			Src: `variable "image" {}
+job "example" {}`,
+			Err: `job.hcl:1,1-17: Unset variable; A value for the variable "image" must be set`,
+		},
+		{
+			Name: "undefined -var variable",
+			Src:  `job "example" {}`,
+			Args: []string{"image=redis"},
+			Err:  `Undefined -var variable`,
+		},
+		{
+			Name: "invalid value",
+			Src: `variable "count" {
+  type = number
+}
+job "example" {}`,
+			Args: []string{"count=many"},
+			Err:  `Invalid value for variable; The value for variable "count" is not compatible`,
+		},
+		{
+			Name: "undefined local",
+			Src: `job "example" {
+  datacenters = [local.dc]
+}`,
+			Err: `job.hcl:2,23-26: Unsupported attribute`,
+		},
+		{
+			Name: "cyclic locals",
+			Src: `locals {
+  a = local.b
+  b = local.a
+}
+job "example" {}`,
+			Err: `job.hcl:2,3-4: Cycle in local values`,
+		},
+		{
+			Name: "unknown top-level block",
+			Src:  `jobs "example" {}`,
+			Err:  `job.hcl:1,1-5: Unsupported block type`,
+		},
+		{
+			Name: "invalid key",
+			Src: `job "example" {
+  group "cache" {
+    cont = 1
+  }
+}`,
+			Err: `group: 'cache' -> job.hcl:3:5: invalid key: cont`,
+		},
+		{
+			Name: "invalid dynamic",
+			Src: `job "example" {
+  group "cache" {
+    dynamic "task" {
+      for_each = 1
+      content {}
+    }
+  }
+}`,
+			Err: `job.hcl:4,18-19: Invalid dynamic for_each value`,
+		},
+		{
+			Name: "invalid type",
+			Src: `job "example" {
+  group "cache" {
+    count = "many"
+  }
+}`,
+			Err: `group: job.hcl:3:5: cannot parse 'Count' as int`,
+		},
+		{
+			Name: "multiple jobs",
+			Src: `job "example" {}
+job "other" {}`,
+			Err: `job.hcl:2:1: only one 'job' block allowed`,
+		},
+		{
+			Name: "duplicate group",
+			Src: `job "example" {
+  group "cache" {}
+  group "cache" {}
+}`,
+			Err: `group: job.hcl:3:3: group 'cache' defined more than once`,
+		},
+		{
+			Name: "invalid port label",
+			Src: `job "example" {
+  group "cache" {
+    task "redis" {
+      resources {
+        network {
+          port "db-port" {}
+        }
+      }
+    }
+  }
+}`,
+			Err: `ports -> job.hcl:6:11: Port label does not conform to naming requirements`,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseWithConfig(&ParseConfig{
+			Path:    "job.hcl",
+			Body:    []byte(tc.Src),
+			ArgVars: tc.Args,
+		})
+		if err == nil {
+			t.Fatalf("case %s: expected error", tc.Name)
+		}
+		if !strings.Contains(err.Error(), tc.Err) {
+			t.Fatalf("case %s: expected error containing %q; got %v", tc.Name, tc.Err, err)
+		}
+	}
+}
+
+func TestParse_HCL1Compatible(t *testing.T) {
+	// Job files without HCL2 features parse to the same job as with HCL1
+	src := `job "example" {
+  datacenters = ["dc1"]
+
+  group "cache" {
+    count = 3
+
+    task "redis" {
+      driver = "docker"
+
+      config {
+        image = "redis:3.2"
+        port_map {
+          db = 6379
+        }
+      }
+
+      service {
+        name = "${TASKGROUP}-redis"
+        port = "db"
+      }
+
+      template {
+        data = <<EOH
+bind_port = "{{ env "NOMAD_PORT_db" }}"
+EOH
+        destination = "local/redis.conf"
+      }
+    }
+  }
+}`
+
+	job, err := Parse("job.hcl", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	task := job.TaskGroups[0].Tasks[0]
+	if name := task.Services[0].Name; name != "${TASKGROUP}-redis" {
+		t.Fatalf("got service name %q", name)
+	}
+	if data := *task.Templates[0].EmbeddedTmpl; data != "bind_port = \"{{ env \"NOMAD_PORT_db\" }}\"\n" {
+		t.Fatalf("got template %q", data)
+	}
+	portMap := task.Config["port_map"].([]map[string]interface{})
+	if port := portMap[0]["db"]; port != 6379 {
+		t.Fatalf("got port map %#v", portMap)
+	}
+}
+
+func TestParse_HCL1Fixtures(t *testing.T) {
+	// The job files of the HCL1 parser parse to the same jobs with HCL2
+	paths, err := filepath.Glob("../jobspec/test-fixtures/*.hcl")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, path := range paths {
+		expected, err := jobspec.ParseFile(path)
+		if err != nil {
+			continue
+		}
+
+		actual, err := ParseFile(path)
+		if err != nil {
+			t.Errorf("file: %s\n\n%s", path, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			for _, d := range pretty.Diff(actual, expected) {
+				t.Logf("%s", d)
+			}
+			t.Errorf("file: %s", path)
+		}
+	}
+}
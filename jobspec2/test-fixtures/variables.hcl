@@ -0,0 +1,70 @@
+variable "datacenters" {
+  type        = list(string)
+  description = "The datacenters to run the job in"
+  default     = ["dc1"]
+}
+
+variable "count" {
+  type    = number
+  default = 1
+}
+
+variable "image" {
+  type = string
+}
+
+variable "ports" {
+  type = map(number)
+  default = {
+    http = 8080
+    grpc = 8081
+  }
+}
+
+locals {
+  name     = "web-${local.env}"
+  env      = "prod"
+  image    = "${var.image}:${upper(local.env)}"
+  port_map = { for name, port in var.ports : name => port }
+}
+
+job "web" {
+  name        = local.name
+  datacenters = var.datacenters
+
+  meta {
+    env   = local.env
+    node  = "${node.unique.name}"
+    index = "${NOMAD_ALLOC_INDEX}-${local.env}"
+  }
+
+  group "web" {
+    count = var.count * 2
+
+    task "server" {
+      driver = "docker"
+
+      config {
+        image    = local.image
+        port_map = local.port_map
+        args     = ["-listen", "${NOMAD_ADDR_http}", "-kernel", "$${attr.kernel.name}"]
+      }
+
+      resources {
+        network {
+          dynamic "port" {
+            for_each = sort(keys(var.ports))
+            labels   = [port.value]
+
+            content {}
+          }
+        }
+      }
+
+      env {
+        KERNEL = "${attr.kernel.name}"
+        REGION = "${meta["region"]}"
+      }
+    }
+  }
+}
@@ -0,0 +1,294 @@
+package jobspec2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// envVarPrefix is the prefix of environment variables setting the value of
+// input variables.
+const envVarPrefix = "NOMAD_VAR_"
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+// variable is an input variable declared by a variable block.
+type variable struct {
+	Name        string
+	Description string
+
+	// Type is the type constraint of the variable. Variables without a type
+	// accept values of any type.
+	Type cty.Type
+
+	// Default is the value of the variable if none is set. It is cty.NilVal
+	// if the variable is required.
+	Default cty.Value
+
+	DeclRange hcl.Range
+}
+
+// decodeVariables decodes the variable blocks of the job file.
+func (p *parser) decodeVariables(blocks hcl.Blocks) (map[string]*variable, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	vars := make(map[string]*variable, len(blocks))
+	for _, block := range blocks {
+		name := block.Labels[0]
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable name",
+				Detail:   "A name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.",
+				Subject:  &block.LabelRanges[0],
+			})
+			continue
+		}
+		if existing, ok := vars[name]; ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable declaration",
+				Detail:   fmt.Sprintf("A variable named %q was already declared at %s.", name, existing.DeclRange),
+				Subject:  &block.DefRange,
+			})
+			continue
+		}
+
+		content, moreDiags := block.Body.Content(variableSchema)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		v := &variable{
+			Name:      name,
+			Type:      cty.DynamicPseudoType,
+			DeclRange: block.DefRange,
+		}
+
+		if attr, ok := content.Attributes["description"]; ok {
+			val, moreDiags := attr.Expr.Value(nil)
+			diags = append(diags, moreDiags...)
+			if !moreDiags.HasErrors() {
+				val, err := convert.Convert(val, cty.String)
+				if err != nil || val.IsNull() {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid variable description",
+						Detail:   "The description must be a string.",
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				} else {
+					v.Description = val.AsString()
+				}
+			}
+		}
+
+		if attr, ok := content.Attributes["type"]; ok {
+			ty, moreDiags := typeexpr.TypeConstraint(attr.Expr)
+			diags = append(diags, moreDiags...)
+			if !moreDiags.HasErrors() {
+				v.Type = ty
+			}
+		}
+
+		if attr, ok := content.Attributes["default"]; ok {
+			// Defaults may use functions but may not refer to other
+			// variables
+			val, moreDiags := attr.Expr.Value(&hcl.EvalContext{Functions: functions()})
+			diags = append(diags, moreDiags...)
+			if !moreDiags.HasErrors() {
+				val, err := convert.Convert(val, v.Type)
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid default value for variable",
+						Detail:   fmt.Sprintf("This default value is not compatible with the type constraint of the variable: %s.", err),
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				} else {
+					v.Default = val
+				}
+			}
+		}
+
+		vars[name] = v
+	}
+	return vars, diags
+}
+
+// variableValues returns the values of the variables. Values set with -var
+// take precedence over those from files passed with -var-file, which in turn
+// take precedence over environment variables and the defaults of the
+// variables.
+func (p *parser) variableValues(vars map[string]*variable, args *ParseConfig) (map[string]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	values := make(map[string]cty.Value, len(vars))
+
+	// setValue converts the value to the type of the variable
+	setValue := func(v *variable, val cty.Value, subject hcl.Range) {
+		val, err := convert.Convert(val, v.Type)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value for variable",
+				Detail:   fmt.Sprintf("The value for variable %q is not compatible with its type constraint: %s.", v.Name, err),
+				Subject:  subject.Ptr(),
+			})
+			return
+		}
+		values[v.Name] = val
+	}
+
+	for _, env := range args.Envs {
+		if !strings.HasPrefix(env, envVarPrefix) {
+			continue
+		}
+		parts := strings.SplitN(env[len(envVarPrefix):], "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		// Environment variables may be set for variables of other jobs so
+		// unknown ones are ignored
+		v, ok := vars[parts[0]]
+		if !ok {
+			continue
+		}
+
+		name := envVarPrefix + parts[0]
+		val, moreDiags := parseRawValue(v, name, parts[1])
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			setValue(v, val, hcl.Range{Filename: name})
+		}
+	}
+
+	for _, path := range args.VarFiles {
+		attrs, moreDiags := parseVarFile(path)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		for name, attr := range attrs {
+			v, ok := vars[name]
+			if !ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Undefined variable",
+					Detail:   fmt.Sprintf("A variable named %q was assigned in the variable file, but the job does not declare a variable of that name.", name),
+					Subject:  &attr.NameRange,
+				})
+				continue
+			}
+
+			val, moreDiags := attr.Expr.Value(&hcl.EvalContext{Functions: functions()})
+			diags = append(diags, moreDiags...)
+			if !moreDiags.HasErrors() {
+				setValue(v, val, attr.Expr.Range())
+			}
+		}
+	}
+
+	for _, arg := range args.ArgVars {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid -var option",
+				Detail:   fmt.Sprintf("The given -var option %q is not correctly specified. It must be a variable name and value separated by an equals sign, like -var=\"key=value\".", arg),
+			})
+			continue
+		}
+
+		v, ok := vars[parts[0]]
+		if !ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Undefined -var variable",
+				Detail:   fmt.Sprintf("A %q variable was passed with -var, but the job does not declare a variable of that name.", parts[0]),
+			})
+			continue
+		}
+
+		name := fmt.Sprintf("<value for var.%s>", parts[0])
+		val, moreDiags := parseRawValue(v, name, parts[1])
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			setValue(v, val, hcl.Range{Filename: name})
+		}
+	}
+
+	// Use the defaults of the variables that were not set
+	for name, v := range vars {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if v.Default == cty.NilVal {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unset variable",
+				Detail:   fmt.Sprintf("A value for the variable %q must be set with -var, -var-file or the %s%s environment variable.", name, envVarPrefix, name),
+				Subject:  &v.DeclRange,
+			})
+			continue
+		}
+		values[name] = v.Default
+	}
+
+	return values, diags
+}
+
+// parseRawValue parses a value given as a string on the command line or in
+// the environment. Values of string variables are taken literally while other
+// values are parsed as HCL expressions.
+func parseRawValue(v *variable, name, raw string) (cty.Value, hcl.Diagnostics) {
+	if v.Type.IsPrimitiveType() || v.Type == cty.DynamicPseudoType {
+		return cty.StringVal(raw), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), name, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return expr.Value(&hcl.EvalContext{Functions: functions()})
+}
+
+// parseVarFile parses the attributes of a variable file. Files with the .json
+// extension use the JSON syntax of HCL.
+func parseVarFile(path string) (hcl.Attributes, hcl.Diagnostics) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read variable file",
+			Detail:   fmt.Sprintf("The variable file %q could not be read: %s.", path, err),
+		}}
+	}
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = hcljson.Parse(src, path)
+	} else {
+		file, diags = hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return file.Body.JustAttributes()
+}
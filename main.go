@@ -32,9 +32,11 @@ func RunCustom(args []string, commands map[string]cli.CommandFactory) int {
 		case "deployment list", "deployment status", "deployment pause",
 			"deployment resume", "deployment fail", "deployment promote":
 		case "fs ls", "fs cat", "fs stat":
-		case "job deployments", "job dispatch", "job history", "job promote", "job revert":
+		case "job capacity", "job deployments", "job dispatch", "job explain", "job history", "job inspect", "job promote", "job revert":
+		case "job periodic", "job periodic force", "job periodic pause", "job periodic resume":
 		case "namespace list", "namespace delete", "namespace apply":
 		case "operator raft", "operator raft list-peers", "operator raft remove-peer":
+		case "operator scheduler", "operator scheduler get-config", "operator scheduler set-config":
 		case "acl policy", "acl policy apply", "acl token", "acl token create":
 		default:
 			commandsInclude = append(commandsInclude, k)
@@ -122,6 +122,11 @@ type Config struct {
 	// that the workers dequeue for processing.
 	EnabledSchedulers []string
 
+	// SchedulerPlugins are the scheduler plugins this server launches. The
+	// job type of each plugin is added to the enabled schedulers so that its
+	// evaluations are only dequeued by workers hosting the plugin.
+	SchedulerPlugins []*config.SchedulerPluginConfig
+
 	// ReconcileInterval controls how often we reconcile the strongly
 	// consistent store with the Serf info. This is used to handle nodes
 	// that are force removed, as well as intermittent unavailability during
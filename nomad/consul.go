@@ -0,0 +1,217 @@
+package nomad
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	consulacl "github.com/hashicorp/consul/acl"
+	consulapi "github.com/hashicorp/consul/api"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// consulTokenNamePrefix is the prefix of the name of Consul tokens
+	// derived for tasks. The allocation ID and task name follow it.
+	consulTokenNamePrefix = "_nomad_task_"
+)
+
+// ConsulACLsAPI is the interface the Nomad server uses to validate the Consul
+// tokens of submitted jobs and to manage the Consul tokens derived for tasks.
+type ConsulACLsAPI interface {
+	// CheckServiceIdentities returns an error if the token is unable to
+	// register all of the given services.
+	CheckServiceIdentities(token string, services []string) error
+
+	// DeriveTokens creates a Consul token for each of the given tasks in the
+	// allocation, allowing them to register their services.
+	DeriveTokens(alloc *structs.Allocation, tasks []string) (map[string]string, error)
+
+	// RevokeTokens destroys the derived tokens of the given accessors. If
+	// committed is true, the accessors of the revoked tokens are purged from
+	// the state.
+	RevokeTokens(accessors []*structs.ConsulAccessor, committed bool) error
+}
+
+// PurgeConsulAccessorFn is called to remove ConsulAccessors from the system
+// once their tokens are revoked.
+type PurgeConsulAccessorFn func(accessors []*structs.ConsulAccessor) error
+
+// consulACLAPI is the subset of the Consul ACL API used by consulACLsClient.
+type consulACLAPI interface {
+	Info(id string, q *consulapi.QueryOptions) (*consulapi.ACLEntry, *consulapi.QueryMeta, error)
+	Create(acl *consulapi.ACLEntry, q *consulapi.WriteOptions) (string, *consulapi.WriteMeta, error)
+	Destroy(id string, q *consulapi.WriteOptions) (*consulapi.WriteMeta, error)
+}
+
+// consulACLsClient implements ConsulACLsAPI using the Consul ACL API. Derived
+// tokens are created and destroyed using the token the server is configured
+// with which must be a management token.
+type consulACLsClient struct {
+	acls    consulACLAPI
+	purgeFn PurgeConsulAccessorFn
+	logger  *log.Logger
+}
+
+// NewConsulACLsAPI returns a ConsulACLsAPI backed by the given Consul ACL API.
+func NewConsulACLsAPI(acls consulACLAPI, logger *log.Logger, purgeFn PurgeConsulAccessorFn) ConsulACLsAPI {
+	return &consulACLsClient{
+		acls:    acls,
+		purgeFn: purgeFn,
+		logger:  logger,
+	}
+}
+
+func (c *consulACLsClient) CheckServiceIdentities(token string, services []string) error {
+	if len(services) == 0 {
+		return nil
+	}
+	if token == "" {
+		return fmt.Errorf("Consul services registered but missing Consul token")
+	}
+
+	entry, _, err := c.acls.Info(token, nil)
+	if err != nil {
+		return fmt.Errorf("failed to look up Consul token: %v", err)
+	}
+	if entry == nil {
+		return fmt.Errorf("Consul token not found")
+	}
+
+	// Management tokens can register any service
+	if entry.Type == consulapi.ACLManagementType {
+		return nil
+	}
+
+	policy, err := consulacl.Parse(entry.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to parse Consul token rules: %v", err)
+	}
+	acl, err := consulacl.New(consulacl.DenyAll(), policy)
+	if err != nil {
+		return fmt.Errorf("failed to parse Consul token rules: %v", err)
+	}
+
+	var offending []string
+	for _, service := range services {
+		if !acl.ServiceWrite(servicePrefix(service)) {
+			offending = append(offending, service)
+		}
+	}
+	if len(offending) != 0 {
+		return fmt.Errorf("Passed Consul token doesn't allow registering the following services: %s",
+			strings.Join(offending, ", "))
+	}
+	return nil
+}
+
+func (c *consulACLsClient) DeriveTokens(alloc *structs.Allocation, tasks []string) (map[string]string, error) {
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil {
+		return nil, fmt.Errorf("allocation's task group %q not found", alloc.TaskGroup)
+	}
+
+	tokens := make(map[string]string, len(tasks))
+	for _, name := range tasks {
+		task := tg.LookupTask(name)
+		if task == nil {
+			return nil, fmt.Errorf("task %q not found in allocation", name)
+		}
+
+		entry := &consulapi.ACLEntry{
+			Name:  consulTokenName(alloc.ID, name),
+			Type:  consulapi.ACLClientType,
+			Rules: taskConsulRules(task),
+		}
+		id, _, err := c.acls.Create(entry, nil)
+		if err != nil {
+			// Destroy the tokens created so far
+			for _, created := range tokens {
+				if _, err := c.acls.Destroy(created, nil); err != nil {
+					c.logger.Printf("[WARN] nomad.consul: failed to revoke Consul token for alloc %q: %v", alloc.ID, err)
+				}
+			}
+			return nil, structs.NewRecoverableError(
+				fmt.Errorf("failed to create Consul token for task %q: %v", name, err), true)
+		}
+		tokens[name] = id
+	}
+	return tokens, nil
+}
+
+func (c *consulACLsClient) RevokeTokens(accessors []*structs.ConsulAccessor, committed bool) error {
+	var mErr multierror.Error
+	revoked := make([]*structs.ConsulAccessor, 0, len(accessors))
+	for _, accessor := range accessors {
+		// Destroying a token that no longer exists succeeds, so tokens whose
+		// accessors failed to be purged are safely revoked again.
+		if _, err := c.acls.Destroy(accessor.Accessor, nil); err != nil {
+			multierror.Append(&mErr, fmt.Errorf("failed to revoke Consul token for task %q of alloc %q: %v",
+				accessor.Task, accessor.AllocID, err))
+			continue
+		}
+		revoked = append(revoked, accessor)
+	}
+
+	if len(revoked) != 0 {
+		c.logger.Printf("[DEBUG] nomad.consul: revoked %d Consul tokens", len(revoked))
+		if committed && c.purgeFn != nil {
+			if err := c.purgeFn(revoked); err != nil {
+				multierror.Append(&mErr, fmt.Errorf("failed to purge Consul accessors: %v", err))
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// consulTokenName returns the name of the Consul token derived for a task.
+func consulTokenName(allocID, task string) string {
+	return fmt.Sprintf("%s%s_%s", consulTokenNamePrefix, allocID, task)
+}
+
+// purgeConsulAccessors creates a Raft transaction to remove the passed Consul
+// accessors
+func (s *Server) purgeConsulAccessors(accessors []*structs.ConsulAccessor) error {
+	// Commit this update via Raft
+	req := structs.ConsulAccessorsRequest{Accessors: accessors}
+	_, _, err := s.raftApply(structs.ConsulAccessorDeregisterRequestType, req)
+	return err
+}
+
+// servicePrefix returns the portion of a service name preceding any
+// interpolation that can only be resolved on the client. Consul service
+// rules match by prefix so access to the prefix implies access to the name.
+func servicePrefix(name string) string {
+	if i := strings.Index(name, "${"); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// taskConsulRules returns the Consul ACL rules of the token derived for the
+// task. It may register its own services and discover others. Any other
+// access falls back to Consul's default ACL policy.
+func taskConsulRules(task *structs.Task) string {
+	set := make(map[string]struct{}, len(task.Services))
+	for _, service := range task.Services {
+		set[servicePrefix(service.Name)] = struct{}{}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString("node \"\" {\n  policy = \"read\"\n}\n")
+	if _, ok := set[""]; !ok {
+		b.WriteString("service \"\" {\n  policy = \"read\"\n}\n")
+	}
+	for _, name := range names {
+		fmt.Fprintf(&b, "service %q {\n  policy = \"write\"\n}\n", name)
+	}
+	return b.String()
+}
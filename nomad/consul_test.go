@@ -0,0 +1,191 @@
+package nomad
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockConsulACLAPI is an in-memory implementation of the Consul ACL API.
+type mockConsulACLAPI struct {
+	entries   map[string]*consulapi.ACLEntry
+	nextID    int
+	failAfter int
+}
+
+func newMockConsulACLAPI() *mockConsulACLAPI {
+	return &mockConsulACLAPI{
+		entries:   make(map[string]*consulapi.ACLEntry),
+		failAfter: -1,
+	}
+}
+
+func (m *mockConsulACLAPI) Info(id string, q *consulapi.QueryOptions) (*consulapi.ACLEntry, *consulapi.QueryMeta, error) {
+	return m.entries[id], nil, nil
+}
+
+func (m *mockConsulACLAPI) Create(acl *consulapi.ACLEntry, q *consulapi.WriteOptions) (string, *consulapi.WriteMeta, error) {
+	if m.failAfter == 0 {
+		return "", nil, fmt.Errorf("create failed")
+	}
+	m.failAfter--
+
+	m.nextID++
+	entry := *acl
+	entry.ID = fmt.Sprintf("token-%d", m.nextID)
+	m.entries[entry.ID] = &entry
+	return entry.ID, nil, nil
+}
+
+func (m *mockConsulACLAPI) Destroy(id string, q *consulapi.WriteOptions) (*consulapi.WriteMeta, error) {
+	delete(m.entries, id)
+	return nil, nil
+}
+
+func TestConsulACLsAPI_CheckServiceIdentities(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	acls := newMockConsulACLAPI()
+	acls.entries["management"] = &consulapi.ACLEntry{ID: "management", Type: consulapi.ACLManagementType}
+	acls.entries["web"] = &consulapi.ACLEntry{
+		ID:    "web",
+		Type:  consulapi.ACLClientType,
+		Rules: `service "web" { policy = "write" }`,
+	}
+	c := NewConsulACLsAPI(acls, testLogger(), nil)
+
+	// No services never require a token
+	assert.Nil(c.CheckServiceIdentities("", nil))
+
+	// Missing and unknown tokens are rejected
+	assert.NotNil(c.CheckServiceIdentities("", []string{"web"}))
+	assert.NotNil(c.CheckServiceIdentities("unknown", []string{"web"}))
+
+	// Management tokens can register any service
+	assert.Nil(c.CheckServiceIdentities("management", []string{"web", "db"}))
+
+	// Client tokens are limited to their rules
+	assert.Nil(c.CheckServiceIdentities("web", []string{"web", "web-${NOMAD_ALLOC_INDEX}"}))
+	err := c.CheckServiceIdentities("web", []string{"web", "db"})
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "db")
+		assert.NotContains(err.Error(), "web,")
+	}
+}
+
+func TestConsulACLsAPI_DeriveTokens(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	acls := newMockConsulACLAPI()
+	c := NewConsulACLsAPI(acls, testLogger(), nil)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	tokens, err := c.DeriveTokens(alloc, []string{task.Name})
+	assert.Nil(err)
+	assert.Len(tokens, 1)
+
+	entry := acls.entries[tokens[task.Name]]
+	if assert.NotNil(entry) {
+		assert.Equal(consulTokenName(alloc.ID, task.Name), entry.Name)
+		assert.Equal(consulapi.ACLClientType, entry.Type)
+		for _, service := range task.Services {
+			assert.Contains(entry.Rules, fmt.Sprintf("service %q", servicePrefix(service.Name)))
+		}
+	}
+
+	// Unknown tasks are rejected
+	_, err = c.DeriveTokens(alloc, []string{"unknown"})
+	assert.NotNil(err)
+}
+
+func TestConsulACLsAPI_DeriveTokens_Cleanup(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	acls := newMockConsulACLAPI()
+	c := NewConsulACLsAPI(acls, testLogger(), nil)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	second := task.Copy()
+	second.Name = "second"
+	alloc.Job.TaskGroups[0].Tasks = append(alloc.Job.TaskGroups[0].Tasks, second)
+
+	// Failing to create the second token destroys the first
+	acls.failAfter = 1
+	_, err := c.DeriveTokens(alloc, []string{task.Name, second.Name})
+	if assert.NotNil(err) {
+		assert.True(structs.IsRecoverable(err))
+	}
+	assert.Empty(acls.entries)
+}
+
+func TestConsulACLsAPI_RevokeTokens(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var purged []*structs.ConsulAccessor
+	purgeFn := func(accessors []*structs.ConsulAccessor) error {
+		purged = append(purged, accessors...)
+		return nil
+	}
+
+	acls := newMockConsulACLAPI()
+	acls.entries["other"] = &consulapi.ACLEntry{ID: "other", Name: "not nomad"}
+	c := NewConsulACLsAPI(acls, testLogger(), purgeFn)
+
+	a1 := mock.Alloc()
+	a2 := mock.Alloc()
+	task := a1.Job.TaskGroups[0].Tasks[0].Name
+	t1, err := c.DeriveTokens(a1, []string{task})
+	assert.Nil(err)
+	_, err = c.DeriveTokens(a2, []string{task})
+	assert.Nil(err)
+
+	// Revoking uncommitted accessors doesn't purge them
+	accessor := &structs.ConsulAccessor{AllocID: a1.ID, Task: task, Accessor: t1[task]}
+	assert.Nil(c.RevokeTokens([]*structs.ConsulAccessor{accessor}, false))
+	assert.Empty(purged)
+
+	var names []string
+	for _, entry := range acls.entries {
+		names = append(names, entry.Name)
+	}
+	sort.Strings(names)
+	assert.Equal([]string{consulTokenName(a2.ID, task), "not nomad"}, names)
+
+	// Revoking committed accessors purges them, even if the token is gone
+	assert.Nil(c.RevokeTokens([]*structs.ConsulAccessor{accessor}, true))
+	assert.Equal([]*structs.ConsulAccessor{accessor}, purged)
+}
+
+func TestTaskConsulRules(t *testing.T) {
+	t.Parallel()
+	task := &structs.Task{
+		Services: []*structs.Service{
+			{Name: "web"},
+			{Name: "web"},
+			{Name: "api-${NOMAD_ALLOC_INDEX}"},
+		},
+	}
+
+	rules := taskConsulRules(task)
+	if !strings.Contains(rules, `service "web" {`) || !strings.Contains(rules, `service "api-" {`) {
+		t.Fatalf("missing service rules:\n%s", rules)
+	}
+	if n := strings.Count(rules, `service "web"`); n != 1 {
+		t.Fatalf("expected web rule once but found %d:\n%s", n, rules)
+	}
+	if !strings.Contains(rules, `service "" {`) {
+		t.Fatalf("missing service read rule:\n%s", rules)
+	}
+}
@@ -240,11 +240,11 @@ func (c *CoreScheduler) gcEval(eval *structs.Evaluation, thresholdIndex uint64,
 	// Create a watchset
 	ws := memdb.NewWatchSet()
 
-	// If the eval is from a running "batch" job we don't want to garbage
-	// collect its allocations. If there is a long running batch job and its
-	// terminal allocations get GC'd the scheduler would re-run the
-	// allocations.
-	if eval.Type == structs.JobTypeBatch {
+	// If the eval is from a running "batch" or "sysbatch" job we don't want
+	// to garbage collect its allocations. If there is a long running batch
+	// job and its terminal allocations get GC'd the scheduler would re-run
+	// the allocations.
+	if eval.Type == structs.JobTypeBatch || eval.Type == structs.JobTypeSysBatch {
 		// Check if the job is running
 		job, err := c.snap.JobByID(ws, eval.Namespace, eval.JobID)
 		if err != nil {
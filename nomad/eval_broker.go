@@ -18,6 +18,10 @@ const (
 	// they've reached the deliveryLimit. This allows the leader to
 	// set the status to failed.
 	failedQueue = "_failed"
+
+	// cancelableBatchSize is the maximum number of superseded evaluations
+	// returned by GetCancelable so they can be cancelled in one Raft apply.
+	cancelableBatchSize = 512
 )
 
 var (
@@ -56,8 +60,16 @@ type EvalBroker struct {
 	// jobEvals tracks queued evaluations by a job's ID and namespace to serialize them
 	jobEvals map[structs.NamespacedID]string
 
-	// blocked tracks the blocked evaluations by JobID in a priority queue
-	blocked map[string]PendingEvaluations
+	// blocked tracks the blocked evaluations by a job's ID and namespace in a
+	// priority queue
+	blocked map[structs.NamespacedID]PendingEvaluations
+
+	// cancelable tracks the blocked evaluations that were superseded by a
+	// newer evaluation for the same job and can be cancelled.
+	cancelable []*structs.Evaluation
+
+	// cancelableCh is used to signal that there are cancelable evaluations
+	cancelableCh chan struct{}
 
 	// ready tracks the ready jobs by scheduler in a priority queue
 	ready map[string]PendingEvaluations
@@ -119,7 +131,8 @@ func NewEvalBroker(timeout, initialNackDelay, subsequentNackDelay time.Duration,
 		stats:               new(BrokerStats),
 		evals:               make(map[string]int),
 		jobEvals:            make(map[structs.NamespacedID]string),
-		blocked:             make(map[string]PendingEvaluations),
+		blocked:             make(map[structs.NamespacedID]PendingEvaluations),
+		cancelableCh:        make(chan struct{}, 1),
 		ready:               make(map[string]PendingEvaluations),
 		unack:               make(map[string]*unackEval),
 		waiting:             make(map[string]chan struct{}),
@@ -244,9 +257,9 @@ func (b *EvalBroker) enqueueLocked(eval *structs.Evaluation, queue string) {
 	if pendingEval == "" {
 		b.jobEvals[tuple] = eval.ID
 	} else if pendingEval != eval.ID {
-		blocked := b.blocked[eval.JobID]
+		blocked := b.blocked[tuple]
 		heap.Push(&blocked, eval)
-		b.blocked[eval.JobID] = blocked
+		b.blocked[tuple] = blocked
 		b.stats.TotalBlocked += 1
 		return
 	}
@@ -499,8 +512,6 @@ func (b *EvalBroker) Ack(evalID, token string) error {
 	if unack.Token != token {
 		return fmt.Errorf("Token does not match for Evaluation ID")
 	}
-	jobID := unack.Eval.JobID
-
 	// Ensure we were able to stop the timer
 	if !unack.NackTimer.Stop() {
 		return fmt.Errorf("Evaluation ID Ack'd after Nack timer expiration")
@@ -520,22 +531,35 @@ func (b *EvalBroker) Ack(evalID, token string) error {
 	delete(b.evals, evalID)
 
 	tuple := structs.NamespacedID{
-		ID:        jobID,
+		ID:        unack.Eval.JobID,
 		Namespace: unack.Eval.Namespace,
 	}
 	delete(b.jobEvals, tuple)
 
-	// Check if there are any blocked evaluations
-	if blocked := b.blocked[jobID]; len(blocked) != 0 {
-		raw := heap.Pop(&blocked)
-		if len(blocked) > 0 {
-			b.blocked[jobID] = blocked
-		} else {
-			delete(b.blocked, jobID)
+	// Check if there are any blocked evaluations. Only the newest one has to
+	// be processed since the scheduler reconciles against the latest state of
+	// the job, so the others are superseded and can be cancelled.
+	if blocked := b.blocked[tuple]; len(blocked) != 0 {
+		delete(b.blocked, tuple)
+		b.stats.TotalBlocked -= len(blocked)
+
+		newest := blocked.Newest()
+		for _, eval := range blocked {
+			if eval == newest {
+				continue
+			}
+			delete(b.evals, eval.ID)
+			b.cancelable = append(b.cancelable, eval)
+		}
+		b.stats.TotalCancelable = len(b.cancelable)
+		if len(b.cancelable) != 0 {
+			select {
+			case b.cancelableCh <- struct{}{}:
+			default:
+			}
 		}
-		eval := raw.(*structs.Evaluation)
-		b.stats.TotalBlocked -= 1
-		b.enqueueLocked(eval, eval.Type)
+
+		b.enqueueLocked(newest, newest.Type)
 	}
 
 	// Re-enqueue the evaluation.
@@ -642,6 +666,42 @@ func (b *EvalBroker) ResumeNackTimeout(evalID, token string) error {
 	return nil
 }
 
+// GetCancelable returns a batch of the evaluations that were superseded by a
+// newer evaluation for the same job and blocks until the passed timeout if
+// there are none.
+func (b *EvalBroker) GetCancelable(timeout time.Duration) []*structs.Evaluation {
+	var timeoutTimer *time.Timer
+	var timeoutCh <-chan time.Time
+SCAN:
+	b.l.Lock()
+	if len(b.cancelable) != 0 {
+		n := len(b.cancelable)
+		if n > cancelableBatchSize {
+			n = cancelableBatchSize
+		}
+		cancelable := b.cancelable[:n:n]
+		b.cancelable = b.cancelable[n:]
+		b.stats.TotalCancelable = len(b.cancelable)
+		b.l.Unlock()
+		return cancelable
+	}
+	b.l.Unlock()
+
+	// Create the timer
+	if timeoutTimer == nil && timeout != 0 {
+		timeoutTimer = time.NewTimer(timeout)
+		timeoutCh = timeoutTimer.C
+		defer timeoutTimer.Stop()
+	}
+
+	select {
+	case <-timeoutCh:
+		return nil
+	case <-b.cancelableCh:
+		goto SCAN
+	}
+}
+
 // Flush is used to clear the state of the broker
 func (b *EvalBroker) Flush() {
 	b.l.Lock()
@@ -668,10 +728,12 @@ func (b *EvalBroker) Flush() {
 	b.stats.TotalUnacked = 0
 	b.stats.TotalBlocked = 0
 	b.stats.TotalWaiting = 0
+	b.stats.TotalCancelable = 0
 	b.stats.ByScheduler = make(map[string]*SchedulerStats)
 	b.evals = make(map[string]int)
 	b.jobEvals = make(map[structs.NamespacedID]string)
-	b.blocked = make(map[string]PendingEvaluations)
+	b.blocked = make(map[structs.NamespacedID]PendingEvaluations)
+	b.cancelable = nil
 	b.ready = make(map[string]PendingEvaluations)
 	b.unack = make(map[string]*unackEval)
 	b.timeWait = make(map[string]*time.Timer)
@@ -691,6 +753,7 @@ func (b *EvalBroker) Stats() *BrokerStats {
 	stats.TotalUnacked = b.stats.TotalUnacked
 	stats.TotalBlocked = b.stats.TotalBlocked
 	stats.TotalWaiting = b.stats.TotalWaiting
+	stats.TotalCancelable = b.stats.TotalCancelable
 	for sched, subStat := range b.stats.ByScheduler {
 		subStatCopy := new(SchedulerStats)
 		*subStatCopy = *subStat
@@ -709,6 +772,7 @@ func (b *EvalBroker) EmitStats(period time.Duration, stopCh chan struct{}) {
 			metrics.SetGauge([]string{"nomad", "broker", "total_unacked"}, float32(stats.TotalUnacked))
 			metrics.SetGauge([]string{"nomad", "broker", "total_blocked"}, float32(stats.TotalBlocked))
 			metrics.SetGauge([]string{"nomad", "broker", "total_waiting"}, float32(stats.TotalWaiting))
+			metrics.SetGauge([]string{"nomad", "broker", "total_cancelable"}, float32(stats.TotalCancelable))
 			for sched, schedStats := range stats.ByScheduler {
 				metrics.SetGauge([]string{"nomad", "broker", sched, "ready"}, float32(schedStats.Ready))
 				metrics.SetGauge([]string{"nomad", "broker", sched, "unacked"}, float32(schedStats.Unacked))
@@ -722,11 +786,12 @@ func (b *EvalBroker) EmitStats(period time.Duration, stopCh chan struct{}) {
 
 // BrokerStats returns all the stats about the broker
 type BrokerStats struct {
-	TotalReady   int
-	TotalUnacked int
-	TotalBlocked int
-	TotalWaiting int
-	ByScheduler  map[string]*SchedulerStats
+	TotalReady      int
+	TotalUnacked    int
+	TotalBlocked    int
+	TotalWaiting    int
+	TotalCancelable int
+	ByScheduler     map[string]*SchedulerStats
 }
 
 // SchedulerStats returns the stats per scheduler
@@ -769,6 +834,18 @@ func (p *PendingEvaluations) Pop() interface{} {
 	return e
 }
 
+// Newest returns the most recently modified evaluation
+func (p PendingEvaluations) Newest() *structs.Evaluation {
+	var newest *structs.Evaluation
+	for _, eval := range p {
+		if newest == nil || eval.ModifyIndex > newest.ModifyIndex ||
+			(eval.ModifyIndex == newest.ModifyIndex && eval.CreateIndex > newest.CreateIndex) {
+			newest = eval
+		}
+	}
+	return newest
+}
+
 // Peek is used to peek at the next element that would be popped
 func (p PendingEvaluations) Peek() *structs.Evaluation {
 	n := len(p)
@@ -452,7 +452,7 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	// Check the stats
+	// Check the stats. The blocked eval2 is superseded by eval3.
 	stats = b.Stats()
 	if stats.TotalReady != 2 {
 		t.Fatalf("bad: %#v", stats)
@@ -460,7 +460,10 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if stats.TotalUnacked != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalBlocked != 2 {
+	if stats.TotalBlocked != 1 {
+		t.Fatalf("bad: %#v", stats)
+	}
+	if stats.TotalCancelable != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
 
@@ -469,7 +472,7 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out != eval2 {
+	if out != eval3 {
 		t.Fatalf("bad : %#v", out)
 	}
 
@@ -481,19 +484,19 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if stats.TotalUnacked != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalBlocked != 2 {
+	if stats.TotalBlocked != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
 
 	// Ack out
-	err = b.Ack(eval2.ID, token)
+	err = b.Ack(eval3.ID, token)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	// Check the stats
 	stats = b.Stats()
-	if stats.TotalReady != 2 {
+	if stats.TotalReady != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
 	if stats.TotalUnacked != 0 {
@@ -508,13 +511,13 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out != eval3 {
+	if out != eval4 {
 		t.Fatalf("bad : %#v", out)
 	}
 
 	// Check the stats
 	stats = b.Stats()
-	if stats.TotalReady != 1 {
+	if stats.TotalReady != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
 	if stats.TotalUnacked != 1 {
@@ -525,7 +528,7 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	}
 
 	// Ack out
-	err = b.Ack(eval3.ID, token)
+	err = b.Ack(eval4.ID, token)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -538,7 +541,7 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if stats.TotalUnacked != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalBlocked != 1 {
+	if stats.TotalBlocked != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
 
@@ -547,7 +550,7 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out != eval4 {
+	if out != eval5 {
 		t.Fatalf("bad : %#v", out)
 	}
 
@@ -559,19 +562,19 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 	if stats.TotalUnacked != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalBlocked != 1 {
+	if stats.TotalBlocked != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
 
 	// Ack out
-	err = b.Ack(eval4.ID, token)
+	err = b.Ack(eval5.ID, token)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	// Check the stats
 	stats = b.Stats()
-	if stats.TotalReady != 1 {
+	if stats.TotalReady != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
 	if stats.TotalUnacked != 0 {
@@ -581,44 +584,138 @@ func TestEvalBroker_Serialize_DuplicateJobID(t *testing.T) {
 		t.Fatalf("bad: %#v", stats)
 	}
 
-	// Dequeue should work
-	out, token, err = b.Dequeue(defaultSched, time.Second)
+	// The superseded evaluation should be cancelable
+	cancelable := b.GetCancelable(time.Second)
+	if len(cancelable) != 1 || cancelable[0] != eval2 {
+		t.Fatalf("bad: %#v", cancelable)
+	}
+	if stats := b.Stats(); stats.TotalCancelable != 0 {
+		t.Fatalf("bad: %#v", stats)
+	}
+}
+
+// Ensure that only the newest blocked evaluation for a job is processed
+func TestEvalBroker_Coalesce_Blocked(t *testing.T) {
+	t.Parallel()
+	b := testBroker(t, 0)
+	b.SetEnabled(true)
+
+	eval := mock.Eval()
+	b.Enqueue(eval)
+
+	// Block many evaluations for the same job while the first is outstanding
+	out, token, err := b.Dequeue(defaultSched, time.Second)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	if out != eval5 {
+	if out != eval {
 		t.Fatalf("bad : %#v", out)
 	}
 
-	// Check the stats
-	stats = b.Stats()
-	if stats.TotalReady != 0 {
+	var newest *structs.Evaluation
+	for i := 0; i < cancelableBatchSize+10; i++ {
+		blocked := mock.Eval()
+		blocked.JobID = eval.JobID
+		blocked.ModifyIndex = uint64(1000 - i)
+		if newest == nil {
+			newest = blocked
+		}
+		b.Enqueue(blocked)
+	}
+	if stats := b.Stats(); stats.TotalBlocked != cancelableBatchSize+10 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalUnacked != 1 {
+
+	// Ack out and ensure only the newest evaluation is ready
+	if err := b.Ack(eval.ID, token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	stats := b.Stats()
+	if stats.TotalReady != 1 || stats.TotalBlocked != 0 {
 		t.Fatalf("bad: %#v", stats)
 	}
-	if stats.TotalBlocked != 0 {
+	if stats.TotalCancelable != cancelableBatchSize+9 {
 		t.Fatalf("bad: %#v", stats)
 	}
 
-	// Ack out
-	err = b.Ack(eval5.ID, token)
+	out, _, err = b.Dequeue(defaultSched, time.Second)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
+	if out != newest {
+		t.Fatalf("bad : %#v", out)
+	}
 
-	// Check the stats
-	stats = b.Stats()
-	if stats.TotalReady != 0 {
-		t.Fatalf("bad: %#v", stats)
+	// The superseded evaluations are returned in batches
+	if cancelable := b.GetCancelable(time.Second); len(cancelable) != cancelableBatchSize {
+		t.Fatalf("bad: %d", len(cancelable))
 	}
-	if stats.TotalUnacked != 0 {
-		t.Fatalf("bad: %#v", stats)
+	if cancelable := b.GetCancelable(time.Second); len(cancelable) != 9 {
+		t.Fatalf("bad: %d", len(cancelable))
 	}
-	if stats.TotalBlocked != 0 {
+	if cancelable := b.GetCancelable(10 * time.Millisecond); cancelable != nil {
+		t.Fatalf("bad: %#v", cancelable)
+	}
+}
+
+// Ensure superseded evaluations that fail to be cancelled can be re-enqueued
+func TestEvalBroker_Coalesce_Requeue(t *testing.T) {
+	t.Parallel()
+	b := testBroker(t, 0)
+	b.SetEnabled(true)
+
+	eval := mock.Eval()
+	b.Enqueue(eval)
+	out, token, err := b.Dequeue(defaultSched, time.Second)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != eval {
+		t.Fatalf("bad : %#v", out)
+	}
+
+	// Supersede eval2 with eval3 while the first eval is outstanding
+	eval2 := mock.Eval()
+	eval2.JobID = eval.JobID
+	eval2.ModifyIndex = 1
+	eval3 := mock.Eval()
+	eval3.JobID = eval.JobID
+	eval3.ModifyIndex = 2
+	b.Enqueue(eval2)
+	b.Enqueue(eval3)
+	if err := b.Ack(eval.ID, token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cancelable := b.GetCancelable(time.Second)
+	if len(cancelable) != 1 || cancelable[0] != eval2 {
+		t.Fatalf("bad: %#v", cancelable)
+	}
+
+	// Re-enqueue the superseded eval as if cancelling it failed. It is
+	// tracked again and blocked behind the newer eval.
+	b.Enqueue(eval2)
+	if stats := b.Stats(); stats.TotalReady != 1 || stats.TotalBlocked != 1 {
 		t.Fatalf("bad: %#v", stats)
 	}
+
+	out, token, err = b.Dequeue(defaultSched, time.Second)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != eval3 {
+		t.Fatalf("bad : %#v", out)
+	}
+	if err := b.Ack(eval3.ID, token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, _, err = b.Dequeue(defaultSched, time.Second)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != eval2 {
+		t.Fatalf("bad : %#v", out)
+	}
 }
 
 func TestEvalBroker_Enqueue_Disable(t *testing.T) {
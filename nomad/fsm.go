@@ -44,6 +44,10 @@ const (
 	DeploymentSnapshot
 	ACLPolicySnapshot
 	ACLTokenSnapshot
+	SchedulerConfigSnapshot
+	NodePoolSnapshot
+	JobSubmissionSnapshot
+	ConsulAccessorSnapshot
 )
 
 // LogApplier is the definition of a function that can apply a Raft log
@@ -209,6 +213,18 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyACLTokenDelete(buf[1:], log.Index)
 	case structs.ACLTokenBootstrapRequestType:
 		return n.applyACLTokenBootstrap(buf[1:], log.Index)
+	case structs.SchedulerConfigRequestType:
+		return n.applySchedulerConfigUpdate(buf[1:], log.Index)
+	case structs.NodePoolUpsertRequestType:
+		return n.applyNodePoolUpsert(buf[1:], log.Index)
+	case structs.NodePoolDeleteRequestType:
+		return n.applyNodePoolDelete(buf[1:], log.Index)
+	case structs.PeriodicPauseRequestType:
+		return n.applyPeriodicPause(buf[1:], log.Index)
+	case structs.ConsulAccessorRegisterRequestType:
+		return n.applyUpsertConsulAccessor(buf[1:], log.Index)
+	case structs.ConsulAccessorDeregisterRequestType:
+		return n.applyDeregisterConsulAccessor(buf[1:], log.Index)
 	}
 
 	// Check enterprise only message types.
@@ -267,7 +283,7 @@ func (n *nomadFSM) applyStatusUpdate(buf []byte, index uint64) interface{} {
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
-	if err := n.state.UpdateNodeStatus(index, req.NodeID, req.Status); err != nil {
+	if err := n.state.UpdateNodeStatus(index, req.NodeID, req.Status, req.UpdatedAt); err != nil {
 		n.logger.Printf("[ERR] nomad.fsm: UpdateNodeStatus failed: %v", err)
 		return err
 	}
@@ -318,7 +334,7 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 	 */
 	req.Job.Canonicalize()
 
-	if err := n.state.UpsertJob(index, req.Job); err != nil {
+	if err := n.state.UpsertJobWithSubmission(index, req.Job, req.Submission); err != nil {
 		n.logger.Printf("[ERR] nomad.fsm: UpsertJob failed: %v", err)
 		return err
 	}
@@ -359,6 +375,9 @@ func (n *nomadFSM) applyUpsertJob(buf []byte, index uint64) interface{} {
 				n.logger.Printf("[ERR] nomad.fsm: UpsertPeriodicLaunch failed: %v", err)
 				return err
 			}
+		} else if prevLaunch.Paused {
+			// Keep the job paused if it was stopped and started again
+			n.periodicDispatcher.SetPaused(req.Namespace, req.Job.ID, true)
 		}
 	}
 
@@ -600,6 +619,39 @@ func (n *nomadFSM) applyUpsertVaultAccessor(buf []byte, index uint64) interface{
 	return nil
 }
 
+// applyUpsertConsulAccessor stores the Consul accessors for a given allocation
+// and task
+func (n *nomadFSM) applyUpsertConsulAccessor(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_consul_accessor"}, time.Now())
+	var req structs.ConsulAccessorsRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertConsulAccessors(index, req.Accessors); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertConsulAccessors failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// applyDeregisterConsulAccessor deregisters a set of Consul accessors
+func (n *nomadFSM) applyDeregisterConsulAccessor(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "deregister_consul_accessor"}, time.Now())
+	var req structs.ConsulAccessorsRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteConsulAccessors(index, req.Accessors); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: DeregisterConsulAccessor failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 // applyDeregisterVaultAccessor deregisters a set of Vault accessors
 func (n *nomadFSM) applyDeregisterVaultAccessor(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "deregister_vault_accessor"}, time.Now())
@@ -801,6 +853,77 @@ func (n *nomadFSM) applyACLTokenBootstrap(buf []byte, index uint64) interface{}
 	return nil
 }
 
+// applySchedulerConfigUpdate is used to update the scheduler configuration
+func (n *nomadFSM) applySchedulerConfigUpdate(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_scheduler_config"}, time.Now())
+	var req structs.SchedulerSetConfigRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if req.CAS {
+		applied, err := n.state.SchedulerCASConfig(index, req.Config.ModifyIndex, &req.Config)
+		if err != nil {
+			n.logger.Printf("[ERR] nomad.fsm: SchedulerCASConfig failed: %v", err)
+			return err
+		}
+		return applied
+	}
+
+	if err := n.state.SchedulerSetConfig(index, &req.Config); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: SchedulerSetConfig failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// applyNodePoolUpsert is used to upsert a set of node pools
+func (n *nomadFSM) applyNodePoolUpsert(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_node_pool_upsert"}, time.Now())
+	var req structs.NodePoolUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertNodePools(index, req.NodePools); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpsertNodePools failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// applyNodePoolDelete is used to delete a set of node pools
+func (n *nomadFSM) applyNodePoolDelete(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_node_pool_delete"}, time.Now())
+	var req structs.NodePoolDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteNodePools(index, req.Names); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: DeleteNodePools failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (n *nomadFSM) applyPeriodicPause(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_periodic_pause"}, time.Now())
+	var req structs.PeriodicPauseRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpdatePeriodicLaunchPaused(index, req.Namespace, req.JobID, req.Paused); err != nil {
+		n.logger.Printf("[ERR] nomad.fsm: UpdatePeriodicLaunchPaused failed: %v", err)
+		return err
+	}
+
+	// Pause or resume the launches if we are the leader
+	n.periodicDispatcher.SetPaused(req.Namespace, req.JobID, req.Paused)
+	return nil
+}
+
 func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
 	// Create a new snapshot
 	snap, err := n.state.Snapshot()
@@ -1013,6 +1136,42 @@ func (n *nomadFSM) Restore(old io.ReadCloser) error {
 				return err
 			}
 
+		case SchedulerConfigSnapshot:
+			config := new(structs.SchedulerConfiguration)
+			if err := dec.Decode(config); err != nil {
+				return err
+			}
+			if err := restore.SchedulerConfigRestore(config); err != nil {
+				return err
+			}
+
+		case NodePoolSnapshot:
+			pool := new(structs.NodePool)
+			if err := dec.Decode(pool); err != nil {
+				return err
+			}
+			if err := restore.NodePoolRestore(pool); err != nil {
+				return err
+			}
+
+		case JobSubmissionSnapshot:
+			sub := new(structs.JobSubmission)
+			if err := dec.Decode(sub); err != nil {
+				return err
+			}
+			if err := restore.JobSubmissionRestore(sub); err != nil {
+				return err
+			}
+
+		case ConsulAccessorSnapshot:
+			accessor := new(structs.ConsulAccessor)
+			if err := dec.Decode(accessor); err != nil {
+				return err
+			}
+			if err := restore.ConsulAccessorRestore(accessor); err != nil {
+				return err
+			}
+
 		default:
 			// Check if this is an enterprise only object being restored
 			restorer, ok := n.enterpriseRestorers[snapType]
@@ -1091,6 +1250,13 @@ func (n *nomadFSM) reconcileQueuedAllocations(index uint64) error {
 			break
 		}
 		job := rawJob.(*structs.Job)
+
+		// Jobs handled by scheduler plugins are skipped since the plugins
+		// may not be loaded on every server
+		if _, ok := scheduler.BuiltinSchedulers[job.Type]; !ok {
+			continue
+		}
+
 		planner := &scheduler.Harness{
 			State: &snap.StateStore,
 		}
@@ -1237,6 +1403,22 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 		sink.Cancel()
 		return err
 	}
+	if err := s.persistSchedulerConfig(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistNodePools(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistJobSubmissions(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
+	if err := s.persistConsulAccessors(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
 	if err := s.persistEnterpriseTables(sink, encoder); err != nil {
 		sink.Cancel()
 		return err
@@ -1461,6 +1643,31 @@ func (s *nomadSnapshot) persistVaultAccessors(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistConsulAccessors(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+
+	ws := memdb.NewWatchSet()
+	accessors, err := s.snap.ConsulAccessors(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := accessors.Next()
+		if raw == nil {
+			break
+		}
+
+		accessor := raw.(*structs.ConsulAccessor)
+
+		sink.Write([]byte{byte(ConsulAccessorSnapshot)})
+		if err := encoder.Encode(accessor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *nomadSnapshot) persistJobVersions(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	// Get all the jobs
@@ -1573,6 +1780,77 @@ func (s *nomadSnapshot) persistACLTokens(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistSchedulerConfig(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get the scheduler config
+	_, config, err := s.snap.SchedulerConfig()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+
+	// Write out the scheduler config
+	sink.Write([]byte{byte(SchedulerConfigSnapshot)})
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistNodePools(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the node pools
+	ws := memdb.NewWatchSet()
+	pools, err := s.snap.NodePools(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := pools.Next()
+		if raw == nil {
+			break
+		}
+
+		// Write out a node pool registration
+		pool := raw.(*structs.NodePool)
+		sink.Write([]byte{byte(NodePoolSnapshot)})
+		if err := encoder.Encode(pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistJobSubmissions(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the job submissions
+	ws := memdb.NewWatchSet()
+	subs, err := s.snap.JobSubmissions(ws)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Get the next item
+		raw := subs.Next()
+		if raw == nil {
+			break
+		}
+
+		// Write out a job submission
+		sub := raw.(*structs.JobSubmission)
+		sink.Write([]byte{byte(JobSubmissionSnapshot)})
+		if err := encoder.Encode(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Release is a no-op, as we just need to GC the pointer
 // to the state store snapshot. There is nothing to explicitly
 // cleanup.
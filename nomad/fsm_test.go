@@ -331,6 +331,73 @@ func TestFSM_RegisterJob(t *testing.T) {
 	}
 }
 
+func TestFSM_PeriodicPause(t *testing.T) {
+	t.Parallel()
+	fsm := testFSM(t)
+
+	job := mock.PeriodicJob()
+	req := structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	buf, err := structs.Encode(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	pauseReq := structs.PeriodicPauseRequest{
+		JobID:  job.ID,
+		Paused: true,
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	buf, err = structs.Encode(structs.PeriodicPauseRequestType, pauseReq)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify the launch is paused
+	ws := memdb.NewWatchSet()
+	launchOut, err := fsm.State().PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if launchOut == nil || !launchOut.Paused {
+		t.Fatalf("bad: %#v", launchOut)
+	}
+
+	// Verify it was paused in the periodic runner, also after the job is
+	// registered again.
+	tuple := structs.NamespacedID{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+	}
+	if _, ok := fsm.periodicDispatcher.paused[tuple]; !ok {
+		t.Fatal("job not paused in periodic runner")
+	}
+
+	fsm.periodicDispatcher.Remove(job.Namespace, job.ID)
+	buf, err = structs.Encode(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+	if _, ok := fsm.periodicDispatcher.paused[tuple]; !ok {
+		t.Fatal("job not paused in periodic runner after registration")
+	}
+}
+
 func TestFSM_RegisterJob_BadNamespace(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -1138,6 +1205,55 @@ func TestFSM_DeregisterVaultAccessor(t *testing.T) {
 	}
 }
 
+func TestFSM_UpsertDeregisterConsulAccessors(t *testing.T) {
+	t.Parallel()
+	fsm := testFSM(t)
+
+	ca := mock.ConsulAccessor()
+	ca2 := mock.ConsulAccessor()
+	req := structs.ConsulAccessorsRequest{
+		Accessors: []*structs.ConsulAccessor{ca, ca2},
+	}
+	buf, err := structs.Encode(structs.ConsulAccessorRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are registered
+	ws := memdb.NewWatchSet()
+	out, err := fsm.State().ConsulAccessorsByAlloc(ws, ca.AllocID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out) != 1 || out[0].Accessor != ca.Accessor || out[0].CreateIndex != 1 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Deregister the accessors
+	buf, err = structs.Encode(structs.ConsulAccessorDeregisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp = fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	iter, err := fsm.State().ConsulAccessors(ws)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if raw := iter.Next(); raw != nil {
+		t.Fatalf("not deleted: %#v", raw)
+	}
+}
+
 func TestFSM_ApplyPlanResults(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -1642,6 +1758,59 @@ func TestFSM_DeleteACLPolicies(t *testing.T) {
 	assert.Nil(t, out)
 }
 
+func TestFSM_UpsertNodePools(t *testing.T) {
+	t.Parallel()
+	fsm := testFSM(t)
+
+	pool := mock.NodePool()
+	req := structs.NodePoolUpsertRequest{
+		NodePools: []*structs.NodePool{pool},
+	}
+	buf, err := structs.Encode(structs.NodePoolUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are registered
+	ws := memdb.NewWatchSet()
+	out, err := fsm.State().NodePoolByName(ws, pool.Name)
+	assert.Nil(t, err)
+	assert.NotNil(t, out)
+}
+
+func TestFSM_DeleteNodePools(t *testing.T) {
+	t.Parallel()
+	fsm := testFSM(t)
+
+	pool := mock.NodePool()
+	err := fsm.State().UpsertNodePools(1000, []*structs.NodePool{pool})
+	assert.Nil(t, err)
+
+	req := structs.NodePoolDeleteRequest{
+		Names: []string{pool.Name},
+	}
+	buf, err := structs.Encode(structs.NodePoolDeleteRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify we are NOT registered
+	ws := memdb.NewWatchSet()
+	out, err := fsm.State().NodePoolByName(ws, pool.Name)
+	assert.Nil(t, err)
+	assert.Nil(t, out)
+}
+
 func TestFSM_BootstrapACLTokens(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -1712,6 +1881,53 @@ func TestFSM_UpsertACLTokens(t *testing.T) {
 	assert.NotNil(t, out)
 }
 
+func TestFSM_SchedulerConfig(t *testing.T) {
+	t.Parallel()
+	fsm := testFSM(t)
+
+	req := structs.SchedulerSetConfigRequest{
+		Config: structs.SchedulerConfiguration{
+			MemoryOversubscriptionEnabled: true,
+		},
+	}
+	buf, err := structs.Encode(structs.SchedulerConfigRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp := fsm.Apply(makeLog(buf))
+	if resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// Verify the configuration was stored
+	_, config, err := fsm.State().SchedulerConfig()
+	assert.Nil(t, err)
+	if assert.NotNil(t, config) {
+		assert.True(t, config.MemoryOversubscriptionEnabled)
+	}
+
+	// A CAS update with a stale index isn't applied
+	req.CAS = true
+	req.Config = structs.SchedulerConfiguration{
+		SchedulerAlgorithm: structs.SchedulerAlgorithmSpread,
+		ModifyIndex:        config.ModifyIndex + 1,
+	}
+	buf, err = structs.Encode(structs.SchedulerConfigRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	resp = fsm.Apply(makeLog(buf))
+	if applied, ok := resp.(bool); !ok || applied {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	_, config, err = fsm.State().SchedulerConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, structs.SchedulerAlgorithm(""), config.SchedulerAlgorithm)
+}
+
 func TestFSM_DeleteACLTokens(t *testing.T) {
 	t.Parallel()
 	fsm := testFSM(t)
@@ -2108,6 +2324,66 @@ func TestFSM_SnapshotRestore_ACLPolicy(t *testing.T) {
 	assert.Equal(t, p2, out2)
 }
 
+func TestFSM_SnapshotRestore_NodePools(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	p1 := mock.NodePool()
+	p2 := mock.NodePool()
+	state.UpsertNodePools(1000, []*structs.NodePool{p1, p2})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	ws := memdb.NewWatchSet()
+	out1, _ := state2.NodePoolByName(ws, p1.Name)
+	out2, _ := state2.NodePoolByName(ws, p2.Name)
+	assert.Equal(t, p1, out1)
+	assert.Equal(t, p2, out2)
+}
+
+func TestFSM_SnapshotRestore_JobSubmissions(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	job := mock.Job()
+	sub := &structs.JobSubmission{
+		Source: `job "example" {}`,
+		Format: structs.JobSubmissionFormatHCL2,
+	}
+	state.UpsertJobWithSubmission(1000, job, sub)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	ws := memdb.NewWatchSet()
+	expected, _ := state.JobSubmission(ws, job.Namespace, job.ID, job.Version)
+	out, _ := state2.JobSubmission(ws, job.Namespace, job.ID, job.Version)
+	assert.NotNil(t, out)
+	assert.Equal(t, expected, out)
+}
+
+func TestFSM_SnapshotRestore_ConsulAccessors(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	a1 := mock.ConsulAccessor()
+	a2 := mock.ConsulAccessor()
+	state.UpsertConsulAccessors(1000, []*structs.ConsulAccessor{a1, a2})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	ws := memdb.NewWatchSet()
+	out1, _ := state2.ConsulAccessorsByAlloc(ws, a1.AllocID)
+	out2, _ := state2.ConsulAccessorsByAlloc(ws, a2.AllocID)
+	assert.Equal(t, []*structs.ConsulAccessor{a1}, out1)
+	assert.Equal(t, []*structs.ConsulAccessor{a2}, out2)
+}
+
 func TestFSM_SnapshotRestore_ACLTokens(t *testing.T) {
 	t.Parallel()
 	// Add some state
@@ -2127,6 +2403,25 @@ func TestFSM_SnapshotRestore_ACLTokens(t *testing.T) {
 	assert.Equal(t, tk2, out2)
 }
 
+func TestFSM_SnapshotRestore_SchedulerConfig(t *testing.T) {
+	t.Parallel()
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	config := &structs.SchedulerConfiguration{
+		MemoryOversubscriptionEnabled: true,
+	}
+	state.SchedulerSetConfig(1000, config)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	index, out, err := state2.SchedulerConfig()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1000, index)
+	assert.Equal(t, config, out)
+}
+
 func TestFSM_SnapshotRestore_AddMissingSummary(t *testing.T) {
 	t.Parallel()
 	// Add some state
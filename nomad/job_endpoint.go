@@ -29,6 +29,14 @@ const (
 	// DispatchPayloadSizeLimit is the maximum size of the uncompressed input
 	// data payload.
 	DispatchPayloadSizeLimit = 16 * 1024
+
+	// DefaultCapacityLimit is the number of instances a capacity forecast
+	// places if the request does not set a limit.
+	DefaultCapacityLimit = 1000
+
+	// MaxCapacityLimit is the maximum number of instances a capacity forecast
+	// may place.
+	MaxCapacityLimit = 10000
 )
 
 var (
@@ -65,13 +73,33 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	setImplicitConstraints(args.Job)
 
 	// Validate the job and capture any warnings
-	err, warnings := validateJob(args.Job)
+	err, warnings := validateJob(args.Job, j.srv.schedulerPluginTypes())
+	if err != nil {
+		return err
+	}
+
+	// Warn if the memory max of tasks will be ignored
+	memoryWarnings, err := j.memoryOversubscriptionWarnings(args.Job)
 	if err != nil {
 		return err
 	}
 
+	// Validate the job source, dropping it rather than failing the
+	// registration if it is too large to store
+	var submissionWarning error
+	if args.Submission != nil {
+		if err := args.Submission.Validate(); err != nil {
+			return err
+		}
+		if size := args.Submission.Size(); size > structs.MaxJobSubmissionSize {
+			submissionWarning = fmt.Errorf("job source of %d bytes exceeds the maximum of %d bytes and will not be stored",
+				size, structs.MaxJobSubmissionSize)
+			args.Submission = nil
+		}
+	}
+
 	// Set the warning message
-	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings)
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, memoryWarnings, submissionWarning)
 
 	// Check job submission permissions
 	if aclObj, err := j.srv.ResolveToken(args.SecretID); err != nil {
@@ -80,6 +108,9 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		if !aclObj.AllowNsOp(structs.DefaultNamespace, acl.NamespaceCapabilitySubmitJob) {
 			return structs.ErrPermissionDenied
 		}
+		if !aclObj.AllowNamespaceNodePool(structs.DefaultNamespace, args.Job.NodePool) {
+			return structs.ErrPermissionDenied
+		}
 		// Check if override is set and we do not have permissions
 		if args.PolicyOverride {
 			if !aclObj.AllowNsOp(structs.DefaultNamespace, acl.NamespaceCapabilitySentinelOverride) {
@@ -101,6 +132,13 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		return err
 	}
 
+	// Ensure the node pool exists
+	if pool, err := snap.NodePoolByName(ws, args.Job.NodePool); err != nil {
+		return err
+	} else if pool == nil {
+		return fmt.Errorf("job %q is in non-existent node pool %q", args.Job.ID, args.Job.NodePool)
+	}
+
 	// If EnforceIndex set, check it before trying to apply
 	if args.EnforceIndex {
 		jmi := args.JobModifyIndex
@@ -156,10 +194,25 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 					return fmt.Errorf("Passed Vault Token doesn't allow access to the following policies: %s",
 						strings.Join(offending, ", "))
 				}
+
+				// Tasks may only create tokens from roles the token can use
+				for _, role := range structs.VaultRolesSet(policies) {
+					if err := vault.CheckTokenRole(context.Background(), args.Job.VaultToken, role); err != nil {
+						return err
+					}
+				}
 			}
 		}
 	}
 
+	// Ensure that the job has permissions to register its Consul services
+	if !j.srv.config.ConsulConfig.AllowsUnauthenticated() {
+		services := args.Job.ConsulServiceNames()
+		if err := j.srv.consulACLs.CheckServiceIdentities(args.Job.ConsulToken, services); err != nil {
+			return err
+		}
+	}
+
 	// Enforce Sentinel policies
 	policyWarnings, err := j.enforceSubmitJob(args.PolicyOverride, args.Job)
 	if err != nil {
@@ -167,11 +220,12 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	}
 	if policyWarnings != nil {
 		reply.Warnings = structs.MergeMultierrorWarnings(warnings,
-			canonicalizeWarnings, policyWarnings)
+			canonicalizeWarnings, memoryWarnings, submissionWarning, policyWarnings)
 	}
 
-	// Clear the Vault token
+	// Clear the Vault and Consul tokens
 	args.Job.VaultToken = ""
+	args.Job.ConsulToken = ""
 
 	// Check if the job has changed at all
 	if existingJob == nil || existingJob.SpecChanged(args.Job) {
@@ -368,7 +422,7 @@ func (j *Job) Validate(args *structs.JobValidateRequest, reply *structs.JobValid
 	setImplicitConstraints(args.Job)
 
 	// Validate the job and capture any warnings
-	err, warnings := validateJob(args.Job)
+	err, warnings := validateJob(args.Job, j.srv.schedulerPluginTypes())
 	if err != nil {
 		if merr, ok := err.(*multierror.Error); ok {
 			for _, err := range merr.Errors {
@@ -432,9 +486,16 @@ func (j *Job) Revert(args *structs.JobRevertRequest, reply *structs.JobRegisterR
 		return fmt.Errorf("job %q in namespace %q at version %d not found", args.JobID, args.RequestNamespace(), args.JobVersion)
 	}
 
+	// Carry over the source the version was submitted with
+	sub, err := snap.JobSubmission(ws, args.RequestNamespace(), args.JobID, args.JobVersion)
+	if err != nil {
+		return err
+	}
+
 	// Build the register request
 	reg := &structs.JobRegisterRequest{
 		Job:          jobV.Copy(),
+		Submission:   sub.Copy(),
 		WriteRequest: args.WriteRequest,
 	}
 
@@ -750,6 +811,53 @@ func (j *Job) GetJobVersions(args *structs.JobVersionsRequest,
 	return j.srv.blockingRPC(&opts)
 }
 
+// GetJobSubmission is used to retrieve the source a job version was
+// submitted with.
+func (j *Job) GetJobSubmission(args *structs.JobSubmissionRequest,
+	reply *structs.JobSubmissionResponse) error {
+	if done, err := j.srv.forward("Job.GetJobSubmission", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "get_job_submission"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the submission
+			out, err := state.JobSubmission(ws, args.RequestNamespace(), args.JobID, args.Version)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Submission = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the job submission table
+				index, err := state.Index("job_submission")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
 // List is used to list the jobs registered in the system
 func (j *Job) List(args *structs.JobListRequest,
 	reply *structs.JobListResponse) error {
@@ -991,6 +1099,320 @@ func (j *Job) LatestDeployment(args *structs.JobSpecificRequest,
 	return j.srv.blockingRPC(&opts)
 }
 
+// Capacity is used to forecast how many more instances of a task group of the
+// job fit in the cluster. The scheduler places the task group against a state
+// snapshot until the cluster is exhausted, without submitting the placements.
+func (j *Job) Capacity(args *structs.JobCapacityRequest, reply *structs.JobCapacityResponse) error {
+	if done, err := j.srv.forward("Job.Capacity", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "capacity"}, time.Now())
+
+	// Validate the arguments
+	if args.Job == nil {
+		return fmt.Errorf("Job required for capacity")
+	}
+	limit := args.Limit
+	switch {
+	case limit < 0:
+		return fmt.Errorf("Limit must not be negative: %d", limit)
+	case limit == 0:
+		limit = DefaultCapacityLimit
+	case limit > MaxCapacityLimit:
+		limit = MaxCapacityLimit
+	}
+
+	// Initialize the job fields (sets defaults and any necessary init work).
+	canonicalizeWarnings := args.Job.Canonicalize()
+
+	// Add implicit constraints
+	setImplicitConstraints(args.Job)
+
+	// Validate the job and capture any warnings
+	err, warnings := validateJob(args.Job, j.srv.schedulerPluginTypes())
+	if err != nil {
+		return err
+	}
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings)
+
+	// Determine the task group to forecast
+	tgName := args.TaskGroup
+	if tgName == "" {
+		if len(args.Job.TaskGroups) != 1 {
+			return fmt.Errorf("Task group required for jobs with %d task groups", len(args.Job.TaskGroups))
+		}
+		tgName = args.Job.TaskGroups[0].Name
+	}
+
+	// Check job submission permissions, which we assume is the same for
+	// forecasting capacity
+	if aclObj, err := j.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil {
+		if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+			return structs.ErrPermissionDenied
+		}
+		if !aclObj.AllowNamespaceNodePool(args.RequestNamespace(), args.Job.NodePool) {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	// Acquire a snapshot of the state
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	capacity, err := scheduler.Capacity(j.srv.logger, snap, args.Job, tgName, limit)
+	if err != nil {
+		return err
+	}
+	reply.Capacity = capacity
+	reply.Index, err = snap.LatestIndex()
+	return err
+}
+
+// Explain is used to diagnose why the allocations of a job are not placed
+func (j *Job) Explain(args *structs.JobSpecificRequest,
+	reply *structs.JobExplainResponse) error {
+	if done, err := j.srv.forward("Job.Explain", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "explain"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the job
+			job, err := state.JobByID(ws, args.RequestNamespace(), args.JobID)
+			if err != nil {
+				return err
+			}
+
+			reply.Explanation = nil
+			if job != nil {
+				reply.Explanation, err = explainJob(ws, state, job)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Use the last index that affected the jobs, evals or
+			// deployments
+			reply.Index = 0
+			for _, table := range []string{"jobs", "evals", "deployment"} {
+				index, err := state.Index(table)
+				if err != nil {
+					return err
+				}
+				if index > reply.Index {
+					reply.Index = index
+				}
+			}
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
+// explainReasonRank orders the types of explain reasons by relevance. Reasons
+// of the same rank are ordered by the number of nodes they apply to.
+var explainReasonRank = map[string]int{
+	structs.JobExplainReasonJob:        0,
+	structs.JobExplainReasonEval:       1,
+	structs.JobExplainReasonNodes:      2,
+	structs.JobExplainReasonConstraint: 3,
+	structs.JobExplainReasonClass:      3,
+	structs.JobExplainReasonExhausted:  3,
+	structs.JobExplainReasonDeployment: 4,
+	structs.JobExplainReasonBlocked:    5,
+}
+
+// explainJob builds the diagnosis of why the allocations of the job are not
+// placed from its latest evaluations and deployment.
+func explainJob(ws memdb.WatchSet, state *state.StateStore, job *structs.Job) (*structs.JobExplanation, error) {
+	exp := &structs.JobExplanation{
+		JobID:     job.ID,
+		Namespace: job.Namespace,
+		JobStatus: job.Status,
+	}
+
+	var reasons []*structs.JobExplainReason
+	addReason := func(typ, tg string, nodes int, format string, a ...interface{}) {
+		reasons = append(reasons, &structs.JobExplainReason{
+			Type:      typ,
+			TaskGroup: tg,
+			Message:   fmt.Sprintf(format, a...),
+			Nodes:     nodes,
+		})
+	}
+
+	switch {
+	case job.Stop:
+		addReason(structs.JobExplainReasonJob, "", 0, "The job is stopped")
+	case job.IsParameterized():
+		addReason(structs.JobExplainReasonJob, "", 0, "The job is parameterized and is only placed when dispatched")
+	case job.IsPeriodic():
+		addReason(structs.JobExplainReasonJob, "", 0, "The job is periodic and is only placed when launched")
+	}
+
+	// Find the latest evaluation, the latest processed evaluation and the
+	// blocked evaluation
+	evals, err := state.EvalsByJob(ws, job.Namespace, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	var latest, processed, blocked *structs.Evaluation
+	for _, eval := range evals {
+		if eval.Status == structs.EvalStatusBlocked {
+			if blocked == nil || eval.CreateIndex > blocked.CreateIndex {
+				blocked = eval
+			}
+			continue
+		}
+		if latest == nil || eval.CreateIndex > latest.CreateIndex {
+			latest = eval
+		}
+		if eval.TerminalStatus() && eval.Status != structs.EvalStatusCancelled &&
+			(processed == nil || eval.CreateIndex > processed.CreateIndex) {
+			processed = eval
+		}
+	}
+
+	if latest != nil {
+		exp.EvalID = latest.ID
+		exp.EvalStatus = latest.Status
+		exp.EvalStatusDescription = latest.StatusDescription
+
+		switch latest.Status {
+		case structs.EvalStatusFailed:
+			addReason(structs.JobExplainReasonEval, "", 0, "The latest evaluation failed: %s", latest.StatusDescription)
+		case structs.EvalStatusPending:
+			addReason(structs.JobExplainReasonEval, "", 0, "The latest evaluation has not been processed by a scheduler yet")
+		case structs.EvalStatusQueued:
+			addReason(structs.JobExplainReasonEval, "", 0, "The latest evaluation is queued: %s", latest.StatusDescription)
+		}
+	}
+
+	// Explain the placement failures of the latest processed evaluation
+	if processed != nil && len(processed.FailedTGAllocs) != 0 {
+		exp.UnplacedAllocations = make(map[string]int, len(processed.FailedTGAllocs))
+		tgs := make([]string, 0, len(processed.FailedTGAllocs))
+		for tg := range processed.FailedTGAllocs {
+			tgs = append(tgs, tg)
+		}
+		sort.Strings(tgs)
+
+		for _, tg := range tgs {
+			metric := processed.FailedTGAllocs[tg]
+			exp.UnplacedAllocations[tg] = metric.CoalescedFailures + 1
+
+			if metric.NodesEvaluated == 0 {
+				addReason(structs.JobExplainReasonNodes, tg, 0, "No nodes were eligible for evaluation")
+			}
+			for _, dc := range sortedMapKeys(metric.NodesAvailable) {
+				if metric.NodesAvailable[dc] == 0 {
+					addReason(structs.JobExplainReasonNodes, tg, 0, "No nodes are available in datacenter %q", dc)
+				}
+			}
+			for _, class := range sortedMapKeys(metric.ClassFiltered) {
+				num := metric.ClassFiltered[class]
+				addReason(structs.JobExplainReasonClass, tg, num, "Class %q filtered %d nodes", class, num)
+			}
+			for _, cs := range sortedMapKeys(metric.ConstraintFiltered) {
+				num := metric.ConstraintFiltered[cs]
+				addReason(structs.JobExplainReasonConstraint, tg, num, "Constraint %q filtered %d nodes", cs, num)
+			}
+			for _, dim := range sortedMapKeys(metric.DimensionExhausted) {
+				num := metric.DimensionExhausted[dim]
+				addReason(structs.JobExplainReasonExhausted, tg, num, "Dimension %q exhausted on %d nodes", dim, num)
+			}
+			for _, class := range sortedMapKeys(metric.ClassExhausted) {
+				num := metric.ClassExhausted[class]
+				addReason(structs.JobExplainReasonExhausted, tg, num, "Class %q exhausted on %d nodes", class, num)
+			}
+		}
+	}
+
+	// Explain what the blocked evaluation waits for
+	if blocked != nil {
+		exp.BlockedEvalID = blocked.ID
+		exp.ClassEligibility = blocked.ClassEligibility
+		exp.EscapedComputedClass = blocked.EscapedComputedClass
+
+		var eligible []string
+		for class, ok := range blocked.ClassEligibility {
+			if ok {
+				eligible = append(eligible, class)
+			}
+		}
+		sort.Strings(eligible)
+
+		switch {
+		case blocked.EscapedComputedClass:
+			addReason(structs.JobExplainReasonBlocked, "", 0, "Waiting for capacity on any node since the job's constraints escape the computed node classes")
+		case len(eligible) != 0:
+			addReason(structs.JobExplainReasonBlocked, "", 0, "Waiting for capacity on nodes of the eligible computed classes: %s", strings.Join(eligible, ", "))
+		default:
+			addReason(structs.JobExplainReasonBlocked, "", 0, "Waiting for a node that satisfies the job's constraints to join the cluster")
+		}
+	}
+
+	// Explain the state of the latest deployment
+	deploys, err := state.DeploymentsByJobID(ws, job.Namespace, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	var deploy *structs.Deployment
+	for _, d := range deploys {
+		if deploy == nil || d.CreateIndex > deploy.CreateIndex {
+			deploy = d
+		}
+	}
+	if deploy != nil {
+		exp.DeploymentID = deploy.ID
+		exp.DeploymentStatus = deploy.Status
+		exp.DeploymentStatusDescription = deploy.StatusDescription
+
+		switch deploy.Status {
+		case structs.DeploymentStatusFailed, structs.DeploymentStatusPaused:
+			addReason(structs.JobExplainReasonDeployment, "", 0, "The latest deployment is %s: %s", deploy.Status, deploy.StatusDescription)
+		}
+	}
+
+	// Rank the reasons
+	sort.SliceStable(reasons, func(i, j int) bool {
+		ri, rj := explainReasonRank[reasons[i].Type], explainReasonRank[reasons[j].Type]
+		if ri != rj {
+			return ri < rj
+		}
+		return reasons[i].Nodes > reasons[j].Nodes
+	})
+	exp.Reasons = reasons
+	return exp, nil
+}
+
+// sortedMapKeys returns the keys of the map in sorted order
+func sortedMapKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Plan is used to cause a dry-run evaluation of the Job and return the results
 // with a potential diff containing annotations.
 func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse) error {
@@ -1011,13 +1433,19 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	setImplicitConstraints(args.Job)
 
 	// Validate the job and capture any warnings
-	err, warnings := validateJob(args.Job)
+	err, warnings := validateJob(args.Job, j.srv.schedulerPluginTypes())
+	if err != nil {
+		return err
+	}
+
+	// Warn if the memory max of tasks will be ignored
+	memoryWarnings, err := j.memoryOversubscriptionWarnings(args.Job)
 	if err != nil {
 		return err
 	}
 
 	// Set the warning message
-	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings)
+	reply.Warnings = structs.MergeMultierrorWarnings(warnings, canonicalizeWarnings, memoryWarnings)
 
 	// Check job submission permissions, which we assume is the same for plan
 	if aclObj, err := j.srv.ResolveToken(args.SecretID); err != nil {
@@ -1026,6 +1454,9 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 		if !aclObj.AllowNsOp(structs.DefaultNamespace, acl.NamespaceCapabilitySubmitJob) {
 			return structs.ErrPermissionDenied
 		}
+		if !aclObj.AllowNamespaceNodePool(structs.DefaultNamespace, args.Job.NodePool) {
+			return structs.ErrPermissionDenied
+		}
 		// Check if override is set and we do not have permissions
 		if args.PolicyOverride {
 			if !aclObj.AllowNsOp(structs.DefaultNamespace, acl.NamespaceCapabilitySentinelOverride) {
@@ -1041,7 +1472,7 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	}
 	if policyWarnings != nil {
 		reply.Warnings = structs.MergeMultierrorWarnings(warnings,
-			canonicalizeWarnings, policyWarnings)
+			canonicalizeWarnings, memoryWarnings, policyWarnings)
 	}
 
 	// Acquire a snapshot of the state
@@ -1095,7 +1526,7 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	}
 
 	// Create the scheduler and run it
-	sched, err := scheduler.NewScheduler(eval.Type, j.srv.logger, snap, planner)
+	sched, err := j.srv.newScheduler(eval.Type, j.srv.logger, snap, planner)
 	if err != nil {
 		return err
 	}
@@ -1140,12 +1571,40 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	return nil
 }
 
+// memoryOversubscriptionWarnings returns a warning listing the tasks that set
+// a memory max if memory oversubscription is not enabled in the scheduler
+// configuration.
+func (j *Job) memoryOversubscriptionWarnings(job *structs.Job) (warnings, err error) {
+	_, config, err := j.srv.fsm.State().SchedulerConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config != nil && config.MemoryOversubscriptionEnabled {
+		return nil, nil
+	}
+
+	var tasks []string
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if task.Resources != nil && task.Resources.MemoryMaxMB != 0 {
+				tasks = append(tasks, fmt.Sprintf("%s.%s", tg.Name, task.Name))
+			}
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	return fmt.Errorf("Memory oversubscription is not enabled; memory_max of tasks %s will be ignored",
+		strings.Join(tasks, ", ")), nil
+}
+
 // validateJob validates a Job and task drivers and returns an error if there is
 // a validation problem or if the Job is of a type a user is not allowed to
-// submit.
-func validateJob(job *structs.Job) (invalid, warnings error) {
+// submit. Jobs may be of the types handled by the given scheduler plugins.
+func validateJob(job *structs.Job, schedulerPlugins []string) (invalid, warnings error) {
 	validationErrors := new(multierror.Error)
-	if err := job.Validate(); err != nil {
+	if err := job.ValidateWithSchedulers(schedulerPlugins); err != nil {
 		multierror.Append(validationErrors, err)
 	}
 
@@ -1249,6 +1708,11 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 		return fmt.Errorf("missing parameterized job ID")
 	}
 
+	// Serialize the dispatches so that the idempotency token and the running
+	// limit are checked against the previously dispatched jobs
+	j.srv.dispatchLock.Lock()
+	defer j.srv.dispatchLock.Unlock()
+
 	snap, err := j.srv.fsm.State().Snapshot()
 	if err != nil {
 		return err
@@ -1275,12 +1739,53 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 		return err
 	}
 
+	// Return the existing dispatched job if the request was already handled
+	if args.IdempotencyToken != "" {
+		existing, err := dispatchedJobByToken(ws, snap, parameterizedJob, args.IdempotencyToken)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			evals, err := snap.EvalsByJob(ws, existing.Namespace, existing.ID)
+			if err != nil {
+				return err
+			}
+
+			reply.DispatchedJobID = existing.ID
+			reply.JobCreateIndex = existing.CreateIndex
+			reply.Queued = existing.Status == structs.JobStatusQueued
+			reply.Index = existing.ModifyIndex
+			for _, eval := range evals {
+				if eval.CreateIndex > reply.EvalCreateIndex {
+					reply.EvalID = eval.ID
+					reply.EvalCreateIndex = eval.CreateIndex
+				}
+			}
+			return nil
+		}
+	}
+
+	// Queue the dispatched job if the parameterized job is at its running
+	// limit or other dispatched jobs are already waiting for it
+	queued := false
+	if max := parameterizedJob.ParameterizedJob.MaxRunning; max > 0 {
+		summary, err := snap.JobSummaryByID(ws, parameterizedJob.Namespace, parameterizedJob.ID)
+		if err != nil {
+			return err
+		}
+		if summary != nil && summary.Children != nil {
+			children := summary.Children
+			queued = children.Queued > 0 || children.Pending+children.Running >= int64(max)
+		}
+	}
+
 	// Derive the child job and commit it via Raft
 	dispatchJob := parameterizedJob.Copy()
 	dispatchJob.ParameterizedJob = nil
 	dispatchJob.ID = structs.DispatchedID(parameterizedJob.ID, time.Now())
 	dispatchJob.ParentID = parameterizedJob.ID
 	dispatchJob.Name = dispatchJob.ID
+	dispatchJob.DispatchIdempotencyToken = args.IdempotencyToken
 	dispatchJob.SetSubmitTime()
 
 	// Merge in the meta data
@@ -1327,6 +1832,10 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 			JobModifyIndex: jobCreateIndex,
 			Status:         structs.EvalStatusPending,
 		}
+		if queued {
+			eval.Status = structs.EvalStatusQueued
+			eval.StatusDescription = "waiting for the running limit of the parameterized job"
+		}
 		update := &structs.EvalUpdateRequest{
 			Evals:        []*structs.Evaluation{eval},
 			WriteRequest: structs.WriteRequest{Region: args.Region},
@@ -1343,11 +1852,28 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 		reply.EvalID = eval.ID
 		reply.EvalCreateIndex = evalIndex
 		reply.Index = evalIndex
+		reply.Queued = queued
 	}
 
 	return nil
 }
 
+// dispatchedJobByToken returns the dispatched job of the parameterized job
+// that was dispatched with the given idempotency token and is not dead.
+func dispatchedJobByToken(ws memdb.WatchSet, snap *state.StateSnapshot, parent *structs.Job, token string) (*structs.Job, error) {
+	iter, err := snap.JobsByIdempotencyToken(ws, parent.Namespace, parent.ID, token)
+	if err != nil {
+		return nil, err
+	}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		job := raw.(*structs.Job)
+		if job.Status != structs.JobStatusDead {
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
 // validateDispatchRequest returns whether the request is valid given the
 // parameterized job.
 func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job) error {
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	consulapi "github.com/hashicorp/consul/api"
 	memdb "github.com/hashicorp/go-memdb"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
@@ -14,6 +15,7 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/scheduler"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/assert"
@@ -141,6 +143,76 @@ func TestJobEndpoint_Register_ACL(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_NodePool_ACL(t *testing.T) {
+	t.Parallel()
+	s1, _ := testACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	pool := mock.NodePool()
+	if err := state.UpsertNodePools(1000, []*structs.NodePool{pool}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create a token that may only submit jobs to the default pool
+	policy := `
+namespace "default" {
+	capabilities = ["submit-job"]
+	node_pools = ["default"]
+}`
+	token := mock.CreatePolicyAndToken(t, state, 1001, "test-pool", policy)
+
+	// Try to register a job in the other pool, expect failure
+	job := mock.Job()
+	job.NodePool = pool.Name
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:   "global",
+			SecretID: token.SecretID,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Register in the default pool
+	job.NodePool = structs.NodePoolDefault
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestJobEndpoint_Register_InvalidNodePool(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request
+	job := mock.Job()
+	job.NodePool = "unknown"
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "non-existent node pool") {
+		t.Fatalf("expected node pool error: %v", err)
+	}
+}
+
 func TestJobEndpoint_Register_InvalidNamespace(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -210,6 +282,46 @@ func TestJobEndpoint_Register_InvalidDriverConfig(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_SchedulerPlugin(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request with a job of a custom type
+	job := mock.Job()
+	job.Type = "custom"
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// The job is rejected without a plugin handling its type
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "Invalid job type") {
+		t.Fatalf("expected invalid job type error; got %v", err)
+	}
+
+	// Register a scheduler plugin for the type
+	s1.schedulerPlugins["custom"] = scheduler.NewServiceScheduler
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure the evaluation is queued for the plugin
+	stats := s1.evalBroker.Stats()
+	if s, ok := stats.ByScheduler["custom"]; !ok || s.Ready != 1 {
+		t.Fatalf("bad: %#v", stats.ByScheduler)
+	}
+}
+
 func TestJobEndpoint_Register_Payload(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -243,6 +355,50 @@ func TestJobEndpoint_Register_Payload(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_MemoryMax(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create the register request with a task that sets a memory max
+	job := mock.Job()
+	job.TaskGroups[0].Tasks[0].Resources.MemoryMaxMB = 1024
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Memory oversubscription is disabled so expect a warning
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(resp.Warnings, "memory_max") {
+		t.Fatalf("expected a memory_max warning but got: %q", resp.Warnings)
+	}
+
+	// Enable memory oversubscription and register again
+	config := &structs.SchedulerConfiguration{MemoryOversubscriptionEnabled: true}
+	if err := s1.fsm.State().SchedulerSetConfig(1000, config); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var resp2 structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if strings.Contains(resp2.Warnings, "memory_max") {
+		t.Fatalf("unexpected memory_max warning: %q", resp2.Warnings)
+	}
+}
+
 func TestJobEndpoint_Register_Existing(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -714,6 +870,60 @@ func TestJobEndpoint_Register_Vault_NoToken(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_ConsulToken(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Enforce service identities
+	f := false
+	s1.config.ConsulConfig.AllowUnauthenticated = &f
+
+	// Replace the Consul ACL API on the server
+	acls := newMockConsulACLAPI()
+	acls.entries["management"] = &consulapi.ACLEntry{ID: "management", Type: consulapi.ACLManagementType}
+	s1.consulACLs = NewConsulACLsAPI(acls, s1.logger, nil)
+
+	// Registering a job with services but no Consul token fails
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "missing Consul token") {
+		t.Fatalf("expected missing Consul token error: %v", err)
+	}
+
+	// Registering with a token able to register the services succeeds
+	job.ConsulToken = "management"
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Check the token isn't stored
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected job")
+	}
+	if out.ConsulToken != "" {
+		t.Fatalf("ConsulToken not cleared")
+	}
+}
+
 func TestJobEndpoint_Register_Vault_Policies(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -855,6 +1065,61 @@ func TestJobEndpoint_Register_Vault_Policies(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_Vault_Role(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Enable vault
+	tr, f := true, false
+	s1.config.VaultConfig.Enabled = &tr
+	s1.config.VaultConfig.AllowUnauthenticated = &f
+
+	// Replace the Vault Client on the server
+	tvc := &TestVaultClient{}
+	s1.vault = tvc
+
+	// Add a token allowing the policy but not the role and one allowing both
+	badToken := uuid.Generate()
+	tvc.SetLookupTokenAllowedPolicies(badToken, []string{"foo"})
+
+	goodToken := uuid.Generate()
+	tvc.SetLookupTokenAllowedPolicies(goodToken, []string{"foo"})
+	tvc.SetTokenRoles(goodToken, []string{"web"})
+
+	// Create the register request with a job asking for a vault role
+	job := mock.Job()
+	job.VaultToken = badToken
+	job.TaskGroups[0].Tasks[0].Vault = &structs.Vault{
+		Policies:   []string{"foo"},
+		Role:       "web",
+		ChangeMode: structs.VaultChangeModeRestart,
+	}
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), `creating tokens from role "web"`) {
+		t.Fatalf("expected role permission denied error: %v", err)
+	}
+
+	// Use the good token
+	job.VaultToken = goodToken
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
 func TestJobEndpoint_Revert(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -2212,6 +2477,148 @@ func TestJobEndpoint_GetJobVersions_ACL(t *testing.T) {
 	assert.Equal(versions[1].ID, job.ID)
 }
 
+func TestJobEndpoint_GetJobSubmission(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a job with its source
+	job := mock.Job()
+	reg := &structs.JobRegisterRequest{
+		Job: job,
+		Submission: &structs.JobSubmission{
+			Source:        `job "example" {}`,
+			Format:        structs.JobSubmissionFormatHCL2,
+			VariableFlags: map[string]string{"image": "redis"},
+		},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &resp))
+
+	// Register a new version with a source too large to store
+	job.Priority = 100
+	reg.Submission = &structs.JobSubmission{
+		Source: strings.Repeat("#", structs.MaxJobSubmissionSize+1),
+		Format: structs.JobSubmissionFormatHCL2,
+	}
+	var resp2 structs.JobRegisterResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &resp2))
+	assert.Contains(resp2.Warnings, "will not be stored")
+
+	// Lookup the source of the first version
+	get := &structs.JobSubmissionRequest{
+		JobID:   job.ID,
+		Version: 0,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var subResp structs.JobSubmissionResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &subResp))
+	assert.EqualValues(resp.JobModifyIndex, subResp.Index)
+	assert.NotNil(subResp.Submission)
+	assert.Equal(`job "example" {}`, subResp.Submission.Source)
+	assert.Equal(map[string]string{"image": "redis"}, subResp.Submission.VariableFlags)
+
+	// The second version has no source
+	get.Version = 1
+	var subResp2 structs.JobSubmissionResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &subResp2))
+	assert.Nil(subResp2.Submission)
+
+	// Reverting to the first version carries over its source
+	revert := &structs.JobRevertRequest{
+		JobID:      job.ID,
+		JobVersion: 0,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var revertResp structs.JobRegisterResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Revert", revert, &revertResp))
+
+	get.Version = 2
+	var subResp3 structs.JobSubmissionResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &subResp3))
+	assert.NotNil(subResp3.Submission)
+	assert.Equal(`job "example" {}`, subResp3.Submission.Source)
+
+	// Registering with an invalid format fails
+	reg.Submission = &structs.JobSubmission{Format: "yaml"}
+	var resp3 structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &resp3)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid job submission format")
+}
+
+func TestJobEndpoint_GetJobSubmission_ACL(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s1, root := testACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// Create a job with its source
+	job := mock.Job()
+	sub := &structs.JobSubmission{
+		Source: `job "example" {}`,
+		Format: structs.JobSubmissionFormatHCL2,
+	}
+	assert.Nil(state.UpsertJobWithSubmission(10, job, sub))
+
+	get := &structs.JobSubmissionRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Attempt to fetch without a token should fail
+	var resp structs.JobSubmissionResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &resp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Expect failure for request with an invalid token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityListJobs}))
+
+	get.SecretID = invalidToken.SecretID
+	var invalidResp structs.JobSubmissionResponse
+	err = msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &invalidResp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Expect success for request with a valid management token
+	get.SecretID = root.SecretID
+	var validResp structs.JobSubmissionResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &validResp))
+
+	// Expect success for request with a valid token
+	validToken := mock.CreatePolicyAndToken(t, state, 1005, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+
+	get.SecretID = validToken.SecretID
+	var validResp2 structs.JobSubmissionResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", get, &validResp2))
+	assert.NotNil(validResp2.Submission)
+	assert.Equal(sub.Source, validResp2.Submission.Source)
+}
+
 func TestJobEndpoint_GetJobVersions_Diff(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, nil)
@@ -3717,7 +4124,7 @@ func TestJobEndpoint_ValidateJob_InvalidDriverConf(t *testing.T) {
 		"foo": "bar",
 	}
 
-	err, warnings := validateJob(job)
+	err, warnings := validateJob(job, nil)
 	if err == nil || !strings.Contains(err.Error(), "-> config") {
 		t.Fatalf("Expected config error; got %v", err)
 	}
@@ -3738,7 +4145,7 @@ func TestJobEndpoint_ValidateJob_InvalidSignals(t *testing.T) {
 		ChangeSignal: "SIGUSR1",
 	}
 
-	err, warnings := validateJob(job)
+	err, warnings := validateJob(job, nil)
 	if err == nil || !strings.Contains(err.Error(), "support sending signals") {
 		t.Fatalf("Expected signal feasibility error; got %v", err)
 	}
@@ -4168,3 +4575,361 @@ func TestJobEndpoint_Dispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestJobEndpoint_Dispatch_IdempotencyToken(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	// Register a parameterized job
+	job := mock.Job()
+	job.Type = structs.JobTypeBatch
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{}
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp))
+
+	// Dispatch it twice with the same token
+	req := &structs.JobDispatchRequest{
+		JobID:            job.ID,
+		IdempotencyToken: "foo",
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp1, resp2 structs.JobDispatchResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp1))
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp2))
+	assert.NotEmpty(resp1.DispatchedJobID)
+	assert.Equal(resp1.DispatchedJobID, resp2.DispatchedJobID)
+	assert.Equal(resp1.JobCreateIndex, resp2.JobCreateIndex)
+	assert.Equal(resp1.EvalID, resp2.EvalID)
+
+	out, err := s1.fsm.State().JobByID(nil, job.Namespace, resp1.DispatchedJobID)
+	assert.Nil(err)
+	assert.NotNil(out)
+	assert.Equal("foo", out.DispatchIdempotencyToken)
+
+	// A different token dispatches a new job
+	req.IdempotencyToken = "bar"
+	var resp3 structs.JobDispatchResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp3))
+	assert.NotEqual(resp1.DispatchedJobID, resp3.DispatchedJobID)
+
+	// Once the dispatched job is dead the token dispatches a new job
+	eval, err := s1.fsm.State().EvalByID(nil, resp1.EvalID)
+	assert.Nil(err)
+	eval = eval.Copy()
+	eval.Status = structs.EvalStatusComplete
+	_, _, err = s1.raftApply(structs.EvalUpdateRequestType, &structs.EvalUpdateRequest{
+		Evals: []*structs.Evaluation{eval},
+	})
+	assert.Nil(err)
+
+	req.IdempotencyToken = "foo"
+	var resp4 structs.JobDispatchResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp4))
+	assert.NotEqual(resp1.DispatchedJobID, resp4.DispatchedJobID)
+}
+
+func TestJobEndpoint_Dispatch_MaxRunning(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	// Register a parameterized job that allows one running dispatched job
+	job := mock.Job()
+	job.Type = structs.JobTypeBatch
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{
+		MaxRunning: 1,
+	}
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp))
+
+	req := &structs.JobDispatchRequest{
+		JobID: job.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// The first dispatched job is not queued
+	var resp1 structs.JobDispatchResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp1))
+	assert.False(resp1.Queued)
+
+	// The second dispatched job waits for the first
+	var resp2 structs.JobDispatchResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Dispatch", req, &resp2))
+	assert.True(resp2.Queued)
+
+	state := s1.fsm.State()
+	eval, err := state.EvalByID(nil, resp2.EvalID)
+	assert.Nil(err)
+	assert.Equal(structs.EvalStatusQueued, eval.Status)
+
+	out, err := state.JobByID(nil, job.Namespace, resp2.DispatchedJobID)
+	assert.Nil(err)
+	assert.Equal(structs.JobStatusQueued, out.Status)
+
+	summary, err := state.JobSummaryByID(nil, job.Namespace, job.ID)
+	assert.Nil(err)
+	assert.EqualValues(1, summary.Children.Pending)
+	assert.EqualValues(1, summary.Children.Queued)
+
+	// The queued evaluation must not be handed to the schedulers
+	assert.Equal(1, s1.evalBroker.Stats().TotalReady)
+}
+
+func TestJobEndpoint_Explain(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+	assert := assert.New(t)
+
+	// Create a job whose latest evaluation failed to place allocations
+	j := mock.Job()
+	eval := mock.Eval()
+	eval.JobID = j.ID
+	eval.Status = structs.EvalStatusComplete
+	eval.FailedTGAllocs = map[string]*structs.AllocMetric{
+		"web": {
+			NodesEvaluated:     10,
+			NodesAvailable:     map[string]int{"dc1": 10, "dc2": 0},
+			ConstraintFiltered: map[string]int{"${attr.kernel.name} = linux": 3},
+			DimensionExhausted: map[string]int{"memory exhausted": 7},
+			CoalescedFailures:  4,
+		},
+	}
+	blocked := mock.Eval()
+	blocked.JobID = j.ID
+	blocked.Status = structs.EvalStatusBlocked
+	blocked.ClassEligibility = map[string]bool{"v1:123": true, "v1:456": false}
+	d := mock.Deployment()
+	d.JobID = j.ID
+	d.Status = structs.DeploymentStatusFailed
+	d.StatusDescription = structs.DeploymentStatusDescriptionFailedAllocations
+
+	assert.Nil(state.UpsertJob(1000, j), "UpsertJob")
+	assert.Nil(state.UpsertEvals(1001, []*structs.Evaluation{eval, blocked}), "UpsertEvals")
+	assert.Nil(state.UpsertDeployment(1002, d), "UpsertDeployment")
+
+	// Explain the job
+	get := &structs.JobSpecificRequest{
+		JobID: j.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: j.Namespace,
+		},
+	}
+	var resp structs.JobExplainResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &resp), "RPC")
+	assert.EqualValues(1002, resp.Index, "response index")
+
+	exp := resp.Explanation
+	assert.NotNil(exp)
+	assert.Equal(eval.ID, exp.EvalID)
+	assert.Equal(blocked.ID, exp.BlockedEvalID)
+	assert.Equal(d.ID, exp.DeploymentID)
+	assert.Equal(structs.DeploymentStatusFailed, exp.DeploymentStatus)
+	assert.Equal(map[string]int{"web": 5}, exp.UnplacedAllocations)
+
+	// Check the reasons are ranked
+	var types []string
+	for _, r := range exp.Reasons {
+		types = append(types, r.Type)
+	}
+	assert.Equal([]string{
+		structs.JobExplainReasonNodes,
+		structs.JobExplainReasonExhausted,
+		structs.JobExplainReasonConstraint,
+		structs.JobExplainReasonDeployment,
+		structs.JobExplainReasonBlocked,
+	}, types)
+	assert.Equal(`No nodes are available in datacenter "dc2"`, exp.Reasons[0].Message)
+	assert.Equal(7, exp.Reasons[1].Nodes)
+	assert.Equal("web", exp.Reasons[1].TaskGroup)
+	assert.Contains(exp.Reasons[4].Message, "v1:123")
+	assert.NotContains(exp.Reasons[4].Message, "v1:456")
+
+	// Explaining a missing job returns no explanation
+	get.JobID = "foo"
+	var missing structs.JobExplainResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &missing), "RPC")
+	assert.Nil(missing.Explanation)
+}
+
+func TestJobEndpoint_Explain_ACL(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s1, root := testACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	j := mock.Job()
+	assert.Nil(state.UpsertJob(1000, j), "UpsertJob")
+
+	get := &structs.JobSpecificRequest{
+		JobID: j.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: j.Namespace,
+		},
+	}
+
+	// Attempt to fetch the response without a token should fail
+	var resp structs.JobExplainResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &resp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Attempt to fetch the response with an invalid token should fail
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityListJobs}))
+	get.SecretID = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &resp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Fetching the explanation with a valid token should succeed
+	validToken := mock.CreatePolicyAndToken(t, state, 1003, "test-valid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	get.SecretID = validToken.SecretID
+	var validResp structs.JobExplainResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &validResp), "RPC")
+	assert.NotNil(validResp.Explanation)
+
+	// Fetching the explanation with a management token should succeed
+	get.SecretID = root.SecretID
+	var rootResp structs.JobExplainResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Explain", get, &rootResp), "RPC")
+	assert.Equal(j.ID, rootResp.Explanation.JobID)
+}
+
+func TestJobEndpoint_Capacity(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+	assert := assert.New(t)
+
+	// Create a node that fits seven instances of the mock job
+	node := mock.Node()
+	assert.Nil(state.UpsertNode(1000, node), "UpsertNode")
+
+	job := mock.Job()
+	req := &structs.JobCapacityRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobCapacityResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &resp), "RPC")
+	assert.NotNil(resp.Capacity)
+	assert.Equal("web", resp.Capacity.TaskGroup)
+	assert.Equal(7, resp.Capacity.Placed)
+	assert.Equal(7, resp.Capacity.ByNode[node.ID])
+	assert.Equal("cpu exhausted", resp.Capacity.LimitingDimension)
+	assert.NotZero(resp.Index)
+
+	// The forecast is not submitted
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	assert.Nil(err)
+	assert.Nil(out)
+	allocs, err := state.AllocsByNode(nil, node.ID)
+	assert.Nil(err)
+	assert.Len(allocs, 0)
+
+	// The limit bounds the forecast
+	req.Limit = 2
+	var limited structs.JobCapacityResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &limited), "RPC")
+	assert.Equal(2, limited.Capacity.Placed)
+	assert.True(limited.Capacity.LimitReached)
+
+	// An unknown task group fails
+	req.TaskGroup = "foo"
+	var unknown structs.JobCapacityResponse
+	err = msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &unknown)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "not found")
+}
+
+func TestJobEndpoint_Capacity_ACL(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s1, root := testACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	job := mock.Job()
+	req := &structs.JobCapacityRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// Attempt to forecast without a token should fail
+	var resp structs.JobCapacityResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &resp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Attempt to forecast with a read token should fail
+	readToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	req.SecretID = readToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &resp)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Permission denied")
+
+	// Forecasting with a management token should succeed
+	req.SecretID = root.SecretID
+	var validResp structs.JobCapacityResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Job.Capacity", req, &validResp), "RPC")
+	assert.NotNil(validResp.Capacity)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"sort"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -26,9 +27,17 @@ const (
 	// high contention when the schedulers plan does not make progress.
 	failedEvalUnblockInterval = 1 * time.Minute
 
+	// consulTokenReapInterval is the interval at which Consul tokens derived
+	// for terminal allocations are revoked.
+	consulTokenReapInterval = 5 * time.Minute
+
 	// replicationRateLimit is used to rate limit how often data is replicated
 	// between the authoritative region and the local region
 	replicationRateLimit rate.Limit = 10.0
+
+	// dispatchReleaseRateLimit is used to rate limit how often the queued
+	// dispatched jobs are checked for release
+	dispatchReleaseRateLimit rate.Limit = 10.0
 )
 
 // monitorLeadership is used to monitor if we acquire or lose our role
@@ -175,6 +184,15 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	// Periodically unblock failed allocations
 	go s.periodicUnblockFailedEvals(stopCh)
 
+	// Reap evaluations superseded in the eval broker
+	go s.reapCancelableEvaluations(stopCh)
+
+	// Release queued dispatched jobs of parameterized jobs
+	go s.releaseQueuedDispatches(stopCh)
+
+	// Periodically revoke Consul tokens of terminal allocations
+	go s.reapConsulTokens(stopCh)
+
 	// Setup the heartbeat timers. This is done both when starting up or when
 	// a leader fail over happens. Since the timers are maintained by the leader
 	// node, effectively this means all the timers are renewed at the time of failover.
@@ -295,7 +313,8 @@ func (s *Server) restoreRevokingAccessors() error {
 
 // restorePeriodicDispatcher is used to restore all periodic jobs into the
 // periodic dispatcher. It also determines if a periodic job should have been
-// created during the leadership transition and force runs them. The periodic
+// created during the leadership transition and catches up the missed launches
+// according to the catch up policy of the job. The periodic
 // dispatcher is maintained only by the leader, so it must be restored anytime a
 // leadership transition takes place.
 func (s *Server) restorePeriodicDispatcher() error {
@@ -334,23 +353,23 @@ func (s *Server) restorePeriodicDispatcher() error {
 			return fmt.Errorf("failed to get periodic launch time: %v", err)
 		}
 
-		// nextLaunch is the next launch that should occur.
-		nextLaunch := job.Periodic.Next(launch.Launch.In(job.Periodic.GetLocation()))
-
-		// We skip force launching the job if  there should be no next launch
-		// (the zero case) or if the next launch time is in the future. If it is
-		// in the future, it will be handled by the periodic dispatcher.
-		if nextLaunch.IsZero() || !nextLaunch.Before(now) {
+		// Paused jobs are not caught up
+		if launch.Paused {
+			s.periodicDispatcher.SetPaused(job.Namespace, job.ID, true)
 			continue
 		}
 
-		if _, err := s.periodicDispatcher.ForceRun(job.Namespace, job.ID); err != nil {
-			msg := fmt.Sprintf("force run of periodic job %q failed: %v", job.ID, err)
-			s.logger.Printf("[ERR] nomad.periodic: %s", msg)
-			return errors.New(msg)
+		// Launch the missed launches according to the catch up policy of the
+		// job. Launches in the future are handled by the periodic dispatcher.
+		for _, missed := range job.Periodic.MissedLaunches(launch.Launch, now) {
+			if _, err := s.periodicDispatcher.LaunchAt(job.Namespace, job.ID, missed); err != nil {
+				msg := fmt.Sprintf("force run of periodic job %q failed: %v", job.ID, err)
+				s.logger.Printf("[ERR] nomad.periodic: %s", msg)
+				return errors.New(msg)
+			}
+			s.logger.Printf("[DEBUG] nomad.periodic: periodic job %q launch at %v"+
+				" caught up during leadership establishment", job.ID, missed)
 		}
-		s.logger.Printf("[DEBUG] nomad.periodic: periodic job %q force"+
-			" run during leadership establishment", job.ID)
 	}
 
 	return nil
@@ -498,6 +517,245 @@ func (s *Server) reapDupBlockedEvaluations(stopCh chan struct{}) {
 	}
 }
 
+// reapCancelableEvaluations is used to cancel the evaluations that were
+// superseded by a newer evaluation for the same job in the eval broker.
+func (s *Server) reapCancelableEvaluations(stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			// Scan for superseded evals
+			evals := s.evalBroker.GetCancelable(time.Second)
+			if evals == nil {
+				continue
+			}
+
+			cancel := make([]*structs.Evaluation, len(evals))
+			for i, eval := range evals {
+				// Update the status to cancelled
+				newEval := eval.Copy()
+				newEval.Status = structs.EvalStatusCancelled
+				newEval.StatusDescription = fmt.Sprintf("superseded by a newer evaluation for job %q", newEval.JobID)
+				cancel[i] = newEval
+			}
+
+			// Update via Raft
+			req := structs.EvalUpdateRequest{
+				Evals: cancel,
+			}
+			if _, _, err := s.raftApply(structs.EvalUpdateRequestType, &req); err != nil {
+				s.logger.Printf("[ERR] nomad: failed to cancel superseded evals: %v", err)
+
+				// The evals are still pending in the state store, so
+				// re-enqueue them rather than leaving them untracked by the
+				// broker. They are superseded again if a newer eval for their
+				// job is still outstanding.
+				for _, eval := range evals {
+					s.evalBroker.Enqueue(eval)
+				}
+				continue
+			}
+			metrics.IncrCounter([]string{"nomad", "broker", "evals_cancelled"}, float32(len(cancel)))
+		}
+	}
+}
+
+// releaseQueuedDispatches watches the jobs and releases the queued dispatched
+// jobs of parameterized jobs once their running limit allows it.
+func (s *Server) releaseQueuedDispatches(stopCh chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	limiter := rate.NewLimiter(dispatchReleaseRateLimit, int(dispatchReleaseRateLimit))
+	var index uint64
+	for {
+		// Rate limit how often the dispatched jobs are checked
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+
+		// Wait for a change of the jobs
+		_, idx, err := s.State().BlockingQuery(func(ws memdb.WatchSet, state *state.StateStore) (interface{}, uint64, error) {
+			if _, err := state.Jobs(ws); err != nil {
+				return nil, 0, err
+			}
+			index, err := state.Index("jobs")
+			return nil, index, err
+		}, index, ctx)
+		if err != nil {
+			if err == context.Canceled {
+				return
+			}
+			s.logger.Printf("[ERR] nomad: failed to watch jobs for queued dispatched jobs: %v", err)
+			continue
+		}
+		index = idx
+
+		if err := s.releaseDispatchedJobs(); err != nil {
+			s.logger.Printf("[ERR] nomad: failed to release queued dispatched jobs: %v", err)
+		}
+	}
+}
+
+// releaseDispatchedJobs moves the queued evaluations of dispatched jobs to
+// pending, in dispatch order, while the running limit of their parameterized
+// job allows it. The queued evaluations of stopped dispatched jobs are
+// cancelled.
+func (s *Server) releaseDispatchedJobs() error {
+	s.dispatchLock.Lock()
+	defer s.dispatchLock.Unlock()
+
+	state := s.fsm.State()
+	iter, err := state.Jobs(nil)
+	if err != nil {
+		return err
+	}
+
+	// Group the queued dispatched jobs by parameterized job
+	queued := make(map[structs.NamespacedID][]*structs.Job)
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		job := raw.(*structs.Job)
+		if job.Status != structs.JobStatusQueued || job.ParentID == "" {
+			continue
+		}
+		parentID := structs.NamespacedID{
+			ID:        job.ParentID,
+			Namespace: job.Namespace,
+		}
+		queued[parentID] = append(queued[parentID], job)
+	}
+
+	var updates []*structs.Evaluation
+	for parentID, children := range queued {
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].CreateIndex < children[j].CreateIndex
+		})
+
+		// Determine how many dispatched jobs can be released. The queued
+		// jobs of removed parameterized jobs are all released.
+		available := len(children)
+		parent, err := state.JobByID(nil, parentID.Namespace, parentID.ID)
+		if err != nil {
+			return err
+		}
+		if parent != nil && parent.IsParameterized() && parent.ParameterizedJob.MaxRunning > 0 {
+			summary, err := state.JobSummaryByID(nil, parentID.Namespace, parentID.ID)
+			if err != nil {
+				return err
+			}
+			available = parent.ParameterizedJob.MaxRunning
+			if summary != nil && summary.Children != nil {
+				available -= int(summary.Children.Pending + summary.Children.Running)
+			}
+		}
+
+		for _, child := range children {
+			if !child.Stop && available <= 0 {
+				continue
+			}
+
+			evals, err := state.EvalsByJob(nil, child.Namespace, child.ID)
+			if err != nil {
+				return err
+			}
+			for _, eval := range evals {
+				if eval.Status != structs.EvalStatusQueued {
+					continue
+				}
+
+				newEval := eval.Copy()
+				if child.Stop {
+					newEval.Status = structs.EvalStatusCancelled
+					newEval.StatusDescription = "dispatched job stopped while queued"
+				} else {
+					newEval.Status = structs.EvalStatusPending
+					newEval.StatusDescription = ""
+				}
+				updates = append(updates, newEval)
+			}
+			if !child.Stop {
+				available--
+			}
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	// Update via Raft
+	req := structs.EvalUpdateRequest{
+		Evals: updates,
+	}
+	if _, _, err := s.raftApply(structs.EvalUpdateRequestType, &req); err != nil {
+		return err
+	}
+	return nil
+}
+
+// reapConsulTokens revokes the Consul tokens derived for allocations that are
+// terminal or no longer exist when leadership is established and then
+// periodically, retrying the revocations that failed when the allocations
+// were updated.
+func (s *Server) reapConsulTokens(stopCh chan struct{}) {
+	ticker := time.NewTicker(consulTokenReapInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.revokeTerminalConsulAccessors(); err != nil {
+			s.logger.Printf("[ERR] nomad: failed to reap Consul tokens: %v", err)
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// revokeTerminalConsulAccessors revokes the Consul tokens whose allocations
+// are terminal or no longer exist.
+func (s *Server) revokeTerminalConsulAccessors() error {
+	ws := memdb.NewWatchSet()
+	state := s.fsm.State()
+	iter, err := state.ConsulAccessors(ws)
+	if err != nil {
+		return fmt.Errorf("failed to get Consul accessors: %v", err)
+	}
+
+	var revoke []*structs.ConsulAccessor
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+
+		ca := raw.(*structs.ConsulAccessor)
+		alloc, err := state.AllocByID(ws, ca.AllocID)
+		if err != nil {
+			return fmt.Errorf("failed to lookup allocation %q: %v", ca.AllocID, err)
+		}
+		if alloc == nil || alloc.Terminated() {
+			revoke = append(revoke, ca)
+		}
+	}
+
+	if len(revoke) != 0 {
+		if err := s.consulACLs.RevokeTokens(revoke, true); err != nil {
+			return fmt.Errorf("failed to revoke tokens: %v", err)
+		}
+	}
+	return nil
+}
+
 // periodicUnblockFailedEvals periodically unblocks failed, blocked evaluations.
 func (s *Server) periodicUnblockFailedEvals(stopCh chan struct{}) {
 	ticker := time.NewTicker(failedEvalUnblockInterval)
@@ -510,6 +510,137 @@ func TestLeader_PeriodicDispatcher_Restore_Evals(t *testing.T) {
 	}
 }
 
+func TestLeader_PeriodicDispatcher_Restore_CatchUpAll(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Inject a periodic job that will be triggered twice soon and catches up
+	// all missed launches.
+	now := time.Now().Round(1 * time.Second)
+	launch1 := now.Add(1 * time.Second)
+	launch2 := now.Add(2 * time.Second)
+	job := testPeriodicJob(launch1, launch2)
+	job.Periodic.CatchUp = structs.PeriodicCatchUpAll
+	job.Periodic.CatchUpWindow = time.Hour
+	req := structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	_, _, err := s1.raftApply(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Flush the periodic dispatcher, ensuring that no evals will be created.
+	s1.periodicDispatcher.SetEnabled(false)
+
+	// Sleep till after the job should have been launched.
+	time.Sleep(3 * time.Second)
+
+	// Restore the periodic dispatcher.
+	s1.periodicDispatcher.SetEnabled(true)
+	s1.restorePeriodicDispatcher()
+
+	// Check that both missed launches were made.
+	ws := memdb.NewWatchSet()
+	iter, err := s1.fsm.State().JobsByIDPrefix(ws, job.Namespace, job.ID+structs.PeriodicLaunchSuffix)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var children int
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		children++
+	}
+	if children != 2 {
+		t.Fatalf("restorePeriodicDispatcher caught up %d launches; want 2", children)
+	}
+
+	last, err := s1.fsm.State().PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil || last == nil {
+		t.Fatalf("failed to get periodic launch time: %v", err)
+	}
+	if !last.Launch.Equal(launch2) {
+		t.Fatalf("bad last launch: got %v; want %v", last.Launch, launch2)
+	}
+}
+
+func TestLeader_PeriodicDispatcher_Restore_Paused(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Inject a periodic job that will be triggered soon and pause it.
+	launch := time.Now().Add(1 * time.Second)
+	job := testPeriodicJob(launch)
+	req := structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	_, _, err := s1.raftApply(structs.JobRegisterRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pauseReq := structs.PeriodicPauseRequest{
+		JobID:  job.ID,
+		Paused: true,
+		WriteRequest: structs.WriteRequest{
+			Namespace: job.Namespace,
+		},
+	}
+	_, _, err = s1.raftApply(structs.PeriodicPauseRequestType, pauseReq)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Flush the periodic dispatcher, ensuring that no evals will be created.
+	s1.periodicDispatcher.SetEnabled(false)
+
+	// Get the current time to ensure the launch time is before this once we
+	// restore.
+	now := time.Now()
+
+	// Sleep till after the job should have been launched.
+	time.Sleep(3 * time.Second)
+
+	// Restore the periodic dispatcher.
+	s1.periodicDispatcher.SetEnabled(true)
+	s1.restorePeriodicDispatcher()
+
+	// Ensure the job is tracked and paused.
+	tuple := structs.NamespacedID{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+	}
+	if _, tracked := s1.periodicDispatcher.tracked[tuple]; !tracked {
+		t.Fatalf("periodic job not restored")
+	}
+	if _, paused := s1.periodicDispatcher.paused[tuple]; !paused {
+		t.Fatalf("periodic job not paused")
+	}
+
+	// Check that no launch was caught up.
+	ws := memdb.NewWatchSet()
+	last, err := s1.fsm.State().PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil || last == nil {
+		t.Fatalf("failed to get periodic launch time: %v", err)
+	}
+	if !last.Paused {
+		t.Fatalf("periodic launch not paused")
+	}
+	if last.Launch.After(now) {
+		t.Fatalf("restorePeriodicDispatcher launched paused job: last %v; want before %v", last.Launch, now)
+	}
+}
+
 func TestLeader_PeriodicDispatch(t *testing.T) {
 	s1 := testServer(t, func(c *Config) {
 		c.NumSchedulers = 0
@@ -629,6 +760,66 @@ func TestLeader_ReapDuplicateEval(t *testing.T) {
 	})
 }
 
+func TestLeader_ReapCancelableEval(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Dequeue an eval and block two more for the same job behind it
+	eval := mock.Eval()
+	eval2 := mock.Eval()
+	eval2.JobID = eval.JobID
+	eval3 := mock.Eval()
+	eval3.JobID = eval.JobID
+
+	state := s1.fsm.State()
+	if err := state.UpsertEvals(1000, []*structs.Evaluation{eval, eval2}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertEvals(1001, []*structs.Evaluation{eval3}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s1.evalBroker.Enqueue(eval)
+	out, token, err := s1.evalBroker.Dequeue([]string{eval.Type}, time.Second)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != eval {
+		t.Fatalf("bad: %#v", out)
+	}
+	s1.evalBroker.Enqueue(eval2)
+	s1.evalBroker.Enqueue(eval3)
+
+	// Acking the eval supersedes the older blocked eval
+	if err := s1.evalBroker.Ack(eval.ID, token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Wait for the superseded evaluation to be marked as cancelled
+	testutil.WaitForResult(func() (bool, error) {
+		ws := memdb.NewWatchSet()
+		out, err := state.EvalByID(ws, eval2.ID)
+		if err != nil {
+			return false, err
+		}
+		return out != nil && out.Status == structs.EvalStatusCancelled, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The newest evaluation is still pending
+	ws := memdb.NewWatchSet()
+	out, err = state.EvalByID(ws, eval3.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Status != structs.EvalStatusPending {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
 func TestLeader_RestoreVaultAccessors(t *testing.T) {
 	s1 := testServer(t, func(c *Config) {
 		c.NumSchedulers = 0
@@ -807,3 +998,84 @@ func TestLeader_DiffACLTokens(t *testing.T) {
 	// P2 is un-modified - ignore. P3 modified, P4 new.
 	assert.Equal(t, []string{p3.AccessorID, p4.AccessorID}, update)
 }
+
+func TestLeader_ReleaseQueuedDispatches(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a parameterized job that allows one running dispatched job
+	job := mock.Job()
+	job.Type = structs.JobTypeBatch
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{
+		MaxRunning: 1,
+	}
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	if err := s1.RPC("Job.Register", regReq, &regResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Dispatch two jobs so the second is queued
+	req := &structs.JobDispatchRequest{
+		JobID: job.ID,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp1, resp2 structs.JobDispatchResponse
+	if err := s1.RPC("Job.Dispatch", req, &resp1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := s1.RPC("Job.Dispatch", req, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !resp2.Queued {
+		t.Fatalf("expected the second dispatched job to be queued")
+	}
+
+	// Complete the evaluation of the first dispatched job
+	state := s1.fsm.State()
+	eval, err := state.EvalByID(nil, resp1.EvalID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	eval = eval.Copy()
+	eval.Status = structs.EvalStatusComplete
+	if _, _, err := s1.raftApply(structs.EvalUpdateRequestType, &structs.EvalUpdateRequest{
+		Evals: []*structs.Evaluation{eval},
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The queued dispatched job should be released
+	testutil.WaitForResult(func() (bool, error) {
+		out, err := state.EvalByID(nil, resp2.EvalID)
+		if err != nil {
+			return false, err
+		}
+		if out.Status != structs.EvalStatusPending {
+			return false, fmt.Errorf("got eval status %q; want %q", out.Status, structs.EvalStatusPending)
+		}
+		job, err := state.JobByID(nil, job.Namespace, resp2.DispatchedJobID)
+		if err != nil {
+			return false, err
+		}
+		if job.Status != structs.JobStatusPending {
+			return false, fmt.Errorf("got job status %q; want %q", job.Status, structs.JobStatusPending)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
@@ -55,6 +55,7 @@ func Node() *structs.Node {
 			"version":  "5.6",
 		},
 		NodeClass: "linux-medium-pci",
+		NodePool:  structs.NodePoolDefault,
 		Status:    structs.NodeStatusReady,
 	}
 	node.ComputeClass()
@@ -220,6 +221,20 @@ func SystemJob() *structs.Job {
 	return job
 }
 
+func SysBatchJob() *structs.Job {
+	job := SystemJob()
+	job.Type = structs.JobTypeSysBatch
+	job.Priority = 50
+	job.TaskGroups[0].RestartPolicy = &structs.RestartPolicy{
+		Attempts: 3,
+		Interval: 10 * time.Minute,
+		Delay:    1 * time.Minute,
+		Mode:     structs.RestartPolicyModeFail,
+	}
+	job.Canonicalize()
+	return job
+}
+
 func PeriodicJob() *structs.Job {
 	job := Job()
 	job.Type = structs.JobTypeBatch
@@ -315,6 +330,15 @@ func VaultAccessor() *structs.VaultAccessor {
 	}
 }
 
+func ConsulAccessor() *structs.ConsulAccessor {
+	return &structs.ConsulAccessor{
+		Accessor: uuid.Generate(),
+		NodeID:   uuid.Generate(),
+		AllocID:  uuid.Generate(),
+		Task:     "foo",
+	}
+}
+
 func Deployment() *structs.Deployment {
 	return &structs.Deployment{
 		ID:             uuid.Generate(),
@@ -367,6 +391,18 @@ func ACLPolicy() *structs.ACLPolicy {
 	return ap
 }
 
+func NodePool() *structs.NodePool {
+	return &structs.NodePool{
+		Name:        fmt.Sprintf("pool-%s", uuid.Generate()[:8]),
+		Description: "Super cool pool!",
+		Meta: map[string]string{
+			"team": "platform",
+		},
+		CreateIndex: 10,
+		ModifyIndex: 20,
+	}
+}
+
 func ACLToken() *structs.ACLToken {
 	tk := &structs.ACLToken{
 		AccessorID:  uuid.Generate(),
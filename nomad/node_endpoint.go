@@ -91,6 +91,17 @@ func (n *Node) Register(args *structs.NodeRegisterRequest, reply *structs.NodeUp
 		return fmt.Errorf("invalid status for node")
 	}
 
+	// Default the node pool if none is given
+	if args.Node.NodePool == "" {
+		args.Node.NodePool = structs.NodePoolDefault
+	}
+	if err := structs.ValidateNodePoolName(args.Node.NodePool); err != nil {
+		return err
+	}
+	if args.Node.NodePool == structs.NodePoolAll {
+		return fmt.Errorf("node can't be registered in the %q node pool", structs.NodePoolAll)
+	}
+
 	// Set the timestamp when the node is registered
 	args.Node.StatusUpdatedAt = time.Now().Unix()
 
@@ -310,6 +321,7 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 
 	// Update the timestamp of when the node status was updated
 	node.StatusUpdatedAt = time.Now().Unix()
+	args.UpdatedAt = node.StatusUpdatedAt
 
 	// Commit this update via Raft
 	var index uint64
@@ -768,17 +780,32 @@ func (n *Node) batchUpdate(future *batchFuture, updates []*structs.Allocation) {
 		WriteRequest: structs.WriteRequest{Region: n.srv.config.Region},
 	}
 
+	// Determine the evaluations to create for jobs waiting on the groups of
+	// the updated allocations before the update is applied
+	depEvals := n.groupDependencyEvals(updates)
+
 	// Commit this update via Raft
 	var mErr multierror.Error
 	_, index, err := n.srv.raftApply(structs.AllocClientUpdateRequestType, batch)
 	if err != nil {
 		n.srv.logger.Printf("[ERR] nomad.client: alloc update failed: %v", err)
 		mErr.Errors = append(mErr.Errors, err)
+	} else if len(depEvals) != 0 {
+		// Retry the placements held back by task group dependencies
+		update := &structs.EvalUpdateRequest{
+			Evals:        depEvals,
+			WriteRequest: structs.WriteRequest{Region: n.srv.config.Region},
+		}
+		if _, _, err := n.srv.raftApply(structs.EvalUpdateRequestType, update); err != nil {
+			n.srv.logger.Printf("[ERR] nomad.client: failed to create group dependency evals: %v", err)
+			mErr.Errors = append(mErr.Errors, err)
+		}
 	}
 
 	// For each allocation we are updating check if we should revoke any
 	// Vault Accessors
 	var revoke []*structs.VaultAccessor
+	var revokeConsul []*structs.ConsulAccessor
 	for _, alloc := range updates {
 		// Skip any allocation that isn't dead on the client
 		if !alloc.Terminated() {
@@ -794,6 +821,15 @@ func (n *Node) batchUpdate(future *batchFuture, updates []*structs.Allocation) {
 		}
 
 		revoke = append(revoke, accessors...)
+
+		// Determine if there are any Consul accessors for the allocation
+		consulAccessors, err := n.srv.State().ConsulAccessorsByAlloc(ws, alloc.ID)
+		if err != nil {
+			n.srv.logger.Printf("[ERR] nomad.client: looking up Consul accessors for alloc %q failed: %v", alloc.ID, err)
+			mErr.Errors = append(mErr.Errors, err)
+		}
+
+		revokeConsul = append(revokeConsul, consulAccessors...)
 	}
 
 	if l := len(revoke); l != 0 {
@@ -804,10 +840,70 @@ func (n *Node) batchUpdate(future *batchFuture, updates []*structs.Allocation) {
 		}
 	}
 
+	// Revoke the Consul tokens of terminal allocations. Failures are retried
+	// by the leader's periodic reaping so they do not fail the update.
+	if l := len(revokeConsul); l != 0 {
+		n.srv.logger.Printf("[DEBUG] nomad.client: revoking %d Consul accessors due to terminal allocations", l)
+		if err := n.srv.consulACLs.RevokeTokens(revokeConsul, true); err != nil {
+			n.srv.logger.Printf("[WARN] nomad.client: batched Consul accessor revocation failed: %v", err)
+		}
+	}
+
 	// Respond to the future
 	future.Respond(index, mErr.ErrorOrNil())
 }
 
+// groupDependencyEvals returns an evaluation for each job with a task group
+// depending on the group of an allocation that is transitioning to running or
+// complete. This retries the placements the scheduler held back while the
+// dependency was unmet.
+func (n *Node) groupDependencyEvals(updates []*structs.Allocation) []*structs.Evaluation {
+	snap, err := n.srv.State().Snapshot()
+	if err != nil {
+		n.srv.logger.Printf("[ERR] nomad.client: failed to snapshot state: %v", err)
+		return nil
+	}
+
+	var evals []*structs.Evaluation
+	jobs := make(map[structs.NamespacedID]struct{})
+	for _, update := range updates {
+		switch update.ClientStatus {
+		case structs.AllocClientStatusRunning, structs.AllocClientStatusComplete:
+		default:
+			continue
+		}
+
+		existing, err := snap.AllocByID(nil, update.ID)
+		if err != nil || existing == nil || existing.ClientStatus == update.ClientStatus {
+			continue
+		}
+
+		id := structs.NamespacedID{ID: existing.JobID, Namespace: existing.Namespace}
+		if _, ok := jobs[id]; ok {
+			continue
+		}
+
+		job, err := snap.JobByID(nil, existing.Namespace, existing.JobID)
+		if err != nil || job == nil || job.Stopped() || !job.HasGroupDependency(existing.TaskGroup) {
+			continue
+		}
+
+		jobs[id] = struct{}{}
+		evals = append(evals, &structs.Evaluation{
+			ID:             uuid.Generate(),
+			Namespace:      job.Namespace,
+			Priority:       job.Priority,
+			Type:           job.Type,
+			TriggeredBy:    structs.EvalTriggerGroupDependency,
+			JobID:          job.ID,
+			JobModifyIndex: job.ModifyIndex,
+			Status:         structs.EvalStatusPending,
+		})
+	}
+
+	return evals
+}
+
 // List is used to list the available nodes
 func (n *Node) List(args *structs.NodeListRequest,
 	reply *structs.NodeListResponse) error {
@@ -891,6 +987,20 @@ func (n *Node) createNodeEvals(nodeID string, nodeIndex uint64) ([]string, uint6
 		sysJobs = append(sysJobs, job.(*structs.Job))
 	}
 
+	sysBatchJobsIter, err := snap.JobsByScheduler(ws, structs.JobTypeSysBatch)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find sysbatch jobs for '%s': %v", nodeID, err)
+	}
+
+	for raw := sysBatchJobsIter.Next(); raw != nil; raw = sysBatchJobsIter.Next() {
+		// Periodic and parameterized jobs only run through their children
+		job := raw.(*structs.Job)
+		if job.IsPeriodic() || job.IsParameterized() {
+			continue
+		}
+		sysJobs = append(sysJobs, job)
+	}
+
 	// Fast-path if nothing to do
 	if len(allocs) == 0 && len(sysJobs) == 0 {
 		return nil, 0, nil
@@ -924,7 +1034,7 @@ func (n *Node) createNodeEvals(nodeID string, nodeIndex uint64) ([]string, uint6
 		evalIDs = append(evalIDs, eval.ID)
 	}
 
-	// Create an evaluation for each system job.
+	// Create an evaluation for each system and sysbatch job.
 	for _, job := range sysJobs {
 		// Still dedup on JobID as the node may already have the system job.
 		if _, ok := jobIDs[job.ID]; ok {
@@ -1221,3 +1331,132 @@ func (n *Node) DeriveVaultToken(args *structs.DeriveVaultTokenRequest,
 	n.srv.setQueryMeta(&reply.QueryMeta)
 	return nil
 }
+
+// DeriveConsulToken is used by the clients to request Consul tokens for tasks
+// to register their services and render their templates with.
+func (n *Node) DeriveConsulToken(args *structs.DeriveConsulTokenRequest,
+	reply *structs.DeriveConsulTokenResponse) error {
+
+	// setErr is a helper for setting the recoverable error on the reply and
+	// logging it
+	setErr := func(e error, recoverable bool) {
+		if e == nil {
+			return
+		}
+		reply.Error = structs.NewRecoverableError(e, recoverable).(*structs.RecoverableError)
+		n.srv.logger.Printf("[ERR] nomad.client: DeriveConsulToken failed (recoverable %v): %v", recoverable, e)
+	}
+
+	if done, err := n.srv.forward("Node.DeriveConsulToken", args, args, reply); done {
+		setErr(err, structs.IsRecoverable(err) || err == structs.ErrNoLeader)
+		return nil
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "derive_consul_token"}, time.Now())
+
+	// Verify the arguments
+	if args.NodeID == "" {
+		setErr(fmt.Errorf("missing node ID"), false)
+		return nil
+	}
+	if args.SecretID == "" {
+		setErr(fmt.Errorf("missing node SecretID"), false)
+		return nil
+	}
+	if args.AllocID == "" {
+		setErr(fmt.Errorf("missing allocation ID"), false)
+		return nil
+	}
+	if len(args.Tasks) == 0 {
+		setErr(fmt.Errorf("no tasks specified"), false)
+		return nil
+	}
+
+	// Verify the following:
+	// * The Node exists and has the correct SecretID
+	// * The Allocation exists on the specified node and isn't terminal
+	snap, err := n.srv.fsm.State().Snapshot()
+	if err != nil {
+		setErr(err, false)
+		return nil
+	}
+	ws := memdb.NewWatchSet()
+	node, err := snap.NodeByID(ws, args.NodeID)
+	if err != nil {
+		setErr(err, false)
+		return nil
+	}
+	if node == nil {
+		setErr(fmt.Errorf("Node %q does not exist", args.NodeID), false)
+		return nil
+	}
+	if node.SecretID != args.SecretID {
+		setErr(fmt.Errorf("SecretID mismatch"), false)
+		return nil
+	}
+
+	alloc, err := snap.AllocByID(ws, args.AllocID)
+	if err != nil {
+		setErr(err, false)
+		return nil
+	}
+	if alloc == nil {
+		setErr(fmt.Errorf("Allocation %q does not exist", args.AllocID), false)
+		return nil
+	}
+	if alloc.NodeID != args.NodeID {
+		setErr(fmt.Errorf("Allocation %q not running on Node %q", args.AllocID, args.NodeID), false)
+		return nil
+	}
+	if alloc.TerminalStatus() {
+		setErr(fmt.Errorf("Can't request Consul token for terminal allocation"), false)
+		return nil
+	}
+
+	// Tokens are only derived when service identities are enforced;
+	// otherwise clients use the token of their Consul agent.
+	reply.Tasks = make(map[string]string)
+	if !n.srv.config.ConsulConfig.AllowsUnauthenticated() {
+		tokens, err := n.srv.consulACLs.DeriveTokens(alloc, args.Tasks)
+		if err != nil {
+			setErr(err, structs.IsRecoverable(err))
+			return nil
+		}
+
+		accessors := make([]*structs.ConsulAccessor, 0, len(tokens))
+		for task, token := range tokens {
+			accessors = append(accessors, &structs.ConsulAccessor{
+				Accessor: token,
+				Task:     task,
+				NodeID:   alloc.NodeID,
+				AllocID:  alloc.ID,
+			})
+		}
+
+		// Commit to Raft before returning any of the tokens so they are
+		// revoked once the allocation is terminal
+		req := structs.ConsulAccessorsRequest{Accessors: accessors}
+		_, index, err := n.srv.raftApply(structs.ConsulAccessorRegisterRequestType, &req)
+		if err != nil {
+			n.srv.logger.Printf("[ERR] nomad.client: Register Consul accessors for alloc %q failed: %v", alloc.ID, err)
+			if revokeErr := n.srv.consulACLs.RevokeTokens(accessors, false); revokeErr != nil {
+				n.srv.logger.Printf("[ERR] nomad.node: Consul token revocation for alloc %q failed: %v", alloc.ID, revokeErr)
+			}
+
+			// Determine if we can recover from the error
+			retry := false
+			switch err {
+			case raft.ErrNotLeader, raft.ErrLeadershipLost, raft.ErrRaftShutdown, raft.ErrEnqueueTimeout:
+				retry = true
+			}
+
+			setErr(err, retry)
+			return nil
+		}
+
+		reply.Index = index
+		reply.Tasks = tokens
+	}
+
+	n.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
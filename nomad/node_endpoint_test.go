@@ -59,6 +59,43 @@ func TestClientEndpoint_Register(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_Register_NodePool(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Nodes can't join the built-in all pool
+	node := mock.Node()
+	node.NodePool = structs.NodePoolAll
+	req := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "node pool") {
+		t.Fatalf("expected node pool error: %v", err)
+	}
+
+	// Registering in a new pool creates it
+	node.NodePool = "gpu"
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	state := s1.fsm.State()
+	ws := memdb.NewWatchSet()
+	pool, err := state.NodePoolByName(ws, "gpu")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if pool == nil {
+		t.Fatalf("expected node pool")
+	}
+}
+
 func TestClientEndpoint_Register_NoSecret(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, nil)
@@ -1539,6 +1576,74 @@ func TestClientEndpoint_UpdateAlloc(t *testing.T) {
 	}
 }
 
+func TestClientEndpoint_UpdateAlloc_GroupDependency(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create a job whose second group depends on the first
+	job := mock.Job()
+	tg2 := job.TaskGroups[0].Copy()
+	tg2.Name = "foo"
+	tg2.DependsOn = []*structs.TaskGroupDependency{
+		{
+			Group:     job.TaskGroups[0].Name,
+			Condition: structs.TaskGroupDependencyRunning,
+		},
+	}
+	job.TaskGroups = append(job.TaskGroups, tg2)
+
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	state := s1.fsm.State()
+	if err := state.UpsertJob(99, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertAllocs(100, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// updateStatus reports the allocation with the given client status and
+	// returns the evals of the job
+	updateStatus := func(status string) []*structs.Evaluation {
+		clientAlloc := alloc.Copy()
+		clientAlloc.ClientStatus = status
+		update := &structs.AllocUpdateRequest{
+			Alloc:        []*structs.Allocation{clientAlloc},
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeAllocsResponse
+		if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateAlloc", update, &resp); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		evals, err := state.EvalsByJob(nil, job.Namespace, job.ID)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return evals
+	}
+
+	// Starting the allocation triggers an eval for the dependent group
+	evals := updateStatus(structs.AllocClientStatusRunning)
+	if len(evals) != 1 {
+		t.Fatalf("expected one eval: %#v", evals)
+	}
+	if e := evals[0]; e.TriggeredBy != structs.EvalTriggerGroupDependency || e.Status != structs.EvalStatusPending {
+		t.Fatalf("bad eval: %#v", e)
+	}
+
+	// Reporting the same status again doesn't trigger another eval
+	if evals := updateStatus(structs.AllocClientStatusRunning); len(evals) != 1 {
+		t.Fatalf("expected no new eval: %#v", evals)
+	}
+}
+
 func TestClientEndpoint_BatchUpdate(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, nil)
@@ -1693,6 +1798,21 @@ func TestClientEndpoint_CreateNodeEvals(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
+	// Inject a fake sysbatch job and a periodic one that is skipped.
+	sysBatchJob := mock.SysBatchJob()
+	if err := state.UpsertJob(4, sysBatchJob); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	periodicJob := mock.SysBatchJob()
+	periodicJob.Periodic = &structs.PeriodicConfig{
+		Enabled:  true,
+		SpecType: structs.PeriodicSpecCron,
+		Spec:     "*/30 * * * *",
+	}
+	if err := state.UpsertJob(5, periodicJob); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
 	// Create some evaluations
 	ids, index, err := s1.endpoints.Node.createNodeEvals(alloc.NodeID, 1)
 	if err != nil {
@@ -1701,13 +1821,13 @@ func TestClientEndpoint_CreateNodeEvals(t *testing.T) {
 	if index == 0 {
 		t.Fatalf("bad: %d", index)
 	}
-	if len(ids) != 2 {
+	if len(ids) != 3 {
 		t.Fatalf("bad: %s", ids)
 	}
 
 	// Lookup the evaluations
 	ws := memdb.NewWatchSet()
-	evalByType := make(map[string]*structs.Evaluation, 2)
+	evalByType := make(map[string]*structs.Evaluation, 3)
 	for _, id := range ids {
 		eval, err := state.EvalByID(ws, id)
 		if err != nil {
@@ -1724,17 +1844,21 @@ func TestClientEndpoint_CreateNodeEvals(t *testing.T) {
 		evalByType[eval.Type] = eval
 	}
 
-	if len(evalByType) != 2 {
-		t.Fatalf("Expected a service and system job; got %#v", evalByType)
+	if len(evalByType) != 3 {
+		t.Fatalf("Expected a service, system and sysbatch job; got %#v", evalByType)
 	}
 
 	// Ensure the evals are correct.
 	for schedType, eval := range evalByType {
 		expPriority := alloc.Job.Priority
 		expJobID := alloc.JobID
-		if schedType == "system" {
+		switch schedType {
+		case structs.JobTypeSystem:
 			expPriority = job.Priority
 			expJobID = job.ID
+		case structs.JobTypeSysBatch:
+			expPriority = sysBatchJob.Priority
+			expJobID = sysBatchJob.ID
 		}
 
 		if eval.CreateIndex != index {
@@ -2081,7 +2205,7 @@ func TestClientEndpoint_ListNodes_Blocking(t *testing.T) {
 
 	// Node status update triggers watches
 	time.AfterFunc(100*time.Millisecond, func() {
-		if err := state.UpdateNodeStatus(4, node.ID, structs.NodeStatusDown); err != nil {
+		if err := state.UpdateNodeStatus(4, node.ID, structs.NodeStatusDown, time.Now().Unix()); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	})
@@ -2385,3 +2509,152 @@ func TestClientEndpoint_DeriveVaultToken_VaultError(t *testing.T) {
 		t.Fatalf("bad: %+v", resp.Error)
 	}
 }
+
+func TestClientEndpoint_DeriveConsulToken(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	state := s1.fsm.State()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Replace the Consul ACL API on the server
+	acls := newMockConsulACLAPI()
+	s1.consulACLs = NewConsulACLsAPI(acls, s1.logger, s1.purgeConsulAccessors)
+
+	// Create the node
+	node := mock.Node()
+	if err := state.UpsertNode(2, node); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	if err := state.UpsertAllocs(3, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req := &structs.DeriveConsulTokenRequest{
+		NodeID:   node.ID,
+		SecretID: node.SecretID,
+		AllocID:  alloc.ID,
+		Tasks:    []string{task.Name},
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+
+	// No tokens are derived when unauthenticated jobs are allowed
+	var resp structs.DeriveConsulTokenResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.DeriveConsulToken", req, &resp); err != nil {
+		t.Fatalf("bad: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("bad: %v", resp.Error)
+	}
+	if len(resp.Tasks) != 0 || len(acls.entries) != 0 {
+		t.Fatalf("expected no tokens: %v", resp.Tasks)
+	}
+
+	// Enforce service identities
+	f := false
+	s1.config.ConsulConfig.AllowUnauthenticated = &f
+
+	var resp2 structs.DeriveConsulTokenResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.DeriveConsulToken", req, &resp2); err != nil {
+		t.Fatalf("bad: %v", err)
+	}
+	if resp2.Error != nil {
+		t.Fatalf("bad: %v", resp2.Error)
+	}
+	token, ok := resp2.Tasks[task.Name]
+	if !ok {
+		t.Fatalf("missing token for task: %v", resp2.Tasks)
+	}
+	if entry := acls.entries[token]; entry == nil || entry.Name != consulTokenName(alloc.ID, task.Name) {
+		t.Fatalf("bad token: %#v", entry)
+	}
+
+	// Check the accessor was committed
+	ws := memdb.NewWatchSet()
+	accessors, err := state.ConsulAccessorsByAlloc(ws, alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(accessors) != 1 || accessors[0].Accessor != token || accessors[0].Task != task.Name || accessors[0].NodeID != node.ID {
+		t.Fatalf("bad accessors: %#v", accessors)
+	}
+}
+
+func TestClientEndpoint_UpdateAlloc_ConsulAccessors(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Replace the Consul ACL API on the server
+	acls := newMockConsulACLAPI()
+	s1.consulACLs = NewConsulACLsAPI(acls, s1.logger, s1.purgeConsulAccessors)
+
+	// Create the node
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Inject a fake allocation with a derived token
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	state := s1.fsm.State()
+	state.UpsertJobSummary(99, mock.JobSummary(alloc.JobID))
+	if err := state.UpsertAllocs(100, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tokens, err := s1.consulACLs.DeriveTokens(alloc, []string{task.Name})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ca := &structs.ConsulAccessor{
+		AllocID:  alloc.ID,
+		Task:     task.Name,
+		NodeID:   node.ID,
+		Accessor: tokens[task.Name],
+	}
+	if err := state.UpsertConsulAccessors(101, []*structs.ConsulAccessor{ca}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Mark the alloc as failed
+	clientAlloc := alloc.Copy()
+	clientAlloc.ClientStatus = structs.AllocClientStatusFailed
+	update := &structs.AllocUpdateRequest{
+		Alloc:        []*structs.Allocation{clientAlloc},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp2 structs.NodeAllocsResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Node.UpdateAlloc", update, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The token is revoked and its accessor purged
+	if len(acls.entries) != 0 {
+		t.Fatalf("expected the token to be revoked: %v", acls.entries)
+	}
+	ws := memdb.NewWatchSet()
+	accessors, err := state.ConsulAccessorsByAlloc(ws, alloc.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(accessors) != 0 {
+		t.Fatalf("expected the accessor to be purged: %#v", accessors)
+	}
+}
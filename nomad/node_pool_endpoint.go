@@ -0,0 +1,240 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// NodePool endpoint is used for manipulating node pools
+type NodePool struct {
+	srv *Server
+}
+
+// UpsertNodePools is used to create or update a set of node pools
+func (n *NodePool) UpsertNodePools(args *structs.NodePoolUpsertRequest, reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("NodePool.UpsertNodePools", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "node_pool", "upsert_node_pools"}, time.Now())
+
+	// Check node write permissions
+	if aclObj, err := n.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of node pools
+	if len(args.NodePools) == 0 {
+		return fmt.Errorf("must specify as least one node pool")
+	}
+
+	// Validate each node pool
+	for idx, pool := range args.NodePools {
+		if err := pool.Validate(); err != nil {
+			return fmt.Errorf("node pool %d invalid: %v", idx, err)
+		}
+	}
+
+	// Update via Raft
+	_, index, err := n.srv.raftApply(structs.NodePoolUpsertRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// DeleteNodePools is used to delete node pools
+func (n *NodePool) DeleteNodePools(args *structs.NodePoolDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("NodePool.DeleteNodePools", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "node_pool", "delete_node_pools"}, time.Now())
+
+	// Check node write permissions
+	if aclObj, err := n.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate non-zero set of node pools
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify as least one node pool")
+	}
+
+	// Built-in node pools can't be deleted
+	for _, name := range args.Names {
+		if (&structs.NodePool{Name: name}).IsBuiltIn() {
+			return fmt.Errorf("node pool %q is built-in and can't be deleted", name)
+		}
+	}
+
+	// Update via Raft
+	out, index, err := n.srv.raftApply(structs.NodePoolDeleteRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// The pools may be unknown or still in use
+	if err, ok := out.(error); ok && err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
+// List is used to list the node pools
+func (n *NodePool) List(args *structs.NodePoolListRequest, reply *structs.NodePoolListResponse) error {
+	if done, err := n.srv.forward("NodePool.List", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "node_pool", "list"}, time.Now())
+
+	// Check node read permissions
+	if aclObj, err := n.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Iterate over all the node pools
+			var err error
+			var iter memdb.ResultIterator
+			if prefix := args.QueryOptions.Prefix; prefix != "" {
+				iter, err = state.NodePoolsByNamePrefix(ws, prefix)
+			} else {
+				iter, err = state.NodePools(ws)
+			}
+			if err != nil {
+				return err
+			}
+
+			reply.NodePools = nil
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				reply.NodePools = append(reply.NodePools, raw.(*structs.NodePool))
+			}
+
+			// Use the last index that affected the node pools table
+			index, err := state.Index("node_pools")
+			if err != nil {
+				return err
+			}
+			reply.Index = helper.Uint64Max(index, 1)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
+
+// GetNodePool is used to get a specific node pool
+func (n *NodePool) GetNodePool(args *structs.NodePoolSpecificRequest, reply *structs.SingleNodePoolResponse) error {
+	if done, err := n.srv.forward("NodePool.GetNodePool", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "node_pool", "get_node_pool"}, time.Now())
+
+	// Check node read permissions
+	if aclObj, err := n.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// Look for the node pool
+			out, err := state.NodePoolByName(ws, args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.NodePool = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the node pools table
+				index, err := state.Index("node_pools")
+				if err != nil {
+					return err
+				}
+				reply.Index = helper.Uint64Max(index, 1)
+			}
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
+
+// ListNodes is used to list the nodes in a node pool
+func (n *NodePool) ListNodes(args *structs.NodePoolNodesRequest, reply *structs.NodePoolNodesResponse) error {
+	if done, err := n.srv.forward("NodePool.ListNodes", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "node_pool", "list_nodes"}, time.Now())
+
+	// Check node read permissions
+	if aclObj, err := n.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			// The all pool includes every node
+			var err error
+			var iter memdb.ResultIterator
+			if args.Name == structs.NodePoolAll {
+				iter, err = state.Nodes(ws)
+			} else {
+				iter, err = state.NodesByNodePool(ws, args.Name)
+			}
+			if err != nil {
+				return err
+			}
+
+			reply.Nodes = nil
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				reply.Nodes = append(reply.Nodes, raw.(*structs.Node).Stub())
+			}
+
+			// Use the last index that affected the nodes table
+			index, err := state.Index("nodes")
+			if err != nil {
+				return err
+			}
+			reply.Index = helper.Uint64Max(index, 1)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
@@ -0,0 +1,245 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodePoolEndpoint_UpsertNodePools(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	// Create the register request
+	p1 := mock.NodePool()
+	p2 := mock.NodePool()
+	req := &structs.NodePoolUpsertRequest{
+		NodePools:    []*structs.NodePool{p1, p2},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.UpsertNodePools", req, &resp))
+	assert.NotEqual(uint64(0), resp.Index)
+
+	// Check we created the node pools
+	out, err := s1.fsm.State().NodePoolByName(nil, p1.Name)
+	assert.Nil(err)
+	assert.NotNil(out)
+	out, err = s1.fsm.State().NodePoolByName(nil, p2.Name)
+	assert.Nil(err)
+	assert.NotNil(out)
+}
+
+func TestNodePoolEndpoint_UpsertNodePools_Invalid(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	p1 := mock.NodePool()
+	p1.Name = "bad name"
+	req := &structs.NodePoolUpsertRequest{
+		NodePools:    []*structs.NodePool{p1},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "NodePool.UpsertNodePools", req, &resp)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "invalid node pool name")
+	}
+}
+
+func TestNodePoolEndpoint_UpsertNodePools_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := testACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+	state := s1.fsm.State()
+
+	// Create a token that can only read nodes
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyRead))
+
+	p1 := mock.NodePool()
+	req := &structs.NodePoolUpsertRequest{
+		NodePools:    []*structs.NodePool{p1},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+
+	// Try with no token and expect permission denied
+	{
+		var resp structs.GenericResponse
+		err := msgpackrpc.CallWithCodec(codec, "NodePool.UpsertNodePools", req, &resp)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with an invalid token and expect permission denied
+	{
+		req.SecretID = invalidToken.SecretID
+		var resp structs.GenericResponse
+		err := msgpackrpc.CallWithCodec(codec, "NodePool.UpsertNodePools", req, &resp)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Use management token
+	{
+		req.SecretID = root.SecretID
+		var resp structs.GenericResponse
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.UpsertNodePools", req, &resp))
+
+		out, err := state.NodePoolByName(nil, p1.Name)
+		assert.Nil(err)
+		assert.NotNil(out)
+	}
+}
+
+func TestNodePoolEndpoint_DeleteNodePools(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	p1 := mock.NodePool()
+	assert.Nil(s1.fsm.State().UpsertNodePools(1000, []*structs.NodePool{p1}))
+
+	// Built-in pools can't be deleted
+	req := &structs.NodePoolDeleteRequest{
+		Names:        []string{structs.NodePoolDefault},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "NodePool.DeleteNodePools", req, &resp)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "built-in")
+	}
+
+	// Pools with nodes can't be deleted
+	node := mock.Node()
+	node.NodePool = p1.Name
+	assert.Nil(s1.fsm.State().UpsertNode(1001, node))
+	req.Names = []string{p1.Name}
+	err = msgpackrpc.CallWithCodec(codec, "NodePool.DeleteNodePools", req, &resp)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "has nodes")
+	}
+	assert.Nil(s1.fsm.State().DeleteNode(1002, node.ID))
+
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.DeleteNodePools", req, &resp))
+	assert.NotEqual(uint64(0), resp.Index)
+
+	out, err := s1.fsm.State().NodePoolByName(nil, p1.Name)
+	assert.Nil(err)
+	assert.Nil(out)
+}
+
+func TestNodePoolEndpoint_List(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	p1 := mock.NodePool()
+	p1.Name = "aaaaaaaa-pool"
+	p2 := mock.NodePool()
+	p2.Name = "aaaabbbb-pool"
+	assert.Nil(s1.fsm.State().UpsertNodePools(1000, []*structs.NodePool{p1, p2}))
+
+	// Lookup the pools
+	get := &structs.NodePoolListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.NodePoolListResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.List", get, &resp))
+	assert.EqualValues(1000, resp.Index)
+	assert.Len(resp.NodePools, 4)
+
+	// Lookup the pools by prefix
+	get.Prefix = "aaaab"
+	var resp2 structs.NodePoolListResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.List", get, &resp2))
+	if assert.Len(resp2.NodePools, 1) {
+		assert.Equal(p2.Name, resp2.NodePools[0].Name)
+	}
+}
+
+func TestNodePoolEndpoint_GetNodePool(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	p1 := mock.NodePool()
+	assert.Nil(s1.fsm.State().UpsertNodePools(1000, []*structs.NodePool{p1}))
+
+	// Lookup the pool
+	get := &structs.NodePoolSpecificRequest{
+		Name:         p1.Name,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.SingleNodePoolResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.GetNodePool", get, &resp))
+	assert.EqualValues(1000, resp.Index)
+	assert.Equal(p1, resp.NodePool)
+
+	// Lookup a non-existent pool
+	get.Name = "unknown"
+	var resp2 structs.SingleNodePoolResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.GetNodePool", get, &resp2))
+	assert.EqualValues(1000, resp2.Index)
+	assert.Nil(resp2.NodePool)
+}
+
+func TestNodePoolEndpoint_ListNodes(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+	state := s1.fsm.State()
+
+	n1 := mock.Node()
+	n2 := mock.Node()
+	n2.NodePool = "gpu"
+	assert.Nil(state.UpsertNode(1000, n1))
+	assert.Nil(state.UpsertNode(1001, n2))
+
+	// Lookup the nodes of the pool
+	get := &structs.NodePoolNodesRequest{
+		Name:         "gpu",
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.NodePoolNodesResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.ListNodes", get, &resp))
+	assert.EqualValues(1001, resp.Index)
+	if assert.Len(resp.Nodes, 1) {
+		assert.Equal(n2.ID, resp.Nodes[0].ID)
+		assert.Equal("gpu", resp.Nodes[0].NodePool)
+	}
+
+	// The all pool includes every node
+	get.Name = structs.NodePoolAll
+	var resp2 structs.NodePoolNodesResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "NodePool.ListNodes", get, &resp2))
+	assert.Len(resp2.Nodes, 2)
+}
@@ -119,3 +119,65 @@ REMOVE:
 	op.srv.logger.Printf("[WARN] nomad.operator: Removed Raft peer %q", args.Address)
 	return nil
 }
+
+// SchedulerGetConfiguration is used to retrieve the current scheduler
+// configuration.
+func (op *Operator) SchedulerGetConfiguration(args *structs.GenericRequest, reply *structs.SchedulerConfigurationResponse) error {
+	if done, err := op.srv.forward("Operator.SchedulerGetConfiguration", args, args, reply); done {
+		return err
+	}
+
+	// Check operator read permissions
+	if aclObj, err := op.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowOperatorRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	index, config, err := op.srv.fsm.State().SchedulerConfig()
+	if err != nil {
+		return err
+	}
+
+	reply.SchedulerConfig = config
+	reply.Index = index
+	op.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
+// SchedulerSetConfiguration is used to set the current scheduler
+// configuration.
+func (op *Operator) SchedulerSetConfiguration(args *structs.SchedulerSetConfigRequest, reply *structs.SchedulerSetConfigurationResponse) error {
+	if done, err := op.srv.forward("Operator.SchedulerSetConfiguration", args, args, reply); done {
+		return err
+	}
+
+	// Check operator write permissions
+	if aclObj, err := op.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowOperatorWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the configuration
+	if err := args.Config.Validate(); err != nil {
+		return err
+	}
+
+	resp, index, err := op.srv.raftApply(structs.SchedulerConfigRequestType, args)
+	if err != nil {
+		op.srv.logger.Printf("[ERR] nomad.operator: Apply failed: %v", err)
+		return err
+	}
+	if respErr, ok := resp.(error); ok {
+		return respErr
+	}
+
+	// Check if the CAS update was applied
+	reply.Updated = true
+	if args.CAS {
+		reply.Updated, _ = resp.(bool)
+	}
+	reply.Index = index
+	return nil
+}
@@ -222,3 +222,144 @@ func TestOperator_RaftRemovePeerByAddress_ACL(t *testing.T) {
 		assert.Nil(err)
 	}
 }
+
+func TestOperator_SchedulerGetSetConfiguration(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	// Set the configuration
+	setArg := structs.SchedulerSetConfigRequest{
+		Config: structs.SchedulerConfiguration{
+			MemoryOversubscriptionEnabled: true,
+		},
+		WriteRequest: structs.WriteRequest{
+			Region: s1.config.Region,
+		},
+	}
+	var setReply structs.SchedulerSetConfigurationResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &setArg, &setReply))
+	assert.NotZero(setReply.Index)
+	assert.True(setReply.Updated)
+
+	// Read it back
+	getArg := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: s1.config.Region,
+		},
+	}
+	var getReply structs.SchedulerConfigurationResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerGetConfiguration", &getArg, &getReply))
+	assert.Equal(setReply.Index, getReply.Index)
+	if assert.NotNil(getReply.SchedulerConfig) {
+		assert.True(getReply.SchedulerConfig.MemoryOversubscriptionEnabled)
+	}
+}
+
+func TestOperator_SchedulerSetConfiguration_CAS(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+
+	// Set the configuration
+	arg := structs.SchedulerSetConfigRequest{
+		Config: structs.SchedulerConfiguration{
+			SchedulerAlgorithm: structs.SchedulerAlgorithmSpread,
+		},
+		WriteRequest: structs.WriteRequest{
+			Region: s1.config.Region,
+		},
+	}
+	var reply structs.SchedulerSetConfigurationResponse
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply))
+	assert.True(reply.Updated)
+	index := reply.Index
+
+	// Try a CAS update with a stale index
+	arg.CAS = true
+	arg.Config = structs.SchedulerConfiguration{
+		SchedulerAlgorithm: structs.SchedulerAlgorithmBinpack,
+		ModifyIndex:        index - 1,
+	}
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply))
+	assert.False(reply.Updated)
+
+	_, config, err := s1.fsm.State().SchedulerConfig()
+	assert.Nil(err)
+	assert.Equal(structs.SchedulerAlgorithmSpread, config.SchedulerAlgorithm)
+
+	// Try a CAS update with the current index
+	arg.Config.ModifyIndex = index
+	assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply))
+	assert.True(reply.Updated)
+
+	_, config, err = s1.fsm.State().SchedulerConfig()
+	assert.Nil(err)
+	assert.Equal(structs.SchedulerAlgorithmBinpack, config.SchedulerAlgorithm)
+
+	// Invalid configurations are rejected
+	arg.CAS = false
+	arg.Config.SchedulerAlgorithm = "random"
+	err = msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "invalid scheduler algorithm")
+	}
+}
+
+func TestOperator_SchedulerSetConfiguration_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := testACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+	state := s1.fsm.State()
+
+	// Create ACL token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.SchedulerSetConfigRequest{
+		Config: structs.SchedulerConfiguration{
+			MemoryOversubscriptionEnabled: true,
+		},
+		WriteRequest: structs.WriteRequest{
+			Region: s1.config.Region,
+		},
+	}
+
+	// Try with no token and expect permission denied
+	{
+		var reply structs.SchedulerSetConfigurationResponse
+		err := msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with an invalid token and expect permission denied
+	{
+		arg.SecretID = invalidToken.SecretID
+		var reply structs.SchedulerSetConfigurationResponse
+		err := msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Use management token
+	{
+		arg.SecretID = root.SecretID
+		var reply structs.SchedulerSetConfigurationResponse
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "Operator.SchedulerSetConfiguration", &arg, &reply))
+
+		_, config, err := state.SchedulerConfig()
+		assert.Nil(err)
+		if assert.NotNil(config) {
+			assert.True(config.MemoryOversubscriptionEnabled)
+		}
+	}
+}
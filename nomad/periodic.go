@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"strconv"
 	"strings"
@@ -25,6 +26,10 @@ type PeriodicDispatch struct {
 	tracked map[structs.NamespacedID]*structs.Job
 	heap    *periodicHeap
 
+	// paused is the set of jobs whose launches are paused. Paused jobs
+	// remain tracked so that resuming them continues their schedule.
+	paused map[structs.NamespacedID]struct{}
+
 	updateCh chan struct{}
 	stopFn   context.CancelFunc
 	logger   *log.Logger
@@ -149,6 +154,7 @@ func NewPeriodicDispatch(logger *log.Logger, dispatcher JobEvalDispatcher) *Peri
 		dispatcher: dispatcher,
 		tracked:    make(map[structs.NamespacedID]*structs.Job),
 		heap:       NewPeriodicHeap(),
+		paused:     make(map[structs.NamespacedID]struct{}),
 		updateCh:   make(chan struct{}, 1),
 		logger:     logger,
 	}
@@ -221,7 +227,7 @@ func (p *PeriodicDispatch) Add(job *structs.Job) (added bool, err error) {
 
 	// Add or update the job.
 	p.tracked[tuple] = job
-	next := job.Periodic.Next(time.Now().In(job.Periodic.GetLocation()))
+	next := launchAfter(job, time.Now().In(job.Periodic.GetLocation()))
 	if tracked {
 		if err := p.heap.Update(job, next); err != nil {
 			return false, fmt.Errorf("failed to update job %q (%s) launch time: %v", job.ID, job.Namespace, err)
@@ -268,6 +274,7 @@ func (p *PeriodicDispatch) removeLocked(jobID structs.NamespacedID) error {
 	}
 
 	delete(p.tracked, jobID)
+	delete(p.paused, jobID)
 	if err := p.heap.Remove(job); err != nil {
 		return fmt.Errorf("failed to remove tracked job %q (%s): %v", jobID.ID, jobID.Namespace, err)
 	}
@@ -282,9 +289,40 @@ func (p *PeriodicDispatch) removeLocked(jobID structs.NamespacedID) error {
 	return nil
 }
 
+// SetPaused pauses or resumes the launches of the periodic job. Paused jobs
+// can still be force run.
+func (p *PeriodicDispatch) SetPaused(namespace, jobID string, paused bool) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	// Do nothing if not enabled
+	if !p.enabled {
+		return
+	}
+
+	tuple := structs.NamespacedID{
+		ID:        jobID,
+		Namespace: namespace,
+	}
+	if paused {
+		p.paused[tuple] = struct{}{}
+		p.logger.Printf("[DEBUG] nomad.periodic: paused periodic job %q (%s)", jobID, namespace)
+	} else {
+		delete(p.paused, tuple)
+		p.logger.Printf("[DEBUG] nomad.periodic: resumed periodic job %q (%s)", jobID, namespace)
+	}
+}
+
 // ForceRun causes the periodic job to be evaluated immediately and returns the
 // subsequent eval.
 func (p *PeriodicDispatch) ForceRun(namespace, jobID string) (*structs.Evaluation, error) {
+	return p.LaunchAt(namespace, jobID, time.Now())
+}
+
+// LaunchAt causes the periodic job to be evaluated immediately as the launch
+// of the passed time and returns the subsequent eval. It is used to catch up
+// launches missed while there was no leader.
+func (p *PeriodicDispatch) LaunchAt(namespace, jobID string, launch time.Time) (*structs.Evaluation, error) {
 	p.l.Lock()
 
 	// Do nothing if not enabled
@@ -304,7 +342,7 @@ func (p *PeriodicDispatch) ForceRun(namespace, jobID string) (*structs.Evaluatio
 	}
 
 	p.l.Unlock()
-	return p.createEval(job, time.Now().In(job.Periodic.GetLocation()))
+	return p.createEval(job, launch.In(job.Periodic.GetLocation()))
 }
 
 // shouldRun returns whether the long lived run function should run.
@@ -344,11 +382,22 @@ func (p *PeriodicDispatch) run(ctx context.Context) {
 func (p *PeriodicDispatch) dispatch(job *structs.Job, launchTime time.Time) {
 	p.l.Lock()
 
-	nextLaunch := job.Periodic.Next(launchTime)
+	nextLaunch := launchAfter(job, launchTime)
 	if err := p.heap.Update(job, nextLaunch); err != nil {
 		p.logger.Printf("[ERR] nomad.periodic: failed to update next launch of periodic job %q (%s): %v", job.ID, job.Namespace, err)
 	}
 
+	// Skip the launch if the job is paused
+	tuple := structs.NamespacedID{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+	}
+	if _, ok := p.paused[tuple]; ok {
+		p.logger.Printf("[DEBUG] nomad.periodic: skipping launch of paused periodic job %q (%s)", job.ID, job.Namespace)
+		p.l.Unlock()
+		return
+	}
+
 	// If the job prohibits overlapping and there are running children, we skip
 	// the launch.
 	if job.Periodic.ProhibitOverlap {
@@ -375,6 +424,22 @@ func (p *PeriodicDispatch) dispatch(job *structs.Job, launchTime time.Time) {
 	p.createEval(job, launchTime)
 }
 
+// launchAfter returns the next launch of the job after the passed time,
+// including the launch jitter of the job.
+func launchAfter(job *structs.Job, fromTime time.Time) time.Time {
+	next := job.Periodic.Next(fromTime)
+	if next.IsZero() || job.Periodic.Jitter <= 0 {
+		return next
+	}
+
+	// Derive the jitter from the job and the launch so that it is stable
+	// when the launch is recomputed.
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%s/%d", job.Namespace, job.ID, next.Unix())
+	jitter := time.Duration(h.Sum64() % uint64(job.Periodic.Jitter))
+	return next.Add(jitter.Truncate(time.Second))
+}
+
 // nextLaunch returns the next job to launch and when it should be launched. If
 // the next job can't be determined, an error is returned. If the dispatcher is
 // stopped, a nil job will be returned.
@@ -468,6 +533,7 @@ func (p *PeriodicDispatch) flush() {
 	p.updateCh = make(chan struct{}, 1)
 	p.tracked = make(map[structs.NamespacedID]*structs.Job)
 	p.heap = NewPeriodicHeap()
+	p.paused = make(map[structs.NamespacedID]struct{})
 	p.stopFn = nil
 }
 
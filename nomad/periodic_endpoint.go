@@ -6,6 +6,8 @@ import (
 
 	"github.com/armon/go-metrics"
 	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -56,3 +58,102 @@ func (p *Periodic) Force(args *structs.PeriodicForceRequest, reply *structs.Peri
 	reply.Index = eval.CreateIndex
 	return nil
 }
+
+// Pause is used to pause or resume the launches of a periodic job
+func (p *Periodic) Pause(args *structs.PeriodicPauseRequest, reply *structs.GenericResponse) error {
+	if done, err := p.srv.forward("Periodic.Pause", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "periodic", "pause"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := p.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID")
+	}
+
+	// Lookup the job
+	snap, err := p.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	ws := memdb.NewWatchSet()
+	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job not found")
+	}
+
+	if !job.IsPeriodic() || job.IsParameterized() {
+		return fmt.Errorf("can't pause non-periodic job")
+	}
+
+	// Commit the paused state
+	fsmErr, index, err := p.srv.raftApply(structs.PeriodicPauseRequestType, args)
+	if err, ok := fsmErr.(error); ok && err != nil {
+		p.srv.logger.Printf("[ERR] nomad.periodic: Pause failed: %v", err)
+		return err
+	}
+	if err != nil {
+		p.srv.logger.Printf("[ERR] nomad.periodic: Pause failed: %v", err)
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// GetLaunch is used to lookup the launch state of a periodic job
+func (p *Periodic) GetLaunch(args *structs.PeriodicLaunchSpecificRequest,
+	reply *structs.SinglePeriodicLaunchResponse) error {
+
+	if done, err := p.srv.forward("Periodic.GetLaunch", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "periodic", "get_launch"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := p.srv.ResolveToken(args.SecretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, state *state.StateStore) error {
+			out, err := state.PeriodicLaunchByID(ws, args.RequestNamespace(), args.JobID)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Launch = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the periodic launch table
+				index, err := state.Index("periodic_launch")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			p.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return p.srv.blockingRPC(&opts)
+}
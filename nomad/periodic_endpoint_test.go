@@ -91,3 +91,129 @@ func TestPeriodicEndpoint_Force_NonPeriodic(t *testing.T) {
 		t.Fatalf("Force on non-perodic job should err")
 	}
 }
+
+func TestPeriodicEndpoint_Pause(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	state := s1.fsm.State()
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a periodic job so that its launch is tracked.
+	job := mock.PeriodicJob()
+	regReq := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Pause it.
+	req := &structs.PeriodicPauseRequest{
+		JobID:  job.ID,
+		Paused: true,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Periodic.Pause", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// Check the launch is paused in the state and the dispatcher
+	ws := memdb.NewWatchSet()
+	launch, err := state.PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if launch == nil || !launch.Paused {
+		t.Fatalf("bad: %#v", launch)
+	}
+	tuple := structs.NamespacedID{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+	}
+	if _, paused := s1.periodicDispatcher.paused[tuple]; !paused {
+		t.Fatalf("periodic job not paused")
+	}
+
+	// Lookup the launch
+	get := &structs.PeriodicLaunchSpecificRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var getResp structs.SinglePeriodicLaunchResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Periodic.GetLaunch", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Launch == nil || !getResp.Launch.Paused {
+		t.Fatalf("bad: %#v", getResp.Launch)
+	}
+	if getResp.Index != resp.Index {
+		t.Fatalf("bad index: %d %d", getResp.Index, resp.Index)
+	}
+
+	// Resume it.
+	req.Paused = false
+	if err := msgpackrpc.CallWithCodec(codec, "Periodic.Pause", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	launch, err = state.PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if launch.Paused {
+		t.Fatalf("bad: %#v", launch)
+	}
+	if _, paused := s1.periodicDispatcher.paused[tuple]; paused {
+		t.Fatalf("periodic job not resumed")
+	}
+}
+
+func TestPeriodicEndpoint_Pause_NonPeriodic(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	state := s1.fsm.State()
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create and insert a non-periodic job.
+	job := mock.Job()
+	if err := state.UpsertJob(100, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Pause it.
+	req := &structs.PeriodicPauseRequest{
+		JobID:  job.ID,
+		Paused: true,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Periodic.Pause", req, &resp); err == nil {
+		t.Fatalf("Pause on non-perodic job should err")
+	}
+}
@@ -453,6 +453,68 @@ func TestPeriodicDispatch_Run_Multiple(t *testing.T) {
 	}
 }
 
+func TestPeriodicDispatch_Run_Paused(t *testing.T) {
+	t.Parallel()
+	p, m := testPeriodicDispatcher()
+
+	// Create a job that will be launched twice.
+	launch1 := time.Now().Round(1 * time.Second).Add(1 * time.Second)
+	launch2 := time.Now().Round(1 * time.Second).Add(3 * time.Second)
+	job := testPeriodicJob(launch1, launch2)
+
+	// Add it and pause it.
+	if added, err := p.Add(job); err != nil || !added {
+		t.Fatalf("Add failed %v %v", added, err)
+	}
+	p.SetPaused(job.Namespace, job.ID, true)
+
+	time.Sleep(2 * time.Second)
+
+	// Check that the job was not launched.
+	times, err := m.LaunchTimes(p, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("failed to get launch times for job %q", job.ID)
+	}
+	if len(times) != 0 {
+		t.Fatalf("paused job %q was launched: %v", job.ID, times)
+	}
+
+	// Resume the job and check that the next launch occurs.
+	p.SetPaused(job.Namespace, job.ID, false)
+	time.Sleep(2 * time.Second)
+
+	times, err = m.LaunchTimes(p, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("failed to get launch times for job %q", job.ID)
+	}
+	if len(times) != 1 {
+		t.Fatalf("incorrect number of launch times for job %q; got %v", job.ID, times)
+	}
+	if times[0] != launch2 {
+		t.Fatalf("periodic dispatcher created eval for time %v; want %v", times[0], launch2)
+	}
+}
+
+func TestPeriodicDispatch_LaunchAfter_Jitter(t *testing.T) {
+	t.Parallel()
+	job := mock.PeriodicJob()
+	job.Periodic.Spec = "0 * * * *"
+	job.Periodic.Jitter = 10 * time.Minute
+	job.Periodic.Canonicalize()
+
+	from := time.Date(2009, time.November, 10, 23, 22, 30, 0, time.UTC)
+	next := job.Periodic.Next(from)
+	launch := launchAfter(job, from)
+	if launch.Before(next) || !launch.Before(next.Add(job.Periodic.Jitter)) {
+		t.Fatalf("launch %v not within jitter of %v", launch, next)
+	}
+
+	// The jitter must be stable for the same launch.
+	if other := launchAfter(job, from); other != launch {
+		t.Fatalf("unstable jitter: got %v and %v", launch, other)
+	}
+}
+
 func TestPeriodicDispatch_Run_SameTime(t *testing.T) {
 	t.Parallel()
 	p, m := testPeriodicDispatcher()
@@ -0,0 +1,90 @@
+package nomad
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/scheduler"
+)
+
+// setupSchedulerPlugins launches the configured scheduler plugins and enables
+// their job types on the workers of this server. Evaluations are queued in the
+// eval broker by job type, so only workers hosting a plugin dequeue the
+// evaluations of its jobs.
+func (s *Server) setupSchedulerPlugins() error {
+	s.schedulerPlugins = make(map[string]scheduler.Factory, len(s.config.SchedulerPlugins))
+	for _, p := range s.config.SchedulerPlugins {
+		switch {
+		case p.JobType == "":
+			return fmt.Errorf("scheduler plugin %q is missing a job type", p.Command)
+		case p.JobType == structs.JobTypeCore:
+			return fmt.Errorf("scheduler plugin can not handle reserved job type %q", p.JobType)
+		case p.Command == "":
+			return fmt.Errorf("scheduler plugin for job type %q is missing a command", p.JobType)
+		}
+		if _, ok := scheduler.BuiltinSchedulers[p.JobType]; ok {
+			return fmt.Errorf("scheduler plugin can not replace builtin scheduler %q", p.JobType)
+		}
+		if _, ok := s.schedulerPlugins[p.JobType]; ok {
+			return fmt.Errorf("duplicate scheduler plugin for job type %q", p.JobType)
+		}
+
+		client := plugin.NewClient(&plugin.ClientConfig{
+			HandshakeConfig: scheduler.PluginHandshake,
+			Plugins: map[string]plugin.Plugin{
+				scheduler.PluginName: new(scheduler.SchedulerPlugin),
+			},
+			Cmd:    exec.Command(p.Command, p.Args...),
+			Stderr: s.config.LogOutput,
+		})
+		s.schedulerPluginClients = append(s.schedulerPluginClients, client)
+
+		rpcClient, err := client.Client()
+		if err != nil {
+			return fmt.Errorf("failed to launch scheduler plugin for job type %q: %v", p.JobType, err)
+		}
+		raw, err := rpcClient.Dispense(scheduler.PluginName)
+		if err != nil {
+			return fmt.Errorf("failed to dispense scheduler plugin for job type %q: %v", p.JobType, err)
+		}
+		s.schedulerPlugins[p.JobType] = raw.(*scheduler.SchedulerRPC).Factory()
+
+		// Route the evaluations of the job type to the workers
+		enabled := false
+		for _, name := range s.config.EnabledSchedulers {
+			if name == p.JobType {
+				enabled = true
+				break
+			}
+		}
+		if !enabled {
+			s.config.EnabledSchedulers = append(s.config.EnabledSchedulers, p.JobType)
+		}
+		s.logger.Printf("[INFO] nomad: loaded scheduler plugin %q for job type %q", p.Command, p.JobType)
+	}
+	return nil
+}
+
+// schedulerPluginTypes returns the sorted job types handled by the scheduler
+// plugins of this server.
+func (s *Server) schedulerPluginTypes() []string {
+	types := make([]string, 0, len(s.schedulerPlugins))
+	for jobType := range s.schedulerPlugins {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// newScheduler instantiates the scheduler for the given job type, preferring
+// a scheduler plugin over the builtin schedulers.
+func (s *Server) newScheduler(name string, logger *log.Logger, state scheduler.State, planner scheduler.Planner) (scheduler.Scheduler, error) {
+	if factory, ok := s.schedulerPlugins[name]; ok {
+		return factory(logger, state, planner), nil
+	}
+	return scheduler.NewScheduler(name, logger, state, planner)
+}
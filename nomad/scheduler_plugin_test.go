@@ -0,0 +1,42 @@
+package nomad
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+func TestServer_setupSchedulerPlugins_Invalid(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Plugins []*config.SchedulerPluginConfig
+		Err     string
+	}{
+		{
+			Plugins: []*config.SchedulerPluginConfig{{Command: "/bin/custom"}},
+			Err:     "missing a job type",
+		},
+		{
+			Plugins: []*config.SchedulerPluginConfig{{JobType: "custom"}},
+			Err:     "missing a command",
+		},
+		{
+			Plugins: []*config.SchedulerPluginConfig{{JobType: structs.JobTypeCore, Command: "/bin/custom"}},
+			Err:     "reserved job type",
+		},
+		{
+			Plugins: []*config.SchedulerPluginConfig{{JobType: structs.JobTypeService, Command: "/bin/custom"}},
+			Err:     "builtin scheduler",
+		},
+	}
+
+	for _, c := range cases {
+		s := &Server{config: &Config{SchedulerPlugins: c.Plugins}}
+		err := s.setupSchedulerPlugins()
+		if err == nil || !strings.Contains(err.Error(), c.Err) {
+			t.Fatalf("expected error containing %q; got %v", c.Err, err)
+		}
+	}
+}
@@ -20,12 +20,14 @@ import (
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/lib"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-plugin"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/nomad/command/agent/consul"
 	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/deploymentwatcher"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/scheduler"
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
@@ -145,6 +147,11 @@ type Server struct {
 	// periodicDispatcher is used to track and create evaluations for periodic jobs.
 	periodicDispatcher *PeriodicDispatch
 
+	// dispatchLock serializes the dispatches of parameterized jobs with the
+	// release of queued dispatched jobs so that idempotency tokens and
+	// running limits are enforced.
+	dispatchLock sync.Mutex
+
 	// planQueue is used to manage the submitted allocation
 	// plans that are waiting to be assessed by the leader
 	planQueue *PlanQueue
@@ -160,9 +167,20 @@ type Server struct {
 	// vault is the client for communicating with Vault.
 	vault VaultClient
 
+	// consulACLs is used to validate and derive Consul tokens for jobs.
+	consulACLs ConsulACLsAPI
+
 	// Worker used for processing
 	workers []*Worker
 
+	// schedulerPlugins maps the job types handled by scheduler plugins to
+	// the factories creating their schedulers
+	schedulerPlugins map[string]scheduler.Factory
+
+	// schedulerPluginClients are the clients of the launched scheduler
+	// plugins
+	schedulerPluginClients []*plugin.Client
+
 	// aclCache is used to maintain the parsed ACL objects
 	aclCache *lru.TwoQueueCache
 
@@ -179,6 +197,7 @@ type Server struct {
 type endpoints struct {
 	Status     *Status
 	Node       *Node
+	NodePool   *NodePool
 	Job        *Job
 	Eval       *Eval
 	Plan       *Plan
@@ -273,6 +292,13 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, logger *log.Logg
 		return nil, fmt.Errorf("Failed to setup Vault client: %v", err)
 	}
 
+	// Setup Consul ACLs
+	if err := s.setupConsulACLs(); err != nil {
+		s.Shutdown()
+		s.logger.Printf("[ERR] nomad: failed to setup Consul ACL client: %v", err)
+		return nil, fmt.Errorf("Failed to setup Consul ACL client: %v", err)
+	}
+
 	// Initialize the RPC layer
 	if err := s.setupRPC(tlsWrap); err != nil {
 		s.Shutdown()
@@ -295,6 +321,13 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, logger *log.Logg
 		return nil, fmt.Errorf("Failed to start serf: %v", err)
 	}
 
+	// Launch the scheduler plugins
+	if err := s.setupSchedulerPlugins(); err != nil {
+		s.Shutdown()
+		s.logger.Printf("[ERR] nomad: failed to start scheduler plugins: %s", err)
+		return nil, fmt.Errorf("Failed to start scheduler plugins: %v", err)
+	}
+
 	// Initialize the scheduling workers
 	if err := s.setupWorkers(); err != nil {
 		s.Shutdown()
@@ -395,6 +428,11 @@ func (s *Server) Shutdown() error {
 		s.vault.Stop()
 	}
 
+	// Stop the scheduler plugins
+	for _, client := range s.schedulerPluginClients {
+		client.Kill()
+	}
+
 	return nil
 }
 
@@ -732,6 +770,20 @@ func (s *Server) setupVaultClient() error {
 	return nil
 }
 
+// setupConsulACLs is used to set up the client used to manage Consul tokens.
+func (s *Server) setupConsulACLs() error {
+	conf, err := s.config.ConsulConfig.ApiConfig()
+	if err != nil {
+		return err
+	}
+	client, err := consulapi.NewClient(conf)
+	if err != nil {
+		return err
+	}
+	s.consulACLs = NewConsulACLsAPI(client.ACL(), s.logger, s.purgeConsulAccessors)
+	return nil
+}
+
 // setupRPC is used to setup the RPC listener
 func (s *Server) setupRPC(tlsWrap tlsutil.RegionWrapper) error {
 	// Create endpoints
@@ -740,6 +792,7 @@ func (s *Server) setupRPC(tlsWrap tlsutil.RegionWrapper) error {
 	s.endpoints.Eval = &Eval{s}
 	s.endpoints.Job = &Job{s}
 	s.endpoints.Node = &Node{srv: s}
+	s.endpoints.NodePool = &NodePool{s}
 	s.endpoints.Deployment = &Deployment{srv: s}
 	s.endpoints.Operator = &Operator{s}
 	s.endpoints.Periodic = &Periodic{s}
@@ -756,6 +809,7 @@ func (s *Server) setupRPC(tlsWrap tlsutil.RegionWrapper) error {
 	s.rpcServer.Register(s.endpoints.Eval)
 	s.rpcServer.Register(s.endpoints.Job)
 	s.rpcServer.Register(s.endpoints.Node)
+	s.rpcServer.Register(s.endpoints.NodePool)
 	s.rpcServer.Register(s.endpoints.Deployment)
 	s.rpcServer.Register(s.endpoints.Operator)
 	s.rpcServer.Register(s.endpoints.Periodic)
@@ -36,13 +36,17 @@ func init() {
 		jobTableSchema,
 		jobSummarySchema,
 		jobVersionSchema,
+		jobSubmissionSchema,
 		deploymentSchema,
 		periodicLaunchTableSchema,
 		evalTableSchema,
 		allocTableSchema,
 		vaultAccessorTableSchema,
+		consulAccessorTableSchema,
 		aclPolicyTableSchema,
 		aclTokenTableSchema,
+		schedulerConfigTableSchema,
+		nodePoolTableSchema,
 	}...)
 }
 
@@ -99,6 +103,16 @@ func nodeTableSchema() *memdb.TableSchema {
 					Field: "ID",
 				},
 			},
+
+			// NodePool index is used to lookup the nodes in a node pool
+			"node_pool": {
+				Name:         "node_pool",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "NodePool",
+				},
+			},
 		},
 	}
 }
@@ -156,6 +170,38 @@ func jobTableSchema() *memdb.TableSchema {
 					Conditional: jobIsPeriodic,
 				},
 			},
+			"node_pool": {
+				Name:         "node_pool",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "NodePool",
+				},
+			},
+
+			// Dispatched jobs are indexed by the tuple of (Namespace,
+			// ParentID, DispatchIdempotencyToken). Jobs without a token are
+			// not indexed.
+			"idempotency_token": {
+				Name:         "idempotency_token",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "ParentID",
+						},
+
+						&memdb.StringFieldIndex{
+							Field: "DispatchIdempotencyToken",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -222,6 +268,40 @@ func jobVersionSchema() *memdb.TableSchema {
 	}
 }
 
+// jobSubmissionSchema returns the memdb schema for the job submission table
+// which keeps the original source of job versions.
+func jobSubmissionSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "job_submission",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+
+				// Use a compound index so the tuple of (Namespace, JobID,
+				// Version) is uniquely identifying
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+
+						&memdb.StringFieldIndex{
+							Field:     "JobID",
+							Lowercase: true,
+						},
+
+						&memdb.UintFieldIndex{
+							Field: "Version",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 // jobIsGCable satisfies the ConditionalIndexFunc interface and creates an index
 // on whether a job is eligible for garbage collection.
 func jobIsGCable(obj interface{}) (bool, error) {
@@ -248,9 +328,9 @@ func jobIsGCable(obj interface{}) (bool, error) {
 		return true, nil
 	}
 
-	// Otherwise, only batch jobs are eligible because they complete on their
-	// own without a user stopping them.
-	if j.Type != structs.JobTypeBatch {
+	// Otherwise, only batch and sysbatch jobs are eligible because they
+	// complete on their own without a user stopping them.
+	if j.Type != structs.JobTypeBatch && j.Type != structs.JobTypeSysBatch {
 		return false, nil
 	}
 
@@ -539,6 +619,35 @@ func vaultAccessorTableSchema() *memdb.TableSchema {
 	}
 }
 
+// consulAccessorTableSchema returns the MemDB schema for the Consul Accessor
+// Table. This table tracks the Consul tokens created on behalf of allocations
+// so they can be revoked once the allocations are terminal.
+func consulAccessorTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "consul_accessors",
+		Indexes: map[string]*memdb.IndexSchema{
+			// The primary index is the accessor id
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Accessor",
+				},
+			},
+
+			"alloc_id": {
+				Name:         "alloc_id",
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "AllocID",
+				},
+			},
+		},
+	}
+}
+
 // aclPolicyTableSchema returns the MemDB schema for the policy table.
 // This table is used to store the policies which are refrenced by tokens
 func aclPolicyTableSchema() *memdb.TableSchema {
@@ -590,3 +699,39 @@ func aclTokenTableSchema() *memdb.TableSchema {
 		},
 	}
 }
+
+// schedulerConfigTableSchema returns the MemDB schema for the scheduler
+// configuration table. The table holds a single configuration object.
+func schedulerConfigTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "scheduler_config",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: true,
+				Unique:       true,
+				Indexer: &memdb.ConditionalIndex{
+					Conditional: func(obj interface{}) (bool, error) { return true, nil },
+				},
+			},
+		},
+	}
+}
+
+// nodePoolTableSchema returns the MemDB schema for the node pools table.
+// This table is used to store the node pools that partition the nodes.
+func nodePoolTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "node_pools",
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+		},
+	}
+}
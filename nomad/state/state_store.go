@@ -49,6 +49,11 @@ func NewStateStore(logOutput io.Writer) (*StateStore, error) {
 		db:        db,
 		abandonCh: make(chan struct{}),
 	}
+
+	// Create the built-in node pools
+	if err := s.upsertBuiltInNodePools(); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -501,6 +506,15 @@ func (s *StateStore) UpsertNode(index uint64, node *structs.Node) error {
 		node.ModifyIndex = index
 	}
 
+	// Nodes join the default node pool unless configured otherwise, and
+	// create their node pool if it doesn't exist yet
+	if node.NodePool == "" {
+		node.NodePool = structs.NodePoolDefault
+	}
+	if err := s.ensureNodePoolExists(txn, index, node.NodePool); err != nil {
+		return err
+	}
+
 	// Insert the node
 	if err := txn.Insert("nodes", node); err != nil {
 		return fmt.Errorf("node insert failed: %v", err)
@@ -540,7 +554,7 @@ func (s *StateStore) DeleteNode(index uint64, nodeID string) error {
 }
 
 // UpdateNodeStatus is used to update the status of a node
-func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string) error {
+func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string, updatedAt int64) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
@@ -561,6 +575,9 @@ func (s *StateStore) UpdateNodeStatus(index uint64, nodeID, status string) error
 	// Update the status in the copy
 	copyNode.Status = status
 	copyNode.ModifyIndex = index
+	if updatedAt != 0 {
+		copyNode.StatusUpdatedAt = updatedAt
+	}
 
 	// Insert the node
 	if err := txn.Insert("nodes", copyNode); err != nil {
@@ -653,15 +670,56 @@ func (s *StateStore) Nodes(ws memdb.WatchSet) (memdb.ResultIterator, error) {
 
 // UpsertJob is used to register a job or update a job definition
 func (s *StateStore) UpsertJob(index uint64, job *structs.Job) error {
+	return s.UpsertJobWithSubmission(index, job, nil)
+}
+
+// UpsertJobWithSubmission is used to register a job or update a job
+// definition, storing the original source of the job with the version it
+// produces if the submission is given.
+func (s *StateStore) UpsertJobWithSubmission(index uint64, job *structs.Job, sub *structs.JobSubmission) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 	if err := s.upsertJobImpl(index, job, false, txn); err != nil {
 		return err
 	}
+	if sub != nil {
+		if err := s.upsertJobSubmission(index, job, sub, txn); err != nil {
+			return err
+		}
+	}
 	txn.Commit()
 	return nil
 }
 
+// upsertJobSubmission stores the submission with the current version of the
+// job.
+func (s *StateStore) upsertJobSubmission(index uint64, job *structs.Job, sub *structs.JobSubmission, txn *memdb.Txn) error {
+	sub = sub.Copy()
+	sub.Namespace = job.Namespace
+	sub.JobID = job.ID
+	sub.Version = job.Version
+	sub.CreateIndex = index
+	sub.ModifyIndex = index
+
+	// The submission of an existing version is replaced when the job is
+	// registered without a version bump
+	existing, err := txn.First("job_submission", "id", sub.Namespace, sub.JobID, sub.Version)
+	if err != nil {
+		return fmt.Errorf("job submission lookup failed: %v", err)
+	}
+	if existing != nil {
+		sub.CreateIndex = existing.(*structs.JobSubmission).CreateIndex
+	}
+
+	if err := txn.Insert("job_submission", sub); err != nil {
+		return fmt.Errorf("job submission insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"job_submission", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
 // upsertJobImpl is the implementation for registering a job or updating a job definition
 func (s *StateStore) upsertJobImpl(index uint64, job *structs.Job, keepVersion bool, txn *memdb.Txn) error {
 	// COMPAT 0.7: Upgrade old objects that do not have namespaces
@@ -676,6 +734,15 @@ func (s *StateStore) upsertJobImpl(index uint64, job *structs.Job, keepVersion b
 		return fmt.Errorf("job %q is in non-existent namespace %q", job.ID, job.Namespace)
 	}
 
+	// Assert the node pool exists
+	if job.NodePool != "" {
+		if existing, err := txn.First("node_pools", "id", job.NodePool); err != nil {
+			return fmt.Errorf("node pool lookup failed: %v", err)
+		} else if existing == nil {
+			return fmt.Errorf("job %q is in non-existent node pool %q", job.ID, job.NodePool)
+		}
+	}
+
 	// Check if the job already exists
 	existing, err := txn.First("jobs", "id", job.Namespace, job.ID)
 	if err != nil {
@@ -788,6 +855,10 @@ func (s *StateStore) DeleteJob(index uint64, namespace, jobID string) error {
 					pSummary.Children.Running--
 					pSummary.Children.Dead++
 					modified = true
+				case structs.JobStatusQueued:
+					pSummary.Children.Queued--
+					pSummary.Children.Dead++
+					modified = true
 				case structs.JobStatusDead:
 				default:
 					return fmt.Errorf("unknown old job status %q", job.Status)
@@ -872,6 +943,49 @@ func (s *StateStore) deleteJobVersions(index uint64, job *structs.Job, txn *memd
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Delete the submissions of the versions
+	iter, err = txn.Get("job_submission", "id_prefix", job.Namespace, job.ID)
+	if err != nil {
+		return err
+	}
+
+	var subs []*structs.JobSubmission
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+
+		// Ensure the ID is an exact match
+		sub := raw.(*structs.JobSubmission)
+		if sub.JobID != job.ID {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+
+	for _, sub := range subs {
+		if err := s.deleteJobSubmission(index, sub.Namespace, sub.JobID, sub.Version, txn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteJobSubmission deletes the submission of the job version, if any.
+func (s *StateStore) deleteJobSubmission(index uint64, namespace, jobID string, version uint64, txn *memdb.Txn) error {
+	num, err := txn.DeleteAll("job_submission", "id", namespace, jobID, version)
+	if err != nil {
+		return fmt.Errorf("deleting job submission failed: %v", err)
+	}
+	if num == 0 {
+		return nil
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"job_submission", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
 	return nil
 }
 
@@ -926,7 +1040,8 @@ func (s *StateStore) upsertJobVersion(index uint64, job *structs.Job, txn *memdb
 		return fmt.Errorf("failed to delete job %v (%d) from job_version", d.ID, d.Version)
 	}
 
-	return nil
+	// Garbage collect the submission of the deleted version
+	return s.deleteJobSubmission(index, d.Namespace, d.ID, d.Version, txn)
 }
 
 // JobByID is used to lookup a job by its ID. JobByID returns the current/latest job
@@ -1075,6 +1190,42 @@ func (s *StateStore) JobVersions(ws memdb.WatchSet) (memdb.ResultIterator, error
 	return iter, nil
 }
 
+// JobSubmission returns the original source of the job version, or nil if it
+// was not stored.
+func (s *StateStore) JobSubmission(ws memdb.WatchSet, namespace, jobID string, version uint64) (*structs.JobSubmission, error) {
+	txn := s.db.Txn(false)
+
+	// COMPAT 0.7: Upgrade old objects that do not have namespaces
+	if namespace == "" {
+		namespace = structs.DefaultNamespace
+	}
+
+	watchCh, existing, err := txn.FirstWatch("job_submission", "id", namespace, jobID, version)
+	if err != nil {
+		return nil, fmt.Errorf("job submission lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.JobSubmission), nil
+	}
+	return nil, nil
+}
+
+// JobSubmissions returns an iterator over all the job submissions
+func (s *StateStore) JobSubmissions(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire job submissions table
+	iter, err := txn.Get("job_submission", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
 // Jobs returns an iterator over all the jobs
 func (s *StateStore) Jobs(ws memdb.WatchSet) (memdb.ResultIterator, error) {
 	txn := s.db.Txn(false)
@@ -1139,6 +1290,21 @@ func (s *StateStore) JobsByScheduler(ws memdb.WatchSet, schedulerType string) (m
 	return iter, nil
 }
 
+// JobsByIdempotencyToken returns an iterator over the jobs dispatched from the
+// given parameterized job with the given idempotency token.
+func (s *StateStore) JobsByIdempotencyToken(ws memdb.WatchSet, namespace, parentID, token string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("jobs", "idempotency_token", namespace, parentID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
 // JobsByGC returns an iterator over all jobs eligible or uneligible for garbage
 // collection.
 func (s *StateStore) JobsByGC(ws memdb.WatchSet, gc bool) (memdb.ResultIterator, error) {
@@ -1228,10 +1394,12 @@ func (s *StateStore) UpsertPeriodicLaunch(index uint64, launch *structs.Periodic
 		return fmt.Errorf("periodic launch lookup failed: %v", err)
 	}
 
-	// Setup the indexes correctly
+	// Setup the indexes correctly. Launches don't change whether the job is
+	// paused.
 	if existing != nil {
 		launch.CreateIndex = existing.(*structs.PeriodicLaunch).CreateIndex
 		launch.ModifyIndex = index
+		launch.Paused = existing.(*structs.PeriodicLaunch).Paused
 	} else {
 		launch.CreateIndex = index
 		launch.ModifyIndex = index
@@ -1249,6 +1417,37 @@ func (s *StateStore) UpsertPeriodicLaunch(index uint64, launch *structs.Periodic
 	return nil
 }
 
+// UpdatePeriodicLaunchPaused is used to pause or resume the launches of a
+// periodic job.
+func (s *StateStore) UpdatePeriodicLaunchPaused(index uint64, namespace, jobID string, paused bool) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	existing, err := txn.First("periodic_launch", "id", namespace, jobID)
+	if err != nil {
+		return fmt.Errorf("periodic launch lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("periodic launch for job %q (%s) not found", jobID, namespace)
+	}
+
+	// Copy the launch and update its paused state
+	launch := new(structs.PeriodicLaunch)
+	*launch = *existing.(*structs.PeriodicLaunch)
+	launch.Paused = paused
+	launch.ModifyIndex = index
+
+	if err := txn.Insert("periodic_launch", launch); err != nil {
+		return fmt.Errorf("launch insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"periodic_launch", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
 // DeletePeriodicLaunch is used to delete the periodic launch
 func (s *StateStore) DeletePeriodicLaunch(index uint64, namespace, jobID string) error {
 	txn := s.db.Txn(true)
@@ -1764,9 +1963,13 @@ func (s *StateStore) upsertAllocsImpl(index uint64, allocs []*structs.Allocation
 			// Keep the clients task states
 			alloc.TaskStates = exist.TaskStates
 
-			// If the scheduler is marking this allocation as lost we do not
-			// want to reuse the status of the existing allocation.
-			if alloc.ClientStatus != structs.AllocClientStatusLost {
+			// If the scheduler is marking this allocation as lost or as
+			// unknown because its node disconnected we do not want to reuse
+			// the status of the existing allocation. Stopped allocations keep
+			// the status the client last reported.
+			markUnknown := alloc.ClientStatus == structs.AllocClientStatusUnknown &&
+				alloc.DesiredStatus == structs.AllocDesiredStatusRun
+			if alloc.ClientStatus != structs.AllocClientStatusLost && !markUnknown {
 				alloc.ClientStatus = exist.ClientStatus
 				alloc.ClientDescription = exist.ClientDescription
 			}
@@ -2164,6 +2367,93 @@ func (s *StateStore) VaultAccessorsByNode(ws memdb.WatchSet, nodeID string) ([]*
 	return out, nil
 }
 
+// UpsertConsulAccessors is used to register a set of Consul Accessors
+func (s *StateStore) UpsertConsulAccessors(index uint64, accessors []*structs.ConsulAccessor) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, accessor := range accessors {
+		// Set the create index
+		accessor.CreateIndex = index
+
+		// Insert the accessor
+		if err := txn.Insert("consul_accessors", accessor); err != nil {
+			return fmt.Errorf("accessor insert failed: %v", err)
+		}
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"consul_accessors", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// DeleteConsulAccessors is used to delete a set of Consul Accessors
+func (s *StateStore) DeleteConsulAccessors(index uint64, accessors []*structs.ConsulAccessor) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, accessor := range accessors {
+		// Delete the accessor if it wasn't purged already
+		existing, err := txn.First("consul_accessors", "id", accessor.Accessor)
+		if err != nil {
+			return fmt.Errorf("accessor lookup failed: %v", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := txn.Delete("consul_accessors", existing); err != nil {
+			return fmt.Errorf("accessor delete failed: %v", err)
+		}
+	}
+
+	if err := txn.Insert("index", &IndexEntry{"consul_accessors", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// ConsulAccessors returns an iterator of Consul accessors.
+func (s *StateStore) ConsulAccessors(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("consul_accessors", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// ConsulAccessorsByAlloc returns all the Consul accessors by alloc id
+func (s *StateStore) ConsulAccessorsByAlloc(ws memdb.WatchSet, allocID string) ([]*structs.ConsulAccessor, error) {
+	txn := s.db.Txn(false)
+
+	// Get an iterator over the accessors
+	iter, err := txn.Get("consul_accessors", "alloc_id", allocID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.Add(iter.WatchCh())
+
+	var out []*structs.ConsulAccessor
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		out = append(out, raw.(*structs.ConsulAccessor))
+	}
+	return out, nil
+}
+
 // UpdateDeploymentStatus is used to make deployment status updates and
 // potentially make a evaluation
 func (s *StateStore) UpdateDeploymentStatus(index uint64, req *structs.DeploymentStatusUpdateRequest) error {
@@ -2613,6 +2903,8 @@ func (s *StateStore) ReconcileJobSummaries(index uint64) error {
 				tg.Running += 1
 			case structs.AllocClientStatusPending:
 				tg.Starting += 1
+			case structs.AllocClientStatusUnknown:
+				tg.Unknown += 1
 			default:
 				s.logger.Printf("[ERR] state_store: invalid client status: %v in allocation %q", alloc.ClientStatus, alloc.ID)
 			}
@@ -2747,6 +3039,8 @@ func (s *StateStore) setJobStatus(index uint64, txn *memdb.Txn,
 					children.Running--
 				case structs.JobStatusDead:
 					children.Dead--
+				case structs.JobStatusQueued:
+					children.Queued--
 				default:
 					return fmt.Errorf("unknown old job status %q", oldStatus)
 				}
@@ -2760,6 +3054,8 @@ func (s *StateStore) setJobStatus(index uint64, txn *memdb.Txn,
 				children.Running++
 			case structs.JobStatusDead:
 				children.Dead++
+			case structs.JobStatusQueued:
+				children.Queued++
 			default:
 				return fmt.Errorf("unknown new job status %q", newStatus)
 			}
@@ -2806,6 +3102,7 @@ func (s *StateStore) getJobStatus(txn *memdb.Txn, job *structs.Job, evalDelete b
 	}
 
 	hasEval := false
+	queued := false
 	for raw := evals.Next(); raw != nil; raw = evals.Next() {
 		e := raw.(*structs.Evaluation)
 
@@ -2815,11 +3112,20 @@ func (s *StateStore) getJobStatus(txn *memdb.Txn, job *structs.Job, evalDelete b
 		}
 
 		hasEval = true
+		if e.Status == structs.EvalStatusQueued {
+			queued = true
+			continue
+		}
 		if !e.TerminalStatus() {
 			return structs.JobStatusPending, nil
 		}
 	}
 
+	// A dispatched job waiting for the running limit of its parent is queued
+	if queued {
+		return structs.JobStatusQueued, nil
+	}
+
 	// system jobs are running until explicitly stopped (which is handled elsewhere)
 	if job.Type == structs.JobTypeSystem {
 		if job.Stop {
@@ -3075,6 +3381,8 @@ func (s *StateStore) updateSummaryWithAlloc(index uint64, alloc *structs.Allocat
 			tgSummary.Complete += 1
 		case structs.AllocClientStatusLost:
 			tgSummary.Lost += 1
+		case structs.AllocClientStatusUnknown:
+			tgSummary.Unknown += 1
 		}
 
 		// Decrementing the count of the bin of the last state
@@ -3085,6 +3393,8 @@ func (s *StateStore) updateSummaryWithAlloc(index uint64, alloc *structs.Allocat
 			tgSummary.Starting -= 1
 		case structs.AllocClientStatusLost:
 			tgSummary.Lost -= 1
+		case structs.AllocClientStatusUnknown:
+			tgSummary.Unknown -= 1
 		case structs.AllocClientStatusFailed, structs.AllocClientStatusComplete:
 		default:
 			s.logger.Printf("[ERR] state_store: invalid old state of allocation with id: %v, and state: %v",
@@ -3115,6 +3425,14 @@ func (s *StateStore) updateSummaryWithAlloc(index uint64, alloc *structs.Allocat
 	return nil
 }
 
+// SchedulerConfigRestore is used to restore the scheduler configuration
+func (r *StateRestore) SchedulerConfigRestore(config *structs.SchedulerConfiguration) error {
+	if err := r.txn.Insert("scheduler_config", config); err != nil {
+		return fmt.Errorf("inserting scheduler config failed: %v", err)
+	}
+	return nil
+}
+
 // addEphemeralDiskToTaskGroups adds missing EphemeralDisk objects to TaskGroups
 func (s *StateStore) addEphemeralDiskToTaskGroups(job *structs.Job) {
 	for _, tg := range job.TaskGroups {
@@ -3428,6 +3746,264 @@ func (s *StateStore) BootstrapACLTokens(index, resetIndex uint64, token *structs
 	return nil
 }
 
+// SchedulerConfig returns the scheduler configuration and the index it was
+// last modified at. The configuration is nil if it has never been set.
+func (s *StateStore) SchedulerConfig() (uint64, *structs.SchedulerConfiguration, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("scheduler_config", "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("scheduler config lookup failed: %v", err)
+	}
+	if existing == nil {
+		return 0, nil, nil
+	}
+
+	config := existing.(*structs.SchedulerConfiguration)
+	return config.ModifyIndex, config, nil
+}
+
+// SchedulerSetConfig is used to set the scheduler configuration
+func (s *StateStore) SchedulerSetConfig(index uint64, config *structs.SchedulerConfiguration) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	if err := s.schedulerSetConfigTxn(index, txn, config); err != nil {
+		return err
+	}
+
+	txn.Commit()
+	return nil
+}
+
+// SchedulerCASConfig is used to update the scheduler configuration with a
+// given Raft index. If the CAS index specified is not equal to the last
+// observed index for the config, then the call is a noop and false is
+// returned. A CAS index of 0 only succeeds if the configuration has never been
+// set.
+func (s *StateStore) SchedulerCASConfig(index, cidx uint64, config *structs.SchedulerConfiguration) (bool, error) {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	// Check for an existing config
+	existing, err := txn.First("scheduler_config", "id")
+	if err != nil {
+		return false, fmt.Errorf("scheduler config lookup failed: %v", err)
+	}
+
+	// If the existing index does not match the provided CAS
+	// index arg, then we shouldn't update anything and can safely
+	// return early here.
+	if existing == nil {
+		if cidx != 0 {
+			return false, nil
+		}
+	} else if existing.(*structs.SchedulerConfiguration).ModifyIndex != cidx {
+		return false, nil
+	}
+
+	if err := s.schedulerSetConfigTxn(index, txn, config); err != nil {
+		return false, err
+	}
+
+	txn.Commit()
+	return true, nil
+}
+
+// schedulerSetConfigTxn sets the scheduler configuration within a transaction
+func (s *StateStore) schedulerSetConfigTxn(index uint64, txn *memdb.Txn, config *structs.SchedulerConfiguration) error {
+	// Retain the create index of an existing configuration
+	existing, err := txn.First("scheduler_config", "id")
+	if err != nil {
+		return fmt.Errorf("scheduler config lookup failed: %v", err)
+	}
+	if existing != nil {
+		config.CreateIndex = existing.(*structs.SchedulerConfiguration).CreateIndex
+	} else {
+		config.CreateIndex = index
+	}
+	config.ModifyIndex = index
+
+	if err := txn.Insert("scheduler_config", config); err != nil {
+		return fmt.Errorf("scheduler config insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"scheduler_config", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// upsertBuiltInNodePools creates the built-in node pools. They are created
+// at index 1 so they exist before any node or job is registered.
+func (s *StateStore) upsertBuiltInNodePools() error {
+	pools := []*structs.NodePool{
+		{
+			Name:        structs.NodePoolAll,
+			Description: "Node pool with all nodes in the cluster.",
+		},
+		{
+			Name:        structs.NodePoolDefault,
+			Description: "Default node pool.",
+		},
+	}
+	return s.UpsertNodePools(1, pools)
+}
+
+// ensureNodePoolExists creates the named node pool if it doesn't exist.
+func (s *StateStore) ensureNodePoolExists(txn *memdb.Txn, index uint64, name string) error {
+	existing, err := txn.First("node_pools", "id", name)
+	if err != nil {
+		return fmt.Errorf("node pool lookup failed: %v", err)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	pool := &structs.NodePool{
+		Name:        name,
+		CreateIndex: index,
+		ModifyIndex: index,
+	}
+	if err := txn.Insert("node_pools", pool); err != nil {
+		return fmt.Errorf("node pool insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"node_pools", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// UpsertNodePools is used to create or update a set of node pools
+func (s *StateStore) UpsertNodePools(index uint64, pools []*structs.NodePool) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, pool := range pools {
+		// Check if the pool already exists
+		existing, err := txn.First("node_pools", "id", pool.Name)
+		if err != nil {
+			return fmt.Errorf("node pool lookup failed: %v", err)
+		}
+
+		// Update all the indexes
+		if existing != nil {
+			pool.CreateIndex = existing.(*structs.NodePool).CreateIndex
+			pool.ModifyIndex = index
+		} else {
+			pool.CreateIndex = index
+			pool.ModifyIndex = index
+		}
+
+		// Update the pool
+		if err := txn.Insert("node_pools", pool); err != nil {
+			return fmt.Errorf("upserting node pool failed: %v", err)
+		}
+	}
+
+	// Update the indexes table
+	if err := txn.Insert("index", &IndexEntry{"node_pools", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
+
+// DeleteNodePools deletes the node pools with the given names. Built-in node
+// pools and node pools that still have nodes or jobs can't be deleted.
+func (s *StateStore) DeleteNodePools(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	for _, name := range names {
+		existing, err := txn.First("node_pools", "id", name)
+		if err != nil {
+			return fmt.Errorf("node pool lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("node pool %q not found", name)
+		}
+		if existing.(*structs.NodePool).IsBuiltIn() {
+			return fmt.Errorf("node pool %q is built-in and can't be deleted", name)
+		}
+
+		// Ensure the pool is unused
+		if node, err := txn.First("nodes", "node_pool", name); err != nil {
+			return fmt.Errorf("node lookup failed: %v", err)
+		} else if node != nil {
+			return fmt.Errorf("node pool %q has nodes", name)
+		}
+		if job, err := txn.First("jobs", "node_pool", name); err != nil {
+			return fmt.Errorf("job lookup failed: %v", err)
+		} else if job != nil {
+			return fmt.Errorf("node pool %q has jobs", name)
+		}
+
+		if err := txn.Delete("node_pools", existing); err != nil {
+			return fmt.Errorf("deleting node pool failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"node_pools", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	txn.Commit()
+	return nil
+}
+
+// NodePoolByName is used to lookup a node pool by name
+func (s *StateStore) NodePoolByName(ws memdb.WatchSet, name string) (*structs.NodePool, error) {
+	txn := s.db.Txn(false)
+
+	watchCh, existing, err := txn.FirstWatch("node_pools", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("node pool lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.NodePool), nil
+	}
+	return nil, nil
+}
+
+// NodePoolsByNamePrefix is used to lookup node pools by prefix
+func (s *StateStore) NodePoolsByNamePrefix(ws memdb.WatchSet, prefix string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("node_pools", "id_prefix", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("node pool lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// NodePools returns an iterator over all the node pools
+func (s *StateStore) NodePools(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("node_pools", "id")
+	if err != nil {
+		return nil, err
+	}
+	ws.Add(iter.WatchCh())
+	return iter, nil
+}
+
+// NodesByNodePool returns an iterator over all the nodes in a node pool
+func (s *StateStore) NodesByNodePool(ws memdb.WatchSet, pool string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("nodes", "node_pool", pool)
+	if err != nil {
+		return nil, fmt.Errorf("node lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
 // StateSnapshot is used to provide a point-in-time snapshot
 type StateSnapshot struct {
 	StateStore
@@ -3452,6 +4028,11 @@ func (s *StateRestore) Commit() {
 
 // NodeRestore is used to restore a node
 func (r *StateRestore) NodeRestore(node *structs.Node) error {
+	// COMPAT: Nodes registered before node pools are in the default pool
+	if node.NodePool == "" {
+		node.NodePool = structs.NodePoolDefault
+	}
+
 	if err := r.txn.Insert("nodes", node); err != nil {
 		return fmt.Errorf("node insert failed: %v", err)
 	}
@@ -3464,6 +4045,11 @@ func (r *StateRestore) JobRestore(job *structs.Job) error {
 	// COMPAT 0.4.1 -> 0.5
 	r.addEphemeralDiskToTaskGroups(job)
 
+	// COMPAT: Jobs registered before node pools are in the default pool
+	if job.NodePool == "" {
+		job.NodePool = structs.NodePoolDefault
+	}
+
 	if err := r.txn.Insert("jobs", job); err != nil {
 		return fmt.Errorf("job insert failed: %v", err)
 	}
@@ -3531,6 +4117,14 @@ func (r *StateRestore) JobVersionRestore(version *structs.Job) error {
 	return nil
 }
 
+// JobSubmissionRestore is used to restore a job submission
+func (r *StateRestore) JobSubmissionRestore(sub *structs.JobSubmission) error {
+	if err := r.txn.Insert("job_submission", sub); err != nil {
+		return fmt.Errorf("job submission insert failed: %v", err)
+	}
+	return nil
+}
+
 // DeploymentRestore is used to restore a deployment
 func (r *StateRestore) DeploymentRestore(deployment *structs.Deployment) error {
 	if err := r.txn.Insert("deployment", deployment); err != nil {
@@ -3547,6 +4141,14 @@ func (r *StateRestore) VaultAccessorRestore(accessor *structs.VaultAccessor) err
 	return nil
 }
 
+// ConsulAccessorRestore is used to restore a Consul accessor
+func (r *StateRestore) ConsulAccessorRestore(accessor *structs.ConsulAccessor) error {
+	if err := r.txn.Insert("consul_accessors", accessor); err != nil {
+		return fmt.Errorf("consul accessor insert failed: %v", err)
+	}
+	return nil
+}
+
 // ACLPolicyRestore is used to restore an ACL policy
 func (r *StateRestore) ACLPolicyRestore(policy *structs.ACLPolicy) error {
 	if err := r.txn.Insert("acl_policy", policy); err != nil {
@@ -3555,6 +4157,14 @@ func (r *StateRestore) ACLPolicyRestore(policy *structs.ACLPolicy) error {
 	return nil
 }
 
+// NodePoolRestore is used to restore a node pool
+func (r *StateRestore) NodePoolRestore(pool *structs.NodePool) error {
+	if err := r.txn.Insert("node_pools", pool); err != nil {
+		return fmt.Errorf("inserting node pool failed: %v", err)
+	}
+	return nil
+}
+
 // ACLTokenRestore is used to restore an ACL token
 func (r *StateRestore) ACLTokenRestore(token *structs.ACLToken) error {
 	if err := r.txn.Insert("acl_token", token); err != nil {
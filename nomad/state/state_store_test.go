@@ -652,7 +652,7 @@ func TestStateStore_UpdateNodeStatus_Node(t *testing.T) {
 		t.Fatalf("bad: %v", err)
 	}
 
-	err = state.UpdateNodeStatus(801, node.ID, structs.NodeStatusReady)
+	err = state.UpdateNodeStatus(801, node.ID, structs.NodeStatusReady, 70)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -673,6 +673,9 @@ func TestStateStore_UpdateNodeStatus_Node(t *testing.T) {
 	if out.ModifyIndex != 801 {
 		t.Fatalf("bad: %#v", out)
 	}
+	if out.StatusUpdatedAt != 70 {
+		t.Fatalf("bad: %#v", out)
+	}
 
 	index, err := state.Index("nodes")
 	if err != nil {
@@ -1443,6 +1446,75 @@ func TestStateStore_DeleteJob_MultipleVersions(t *testing.T) {
 	assert.False(watchFired(ws))
 }
 
+func TestStateStore_JobSubmission(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	job := mock.Job()
+	sub := &structs.JobSubmission{
+		Source:        `job "example" {}`,
+		Format:        structs.JobSubmissionFormatHCL2,
+		VariableFlags: map[string]string{"image": "redis"},
+	}
+
+	// Create a watchset so we can test that upsert fires the watch
+	ws := memdb.NewWatchSet()
+	_, err := state.JobSubmission(ws, job.Namespace, job.ID, 0)
+	assert.Nil(err)
+	assert.Nil(state.UpsertJobWithSubmission(1000, job, sub))
+	assert.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err := state.JobSubmission(ws, job.Namespace, job.ID, 0)
+	assert.Nil(err)
+	assert.NotNil(out)
+	assert.Equal(sub.Source, out.Source)
+	assert.Equal(sub.VariableFlags, out.VariableFlags)
+	assert.Equal(job.ID, out.JobID)
+	assert.EqualValues(0, out.Version)
+	assert.EqualValues(1000, out.CreateIndex)
+
+	index, err := state.Index("job_submission")
+	assert.Nil(err)
+	assert.EqualValues(1000, index)
+
+	// Registering a version without a source stores none for it
+	job2 := job.Copy()
+	job2.Priority = 1
+	assert.Nil(state.UpsertJob(1001, job2))
+	out, err = state.JobSubmission(ws, job.Namespace, job.ID, 1)
+	assert.Nil(err)
+	assert.Nil(out)
+
+	// Trimming the first version deletes its source
+	for i := 2; i <= structs.JobTrackedVersions; i++ {
+		next := job.Copy()
+		next.Priority = i
+		assert.Nil(state.UpsertJobWithSubmission(uint64(1000+i), next, sub))
+	}
+	assert.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err = state.JobSubmission(ws, job.Namespace, job.ID, 0)
+	assert.Nil(err)
+	assert.Nil(out)
+	out, err = state.JobSubmission(ws, job.Namespace, job.ID, structs.JobTrackedVersions)
+	assert.Nil(err)
+	assert.NotNil(out)
+
+	// Deleting the job deletes the sources of its versions
+	assert.Nil(state.DeleteJob(2000, job.Namespace, job.ID))
+	assert.True(watchFired(ws))
+
+	iter, err := state.JobSubmissions(nil)
+	assert.Nil(err)
+	assert.Nil(iter.Next())
+
+	index, err = state.Index("job_submission")
+	assert.Nil(err)
+	assert.EqualValues(2000, index)
+}
+
 func TestStateStore_DeleteJob_ChildJob(t *testing.T) {
 	state := testStateStore(t)
 
@@ -1798,6 +1870,38 @@ func TestStateStore_JobsByScheduler(t *testing.T) {
 	}
 }
 
+func TestStateStore_JobsByIdempotencyToken(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	parent := mock.Job()
+	child := mock.Job()
+	child.ParentID = parent.ID
+	child.DispatchIdempotencyToken = "foo"
+	other := mock.Job()
+	other.ParentID = parent.ID
+	other.DispatchIdempotencyToken = "bar"
+	noToken := mock.Job()
+	noToken.ParentID = parent.ID
+
+	for i, job := range []*structs.Job{parent, child, other, noToken} {
+		assert.Nil(state.UpsertJob(1000+uint64(i), job))
+	}
+
+	iter, err := state.JobsByIdempotencyToken(nil, parent.Namespace, parent.ID, "foo")
+	assert.Nil(err)
+	var out []*structs.Job
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		out = append(out, raw.(*structs.Job))
+	}
+	assert.Len(out, 1)
+	assert.Equal(child.ID, out[0].ID)
+
+	iter, err = state.JobsByIdempotencyToken(nil, parent.Namespace, "other", "foo")
+	assert.Nil(err)
+	assert.Nil(iter.Next())
+}
+
 func TestStateStore_JobsByGC(t *testing.T) {
 	state := testStateStore(t)
 	gc, nonGc := make(map[string]struct{}), make(map[string]struct{})
@@ -1819,6 +1923,9 @@ func TestStateStore_JobsByGC(t *testing.T) {
 	for i := 0; i < 20; i += 2 {
 		job := mock.Job()
 		job.Type = structs.JobTypeBatch
+		if i%4 == 0 {
+			job.Type = structs.JobTypeSysBatch
+		}
 		gc[job.ID] = struct{}{}
 
 		if err := state.UpsertJob(2000+uint64(i), job); err != nil {
@@ -2051,6 +2158,73 @@ func TestStateStore_UpdateUpsertPeriodicLaunch(t *testing.T) {
 	}
 }
 
+func TestStateStore_UpdatePeriodicLaunchPaused(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.Job()
+	launch := &structs.PeriodicLaunch{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+		Launch:    time.Now(),
+	}
+
+	// Pausing an unknown launch fails
+	if err := state.UpdatePeriodicLaunchPaused(999, job.Namespace, job.ID, true); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	err := state.UpsertPeriodicLaunch(1000, launch)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create a watchset so we can test that the update fires the watch
+	ws := memdb.NewWatchSet()
+	if _, err := state.PeriodicLaunchByID(ws, job.Namespace, launch.ID); err != nil {
+		t.Fatalf("bad: %v", err)
+	}
+
+	if err := state.UpdatePeriodicLaunchPaused(1001, job.Namespace, job.ID, true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !watchFired(ws) {
+		t.Fatalf("bad")
+	}
+
+	// Recording a new launch keeps the launch paused
+	launch2 := &structs.PeriodicLaunch{
+		ID:        job.ID,
+		Namespace: job.Namespace,
+		Launch:    launch.Launch.Add(1 * time.Second),
+	}
+	if err := state.UpsertPeriodicLaunch(1002, launch2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ws = memdb.NewWatchSet()
+	out, err := state.PeriodicLaunchByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.Paused {
+		t.Fatalf("bad: %#v", out)
+	}
+	if !out.Launch.Equal(launch2.Launch) {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1002 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	index, err := state.Index("periodic_launch")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1002 {
+		t.Fatalf("bad: %d", index)
+	}
+}
+
 func TestStateStore_DeletePeriodicLaunch(t *testing.T) {
 	state := testStateStore(t)
 	job := mock.Job()
@@ -2317,6 +2491,7 @@ func TestStateStore_Indexes(t *testing.T) {
 	}
 
 	expect := []*IndexEntry{
+		{"node_pools", 1},
 		{"nodes", 1000},
 	}
 
@@ -4659,6 +4834,69 @@ func TestStateStore_SetJobStatus_PendingEval(t *testing.T) {
 	}
 }
 
+func TestStateStore_SetJobStatus_QueuedEval(t *testing.T) {
+	state := testStateStore(t)
+	parent := mock.Job()
+	parent.ParameterizedJob = &structs.ParameterizedJobConfig{MaxRunning: 1}
+	if err := state.UpsertJob(999, parent); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	child := mock.Job()
+	child.ParentID = parent.ID
+	if err := state.UpsertJob(1000, child); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create a mock eval that waits for the running limit of the parent.
+	eval := mock.Eval()
+	eval.JobID = child.ID
+	eval.Status = structs.EvalStatusQueued
+	if err := state.UpsertEvals(1001, []*structs.Evaluation{eval}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, child.Namespace, child.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Status != structs.JobStatusQueued {
+		t.Fatalf("bad status: %v; expected %v", out.Status, structs.JobStatusQueued)
+	}
+
+	summary, err := state.JobSummaryByID(ws, parent.Namespace, parent.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if summary.Children.Queued != 1 || summary.Children.Pending != 0 {
+		t.Fatalf("bad children summary: %v", summary.Children)
+	}
+
+	// Release the eval
+	released := eval.Copy()
+	released.Status = structs.EvalStatusPending
+	if err := state.UpsertEvals(1002, []*structs.Evaluation{released}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err = state.JobByID(ws, child.Namespace, child.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Status != structs.JobStatusPending {
+		t.Fatalf("bad status: %v; expected %v", out.Status, structs.JobStatusPending)
+	}
+
+	summary, err = state.JobSummaryByID(ws, parent.Namespace, parent.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if summary.Children.Queued != 0 || summary.Children.Pending != 1 {
+		t.Fatalf("bad children summary: %v", summary.Children)
+	}
+}
+
 // TestStateStore_SetJobStatus_SystemJob asserts that system jobs are still
 // considered running until explicitly stopped.
 func TestStateStore_SetJobStatus_SystemJob(t *testing.T) {
@@ -4696,6 +4934,76 @@ func TestStateStore_SetJobStatus_SystemJob(t *testing.T) {
 	}
 }
 
+// TestStateStore_SetJobStatus_SysBatchJob asserts that sysbatch jobs are dead
+// once they completed on every node and that the summary counts the nodes
+// they completed on.
+func TestStateStore_SetJobStatus_SysBatchJob(t *testing.T) {
+	state := testStateStore(t)
+	job := mock.SysBatchJob()
+	if err := state.UpsertJob(1000, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create a complete eval
+	eval := mock.Eval()
+	eval.JobID = job.ID
+	eval.Type = job.Type
+	eval.Status = structs.EvalStatusComplete
+	if err := state.UpsertEvals(1001, []*structs.Evaluation{eval}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create an allocation on each of three nodes
+	var allocs []*structs.Allocation
+	for i := 0; i < 3; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		allocs = append(allocs, alloc)
+	}
+	if err := state.UpsertAllocs(1002, allocs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ws := memdb.NewWatchSet()
+	out, err := state.JobByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if expected := structs.JobStatusRunning; out.Status != expected {
+		t.Fatalf("job status %v; expected %v", out.Status, expected)
+	}
+
+	// Complete the allocations
+	var updates []*structs.Allocation
+	for _, alloc := range allocs {
+		update := alloc.Copy()
+		update.ClientStatus = structs.AllocClientStatusComplete
+		updates = append(updates, update)
+	}
+	if err := state.UpdateAllocsFromClient(1003, updates); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err = state.JobByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if expected := structs.JobStatusDead; out.Status != expected {
+		t.Fatalf("job status %v; expected %v", out.Status, expected)
+	}
+
+	summary, err := state.JobSummaryByID(ws, job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	tgSummary := summary.Summary[job.TaskGroups[0].Name]
+	if tgSummary.Complete != 3 || tgSummary.Starting != 0 {
+		t.Fatalf("bad summary: %#v", tgSummary)
+	}
+}
+
 func TestStateJobSummary_UpdateJobCount(t *testing.T) {
 	state := testStateStore(t)
 	alloc := mock.Alloc()
@@ -5699,6 +6007,47 @@ func TestStateStore_UpsertVaultAccessors(t *testing.T) {
 	}
 }
 
+func TestStateStore_ConsulAccessors(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	a1 := mock.ConsulAccessor()
+	a2 := mock.ConsulAccessor()
+	a2.AllocID = a1.AllocID
+	a3 := mock.ConsulAccessor()
+
+	ws := memdb.NewWatchSet()
+	_, err := state.ConsulAccessorsByAlloc(ws, a1.AllocID)
+	assert.Nil(err)
+
+	assert.Nil(state.UpsertConsulAccessors(1000, []*structs.ConsulAccessor{a1, a2, a3}))
+	assert.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err := state.ConsulAccessorsByAlloc(ws, a1.AllocID)
+	assert.Nil(err)
+	assert.Len(out, 2)
+	for _, ca := range out {
+		assert.EqualValues(1000, ca.CreateIndex)
+	}
+
+	// Deleting accessors, including already deleted ones, only removes them
+	assert.Nil(state.DeleteConsulAccessors(1001, []*structs.ConsulAccessor{a1, a2}))
+	assert.Nil(state.DeleteConsulAccessors(1002, []*structs.ConsulAccessor{a1}))
+	assert.True(watchFired(ws))
+
+	out, err = state.ConsulAccessorsByAlloc(nil, a1.AllocID)
+	assert.Nil(err)
+	assert.Empty(out)
+	out, err = state.ConsulAccessorsByAlloc(nil, a3.AllocID)
+	assert.Nil(err)
+	assert.Len(out, 1)
+
+	index, err := state.Index("consul_accessors")
+	assert.Nil(err)
+	assert.EqualValues(1002, index)
+}
+
 func TestStateStore_DeleteVaultAccessors(t *testing.T) {
 	state := testStateStore(t)
 	a1 := mock.VaultAccessor()
@@ -6393,6 +6742,304 @@ func TestStateStore_RestoreACLToken(t *testing.T) {
 	assert.Equal(t, token, out)
 }
 
+func TestStateStore_SchedulerConfig(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	// No configuration has been set
+	index, config, err := state.SchedulerConfig()
+	assert.Nil(err)
+	assert.Zero(index)
+	assert.Nil(config)
+
+	// Set the configuration
+	err = state.SchedulerSetConfig(1000, &structs.SchedulerConfiguration{
+		MemoryOversubscriptionEnabled: true,
+	})
+	assert.Nil(err)
+
+	index, config, err = state.SchedulerConfig()
+	assert.Nil(err)
+	assert.EqualValues(1000, index)
+	if assert.NotNil(config) {
+		assert.True(config.MemoryOversubscriptionEnabled)
+		assert.EqualValues(1000, config.CreateIndex)
+		assert.EqualValues(1000, config.ModifyIndex)
+	}
+
+	// Update the configuration and ensure the create index is kept
+	err = state.SchedulerSetConfig(1001, &structs.SchedulerConfiguration{})
+	assert.Nil(err)
+
+	index, config, err = state.SchedulerConfig()
+	assert.Nil(err)
+	assert.EqualValues(1001, index)
+	if assert.NotNil(config) {
+		assert.False(config.MemoryOversubscriptionEnabled)
+		assert.EqualValues(1000, config.CreateIndex)
+		assert.EqualValues(1001, config.ModifyIndex)
+	}
+
+	tableIndex, err := state.Index("scheduler_config")
+	assert.Nil(err)
+	assert.EqualValues(1001, tableIndex)
+}
+
+func TestStateStore_SchedulerCASConfig(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	// A non-zero CAS index fails when the configuration was never set
+	ok, err := state.SchedulerCASConfig(1000, 1, &structs.SchedulerConfiguration{})
+	assert.Nil(err)
+	assert.False(ok)
+
+	// A zero CAS index succeeds when the configuration was never set
+	ok, err = state.SchedulerCASConfig(1001, 0, &structs.SchedulerConfiguration{
+		SchedulerAlgorithm: structs.SchedulerAlgorithmSpread,
+	})
+	assert.Nil(err)
+	assert.True(ok)
+
+	// A stale CAS index fails
+	ok, err = state.SchedulerCASConfig(1002, 0, &structs.SchedulerConfiguration{})
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, config, err := state.SchedulerConfig()
+	assert.Nil(err)
+	assert.Equal(structs.SchedulerAlgorithmSpread, config.SchedulerAlgorithm)
+
+	// The current CAS index succeeds
+	ok, err = state.SchedulerCASConfig(1003, 1001, &structs.SchedulerConfiguration{})
+	assert.Nil(err)
+	assert.True(ok)
+
+	index, config, err := state.SchedulerConfig()
+	assert.Nil(err)
+	assert.EqualValues(1003, index)
+	assert.Equal(structs.SchedulerAlgorithm(""), config.SchedulerAlgorithm)
+	assert.EqualValues(1001, config.CreateIndex)
+}
+
+func TestStateStore_RestoreSchedulerConfig(t *testing.T) {
+	state := testStateStore(t)
+	config := &structs.SchedulerConfiguration{
+		MemoryOversubscriptionEnabled: true,
+		CreateIndex:                   100,
+		ModifyIndex:                   200,
+	}
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	err = restore.SchedulerConfigRestore(config)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	index, out, err := state.SchedulerConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.EqualValues(t, 200, index)
+	assert.Equal(t, config, out)
+}
+
+func TestStateStore_BuiltInNodePools(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	ws := memdb.NewWatchSet()
+	for _, name := range []string{structs.NodePoolAll, structs.NodePoolDefault} {
+		pool, err := state.NodePoolByName(ws, name)
+		assert.Nil(err)
+		if assert.NotNil(pool, name) {
+			assert.True(pool.IsBuiltIn())
+			assert.EqualValues(1, pool.CreateIndex)
+		}
+	}
+
+	// Built-in pools can't be deleted
+	err := state.DeleteNodePools(1000, []string{structs.NodePoolDefault})
+	assert.NotNil(err)
+}
+
+func TestStateStore_UpsertNodePools(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+	pool := mock.NodePool()
+	pool2 := mock.NodePool()
+
+	ws := memdb.NewWatchSet()
+	out, err := state.NodePoolByName(ws, pool.Name)
+	assert.Nil(err)
+	assert.Nil(out)
+
+	err = state.UpsertNodePools(1000, []*structs.NodePool{pool, pool2})
+	assert.Nil(err)
+	assert.True(watchFired(ws))
+
+	ws = memdb.NewWatchSet()
+	out, err = state.NodePoolByName(ws, pool.Name)
+	assert.Nil(err)
+	assert.Equal(pool, out)
+	assert.EqualValues(1000, out.CreateIndex)
+
+	// Update the pool and ensure the create index is kept
+	update := pool.Copy()
+	update.Description = "updated"
+	err = state.UpsertNodePools(1001, []*structs.NodePool{update})
+	assert.Nil(err)
+	assert.True(watchFired(ws))
+
+	out, err = state.NodePoolByName(nil, pool.Name)
+	assert.Nil(err)
+	assert.Equal("updated", out.Description)
+	assert.EqualValues(1000, out.CreateIndex)
+	assert.EqualValues(1001, out.ModifyIndex)
+
+	// Ensure we see both pools along with the built-in ones
+	iter, err := state.NodePools(nil)
+	assert.Nil(err)
+	count := 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	assert.Equal(4, count)
+
+	// Lookup by prefix
+	iter, err = state.NodePoolsByNamePrefix(nil, "pool-")
+	assert.Nil(err)
+	count = 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	assert.Equal(2, count)
+
+	index, err := state.Index("node_pools")
+	assert.Nil(err)
+	assert.EqualValues(1001, index)
+}
+
+func TestStateStore_DeleteNodePools(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+	pool := mock.NodePool()
+	assert.Nil(state.UpsertNodePools(1000, []*structs.NodePool{pool}))
+
+	// A pool with nodes can't be deleted
+	node := mock.Node()
+	node.NodePool = pool.Name
+	assert.Nil(state.UpsertNode(1001, node))
+	err := state.DeleteNodePools(1002, []string{pool.Name})
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "has nodes")
+	}
+	assert.Nil(state.DeleteNode(1003, node.ID))
+
+	// A pool with jobs can't be deleted
+	job := mock.Job()
+	job.NodePool = pool.Name
+	assert.Nil(state.UpsertJob(1004, job))
+	err = state.DeleteNodePools(1005, []string{pool.Name})
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "has jobs")
+	}
+	assert.Nil(state.DeleteJob(1006, job.Namespace, job.ID))
+
+	// Unknown pools can't be deleted
+	err = state.DeleteNodePools(1007, []string{pool.Name, "unknown"})
+	assert.NotNil(err)
+
+	// The pool is now unused
+	ws := memdb.NewWatchSet()
+	_, err = state.NodePoolByName(ws, pool.Name)
+	assert.Nil(err)
+
+	assert.Nil(state.DeleteNodePools(1008, []string{pool.Name}))
+	assert.True(watchFired(ws))
+
+	out, err := state.NodePoolByName(nil, pool.Name)
+	assert.Nil(err)
+	assert.Nil(out)
+
+	index, err := state.Index("node_pools")
+	assert.Nil(err)
+	assert.EqualValues(1008, index)
+}
+
+func TestStateStore_NodePools_UpsertNode(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	// Nodes without a pool join the default pool
+	node := mock.Node()
+	assert.Nil(state.UpsertNode(1000, node))
+	out, err := state.NodeByID(nil, node.ID)
+	assert.Nil(err)
+	assert.Equal(structs.NodePoolDefault, out.NodePool)
+
+	// Nodes in an unknown pool create it
+	node2 := mock.Node()
+	node2.NodePool = "gpu"
+	assert.Nil(state.UpsertNode(1001, node2))
+	pool, err := state.NodePoolByName(nil, "gpu")
+	assert.Nil(err)
+	if assert.NotNil(pool) {
+		assert.EqualValues(1001, pool.CreateIndex)
+	}
+
+	iter, err := state.NodesByNodePool(nil, "gpu")
+	assert.Nil(err)
+	raw := iter.Next()
+	if assert.NotNil(raw) {
+		assert.Equal(node2.ID, raw.(*structs.Node).ID)
+	}
+	assert.Nil(iter.Next())
+}
+
+func TestStateStore_NodePools_UpsertJob(t *testing.T) {
+	state := testStateStore(t)
+	assert := assert.New(t)
+
+	// Jobs can't be registered in an unknown pool
+	job := mock.Job()
+	job.NodePool = "unknown"
+	err := state.UpsertJob(1000, job)
+	assert.NotNil(err)
+
+	pool := mock.NodePool()
+	assert.Nil(state.UpsertNodePools(1001, []*structs.NodePool{pool}))
+	job.NodePool = pool.Name
+	assert.Nil(state.UpsertJob(1002, job))
+}
+
+func TestStateStore_RestoreNodePool(t *testing.T) {
+	state := testStateStore(t)
+	pool := mock.NodePool()
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	err = restore.NodePoolRestore(pool)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	out, err := state.NodePoolByName(nil, pool.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, pool, out)
+}
+
 func TestStateStore_Abandon(t *testing.T) {
 	s := testStateStore(t)
 	abandonCh := s.AbandonCh()
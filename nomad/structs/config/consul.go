@@ -72,24 +72,37 @@ type ConsulConfig struct {
 	// ClientAutoJoin enables Nomad servers to find addresses of Nomad servers
 	// and register with them
 	ClientAutoJoin *bool `mapstructure:"client_auto_join"`
+
+	// AllowUnauthenticated allows users to submit jobs registering Consul
+	// services without providing a Consul token proving they have access to
+	// them. When disabled, servers also derive a Consul token for each task
+	// to register its services and render its templates with.
+	AllowUnauthenticated *bool `mapstructure:"allow_unauthenticated"`
 }
 
 // DefaultConsulConfig() returns the canonical defaults for the Nomad
 // `consul` configuration.
 func DefaultConsulConfig() *ConsulConfig {
 	return &ConsulConfig{
-		ServerServiceName:  "nomad",
-		ClientServiceName:  "nomad-client",
-		AutoAdvertise:      helper.BoolToPtr(true),
-		ChecksUseAdvertise: helper.BoolToPtr(false),
-		EnableSSL:          helper.BoolToPtr(false),
-		VerifySSL:          helper.BoolToPtr(true),
-		ServerAutoJoin:     helper.BoolToPtr(true),
-		ClientAutoJoin:     helper.BoolToPtr(true),
-		Timeout:            5 * time.Second,
+		ServerServiceName:    "nomad",
+		ClientServiceName:    "nomad-client",
+		AutoAdvertise:        helper.BoolToPtr(true),
+		ChecksUseAdvertise:   helper.BoolToPtr(false),
+		EnableSSL:            helper.BoolToPtr(false),
+		VerifySSL:            helper.BoolToPtr(true),
+		ServerAutoJoin:       helper.BoolToPtr(true),
+		ClientAutoJoin:       helper.BoolToPtr(true),
+		AllowUnauthenticated: helper.BoolToPtr(true),
+		Timeout:              5 * time.Second,
 	}
 }
 
+// AllowsUnauthenticated returns whether the config allows unauthenticated
+// access to Consul services.
+func (a *ConsulConfig) AllowsUnauthenticated() bool {
+	return a.AllowUnauthenticated != nil && *a.AllowUnauthenticated
+}
+
 // Merge merges two Consul Configurations together.
 func (a *ConsulConfig) Merge(b *ConsulConfig) *ConsulConfig {
 	result := a.Copy()
@@ -139,6 +152,9 @@ func (a *ConsulConfig) Merge(b *ConsulConfig) *ConsulConfig {
 	if b.ChecksUseAdvertise != nil {
 		result.ChecksUseAdvertise = helper.BoolToPtr(*b.ChecksUseAdvertise)
 	}
+	if b.AllowUnauthenticated != nil {
+		result.AllowUnauthenticated = helper.BoolToPtr(*b.AllowUnauthenticated)
+	}
 	return result
 }
 
@@ -226,6 +242,9 @@ func (c *ConsulConfig) Copy() *ConsulConfig {
 	if nc.ClientAutoJoin != nil {
 		nc.ClientAutoJoin = helper.BoolToPtr(*nc.ClientAutoJoin)
 	}
+	if nc.AllowUnauthenticated != nil {
+		nc.AllowUnauthenticated = helper.BoolToPtr(*nc.AllowUnauthenticated)
+	}
 
 	return nc
 }
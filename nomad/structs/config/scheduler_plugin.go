@@ -0,0 +1,24 @@
+package config
+
+// SchedulerPluginConfig is used per configured scheduler plugin. The plugin
+// handles the evaluations of jobs of the given type.
+type SchedulerPluginConfig struct {
+	// JobType is the job type the plugin schedules
+	JobType string `hcl:",key"`
+
+	// Command is the path to the plugin binary
+	Command string `hcl:"command"`
+
+	// Args are the arguments the plugin is launched with
+	Args []string `hcl:"args"`
+}
+
+// Copy returns a copy of the scheduler plugin config
+func (c *SchedulerPluginConfig) Copy() *SchedulerPluginConfig {
+	if c == nil {
+		return nil
+	}
+	nc := *c
+	nc.Args = append([]string(nil), c.Args...)
+	return &nc
+}
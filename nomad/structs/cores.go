@@ -0,0 +1,203 @@
+package structs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoreIndex is used to index the CPU cores of a machine that can be reserved
+// and the cores that are reserved given allocations
+type CoreIndex struct {
+	AvailCores map[uint16]struct{} // Cores that can be reserved
+	UsedCores  map[uint16]struct{} // Cores that are reserved
+}
+
+// NewCoreIndex is used to construct a new core index
+func NewCoreIndex() *CoreIndex {
+	return &CoreIndex{
+		AvailCores: make(map[uint16]struct{}),
+		UsedCores:  make(map[uint16]struct{}),
+	}
+}
+
+// Overcommitted checks if cores that are not available are reserved
+func (idx *CoreIndex) Overcommitted() bool {
+	for core := range idx.UsedCores {
+		if _, ok := idx.AvailCores[core]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNode is used to setup the available cores. Returns true if there is a
+// collision
+func (idx *CoreIndex) SetNode(node *Node) (collide bool) {
+	if node.Resources != nil {
+		for _, core := range node.Resources.CoreIDs {
+			idx.AvailCores[core] = struct{}{}
+		}
+	}
+
+	// Add the cores reserved for the host
+	if r := node.Reserved; r != nil {
+		for _, core := range r.CoreIDs {
+			// Cores reserved for the host that the node does not have
+			// can't collide with tasks so don't track them
+			if _, ok := idx.AvailCores[core]; !ok {
+				continue
+			}
+			if idx.AddReserved(core) {
+				collide = true
+			}
+		}
+	}
+	return
+}
+
+// AddAllocs is used to add the cores reserved by allocations. Returns true
+// if there is a collision
+func (idx *CoreIndex) AddAllocs(allocs []*Allocation) (collide bool) {
+	for _, alloc := range allocs {
+		// Allocations within the plan may only have the combined resources
+		if alloc.TaskResources == nil {
+			if alloc.Resources != nil && idx.addCores(alloc.Resources.CoreIDs) {
+				collide = true
+			}
+			continue
+		}
+
+		for _, task := range alloc.TaskResources {
+			if idx.addCores(task.CoreIDs) {
+				collide = true
+			}
+		}
+	}
+	return
+}
+
+// addCores reserves each of the given cores. Returns true if there is a
+// collision
+func (idx *CoreIndex) addCores(cores []uint16) (collide bool) {
+	for _, core := range cores {
+		if idx.AddReserved(core) {
+			collide = true
+		}
+	}
+	return
+}
+
+// AddReserved is used to add a reserved core. Returns true if the core is
+// already reserved
+func (idx *CoreIndex) AddReserved(core uint16) (collide bool) {
+	if _, ok := idx.UsedCores[core]; ok {
+		return true
+	}
+	idx.UsedCores[core] = struct{}{}
+	return false
+}
+
+// AssignCores is used to reserve the given number of free cores. The lowest
+// free core IDs are chosen so the assignment is deterministic.
+func (idx *CoreIndex) AssignCores(count int) ([]uint16, error) {
+	var free []uint16
+	for core := range idx.AvailCores {
+		if _, ok := idx.UsedCores[core]; !ok {
+			free = append(free, core)
+		}
+	}
+
+	if len(free) < count {
+		return nil, fmt.Errorf("%d cores requested but %d available", count, len(free))
+	}
+
+	sort.Slice(free, func(i, j int) bool { return free[i] < free[j] })
+	cores := free[:count]
+	idx.addCores(cores)
+	return cores, nil
+}
+
+// ParseCpuset parses a list of CPU cores in the Linux cpuset format, comma
+// separated core IDs or ranges separated by hyphens. For example, "0-3,8".
+// The returned cores are sorted and deduplicated.
+func ParseCpuset(s string) ([]uint16, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	set := make(map[uint16]struct{})
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		rangeParts := strings.Split(part, "-")
+		switch len(rangeParts) {
+		case 1:
+			core, err := strconv.ParseUint(rangeParts[0], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid core %q", part)
+			}
+			set[uint16(core)] = struct{}{}
+		case 2:
+			start, err := strconv.ParseUint(rangeParts[0], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid core range %q", part)
+			}
+			end, err := strconv.ParseUint(rangeParts[1], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid core range %q", part)
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid core range %q: end less than start", part)
+			}
+			for i := start; i <= end; i++ {
+				set[uint16(i)] = struct{}{}
+			}
+		default:
+			return nil, fmt.Errorf("can only parse single cores or core ranges (ex. 0,2-4,7)")
+		}
+	}
+
+	cores := make([]uint16, 0, len(set))
+	for core := range set {
+		cores = append(cores, core)
+	}
+	sort.Slice(cores, func(i, j int) bool { return cores[i] < cores[j] })
+	return cores, nil
+}
+
+// CpusetString formats the cores in the Linux cpuset format, collapsing
+// consecutive cores into ranges.
+func CpusetString(cores []uint16) string {
+	if len(cores) == 0 {
+		return ""
+	}
+
+	sorted := make([]uint16, len(cores))
+	copy(sorted, cores)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var parts []string
+	start, prev := sorted[0], sorted[0]
+	flush := func() {
+		if start == prev {
+			parts = append(parts, strconv.Itoa(int(start)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, prev))
+		}
+	}
+	for _, core := range sorted[1:] {
+		if core == prev {
+			continue
+		}
+		if core == prev+1 {
+			prev = core
+			continue
+		}
+		flush()
+		start, prev = core, core
+	}
+	flush()
+	return strings.Join(parts, ",")
+}
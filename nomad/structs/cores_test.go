@@ -0,0 +1,173 @@
+package structs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoreIndex_SetNode(t *testing.T) {
+	idx := NewCoreIndex()
+	n := &Node{
+		Resources: &Resources{
+			CoreIDs: []uint16{0, 1, 2, 3},
+		},
+		Reserved: &Resources{
+			CoreIDs: []uint16{0, 8},
+		},
+	}
+	if collide := idx.SetNode(n); collide {
+		t.Fatalf("bad")
+	}
+
+	if len(idx.AvailCores) != 4 {
+		t.Fatalf("bad: %v", idx.AvailCores)
+	}
+
+	// Reserved cores the node doesn't have are ignored
+	if len(idx.UsedCores) != 1 {
+		t.Fatalf("bad: %v", idx.UsedCores)
+	}
+	if _, ok := idx.UsedCores[0]; !ok {
+		t.Fatalf("bad: %v", idx.UsedCores)
+	}
+}
+
+func TestCoreIndex_AddAllocs(t *testing.T) {
+	idx := NewCoreIndex()
+	allocs := []*Allocation{
+		{
+			TaskResources: map[string]*Resources{
+				"web": {CoreIDs: []uint16{1, 2}},
+				"db":  {CoreIDs: []uint16{3}},
+			},
+		},
+		{
+			// Plan allocations may only have the combined resources
+			Resources: &Resources{CoreIDs: []uint16{4}},
+		},
+	}
+	if collide := idx.AddAllocs(allocs); collide {
+		t.Fatalf("bad")
+	}
+	if len(idx.UsedCores) != 4 {
+		t.Fatalf("bad: %v", idx.UsedCores)
+	}
+
+	// Adding a reserved core again collides
+	if collide := idx.AddAllocs(allocs[1:]); !collide {
+		t.Fatalf("expected collision")
+	}
+}
+
+func TestCoreIndex_Overcommitted(t *testing.T) {
+	idx := NewCoreIndex()
+	idx.SetNode(&Node{Resources: &Resources{CoreIDs: []uint16{0, 1}}})
+
+	idx.AddReserved(1)
+	if idx.Overcommitted() {
+		t.Fatalf("bad")
+	}
+
+	idx.AddReserved(2)
+	if !idx.Overcommitted() {
+		t.Fatalf("expected overcommitted")
+	}
+}
+
+func TestCoreIndex_AssignCores(t *testing.T) {
+	idx := NewCoreIndex()
+	idx.SetNode(&Node{
+		Resources: &Resources{CoreIDs: []uint16{0, 1, 2, 3, 4}},
+		Reserved:  &Resources{CoreIDs: []uint16{0}},
+	})
+	idx.AddReserved(2)
+
+	cores, err := idx.AssignCores(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(cores, []uint16{1, 3}) {
+		t.Fatalf("bad: %v", cores)
+	}
+
+	// The assigned cores are reserved
+	cores, err = idx.AssignCores(1)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(cores, []uint16{4}) {
+		t.Fatalf("bad: %v", cores)
+	}
+
+	if _, err := idx.AssignCores(1); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestParseCpuset(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Parsed []uint16
+		Err    bool
+	}{
+		{
+			"",
+			nil,
+			false,
+		},
+		{
+			"0-3,8\n",
+			[]uint16{0, 1, 2, 3, 8},
+			false,
+		},
+		{
+			"3,1,2,1-2",
+			[]uint16{1, 2, 3},
+			false,
+		},
+		{
+			"3-1",
+			nil,
+			true,
+		},
+		{
+			"a",
+			nil,
+			true,
+		},
+		{
+			"1-2-3",
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		cores, err := ParseCpuset(tc.Input)
+		if (err != nil) != tc.Err {
+			t.Fatalf("test case %d: %v", i, err)
+		}
+		if !tc.Err && len(tc.Parsed) != 0 && !reflect.DeepEqual(cores, tc.Parsed) {
+			t.Fatalf("test case %d: got %v; want %v", i, cores, tc.Parsed)
+		}
+	}
+}
+
+func TestCpusetString(t *testing.T) {
+	cases := []struct {
+		Cores    []uint16
+		Expected string
+	}{
+		{nil, ""},
+		{[]uint16{3}, "3"},
+		{[]uint16{0, 1, 2, 3}, "0-3"},
+		{[]uint16{8, 0, 2, 1, 5, 6}, "0-2,5-6,8"},
+		{[]uint16{1, 1, 2}, "1-2"},
+	}
+
+	for _, tc := range cases {
+		if act := CpusetString(tc.Cores); act != tc.Expected {
+			t.Fatalf("%v: got %q; want %q", tc.Cores, act, tc.Expected)
+		}
+	}
+}
@@ -113,7 +113,7 @@ func (j *Job) Diff(other *Job, contextual bool) (*JobDiff, error) {
 	diff.TaskGroups = tgs
 
 	// Periodic diff
-	if pDiff := primitiveObjectDiff(j.Periodic, other.Periodic, nil, "Periodic", contextual); pDiff != nil {
+	if pDiff := periodicConfigDiff(j.Periodic, other.Periodic, contextual); pDiff != nil {
 		diff.Objects = append(diff.Objects, pDiff)
 	}
 
@@ -214,6 +214,15 @@ func (tg *TaskGroup) Diff(other *TaskGroup, contextual bool) (*TaskGroupDiff, er
 		newPrimitiveFlat = flatmap.Flatten(other, filter, true)
 	}
 
+	// The disconnect window is a pointer so it isn't flattened with the other
+	// primitive fields.
+	if d := tg.MaxClientDisconnect; d != nil && oldPrimitiveFlat != nil {
+		oldPrimitiveFlat["MaxClientDisconnect"] = fmt.Sprintf("%d", *d)
+	}
+	if d := other.MaxClientDisconnect; d != nil && newPrimitiveFlat != nil {
+		newPrimitiveFlat["MaxClientDisconnect"] = fmt.Sprintf("%d", *d)
+	}
+
 	// Diff the primitive fields.
 	diff.Fields = fieldDiffs(oldPrimitiveFlat, newPrimitiveFlat, false)
 
@@ -228,6 +237,17 @@ func (tg *TaskGroup) Diff(other *TaskGroup, contextual bool) (*TaskGroupDiff, er
 		diff.Objects = append(diff.Objects, conDiff...)
 	}
 
+	// Dependencies diff
+	depDiff := primitiveObjectSetDiff(
+		interfaceSlice(tg.DependsOn),
+		interfaceSlice(other.DependsOn),
+		nil,
+		"DependsOn",
+		contextual)
+	if depDiff != nil {
+		diff.Objects = append(diff.Objects, depDiff...)
+	}
+
 	// Restart policy diff
 	rDiff := primitiveObjectDiff(tg.RestartPolicy, other.RestartPolicy, nil, "RestartPolicy", contextual)
 	if rDiff != nil {
@@ -240,6 +260,11 @@ func (tg *TaskGroup) Diff(other *TaskGroup, contextual bool) (*TaskGroupDiff, er
 		diff.Objects = append(diff.Objects, diskDiff)
 	}
 
+	// Network Resources diff
+	if nDiffs := networkResourceDiffs(tg.Networks, other.Networks, contextual); nDiffs != nil {
+		diff.Objects = append(diff.Objects, nDiffs...)
+	}
+
 	// Update diff
 	// COMPAT: Remove "Stagger" in 0.7.0.
 	if uDiff := primitiveObjectDiff(tg.Update, other.Update, []string{"Stagger"}, "Update", contextual); uDiff != nil {
@@ -397,6 +422,17 @@ func (t *Task) Diff(other *Task, contextual bool) (*TaskDiff, error) {
 		diff.Objects = append(diff.Objects, lDiff)
 	}
 
+	// Log shippers diff
+	lsDiffs := primitiveObjectSetDiff(
+		interfaceSlice(t.LogShippers),
+		interfaceSlice(other.LogShippers),
+		nil,
+		"LogShipper",
+		contextual)
+	if lsDiffs != nil {
+		diff.Objects = append(diff.Objects, lsDiffs...)
+	}
+
 	// Dispatch payload diff
 	dDiff := primitiveObjectDiff(t.DispatchPayload, other.DispatchPayload, nil, "DispatchPayload", contextual)
 	if dDiff != nil {
@@ -724,6 +760,38 @@ func vaultDiff(old, new *Vault, contextual bool) *ObjectDiff {
 	return diff
 }
 
+// periodicConfigDiff returns the diff of two periodic configs. If contextual
+// diff is enabled, all fields will be returned, even if no diff occurred.
+func periodicConfigDiff(old, new *PeriodicConfig, contextual bool) *ObjectDiff {
+	if old == nil && new == nil {
+		return nil
+	}
+
+	diff := primitiveObjectDiff(old, new, nil, "Periodic", contextual)
+	if diff == nil {
+		diff = &ObjectDiff{Type: DiffTypeNone, Name: "Periodic"}
+	}
+
+	var oldSpecs, newSpecs []string
+	if old != nil {
+		oldSpecs = old.Specs
+	}
+	if new != nil {
+		newSpecs = new.Specs
+	}
+	if specsDiff := stringSetDiff(oldSpecs, newSpecs, "Specs", contextual); specsDiff != nil {
+		diff.Objects = append(diff.Objects, specsDiff)
+		if diff.Type == DiffTypeNone {
+			diff.Type = DiffTypeEdited
+		}
+	}
+
+	if diff.Type == DiffTypeNone {
+		return nil
+	}
+	return diff
+}
+
 // parameterizedJobDiff returns the diff of two parameterized job objects. If
 // contextual diff is enabled, all fields will be returned, even if no diff
 // occurred.
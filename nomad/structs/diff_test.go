@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/hashicorp/nomad/helper"
 )
 
 func TestJobDiff(t *testing.T) {
@@ -439,12 +441,24 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeAdded,
 						Name: "Periodic",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "CatchUpWindow",
+								Old:  "",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "Enabled",
 								Old:  "",
 								New:  "false",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Jitter",
+								Old:  "",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "ProhibitOverlap",
@@ -493,12 +507,24 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeDeleted,
 						Name: "Periodic",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "CatchUpWindow",
+								Old:  "0",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "Enabled",
 								Old:  "false",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "Jitter",
+								Old:  "0",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "ProhibitOverlap",
@@ -543,9 +569,13 @@ func TestJobDiff(t *testing.T) {
 				Periodic: &PeriodicConfig{
 					Enabled:         true,
 					Spec:            "* * * * * *",
+					Specs:           []string{"@daily"},
 					SpecType:        "cron",
 					ProhibitOverlap: true,
 					TimeZone:        "America/Los_Angeles",
+					CatchUp:         "all",
+					CatchUpWindow:   time.Hour,
+					Jitter:          time.Minute,
 				},
 			},
 			Expected: &JobDiff{
@@ -555,12 +585,30 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "Periodic",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "CatchUp",
+								Old:  "",
+								New:  "all",
+							},
+							{
+								Type: DiffTypeEdited,
+								Name: "CatchUpWindow",
+								Old:  "0",
+								New:  "3600000000000",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "Enabled",
 								Old:  "false",
 								New:  "true",
 							},
+							{
+								Type: DiffTypeEdited,
+								Name: "Jitter",
+								Old:  "0",
+								New:  "60000000000",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "ProhibitOverlap",
@@ -586,6 +634,20 @@ func TestJobDiff(t *testing.T) {
 								New:  "America/Los_Angeles",
 							},
 						},
+						Objects: []*ObjectDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "Specs",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeAdded,
+										Name: "Specs",
+										Old:  "",
+										New:  "@daily",
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -618,12 +680,30 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "Periodic",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeNone,
+								Name: "CatchUp",
+								Old:  "",
+								New:  "",
+							},
+							{
+								Type: DiffTypeNone,
+								Name: "CatchUpWindow",
+								Old:  "0",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "Enabled",
 								Old:  "false",
 								New:  "true",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Jitter",
+								Old:  "0",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "ProhibitOverlap",
@@ -838,6 +918,12 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeAdded,
 						Name: "ParameterizedJob",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "MaxRunning",
+								Old:  "",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "Payload",
@@ -892,6 +978,12 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeDeleted,
 						Name: "ParameterizedJob",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "MaxRunning",
+								Old:  "0",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "Payload",
@@ -943,6 +1035,7 @@ func TestJobDiff(t *testing.T) {
 					Payload:      DispatchPayloadOptional,
 					MetaOptional: []string{"bam"},
 					MetaRequired: []string{"bang"},
+					MaxRunning:   2,
 				},
 			},
 			Expected: &JobDiff{
@@ -952,6 +1045,12 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "ParameterizedJob",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "MaxRunning",
+								Old:  "0",
+								New:  "2",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "Payload",
@@ -1025,6 +1124,12 @@ func TestJobDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "ParameterizedJob",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeNone,
+								Name: "MaxRunning",
+								Old:  "0",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "Payload",
@@ -1291,6 +1396,104 @@ func TestTaskGroupDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			// DependsOn edited
+			Old: &TaskGroup{
+				DependsOn: []*TaskGroupDependency{
+					{
+						Group:     "db",
+						Condition: TaskGroupDependencyRunning,
+					},
+				},
+			},
+			New: &TaskGroup{
+				DependsOn: []*TaskGroupDependency{
+					{
+						Group:     "db",
+						Condition: TaskGroupDependencyHealthy,
+					},
+				},
+			},
+			Expected: &TaskGroupDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeAdded,
+						Name: "DependsOn",
+						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "Condition",
+								Old:  "",
+								New:  "healthy",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Group",
+								Old:  "",
+								New:  "db",
+							},
+						},
+					},
+					{
+						Type: DiffTypeDeleted,
+						Name: "DependsOn",
+						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "Condition",
+								Old:  "running",
+								New:  "",
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "Group",
+								Old:  "db",
+								New:  "",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			// MaxClientDisconnect added
+			Old: &TaskGroup{},
+			New: &TaskGroup{
+				MaxClientDisconnect: helper.TimeToPtr(5 * time.Minute),
+			},
+			Expected: &TaskGroupDiff{
+				Type: DiffTypeEdited,
+				Fields: []*FieldDiff{
+					{
+						Type: DiffTypeAdded,
+						Name: "MaxClientDisconnect",
+						Old:  "",
+						New:  "300000000000",
+					},
+				},
+			},
+		},
+		{
+			// MaxClientDisconnect edited
+			Old: &TaskGroup{
+				MaxClientDisconnect: helper.TimeToPtr(5 * time.Minute),
+			},
+			New: &TaskGroup{
+				MaxClientDisconnect: helper.TimeToPtr(10 * time.Minute),
+			},
+			Expected: &TaskGroupDiff{
+				Type: DiffTypeEdited,
+				Fields: []*FieldDiff{
+					{
+						Type: DiffTypeEdited,
+						Name: "MaxClientDisconnect",
+						Old:  "300000000000",
+						New:  "600000000000",
+					},
+				},
+			},
+		},
 		{
 			// RestartPolicy added
 			Old: &TaskGroup{},
@@ -1735,6 +1938,60 @@ func TestTaskGroupDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			// Network added
+			Old: &TaskGroup{},
+			New: &TaskGroup{
+				Networks: []*NetworkResource{
+					{
+						Mode:  NetworkModeBridge,
+						MBits: 10,
+						DynamicPorts: []Port{
+							{
+								Label: "http",
+							},
+						},
+					},
+				},
+			},
+			Expected: &TaskGroupDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeAdded,
+						Name: "Network",
+						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "MBits",
+								Old:  "",
+								New:  "10",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Mode",
+								Old:  "",
+								New:  "bridge",
+							},
+						},
+						Objects: []*ObjectDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "Dynamic Port",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeAdded,
+										Name: "Label",
+										Old:  "",
+										New:  "http",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			// EphemeralDisk added
 			Old: &TaskGroup{},
@@ -2338,6 +2595,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeAdded,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "Compress",
+								Old:  "",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "MaxFileSizeMB",
@@ -2350,6 +2613,18 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "1",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "MaxTotalSizeMB",
+								Old:  "",
+								New:  "0",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "RotationInterval",
+								Old:  "",
+								New:  "0",
+							},
 						},
 					},
 				},
@@ -2371,6 +2646,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeDeleted,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeDeleted,
+								Name: "Compress",
+								Old:  "false",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "MaxFileSizeMB",
@@ -2383,6 +2664,18 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "1",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "MaxTotalSizeMB",
+								Old:  "0",
+								New:  "",
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "RotationInterval",
+								Old:  "0",
+								New:  "",
+							},
 						},
 					},
 				},
@@ -2392,14 +2685,20 @@ func TestTaskDiff(t *testing.T) {
 			Name: "LogConfig edited",
 			Old: &Task{
 				LogConfig: &LogConfig{
-					MaxFiles:      1,
-					MaxFileSizeMB: 10,
+					MaxFiles:         1,
+					MaxFileSizeMB:    10,
+					RotationInterval: time.Hour,
+					Compress:         true,
+					MaxTotalSizeMB:   40,
 				},
 			},
 			New: &Task{
 				LogConfig: &LogConfig{
-					MaxFiles:      2,
-					MaxFileSizeMB: 20,
+					MaxFiles:         2,
+					MaxFileSizeMB:    20,
+					RotationInterval: 2 * time.Hour,
+					Compress:         false,
+					MaxTotalSizeMB:   80,
 				},
 			},
 			Expected: &TaskDiff{
@@ -2409,6 +2708,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "Compress",
+								Old:  "true",
+								New:  "false",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MaxFileSizeMB",
@@ -2421,6 +2726,18 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "1",
 								New:  "2",
 							},
+							{
+								Type: DiffTypeEdited,
+								Name: "MaxTotalSizeMB",
+								Old:  "40",
+								New:  "80",
+							},
+							{
+								Type: DiffTypeEdited,
+								Name: "RotationInterval",
+								Old:  "3600000000000",
+								New:  "7200000000000",
+							},
 						},
 					},
 				},
@@ -2431,14 +2748,20 @@ func TestTaskDiff(t *testing.T) {
 			Contextual: true,
 			Old: &Task{
 				LogConfig: &LogConfig{
-					MaxFiles:      1,
-					MaxFileSizeMB: 10,
+					MaxFiles:         1,
+					MaxFileSizeMB:    10,
+					RotationInterval: time.Hour,
+					Compress:         true,
+					MaxTotalSizeMB:   40,
 				},
 			},
 			New: &Task{
 				LogConfig: &LogConfig{
-					MaxFiles:      1,
-					MaxFileSizeMB: 20,
+					MaxFiles:         1,
+					MaxFileSizeMB:    20,
+					RotationInterval: time.Hour,
+					Compress:         true,
+					MaxTotalSizeMB:   40,
 				},
 			},
 			Expected: &TaskDiff{
@@ -2448,6 +2771,12 @@ func TestTaskDiff(t *testing.T) {
 						Type: DiffTypeEdited,
 						Name: "LogConfig",
 						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeNone,
+								Name: "Compress",
+								Old:  "true",
+								New:  "true",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MaxFileSizeMB",
@@ -2460,6 +2789,18 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "1",
 								New:  "1",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "MaxTotalSizeMB",
+								Old:  "40",
+								New:  "40",
+							},
+							{
+								Type: DiffTypeNone,
+								Name: "RotationInterval",
+								Old:  "3600000000000",
+								New:  "3600000000000",
+							},
 						},
 					},
 				},
@@ -2657,6 +2998,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "100",
 								New:  "200",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Cores",
+								Old:  "0",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "DiskMB",
@@ -2675,6 +3022,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "100",
 								New:  "100",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "MemoryMaxMB",
+								Old:  "0",
+								New:  "0",
+							},
 						},
 					},
 				},
@@ -3309,6 +3662,12 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "",
 										New:  "foo",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "GRPCUseTLS",
+										Old:  "",
+										New:  "false",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "Interval",
@@ -3363,6 +3722,12 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "foo",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "GRPCUseTLS",
+										Old:  "false",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "Interval",
@@ -3513,6 +3878,18 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "foo",
 										New:  "foo",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "GRPCService",
+										Old:  "",
+										New:  "",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "GRPCUseTLS",
+										Old:  "false",
+										New:  "false",
+									},
 									{
 										Type: DiffTypeEdited,
 										Name: "InitialStatus",
@@ -3798,6 +4175,9 @@ func TestTaskDiff(t *testing.T) {
 			New: &Task{
 				Vault: &Vault{
 					Policies:     []string{"foo", "bar"},
+					Role:         "nomad-cluster",
+					EntityAlias:  "${NOMAD_JOB_ID}",
+					TTL:          time.Hour,
 					Env:          true,
 					ChangeMode:   "signal",
 					ChangeSignal: "SIGUSR1",
@@ -3822,12 +4202,30 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "SIGUSR1",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "EntityAlias",
+								Old:  "",
+								New:  "${NOMAD_JOB_ID}",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "Env",
 								Old:  "",
 								New:  "true",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Role",
+								Old:  "",
+								New:  "nomad-cluster",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "TTL",
+								Old:  "",
+								New:  "3600000000000",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -3858,6 +4256,8 @@ func TestTaskDiff(t *testing.T) {
 			Old: &Task{
 				Vault: &Vault{
 					Policies:     []string{"foo", "bar"},
+					Role:         "nomad-cluster",
+					TTL:          time.Hour,
 					Env:          true,
 					ChangeMode:   "signal",
 					ChangeSignal: "SIGUSR1",
@@ -3889,6 +4289,18 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "true",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "Role",
+								Old:  "nomad-cluster",
+								New:  "",
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "TTL",
+								Old:  "3600000000000",
+								New:  "",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -3919,6 +4331,7 @@ func TestTaskDiff(t *testing.T) {
 			Old: &Task{
 				Vault: &Vault{
 					Policies:     []string{"foo", "bar"},
+					Role:         "nomad-cluster",
 					Env:          true,
 					ChangeMode:   "signal",
 					ChangeSignal: "SIGUSR1",
@@ -3927,6 +4340,7 @@ func TestTaskDiff(t *testing.T) {
 			New: &Task{
 				Vault: &Vault{
 					Policies:     []string{"bar", "baz"},
+					Role:         "nomad-web",
 					Env:          false,
 					ChangeMode:   "restart",
 					ChangeSignal: "foo",
@@ -3957,6 +4371,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "true",
 								New:  "false",
 							},
+							{
+								Type: DiffTypeEdited,
+								Name: "Role",
+								Old:  "nomad-cluster",
+								New:  "nomad-web",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -4020,12 +4440,30 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "SIGUSR1",
 								New:  "SIGUSR1",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "EntityAlias",
+								Old:  "",
+								New:  "",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "Env",
 								Old:  "true",
 								New:  "true",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "Role",
+								Old:  "",
+								New:  "",
+							},
+							{
+								Type: DiffTypeNone,
+								Name: "TTL",
+								Old:  "0",
+								New:  "0",
+							},
 						},
 						Objects: []*ObjectDiff{
 							{
@@ -4234,6 +4672,62 @@ func TestTaskDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "LogShipper added",
+			Old:  &Task{},
+			New: &Task{
+				LogShippers: []*LogShipper{
+					{
+						Name:       "audit",
+						Type:       LogShipperTypeSyslog,
+						Address:    "10.0.0.1:514",
+						Protocol:   "udp",
+						BufferSize: 512,
+					},
+				},
+			},
+			Expected: &TaskDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeAdded,
+						Name: "LogShipper",
+						Fields: []*FieldDiff{
+							{
+								Type: DiffTypeAdded,
+								Name: "Address",
+								Old:  "",
+								New:  "10.0.0.1:514",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "BufferSize",
+								Old:  "",
+								New:  "512",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Name",
+								Old:  "",
+								New:  "audit",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Protocol",
+								Old:  "",
+								New:  "udp",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "Type",
+								Old:  "",
+								New:  "syslog",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			Name: "DispatchPayload added",
 			Old:  &Task{},
@@ -152,6 +152,15 @@ func AllocsFit(node *Node, allocs []*Allocation, netIdx *NetworkIndex) (bool, st
 		return false, "bandwidth exceeded", used, nil
 	}
 
+	// Check that no core is reserved twice or is missing from the node
+	coreIdx := NewCoreIndex()
+	if coreIdx.SetNode(node) || coreIdx.AddAllocs(allocs) {
+		return false, "reserved core collision", used, nil
+	}
+	if coreIdx.Overcommitted() {
+		return false, "cores exhausted", used, nil
+	}
+
 	// Allocations fit!
 	return true, "", used, nil
 }
@@ -160,19 +169,7 @@ func AllocsFit(node *Node, allocs []*Allocation, netIdx *NetworkIndex) (bool, st
 // http://www.columbia.edu/~cs2035/courses/ieor4405.S13/datacenter_scheduling.ppt
 // This is equivalent to their BestFit v3
 func ScoreFit(node *Node, util *Resources) float64 {
-	// Determine the node availability
-	nodeCpu := float64(node.Resources.CPU)
-	if node.Reserved != nil {
-		nodeCpu -= float64(node.Reserved.CPU)
-	}
-	nodeMem := float64(node.Resources.MemoryMB)
-	if node.Reserved != nil {
-		nodeMem -= float64(node.Reserved.MemoryMB)
-	}
-
-	// Compute the free percentage
-	freePctCpu := 1 - (float64(util.CPU) / nodeCpu)
-	freePctRam := 1 - (float64(util.MemoryMB) / nodeMem)
+	freePctCpu, freePctRam := computeFreePercentage(node, util)
 
 	// Total will be "maximized" the smaller the value is.
 	// At 100% utilization, the total is 2, while at 0% util it is 20.
@@ -193,6 +190,46 @@ func ScoreFit(node *Node, util *Resources) float64 {
 	return score
 }
 
+// ScoreFitSpread is used to score the fit based on the Google work published
+// here: http://www.columbia.edu/~cs2035/courses/ieor4405.S13/datacenter_scheduling.ppt
+// Unlike ScoreFit, the score is maximized for the node with the most free
+// resources, which spreads allocations across the nodes.
+func ScoreFitSpread(node *Node, util *Resources) float64 {
+	freePctCpu, freePctRam := computeFreePercentage(node, util)
+
+	// At 0% utilization the total is 20, which is the maximized score of
+	// 18 once anchored, while at 100% utilization it is 0.
+	total := math.Pow(10, freePctCpu) + math.Pow(10, freePctRam)
+	score := total - 2.0
+
+	// Bound the score, just in case
+	if score > 18.0 {
+		score = 18.0
+	} else if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// computeFreePercentage returns the percentage of free CPU and memory of the
+// node once the given resources are used.
+func computeFreePercentage(node *Node, util *Resources) (freePctCpu, freePctRam float64) {
+	// Determine the node availability
+	nodeCpu := float64(node.Resources.CPU)
+	if node.Reserved != nil {
+		nodeCpu -= float64(node.Reserved.CPU)
+	}
+	nodeMem := float64(node.Resources.MemoryMB)
+	if node.Reserved != nil {
+		nodeMem -= float64(node.Reserved.MemoryMB)
+	}
+
+	// Compute the free percentage
+	freePctCpu = 1 - (float64(util.CPU) / nodeCpu)
+	freePctRam = 1 - (float64(util.MemoryMB) / nodeMem)
+	return freePctCpu, freePctRam
+}
+
 func CopySliceConstraints(s []*Constraint) []*Constraint {
 	l := len(s)
 	if l == 0 {
@@ -226,6 +263,27 @@ func VaultPoliciesSet(policies map[string]map[string]*Vault) []string {
 	return flattened
 }
 
+// VaultRolesSet takes the structure returned by VaultPolicies and returns the
+// sorted set of token roles requested by tasks
+func VaultRolesSet(policies map[string]map[string]*Vault) []string {
+	set := make(map[string]struct{})
+
+	for _, tgp := range policies {
+		for _, tp := range tgp {
+			if tp.Role != "" {
+				set[tp.Role] = struct{}{}
+			}
+		}
+	}
+
+	flattened := make([]string, 0, len(set))
+	for r := range set {
+		flattened = append(flattened, r)
+	}
+	sort.Strings(flattened)
+	return flattened
+}
+
 // DenormalizeAllocationJobs is used to attach a job to all allocations that are
 // non-terminal and do not have a job already. This is useful in cases where the
 // job is normalized.
@@ -131,6 +131,55 @@ func TestAllocsFit_PortsOvercommitted(t *testing.T) {
 	}
 }
 
+func TestAllocsFit_CoresCollide(t *testing.T) {
+	n := &Node{
+		Resources: &Resources{
+			CPU:      4000,
+			MemoryMB: 4096,
+			CoreIDs:  []uint16{0, 1, 2, 3},
+		},
+	}
+
+	a1 := &Allocation{
+		TaskResources: map[string]*Resources{
+			"web": {
+				CPU:      1000,
+				MemoryMB: 256,
+				CoreIDs:  []uint16{1},
+			},
+		},
+	}
+
+	// Should fit one allocation
+	fit, dim, _, err := AllocsFit(n, []*Allocation{a1}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !fit {
+		t.Fatalf("Bad: %s", dim)
+	}
+
+	// Should not fit a second allocation on the same core
+	fit, dim, _, err = AllocsFit(n, []*Allocation{a1, a1}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if fit || dim != "reserved core collision" {
+		t.Fatalf("Bad: %v %s", fit, dim)
+	}
+
+	// Should not fit an allocation on a core the node doesn't have
+	a2 := a1.Copy()
+	a2.TaskResources["web"].CoreIDs = []uint16{7}
+	fit, dim, _, err = AllocsFit(n, []*Allocation{a2}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if fit || dim != "cores exhausted" {
+		t.Fatalf("Bad: %v %s", fit, dim)
+	}
+}
+
 func TestAllocsFit(t *testing.T) {
 	n := &Node{
 		Resources: &Resources{
@@ -257,6 +306,48 @@ func TestScoreFit(t *testing.T) {
 	}
 }
 
+func TestScoreFitSpread(t *testing.T) {
+	node := &Node{}
+	node.Resources = &Resources{
+		CPU:      4096,
+		MemoryMB: 8192,
+	}
+	node.Reserved = &Resources{
+		CPU:      2048,
+		MemoryMB: 4096,
+	}
+
+	// Test a full node
+	util := &Resources{
+		CPU:      2048,
+		MemoryMB: 4096,
+	}
+	score := ScoreFitSpread(node, util)
+	if score != 0.0 {
+		t.Fatalf("bad: %v", score)
+	}
+
+	// Test an empty node
+	util = &Resources{
+		CPU:      0,
+		MemoryMB: 0,
+	}
+	score = ScoreFitSpread(node, util)
+	if score != 18.0 {
+		t.Fatalf("bad: %v", score)
+	}
+
+	// Test a mid-case scenario scores lower than with bin packing
+	util = &Resources{
+		CPU:      1024,
+		MemoryMB: 2048,
+	}
+	score = ScoreFitSpread(node, util)
+	if score < 2.0 || score > 8.0 {
+		t.Fatalf("bad: %v", score)
+	}
+}
+
 func TestACLPolicyListHash(t *testing.T) {
 	h1 := ACLPolicyListHash(nil)
 	assert.NotEqual(t, "", h1)
@@ -73,6 +73,16 @@ func TestNode_ComputedClass(t *testing.T) {
 	if old == n.ComputedClass {
 		t.Fatal("ComputeClass() returned same computed class")
 	}
+
+	// Modify the node pool and compute the class again.
+	old = n.ComputedClass
+	n.NodePool = "gpu"
+	if err := n.ComputeClass(); err != nil {
+		t.Fatalf("ComputeClass() failed: %v", err)
+	}
+	if old == n.ComputedClass {
+		t.Fatal("ComputeClass() returned same computed class")
+	}
 }
 
 func TestNode_ComputedClass_Ignore(t *testing.T) {
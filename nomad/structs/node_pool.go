@@ -0,0 +1,191 @@
+package structs
+
+import (
+	"fmt"
+	"regexp"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// NodePoolAll is a built-in node pool that always includes all nodes in
+	// the cluster. Jobs in this pool may be placed on any node.
+	NodePoolAll = "all"
+
+	// NodePoolDefault is a built-in node pool that holds the nodes and jobs
+	// that don't specify a node pool.
+	NodePoolDefault = "default"
+
+	// maxNodePoolDescriptionLength limits a node pool description length
+	maxNodePoolDescriptionLength = 256
+)
+
+var (
+	// validNodePoolName is used to validate a node pool name
+	validNodePoolName = regexp.MustCompile("^[a-zA-Z0-9-_]{1,128}$")
+)
+
+// ValidateNodePoolName returns an error if the node pool name is invalid.
+func ValidateNodePoolName(pool string) error {
+	if !validNodePoolName.MatchString(pool) {
+		return fmt.Errorf("invalid node pool name %q", pool)
+	}
+	return nil
+}
+
+// NodePool allows partitioning the nodes of a cluster. Nodes join a node pool
+// when they register and jobs are only placed on the nodes of their pool.
+type NodePool struct {
+	// Name is the node pool name. It must be unique.
+	Name string
+
+	// Description is the human-friendly description of the node pool.
+	Description string
+
+	// Meta is a set of user-provided metadata for the node pool.
+	Meta map[string]string
+
+	// SchedulerConfiguration overrides the cluster wide scheduler
+	// configuration for the jobs in the pool.
+	SchedulerConfiguration *NodePoolSchedulerConfiguration
+
+	// CreateIndex/ModifyIndex store the create/modify indexes of this node
+	// pool.
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// IsBuiltIn returns true if the node pool is one of the built-in pools, which
+// can't be deleted.
+func (n *NodePool) IsBuiltIn() bool {
+	switch n.Name {
+	case NodePoolAll, NodePoolDefault:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate returns an error if the node pool is invalid.
+func (n *NodePool) Validate() error {
+	var mErr multierror.Error
+	if err := ValidateNodePoolName(n.Name); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if len(n.Description) > maxNodePoolDescriptionLength {
+		err := fmt.Errorf("description longer than %d", maxNodePoolDescriptionLength)
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	if n.SchedulerConfiguration != nil {
+		if err := ValidateSchedulerAlgorithm(n.SchedulerConfiguration.SchedulerAlgorithm); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// Copy returns a deep copy of the node pool.
+func (n *NodePool) Copy() *NodePool {
+	if n == nil {
+		return nil
+	}
+	nn := new(NodePool)
+	*nn = *n
+	nn.Meta = helper.CopyMapStringString(n.Meta)
+	nn.SchedulerConfiguration = n.SchedulerConfiguration.Copy()
+	return nn
+}
+
+// NodePoolSchedulerConfiguration is the scheduler configuration of a node
+// pool. Unset fields use the value of the cluster wide configuration.
+type NodePoolSchedulerConfiguration struct {
+	// SchedulerAlgorithm overrides the algorithm used to score nodes.
+	SchedulerAlgorithm SchedulerAlgorithm
+
+	// MemoryOversubscriptionEnabled overrides whether tasks may use memory
+	// up to their MemoryMaxMB.
+	MemoryOversubscriptionEnabled *bool
+}
+
+// Copy returns a deep copy of the configuration.
+func (n *NodePoolSchedulerConfiguration) Copy() *NodePoolSchedulerConfiguration {
+	if n == nil {
+		return nil
+	}
+	nn := new(NodePoolSchedulerConfiguration)
+	*nn = *n
+	if n.MemoryOversubscriptionEnabled != nil {
+		nn.MemoryOversubscriptionEnabled = helper.BoolToPtr(*n.MemoryOversubscriptionEnabled)
+	}
+	return nn
+}
+
+// WithNodePool returns the scheduler configuration to use for jobs in the
+// given node pool. Both the configuration and the pool may be nil.
+func (s *SchedulerConfiguration) WithNodePool(pool *NodePool) *SchedulerConfiguration {
+	if pool == nil || pool.SchedulerConfiguration == nil {
+		return s
+	}
+
+	config := new(SchedulerConfiguration)
+	if s != nil {
+		*config = *s
+	}
+
+	override := pool.SchedulerConfiguration
+	if override.SchedulerAlgorithm != "" {
+		config.SchedulerAlgorithm = override.SchedulerAlgorithm
+	}
+	if override.MemoryOversubscriptionEnabled != nil {
+		config.MemoryOversubscriptionEnabled = *override.MemoryOversubscriptionEnabled
+	}
+	return config
+}
+
+// NodePoolListRequest is used to request a list of node pools
+type NodePoolListRequest struct {
+	QueryOptions
+}
+
+// NodePoolSpecificRequest is used to query a specific node pool
+type NodePoolSpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// NodePoolListResponse is used for a list request
+type NodePoolListResponse struct {
+	NodePools []*NodePool
+	QueryMeta
+}
+
+// SingleNodePoolResponse is used to return a single node pool
+type SingleNodePoolResponse struct {
+	NodePool *NodePool
+	QueryMeta
+}
+
+// NodePoolUpsertRequest is used to upsert a set of node pools
+type NodePoolUpsertRequest struct {
+	NodePools []*NodePool
+	WriteRequest
+}
+
+// NodePoolDeleteRequest is used to delete a set of node pools
+type NodePoolDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// NodePoolNodesRequest is used to list the nodes in a node pool
+type NodePoolNodesRequest struct {
+	Name string
+	QueryOptions
+}
+
+// NodePoolNodesResponse is used to return the nodes in a node pool
+type NodePoolNodesResponse struct {
+	Nodes []*NodeListStub
+	QueryMeta
+}
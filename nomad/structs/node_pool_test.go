@@ -0,0 +1,91 @@
+package structs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+func TestNodePool_Validate(t *testing.T) {
+	pool := &NodePool{
+		Name:        "gpu",
+		Description: "GPU nodes",
+	}
+	if err := pool.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	pool.Name = "has a space"
+	pool.Description = strings.Repeat("a", maxNodePoolDescriptionLength+1)
+	err := pool.Validate()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "invalid node pool name") {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(err.Error(), "description longer than") {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The scheduler algorithm must be known
+	pool = &NodePool{
+		Name: "gpu",
+		SchedulerConfiguration: &NodePoolSchedulerConfiguration{
+			SchedulerAlgorithm: "random",
+		},
+	}
+	err = pool.Validate()
+	if err == nil || !strings.Contains(err.Error(), "invalid scheduler algorithm") {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestNodePool_IsBuiltIn(t *testing.T) {
+	if !(&NodePool{Name: NodePoolAll}).IsBuiltIn() {
+		t.Fatalf("all node pool should be built-in")
+	}
+	if !(&NodePool{Name: NodePoolDefault}).IsBuiltIn() {
+		t.Fatalf("default node pool should be built-in")
+	}
+	if (&NodePool{Name: "gpu"}).IsBuiltIn() {
+		t.Fatalf("gpu node pool shouldn't be built-in")
+	}
+}
+
+func TestSchedulerConfiguration_WithNodePool(t *testing.T) {
+	cluster := &SchedulerConfiguration{
+		MemoryOversubscriptionEnabled: true,
+	}
+
+	// A pool without overrides uses the cluster configuration
+	pool := &NodePool{Name: "gpu"}
+	if config := cluster.WithNodePool(pool); config != cluster {
+		t.Fatalf("unexpected configuration: %#v", config)
+	}
+
+	// Overrides are applied without modifying the cluster configuration
+	pool.SchedulerConfiguration = &NodePoolSchedulerConfiguration{
+		MemoryOversubscriptionEnabled: helper.BoolToPtr(false),
+	}
+	if config := cluster.WithNodePool(pool); config.MemoryOversubscriptionEnabled {
+		t.Fatalf("expected the override to be applied: %#v", config)
+	}
+	if !cluster.MemoryOversubscriptionEnabled {
+		t.Fatalf("cluster configuration was modified")
+	}
+
+	// The scheduler algorithm can be overridden
+	pool.SchedulerConfiguration.SchedulerAlgorithm = SchedulerAlgorithmSpread
+	if config := cluster.WithNodePool(pool); config.SchedulerAlgorithm != SchedulerAlgorithmSpread {
+		t.Fatalf("expected the override to be applied: %#v", config)
+	}
+
+	// Overrides apply when the cluster configuration was never set
+	var unset *SchedulerConfiguration
+	pool.SchedulerConfiguration.MemoryOversubscriptionEnabled = helper.BoolToPtr(true)
+	if config := unset.WithNodePool(pool); !config.MemoryOversubscriptionEnabled {
+		t.Fatalf("expected the override to be applied: %#v", config)
+	}
+}
@@ -1,6 +1,8 @@
 package structs
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/raft"
 )
 
@@ -47,3 +49,110 @@ type RaftPeerByAddressRequest struct {
 	// WriteRequest holds the Region for this request.
 	WriteRequest
 }
+
+// SchedulerAlgorithm is the algorithm used to score the nodes a task group
+// may be placed on.
+type SchedulerAlgorithm string
+
+const (
+	// SchedulerAlgorithmBinpack scores nodes by how tightly they are packed,
+	// which favors filling nodes before using new ones.
+	SchedulerAlgorithmBinpack SchedulerAlgorithm = "binpack"
+
+	// SchedulerAlgorithmSpread scores nodes by how much free capacity they
+	// have, which spreads load across the nodes.
+	SchedulerAlgorithmSpread SchedulerAlgorithm = "spread"
+)
+
+// ValidateSchedulerAlgorithm returns an error if the algorithm is unknown. An
+// empty algorithm is valid and uses the default.
+func ValidateSchedulerAlgorithm(algorithm SchedulerAlgorithm) error {
+	switch algorithm {
+	case "", SchedulerAlgorithmBinpack, SchedulerAlgorithmSpread:
+		return nil
+	default:
+		return fmt.Errorf("invalid scheduler algorithm %q: must be %q or %q",
+			algorithm, SchedulerAlgorithmBinpack, SchedulerAlgorithmSpread)
+	}
+}
+
+// SchedulerConfiguration is the cluster wide configuration of the
+// schedulers. It is stored in Raft so it can be changed at runtime.
+type SchedulerConfiguration struct {
+	// SchedulerAlgorithm is the algorithm used to score nodes. Defaults to
+	// binpack when unset.
+	SchedulerAlgorithm SchedulerAlgorithm
+
+	// MemoryOversubscriptionEnabled allows tasks to use memory up to their
+	// MemoryMaxMB. When disabled tasks are limited to their MemoryMB.
+	MemoryOversubscriptionEnabled bool
+
+	// CreateIndex/ModifyIndex store the create/modify indexes of this
+	// configuration.
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// EffectiveSchedulerAlgorithm returns the configured scheduler algorithm or
+// the default if it is unset. The configuration may be nil.
+func (s *SchedulerConfiguration) EffectiveSchedulerAlgorithm() SchedulerAlgorithm {
+	if s == nil || s.SchedulerAlgorithm == "" {
+		return SchedulerAlgorithmBinpack
+	}
+	return s.SchedulerAlgorithm
+}
+
+// WithJob returns the scheduler configuration with the overrides of the job
+// applied. Both the configuration and the job may be nil.
+func (s *SchedulerConfiguration) WithJob(job *Job) *SchedulerConfiguration {
+	if job == nil || job.SchedulerAlgorithm == "" {
+		return s
+	}
+
+	config := new(SchedulerConfiguration)
+	if s != nil {
+		*config = *s
+	}
+	config.SchedulerAlgorithm = job.SchedulerAlgorithm
+	return config
+}
+
+// Validate returns an error if the configuration is invalid.
+func (s *SchedulerConfiguration) Validate() error {
+	return ValidateSchedulerAlgorithm(s.SchedulerAlgorithm)
+}
+
+// SchedulerConfigurationResponse is returned when querying for the current
+// scheduler configuration.
+type SchedulerConfigurationResponse struct {
+	// SchedulerConfig contains the scheduler configuration. It is nil if the
+	// configuration has never been set.
+	SchedulerConfig *SchedulerConfiguration
+
+	QueryMeta
+}
+
+// SchedulerSetConfigRequest is used by the Operator endpoint to update the
+// scheduler configuration of the cluster.
+type SchedulerSetConfigRequest struct {
+	// Config is the new scheduler configuration.
+	Config SchedulerConfiguration
+
+	// CAS controls whether to use check-and-set semantics for this request.
+	// The configuration is only updated if Config.ModifyIndex matches the
+	// index of the current configuration.
+	CAS bool
+
+	// WriteRequest holds the Region for this request.
+	WriteRequest
+}
+
+// SchedulerSetConfigurationResponse is returned when updating the scheduler
+// configuration.
+type SchedulerSetConfigurationResponse struct {
+	// Updated is false if a check-and-set update failed because the
+	// configuration was modified concurrently.
+	Updated bool
+
+	WriteMeta
+}
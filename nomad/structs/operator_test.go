@@ -0,0 +1,63 @@
+package structs
+
+import (
+	"testing"
+)
+
+func TestSchedulerConfiguration_EffectiveSchedulerAlgorithm(t *testing.T) {
+	var unset *SchedulerConfiguration
+	if alg := unset.EffectiveSchedulerAlgorithm(); alg != SchedulerAlgorithmBinpack {
+		t.Fatalf("bad: %v", alg)
+	}
+
+	config := &SchedulerConfiguration{}
+	if alg := config.EffectiveSchedulerAlgorithm(); alg != SchedulerAlgorithmBinpack {
+		t.Fatalf("bad: %v", alg)
+	}
+
+	config.SchedulerAlgorithm = SchedulerAlgorithmSpread
+	if alg := config.EffectiveSchedulerAlgorithm(); alg != SchedulerAlgorithmSpread {
+		t.Fatalf("bad: %v", alg)
+	}
+}
+
+func TestSchedulerConfiguration_Validate(t *testing.T) {
+	config := &SchedulerConfiguration{SchedulerAlgorithm: SchedulerAlgorithmSpread}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	config.SchedulerAlgorithm = "random"
+	if err := config.Validate(); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestSchedulerConfiguration_WithJob(t *testing.T) {
+	cluster := &SchedulerConfiguration{
+		SchedulerAlgorithm:            SchedulerAlgorithmBinpack,
+		MemoryOversubscriptionEnabled: true,
+	}
+
+	// A job without overrides uses the given configuration
+	job := &Job{}
+	if config := cluster.WithJob(job); config != cluster {
+		t.Fatalf("unexpected configuration: %#v", config)
+	}
+
+	// Overrides are applied without modifying the given configuration
+	job.SchedulerAlgorithm = SchedulerAlgorithmSpread
+	config := cluster.WithJob(job)
+	if config.SchedulerAlgorithm != SchedulerAlgorithmSpread || !config.MemoryOversubscriptionEnabled {
+		t.Fatalf("unexpected configuration: %#v", config)
+	}
+	if cluster.SchedulerAlgorithm != SchedulerAlgorithmBinpack {
+		t.Fatalf("cluster configuration was modified")
+	}
+
+	// Overrides apply when the configuration was never set
+	var unset *SchedulerConfiguration
+	if config := unset.WithJob(job); config.SchedulerAlgorithm != SchedulerAlgorithmSpread {
+		t.Fatalf("expected the override to be applied: %#v", config)
+	}
+}
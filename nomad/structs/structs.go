@@ -73,6 +73,12 @@ const (
 	ACLTokenUpsertRequestType
 	ACLTokenDeleteRequestType
 	ACLTokenBootstrapRequestType
+	SchedulerConfigRequestType
+	NodePoolUpsertRequestType
+	NodePoolDeleteRequestType
+	PeriodicPauseRequestType
+	ConsulAccessorRegisterRequestType
+	ConsulAccessorDeregisterRequestType
 )
 
 const (
@@ -282,6 +288,10 @@ type NodeServerInfo struct {
 type NodeUpdateStatusRequest struct {
 	NodeID string
 	Status string
+
+	// UpdatedAt is the Unix time of the status update. It is recorded on the
+	// node so the schedulers agree on when it went down.
+	UpdatedAt int64
 	WriteRequest
 }
 
@@ -348,6 +358,10 @@ type JobRegisterRequest struct {
 	// PolicyOverride is set when the user is attempting to override any policies
 	PolicyOverride bool
 
+	// Submission is the original source of the job. It is stored with the
+	// version of the job the registration produces.
+	Submission *JobSubmission
+
 	WriteRequest
 }
 
@@ -392,6 +406,21 @@ type JobPlanRequest struct {
 	WriteRequest
 }
 
+// JobCapacityRequest is used to forecast how many more instances of a task
+// group of the job fit in the cluster
+type JobCapacityRequest struct {
+	Job *Job
+
+	// TaskGroup is the name of the task group to forecast. It may be omitted
+	// if the job has a single task group.
+	TaskGroup string
+
+	// Limit is the maximum number of instances to place. Zero uses the
+	// default limit.
+	Limit int
+	WriteRequest
+}
+
 // JobSummaryRequest is used when we just need to get a specific job summary
 type JobSummaryRequest struct {
 	JobID string
@@ -403,6 +432,12 @@ type JobDispatchRequest struct {
 	JobID   string
 	Payload []byte
 	Meta    map[string]string
+
+	// IdempotencyToken is used to ensure the job is dispatched only once. If
+	// a dispatched job with the same token is not dead, it is returned
+	// instead of dispatching a new job.
+	IdempotencyToken string
+
 	WriteRequest
 }
 
@@ -548,6 +583,21 @@ type PeriodicForceRequest struct {
 	WriteRequest
 }
 
+// PeriodicPauseRequest is used to pause or resume the launches of a periodic
+// job.
+type PeriodicPauseRequest struct {
+	JobID  string
+	Paused bool
+	WriteRequest
+}
+
+// PeriodicLaunchSpecificRequest is used to lookup the launch state of a
+// periodic job.
+type PeriodicLaunchSpecificRequest struct {
+	JobID string
+	QueryOptions
+}
+
 // ServerMembersResponse has the list of servers in a cluster
 type ServerMembersResponse struct {
 	ServerName   string
@@ -599,6 +649,24 @@ type VaultAccessor struct {
 	CreateIndex uint64
 }
 
+// ConsulAccessorsRequest is used to operate on a set of Consul accessors
+type ConsulAccessorsRequest struct {
+	Accessors []*ConsulAccessor
+}
+
+// ConsulAccessor is a reference to a Consul token created on behalf of an
+// allocation's task. With Consul's legacy ACL system the accessor is the ID
+// of the token.
+type ConsulAccessor struct {
+	AllocID  string
+	Task     string
+	NodeID   string
+	Accessor string
+
+	// Raft Indexes
+	CreateIndex uint64
+}
+
 // DeriveVaultTokenResponse returns the wrapped tokens for each requested task
 type DeriveVaultTokenResponse struct {
 	// Tasks is a mapping between the task name and the wrapped token
@@ -611,6 +679,29 @@ type DeriveVaultTokenResponse struct {
 	QueryMeta
 }
 
+// DeriveConsulTokenRequest is used to request Consul tokens for the following
+// tasks in the given allocation
+type DeriveConsulTokenRequest struct {
+	NodeID   string
+	SecretID string
+	AllocID  string
+	Tasks    []string
+	QueryOptions
+}
+
+// DeriveConsulTokenResponse returns the Consul tokens for each requested task
+type DeriveConsulTokenResponse struct {
+	// Tasks is a mapping between the task name and the Consul token. It is
+	// empty if servers do not enforce Consul service identities.
+	Tasks map[string]string
+
+	// Error stores any error that occurred. Errors are stored here so we can
+	// communicate whether it is retriable
+	Error *RecoverableError
+
+	QueryMeta
+}
+
 // GenericRequest is used to request where no
 // specific information is needed.
 type GenericRequest struct {
@@ -849,6 +940,11 @@ type JobDispatchResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
 	JobCreateIndex  uint64
+
+	// Queued is set if the dispatched job waits for the running limit of
+	// the parameterized job.
+	Queued bool
+
 	WriteMeta
 }
 
@@ -872,6 +968,19 @@ type JobVersionsResponse struct {
 	QueryMeta
 }
 
+// JobSubmissionRequest is used to get the original source of a job version
+type JobSubmissionRequest struct {
+	JobID   string
+	Version uint64
+	QueryOptions
+}
+
+// JobSubmissionResponse is used for a job get submission request
+type JobSubmissionResponse struct {
+	Submission *JobSubmission
+	QueryMeta
+}
+
 // JobPlanResponse is used to respond to a job plan request
 type JobPlanResponse struct {
 	// Annotations stores annotations explaining decisions the scheduler made.
@@ -904,6 +1013,111 @@ type JobPlanResponse struct {
 	WriteMeta
 }
 
+// JobCapacityResponse is used to respond to a job capacity request
+type JobCapacityResponse struct {
+	Capacity *TaskGroupCapacity
+
+	// Warnings contains any warnings about the given job. These may include
+	// deprecation warnings.
+	Warnings string
+
+	WriteMeta
+}
+
+// TaskGroupCapacity is the forecast of how many more instances of a task
+// group fit in the cluster and where they would be placed.
+type TaskGroupCapacity struct {
+	TaskGroup string
+
+	// Placed is the number of additional instances that fit
+	Placed int
+
+	// LimitReached is set if the forecast stopped at the requested limit
+	// before the cluster was exhausted
+	LimitReached bool
+
+	// ByDatacenter, ByNodeClass and ByNode are the number of instances placed
+	// by datacenter, node class and node ID.
+	ByDatacenter map[string]int
+	ByNodeClass  map[string]int
+	ByNode       map[string]int
+
+	// LimitingDimension is the resource dimension that was exhausted on the
+	// most nodes when the next instance failed to place.
+	LimitingDimension string
+
+	// Metrics are the metrics of the placement that failed and ended the
+	// forecast.
+	Metrics *AllocMetric
+}
+
+// JobExplainResponse is used to respond to a job explain request
+type JobExplainResponse struct {
+	// Explanation is the diagnosis of why the job is not placed. It is nil if
+	// the job does not exist.
+	Explanation *JobExplanation
+	QueryMeta
+}
+
+const (
+	JobExplainReasonJob        = "job"
+	JobExplainReasonEval       = "eval"
+	JobExplainReasonNodes      = "nodes"
+	JobExplainReasonClass      = "class"
+	JobExplainReasonConstraint = "constraint"
+	JobExplainReasonExhausted  = "exhausted"
+	JobExplainReasonDeployment = "deployment"
+	JobExplainReasonBlocked    = "blocked"
+)
+
+// JobExplanation aggregates the scheduling state of a job into a diagnosis
+// of why its allocations are not placed.
+type JobExplanation struct {
+	JobID     string
+	Namespace string
+	JobStatus string
+
+	// EvalID, EvalStatus and EvalStatusDescription describe the latest
+	// evaluation of the job that is not blocked.
+	EvalID                string
+	EvalStatus            string
+	EvalStatusDescription string
+
+	// BlockedEvalID is the ID of the evaluation waiting for cluster capacity.
+	// ClassEligibility and EscapedComputedClass are copied from it.
+	BlockedEvalID        string
+	ClassEligibility     map[string]bool
+	EscapedComputedClass bool
+
+	// DeploymentID, DeploymentStatus and DeploymentStatusDescription describe
+	// the latest deployment of the job.
+	DeploymentID                string
+	DeploymentStatus            string
+	DeploymentStatusDescription string
+
+	// UnplacedAllocations is the number of allocations the latest evaluation
+	// failed to place by task group.
+	UnplacedAllocations map[string]int
+
+	// Reasons is the diagnosis ordered by relevance, most relevant first.
+	Reasons []*JobExplainReason
+}
+
+// JobExplainReason is a single reason why allocations of a job are not placed
+type JobExplainReason struct {
+	// Type is the kind of the reason, one of the JobExplainReason constants
+	Type string
+
+	// TaskGroup is the task group the reason applies to, if any
+	TaskGroup string
+
+	// Message is the human readable description of the reason
+	Message string
+
+	// Nodes is the number of nodes the reason applies to, if any
+	Nodes int
+}
+
 // SingleAllocResponse is used to return a single allocation
 type SingleAllocResponse struct {
 	Alloc *Allocation
@@ -998,6 +1212,13 @@ type PeriodicForceResponse struct {
 	WriteMeta
 }
 
+// SinglePeriodicLaunchResponse is used to return the launch state of a
+// periodic job.
+type SinglePeriodicLaunchResponse struct {
+	Launch *PeriodicLaunch
+	QueryMeta
+}
+
 // DeploymentUpdateResponse is used to respond to a deployment change. The
 // response will include the modify index of the deployment as well as details
 // of any triggered evaluation.
@@ -1097,6 +1318,10 @@ type Node struct {
 	// together for the purpose of determining scheduling pressure.
 	NodeClass string
 
+	// NodePool is the node pool the node belongs to. Jobs are only placed
+	// on the nodes of their node pool.
+	NodePool string
+
 	// ComputedClass is a unique id that identifies nodes with a common set of
 	// attributes and capabilities.
 	ComputedClass string
@@ -1158,6 +1383,7 @@ func (n *Node) Stub() *NodeListStub {
 		Datacenter:        n.Datacenter,
 		Name:              n.Name,
 		NodeClass:         n.NodeClass,
+		NodePool:          n.NodePool,
 		Version:           n.Attributes["nomad.version"],
 		Drain:             n.Drain,
 		Status:            n.Status,
@@ -1174,6 +1400,7 @@ type NodeListStub struct {
 	Datacenter        string
 	Name              string
 	NodeClass         string
+	NodePool          string
 	Version           string
 	Drain             bool
 	Status            string
@@ -1185,6 +1412,19 @@ type NodeListStub struct {
 // Networks defined for a task on the Resources struct.
 type Networks []*NetworkResource
 
+// Copy returns a deep copy of the networks
+func (ns Networks) Copy() Networks {
+	if len(ns) == 0 {
+		return nil
+	}
+
+	out := make([]*NetworkResource, len(ns))
+	for i := range ns {
+		out[i] = ns[i].Copy()
+	}
+	return out
+}
+
 // Port assignment and IP for the given label or empty values.
 func (ns Networks) Port(label string) (string, int) {
 	for _, n := range ns {
@@ -1207,9 +1447,23 @@ func (ns Networks) Port(label string) (string, int) {
 type Resources struct {
 	CPU      int
 	MemoryMB int
+
+	// MemoryMaxMB is the hard memory limit of the task. The scheduler packs
+	// on MemoryMB and the task may use memory up to MemoryMaxMB when memory
+	// oversubscription is enabled.
+	MemoryMaxMB int
+
 	DiskMB   int
 	IOPS     int
 	Networks Networks
+
+	// Cores is the number of CPU cores to reserve for the exclusive use of
+	// the task. When set, the CPU of the task is derived from the cores.
+	Cores int
+
+	// CoreIDs are the IDs of CPU cores. For a node these are the cores that
+	// can be reserved. For a task these are the cores reserved for it.
+	CoreIDs []uint16
 }
 
 const (
@@ -1238,6 +1492,9 @@ func (r *Resources) Merge(other *Resources) {
 	if other.MemoryMB != 0 {
 		r.MemoryMB = other.MemoryMB
 	}
+	if other.MemoryMaxMB != 0 {
+		r.MemoryMaxMB = other.MemoryMaxMB
+	}
 	if other.DiskMB != 0 {
 		r.DiskMB = other.DiskMB
 	}
@@ -1247,6 +1504,9 @@ func (r *Resources) Merge(other *Resources) {
 	if len(other.Networks) != 0 {
 		r.Networks = other.Networks
 	}
+	if other.Cores != 0 {
+		r.Cores = other.Cores
+	}
 }
 
 func (r *Resources) Canonicalize() {
@@ -1271,9 +1531,15 @@ func (r *Resources) MeetsMinResources() error {
 	if r.MemoryMB < 10 {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum MemoryMB value is 10; got %d", r.MemoryMB))
 	}
+	if r.MemoryMaxMB != 0 && r.MemoryMaxMB < r.MemoryMB {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("MemoryMaxMB value (%d) must be greater than or equal to MemoryMB value (%d)", r.MemoryMaxMB, r.MemoryMB))
+	}
 	if r.IOPS < 0 {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum IOPS value is 0; got %d", r.IOPS))
 	}
+	if r.Cores < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum Cores value is 0; got %d", r.Cores))
+	}
 	for i, n := range r.Networks {
 		if err := n.MeetsMinResources(); err != nil {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("network resource at index %d failed: %v", i, err))
@@ -1297,6 +1563,10 @@ func (r *Resources) Copy() *Resources {
 			newR.Networks[i] = r.Networks[i].Copy()
 		}
 	}
+	if r.CoreIDs != nil {
+		newR.CoreIDs = make([]uint16, len(r.CoreIDs))
+		copy(newR.CoreIDs, r.CoreIDs)
+	}
 	return newR
 }
 
@@ -1311,8 +1581,8 @@ func (r *Resources) NetIndex(n *NetworkResource) int {
 }
 
 // Superset checks if one set of resources is a superset
-// of another. This ignores network resources, and the NetworkIndex
-// should be used for that.
+// of another. This ignores network resources and CPU cores, and the
+// NetworkIndex and CoreIndex should be used for those.
 func (r *Resources) Superset(other *Resources) (bool, string) {
 	if r.CPU < other.CPU {
 		return false, "cpu exhausted"
@@ -1339,6 +1609,7 @@ func (r *Resources) Add(delta *Resources) error {
 	r.MemoryMB += delta.MemoryMB
 	r.DiskMB += delta.DiskMB
 	r.IOPS += delta.IOPS
+	r.CoreIDs = append(r.CoreIDs, delta.CoreIDs...)
 
 	for _, n := range delta.Networks {
 		// Find the matching interface by IP or CIDR
@@ -1361,6 +1632,20 @@ type Port struct {
 	Value int
 }
 
+const (
+	// NetworkModeHost shares the host's network namespace with the tasks of
+	// a task group.
+	NetworkModeHost = "host"
+
+	// NetworkModeBridge places the tasks of a task group in a shared network
+	// namespace that is connected to a bridge on the host.
+	NetworkModeBridge = "bridge"
+
+	// NetworkModeNone places the tasks of a task group in a shared network
+	// namespace that only has a loopback interface.
+	NetworkModeNone = "none"
+)
+
 // NetworkResource is used to represent available network
 // resources
 type NetworkResource struct {
@@ -1370,6 +1655,7 @@ type NetworkResource struct {
 	MBits         int    // Throughput
 	ReservedPorts []Port // Host Reserved ports
 	DynamicPorts  []Port // Host Dynamically assigned ports
+	Mode          string // Mode of the network; only used by task group networks
 }
 
 func (n *NetworkResource) Canonicalize() {
@@ -1441,16 +1727,18 @@ func (n *NetworkResource) PortLabels() map[string]int {
 const (
 	// JobTypeNomad is reserved for internal system tasks and is
 	// always handled by the CoreScheduler.
-	JobTypeCore    = "_core"
-	JobTypeService = "service"
-	JobTypeBatch   = "batch"
-	JobTypeSystem  = "system"
+	JobTypeCore     = "_core"
+	JobTypeService  = "service"
+	JobTypeBatch    = "batch"
+	JobTypeSystem   = "system"
+	JobTypeSysBatch = "sysbatch"
 )
 
 const (
 	JobStatusPending = "pending" // Pending means the job is waiting on scheduling
 	JobStatusRunning = "running" // Running means the job has non-terminal allocations
 	JobStatusDead    = "dead"    // Dead means all evaluation's and allocations are terminal
+	JobStatusQueued  = "queued"  // Queued means the dispatched job waits for its parent's running limit
 )
 
 const (
@@ -1474,6 +1762,74 @@ const (
 	JobTrackedVersions = 6
 )
 
+const (
+	JobSubmissionFormatHCL1 = "hcl1"
+	JobSubmissionFormatHCL2 = "hcl2"
+	JobSubmissionFormatJSON = "json"
+
+	// MaxJobSubmissionSize is the maximum size in bytes of the source and
+	// variables of a job submission. Larger submissions are not stored.
+	MaxJobSubmissionSize = 1024 * 1024
+)
+
+// JobSubmission is the original source of a job, as submitted by the user.
+// It is stored with the version of the job it produced and garbage collected
+// with it.
+type JobSubmission struct {
+	// Source is the contents of the job file
+	Source string
+
+	// Format is the format of the source, one of hcl1, hcl2 or json
+	Format string
+
+	// VariableFlags are the input variables set on the command line, by name
+	VariableFlags map[string]string
+
+	// Variables are the contents of the variable files
+	Variables string
+
+	// Namespace, JobID and Version identify the job version the submission
+	// produced. They are set by the server.
+	Namespace string
+	JobID     string
+	Version   uint64
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Size returns the size in bytes of the source and variables of the
+// submission.
+func (s *JobSubmission) Size() int {
+	size := len(s.Source) + len(s.Variables)
+	for k, v := range s.VariableFlags {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// Validate validates the submission.
+func (s *JobSubmission) Validate() error {
+	switch s.Format {
+	case JobSubmissionFormatHCL1, JobSubmissionFormatHCL2, JobSubmissionFormatJSON:
+	default:
+		return fmt.Errorf("invalid job submission format %q", s.Format)
+	}
+	return nil
+}
+
+// Copy returns a copy of the submission.
+func (s *JobSubmission) Copy() *JobSubmission {
+	if s == nil {
+		return nil
+	}
+
+	ns := new(JobSubmission)
+	*ns = *s
+	ns.VariableFlags = helper.CopyMapStringString(s.VariableFlags)
+	return ns
+}
+
 // Job is the scope of a scheduling request to Nomad. It is the largest
 // scoped object, and is a named collection of task groups. Each task group
 // is further composed of tasks. A task group (TG) is the unit of scheduling
@@ -1520,6 +1876,14 @@ type Job struct {
 	// Datacenters contains all the datacenters this job is allowed to span
 	Datacenters []string
 
+	// NodePool is the node pool the job is placed in. The job is only
+	// placed on nodes in the pool, unless the pool is the "all" pool.
+	NodePool string
+
+	// SchedulerAlgorithm overrides the algorithm used to score nodes for the
+	// job. When unset the configuration of the cluster and node pool is used.
+	SchedulerAlgorithm SchedulerAlgorithm
+
 	// Constraints can be specified at a job level and apply to
 	// all the task groups and tasks.
 	Constraints []*Constraint
@@ -1541,6 +1905,10 @@ type Job struct {
 	// Payload is the payload supplied when the job was dispatched.
 	Payload []byte
 
+	// DispatchIdempotencyToken is the idempotency token supplied when the job
+	// was dispatched.
+	DispatchIdempotencyToken string
+
 	// Meta is used to associate arbitrary metadata with this
 	// job. This is opaque to Nomad.
 	Meta map[string]string
@@ -1550,6 +1918,11 @@ type Job struct {
 	// transfer the token and is not stored after Job submission.
 	VaultToken string
 
+	// ConsulToken is the Consul token that proves the submitter of the job
+	// has access to register the job's services. This field is only used to
+	// transfer the token and is not stored after Job submission.
+	ConsulToken string
+
 	// Job status
 	Status string
 
@@ -1595,6 +1968,11 @@ func (j *Job) Canonicalize() (warnings error) {
 		j.Namespace = DefaultNamespace
 	}
 
+	// Ensure the job is in a node pool.
+	if j.NodePool == "" {
+		j.NodePool = NodePoolDefault
+	}
+
 	for _, tg := range j.TaskGroups {
 		tg.Canonicalize(j)
 	}
@@ -1610,7 +1988,7 @@ func (j *Job) Canonicalize() (warnings error) {
 	// COMPAT: Remove in 0.7.0
 	// Rewrite any job that has an update block with pre 0.6.0 syntax.
 	jobHasOldUpdate := j.Update.Stagger > 0 && j.Update.MaxParallel > 0
-	if jobHasOldUpdate && j.Type != JobTypeBatch {
+	if jobHasOldUpdate && j.Type != JobTypeBatch && j.Type != JobTypeSysBatch {
 		// Build an appropriate update block and copy it down to each task group
 		base := DefaultUpdateStrategy.Copy()
 		base.MaxParallel = j.Update.MaxParallel
@@ -1661,7 +2039,7 @@ func (j *Job) Canonicalize() (warnings error) {
 	// a release so we can't check in the task group since that may be new style
 	// but wouldn't capture the old style and we don't want to have duplicate
 	// warnings.
-	if j.Type == JobTypeBatch {
+	if j.Type == JobTypeBatch || j.Type == JobTypeSysBatch {
 		displayWarning := jobHasOldUpdate
 		j.Update.Stagger = 0
 		j.Update.MaxParallel = 0
@@ -1680,9 +2058,9 @@ func (j *Job) Canonicalize() (warnings error) {
 		}
 
 		if displayWarning {
-			w := "Update stanza is disallowed for batch jobs since v0.6.0. " +
-				"The update block has automatically been removed"
-			multierror.Append(&mErr, fmt.Errorf(w))
+			w := fmt.Sprintf("Update stanza is disallowed for %s jobs since v0.6.0. "+
+				"The update block has automatically been removed", j.Type)
+			multierror.Append(&mErr, errors.New(w))
 		}
 	}
 
@@ -1716,6 +2094,12 @@ func (j *Job) Copy() *Job {
 
 // Validate is used to sanity check a job input
 func (j *Job) Validate() error {
+	return j.ValidateWithSchedulers(nil)
+}
+
+// ValidateWithSchedulers is used to sanity check a job input, additionally
+// accepting the job types handled by the given scheduler plugins.
+func (j *Job) ValidateWithSchedulers(schedulerPlugins []string) error {
 	var mErr multierror.Error
 
 	if j.Region == "" {
@@ -1733,11 +2117,20 @@ func (j *Job) Validate() error {
 		mErr.Errors = append(mErr.Errors, errors.New("Job must be in a namespace"))
 	}
 	switch j.Type {
-	case JobTypeCore, JobTypeService, JobTypeBatch, JobTypeSystem:
+	case JobTypeCore, JobTypeService, JobTypeBatch, JobTypeSystem, JobTypeSysBatch:
 	case "":
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job type"))
 	default:
-		mErr.Errors = append(mErr.Errors, fmt.Errorf("Invalid job type: %q", j.Type))
+		custom := false
+		for _, name := range schedulerPlugins {
+			if j.Type == name {
+				custom = true
+				break
+			}
+		}
+		if !custom {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Invalid job type: %q", j.Type))
+		}
 	}
 	if j.Priority < JobMinPriority || j.Priority > JobMaxPriority {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("Job priority must be between [%d, %d]", JobMinPriority, JobMaxPriority))
@@ -1745,6 +2138,14 @@ func (j *Job) Validate() error {
 	if len(j.Datacenters) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job datacenters"))
 	}
+	if j.NodePool != "" {
+		if err := ValidateNodePoolName(j.NodePool); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	if err := ValidateSchedulerAlgorithm(j.SchedulerAlgorithm); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
 	if len(j.TaskGroups) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job task groups"))
 	}
@@ -1766,10 +2167,10 @@ func (j *Job) Validate() error {
 			taskGroups[tg.Name] = idx
 		}
 
-		if j.Type == "system" && tg.Count > 1 {
+		if (j.Type == JobTypeSystem || j.Type == JobTypeSysBatch) && tg.Count > 1 {
 			mErr.Errors = append(mErr.Errors,
-				fmt.Errorf("Job task group %s has count %d. Count cannot exceed 1 with system scheduler",
-					tg.Name, tg.Count))
+				fmt.Errorf("Job task group %s has count %d. Count cannot exceed 1 with %s scheduler",
+					tg.Name, tg.Count, j.Type))
 		}
 	}
 
@@ -1781,11 +2182,16 @@ func (j *Job) Validate() error {
 		}
 	}
 
-	// Validate periodic is only used with batch jobs.
+	// Validate the dependencies between task groups
+	if err := j.validateGroupDependencies(); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+
+	// Validate periodic is only used with batch and sysbatch jobs.
 	if j.IsPeriodic() && j.Periodic.Enabled {
-		if j.Type != JobTypeBatch {
+		if j.Type != JobTypeBatch && j.Type != JobTypeSysBatch {
 			mErr.Errors = append(mErr.Errors,
-				fmt.Errorf("Periodic can only be used with %q scheduler", JobTypeBatch))
+				fmt.Errorf("Periodic can only be used with %q or %q scheduler", JobTypeBatch, JobTypeSysBatch))
 		}
 
 		if err := j.Periodic.Validate(); err != nil {
@@ -1794,9 +2200,9 @@ func (j *Job) Validate() error {
 	}
 
 	if j.IsParameterized() {
-		if j.Type != JobTypeBatch {
+		if j.Type != JobTypeBatch && j.Type != JobTypeSysBatch {
 			mErr.Errors = append(mErr.Errors,
-				fmt.Errorf("Parameterized job can only be used with %q scheduler", JobTypeBatch))
+				fmt.Errorf("Parameterized job can only be used with %q or %q scheduler", JobTypeBatch, JobTypeSysBatch))
 		}
 
 		if err := j.ParameterizedJob.Validate(); err != nil {
@@ -1807,6 +2213,74 @@ func (j *Job) Validate() error {
 	return mErr.ErrorOrNil()
 }
 
+// validateGroupDependencies checks that every task group dependency refers to
+// a task group in the job and that the dependencies do not form a cycle.
+func (j *Job) validateGroupDependencies() error {
+	var mErr multierror.Error
+	groups := make(map[string]*TaskGroup, len(j.TaskGroups))
+	for _, tg := range j.TaskGroups {
+		groups[tg.Name] = tg
+	}
+
+	for _, tg := range j.TaskGroups {
+		for _, d := range tg.DependsOn {
+			if _, ok := groups[d.Group]; !ok {
+				mErr.Errors = append(mErr.Errors,
+					fmt.Errorf("Task group %q depends on unknown task group %q", tg.Name, d.Group))
+			}
+		}
+	}
+	if len(mErr.Errors) != 0 {
+		return mErr.ErrorOrNil()
+	}
+
+	// Walk the dependency graph depth first, tracking the groups on the
+	// current path to detect cycles.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(groups))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == name {
+					cycle := append(path[i:], name)
+					return fmt.Errorf("Task group dependency cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, d := range groups[name].DependsOn {
+			if d.Group == name {
+				// Self references are reported by the task group
+				continue
+			}
+			if err := visit(d.Group); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, tg := range j.TaskGroups {
+		if err := visit(tg.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Warnings returns a list of warnings that may be from dubious settings or
 // deprecation warnings.
 func (j *Job) Warnings() error {
@@ -1874,6 +2348,20 @@ func (j *Job) Stopped() bool {
 	return j == nil || j.Stop
 }
 
+// HasGroupDependency returns if any task group in the job depends on the
+// given task group
+func (j *Job) HasGroupDependency(group string) bool {
+	for _, tg := range j.TaskGroups {
+		for _, d := range tg.DependsOn {
+			if d.Group == group {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // HasUpdateStrategy returns if any task group in the job has an update strategy
 func (j *Job) HasUpdateStrategy() bool {
 	for _, tg := range j.TaskGroups {
@@ -1939,6 +2427,26 @@ func (j *Job) VaultPolicies() map[string]map[string]*Vault {
 	return policies
 }
 
+// ConsulServiceNames returns the sorted set of Consul service names
+// registered by the job's tasks.
+func (j *Job) ConsulServiceNames() []string {
+	set := make(map[string]struct{})
+	for _, tg := range j.TaskGroups {
+		for _, task := range tg.Tasks {
+			for _, service := range task.Services {
+				set[service.Name] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // RequiredSignals returns a mapping of task groups to tasks to their required
 // set of signals
 func (j *Job) RequiredSignals() map[string]map[string][]string {
@@ -2073,6 +2581,7 @@ type JobChildrenSummary struct {
 	Pending int64
 	Running int64
 	Dead    int64
+	Queued  int64
 }
 
 // Copy returns a new copy of a JobChildrenSummary
@@ -2095,6 +2604,7 @@ type TaskGroupSummary struct {
 	Running  int
 	Starting int
 	Lost     int
+	Unknown  int
 }
 
 const (
@@ -2218,6 +2728,19 @@ const (
 	PeriodicSpecTest = "_internal_test"
 )
 
+const (
+	// PeriodicCatchUpNone skips the launches missed while there was no leader.
+	PeriodicCatchUpNone = "none"
+
+	// PeriodicCatchUpLast launches only the most recent missed launch. This
+	// is the default.
+	PeriodicCatchUpLast = "last"
+
+	// PeriodicCatchUpAll launches every missed launch within the catch up
+	// window.
+	PeriodicCatchUpAll = "all"
+)
+
 // Periodic defines the interval a job should be run at.
 type PeriodicConfig struct {
 	// Enabled determines if the job should be run periodically.
@@ -2227,12 +2750,30 @@ type PeriodicConfig struct {
 	// on the SpecType.
 	Spec string
 
+	// Specs are additional specs the job should be run at. The job is
+	// launched at the earliest time matching any of Spec and Specs. They are
+	// only supported by the cron SpecType.
+	Specs []string
+
 	// SpecType defines the format of the spec.
 	SpecType string
 
 	// ProhibitOverlap enforces that spawned jobs do not run in parallel.
 	ProhibitOverlap bool
 
+	// CatchUp is the policy applied to the launches missed while there was
+	// no leader to launch them. It is one of none, last or all and defaults
+	// to last.
+	CatchUp string
+
+	// CatchUpWindow limits how far in the past missed launches are caught
+	// up. A zero window does not limit the catch up.
+	CatchUpWindow time.Duration
+
+	// Jitter is the maximum random delay added to each launch to spread the
+	// load of jobs launching at the same time.
+	Jitter time.Duration
+
 	// TimeZone is the user specified string that determines the time zone to
 	// launch against. The time zones must be specified from IANA Time Zone
 	// database, such as "America/New_York".
@@ -2250,6 +2791,7 @@ func (p *PeriodicConfig) Copy() *PeriodicConfig {
 	}
 	np := new(PeriodicConfig)
 	*np = *p
+	np.Specs = helper.CopySliceString(p.Specs)
 	return np
 }
 
@@ -2259,10 +2801,26 @@ func (p *PeriodicConfig) Validate() error {
 	}
 
 	var mErr multierror.Error
-	if p.Spec == "" {
+	if p.Spec == "" && len(p.Specs) == 0 {
 		multierror.Append(&mErr, fmt.Errorf("Must specify a spec"))
 	}
 
+	switch p.CatchUp {
+	case "", PeriodicCatchUpNone, PeriodicCatchUpLast:
+	case PeriodicCatchUpAll:
+		if p.CatchUpWindow == 0 {
+			multierror.Append(&mErr, fmt.Errorf("Catch up policy %q requires a catch up window", p.CatchUp))
+		}
+	default:
+		multierror.Append(&mErr, fmt.Errorf("Unknown catch up policy %q", p.CatchUp))
+	}
+	if p.CatchUpWindow < 0 {
+		multierror.Append(&mErr, fmt.Errorf("Catch up window must not be negative"))
+	}
+	if p.Jitter < 0 {
+		multierror.Append(&mErr, fmt.Errorf("Jitter must not be negative"))
+	}
+
 	// Check if we got a valid time zone
 	if p.TimeZone != "" {
 		if _, err := time.LoadLocation(p.TimeZone); err != nil {
@@ -2272,12 +2830,16 @@ func (p *PeriodicConfig) Validate() error {
 
 	switch p.SpecType {
 	case PeriodicSpecCron:
-		// Validate the cron spec
-		if _, err := cronexpr.Parse(p.Spec); err != nil {
-			multierror.Append(&mErr, fmt.Errorf("Invalid cron spec %q: %v", p.Spec, err))
+		// Validate the cron specs
+		for _, spec := range p.cronSpecs() {
+			if _, err := cronexpr.Parse(spec); err != nil {
+				multierror.Append(&mErr, fmt.Errorf("Invalid cron spec %q: %v", spec, err))
+			}
 		}
 	case PeriodicSpecTest:
-		// No-op
+		if len(p.Specs) != 0 {
+			multierror.Append(&mErr, fmt.Errorf("Multiple specs are only supported by the %q spec type", PeriodicSpecCron))
+		}
 	default:
 		multierror.Append(&mErr, fmt.Errorf("Unknown periodic specification type %q", p.SpecType))
 	}
@@ -2302,9 +2864,17 @@ func (p *PeriodicConfig) Canonicalize() {
 func (p *PeriodicConfig) Next(fromTime time.Time) time.Time {
 	switch p.SpecType {
 	case PeriodicSpecCron:
-		if e, err := cronexpr.Parse(p.Spec); err == nil {
-			return e.Next(fromTime)
+		var next time.Time
+		for _, spec := range p.cronSpecs() {
+			e, err := cronexpr.Parse(spec)
+			if err != nil {
+				continue
+			}
+			if n := e.Next(fromTime); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+				next = n
+			}
 		}
+		return next
 	case PeriodicSpecTest:
 		split := strings.Split(p.Spec, ",")
 		if len(split) == 1 && split[0] == "" {
@@ -2333,6 +2903,60 @@ func (p *PeriodicConfig) Next(fromTime time.Time) time.Time {
 	return time.Time{}
 }
 
+// cronSpecs returns all the cron specs of the config.
+func (p *PeriodicConfig) cronSpecs() []string {
+	specs := make([]string, 0, len(p.Specs)+1)
+	if p.Spec != "" {
+		specs = append(specs, p.Spec)
+	}
+	return append(specs, p.Specs...)
+}
+
+// MissedLaunches returns the launches after the last launch and before now
+// that should be caught up according to the catch up policy.
+func (p *PeriodicConfig) MissedLaunches(last, now time.Time) []time.Time {
+	if p.CatchUp == PeriodicCatchUpNone {
+		return nil
+	}
+
+	// Only consider launches within the catch up window
+	from := last
+	if p.CatchUpWindow > 0 {
+		if start := now.Add(-p.CatchUpWindow); start.After(from) {
+			from = start
+		}
+	}
+
+	if p.CatchUp == PeriodicCatchUpAll {
+		var missed []time.Time
+		for next := p.Next(from.In(p.GetLocation())); !next.IsZero() && next.Before(now); next = p.Next(next) {
+			missed = append(missed, next)
+		}
+		return missed
+	}
+
+	// Only the most recent launch is caught up. The last launch may be long
+	// ago, so rather than stepping through every launch since then, search
+	// windows ending at now that double in size until a launch is found.
+	for window := periodicCatchUpLastWindow; ; window *= 2 {
+		start := from
+		if window > 0 && window < now.Sub(from) {
+			start = now.Add(-window)
+		}
+
+		var latest time.Time
+		for next := p.Next(start.In(p.GetLocation())); !next.IsZero() && next.Before(now); next = p.Next(next) {
+			latest = next
+		}
+		if !latest.IsZero() {
+			return []time.Time{latest}
+		}
+		if start.Equal(from) {
+			return nil
+		}
+	}
+}
+
 // GetLocation returns the location to use for determining the time zone to run
 // the periodic job against.
 func (p *PeriodicConfig) GetLocation() *time.Location {
@@ -2344,6 +2968,12 @@ func (p *PeriodicConfig) GetLocation() *time.Location {
 	return time.UTC
 }
 
+const (
+	// periodicCatchUpLastWindow is the initial window searched for the most
+	// recent missed launch of a job with the "last" catch up policy.
+	periodicCatchUpLastWindow = time.Minute
+)
+
 const (
 	// PeriodicLaunchSuffix is the string appended to the periodic jobs ID
 	// when launching derived instances of it.
@@ -2355,6 +2985,7 @@ type PeriodicLaunch struct {
 	ID        string    // ID of the periodic job.
 	Namespace string    // Namespace of the periodic job
 	Launch    time.Time // The last launch time.
+	Paused    bool      // Whether the launches of the job are paused.
 
 	// Raft Indexes
 	CreateIndex uint64
@@ -2381,6 +3012,11 @@ type ParameterizedJobConfig struct {
 
 	// MetaOptional is metadata keys that may be specified by the dispatcher
 	MetaOptional []string
+
+	// MaxRunning is the maximum number of dispatched jobs that are pending or
+	// running at the same time. Further dispatched jobs are queued until a
+	// dispatched job completes. Zero means unlimited.
+	MaxRunning int
 }
 
 func (d *ParameterizedJobConfig) Validate() error {
@@ -2397,6 +3033,10 @@ func (d *ParameterizedJobConfig) Validate() error {
 		multierror.Append(&mErr, fmt.Errorf("Required and optional meta keys should be disjoint. Following keys exist in both: %v", offending))
 	}
 
+	if d.MaxRunning < 0 {
+		multierror.Append(&mErr, fmt.Errorf("Max running must not be negative: %d", d.MaxRunning))
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -2534,11 +3174,17 @@ func NewRestartPolicy(jobType string) *RestartPolicy {
 	case JobTypeService, JobTypeSystem:
 		rp := defaultServiceJobRestartPolicy
 		return &rp
-	case JobTypeBatch:
+	case JobTypeBatch, JobTypeSysBatch:
 		rp := defaultBatchJobRestartPolicy
 		return &rp
+	case JobTypeCore, "":
+		return nil
 	}
-	return nil
+
+	// Jobs handled by scheduler plugins get the batch policy, matching the
+	// defaults applied by the API.
+	rp := defaultBatchJobRestartPolicy
+	return &rp
 }
 
 // TaskGroup is an atomic unit of placement. Each task group belongs to
@@ -2568,6 +3214,20 @@ type TaskGroup struct {
 	// EphemeralDisk is the disk resources that the task group requests
 	EphemeralDisk *EphemeralDisk
 
+	// Networks are the network resources shared by all the tasks in the
+	// task group. Only a single network is currently supported.
+	Networks Networks
+
+	// DependsOn is the set of task groups in the same job that must reach
+	// the given condition before this task group is placed.
+	DependsOn []*TaskGroupDependency
+
+	// MaxClientDisconnect is how long the allocations of the task group are
+	// kept in the unknown state when their node stops heartbeating before
+	// they are considered lost and replaced. If nil they are lost as soon
+	// as the node is down.
+	MaxClientDisconnect *time.Duration
+
 	// Meta is used to associate arbitrary metadata with this
 	// task group. This is opaque to Nomad.
 	Meta map[string]string
@@ -2582,6 +3242,19 @@ func (tg *TaskGroup) Copy() *TaskGroup {
 	ntg.Update = ntg.Update.Copy()
 	ntg.Constraints = CopySliceConstraints(ntg.Constraints)
 	ntg.RestartPolicy = ntg.RestartPolicy.Copy()
+	ntg.Networks = tg.Networks.Copy()
+
+	if tg.DependsOn != nil {
+		deps := make([]*TaskGroupDependency, len(tg.DependsOn))
+		for i, d := range tg.DependsOn {
+			deps[i] = d.Copy()
+		}
+		ntg.DependsOn = deps
+	}
+
+	if tg.MaxClientDisconnect != nil {
+		ntg.MaxClientDisconnect = helper.TimeToPtr(*tg.MaxClientDisconnect)
+	}
 
 	if tg.Tasks != nil {
 		tasks := make([]*Task, len(ntg.Tasks))
@@ -2617,6 +3290,23 @@ func (tg *TaskGroup) Canonicalize(job *Job) {
 		tg.EphemeralDisk = DefaultEphemeralDisk()
 	}
 
+	if len(tg.Networks) == 0 {
+		tg.Networks = nil
+	}
+	for _, n := range tg.Networks {
+		n.Canonicalize()
+		if n.Mode == "" {
+			n.Mode = NetworkModeHost
+		}
+	}
+
+	if len(tg.DependsOn) == 0 {
+		tg.DependsOn = nil
+	}
+	for _, d := range tg.DependsOn {
+		d.Canonicalize()
+	}
+
 	for _, task := range tg.Tasks {
 		task.Canonicalize(job, tg)
 	}
@@ -2683,10 +3373,47 @@ func (tg *TaskGroup) Validate(j *Job) error {
 		}
 	}
 
+	// Validate the group network
+	if err := tg.validateNetworks(); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+
+	// Validate the group dependencies. Whether the referenced groups exist
+	// and are acyclic is checked at the job level.
+	if len(tg.DependsOn) > 0 && (j.Type == JobTypeSystem || j.Type == JobTypeSysBatch) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Job type %q does not allow depends_on", j.Type))
+	}
+	for idx, d := range tg.DependsOn {
+		if err := d.Validate(); err != nil {
+			outer := fmt.Errorf("Dependency %d validation failed: %v", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		} else if d.Group == tg.Name {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Task group %q can not depend on itself", tg.Name))
+		} else if d.Condition == TaskGroupDependencyComplete && j.Type != JobTypeBatch {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Dependency condition %q can only be used with %q scheduler",
+				d.Condition, JobTypeBatch))
+		}
+	}
+
+	// Validate the disconnect window. System and sysbatch jobs are placed on
+	// every node so their allocations are never replaced elsewhere.
+	if tg.MaxClientDisconnect != nil {
+		if j.Type == JobTypeSystem || j.Type == JobTypeSysBatch {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Job type %q does not allow max_client_disconnect", j.Type))
+		} else if *tg.MaxClientDisconnect < 0 {
+			mErr.Errors = append(mErr.Errors, errors.New("max_client_disconnect cannot be negative"))
+		}
+	}
+
 	// Check for duplicate tasks, that there is only leader task if any,
 	// and no duplicated static ports
 	tasks := make(map[string]int)
 	staticPorts := make(map[int]string)
+	for _, net := range tg.Networks {
+		for _, port := range net.ReservedPorts {
+			staticPorts[port.Value] = fmt.Sprintf("group:%s", port.Label)
+		}
+	}
 	leaderTasks := 0
 	for idx, task := range tg.Tasks {
 		if task.Name == "" {
@@ -2705,6 +3432,11 @@ func (tg *TaskGroup) Validate(j *Job) error {
 			continue
 		}
 
+		if mode := tg.NetworkMode(); mode != NetworkModeHost && len(task.Resources.Networks) > 0 {
+			err := fmt.Errorf("Task %s may not request a network when the group network mode is %q", task.Name, mode)
+			mErr.Errors = append(mErr.Errors, err)
+		}
+
 		for _, net := range task.Resources.Networks {
 			for _, port := range net.ReservedPorts {
 				if other, ok := staticPorts[port.Value]; ok {
@@ -2731,6 +3463,112 @@ func (tg *TaskGroup) Validate(j *Job) error {
 	return mErr.ErrorOrNil()
 }
 
+const (
+	// TaskGroupDependencyRunning is satisfied once all allocations of the
+	// depended on task group have started running.
+	TaskGroupDependencyRunning = "running"
+
+	// TaskGroupDependencyHealthy is satisfied once all allocations of the
+	// depended on task group are marked healthy by their deployment. Task
+	// groups without an update strategy are healthy once running.
+	TaskGroupDependencyHealthy = "healthy"
+
+	// TaskGroupDependencyComplete is satisfied once all allocations of the
+	// depended on task group have completed successfully. It may only be
+	// used by batch jobs.
+	TaskGroupDependencyComplete = "complete"
+)
+
+// TaskGroupDependency declares that a task group must not be placed until
+// another task group in the same job has reached the given condition.
+type TaskGroupDependency struct {
+	// Group is the name of the task group depended on.
+	Group string
+
+	// Condition is the state the depended on task group must reach.
+	Condition string
+}
+
+func (d *TaskGroupDependency) Copy() *TaskGroupDependency {
+	if d == nil {
+		return nil
+	}
+	nd := new(TaskGroupDependency)
+	*nd = *d
+	return nd
+}
+
+// Canonicalize sets the default condition of the dependency.
+func (d *TaskGroupDependency) Canonicalize() {
+	if d.Condition == "" {
+		d.Condition = TaskGroupDependencyRunning
+	}
+}
+
+// Validate is used to sanity check a task group dependency
+func (d *TaskGroupDependency) Validate() error {
+	var mErr multierror.Error
+	if d.Group == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing task group name"))
+	}
+	switch d.Condition {
+	case TaskGroupDependencyRunning, TaskGroupDependencyHealthy, TaskGroupDependencyComplete:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Condition must be %q, %q, or %q; not %q",
+			TaskGroupDependencyRunning, TaskGroupDependencyHealthy, TaskGroupDependencyComplete, d.Condition))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// validateNetworks validates the task group's shared network.
+func (tg *TaskGroup) validateNetworks() error {
+	if len(tg.Networks) == 0 {
+		return nil
+	}
+
+	var mErr multierror.Error
+	if len(tg.Networks) > 1 {
+		mErr.Errors = append(mErr.Errors, errors.New("Only one task group network may be specified"))
+	}
+
+	net := tg.Networks[0]
+	switch net.Mode {
+	case "", NetworkModeHost, NetworkModeBridge:
+	case NetworkModeNone:
+		if len(net.ReservedPorts) > 0 || len(net.DynamicPorts) > 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Network mode %q can not have ports", net.Mode))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Network mode must be %q, %q, or %q; not %q",
+			NetworkModeHost, NetworkModeBridge, NetworkModeNone, net.Mode))
+	}
+
+	labels := make(map[string]struct{})
+	for _, ports := range [][]Port{net.ReservedPorts, net.DynamicPorts} {
+		for _, port := range ports {
+			if port.Label == "" {
+				mErr.Errors = append(mErr.Errors, errors.New("Network port must have a label"))
+				continue
+			}
+			if _, ok := labels[port.Label]; ok {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("Network port label %q is defined more than once", port.Label))
+			}
+			labels[port.Label] = struct{}{}
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// NetworkMode returns the network mode of the task group. Task groups without
+// a network use the host's network.
+func (tg *TaskGroup) NetworkMode() string {
+	if len(tg.Networks) == 0 || tg.Networks[0].Mode == "" {
+		return NetworkModeHost
+	}
+	return tg.Networks[0].Mode
+}
+
 // Warnings returns a list of warnings that may be from dubious settings or
 // deprecation warnings.
 func (tg *TaskGroup) Warnings(j *Job) error {
@@ -2802,6 +3640,7 @@ const (
 	ServiceCheckHTTP   = "http"
 	ServiceCheckTCP    = "tcp"
 	ServiceCheckScript = "script"
+	ServiceCheckGRPC   = "grpc"
 
 	// minCheckInterval is the minimum check interval permitted.  Consul
 	// currently has its MinInterval set to 1s.  Mirror that here for
@@ -2817,7 +3656,7 @@ const (
 // Nomad registers for a Task
 type ServiceCheck struct {
 	Name          string              // Name of the check, defaults to id
-	Type          string              // Type of the check - tcp, http, docker and script
+	Type          string              // Type of the check - tcp, http, docker, script and grpc
 	Command       string              // Command is the command to run for script checks
 	Args          []string            // Args is a list of argumes for script checks
 	Path          string              // path of the health check url for http type check
@@ -2830,6 +3669,8 @@ type ServiceCheck struct {
 	Method        string              // HTTP Method to use (GET by default)
 	Header        map[string][]string // HTTP Headers for Consul to set when making HTTP checks
 	CheckRestart  *CheckRestart       // If and when a task should be restarted based on checks
+	GRPCService   string              // Service for GRPC checks
+	GRPCUseTLS    bool                // Whether or not to use TLS for GRPC checks
 }
 
 func (sc *ServiceCheck) Copy() *ServiceCheck {
@@ -2879,8 +3720,13 @@ func (sc *ServiceCheck) validate() error {
 		if sc.Command == "" {
 			return fmt.Errorf("script type must have a valid script path")
 		}
+	case ServiceCheckGRPC:
 	default:
-		return fmt.Errorf(`invalid type (%+q), must be one of "http", "tcp", or "script" type`, sc.Type)
+		return fmt.Errorf(`invalid type (%+q), must be one of "http", "tcp", "script", or "grpc" type`, sc.Type)
+	}
+
+	if strings.ToLower(sc.Type) != ServiceCheckGRPC && (sc.GRPCService != "" || sc.GRPCUseTLS) {
+		return fmt.Errorf("grpc_service and grpc_use_tls may only be set on grpc type checks")
 	}
 
 	if sc.Interval == 0 {
@@ -2912,7 +3758,7 @@ func (sc *ServiceCheck) validate() error {
 // RequiresPort returns whether the service check requires the task has a port.
 func (sc *ServiceCheck) RequiresPort() bool {
 	switch sc.Type {
-	case ServiceCheckHTTP, ServiceCheckTCP:
+	case ServiceCheckHTTP, ServiceCheckTCP, ServiceCheckGRPC:
 		return true
 	default:
 		return false
@@ -2947,6 +3793,15 @@ func (sc *ServiceCheck) Hash(serviceID string) string {
 		io.WriteString(h, "true")
 	}
 
+	// Only include the GRPC fields if set to maintain ID stability with
+	// checks registered by older versions of Nomad
+	if sc.GRPCService != "" {
+		io.WriteString(h, sc.GRPCService)
+	}
+	if sc.GRPCUseTLS {
+		io.WriteString(h, "grpc-tls")
+	}
+
 	// Since map iteration order isn't stable we need to write k/v pairs to
 	// a slice and sort it before hashing.
 	if len(sc.Header) > 0 {
@@ -3101,8 +3956,25 @@ const (
 type LogConfig struct {
 	MaxFiles      int
 	MaxFileSizeMB int
+
+	// RotationInterval is how often the log files are rotated regardless of
+	// their size. Zero disables rotating on an interval.
+	RotationInterval time.Duration
+
+	// Compress enables gzip compression of rotated log files
+	Compress bool
+
+	// MaxTotalSizeMB caps the total size of the stdout and stderr log files
+	// of the task. Zero disables the cap.
+	MaxTotalSizeMB int
 }
 
+const (
+	// MinLogRotationInterval is the minimum interval log files can be
+	// rotated on
+	MinLogRotationInterval = time.Minute
+)
+
 // DefaultLogConfig returns the default LogConfig values.
 func DefaultLogConfig() *LogConfig {
 	return &LogConfig{
@@ -3121,6 +3993,114 @@ func (l *LogConfig) Validate() error {
 	if l.MaxFileSizeMB < 1 {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum file size is 1MB; got %d", l.MaxFileSizeMB))
 	}
+	if l.RotationInterval != 0 && l.RotationInterval < MinLogRotationInterval {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum rotation interval is %v; got %v", MinLogRotationInterval, l.RotationInterval))
+	}
+	if l.MaxTotalSizeMB < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("max total size can't be negative; got %d", l.MaxTotalSizeMB))
+	} else if l.MaxTotalSizeMB != 0 && l.MaxTotalSizeMB < 2*l.MaxFileSizeMB {
+		// The cap must fit the file each of stdout and stderr is writing to
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("max total size must be at least twice the file size (%d MB); got %d", 2*l.MaxFileSizeMB, l.MaxTotalSizeMB))
+	}
+	return mErr.ErrorOrNil()
+}
+
+const (
+	// LogShipperTypeSyslog ships log lines as RFC5424 syslog messages
+	LogShipperTypeSyslog = "syslog"
+
+	// LogShipperTypeJSON ships log lines as newline delimited JSON objects
+	// over TCP
+	LogShipperTypeJSON = "json"
+
+	// LogShipperTypeSocket ships log lines as journald native protocol
+	// datagrams to a local Unix socket
+	LogShipperTypeSocket = "socket"
+
+	// DefaultLogShipperBufferSize is the default number of log lines a
+	// shipper buffers before dropping lines
+	DefaultLogShipperBufferSize = 1024
+
+	// DefaultLogShipperSocket is the default socket of the socket log
+	// shipper
+	DefaultLogShipperSocket = "/run/systemd/journal/socket"
+)
+
+// LogShipper ships the task's stdout and stderr to a sink in addition to
+// the rotated log files
+type LogShipper struct {
+	// Name is the unique name of the shipper within the task
+	Name string
+
+	// Type is the type of the sink
+	Type string
+
+	// Address is the address of the sink. For the socket type it is the path
+	// of the socket.
+	Address string
+
+	// Protocol is the transport protocol used by the syslog type, either tcp
+	// or udp
+	Protocol string
+
+	// BufferSize is the number of log lines buffered for the sink. Lines are
+	// dropped when the buffer is full so a slow sink never blocks the task.
+	BufferSize int
+}
+
+// Copy returns a copy of the log shipper
+func (l *LogShipper) Copy() *LogShipper {
+	if l == nil {
+		return nil
+	}
+	nl := new(LogShipper)
+	*nl = *l
+	return nl
+}
+
+// Canonicalize sets the defaults of the log shipper
+func (l *LogShipper) Canonicalize() {
+	if l.BufferSize == 0 {
+		l.BufferSize = DefaultLogShipperBufferSize
+	}
+	switch l.Type {
+	case LogShipperTypeSyslog:
+		if l.Protocol == "" {
+			l.Protocol = "tcp"
+		}
+	case LogShipperTypeSocket:
+		if l.Address == "" {
+			l.Address = DefaultLogShipperSocket
+		}
+	}
+}
+
+// Validate returns an error if the log shipper is invalid
+func (l *LogShipper) Validate() error {
+	var mErr multierror.Error
+	if l.Name == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing name"))
+	}
+	if l.Address == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing address"))
+	}
+	if l.BufferSize < 1 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("minimum buffer size is 1; got %d", l.BufferSize))
+	}
+
+	switch l.Type {
+	case LogShipperTypeSyslog:
+		if l.Protocol != "tcp" && l.Protocol != "udp" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("syslog protocol must be tcp or udp; got %q", l.Protocol))
+		}
+	case LogShipperTypeJSON, LogShipperTypeSocket:
+		if l.Protocol != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("protocol can only be set for the %q type", LogShipperTypeSyslog))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid type %q; must be one of %q, %q or %q",
+			l.Type, LogShipperTypeSyslog, LogShipperTypeJSON, LogShipperTypeSocket))
+	}
 	return mErr.ErrorOrNil()
 }
 
@@ -3173,6 +4153,9 @@ type Task struct {
 	// LogConfig provides configuration for log rotation
 	LogConfig *LogConfig
 
+	// LogShippers ship the task's logs to sinks in addition to the log files
+	LogShippers []*LogShipper
+
 	// Artifacts is a list of artifacts to download and extract before running
 	// the task.
 	Artifacts []*TaskArtifact
@@ -3231,6 +4214,14 @@ func (t *Task) Copy() *Task {
 		nt.Templates = templates
 	}
 
+	if t.LogShippers != nil {
+		shippers := make([]*LogShipper, len(t.LogShippers))
+		for i, ls := range nt.LogShippers {
+			shippers[i] = ls.Copy()
+		}
+		nt.LogShippers = shippers
+	}
+
 	return nt
 }
 
@@ -3271,6 +4262,10 @@ func (t *Task) Canonicalize(job *Job, tg *TaskGroup) {
 	for _, template := range t.Templates {
 		template.Canonicalize()
 	}
+
+	for _, shipper := range t.LogShippers {
+		shipper.Canonicalize()
+	}
 }
 
 func (t *Task) GoString() string {
@@ -3319,6 +4314,20 @@ func (t *Task) Validate(ephemeralDisk *EphemeralDisk) error {
 		mErr.Errors = append(mErr.Errors, err)
 	}
 
+	// Validate the log shippers
+	shipperNames := make(map[string]struct{}, len(t.LogShippers))
+	for idx, shipper := range t.LogShippers {
+		if err := shipper.Validate(); err != nil {
+			outer := fmt.Errorf("Log shipper %d validation failed: %s", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+
+		if _, ok := shipperNames[shipper.Name]; ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Log shipper %q is duplicate", shipper.Name))
+		}
+		shipperNames[shipper.Name] = struct{}{}
+	}
+
 	for idx, constr := range t.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -3339,6 +4348,9 @@ func (t *Task) Validate(ephemeralDisk *EphemeralDisk) error {
 
 	if t.LogConfig != nil && ephemeralDisk != nil {
 		logUsage := (t.LogConfig.MaxFiles * t.LogConfig.MaxFileSizeMB)
+		if cap := t.LogConfig.MaxTotalSizeMB; cap != 0 && cap < logUsage {
+			logUsage = cap
+		}
 		if ephemeralDisk.SizeMB <= logUsage {
 			mErr.Errors = append(mErr.Errors,
 				fmt.Errorf("log storage (%d MB) must be less than requested disk capacity (%d MB)",
@@ -3779,7 +4791,7 @@ type TaskEvent struct {
 	// Validation fields
 	ValidationError string // Validation error
 
-	// The maximum allowed task disk size.
+	// The maximum allowed task disk size in bytes.
 	DiskLimit int64
 
 	// Name of the sibling task that caused termination of the task that
@@ -4212,6 +5224,18 @@ type Vault struct {
 	// Policies is the set of policies that the task needs access to
 	Policies []string
 
+	// Role is the Vault token role the task's token is created from. If
+	// empty the role the servers are configured with is used.
+	Role string
+
+	// EntityAlias is the name of the Vault entity alias the task's token is
+	// attached to. It may reference the job, group and task names.
+	EntityAlias string
+
+	// TTL is the initial TTL of the task's token. If zero the default of the
+	// servers is used.
+	TTL time.Duration
+
 	// Env marks whether the Vault Token should be exposed as an environment
 	// variable
 	Env bool
@@ -4266,6 +5290,14 @@ func (v *Vault) Validate() error {
 		}
 	}
 
+	if strings.Contains(v.Role, "/") {
+		multierror.Append(&mErr, fmt.Errorf("Invalid role %q", v.Role))
+	}
+
+	if v.TTL < 0 {
+		multierror.Append(&mErr, fmt.Errorf("TTL must be non-negative"))
+	}
+
 	switch v.ChangeMode {
 	case VaultChangeModeSignal:
 		if v.ChangeSignal == "" {
@@ -4508,6 +5540,7 @@ const (
 	AllocClientStatusComplete = "complete"
 	AllocClientStatusFailed   = "failed"
 	AllocClientStatusLost     = "lost"
+	AllocClientStatusUnknown  = "unknown"
 )
 
 // Allocation is used to allocate the placement of a task group to a node.
@@ -4906,6 +5939,11 @@ const (
 	EvalStatusComplete  = "complete"
 	EvalStatusFailed    = "failed"
 	EvalStatusCancelled = "canceled"
+
+	// EvalStatusQueued is the status of the evaluation of a dispatched job
+	// waiting for the running limit of its parameterized job. The leader
+	// moves it to pending once a running child completes.
+	EvalStatusQueued = "queued"
 )
 
 const (
@@ -4918,6 +5956,8 @@ const (
 	EvalTriggerDeploymentWatcher = "deployment-watcher"
 	EvalTriggerFailedFollowUp    = "failed-follow-up"
 	EvalTriggerMaxPlans          = "max-plan-attempts"
+	EvalTriggerGroupDependency   = "group-dependency"
+	EvalTriggerMaxDisconnect     = "max-disconnect-timeout"
 )
 
 const (
@@ -5102,7 +6142,7 @@ func (e *Evaluation) ShouldEnqueue() bool {
 	switch e.Status {
 	case EvalStatusPending:
 		return true
-	case EvalStatusComplete, EvalStatusFailed, EvalStatusBlocked, EvalStatusCancelled:
+	case EvalStatusComplete, EvalStatusFailed, EvalStatusBlocked, EvalStatusCancelled, EvalStatusQueued:
 		return false
 	default:
 		panic(fmt.Sprintf("unhandled evaluation (%s) status %s", e.ID, e.Status))
@@ -5115,7 +6155,7 @@ func (e *Evaluation) ShouldBlock() bool {
 	switch e.Status {
 	case EvalStatusBlocked:
 		return true
-	case EvalStatusComplete, EvalStatusFailed, EvalStatusPending, EvalStatusCancelled:
+	case EvalStatusComplete, EvalStatusFailed, EvalStatusPending, EvalStatusCancelled, EvalStatusQueued:
 		return false
 	default:
 		panic(fmt.Sprintf("unhandled evaluation (%s) status %s", e.ID, e.Status))
@@ -5154,6 +6194,23 @@ func (e *Evaluation) NextRollingEval(wait time.Duration) *Evaluation {
 	}
 }
 
+// NextDisconnectEval creates an evaluation to followup this eval once the
+// max_client_disconnect window of allocations on disconnected nodes expires.
+func (e *Evaluation) NextDisconnectEval(wait time.Duration) *Evaluation {
+	return &Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      e.Namespace,
+		Priority:       e.Priority,
+		Type:           e.Type,
+		TriggeredBy:    EvalTriggerMaxDisconnect,
+		JobID:          e.JobID,
+		JobModifyIndex: e.JobModifyIndex,
+		Status:         EvalStatusPending,
+		Wait:           wait,
+		PreviousEval:   e.ID,
+	}
+}
+
 // CreateBlockedEval creates a blocked evaluation to followup this eval to place any
 // failed allocations. It takes the classes marked explicitly eligible or
 // ineligible and whether the job has escaped computed node classes.
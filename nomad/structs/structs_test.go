@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +52,12 @@ func TestJob_Validate(t *testing.T) {
 		t.Errorf("expected %s but found: %v", expected, err)
 	}
 
+	// Job types of scheduler plugins are accepted
+	err = j.ValidateWithSchedulers([]string{"invalid-job-type"})
+	if strings.Contains(err.Error(), "Invalid job type") {
+		t.Errorf("unexpected job type error: %v", err)
+	}
+
 	j = &Job{
 		Type: JobTypeService,
 		Periodic: &PeriodicConfig{
@@ -181,6 +188,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeService,
 				Update: UpdateStrategy{
 					MaxParallel: 2,
@@ -224,6 +232,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeBatch,
 				Update:    UpdateStrategy{},
 				TaskGroups: []*TaskGroup{
@@ -267,6 +276,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeBatch,
 				Update:    UpdateStrategy{},
 				TaskGroups: []*TaskGroup{
@@ -309,6 +319,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeService,
 				Update: UpdateStrategy{
 					Stagger:         2 * time.Second,
@@ -355,6 +366,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeService,
 				Update: UpdateStrategy{
 					MaxParallel: 200,
@@ -406,6 +418,7 @@ func TestJob_Canonicalize_Update(t *testing.T) {
 			},
 			Expected: &Job{
 				Namespace: "test",
+				NodePool:  NodePoolDefault,
 				Type:      JobTypeService,
 				Update: UpdateStrategy{
 					MaxParallel: 2,
@@ -657,6 +670,145 @@ func TestJob_SystemJob_Validate(t *testing.T) {
 	}
 }
 
+func TestJob_SysBatchJob_Validate(t *testing.T) {
+	j := testJob()
+	j.Type = JobTypeSysBatch
+	j.TaskGroups[0].Update = DefaultUpdateStrategy.Copy()
+	j.Canonicalize()
+
+	// The update stanza is removed
+	if j.TaskGroups[0].Update != nil {
+		t.Fatalf("expected update stanza to be removed: %v", j.TaskGroups[0].Update)
+	}
+
+	err := j.Validate()
+	if err == nil || !strings.Contains(err.Error(), "exceed 1 with sysbatch scheduler") {
+		t.Fatalf("expect error due to count: %v", err)
+	}
+
+	j.TaskGroups[0].Count = 1
+	if err := j.Validate(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Sysbatch jobs may be periodic
+	j.Periodic = &PeriodicConfig{
+		Enabled:  true,
+		SpecType: PeriodicSpecCron,
+		Spec:     "0 * * * *",
+	}
+	if err := j.Validate(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Sysbatch jobs may be parameterized
+	j.Periodic = nil
+	j.ParameterizedJob = &ParameterizedJobConfig{
+		Payload: DispatchPayloadOptional,
+	}
+	if err := j.Validate(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestJob_Validate_GroupDependencies(t *testing.T) {
+	newJob := func(names ...string) *Job {
+		j := testJob()
+		base := j.TaskGroups[0]
+		j.TaskGroups = nil
+		for _, name := range names {
+			tg := base.Copy()
+			tg.Name = name
+			j.TaskGroups = append(j.TaskGroups, tg)
+		}
+		return j
+	}
+	dependsOn := func(j *Job, group, other, condition string) {
+		tg := j.LookupTaskGroup(group)
+		tg.DependsOn = append(tg.DependsOn, &TaskGroupDependency{Group: other, Condition: condition})
+	}
+
+	// A valid chain of dependencies
+	j := newJob("db", "migrate", "web")
+	j.Type = JobTypeBatch
+	dependsOn(j, "migrate", "db", TaskGroupDependencyHealthy)
+	dependsOn(j, "web", "migrate", TaskGroupDependencyComplete)
+	dependsOn(j, "web", "db", "")
+	j.Canonicalize()
+	if err := j.Validate(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if c := j.LookupTaskGroup("web").DependsOn[1].Condition; c != TaskGroupDependencyRunning {
+		t.Fatalf("expected default condition %q; got %q", TaskGroupDependencyRunning, c)
+	}
+
+	cases := []struct {
+		name     string
+		setup    func(j *Job)
+		expected string
+	}{
+		{
+			name: "unknown group",
+			setup: func(j *Job) {
+				dependsOn(j, "web", "cache", TaskGroupDependencyRunning)
+			},
+			expected: `unknown task group "cache"`,
+		},
+		{
+			name: "self reference",
+			setup: func(j *Job) {
+				dependsOn(j, "web", "web", TaskGroupDependencyRunning)
+			},
+			expected: "depend on itself",
+		},
+		{
+			name: "invalid condition",
+			setup: func(j *Job) {
+				dependsOn(j, "web", "db", "started")
+			},
+			expected: `not "started"`,
+		},
+		{
+			name: "complete service group",
+			setup: func(j *Job) {
+				dependsOn(j, "web", "migrate", TaskGroupDependencyComplete)
+			},
+			expected: "can only be used with",
+		},
+		{
+			name: "cycle",
+			setup: func(j *Job) {
+				dependsOn(j, "db", "web", TaskGroupDependencyRunning)
+				dependsOn(j, "migrate", "db", TaskGroupDependencyRunning)
+				dependsOn(j, "web", "migrate", TaskGroupDependencyRunning)
+			},
+			expected: "db -> web -> migrate -> db",
+		},
+		{
+			name: "system job",
+			setup: func(j *Job) {
+				j.Type = JobTypeSystem
+				for _, tg := range j.TaskGroups {
+					tg.Count = 1
+				}
+				dependsOn(j, "web", "db", TaskGroupDependencyRunning)
+			},
+			expected: "does not allow depends_on",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			j := newJob("db", "migrate", "web")
+			c.setup(j)
+			err := j.Validate()
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Fatalf("expected error containing %q; got %v", c.expected, err)
+			}
+		})
+	}
+}
+
 func TestJob_VaultPolicies(t *testing.T) {
 	j0 := &Job{}
 	e0 := make(map[string]map[string]*Vault, 0)
@@ -740,6 +892,23 @@ func TestJob_VaultPolicies(t *testing.T) {
 	}
 }
 
+func TestJob_ConsulServiceNames(t *testing.T) {
+	j := testJob()
+	j.TaskGroups[0].Tasks[0].Services = []*Service{
+		{Name: "web"},
+		{Name: "admin"},
+	}
+	tg := j.TaskGroups[0].Copy()
+	tg.Name = "other"
+	tg.Tasks[0].Services = []*Service{{Name: "web"}}
+	j.TaskGroups = append(j.TaskGroups, tg)
+
+	expected := []string{"admin", "web"}
+	if act := j.ConsulServiceNames(); !reflect.DeepEqual(act, expected) {
+		t.Fatalf("got %v; want %v", act, expected)
+	}
+}
+
 func TestJob_RequiredSignals(t *testing.T) {
 	j0 := &Job{}
 	e0 := make(map[string]map[string][]string, 0)
@@ -945,6 +1114,96 @@ func TestTaskGroup_Validate(t *testing.T) {
 	//}
 }
 
+func TestTaskGroup_Validate_Networks(t *testing.T) {
+	tg := &TaskGroup{
+		Networks: []*NetworkResource{
+			{
+				Mode:          "foo",
+				ReservedPorts: []Port{{Label: "http", Value: 8080}},
+				DynamicPorts:  []Port{{Label: "http"}},
+			},
+			{},
+		},
+	}
+	err := tg.validateNetworks()
+	for _, expected := range []string{
+		"Only one task group network may be specified",
+		`Network mode must be "host", "bridge", or "none"; not "foo"`,
+		`Network port label "http" is defined more than once`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q but found: %v", expected, err)
+		}
+	}
+
+	tg = &TaskGroup{
+		Networks: []*NetworkResource{
+			{
+				Mode:         NetworkModeNone,
+				DynamicPorts: []Port{{Label: "http"}},
+			},
+		},
+	}
+	err = tg.validateNetworks()
+	if err == nil || !strings.Contains(err.Error(), "can not have ports") {
+		t.Errorf("expected ports error but found: %v", err)
+	}
+
+	tg = &TaskGroup{
+		Networks: []*NetworkResource{
+			{
+				Mode:          NetworkModeBridge,
+				ReservedPorts: []Port{{Label: "http", Value: 8080}},
+			},
+		},
+		Tasks: []*Task{
+			{
+				Name: "task-a",
+				Resources: &Resources{
+					Networks: []*NetworkResource{
+						{
+							ReservedPorts: []Port{{Label: "foo", Value: 8080}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if mode := tg.NetworkMode(); mode != NetworkModeBridge {
+		t.Fatalf("expected bridge network mode but found %q", mode)
+	}
+	err = tg.Validate(&Job{})
+	for _, expected := range []string{
+		`Task task-a may not request a network when the group network mode is "bridge"`,
+		"Static port 8080 already reserved by group:http",
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q but found: %v", expected, err)
+		}
+	}
+}
+
+func TestTaskGroup_Validate_MaxClientDisconnect(t *testing.T) {
+	j := testJob()
+	tg := j.TaskGroups[0]
+	tg.MaxClientDisconnect = helper.TimeToPtr(-1 * time.Minute)
+	err := tg.Validate(j)
+	if err == nil || !strings.Contains(err.Error(), "max_client_disconnect cannot be negative") {
+		t.Fatalf("expected negative window error but found: %v", err)
+	}
+
+	tg.MaxClientDisconnect = helper.TimeToPtr(5 * time.Minute)
+	if err := tg.Validate(j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	j.Type = JobTypeSystem
+	err = tg.Validate(j)
+	if err == nil || !strings.Contains(err.Error(), "does not allow max_client_disconnect") {
+		t.Fatalf("expected system job error but found: %v", err)
+	}
+}
+
 func TestTask_Validate(t *testing.T) {
 	task := &Task{}
 	ephemeralDisk := DefaultEphemeralDisk()
@@ -1155,6 +1414,31 @@ func TestTask_Validate_Service_Check(t *testing.T) {
 	}
 }
 
+func TestTask_Validate_Service_Check_GRPC(t *testing.T) {
+	check := ServiceCheck{
+		Name:        "check-name",
+		Type:        ServiceCheckGRPC,
+		Interval:    10 * time.Second,
+		Timeout:     2 * time.Second,
+		GRPCService: "foo.Bar",
+		GRPCUseTLS:  true,
+	}
+
+	if err := check.validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !check.RequiresPort() {
+		t.Fatalf("expected grpc check to require a port")
+	}
+
+	check.Type = ServiceCheckTCP
+	err := check.validate()
+	if err == nil || !strings.Contains(err.Error(), "may only be set on grpc type checks") {
+		t.Fatalf("expected a grpc field validation error but received: %q", err)
+	}
+}
+
 func TestTask_Validate_Service_Check_CheckRestart(t *testing.T) {
 	invalidCheckRestart := &CheckRestart{
 		Limit: -1,
@@ -1188,6 +1472,103 @@ func TestTask_Validate_LogConfig(t *testing.T) {
 	}
 }
 
+func TestLogConfig_Validate(t *testing.T) {
+	l := DefaultLogConfig()
+	l.RotationInterval = 10 * time.Second
+	l.MaxTotalSizeMB = -1
+
+	err := l.Validate()
+	mErr := err.(*multierror.Error)
+	if len(mErr.Errors) != 2 {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[0].Error(), "rotation interval") {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(mErr.Errors[1].Error(), "negative") {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The total size must fit a file of each stream
+	l.RotationInterval = time.Hour
+	l.MaxTotalSizeMB = l.MaxFileSizeMB
+	if err := l.Validate(); err == nil || !strings.Contains(err.Error(), "twice the file size") {
+		t.Fatalf("expected total size error: %v", err)
+	}
+
+	l.MaxTotalSizeMB = 2 * l.MaxFileSizeMB
+	l.Compress = true
+	if err := l.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTask_Validate_LogConfig_TotalSize(t *testing.T) {
+	task := &Task{
+		LogConfig: DefaultLogConfig(),
+	}
+	task.LogConfig.MaxTotalSizeMB = 40
+
+	// The total size caps the storage the logs need
+	ephemeralDisk := &EphemeralDisk{
+		SizeMB: 50,
+	}
+	err := task.Validate(ephemeralDisk)
+	if err != nil && strings.Contains(err.Error(), "log storage") {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestTask_Validate_LogShippers(t *testing.T) {
+	task := &Task{
+		LogShippers: []*LogShipper{
+			{
+				Name:    "audit",
+				Type:    LogShipperTypeSyslog,
+				Address: "10.0.0.1:514",
+			},
+			{
+				Name: "journal",
+				Type: LogShipperTypeSocket,
+			},
+		},
+	}
+	task.Canonicalize(&Job{}, &TaskGroup{})
+
+	// The defaults make the shippers valid
+	for _, ls := range task.LogShippers {
+		if err := ls.Validate(); err != nil {
+			t.Fatalf("log shipper %q: %v", ls.Name, err)
+		}
+	}
+	if act := task.LogShippers[0].Protocol; act != "tcp" {
+		t.Fatalf("protocol: got %q; want tcp", act)
+	}
+	if act := task.LogShippers[1].Address; act != DefaultLogShipperSocket {
+		t.Fatalf("address: got %q; want %q", act, DefaultLogShipperSocket)
+	}
+
+	bad := []*LogShipper{
+		{Name: "a", Type: "kafka", Address: "foo", BufferSize: 1},
+		{Name: "b", Type: LogShipperTypeSyslog, Address: "foo", Protocol: "http", BufferSize: 1},
+		{Name: "c", Type: LogShipperTypeJSON, Address: "foo", Protocol: "udp", BufferSize: 1},
+		{Name: "d", Type: LogShipperTypeJSON, BufferSize: 1},
+		{Name: "e", Type: LogShipperTypeJSON, Address: "foo", BufferSize: -1},
+	}
+	for _, ls := range bad {
+		if err := ls.Validate(); err == nil {
+			t.Fatalf("log shipper %q should be invalid", ls.Name)
+		}
+	}
+
+	// Shipper names must be unique
+	task.LogShippers = append(task.LogShippers, task.LogShippers[0].Copy())
+	err := task.Validate(&EphemeralDisk{SizeMB: 1000})
+	if err == nil || !strings.Contains(err.Error(), `Log shipper "audit" is duplicate`) {
+		t.Fatalf("err: %v", err)
+	}
+}
+
 func TestTask_Validate_Template(t *testing.T) {
 
 	bad := &Template{}
@@ -1475,6 +1856,23 @@ func TestResource_NetIndex(t *testing.T) {
 	}
 }
 
+func TestResource_MeetsMinResources_MemoryMax(t *testing.T) {
+	r := &Resources{
+		CPU:         100,
+		MemoryMB:    256,
+		MemoryMaxMB: 512,
+	}
+	if err := r.MeetsMinResources(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	r.MemoryMaxMB = 128
+	err := r.MeetsMinResources()
+	if err == nil || !strings.Contains(err.Error(), "MemoryMaxMB") {
+		t.Fatalf("expected memory max error; got %v", err)
+	}
+}
+
 func TestResource_Superset(t *testing.T) {
 	r1 := &Resources{
 		CPU:      2000,
@@ -1902,6 +2300,122 @@ func TestPeriodicConfig_NextCron(t *testing.T) {
 	}
 }
 
+func TestPeriodicConfig_NextMultipleCrons(t *testing.T) {
+	from := time.Date(2009, time.November, 10, 23, 22, 30, 0, time.UTC)
+	p := &PeriodicConfig{
+		Enabled:  true,
+		SpecType: PeriodicSpecCron,
+		Specs:    []string{"0 0 * * *", "*/15 * * * *"},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	p.Canonicalize()
+
+	expected := time.Date(2009, time.November, 10, 23, 30, 0, 0, time.UTC)
+	if n := p.Next(from); n != expected {
+		t.Fatalf("Next(%v) returned %v; want %v", from, n, expected)
+	}
+
+	from = time.Date(2009, time.November, 10, 23, 50, 0, 0, time.UTC)
+	expected = time.Date(2009, time.November, 11, 0, 0, 0, 0, time.UTC)
+	if n := p.Next(from); n != expected {
+		t.Fatalf("Next(%v) returned %v; want %v", from, n, expected)
+	}
+}
+
+func TestPeriodicConfig_InvalidCatchUp(t *testing.T) {
+	cases := []*PeriodicConfig{
+		{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", CatchUp: "foo"},
+		{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", CatchUp: PeriodicCatchUpAll},
+		{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", CatchUpWindow: -time.Hour},
+		{Enabled: true, SpecType: PeriodicSpecCron, Spec: "@hourly", Jitter: -time.Minute},
+		{Enabled: true, SpecType: PeriodicSpecCron, Specs: []string{"@hourly", "foo"}},
+	}
+	for i, p := range cases {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("case %d: Enabled PeriodicConfig with invalid settings should be invalid", i)
+		}
+	}
+}
+
+func TestPeriodicConfig_MissedLaunches(t *testing.T) {
+	last := time.Date(2009, time.November, 10, 20, 0, 0, 0, time.UTC)
+	now := time.Date(2009, time.November, 10, 23, 30, 0, 0, time.UTC)
+	launches := []time.Time{
+		time.Date(2009, time.November, 10, 21, 0, 0, 0, time.UTC),
+		time.Date(2009, time.November, 10, 22, 0, 0, 0, time.UTC),
+		time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	cases := []struct {
+		CatchUp  string
+		Window   time.Duration
+		Expected []time.Time
+	}{
+		{PeriodicCatchUpNone, 0, nil},
+		{PeriodicCatchUpLast, 0, launches[2:]},
+		{PeriodicCatchUpAll, 0, launches},
+		{PeriodicCatchUpAll, 100 * time.Minute, launches[1:]},
+		{PeriodicCatchUpLast, 10 * time.Minute, nil},
+	}
+	for _, c := range cases {
+		p := &PeriodicConfig{
+			Enabled:       true,
+			SpecType:      PeriodicSpecCron,
+			Spec:          "0 * * * *",
+			CatchUp:       c.CatchUp,
+			CatchUpWindow: c.Window,
+		}
+		p.Canonicalize()
+		if missed := p.MissedLaunches(last, now); !reflect.DeepEqual(missed, c.Expected) {
+			t.Fatalf("MissedLaunches(%q, %v) returned %v; want %v", c.CatchUp, c.Window, missed, c.Expected)
+		}
+	}
+}
+
+func TestPeriodicConfig_MissedLaunches_LastNoWindow(t *testing.T) {
+	now := time.Date(2009, time.November, 10, 23, 30, 30, 0, time.UTC)
+
+	cases := []struct {
+		Spec     string
+		Last     time.Time
+		Expected time.Time
+	}{
+		// A frequent spec whose last launch is long ago must not step through
+		// every launch since then.
+		{"* * * * * * *", time.Date(1979, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2009, time.November, 10, 23, 30, 29, 0, time.UTC)},
+		// The most recent launch lies beyond the initial search window.
+		{"0 0 1 * *", time.Date(2009, time.January, 15, 0, 0, 0, 0, time.UTC), time.Date(2009, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 * 2000", time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		p := &PeriodicConfig{
+			Enabled:  true,
+			SpecType: PeriodicSpecCron,
+			Spec:     c.Spec,
+			CatchUp:  PeriodicCatchUpLast,
+		}
+		p.Canonicalize()
+
+		start := time.Now()
+		missed := p.MissedLaunches(c.Last, now)
+		if expected := []time.Time{c.Expected}; !reflect.DeepEqual(missed, expected) {
+			t.Fatalf("MissedLaunches(%q) returned %v; want %v", c.Spec, missed, expected)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("MissedLaunches(%q) took %v", c.Spec, elapsed)
+		}
+	}
+
+	// No launch between the last launch and now
+	p := &PeriodicConfig{Enabled: true, SpecType: PeriodicSpecCron, Spec: "0 0 1 1 * 2000", CatchUp: PeriodicCatchUpLast}
+	p.Canonicalize()
+	if missed := p.MissedLaunches(time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC), now); missed != nil {
+		t.Fatalf("MissedLaunches returned %v; want none", missed)
+	}
+}
+
 func TestPeriodicConfig_ValidTimeZone(t *testing.T) {
 	zones := []string{"Africa/Abidjan", "America/Chicago", "Europe/Minsk", "UTC"}
 	for _, zone := range zones {
@@ -2221,6 +2735,34 @@ func TestVault_Validate(t *testing.T) {
 	if !strings.Contains(err.Error(), "root") {
 		t.Fatalf("Expected root error")
 	}
+
+	v.Role = "foo/bar"
+	v.TTL = -1 * time.Second
+	err = v.Validate()
+	if err == nil || !strings.Contains(err.Error(), "Invalid role") {
+		t.Fatalf("Expected role error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "TTL must be non-negative") {
+		t.Fatalf("Expected TTL error: %v", err)
+	}
+}
+
+func TestVaultRolesSet(t *testing.T) {
+	job := testJob()
+	task := job.TaskGroups[0].Tasks[0]
+	task.Vault = &Vault{Policies: []string{"foo"}, Role: "web"}
+	other := task.Copy()
+	other.Name = "other"
+	other.Vault.Role = "api"
+	noRole := task.Copy()
+	noRole.Name = "no-role"
+	noRole.Vault.Role = ""
+	job.TaskGroups[0].Tasks = append(job.TaskGroups[0].Tasks, other, noRole)
+
+	roles := VaultRolesSet(job.VaultPolicies())
+	if !reflect.DeepEqual(roles, []string{"api", "web"}) {
+		t.Fatalf("bad: %v", roles)
+	}
 }
 
 func TestParameterizedJobConfig_Validate(t *testing.T) {
@@ -2239,6 +2781,13 @@ func TestParameterizedJobConfig_Validate(t *testing.T) {
 	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "disjoint") {
 		t.Fatalf("Expected meta not being disjoint error: %v", err)
 	}
+
+	d.MetaRequired = nil
+	d.MaxRunning = -1
+
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "Max running") {
+		t.Fatalf("Expected negative max running error: %v", err)
+	}
 }
 
 func TestParameterizedJobConfig_Validate_NonBatch(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"log"
 	"math/rand"
 	"regexp"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -65,6 +66,9 @@ const (
 	// vaultRoleLookupPath is the path to lookup a role
 	vaultRoleLookupPath = "auth/token/roles/%s"
 
+	// vaultTokenCreatePath is the path to create a token
+	vaultTokenCreatePath = "auth/token/create"
+
 	// vaultRoleCreatePath is the path to create a token from a role
 	vaultTokenRoleCreatePath = "auth/token/create/%s"
 )
@@ -120,6 +124,10 @@ type VaultClient interface {
 	// LookupToken takes a token string and returns its capabilities.
 	LookupToken(ctx context.Context, token string) (*vapi.Secret, error)
 
+	// CheckTokenRole returns an error if the token can not create tokens
+	// from the given token role.
+	CheckTokenRole(ctx context.Context, token, role string) error
+
 	// RevokeTokens takes a set of tokens accessor and revokes the tokens
 	RevokeTokens(ctx context.Context, accessors []*structs.VaultAccessor, committed bool) error
 
@@ -560,15 +568,13 @@ func (v *vaultClient) renew() error {
 
 // getWrappingFn returns an appropriate wrapping function for Nomad Servers
 func (v *vaultClient) getWrappingFn() func(operation, path string) string {
-	createPath := "auth/token/create"
-	role := v.getRole()
-	if role != "" {
-		createPath = fmt.Sprintf("auth/token/create/%s", role)
-	}
-
 	return func(operation, path string) string {
-		// Only wrap the token create operation
-		if operation != "POST" || path != createPath {
+		// Only wrap the token create operations. Tasks may create tokens
+		// from their own role so any role is wrapped.
+		if operation != "POST" {
+			return ""
+		}
+		if path != vaultTokenCreatePath && !strings.HasPrefix(path, vaultTokenCreatePath+"/") {
 			return ""
 		}
 
@@ -865,6 +871,21 @@ func (v *vaultClient) CreateToken(ctx context.Context, a *structs.Allocation, ta
 		return nil, fmt.Errorf("Task does not require Vault policies")
 	}
 
+	// Tasks may override the role and TTL of the token
+	role := taskVault.Role
+	if role == "" {
+		role = v.getRole()
+	}
+	ttl := v.childTTL
+	if taskVault.TTL != 0 {
+		ttl = taskVault.TTL.String()
+	}
+
+	entityAlias := vaultEntityAlias(taskVault.EntityAlias, a, task)
+	if entityAlias != "" && role == "" {
+		return nil, fmt.Errorf("Vault entity alias requires creating the token from a role")
+	}
+
 	// Build the creation request
 	req := &vapi.TokenCreateRequest{
 		Policies: taskVault.Policies,
@@ -873,7 +894,7 @@ func (v *vaultClient) CreateToken(ctx context.Context, a *structs.Allocation, ta
 			"Task":         task,
 			"NodeID":       a.NodeID,
 		},
-		TTL:         v.childTTL,
+		TTL:         ttl,
 		DisplayName: fmt.Sprintf("%s-%s", a.ID, task),
 	}
 
@@ -886,13 +907,15 @@ func (v *vaultClient) CreateToken(ctx context.Context, a *structs.Allocation, ta
 	// token or a role based token
 	var secret *vapi.Secret
 	var err error
-	role := v.getRole()
 	if v.tokenData.Root && role == "" {
-		req.Period = v.childTTL
+		req.Period = ttl
 		secret, err = v.auth.Create(req)
+	} else if entityAlias != "" {
+		// Make the token using the role and attach it to the entity alias
+		secret, err = v.createWithEntityAlias(req, role, entityAlias)
 	} else {
 		// Make the token using the role
-		secret, err = v.auth.CreateWithRole(req, v.getRole())
+		secret, err = v.auth.CreateWithRole(req, role)
 	}
 
 	// Determine whether it is unrecoverable
@@ -908,6 +931,50 @@ func (v *vaultClient) CreateToken(ctx context.Context, a *structs.Allocation, ta
 	return secret, nil
 }
 
+// vaultTokenCreateRequest extends the token creation request with the entity
+// alias the token is attached to.
+type vaultTokenCreateRequest struct {
+	*vapi.TokenCreateRequest
+	EntityAlias string `json:"entity_alias,omitempty"`
+}
+
+// createWithEntityAlias creates a token from the role that is attached to
+// the given entity alias.
+func (v *vaultClient) createWithEntityAlias(req *vapi.TokenCreateRequest, role, entityAlias string) (*vapi.Secret, error) {
+	r := v.client.NewRequest("POST", fmt.Sprintf("/v1/"+vaultTokenRoleCreatePath, role))
+	body := &vaultTokenCreateRequest{
+		TokenCreateRequest: req,
+		EntityAlias:        entityAlias,
+	}
+	if err := r.SetJSONBody(body); err != nil {
+		return nil, err
+	}
+
+	resp, err := v.client.RawRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return vapi.ParseSecret(resp.Body)
+}
+
+// vaultEntityAlias interpolates the job, group and task names in the entity
+// alias of a task.
+func vaultEntityAlias(alias string, a *structs.Allocation, task string) string {
+	if alias == "" {
+		return ""
+	}
+	r := strings.NewReplacer(
+		"${NOMAD_NAMESPACE}", a.Namespace,
+		"${NOMAD_JOB_ID}", a.JobID,
+		"${NOMAD_JOB_NAME}", a.Job.Name,
+		"${NOMAD_GROUP_NAME}", a.TaskGroup,
+		"${NOMAD_TASK_NAME}", task,
+	)
+	return r.Replace(alias)
+}
+
 // LookupToken takes a Vault token and does a lookup against Vault. The call is
 // rate limited and may be canceled with passed context.
 func (v *vaultClient) LookupToken(ctx context.Context, token string) (*vapi.Secret, error) {
@@ -938,6 +1005,48 @@ func (v *vaultClient) LookupToken(ctx context.Context, token string) (*vapi.Secr
 	return v.auth.Lookup(token)
 }
 
+// CheckTokenRole checks that the Vault token is allowed to create tokens from
+// the given token role. The call is rate limited and may be canceled with
+// passed context.
+func (v *vaultClient) CheckTokenRole(ctx context.Context, token, role string) error {
+	if !v.Enabled() {
+		return fmt.Errorf("Vault integration disabled")
+	}
+
+	if !v.Active() {
+		return fmt.Errorf("Vault client not active")
+	}
+
+	// Check if we have established a connection with Vault
+	if established, err := v.ConnectionEstablished(); !established && err == nil {
+		return structs.NewRecoverableError(fmt.Errorf("Connection to Vault has not been established"), true)
+	} else if !established {
+		return fmt.Errorf("Connection to Vault failed: %v", err)
+	}
+
+	// Track how long the request takes
+	defer metrics.MeasureSince([]string{"nomad", "vault", "check_token_role"}, time.Now())
+
+	// Ensure we are under our rate limit
+	if err := v.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(vaultTokenRoleCreatePath, role)
+	caps, err := v.client.Sys().Capabilities(token, path)
+	if err != nil {
+		return fmt.Errorf("failed to lookup Vault token capabilities: %v", err)
+	}
+	for _, c := range caps {
+		for _, r := range vaultTokenRoleCreateCapability {
+			if c == r {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("Passed Vault Token doesn't allow creating tokens from role %q", role)
+}
+
 // PoliciesFrom parses the set of policies returned by a token lookup.
 func PoliciesFrom(s *vapi.Secret) ([]string, error) {
 	if s == nil {
@@ -887,6 +887,93 @@ func TestVaultClient_CreateToken_Root_Target_Role(t *testing.T) {
 	}
 }
 
+func TestVaultClient_CreateToken_Task_Role(t *testing.T) {
+	t.Parallel()
+	v := testutil.NewTestVault(t)
+	defer v.Stop()
+
+	// Create the test role but don't target it from the server
+	defaultTestVaultWhitelistRoleAndToken(v, t, 5)
+
+	// Start the client
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	client, err := NewVaultClient(v.Config, logger, nil)
+	if err != nil {
+		t.Fatalf("failed to build vault client: %v", err)
+	}
+	client.SetActive(true)
+	defer client.Stop()
+
+	waitForConnection(client, t)
+
+	// Create an allocation whose task targets the role
+	a := mock.Alloc()
+	task := a.Job.TaskGroups[0].Tasks[0]
+	task.Vault = &structs.Vault{
+		Policies: []string{"default"},
+		Role:     "test",
+		TTL:      10 * time.Minute,
+	}
+
+	s, err := client.CreateToken(context.Background(), a, task.Name)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	// Ensure that created secret is a wrapped token
+	if s == nil || s.WrapInfo == nil {
+		t.Fatalf("Bad secret: %#v", s)
+	}
+	if s.WrapInfo.WrappedAccessor == "" {
+		t.Fatalf("Bad accessor: %v", s.WrapInfo.WrappedAccessor)
+	}
+
+	// An entity alias requires the role to allow it
+	task.Vault.EntityAlias = "${NOMAD_JOB_ID}"
+	if _, err := client.CreateToken(context.Background(), a, task.Name); err == nil {
+		t.Fatalf("expected entity alias error")
+	}
+}
+
+func TestVaultEntityAlias(t *testing.T) {
+	t.Parallel()
+	a := mock.Alloc()
+	task := a.Job.TaskGroups[0].Tasks[0].Name
+
+	alias := vaultEntityAlias("${NOMAD_NAMESPACE}/${NOMAD_JOB_ID}/${NOMAD_GROUP_NAME}/${NOMAD_TASK_NAME}", a, task)
+	expected := strings.Join([]string{a.Namespace, a.JobID, a.TaskGroup, task}, "/")
+	if alias != expected {
+		t.Fatalf("got %q; want %q", alias, expected)
+	}
+
+	if alias := vaultEntityAlias("", a, task); alias != "" {
+		t.Fatalf("expected empty alias: %q", alias)
+	}
+}
+
+func TestVaultClient_WrappingFn(t *testing.T) {
+	t.Parallel()
+	v := &vaultClient{}
+	fn := v.getWrappingFn()
+
+	cases := []struct {
+		operation string
+		path      string
+		wrapped   bool
+	}{
+		{"POST", "auth/token/create", true},
+		{"POST", "auth/token/create/test", true},
+		{"GET", "auth/token/create/test", false},
+		{"POST", "auth/token/create-orphan", false},
+		{"POST", "auth/token/lookup", false},
+	}
+	for _, c := range cases {
+		if wrapped := fn(c.operation, c.path) != ""; wrapped != c.wrapped {
+			t.Errorf("%s %s: got wrapped %v; want %v", c.operation, c.path, wrapped, c.wrapped)
+		}
+	}
+}
+
 func TestVaultClient_CreateToken_Blacklist_Role(t *testing.T) {
 	t.Parallel()
 	// Need to skip if test is 0.6.4
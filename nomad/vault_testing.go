@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -29,6 +30,10 @@ type TestVaultClient struct {
 	// by the CreateToken call
 	CreateTokenSecret map[string]map[string]*vapi.Secret
 
+	// TokenRoles maps a token to the token roles it may create tokens from
+	// when checked by the CheckTokenRole call
+	TokenRoles map[string][]string
+
 	RevokedTokens []*structs.VaultAccessor
 }
 
@@ -66,6 +71,24 @@ func (v *TestVaultClient) SetLookupTokenSecret(token string, secret *vapi.Secret
 	v.LookupTokenSecret[token] = secret
 }
 
+func (v *TestVaultClient) CheckTokenRole(ctx context.Context, token, role string) error {
+	for _, r := range v.TokenRoles[token] {
+		if r == role {
+			return nil
+		}
+	}
+	return fmt.Errorf("Passed Vault Token doesn't allow creating tokens from role %q", role)
+}
+
+// SetTokenRoles sets the token roles the token may create tokens from
+func (v *TestVaultClient) SetTokenRoles(token string, roles []string) {
+	if v.TokenRoles == nil {
+		v.TokenRoles = make(map[string][]string)
+	}
+
+	v.TokenRoles[token] = roles
+}
+
 // SetLookupTokenAllowedPolicies is a helper that adds a secret that allows the
 // given policies
 func (v *TestVaultClient) SetLookupTokenAllowedPolicies(token string, policies []string) {
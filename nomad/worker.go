@@ -282,7 +282,7 @@ func (w *Worker) invokeScheduler(eval *structs.Evaluation, token string) error {
 	if eval.Type == structs.JobTypeCore {
 		sched = NewCoreScheduler(w.srv, snap)
 	} else {
-		sched, err = scheduler.NewScheduler(eval.Type, w.logger, snap, w)
+		sched, err = w.srv.newScheduler(eval.Type, w.logger, snap, w)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate scheduler: %v", err)
 		}
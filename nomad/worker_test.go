@@ -324,6 +324,34 @@ func TestWorker_invokeScheduler(t *testing.T) {
 	}
 }
 
+func TestWorker_invokeScheduler_Plugin(t *testing.T) {
+	t.Parallel()
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+		c.EnabledSchedulers = []string{structs.JobTypeService}
+	})
+	defer s1.Shutdown()
+
+	// Register a scheduler plugin for a custom job type
+	var sched *NoopScheduler
+	s1.schedulerPlugins["custom"] = func(logger *log.Logger, s scheduler.State, p scheduler.Planner) scheduler.Scheduler {
+		sched = &NoopScheduler{state: s, planner: p}
+		return sched
+	}
+
+	w := &Worker{srv: s1, logger: s1.logger}
+	eval := mock.Eval()
+	eval.Type = "custom"
+
+	err := w.invokeScheduler(eval, uuid.Generate())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if sched == nil || sched.eval != eval {
+		t.Fatalf("plugin scheduler did not process the eval")
+	}
+}
+
 func TestWorker_SubmitPlan(t *testing.T) {
 	t.Parallel()
 	s1 := testServer(t, func(c *Config) {
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// Capacity forecasts how many more instances of the task group of the job fit
+// in the cluster. It repeatedly places the task group against the state,
+// discounting the resources of the previous placements, until no node is
+// feasible or the limit is reached. The placements are never submitted.
+func Capacity(logger *log.Logger, state State, job *structs.Job, tgName string, limit int) (*structs.TaskGroupCapacity, error) {
+	switch job.Type {
+	case structs.JobTypeService, structs.JobTypeBatch:
+	default:
+		return nil, fmt.Errorf("capacity forecasting is not supported for %q jobs", job.Type)
+	}
+
+	tg := job.LookupTaskGroup(tgName)
+	if tg == nil {
+		return nil, fmt.Errorf("task group %q not found", tgName)
+	}
+
+	// Get the base nodes
+	nodes, byDC, err := readyNodesInDCs(state, job.Datacenters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a plan that collects the placements so that their resources are
+	// discounted by the following placements
+	eval := &structs.Evaluation{
+		ID:        uuid.Generate(),
+		Namespace: job.Namespace,
+		Priority:  job.Priority,
+		Type:      job.Type,
+		JobID:     job.ID,
+	}
+	plan := eval.MakePlan(job)
+	ctx := NewEvalContext(state, plan, logger)
+
+	// Construct the placement stack
+	stack := NewGenericStack(job.Type == structs.JobTypeBatch, ctx)
+	stack.SetJob(job)
+	schedConfig, err := jobSchedulerConfig(state, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduler configuration: %v", err)
+	}
+	stack.SetSchedulerConfiguration(schedConfig)
+	stack.SetNodes(nodes)
+
+	capacity := &structs.TaskGroupCapacity{
+		TaskGroup:    tg.Name,
+		ByDatacenter: make(map[string]int),
+		ByNodeClass:  make(map[string]int),
+		ByNode:       make(map[string]int),
+	}
+	for capacity.Placed < limit {
+		option, _ := stack.Select(tg)
+		if option == nil {
+			metrics := ctx.Metrics()
+			metrics.NodesAvailable = byDC
+			capacity.Metrics = metrics
+			capacity.LimitingDimension = limitingDimension(metrics)
+			return capacity, nil
+		}
+
+		alloc := &structs.Allocation{
+			ID:            uuid.Generate(),
+			Namespace:     job.Namespace,
+			EvalID:        eval.ID,
+			Name:          structs.AllocName(job.ID, tg.Name, uint(tg.Count+capacity.Placed)),
+			JobID:         job.ID,
+			TaskGroup:     tg.Name,
+			NodeID:        option.Node.ID,
+			TaskResources: option.TaskResources,
+			DesiredStatus: structs.AllocDesiredStatusRun,
+			ClientStatus:  structs.AllocClientStatusPending,
+
+			SharedResources: &structs.Resources{
+				DiskMB:   tg.EphemeralDisk.SizeMB,
+				Networks: option.GroupNetworks,
+			},
+		}
+		plan.AppendAlloc(alloc)
+
+		capacity.Placed++
+		capacity.ByDatacenter[option.Node.Datacenter]++
+		capacity.ByNodeClass[option.Node.NodeClass]++
+		capacity.ByNode[option.Node.ID]++
+	}
+
+	capacity.LimitReached = true
+	return capacity, nil
+}
+
+// limitingDimension returns the dimension exhausted on the most nodes, or an
+// empty string if no dimension was exhausted.
+func limitingDimension(metrics *structs.AllocMetric) string {
+	dims := make([]string, 0, len(metrics.DimensionExhausted))
+	for dim := range metrics.DimensionExhausted {
+		dims = append(dims, dim)
+	}
+	sort.Strings(dims)
+
+	limiting := ""
+	for _, dim := range dims {
+		if limiting == "" || metrics.DimensionExhausted[dim] > metrics.DimensionExhausted[limiting] {
+			limiting = dim
+		}
+	}
+	return limiting
+}
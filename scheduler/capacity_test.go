@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestCapacity_Exhausted(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes
+	var nodes []*structs.Node
+	for i := 0; i < 3; i++ {
+		node := mock.Node()
+		nodes = append(nodes, node)
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Each node fits seven instances before its CPU is exhausted
+	job := mock.Job()
+	capacity, err := Capacity(testLogger(), h.State, job, "web", 100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if capacity.Placed != 21 || capacity.LimitReached {
+		t.Fatalf("bad: %#v", capacity)
+	}
+	for _, node := range nodes {
+		if n := capacity.ByNode[node.ID]; n != 7 {
+			t.Fatalf("got %d instances on node %q; want 7", n, node.ID)
+		}
+	}
+	if n := capacity.ByDatacenter["dc1"]; n != 21 {
+		t.Fatalf("bad: %#v", capacity.ByDatacenter)
+	}
+	if n := capacity.ByNodeClass[nodes[0].NodeClass]; n != 21 {
+		t.Fatalf("bad: %#v", capacity.ByNodeClass)
+	}
+	if capacity.LimitingDimension != "cpu exhausted" {
+		t.Fatalf("bad: %q", capacity.LimitingDimension)
+	}
+	if capacity.Metrics == nil || capacity.Metrics.NodesExhausted != 3 {
+		t.Fatalf("bad: %#v", capacity.Metrics)
+	}
+}
+
+func TestCapacity_ExistingAllocs(t *testing.T) {
+	h := NewHarness(t)
+
+	node := mock.Node()
+	noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+
+	// Use most of the CPU of the node with an existing allocation
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.Resources.CPU = 3000
+	alloc.TaskResources["web"].CPU = 3000
+	noErr(t, h.State.UpsertJobSummary(h.NextIndex(), mock.JobSummary(alloc.JobID)))
+	noErr(t, h.State.UpsertAllocs(h.NextIndex(), []*structs.Allocation{alloc}))
+
+	job := mock.Job()
+	capacity, err := Capacity(testLogger(), h.State, job, "web", 100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if capacity.Placed != 1 {
+		t.Fatalf("bad: %#v", capacity)
+	}
+}
+
+func TestCapacity_DistinctHosts(t *testing.T) {
+	h := NewHarness(t)
+
+	for i := 0; i < 3; i++ {
+		noErr(t, h.State.UpsertNode(h.NextIndex(), mock.Node()))
+	}
+
+	job := mock.Job()
+	job.Constraints = append(job.Constraints, &structs.Constraint{Operand: structs.ConstraintDistinctHosts})
+	capacity, err := Capacity(testLogger(), h.State, job, "web", 100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if capacity.Placed != 3 {
+		t.Fatalf("bad: %#v", capacity)
+	}
+	if capacity.LimitingDimension != "" {
+		t.Fatalf("bad: %q", capacity.LimitingDimension)
+	}
+}
+
+func TestCapacity_ReservedPorts(t *testing.T) {
+	h := NewHarness(t)
+
+	for i := 0; i < 2; i++ {
+		noErr(t, h.State.UpsertNode(h.NextIndex(), mock.Node()))
+	}
+
+	// A static port fits once per node
+	job := mock.Job()
+	job.TaskGroups[0].Tasks[0].Resources.Networks[0].ReservedPorts = []structs.Port{{Label: "main", Value: 8080}}
+	capacity, err := Capacity(testLogger(), h.State, job, "web", 100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if capacity.Placed != 2 {
+		t.Fatalf("bad: %#v", capacity)
+	}
+	if !strings.Contains(capacity.LimitingDimension, "reserved port collision") {
+		t.Fatalf("bad: %q", capacity.LimitingDimension)
+	}
+}
+
+func TestCapacity_Limit(t *testing.T) {
+	h := NewHarness(t)
+	noErr(t, h.State.UpsertNode(h.NextIndex(), mock.Node()))
+
+	job := mock.Job()
+	capacity, err := Capacity(testLogger(), h.State, job, "web", 5)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if capacity.Placed != 5 || !capacity.LimitReached || capacity.Metrics != nil {
+		t.Fatalf("bad: %#v", capacity)
+	}
+}
+
+func TestCapacity_Invalid(t *testing.T) {
+	h := NewHarness(t)
+
+	job := mock.Job()
+	if _, err := Capacity(testLogger(), h.State, job, "foo", 5); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected task group error, got: %v", err)
+	}
+
+	job = mock.SystemJob()
+	if _, err := Capacity(testLogger(), h.State, job, "web", 5); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected job type error, got: %v", err)
+	}
+}
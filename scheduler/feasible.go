@@ -347,6 +347,51 @@ func (iter *DistinctPropertyIterator) Reset() {
 	}
 }
 
+// NodePoolChecker is a FeasibilityChecker which returns whether a node is in
+// the node pool of the job.
+type NodePoolChecker struct {
+	ctx  Context
+	pool string
+}
+
+// NewNodePoolChecker creates a NodePoolChecker for the given node pool
+func NewNodePoolChecker(ctx Context, pool string) *NodePoolChecker {
+	return &NodePoolChecker{
+		ctx:  ctx,
+		pool: pool,
+	}
+}
+
+func (c *NodePoolChecker) SetNodePool(pool string) {
+	c.pool = pool
+}
+
+func (c *NodePoolChecker) Feasible(option *structs.Node) bool {
+	if c.inNodePool(option) {
+		return true
+	}
+	c.ctx.Metrics().FilterNode(option, "node pool")
+	return false
+}
+
+// inNodePool is used to check if the node is in the node pool. Jobs and nodes
+// without a node pool are in the default node pool.
+func (c *NodePoolChecker) inNodePool(option *structs.Node) bool {
+	pool := c.pool
+	if pool == "" {
+		pool = structs.NodePoolDefault
+	}
+	if pool == structs.NodePoolAll {
+		return true
+	}
+
+	nodePool := option.NodePool
+	if nodePool == "" {
+		nodePool = structs.NodePoolDefault
+	}
+	return pool == nodePool
+}
+
 // ConstraintChecker is a FeasibilityChecker which returns nodes that match a
 // given set of constraints. This is used to filter on job, task group, and task
 // constraints.
@@ -125,6 +125,63 @@ func TestDriverChecker(t *testing.T) {
 	}
 }
 
+func TestNodePoolChecker(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*structs.Node{
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+	}
+	nodes[0].NodePool = ""
+	nodes[1].NodePool = structs.NodePoolDefault
+	nodes[2].NodePool = "gpu"
+
+	cases := []struct {
+		Pool   string
+		Node   *structs.Node
+		Result bool
+	}{
+		{
+			Pool:   "",
+			Node:   nodes[0],
+			Result: true,
+		},
+		{
+			Pool:   structs.NodePoolDefault,
+			Node:   nodes[1],
+			Result: true,
+		},
+		{
+			Pool:   structs.NodePoolDefault,
+			Node:   nodes[2],
+			Result: false,
+		},
+		{
+			Pool:   "gpu",
+			Node:   nodes[1],
+			Result: false,
+		},
+		{
+			Pool:   "gpu",
+			Node:   nodes[2],
+			Result: true,
+		},
+		{
+			Pool:   structs.NodePoolAll,
+			Node:   nodes[2],
+			Result: true,
+		},
+	}
+
+	checker := NewNodePoolChecker(ctx, "")
+	for i, c := range cases {
+		checker.SetNodePool(c.Pool)
+		if act := checker.Feasible(c.Node); act != c.Result {
+			t.Fatalf("case(%d) failed: got %v; want %v", i, act, c.Result)
+		}
+	}
+}
+
 func TestConstraintChecker(t *testing.T) {
 	_, ctx := testContext(t)
 	nodes := []*structs.Node{
@@ -32,6 +32,10 @@ const (
 	// allocLost is the status used when an allocation is lost
 	allocLost = "alloc is lost since its node is down"
 
+	// allocUnknown is the status used when an allocation's node is down but
+	// the allocation is within its max_client_disconnect window
+	allocUnknown = "alloc is unknown since its node is disconnected"
+
 	// allocInPlace is the status used when speculating on an in-place update
 	allocInPlace = "alloc updating in-place"
 
@@ -72,8 +76,9 @@ type GenericScheduler struct {
 	ctx        *EvalContext
 	stack      *GenericStack
 
-	followupEvalWait time.Duration
-	nextEval         *structs.Evaluation
+	followupEvalWait   time.Duration
+	disconnectEvalWait time.Duration
+	nextEval           *structs.Evaluation
 
 	deployment *structs.Deployment
 
@@ -114,7 +119,8 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) error {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerNodeUpdate,
 		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate,
 		structs.EvalTriggerPeriodicJob, structs.EvalTriggerMaxPlans,
-		structs.EvalTriggerDeploymentWatcher:
+		structs.EvalTriggerDeploymentWatcher, structs.EvalTriggerGroupDependency,
+		structs.EvalTriggerMaxDisconnect:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
@@ -227,6 +233,13 @@ func (s *GenericScheduler) process() (bool, error) {
 		s.stack.SetJob(s.job)
 	}
 
+	// Apply the scheduler configuration of the job and its node pool
+	schedConfig, err := jobSchedulerConfig(s.state, s.job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get scheduler configuration: %v", err)
+	}
+	s.stack.SetSchedulerConfiguration(schedConfig)
+
 	// Compute the target job allocations
 	if err := s.computeJobAllocs(); err != nil {
 		s.logger.Printf("[ERR] sched: %#v: %v", s.eval, err)
@@ -244,6 +257,19 @@ func (s *GenericScheduler) process() (bool, error) {
 		s.logger.Printf("[DEBUG] sched: %#v: failed to place all allocations, blocked eval '%s' created", s.eval, s.blocked.ID)
 	}
 
+	// If allocations on disconnected nodes are waiting out their
+	// max_client_disconnect window, create a followup eval to replace them
+	// once it expires. Like above this is done before checking for a no-op
+	// plan since the allocations may already be marked unknown.
+	if s.disconnectEvalWait != 0 && s.nextEval == nil {
+		s.nextEval = s.eval.NextDisconnectEval(s.disconnectEvalWait)
+		if err := s.planner.CreateEval(s.nextEval); err != nil {
+			s.logger.Printf("[ERR] sched: %#v failed to make next eval for disconnected allocations: %v", s.eval, err)
+			return false, err
+		}
+		s.logger.Printf("[DEBUG] sched: %#v: allocations waiting on disconnected nodes, next eval '%s' created", s.eval, s.nextEval.ID)
+	}
+
 	// If the plan is a no-op, we can bail. If AnnotatePlan is set submit the plan
 	// anyways to get the annotations.
 	if s.plan.IsNoOp() && !s.eval.AnnotatePlan {
@@ -377,6 +403,7 @@ func (s *GenericScheduler) computeJobAllocs() error {
 	// Store the the follow up eval wait duration. If set this will trigger a
 	// follow up eval to handle node draining.
 	s.followupEvalWait = results.followupEvalWait
+	s.disconnectEvalWait = results.disconnectEvalWait
 
 	// Update the stored deployment
 	if results.deployment != nil {
@@ -388,6 +415,12 @@ func (s *GenericScheduler) computeJobAllocs() error {
 		s.plan.AppendUpdate(stop.alloc, structs.AllocDesiredStatusStop, stop.statusDescription, stop.clientStatus)
 	}
 
+	// Mark the allocations on disconnected nodes as unknown. They keep running
+	// until their max_client_disconnect window expires.
+	for _, alloc := range results.disconnectUpdates {
+		s.plan.AppendUpdate(alloc, structs.AllocDesiredStatusRun, allocUnknown, structs.AllocClientStatusUnknown)
+	}
+
 	// Handle the in-place updates
 	for _, update := range results.inplaceUpdate {
 		if update.DeploymentID != s.deployment.GetID() {
@@ -503,7 +536,8 @@ func (s *GenericScheduler) computePlacements(destructive, place []placementResul
 					ClientStatus:  structs.AllocClientStatusPending,
 
 					SharedResources: &structs.Resources{
-						DiskMB: tg.EphemeralDisk.SizeMB,
+						DiskMB:   tg.EphemeralDisk.SizeMB,
+						Networks: option.GroupNetworks,
 					},
 				}
 
@@ -97,6 +97,118 @@ func TestServiceSched_JobRegister(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobRegister_GroupDependency(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Create a job with a second task group depending on the first
+	job := mock.Job()
+	tg2 := job.TaskGroups[0].Copy()
+	tg2.Name = "api"
+	tg2.DependsOn = []*structs.TaskGroupDependency{
+		{
+			Group:     job.TaskGroups[0].Name,
+			Condition: structs.TaskGroupDependencyRunning,
+		},
+	}
+	job.TaskGroups = append(job.TaskGroups, tg2)
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	// Create a mock evaluation to register the job
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation
+	err := h.Process(NewServiceScheduler, eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Ensure only the first task group was placed
+	var planned []*structs.Allocation
+	for _, allocList := range plan.NodeAllocation {
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 10 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	for _, alloc := range planned {
+		if alloc.TaskGroup != job.TaskGroups[0].Name {
+			t.Fatalf("unexpected placement of group %q", alloc.TaskGroup)
+		}
+	}
+
+	// Ensure no followup eval was created. The job is re-evaluated when the
+	// allocations it depends on change.
+	if len(h.CreateEvals) != 0 {
+		t.Fatalf("bad: %#v", h.CreateEvals)
+	}
+
+	// Mark the placed allocations as running and process the eval triggered
+	// by the change
+	var running []*structs.Allocation
+	for _, alloc := range planned {
+		alloc = alloc.Copy()
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		running = append(running, alloc)
+	}
+	noErr(t, h.State.UpdateAllocsFromClient(h.NextIndex(), running))
+
+	next := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerGroupDependency,
+		JobID:       job.ID,
+	}
+	err = h.Process(NewServiceScheduler, next)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure the second task group was placed
+	if len(h.Plans) != 2 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	planned = nil
+	for _, allocList := range h.Plans[1].NodeAllocation {
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 10 {
+		t.Fatalf("bad: %#v", h.Plans[1])
+	}
+	for _, alloc := range planned {
+		if alloc.TaskGroup != tg2.Name {
+			t.Fatalf("unexpected placement of group %q", alloc.TaskGroup)
+		}
+	}
+	if len(h.CreateEvals) != 0 {
+		t.Fatalf("bad: %#v", h.CreateEvals)
+	}
+
+	for _, e := range h.Evals {
+		if e.Status != structs.EvalStatusComplete {
+			t.Fatalf("bad: %#v", e)
+		}
+	}
+}
+
 func TestServiceSched_JobRegister_StickyAllocs(t *testing.T) {
 	h := NewHarness(t)
 
@@ -252,6 +364,62 @@ func TestServiceSched_JobRegister_DiskConstraints(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobRegister_NodePool(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes, half of them in another pool
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		if i%2 == 0 {
+			node.NodePool = "gpu"
+			noErr(t, node.ComputeClass())
+		}
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Create a job in the pool
+	job := mock.Job()
+	job.NodePool = "gpu"
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	// Create a mock evaluation to register the job
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation
+	err := h.Process(NewServiceScheduler, eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Ensure all allocations were placed on nodes in the pool
+	var planned []*structs.Allocation
+	for nodeID, allocList := range plan.NodeAllocation {
+		node, err := h.State.NodeByID(nil, nodeID)
+		noErr(t, err)
+		if node.NodePool != "gpu" {
+			t.Fatalf("placed on node %q in pool %q", nodeID, node.NodePool)
+		}
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 10 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
 func TestServiceSched_JobRegister_DistinctHosts(t *testing.T) {
 	h := NewHarness(t)
 
@@ -2191,7 +2359,7 @@ func TestServiceSched_NodeDown(t *testing.T) {
 	}
 
 	// Mark the node as down
-	noErr(t, h.State.UpdateNodeStatus(h.NextIndex(), node.ID, structs.NodeStatusDown))
+	noErr(t, h.State.UpdateNodeStatus(h.NextIndex(), node.ID, structs.NodeStatusDown, time.Now().Unix()))
 
 	// Create a mock evaluation to deal with drain
 	eval := &structs.Evaluation{
@@ -2229,6 +2397,91 @@ func TestServiceSched_NodeDown(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_NodeDown_MaxClientDisconnect(t *testing.T) {
+	h := NewHarness(t)
+
+	// Register a node
+	node := mock.Node()
+	noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+
+	// Generate a fake job whose allocations tolerate disconnects
+	job := mock.Job()
+	job.TaskGroups[0].Count = 2
+	job.TaskGroups[0].MaxClientDisconnect = helper.TimeToPtr(10 * time.Minute)
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	var allocs []*structs.Allocation
+	for i := 0; i < 2; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = node.ID
+		alloc.Name = fmt.Sprintf("my-job.web[%d]", i)
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		allocs = append(allocs, alloc)
+	}
+	noErr(t, h.State.UpsertAllocs(h.NextIndex(), allocs))
+
+	// Mark the node as down
+	noErr(t, h.State.UpdateNodeStatus(h.NextIndex(), node.ID, structs.NodeStatusDown, time.Now().Unix()))
+
+	// Create a mock evaluation to deal with the down node
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    50,
+		TriggeredBy: structs.EvalTriggerNodeUpdate,
+		JobID:       job.ID,
+		NodeID:      node.ID,
+	}
+
+	// Process the evaluation
+	err := h.Process(NewServiceScheduler, eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Test the scheduler marked the allocations unknown without replacing them
+	if len(plan.NodeAllocation) != 0 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	if len(plan.NodeUpdate[node.ID]) != 2 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	for _, out := range plan.NodeUpdate[node.ID] {
+		if out.ClientStatus != structs.AllocClientStatusUnknown || out.DesiredStatus != structs.AllocDesiredStatusRun {
+			t.Fatalf("bad alloc: %#v", out)
+		}
+	}
+
+	// Ensure a followup eval was created for when the window expires
+	if len(h.CreateEvals) != 1 {
+		t.Fatalf("bad: %#v", h.CreateEvals)
+	}
+	next := h.CreateEvals[0]
+	if next.TriggeredBy != structs.EvalTriggerMaxDisconnect || next.Wait <= 0 || next.Wait > 10*time.Minute {
+		t.Fatalf("bad followup eval: %#v", next)
+	}
+
+	// Ensure the allocations are unknown in the state
+	ws := memdb.NewWatchSet()
+	out, err := h.State.AllocsByJob(ws, job.Namespace, job.ID, false)
+	noErr(t, err)
+	for _, alloc := range out {
+		if alloc.ClientStatus != structs.AllocClientStatusUnknown {
+			t.Fatalf("bad alloc: %#v", alloc)
+		}
+	}
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
 func TestServiceSched_NodeUpdate(t *testing.T) {
 	h := NewHarness(t)
 
@@ -0,0 +1,413 @@
+package scheduler
+
+import (
+	"encoding/gob"
+	"log"
+	"net/rpc"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// Registering these types since jobs, including their free form task configs,
+// are serialized over the wire between servers and scheduler plugins.
+func init() {
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register([]map[string]string{})
+	gob.Register([]map[string]int{})
+}
+
+const (
+	// PluginName is the name scheduler plugins are dispensed under.
+	PluginName = "scheduler"
+)
+
+// PluginHandshake is the handshake used between servers and the scheduler
+// plugins they launch.
+var PluginHandshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "NOMAD_SCHEDULER_PLUGIN_MAGIC_COOKIE",
+	MagicCookieValue: "5c2a1e8d7b0f4e9a6c3d2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e",
+}
+
+// ServePlugin serves the scheduler created by the given factory as a
+// scheduler plugin. It is meant to be called from the main function of a
+// plugin binary and blocks until the server stops the plugin.
+func ServePlugin(factory Factory) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: PluginHandshake,
+		Plugins: map[string]plugin.Plugin{
+			PluginName: &SchedulerPlugin{
+				Factory: factory,
+				Logger:  log.New(os.Stderr, "", log.LstdFlags|log.Lmicroseconds),
+			},
+		},
+	})
+}
+
+// SchedulerPlugin is the go-plugin definition of a scheduler plugin. Within
+// the plugin process the Factory creates the scheduler that handles each
+// evaluation. Servers dispense a *SchedulerRPC from it.
+type SchedulerPlugin struct {
+	Factory Factory
+	Logger  *log.Logger
+}
+
+func (p *SchedulerPlugin) Server(b *plugin.MuxBroker) (interface{}, error) {
+	return &SchedulerRPCServer{factory: p.Factory, logger: p.Logger, broker: b}, nil
+}
+
+func (p *SchedulerPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &SchedulerRPC{client: c, broker: b}, nil
+}
+
+// PluginProcessArgs are the arguments to process an evaluation in a
+// scheduler plugin. BrokerID is the connection the plugin dials back to
+// access the state snapshot and planner of the server.
+type PluginProcessArgs struct {
+	BrokerID uint32
+	Eval     *structs.Evaluation
+}
+
+// SchedulerRPC is the server side client of a scheduler plugin.
+type SchedulerRPC struct {
+	client *rpc.Client
+	broker *plugin.MuxBroker
+}
+
+// Factory returns a Factory for schedulers that process evaluations in the
+// plugin.
+func (s *SchedulerRPC) Factory() Factory {
+	return func(logger *log.Logger, state State, planner Planner) Scheduler {
+		return &pluginScheduler{
+			rpc:     s,
+			logger:  logger,
+			state:   state,
+			planner: planner,
+		}
+	}
+}
+
+// pluginScheduler is a Scheduler that hands evaluations to a scheduler plugin
+// while serving it the state and planner it was created with.
+type pluginScheduler struct {
+	rpc     *SchedulerRPC
+	logger  *log.Logger
+	state   State
+	planner Planner
+}
+
+func (s *pluginScheduler) Process(eval *structs.Evaluation) error {
+	id := s.rpc.broker.NextId()
+	go s.rpc.broker.AcceptAndServe(id, &PluginHostRPCServer{
+		state:   s.state,
+		planner: s.planner,
+	})
+
+	args := &PluginProcessArgs{BrokerID: id, Eval: eval}
+	return s.rpc.client.Call("Plugin.Process", args, new(interface{}))
+}
+
+// SchedulerRPCServer serves the scheduler of a plugin to the server.
+type SchedulerRPCServer struct {
+	factory Factory
+	logger  *log.Logger
+	broker  *plugin.MuxBroker
+}
+
+func (s *SchedulerRPCServer) Process(args *PluginProcessArgs, resp *interface{}) error {
+	conn, err := s.broker.Dial(args.BrokerID)
+	if err != nil {
+		return err
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	state := &StateRPC{client: client}
+	planner := &PlannerRPC{client: client, state: state}
+	return s.factory(s.logger, state, planner).Process(args.Eval)
+}
+
+// PluginStateArgs are the arguments of the State calls made by a plugin.
+// Only the fields relevant to the called method are set.
+type PluginStateArgs struct {
+	Namespace string
+	ID        string
+	All       bool
+	Terminal  bool
+}
+
+// PluginNodesResponse is the response to a nodes lookup.
+type PluginNodesResponse struct {
+	Nodes []*structs.Node
+}
+
+// PluginNodeResponse is the response to a node lookup.
+type PluginNodeResponse struct {
+	Node *structs.Node
+}
+
+// PluginAllocsResponse is the response to an allocation lookup.
+type PluginAllocsResponse struct {
+	Allocs []*structs.Allocation
+}
+
+// PluginJobResponse is the response to a job lookup.
+type PluginJobResponse struct {
+	Job *structs.Job
+}
+
+// PluginDeploymentResponse is the response to a deployment lookup.
+type PluginDeploymentResponse struct {
+	Deployment *structs.Deployment
+}
+
+// PluginSchedulerConfigResponse is the response to a scheduler configuration
+// lookup.
+type PluginSchedulerConfigResponse struct {
+	Index  uint64
+	Config *structs.SchedulerConfiguration
+}
+
+// PluginNodePoolResponse is the response to a node pool lookup.
+type PluginNodePoolResponse struct {
+	NodePool *structs.NodePool
+}
+
+// PluginSubmitPlanResponse is the response to a plan submission. If
+// StateRefreshed is set the server has moved the plugin to a newer snapshot.
+type PluginSubmitPlanResponse struct {
+	Result         *structs.PlanResult
+	StateRefreshed bool
+}
+
+// PluginHostRPCServer serves a read-only view of the state snapshot and the
+// planner of a server worker to a plugin while it processes an evaluation.
+type PluginHostRPCServer struct {
+	state   State
+	planner Planner
+	l       sync.RWMutex
+}
+
+func (s *PluginHostRPCServer) snapshot() State {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.state
+}
+
+func (s *PluginHostRPCServer) Nodes(args *PluginStateArgs, resp *PluginNodesResponse) error {
+	iter, err := s.snapshot().Nodes(nil)
+	if err != nil {
+		return err
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		resp.Nodes = append(resp.Nodes, raw.(*structs.Node))
+	}
+	return nil
+}
+
+func (s *PluginHostRPCServer) AllocsByJob(args *PluginStateArgs, resp *PluginAllocsResponse) error {
+	allocs, err := s.snapshot().AllocsByJob(nil, args.Namespace, args.ID, args.All)
+	resp.Allocs = allocs
+	return err
+}
+
+func (s *PluginHostRPCServer) AllocsByNode(args *PluginStateArgs, resp *PluginAllocsResponse) error {
+	allocs, err := s.snapshot().AllocsByNode(nil, args.ID)
+	resp.Allocs = allocs
+	return err
+}
+
+func (s *PluginHostRPCServer) AllocsByNodeTerminal(args *PluginStateArgs, resp *PluginAllocsResponse) error {
+	allocs, err := s.snapshot().AllocsByNodeTerminal(nil, args.ID, args.Terminal)
+	resp.Allocs = allocs
+	return err
+}
+
+func (s *PluginHostRPCServer) NodeByID(args *PluginStateArgs, resp *PluginNodeResponse) error {
+	node, err := s.snapshot().NodeByID(nil, args.ID)
+	resp.Node = node
+	return err
+}
+
+func (s *PluginHostRPCServer) JobByID(args *PluginStateArgs, resp *PluginJobResponse) error {
+	job, err := s.snapshot().JobByID(nil, args.Namespace, args.ID)
+	resp.Job = job
+	return err
+}
+
+func (s *PluginHostRPCServer) LatestDeploymentByJobID(args *PluginStateArgs, resp *PluginDeploymentResponse) error {
+	d, err := s.snapshot().LatestDeploymentByJobID(nil, args.Namespace, args.ID)
+	resp.Deployment = d
+	return err
+}
+
+func (s *PluginHostRPCServer) SchedulerConfig(args *PluginStateArgs, resp *PluginSchedulerConfigResponse) error {
+	index, config, err := s.snapshot().SchedulerConfig()
+	resp.Index = index
+	resp.Config = config
+	return err
+}
+
+func (s *PluginHostRPCServer) NodePoolByName(args *PluginStateArgs, resp *PluginNodePoolResponse) error {
+	pool, err := s.snapshot().NodePoolByName(nil, args.ID)
+	resp.NodePool = pool
+	return err
+}
+
+func (s *PluginHostRPCServer) SubmitPlan(plan *structs.Plan, resp *PluginSubmitPlanResponse) error {
+	result, state, err := s.planner.SubmitPlan(plan)
+	if err != nil {
+		return err
+	}
+	resp.Result = result
+
+	// Serve the refreshed snapshot for the remainder of the evaluation
+	if state != nil {
+		s.l.Lock()
+		s.state = state
+		s.l.Unlock()
+		resp.StateRefreshed = true
+	}
+	return nil
+}
+
+func (s *PluginHostRPCServer) UpdateEval(eval *structs.Evaluation, resp *interface{}) error {
+	return s.planner.UpdateEval(eval)
+}
+
+func (s *PluginHostRPCServer) CreateEval(eval *structs.Evaluation, resp *interface{}) error {
+	return s.planner.CreateEval(eval)
+}
+
+func (s *PluginHostRPCServer) ReblockEval(eval *structs.Evaluation, resp *interface{}) error {
+	return s.planner.ReblockEval(eval)
+}
+
+// StateRPC implements the State interface within a plugin by calling back
+// into the server. Watch sets can not cross the plugin boundary and are
+// ignored.
+type StateRPC struct {
+	client *rpc.Client
+}
+
+func (s *StateRPC) Nodes(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	var resp PluginNodesResponse
+	if err := s.client.Call("Plugin.Nodes", &PluginStateArgs{}, &resp); err != nil {
+		return nil, err
+	}
+	return &nodeIterator{nodes: resp.Nodes}, nil
+}
+
+func (s *StateRPC) AllocsByJob(ws memdb.WatchSet, namespace, jobID string, all bool) ([]*structs.Allocation, error) {
+	var resp PluginAllocsResponse
+	args := &PluginStateArgs{Namespace: namespace, ID: jobID, All: all}
+	err := s.client.Call("Plugin.AllocsByJob", args, &resp)
+	return resp.Allocs, err
+}
+
+func (s *StateRPC) AllocsByNode(ws memdb.WatchSet, node string) ([]*structs.Allocation, error) {
+	var resp PluginAllocsResponse
+	err := s.client.Call("Plugin.AllocsByNode", &PluginStateArgs{ID: node}, &resp)
+	return resp.Allocs, err
+}
+
+func (s *StateRPC) AllocsByNodeTerminal(ws memdb.WatchSet, node string, terminal bool) ([]*structs.Allocation, error) {
+	var resp PluginAllocsResponse
+	args := &PluginStateArgs{ID: node, Terminal: terminal}
+	err := s.client.Call("Plugin.AllocsByNodeTerminal", args, &resp)
+	return resp.Allocs, err
+}
+
+func (s *StateRPC) NodeByID(ws memdb.WatchSet, nodeID string) (*structs.Node, error) {
+	var resp PluginNodeResponse
+	err := s.client.Call("Plugin.NodeByID", &PluginStateArgs{ID: nodeID}, &resp)
+	return resp.Node, err
+}
+
+func (s *StateRPC) JobByID(ws memdb.WatchSet, namespace, id string) (*structs.Job, error) {
+	var resp PluginJobResponse
+	args := &PluginStateArgs{Namespace: namespace, ID: id}
+	err := s.client.Call("Plugin.JobByID", args, &resp)
+	return resp.Job, err
+}
+
+func (s *StateRPC) LatestDeploymentByJobID(ws memdb.WatchSet, namespace, jobID string) (*structs.Deployment, error) {
+	var resp PluginDeploymentResponse
+	args := &PluginStateArgs{Namespace: namespace, ID: jobID}
+	err := s.client.Call("Plugin.LatestDeploymentByJobID", args, &resp)
+	return resp.Deployment, err
+}
+
+func (s *StateRPC) SchedulerConfig() (uint64, *structs.SchedulerConfiguration, error) {
+	var resp PluginSchedulerConfigResponse
+	err := s.client.Call("Plugin.SchedulerConfig", &PluginStateArgs{}, &resp)
+	return resp.Index, resp.Config, err
+}
+
+func (s *StateRPC) NodePoolByName(ws memdb.WatchSet, name string) (*structs.NodePool, error) {
+	var resp PluginNodePoolResponse
+	err := s.client.Call("Plugin.NodePoolByName", &PluginStateArgs{ID: name}, &resp)
+	return resp.NodePool, err
+}
+
+// PlannerRPC implements the Planner interface within a plugin by calling
+// back into the server.
+type PlannerRPC struct {
+	client *rpc.Client
+	state  *StateRPC
+}
+
+func (p *PlannerRPC) SubmitPlan(plan *structs.Plan) (*structs.PlanResult, State, error) {
+	var resp PluginSubmitPlanResponse
+	if err := p.client.Call("Plugin.SubmitPlan", plan, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	// The server swapped its snapshot so the same state client now reads
+	// the refreshed state.
+	if resp.StateRefreshed {
+		return resp.Result, p.state, nil
+	}
+	return resp.Result, nil, nil
+}
+
+func (p *PlannerRPC) UpdateEval(eval *structs.Evaluation) error {
+	return p.client.Call("Plugin.UpdateEval", eval, new(interface{}))
+}
+
+func (p *PlannerRPC) CreateEval(eval *structs.Evaluation) error {
+	return p.client.Call("Plugin.CreateEval", eval, new(interface{}))
+}
+
+func (p *PlannerRPC) ReblockEval(eval *structs.Evaluation) error {
+	return p.client.Call("Plugin.ReblockEval", eval, new(interface{}))
+}
+
+// nodeIterator is a memdb.ResultIterator over the nodes returned to a plugin.
+type nodeIterator struct {
+	nodes  []*structs.Node
+	offset int
+}
+
+func (i *nodeIterator) WatchCh() <-chan struct{} {
+	return nil
+}
+
+func (i *nodeIterator) Next() interface{} {
+	if i.offset >= len(i.nodes) {
+		return nil
+	}
+	node := i.nodes[i.offset]
+	i.offset++
+	return node
+}
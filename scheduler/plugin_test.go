@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"testing"
+
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// testPluginFactory dispenses a scheduler plugin running the given factory
+// over an in-memory connection and returns the server side factory for it.
+func testPluginFactory(t *testing.T, factory Factory) Factory {
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		PluginName: &SchedulerPlugin{Factory: factory, Logger: testLogger()},
+	})
+	raw, err := client.Dispense(PluginName)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return raw.(*SchedulerRPC).Factory()
+}
+
+func TestSchedulerPlugin_JobRegister(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Create a job
+	job := mock.Job()
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	// Create a mock evaluation to register the job
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation in the plugin
+	err := h.Process(testPluginFactory(t, NewServiceScheduler), eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Ensure the plan allocated
+	var planned []*structs.Allocation
+	for _, allocList := range plan.NodeAllocation {
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 10 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Lookup the allocations by JobID
+	ws := memdb.NewWatchSet()
+	out, err := h.State.AllocsByJob(ws, job.Namespace, job.ID, false)
+	noErr(t, err)
+
+	// Ensure all allocations placed
+	if len(out) != 10 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
+func TestSchedulerPlugin_StateRefresh(t *testing.T) {
+	h := NewHarness(t)
+	h.Planner = &RejectPlan{h}
+
+	// Create some nodes
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Create a job
+	job := mock.Job()
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	// Create a mock evaluation to register the job
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation in the plugin
+	err := h.Process(testPluginFactory(t, NewServiceScheduler), eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure the plugin retried against the refreshed state
+	if len(h.Plans) != maxServiceScheduleAttempts {
+		t.Fatalf("bad: %d plans", len(h.Plans))
+	}
+
+	// Ensure the eval failed once the attempts were exhausted
+	h.AssertEvalStatus(t, structs.EvalStatusFailed)
+}
@@ -144,10 +144,15 @@ func (p *propertySet) PopulateProposed() {
 	p.proposedValues = make(map[string]uint64)
 	p.clearedValues = make(map[string]uint64)
 
-	// Gather the set of proposed stops.
+	// Gather the set of proposed stops. Allocations marked unknown because
+	// their node disconnected keep running and still hold their values.
 	var stopping []*structs.Allocation
 	for _, updates := range p.ctx.Plan().NodeUpdate {
-		stopping = append(stopping, updates...)
+		for _, alloc := range updates {
+			if alloc.DesiredStatus != structs.AllocDesiredStatusRun {
+				stopping = append(stopping, alloc)
+			}
+		}
 	}
 	stopping = p.filterAllocs(stopping, false)
 
@@ -14,6 +14,10 @@ type RankedNode struct {
 	Score         float64
 	TaskResources map[string]*structs.Resources
 
+	// GroupNetworks are the network offers for the task group's shared
+	// network, if it requested one.
+	GroupNetworks []*structs.NetworkResource
+
 	// Allocs is used to cache the proposed allocations on the
 	// node. This can be shared between iterators that require it.
 	Proposed []*structs.Allocation
@@ -129,23 +133,34 @@ func (iter *StaticRankIterator) Reset() {
 }
 
 // BinPackIterator is a RankIterator that scores potential options
-// based on a bin-packing algorithm.
+// based on a bin-packing algorithm, or on a spread algorithm if configured.
 type BinPackIterator struct {
 	ctx       Context
 	source    RankIterator
 	evict     bool
 	priority  int
 	taskGroup *structs.TaskGroup
+
+	// memoryOversubscription is whether tasks may keep a memory limit
+	// above their reserved memory
+	memoryOversubscription bool
+
+	// scoreFit scores the fit of the proposed allocations on a node and
+	// algorithm is the name the score is reported under
+	scoreFit  func(*structs.Node, *structs.Resources) float64
+	algorithm structs.SchedulerAlgorithm
 }
 
 // NewBinPackIterator returns a BinPackIterator which tries to fit tasks
 // potentially evicting other tasks based on a given priority.
 func NewBinPackIterator(ctx Context, source RankIterator, evict bool, priority int) *BinPackIterator {
 	iter := &BinPackIterator{
-		ctx:      ctx,
-		source:   source,
-		evict:    evict,
-		priority: priority,
+		ctx:       ctx,
+		source:    source,
+		evict:     evict,
+		priority:  priority,
+		scoreFit:  structs.ScoreFit,
+		algorithm: structs.SchedulerAlgorithmBinpack,
 	}
 	return iter
 }
@@ -158,6 +173,20 @@ func (iter *BinPackIterator) SetTaskGroup(taskGroup *structs.TaskGroup) {
 	iter.taskGroup = taskGroup
 }
 
+// SetSchedulerConfiguration applies the cluster wide scheduler configuration.
+// The configuration may be nil if it has never been set.
+func (iter *BinPackIterator) SetSchedulerConfiguration(config *structs.SchedulerConfiguration) {
+	iter.memoryOversubscription = config != nil && config.MemoryOversubscriptionEnabled
+
+	iter.algorithm = config.EffectiveSchedulerAlgorithm()
+	switch iter.algorithm {
+	case structs.SchedulerAlgorithmSpread:
+		iter.scoreFit = structs.ScoreFitSpread
+	default:
+		iter.scoreFit = structs.ScoreFit
+	}
+}
+
 func (iter *BinPackIterator) Next() *RankedNode {
 OUTER:
 	for {
@@ -181,13 +210,43 @@ OUTER:
 		netIdx.SetNode(option.Node)
 		netIdx.AddAllocs(proposed)
 
+		// Index the existing core usage
+		coreIdx := structs.NewCoreIndex()
+		coreIdx.SetNode(option.Node)
+		coreIdx.AddAllocs(proposed)
+
 		// Assign the resources for each task
 		total := &structs.Resources{
 			DiskMB: iter.taskGroup.EphemeralDisk.SizeMB,
 		}
+
+		// Check if the task group needs a shared network resource
+		if len(iter.taskGroup.Networks) > 0 {
+			ask := iter.taskGroup.Networks[0].Copy()
+			offer, err := netIdx.AssignNetwork(ask)
+			if offer == nil {
+				iter.ctx.Metrics().ExhaustedNode(option.Node,
+					fmt.Sprintf("network: %s", err))
+				netIdx.Release()
+				continue OUTER
+			}
+
+			// Reserve this to prevent a task from colliding
+			netIdx.AddReserved(offer)
+
+			option.GroupNetworks = []*structs.NetworkResource{offer}
+			total.Add(&structs.Resources{Networks: option.GroupNetworks})
+		}
+
 		for _, task := range iter.taskGroup.Tasks {
 			taskResources := task.Resources.Copy()
 
+			// Tasks are packed on their reserved memory. Only allow them to
+			// burst above it if memory oversubscription is enabled.
+			if !iter.memoryOversubscription {
+				taskResources.MemoryMaxMB = 0
+			}
+
 			// Check if we need a network resource
 			if len(taskResources.Networks) > 0 {
 				ask := taskResources.Networks[0]
@@ -206,6 +265,21 @@ OUTER:
 				taskResources.Networks = []*structs.NetworkResource{offer}
 			}
 
+			// Check if we need dedicated cores
+			if taskResources.Cores > 0 {
+				cores, err := coreIdx.AssignCores(taskResources.Cores)
+				if err != nil {
+					iter.ctx.Metrics().ExhaustedNode(option.Node,
+						fmt.Sprintf("cores: %s", err))
+					netIdx.Release()
+					continue OUTER
+				}
+
+				// The task is given the full compute of its cores
+				taskResources.CoreIDs = cores
+				taskResources.CPU = coresCPU(option.Node, len(cores))
+			}
+
 			// Store the task resource
 			option.SetTaskResources(task, taskResources)
 
@@ -230,13 +304,23 @@ OUTER:
 		// carefully.
 
 		// Score the fit normally otherwise
-		fitness := structs.ScoreFit(option.Node, util)
+		fitness := iter.scoreFit(option.Node, util)
 		option.Score += fitness
-		iter.ctx.Metrics().ScoreNode(option.Node, "binpack", fitness)
+		iter.ctx.Metrics().ScoreNode(option.Node, string(iter.algorithm), fitness)
 		return option
 	}
 }
 
+// coresCPU returns the compute in MHz of the given number of cores of the
+// node.
+func coresCPU(node *structs.Node, cores int) int {
+	total := len(node.Resources.CoreIDs)
+	if total == 0 {
+		return 0
+	}
+	return node.Resources.CPU / total * cores
+}
+
 func (iter *BinPackIterator) Reset() {
 	iter.source.Reset()
 }
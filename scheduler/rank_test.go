@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/nomad/helper/uuid"
@@ -100,6 +101,391 @@ func TestBinPackIterator_NoExistingAlloc(t *testing.T) {
 	}
 }
 
+func TestBinPackIterator_MemoryOversubscription(t *testing.T) {
+	taskGroup := &structs.TaskGroup{
+		EphemeralDisk: &structs.EphemeralDisk{},
+		Tasks: []*structs.Task{
+			{
+				Name: "web",
+				Resources: &structs.Resources{
+					CPU:         1024,
+					MemoryMB:    1024,
+					MemoryMaxMB: 2048,
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		Config   *structs.SchedulerConfiguration
+		Expected int
+	}{
+		{
+			Name:     "no configuration",
+			Config:   nil,
+			Expected: 0,
+		},
+		{
+			Name:     "disabled",
+			Config:   &structs.SchedulerConfiguration{},
+			Expected: 0,
+		},
+		{
+			Name: "enabled",
+			Config: &structs.SchedulerConfiguration{
+				MemoryOversubscriptionEnabled: true,
+			},
+			Expected: 2048,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			_, ctx := testContext(t)
+			nodes := []*RankedNode{
+				{
+					Node: &structs.Node{
+						Resources: &structs.Resources{
+							CPU:      4096,
+							MemoryMB: 4096,
+						},
+					},
+				},
+			}
+			static := NewStaticRankIterator(ctx, nodes)
+
+			binp := NewBinPackIterator(ctx, static, false, 0)
+			binp.SetSchedulerConfiguration(c.Config)
+			binp.SetTaskGroup(taskGroup)
+
+			out := collectRanked(binp)
+			if len(out) != 1 {
+				t.Fatalf("Bad: %v", out)
+			}
+			res := out[0].TaskResources["web"]
+			if res.MemoryMB != 1024 {
+				t.Fatalf("bad memory: %d", res.MemoryMB)
+			}
+			if res.MemoryMaxMB != c.Expected {
+				t.Fatalf("bad memory max: got %d; want %d", res.MemoryMaxMB, c.Expected)
+			}
+		})
+	}
+}
+
+func TestBinPackIterator_Spread(t *testing.T) {
+	taskGroup := &structs.TaskGroup{
+		EphemeralDisk: &structs.EphemeralDisk{},
+		Tasks: []*structs.Task{
+			{
+				Name: "web",
+				Resources: &structs.Resources{
+					CPU:      1024,
+					MemoryMB: 1024,
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		Name      string
+		Config    *structs.SchedulerConfiguration
+		Preferred int
+	}{
+		{
+			Name:      "binpack",
+			Config:    nil,
+			Preferred: 0,
+		},
+		{
+			Name: "spread",
+			Config: &structs.SchedulerConfiguration{
+				SchedulerAlgorithm: structs.SchedulerAlgorithmSpread,
+			},
+			Preferred: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			_, ctx := testContext(t)
+			nodes := []*RankedNode{
+				{
+					Node: &structs.Node{
+						// Tight fit
+						Resources: &structs.Resources{
+							CPU:      2048,
+							MemoryMB: 2048,
+						},
+					},
+				},
+				{
+					Node: &structs.Node{
+						// Mostly empty
+						Resources: &structs.Resources{
+							CPU:      8192,
+							MemoryMB: 8192,
+						},
+					},
+				},
+			}
+			static := NewStaticRankIterator(ctx, nodes)
+
+			binp := NewBinPackIterator(ctx, static, false, 0)
+			binp.SetSchedulerConfiguration(c.Config)
+			binp.SetTaskGroup(taskGroup)
+
+			out := collectRanked(binp)
+			if len(out) != 2 {
+				t.Fatalf("Bad: %v", out)
+			}
+			other := 1 - c.Preferred
+			if out[c.Preferred].Score <= out[other].Score {
+				t.Fatalf("expected node %d to score higher: %v > %v",
+					c.Preferred, out[c.Preferred].Score, out[other].Score)
+			}
+		})
+	}
+}
+
+func TestBinPackIterator_Cores(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*RankedNode{
+		{
+			Node: &structs.Node{
+				// No reservable cores
+				Resources: &structs.Resources{
+					CPU:      4000,
+					MemoryMB: 4096,
+				},
+			},
+		},
+		{
+			Node: &structs.Node{
+				// Four cores with the first reserved for the host
+				Resources: &structs.Resources{
+					CPU:      4000,
+					MemoryMB: 4096,
+					CoreIDs:  []uint16{0, 1, 2, 3},
+				},
+				Reserved: &structs.Resources{
+					CoreIDs: []uint16{0},
+				},
+			},
+		},
+	}
+	static := NewStaticRankIterator(ctx, nodes)
+
+	taskGroup := &structs.TaskGroup{
+		EphemeralDisk: &structs.EphemeralDisk{},
+		Tasks: []*structs.Task{
+			{
+				Name: "web",
+				Resources: &structs.Resources{
+					CPU:      100,
+					MemoryMB: 256,
+					Cores:    2,
+				},
+			},
+		},
+	}
+	binp := NewBinPackIterator(ctx, static, false, 0)
+	binp.SetTaskGroup(taskGroup)
+
+	out := collectRanked(binp)
+	if len(out) != 1 {
+		t.Fatalf("Bad: %v", out)
+	}
+	if out[0] != nodes[1] {
+		t.Fatalf("Bad: %v", out)
+	}
+
+	// The lowest free cores are reserved and the task gets their compute
+	res := out[0].TaskResources["web"]
+	if !reflect.DeepEqual(res.CoreIDs, []uint16{1, 2}) {
+		t.Fatalf("bad cores: %v", res.CoreIDs)
+	}
+	if res.CPU != 2000 {
+		t.Fatalf("bad cpu: %d", res.CPU)
+	}
+	if ctx.Metrics().DimensionExhausted["cores: 2 cores requested but 0 available"] != 1 {
+		t.Fatalf("bad: %#v", ctx.Metrics().DimensionExhausted)
+	}
+}
+
+func TestBinPackIterator_Cores_ExistingAlloc(t *testing.T) {
+	state, ctx := testContext(t)
+	nodes := []*RankedNode{
+		{
+			Node: &structs.Node{
+				ID: uuid.Generate(),
+				Resources: &structs.Resources{
+					CPU:      4000,
+					MemoryMB: 4096,
+					CoreIDs:  []uint16{0, 1, 2, 3},
+				},
+			},
+		},
+	}
+	static := NewStaticRankIterator(ctx, nodes)
+
+	// Add an existing allocation that reserves some cores
+	j1 := mock.Job()
+	alloc1 := &structs.Allocation{
+		Namespace: structs.DefaultNamespace,
+		ID:        uuid.Generate(),
+		EvalID:    uuid.Generate(),
+		NodeID:    nodes[0].Node.ID,
+		JobID:     j1.ID,
+		Job:       j1,
+		TaskResources: map[string]*structs.Resources{
+			"web": {
+				CPU:      2000,
+				MemoryMB: 256,
+				Cores:    2,
+				CoreIDs:  []uint16{0, 1},
+			},
+		},
+		DesiredStatus: structs.AllocDesiredStatusRun,
+		ClientStatus:  structs.AllocClientStatusPending,
+		TaskGroup:     "web",
+	}
+	noErr(t, state.UpsertJobSummary(998, mock.JobSummary(alloc1.JobID)))
+	noErr(t, state.UpsertAllocs(1000, []*structs.Allocation{alloc1}))
+
+	taskGroup := &structs.TaskGroup{
+		EphemeralDisk: &structs.EphemeralDisk{},
+		Tasks: []*structs.Task{
+			{
+				Name: "web",
+				Resources: &structs.Resources{
+					CPU:      100,
+					MemoryMB: 256,
+					Cores:    2,
+				},
+			},
+		},
+	}
+	binp := NewBinPackIterator(ctx, static, false, 0)
+	binp.SetTaskGroup(taskGroup)
+
+	out := collectRanked(binp)
+	if len(out) != 1 {
+		t.Fatalf("Bad: %v", out)
+	}
+	if cores := out[0].TaskResources["web"].CoreIDs; !reflect.DeepEqual(cores, []uint16{2, 3}) {
+		t.Fatalf("bad cores: %v", cores)
+	}
+
+	// No cores are left for a third task
+	taskGroup.Tasks = append(taskGroup.Tasks, &structs.Task{
+		Name: "db",
+		Resources: &structs.Resources{
+			CPU:      100,
+			MemoryMB: 256,
+			Cores:    1,
+		},
+	})
+	binp.Reset()
+	binp.SetTaskGroup(taskGroup)
+	out = collectRanked(binp)
+	if len(out) != 0 {
+		t.Fatalf("Bad: %v", out)
+	}
+}
+
+func TestBinPackIterator_GroupNetwork(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*RankedNode{
+		{
+			Node: &structs.Node{
+				Resources: &structs.Resources{
+					CPU:      2048,
+					MemoryMB: 2048,
+					Networks: []*structs.NetworkResource{
+						{
+							Device: "eth0",
+							CIDR:   "192.168.0.100/32",
+							MBits:  100,
+						},
+					},
+				},
+			},
+		},
+		{
+			Node: &structs.Node{
+				// Static port already reserved
+				Resources: &structs.Resources{
+					CPU:      2048,
+					MemoryMB: 2048,
+					Networks: []*structs.NetworkResource{
+						{
+							Device: "eth0",
+							CIDR:   "192.168.0.101/32",
+							MBits:  100,
+						},
+					},
+				},
+				Reserved: &structs.Resources{
+					Networks: []*structs.NetworkResource{
+						{
+							Device:        "eth0",
+							IP:            "192.168.0.101",
+							ReservedPorts: []structs.Port{{Label: "ssh", Value: 8080}},
+						},
+					},
+				},
+			},
+		},
+	}
+	static := NewStaticRankIterator(ctx, nodes)
+
+	taskGroup := &structs.TaskGroup{
+		EphemeralDisk: &structs.EphemeralDisk{},
+		Networks: []*structs.NetworkResource{
+			{
+				Mode:          structs.NetworkModeBridge,
+				MBits:         10,
+				ReservedPorts: []structs.Port{{Label: "http", Value: 8080}},
+				DynamicPorts:  []structs.Port{{Label: "admin"}},
+			},
+		},
+		Tasks: []*structs.Task{
+			{
+				Name: "web",
+				Resources: &structs.Resources{
+					CPU:      1024,
+					MemoryMB: 1024,
+				},
+			},
+		},
+	}
+	binp := NewBinPackIterator(ctx, static, false, 0)
+	binp.SetTaskGroup(taskGroup)
+
+	out := collectRanked(binp)
+	if len(out) != 1 || out[0] != nodes[0] {
+		t.Fatalf("Bad: %v", out)
+	}
+
+	if len(out[0].GroupNetworks) != 1 {
+		t.Fatalf("expected a group network offer: %#v", out[0].GroupNetworks)
+	}
+	offer := out[0].GroupNetworks[0]
+	if offer.IP != "192.168.0.100" || offer.Mode != structs.NetworkModeBridge {
+		t.Fatalf("bad offer: %#v", offer)
+	}
+	if len(offer.DynamicPorts) != 1 || offer.DynamicPorts[0].Value == 0 {
+		t.Fatalf("expected a dynamic port to be assigned: %#v", offer)
+	}
+
+	// The ask must not be modified by the offer
+	if taskGroup.Networks[0].DynamicPorts[0].Value != 0 {
+		t.Fatalf("task group network ask was modified: %#v", taskGroup.Networks[0])
+	}
+}
+
 func TestBinPackIterator_PlannedAlloc(t *testing.T) {
 	_, ctx := testContext(t)
 	nodes := []*RankedNode{
@@ -58,6 +58,10 @@ type allocReconciler struct {
 	// existingAllocs is non-terminal existing allocations
 	existingAllocs []*structs.Allocation
 
+	// now is the time the max_client_disconnect windows of allocations on
+	// down nodes are compared against
+	now time.Time
+
 	// result is the results of the reconcile. During computation it can be
 	// used to store intermediate state
 	result *reconcileResults
@@ -93,6 +97,15 @@ type reconcileResults struct {
 	// followupEvalWait is set if there should be a followup eval run after the
 	// given duration
 	followupEvalWait time.Duration
+
+	// disconnectUpdates is the set of allocations on down nodes that are
+	// within their max_client_disconnect window and should be marked unknown
+	disconnectUpdates []*structs.Allocation
+
+	// disconnectEvalWait is set if allocations are waiting out their
+	// max_client_disconnect window and a followup eval should replace them
+	// once the first window expires
+	disconnectEvalWait time.Duration
 }
 
 func (r *reconcileResults) GoString() string {
@@ -109,6 +122,12 @@ func (r *reconcileResults) GoString() string {
 	if r.followupEvalWait != 0 {
 		base += fmt.Sprintf("\nFollowup Eval in %v", r.followupEvalWait)
 	}
+	if len(r.disconnectUpdates) != 0 {
+		base += fmt.Sprintf("\nDisconnected: %d", len(r.disconnectUpdates))
+	}
+	if r.disconnectEvalWait != 0 {
+		base += fmt.Sprintf("\nDisconnect Eval in %v", r.disconnectEvalWait)
+	}
 	for tg, u := range r.desiredTGUpdates {
 		base += fmt.Sprintf("\nDesired Changes for %q: %#v", tg, u)
 	}
@@ -135,6 +154,7 @@ func NewAllocReconciler(logger *log.Logger, allocUpdateFn allocUpdateType, batch
 		deployment:     deployment.Copy(),
 		existingAllocs: existingAllocs,
 		taintedNodes:   taintedNodes,
+		now:            time.Now(),
 		result: &reconcileResults{
 			desiredTGUpdates: make(map[string]*structs.DesiredUpdates),
 		},
@@ -305,9 +325,15 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 	// Determine what set of allocations are on tainted nodes
 	untainted, migrate, lost := all.filterByTainted(a.taintedNodes)
 
+	// Allocations on down nodes are not replaced until the group's
+	// max_client_disconnect window expires
+	disconnecting, lost := a.handleDisconnecting(tg, lost)
+	desiredChanges.Ignore += uint64(len(disconnecting))
+
 	// Create a structure for choosing names. Seed with the taken names which is
-	// the union of untainted and migrating nodes (includes canaries)
-	nameIndex := newAllocNameIndex(a.jobID, group, tg.Count, untainted.union(migrate))
+	// the union of untainted, migrating and disconnecting allocations
+	// (includes canaries)
+	nameIndex := newAllocNameIndex(a.jobID, group, tg.Count, untainted.union(migrate, disconnecting))
 
 	// Stop any unneeded allocations and update the untainted set to not
 	// included stopped allocations.
@@ -364,7 +390,7 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 	// * The deployment is not paused or failed
 	// * Not placing any canaries
 	// * If there are any canaries that they have been promoted
-	place := a.computePlacements(tg, nameIndex, untainted, migrate)
+	place := a.computePlacements(tg, nameIndex, untainted.union(disconnecting), migrate)
 	if !existingDeployment {
 		dstate.DesiredTotal += len(place)
 	}
@@ -373,7 +399,12 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 	// placements can be made without any other consideration.
 	deploymentPlaceReady := !a.deploymentPaused && !a.deploymentFailed && !canaryState
 
-	if deploymentPlaceReady {
+	// Hold back placements until the task groups this group depends on have
+	// reached their required condition. The job is re-evaluated once the
+	// status or health of their allocations changes.
+	dependenciesMet := len(place) == 0 || a.groupDependenciesMet(tg)
+
+	if deploymentPlaceReady && dependenciesMet {
 		desiredChanges.Place += uint64(len(place))
 		for _, p := range place {
 			a.result.place = append(a.result.place, p)
@@ -381,7 +412,7 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 
 		min := helper.IntMin(len(place), limit)
 		limit -= min
-	} else if !deploymentPlaceReady && len(lost) != 0 {
+	} else if !deploymentPlaceReady && dependenciesMet && len(lost) != 0 {
 		// We are in a situation where we shouldn't be placing more than we need
 		// to but we have lost allocations. It is a very weird user experience
 		// if you have a node go down and Nomad doesn't replace the allocations
@@ -488,6 +519,24 @@ func (a *allocReconciler) computeGroup(group string, all allocSet) bool {
 	return deploymentComplete
 }
 
+// handleDisconnecting splits the lost allocations of the group into those
+// waiting out the group's max_client_disconnect window and those that are
+// lost. The waiting allocations are marked unknown and a followup eval is
+// requested for when the first window expires.
+func (a *allocReconciler) handleDisconnecting(group *structs.TaskGroup, all allocSet) (disconnecting, lost allocSet) {
+	disconnecting, lost, wait := all.filterByDisconnect(a.taintedNodes, group.MaxClientDisconnect, a.now)
+	for _, alloc := range disconnecting.nameOrder() {
+		if alloc.ClientStatus != structs.AllocClientStatusUnknown {
+			a.result.disconnectUpdates = append(a.result.disconnectUpdates, alloc)
+		}
+	}
+
+	if wait != 0 && (a.result.disconnectEvalWait == 0 || wait < a.result.disconnectEvalWait) {
+		a.result.disconnectEvalWait = wait
+	}
+	return disconnecting, lost
+}
+
 // batchFiltration filters batch allocations that should be ignored. These are
 // allocations that are terminal from a previous job version.
 func (a *allocReconciler) batchFiltration(all allocSet) (filtered, ignore allocSet) {
@@ -628,6 +677,54 @@ func (a *allocReconciler) computePlacements(group *structs.TaskGroup,
 	return place
 }
 
+// groupDependenciesMet returns whether every task group the given group
+// depends on has reached the condition required by the dependency.
+func (a *allocReconciler) groupDependenciesMet(group *structs.TaskGroup) bool {
+	for _, d := range group.DependsOn {
+		other := a.job.LookupTaskGroup(d.Group)
+		if other == nil {
+			continue
+		}
+
+		ready := 0
+		for _, alloc := range a.existingAllocs {
+			if alloc.TaskGroup != other.Name || alloc.DesiredStatus != structs.AllocDesiredStatusRun {
+				continue
+			}
+
+			switch d.Condition {
+			case structs.TaskGroupDependencyComplete:
+				if alloc.ClientStatus != structs.AllocClientStatusComplete {
+					continue
+				}
+			case structs.TaskGroupDependencyHealthy:
+				if other.Update != nil && !a.batch {
+					if alloc.ClientStatus != structs.AllocClientStatusRunning || !alloc.DeploymentStatus.IsHealthy() {
+						continue
+					}
+					break
+				}
+				fallthrough
+			default:
+				// Completed batch allocations have run so they satisfy the
+				// dependency as well.
+				switch alloc.ClientStatus {
+				case structs.AllocClientStatusRunning, structs.AllocClientStatusComplete:
+				default:
+					continue
+				}
+			}
+			ready++
+		}
+
+		if ready < other.Count {
+			return false
+		}
+	}
+
+	return true
+}
+
 // computeStop returns the set of allocations that are marked for stopping given
 // the group definition, the set of allocations in various states and whether we
 // are canarying.
@@ -266,14 +266,15 @@ func allocsToNames(allocs []*structs.Allocation) []string {
 }
 
 type resultExpectation struct {
-	createDeployment  *structs.Deployment
-	deploymentUpdates []*structs.DeploymentStatusUpdate
-	place             int
-	destructive       int
-	inplace           int
-	stop              int
-	desiredTGUpdates  map[string]*structs.DesiredUpdates
-	followupEvalWait  time.Duration
+	createDeployment   *structs.Deployment
+	deploymentUpdates  []*structs.DeploymentStatusUpdate
+	place              int
+	destructive        int
+	inplace            int
+	stop               int
+	desiredTGUpdates   map[string]*structs.DesiredUpdates
+	followupEvalWait   time.Duration
+	disconnectEvalWait time.Duration
 }
 
 func assertResults(t *testing.T, r *reconcileResults, exp *resultExpectation) {
@@ -312,6 +313,9 @@ func assertResults(t *testing.T, r *reconcileResults, exp *resultExpectation) {
 	if r.followupEvalWait != exp.followupEvalWait {
 		t.Fatalf("Unexpected followup eval wait time. Got %v; want %v", r.followupEvalWait, exp.followupEvalWait)
 	}
+	if r.disconnectEvalWait != exp.disconnectEvalWait {
+		t.Fatalf("Unexpected disconnect eval wait time. Got %v; want %v", r.disconnectEvalWait, exp.disconnectEvalWait)
+	}
 
 	// Check the desired updates happened
 	for group, desired := range exp.desiredTGUpdates {
@@ -776,6 +780,121 @@ func TestReconciler_LostNode(t *testing.T) {
 	assertNamesHaveIndexes(t, intRange(0, 1), placeResultsToNames(r.place))
 }
 
+// Tests the reconciler doesn't replace allocations on down nodes that are
+// within the group's max_client_disconnect window and marks them unknown
+func TestReconciler_LostNode_MaxClientDisconnect(t *testing.T) {
+	job := mock.Job()
+	job.TaskGroups[0].MaxClientDisconnect = helper.TimeToPtr(10 * time.Minute)
+	now := time.Unix(time.Now().Unix(), 0)
+
+	// Create 10 existing allocations
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		allocs = append(allocs, alloc)
+	}
+
+	// The first node went down 2 minutes ago and the second one 5 minutes ago
+	// but its allocation was already marked unknown
+	allocs[1].ClientStatus = structs.AllocClientStatusUnknown
+	tainted := make(map[string]*structs.Node, 2)
+	for i := 0; i < 2; i++ {
+		n := mock.Node()
+		n.ID = allocs[i].NodeID
+		n.Status = structs.NodeStatusDown
+		n.StatusUpdatedAt = now.Add(-time.Duration(2+3*i) * time.Minute).Unix()
+		tainted[n.ID] = n
+	}
+
+	reconciler := NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, tainted)
+	reconciler.now = now
+	r := reconciler.Compute()
+
+	// Assert the correct results
+	assertResults(t, r, &resultExpectation{
+		createDeployment:   nil,
+		deploymentUpdates:  nil,
+		place:              0,
+		inplace:            0,
+		stop:               0,
+		disconnectEvalWait: 5 * time.Minute,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				Ignore: 10,
+			},
+		},
+	})
+
+	if l := len(r.disconnectUpdates); l != 1 || r.disconnectUpdates[0].ID != allocs[0].ID {
+		t.Fatalf("expected only the first alloc to be marked unknown; got %d updates", l)
+	}
+}
+
+// Tests the reconciler replaces allocations on down nodes once the group's
+// max_client_disconnect window expires
+func TestReconciler_LostNode_MaxClientDisconnect_Expired(t *testing.T) {
+	job := mock.Job()
+	job.TaskGroups[0].MaxClientDisconnect = helper.TimeToPtr(10 * time.Minute)
+	now := time.Unix(time.Now().Unix(), 0)
+
+	// Create 10 existing allocations
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.ClientStatus = structs.AllocClientStatusUnknown
+		allocs = append(allocs, alloc)
+	}
+
+	// Build a map of tainted nodes that went down before the window
+	tainted := make(map[string]*structs.Node, 2)
+	for i := 0; i < 2; i++ {
+		n := mock.Node()
+		n.ID = allocs[i].NodeID
+		n.Status = structs.NodeStatusDown
+		n.StatusUpdatedAt = now.Add(-11 * time.Minute).Unix()
+		tainted[n.ID] = n
+	}
+
+	reconciler := NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, tainted)
+	reconciler.now = now
+	r := reconciler.Compute()
+
+	// Assert the correct results
+	assertResults(t, r, &resultExpectation{
+		createDeployment:  nil,
+		deploymentUpdates: nil,
+		place:             2,
+		inplace:           0,
+		stop:              2,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				Place:  2,
+				Stop:   2,
+				Ignore: 8,
+			},
+		},
+	})
+
+	if l := len(r.disconnectUpdates); l != 0 {
+		t.Fatalf("expected no allocs to be marked unknown; got %d", l)
+	}
+	for _, stop := range r.stop {
+		if stop.clientStatus != structs.AllocClientStatusLost {
+			t.Fatalf("expected stopped alloc to be lost; got %q", stop.clientStatus)
+		}
+	}
+	assertNamesHaveIndexes(t, intRange(0, 1), stopResultsToNames(r.stop))
+	assertNamesHaveIndexes(t, intRange(0, 1), placeResultsToNames(r.place))
+}
+
 // Tests the reconciler properly handles lost nodes with allocations while
 // scaling up
 func TestReconciler_LostNode_ScaleUp(t *testing.T) {
@@ -1168,6 +1287,131 @@ func TestReconciler_MultiTG(t *testing.T) {
 	assertNamesHaveIndexes(t, intRange(2, 9, 0, 9), placeResultsToNames(r.place))
 }
 
+// Tests the reconciler holds back placements of a task group until the task
+// groups it depends on are running
+func TestReconciler_GroupDependency_Running(t *testing.T) {
+	job := mock.Job()
+	tg2 := job.TaskGroups[0].Copy()
+	tg2.Name = "foo"
+	tg2.DependsOn = []*structs.TaskGroupDependency{
+		{
+			Group:     job.TaskGroups[0].Name,
+			Condition: structs.TaskGroupDependencyRunning,
+		},
+	}
+	job.TaskGroups = append(job.TaskGroups, tg2)
+
+	// Create pending allocations for the first tg
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.ClientStatus = structs.AllocClientStatusPending
+		allocs = append(allocs, alloc)
+	}
+
+	reconciler := NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, nil)
+	r := reconciler.Compute()
+
+	// Assert the placements for the second tg are held back
+	assertResults(t, r, &resultExpectation{
+		createDeployment:  nil,
+		deploymentUpdates: nil,
+		place:             0,
+		inplace:           0,
+		stop:              0,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				Ignore: 10,
+			},
+			tg2.Name: {},
+		},
+	})
+
+	// Mark all but one of the allocations as running
+	for _, alloc := range allocs[1:] {
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+	}
+	reconciler = NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, nil)
+	r = reconciler.Compute()
+	if len(r.place) != 0 {
+		t.Fatalf("expected placements to be held back: %#v", r)
+	}
+
+	// Mark the remaining allocation as running
+	allocs[0].ClientStatus = structs.AllocClientStatusRunning
+	reconciler = NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, nil)
+	r = reconciler.Compute()
+
+	assertResults(t, r, &resultExpectation{
+		createDeployment:  nil,
+		deploymentUpdates: nil,
+		place:             10,
+		inplace:           0,
+		stop:              0,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				Ignore: 10,
+			},
+			tg2.Name: {
+				Place: 10,
+			},
+		},
+	})
+
+	assertNamesHaveIndexes(t, intRange(0, 9), placeResultsToNames(r.place))
+}
+
+// Tests the reconciler holds back placements of a task group until the
+// allocations of the task group it depends on are healthy
+func TestReconciler_GroupDependency_Healthy(t *testing.T) {
+	job := mock.Job()
+	job.TaskGroups[0].Update = noCanaryUpdate
+	tg2 := job.TaskGroups[0].Copy()
+	tg2.Name = "foo"
+	tg2.DependsOn = []*structs.TaskGroupDependency{
+		{
+			Group:     job.TaskGroups[0].Name,
+			Condition: structs.TaskGroupDependencyHealthy,
+		},
+	}
+	job.TaskGroups = append(job.TaskGroups, tg2)
+
+	// Create running allocations for the first tg that have not yet been
+	// marked healthy
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		allocs = append(allocs, alloc)
+	}
+
+	reconciler := NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, nil)
+	r := reconciler.Compute()
+	if len(r.place) != 0 {
+		t.Fatalf("expected placements to be held back: %#v", r)
+	}
+
+	// Mark the allocations as healthy
+	for _, alloc := range allocs {
+		alloc.DeploymentStatus = &structs.AllocDeploymentStatus{
+			Healthy: helper.BoolToPtr(true),
+		}
+	}
+	reconciler = NewAllocReconciler(testLogger(), allocUpdateFnIgnore, false, job.ID, job, nil, allocs, nil)
+	r = reconciler.Compute()
+	if len(r.place) != 10 {
+		t.Fatalf("expected placements to be made: %#v", r)
+	}
+}
+
 // Tests the reconciler cancels an old deployment when the job is being stopped
 func TestReconciler_CancelDeployment_JobStop(t *testing.T) {
 	job := mock.Job()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -216,6 +217,34 @@ func (a allocSet) filterByTainted(nodes map[string]*structs.Node) (untainted, mi
 	return
 }
 
+// filterByDisconnect filters lost allocations into those whose node is down
+// but still within the task group's max_client_disconnect window and those
+// that are truly lost. It also returns the time until the first window of the
+// disconnecting allocations expires.
+func (a allocSet) filterByDisconnect(nodes map[string]*structs.Node, window *time.Duration, now time.Time) (disconnecting, lost allocSet, wait time.Duration) {
+	disconnecting = make(map[string]*structs.Allocation)
+	lost = make(map[string]*structs.Allocation)
+	for _, alloc := range a {
+		n := nodes[alloc.NodeID]
+		if window == nil || n == nil || n.Status != structs.NodeStatusDown {
+			lost[alloc.ID] = alloc
+			continue
+		}
+
+		expires := time.Unix(n.StatusUpdatedAt, 0).Add(*window)
+		if !expires.After(now) {
+			lost[alloc.ID] = alloc
+			continue
+		}
+
+		disconnecting[alloc.ID] = alloc
+		if remaining := expires.Sub(now); wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return
+}
+
 // filterByDeployment filters allocations into two sets, those that match the
 // given deployment ID and those that don't
 func (a allocSet) filterByDeployment(id string) (match, nonmatch allocSet) {
@@ -19,9 +19,10 @@ const (
 // BuiltinSchedulers contains the built in registered schedulers
 // which are available
 var BuiltinSchedulers = map[string]Factory{
-	"service": NewServiceScheduler,
-	"batch":   NewBatchScheduler,
-	"system":  NewSystemScheduler,
+	"service":  NewServiceScheduler,
+	"batch":    NewBatchScheduler,
+	"system":   NewSystemScheduler,
+	"sysbatch": NewSysBatchScheduler,
 }
 
 // NewScheduler is used to instantiate and return a new scheduler
@@ -83,6 +84,13 @@ type State interface {
 	// LatestDeploymentByJobID returns the latest deployment matching the given
 	// job ID
 	LatestDeploymentByJobID(ws memdb.WatchSet, namespace, jobID string) (*structs.Deployment, error)
+
+	// SchedulerConfig returns the cluster wide scheduler configuration and
+	// the index it was last modified at
+	SchedulerConfig() (uint64, *structs.SchedulerConfiguration, error)
+
+	// NodePoolByName is used to lookup a node pool by name
+	NodePoolByName(ws memdb.WatchSet, name string) (*structs.NodePool, error)
 }
 
 // Planner interface is used to submit a task allocation plan.
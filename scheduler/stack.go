@@ -40,6 +40,7 @@ type GenericStack struct {
 	source *StaticIterator
 
 	wrappedChecks       *FeasibilityWrapper
+	nodePool            *NodePoolChecker
 	jobConstraint       *ConstraintChecker
 	taskGroupDrivers    *DriverChecker
 	taskGroupConstraint *ConstraintChecker
@@ -65,6 +66,10 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// balancing across eligible nodes.
 	s.source = NewRandomIterator(ctx, nil)
 
+	// Filter on the job's node pool before any constraints. The job is
+	// filled in later.
+	s.nodePool = NewNodePoolChecker(ctx, "")
+
 	// Attach the job constraints. The job is filled in later.
 	s.jobConstraint = NewConstraintChecker(ctx, nil)
 
@@ -78,7 +83,7 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// which feasibility checking can be skipped if the computed node class has
 	// previously been marked as eligible or ineligible. Generally this will be
 	// checks that only needs to examine the single node to determine feasibility.
-	jobs := []FeasibilityChecker{s.jobConstraint}
+	jobs := []FeasibilityChecker{s.nodePool, s.jobConstraint}
 	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint}
 	s.wrappedChecks = NewFeasibilityWrapper(ctx, s.source, jobs, tgs)
 
@@ -137,6 +142,7 @@ func (s *GenericStack) SetNodes(baseNodes []*structs.Node) {
 }
 
 func (s *GenericStack) SetJob(job *structs.Job) {
+	s.nodePool.SetNodePool(job.NodePool)
 	s.jobConstraint.SetConstraints(job.Constraints)
 	s.distinctHostsConstraint.SetJob(job)
 	s.distinctPropertyConstraint.SetJob(job)
@@ -145,6 +151,11 @@ func (s *GenericStack) SetJob(job *structs.Job) {
 	s.ctx.Eligibility().SetJob(job)
 }
 
+// SetSchedulerConfiguration applies the cluster wide scheduler configuration
+func (s *GenericStack) SetSchedulerConfiguration(config *structs.SchedulerConfiguration) {
+	s.binPack.SetSchedulerConfiguration(config)
+}
+
 func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Resources) {
 	// Reset the max selector and context
 	s.maxScore.Reset()
@@ -196,6 +207,7 @@ type SystemStack struct {
 	ctx                        Context
 	source                     *StaticIterator
 	wrappedChecks              *FeasibilityWrapper
+	nodePool                   *NodePoolChecker
 	jobConstraint              *ConstraintChecker
 	taskGroupDrivers           *DriverChecker
 	taskGroupConstraint        *ConstraintChecker
@@ -212,6 +224,10 @@ func NewSystemStack(ctx Context) *SystemStack {
 	// have to evaluate on all nodes.
 	s.source = NewStaticIterator(ctx, nil)
 
+	// Filter on the job's node pool before any constraints. The job is
+	// filled in later.
+	s.nodePool = NewNodePoolChecker(ctx, "")
+
 	// Attach the job constraints. The job is filled in later.
 	s.jobConstraint = NewConstraintChecker(ctx, nil)
 
@@ -225,7 +241,7 @@ func NewSystemStack(ctx Context) *SystemStack {
 	// which feasibility checking can be skipped if the computed node class has
 	// previously been marked as eligible or ineligible. Generally this will be
 	// checks that only needs to examine the single node to determine feasibility.
-	jobs := []FeasibilityChecker{s.jobConstraint}
+	jobs := []FeasibilityChecker{s.nodePool, s.jobConstraint}
 	tgs := []FeasibilityChecker{s.taskGroupDrivers, s.taskGroupConstraint}
 	s.wrappedChecks = NewFeasibilityWrapper(ctx, s.source, jobs, tgs)
 
@@ -248,12 +264,18 @@ func (s *SystemStack) SetNodes(baseNodes []*structs.Node) {
 }
 
 func (s *SystemStack) SetJob(job *structs.Job) {
+	s.nodePool.SetNodePool(job.NodePool)
 	s.jobConstraint.SetConstraints(job.Constraints)
 	s.distinctPropertyConstraint.SetJob(job)
 	s.binPack.SetPriority(job.Priority)
 	s.ctx.Eligibility().SetJob(job)
 }
 
+// SetSchedulerConfiguration applies the cluster wide scheduler configuration
+func (s *SystemStack) SetSchedulerConfiguration(config *structs.SchedulerConfiguration) {
+	s.binPack.SetSchedulerConfiguration(config)
+}
+
 func (s *SystemStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Resources) {
 	// Reset the binpack selector and context
 	s.binPack.Reset()
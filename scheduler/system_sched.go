@@ -20,12 +20,15 @@ const (
 	allocNodeTainted = "alloc not needed as node is tainted"
 )
 
-// SystemScheduler is used for 'system' jobs. This scheduler is
-// designed for services that should be run on every client.
+// SystemScheduler is used for 'system' and 'sysbatch' jobs. This scheduler
+// is designed for services that should be run on every client. In sysbatch
+// mode the tasks run to completion on every client and completed
+// allocations are not replaced.
 type SystemScheduler struct {
-	logger  *log.Logger
-	state   State
-	planner Planner
+	logger   *log.Logger
+	state    State
+	planner  Planner
+	sysbatch bool
 
 	eval       *structs.Evaluation
 	job        *structs.Job
@@ -47,9 +50,21 @@ type SystemScheduler struct {
 // scheduler.
 func NewSystemScheduler(logger *log.Logger, state State, planner Planner) Scheduler {
 	return &SystemScheduler{
-		logger:  logger,
-		state:   state,
-		planner: planner,
+		logger:   logger,
+		state:    state,
+		planner:  planner,
+		sysbatch: false,
+	}
+}
+
+// NewSysBatchScheduler is a factory function to instantiate a new sysbatch
+// scheduler.
+func NewSysBatchScheduler(logger *log.Logger, state State, planner Planner) Scheduler {
+	return &SystemScheduler{
+		logger:   logger,
+		state:    state,
+		planner:  planner,
+		sysbatch: true,
 	}
 }
 
@@ -62,7 +77,7 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) error {
 	switch eval.TriggeredBy {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerNodeUpdate,
 		structs.EvalTriggerJobDeregister, structs.EvalTriggerRollingUpdate,
-		structs.EvalTriggerDeploymentWatcher:
+		structs.EvalTriggerDeploymentWatcher, structs.EvalTriggerPeriodicJob:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
@@ -125,6 +140,13 @@ func (s *SystemScheduler) process() (bool, error) {
 		s.stack.SetJob(s.job)
 	}
 
+	// Apply the scheduler configuration of the job and its node pool
+	schedConfig, err := jobSchedulerConfig(s.state, s.job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get scheduler configuration: %v", err)
+	}
+	s.stack.SetSchedulerConfiguration(schedConfig)
+
 	// Compute the target job allocations
 	if err := s.computeJobAllocs(); err != nil {
 		s.logger.Printf("[ERR] sched: %#v: %v", s.eval, err)
@@ -200,8 +222,16 @@ func (s *SystemScheduler) computeJobAllocs() error {
 	// nodes to lost
 	updateNonTerminalAllocsToLost(s.plan, tainted, allocs)
 
+	// Keep the sysbatch allocations that already completed, so they are
+	// treated as done rather than replaced
+	var completed []*structs.Allocation
+	if s.sysbatch && !s.job.Stopped() {
+		allocs, completed = filterCompletedAllocs(s.job, allocs)
+	}
+
 	// Filter out the allocations in a terminal state
 	allocs, terminalAllocs := structs.FilterTerminalAllocs(allocs)
+	allocs = append(allocs, completed...)
 
 	// Diff the required and existing allocations
 	diff := diffSystemAllocs(s.job, s.nodes, tainted, allocs, terminalAllocs)
@@ -320,7 +350,8 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 				ClientStatus:  structs.AllocClientStatusPending,
 
 				SharedResources: &structs.Resources{
-					DiskMB: missing.TaskGroup.EphemeralDisk.SizeMB,
+					DiskMB:   missing.TaskGroup.EphemeralDisk.SizeMB,
+					Networks: option.GroupNetworks,
 				},
 			}
 
@@ -343,3 +374,17 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 
 	return nil
 }
+
+// filterCompletedAllocs splits off the allocations that completed
+// successfully for the current version of the job. Completed allocations of
+// older versions are left in place so they are replaced.
+func filterCompletedAllocs(job *structs.Job, allocs []*structs.Allocation) (remaining, completed []*structs.Allocation) {
+	for _, alloc := range allocs {
+		if alloc.RanSuccessfully() && alloc.Job != nil && alloc.Job.JobModifyIndex == job.JobModifyIndex {
+			completed = append(completed, alloc)
+		} else {
+			remaining = append(remaining, alloc)
+		}
+	}
+	return remaining, completed
+}
@@ -95,12 +95,13 @@ func diffAllocs(job *structs.Job, taintedNodes map[string]*structs.Node,
 		// If we are on a tainted node, we must migrate if we are a service or
 		// if the batch allocation did not finish
 		if node, ok := taintedNodes[exist.NodeID]; ok {
-			// If the job is batch and finished successfully, the fact that the
-			// node is tainted does not mean it should be migrated or marked as
-			// lost as the work was already successfully finished. However for
-			// service/system jobs, tasks should never complete. The check of
-			// batch type, defends against client bugs.
-			if exist.Job.Type == structs.JobTypeBatch && exist.RanSuccessfully() {
+			// If the job is batch or sysbatch and finished successfully, the
+			// fact that the node is tainted does not mean it should be migrated
+			// or marked as lost as the work was already successfully finished.
+			// However for service/system jobs, tasks should never complete. The
+			// check of batch type, defends against client bugs.
+			batch := exist.Job.Type == structs.JobTypeBatch || exist.Job.Type == structs.JobTypeSysBatch
+			if batch && exist.RanSuccessfully() {
 				goto IGNORE
 			}
 
@@ -258,6 +259,29 @@ func readyNodesInDCs(state State, dcs []string) ([]*structs.Node, map[string]int
 	return out, dcMap, nil
 }
 
+// jobSchedulerConfig returns the cluster wide scheduler configuration with
+// the overrides of the job's node pool and of the job applied. The job may be
+// nil.
+func jobSchedulerConfig(state State, job *structs.Job) (*structs.SchedulerConfiguration, error) {
+	_, config, err := state.SchedulerConfig()
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return config, nil
+	}
+
+	pool := job.NodePool
+	if pool == "" {
+		pool = structs.NodePoolDefault
+	}
+	nodePool, err := state.NodePoolByName(nil, pool)
+	if err != nil {
+		return nil, err
+	}
+	return config.WithNodePool(nodePool).WithJob(job), nil
+}
+
 // retryMax is used to retry a callback until it returns success or
 // a maximum number of attempts is reached. An optional reset function may be
 // passed which is called after each failed iteration. If the reset function is
@@ -348,6 +372,11 @@ func tasksUpdated(jobA, jobB *structs.Job, taskGroup string) bool {
 		return true
 	}
 
+	// Check the shared network. Network offers can not be updated in place.
+	if !reflect.DeepEqual(a.Networks, b.Networks) {
+		return true
+	}
+
 	// Check each task
 	for _, at := range a.Tasks {
 		bt := b.LookupTask(at.Name)
@@ -372,6 +401,9 @@ func tasksUpdated(jobA, jobB *structs.Job, taskGroup string) bool {
 		if !reflect.DeepEqual(at.Vault, bt.Vault) {
 			return true
 		}
+		if !reflect.DeepEqual(at.LogShippers, bt.LogShippers) {
+			return true
+		}
 		if !reflect.DeepEqual(at.Templates, bt.Templates) {
 			return true
 		}
@@ -406,6 +438,10 @@ func tasksUpdated(jobA, jobB *structs.Job, taskGroup string) bool {
 			return true
 		} else if ar.MemoryMB != br.MemoryMB {
 			return true
+		} else if ar.MemoryMaxMB != br.MemoryMaxMB {
+			return true
+		} else if ar.Cores != br.Cores {
+			return true
 		} else if ar.IOPS != br.IOPS {
 			return true
 		}
@@ -521,13 +557,14 @@ func inplaceUpdate(ctx Context, eval *structs.Evaluation, job *structs.Job,
 			continue
 		}
 
-		// Restore the network offers from the existing allocation.
-		// We do not allow network resources (reserved/dynamic ports)
-		// to be updated. This is guarded in taskUpdated, so we can
-		// safely restore those here.
+		// Restore the network offers and reserved cores from the existing
+		// allocation. We do not allow network resources (reserved/dynamic
+		// ports) or cores to be updated. This is guarded in taskUpdated, so
+		// we can safely restore those here.
 		for task, resources := range option.TaskResources {
 			existing := update.Alloc.TaskResources[task]
 			resources.Networks = existing.Networks
+			resources.CoreIDs = existing.CoreIDs
 		}
 
 		// Create a shallow copy
@@ -706,14 +743,15 @@ func adjustQueuedAllocations(logger *log.Logger, result *structs.PlanResult, que
 	}
 }
 
-// updateNonTerminalAllocsToLost updates the allocations which are in pending/running state on tainted node
-// to lost
+// updateNonTerminalAllocsToLost updates the allocations which are in pending/running/unknown state on
+// tainted node to lost
 func updateNonTerminalAllocsToLost(plan *structs.Plan, tainted map[string]*structs.Node, allocs []*structs.Allocation) {
 	for _, alloc := range allocs {
 		if _, ok := tainted[alloc.NodeID]; ok &&
 			alloc.DesiredStatus == structs.AllocDesiredStatusStop &&
 			(alloc.ClientStatus == structs.AllocClientStatusRunning ||
-				alloc.ClientStatus == structs.AllocClientStatusPending) {
+				alloc.ClientStatus == structs.AllocClientStatusPending ||
+				alloc.ClientStatus == structs.AllocClientStatusUnknown) {
 			plan.AppendUpdate(alloc, structs.AllocDesiredStatusStop, allocLost, structs.AllocClientStatusLost)
 		}
 	}
@@ -777,13 +815,14 @@ func genericAllocUpdateFn(ctx Context, stack Stack, evalID string) allocUpdateTy
 			return false, true, nil
 		}
 
-		// Restore the network offers from the existing allocation.
-		// We do not allow network resources (reserved/dynamic ports)
-		// to be updated. This is guarded in taskUpdated, so we can
-		// safely restore those here.
+		// Restore the network offers and reserved cores from the existing
+		// allocation. We do not allow network resources (reserved/dynamic
+		// ports) or cores to be updated. This is guarded in taskUpdated, so
+		// we can safely restore those here.
 		for task, resources := range option.TaskResources {
 			existingResources := existing.TaskResources[task]
 			resources.Networks = existingResources.Networks
+			resources.CoreIDs = existingResources.CoreIDs
 		}
 
 		// Create a shallow copy
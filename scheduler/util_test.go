@@ -572,6 +572,47 @@ func TestTasksUpdated(t *testing.T) {
 	if !tasksUpdated(j1, j18, name) {
 		t.Fatal("bad")
 	}
+
+	// Change group network
+	j19 := mock.Job()
+	j19.TaskGroups[0].Networks = []*structs.NetworkResource{
+		{
+			Mode:         structs.NetworkModeBridge,
+			DynamicPorts: []structs.Port{{Label: "http"}},
+		},
+	}
+	if !tasksUpdated(j1, j19, name) {
+		t.Fatal("bad")
+	}
+
+	// Change memory max
+	j20 := mock.Job()
+	j20.TaskGroups[0].Tasks[0].Resources.MemoryMaxMB = 1024
+	if !tasksUpdated(j1, j20, name) {
+		t.Fatal("bad")
+	}
+
+	// Change reserved cores
+	j21 := mock.Job()
+	j21.TaskGroups[0].Tasks[0].Resources.Cores = 2
+	if !tasksUpdated(j1, j21, name) {
+		t.Fatal("bad")
+	}
+
+	// Add a log shipper
+	j22 := mock.Job()
+	j22.TaskGroups[0].Tasks[0].LogShippers = []*structs.LogShipper{
+		{
+			Name:       "audit",
+			Type:       structs.LogShipperTypeSyslog,
+			Address:    "10.0.0.1:514",
+			Protocol:   "udp",
+			BufferSize: structs.DefaultLogShipperBufferSize,
+		},
+	}
+	if !tasksUpdated(j1, j22, name) {
+		t.Fatal("bad")
+	}
 }
 
 func TestEvictAndPlace_LimitLessThanAllocs(t *testing.T) {